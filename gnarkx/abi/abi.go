@@ -0,0 +1,132 @@
+// The API for the ABI encoding of static tuples of uint256, address and bytes32 inside circuits, as
+// abi.encode and abi.decode in Solidity, so that circuits can reconstruct the calldata of a call or
+// the data of an event and hash it as a contract would.
+//
+// Every value of a static tuple takes a word of 32 bytes: a uint256 is big-endian, an address is
+// left padded with 12 zero bytes and a bytes32 is as is. Reference:
+// https://docs.soliditylang.org/en/latest/abi-spec.html
+package abi
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The length of a word of the encoding.
+const wordLength = 32
+
+// Returns the selector of a function, the first 4 bytes of the Keccak-256 hash of its signature,
+// e.g. "transfer(address,uint256)", as constants of the circuit.
+func Selector(signature string) [4]vars.Byte {
+	var selector [4]vars.Byte
+	hash := crypto.Keccak256([]byte(signature))
+	for i := 0; i < 4; i++ {
+		selector[i] = vars.Byte{Value: vars.NewVariableFromInt(int(hash[i]))}
+	}
+	return selector
+}
+
+// Encoder is used for encoding a static tuple in a circuit, with the values in the order they are
+// written.
+type Encoder struct {
+	api   builder.API
+	bytes []vars.Byte
+}
+
+// Creates a new Encoder.
+func NewEncoder(api builder.API) *Encoder {
+	return &Encoder{api: api, bytes: make([]vars.Byte, 0)}
+}
+
+// Writes a uint256, which also checks that its limbs are 64 bits.
+func (e *Encoder) WriteUint256(i1 vars.U256) {
+	bytes := e.api.ToBytes32FromU256(i1)
+	e.bytes = append(e.bytes, bytes[:]...)
+}
+
+// Writes an address.
+func (e *Encoder) WriteAddress(address [20]vars.Byte) {
+	for i := 0; i < wordLength-20; i++ {
+		e.bytes = append(e.bytes, vars.ZERO_BYTE)
+	}
+	e.bytes = append(e.bytes, address[:]...)
+}
+
+// Writes a bytes32.
+func (e *Encoder) WriteBytes32(bytes [32]vars.Byte) {
+	e.bytes = append(e.bytes, bytes[:]...)
+}
+
+// Returns the encoding of the values written so far, as abi.encode.
+func (e *Encoder) Bytes() []vars.Byte {
+	return append([]vars.Byte{}, e.bytes...)
+}
+
+// Returns the calldata of a call to the function of the selector with the values written so far
+// as its arguments, as abi.encodeWithSelector.
+func (e *Encoder) Calldata(selector [4]vars.Byte) []vars.Byte {
+	return append(selector[:], e.bytes...)
+}
+
+// Returns the Keccak-256 hash of the encoding, as keccak256(abi.encode(...)).
+func (e *Encoder) Keccak256() [32]vars.Byte {
+	return keccak256.Hash(e.api, e.bytes)
+}
+
+// Decoder is used for decoding a static tuple in a circuit, with the values read in order.
+type Decoder struct {
+	api   builder.API
+	ptr   int
+	bytes []vars.Byte
+}
+
+// Creates a new Decoder of the encoding, whose bytes are assumed to be range checked.
+func NewDecoder(api builder.API, bytes []vars.Byte) *Decoder {
+	if len(bytes)%wordLength != 0 {
+		panic(fmt.Sprintf("the length %d of the encoding is not a multiple of 32", len(bytes)))
+	}
+	return &Decoder{api: api, ptr: 0, bytes: bytes}
+}
+
+// Reads the next word of the encoding.
+func (d *Decoder) readWord() [32]vars.Byte {
+	if d.ptr+wordLength > len(d.bytes) {
+		panic("read past the end of the encoding")
+	}
+	var word [32]vars.Byte
+	copy(word[:], d.bytes[d.ptr:d.ptr+wordLength])
+	d.ptr += wordLength
+	return word
+}
+
+// Reads a uint256.
+func (d *Decoder) ReadUint256() vars.U256 {
+	return d.api.ToU256FromBytes32(d.readWord())
+}
+
+// Reads an address, asserting that its padding is zero as abi.decode does.
+func (d *Decoder) ReadAddress() [20]vars.Byte {
+	word := d.readWord()
+	for i := 0; i < wordLength-20; i++ {
+		d.api.AssertIsEqual(word[i].Value, vars.ZERO)
+	}
+	var address [20]vars.Byte
+	copy(address[:], word[wordLength-20:])
+	return address
+}
+
+// Reads a bytes32.
+func (d *Decoder) ReadBytes32() [32]vars.Byte {
+	return d.readWord()
+}
+
+// Panics unless every word of the encoding was read, so that the tuple has the expected types.
+func (d *Decoder) Close() {
+	if d.ptr != len(d.bytes) {
+		panic(fmt.Sprintf("%d bytes of the encoding were not read", len(d.bytes)-d.ptr))
+	}
+}
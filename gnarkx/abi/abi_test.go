@@ -0,0 +1,86 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestABICircuit struct {
+	Amount   vars.U256
+	Address  [20]vars.Byte
+	Hash     [32]vars.Byte
+	Encoded  [96]vars.Byte
+	Calldata [100]vars.Byte
+	Digest   [32]vars.Byte
+}
+
+func (c *TestABICircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	encoder := NewEncoder(*api)
+	encoder.WriteUint256(c.Amount)
+	encoder.WriteAddress(c.Address)
+	encoder.WriteBytes32(c.Hash)
+	for i, b := range encoder.Bytes() {
+		api.AssertIsEqualByte(b, c.Encoded[i])
+	}
+	for i, b := range encoder.Calldata(Selector("f(uint256,address,bytes32)")) {
+		api.AssertIsEqualByte(b, c.Calldata[i])
+	}
+	api.AssertIsEqualBytes32(encoder.Keccak256(), c.Digest)
+
+	decoder := NewDecoder(*api, c.Encoded[:])
+	api.AssertIsEqualU256(decoder.ReadUint256(), c.Amount)
+	address := decoder.ReadAddress()
+	for i := range address {
+		api.AssertIsEqualByte(address[i], c.Address[i])
+	}
+	api.AssertIsEqualBytes32(decoder.ReadBytes32(), c.Hash)
+	decoder.Close()
+	return nil
+}
+
+func TestABI(t *testing.T) {
+	uint256Type, _ := gethabi.NewType("uint256", "", nil)
+	addressType, _ := gethabi.NewType("address", "", nil)
+	bytes32Type, _ := gethabi.NewType("bytes32", "", nil)
+	arguments := gethabi.Arguments{{Type: uint256Type}, {Type: addressType}, {Type: bytes32Type}}
+
+	amount, _ := new(big.Int).SetString("fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210", 16)
+	address := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	hash := crypto.Keccak256Hash([]byte("Succinct Labs"))
+	encoded, err := arguments.Pack(amount, address, hash)
+	assert.NoError(t, err)
+	calldata := append(crypto.Keccak256([]byte("f(uint256,address,bytes32)"))[:4], encoded...)
+
+	var witness TestABICircuit
+	witness.Amount.Set(amount)
+	for i := range address {
+		witness.Address[i].Set(address[i])
+	}
+	vars.SetBytes32(&witness.Hash, hash)
+	copy(witness.Encoded[:], vars.NewBytesFrom(encoded))
+	copy(witness.Calldata[:], vars.NewBytesFrom(calldata))
+	vars.SetBytes32(&witness.Digest, crypto.Keccak256Hash(encoded))
+	assert.NoError(t, test.IsSolved(&TestABICircuit{}, &witness, ecc.BN254.ScalarField()))
+
+	// The padding of an address must be zero.
+	witness.Encoded[32+11].Set(1)
+	assert.Error(t, test.IsSolved(&TestABICircuit{}, &witness, ecc.BN254.ScalarField()))
+}
+
+func TestDecoderClose(t *testing.T) {
+	decoder := NewDecoder(builder.API{}, vars.NewBytes(64))
+	decoder.ReadBytes32()
+	assert.Panics(t, decoder.Close)
+	assert.Panics(t, func() { NewDecoder(builder.API{}, vars.NewBytes(33)) })
+}
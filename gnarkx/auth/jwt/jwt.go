@@ -0,0 +1,210 @@
+// A circuit template verifying a JSON Web Token signed with RS256 or ES256 and outputting one of
+// its claims, for zk-auth applications that only reveal a chosen claim of a token, such as an
+// email address or a user id, to a contract.
+//
+// The input of the circuit is the public key of the issuer, the big-endian RSA modulus for RS256
+// or the big-endian coordinates X || Y for ES256, followed by a uint64 unix timestamp at which the
+// token must not be expired. The signing input is hashed and verified in the circuit, and its
+// header and payload are base64url decoded to check that the alg header parameter matches the
+// config and to read the exp claim and the chosen claim, a string without escape sequences. The
+// claim is output with WriteVariableBytes, as its length followed by its bytes padded to its
+// maximum length.
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	jwtgadget "github.com/succinctlabs/succinctx/gnarkx/jwt"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Config describes the tokens a circuit accepts and the claim it outputs.
+type Config struct {
+	// The signature algorithm of the tokens, and for RS256 the length of the modulus of the key
+	// in bytes.
+	Algorithm     jwtgadget.Algorithm
+	ModulusLength int
+
+	// The maximum length of the signing input of the tokens.
+	MaxTokenLength int
+
+	// The name of the output claim and the maximum length of its value.
+	Claim          string
+	MaxClaimLength int
+}
+
+// Circuit verifies a JSON Web Token under the key of its input and outputs the claim of the
+// config.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Token jwtgadget.Token
+	Alg   jwtgadget.Claim
+	Exp   jwtgadget.Claim
+	Value jwtgadget.Claim
+
+	config *Config `gnark:"-"`
+	token  string  `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Returns the name of the algorithm, as in the alg header parameter, and the length of the keys
+// and signatures of the config in bytes.
+func (c *Config) algorithm() (string, int, int) {
+	switch c.Algorithm {
+	case jwtgadget.RS256:
+		return "RS256", c.ModulusLength, c.ModulusLength
+	case jwtgadget.ES256:
+		return "ES256", 64, 64
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %d", c.Algorithm))
+	}
+}
+
+// Creates a new circuit for tokens described by the config.
+func NewCircuit(config *Config) *Circuit {
+	_, keyLength, signatureLength := config.algorithm()
+	return &Circuit{
+		InputBytes:  vars.NewBytes(keyLength + 8),
+		OutputBytes: vars.NewBytes(8 + config.MaxClaimLength),
+		Token:       jwtgadget.NewToken(config.MaxTokenLength, signatureLength),
+		Alg:         jwtgadget.NewClaim(),
+		Exp:         jwtgadget.NewClaim(),
+		Value:       jwtgadget.NewClaim(),
+		config:      config,
+	}
+}
+
+// Sets the token that the next call to SetWitness assigns. The token is checked to have the claims
+// read by the circuit, but its signature and expiration are only checked by the circuit.
+func (c *Circuit) SetToken(token string) error {
+	t := jwtgadget.NewToken(len(c.Token.SigningInput), len(c.Token.Signature))
+	if err := t.Set(token); err != nil {
+		return err
+	}
+	if _, err := c.claims(token); err != nil {
+		return err
+	}
+	c.token = token
+	return nil
+}
+
+// Checks that the token has the alg header parameter and the exp and output claims read by the
+// circuit, and returns the value of the output claim.
+func (c *Circuit) claims(token string) ([]byte, error) {
+	header, err := jwtgadget.ParseHeader(token)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := jwtgadget.ParsePayload(token)
+	if err != nil {
+		return nil, err
+	}
+	alg := jwtgadget.NewClaim()
+	if err := alg.Set(header, "alg"); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"exp", c.config.Claim} {
+		claim := jwtgadget.NewClaim()
+		if err := claim.Set(payload, name); err != nil {
+			return nil, err
+		}
+	}
+
+	var claims map[string]interface{}
+	if err := json.NewDecoder(bytes.NewReader(payload)).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	value, ok := claims[c.config.Claim].(string)
+	if !ok {
+		return nil, fmt.Errorf("claim %s is not a string", c.config.Claim)
+	}
+	if len(value) > c.config.MaxClaimLength {
+		return nil, fmt.Errorf("claim %s length %d exceeds %d", c.config.Claim, len(value), c.config.MaxClaimLength)
+	}
+	return []byte(value), nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the token given to SetToken.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.token == "" {
+		panic("token must be set before the witness")
+	}
+	value, err := c.claims(c.token)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	if err := c.Token.Set(c.token); err != nil {
+		panic(err)
+	}
+	header, _ := jwtgadget.ParseHeader(c.token)
+	payload, _ := jwtgadget.ParsePayload(c.token)
+	if err := c.Alg.Set(header, "alg"); err != nil {
+		panic(err)
+	}
+	if err := c.Exp.Set(payload, "exp"); err != nil {
+		panic(err)
+	}
+	if err := c.Value.Set(payload, c.config.Claim); err != nil {
+		panic(err)
+	}
+
+	output := make([]byte, 8+c.config.MaxClaimLength)
+	for i := 0; i < 8; i++ {
+		output[7-i] = byte(len(value) >> (8 * i))
+	}
+	copy(output[8:], value)
+	vars.SetBytes(&c.OutputBytes, output)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	name, keyLength, _ := c.config.algorithm()
+	key := jwtgadget.PublicKey{Algorithm: c.config.Algorithm}
+	if c.config.Algorithm == jwtgadget.RS256 {
+		key.Modulus = inputReader.ReadBytes(keyLength)
+	} else {
+		key.X = inputReader.ReadBytes32()
+		key.Y = inputReader.ReadBytes32()
+	}
+	now := inputReader.ReadUint64()
+	inputReader.Close()
+
+	jwtgadget.Verify(*api, c.Token, key)
+	header := jwtgadget.DecodeHeader(*api, c.Token)
+	api.AssertIsEqual(c.Alg.Length, vars.NewVariableFromInt(len(name)))
+	alg := header.String("alg", c.Alg, len(name))
+	for i := 0; i < len(name); i++ {
+		api.AssertIsEqualByte(alg[i], vars.Byte{Value: vars.NewVariableFromInt(int(name[i]))})
+	}
+
+	payload := jwtgadget.DecodePayload(*api, c.Token)
+	payload.AssertNotExpired(c.Exp, now.Value)
+	value := payload.String(c.config.Claim, c.Value, c.config.MaxClaimLength)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteVariableBytes(vars.VariableBytes{Data: value, Length: c.Value.Length})
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
@@ -0,0 +1,207 @@
+// The API for Baby Jubjub, the twisted Edwards curve over the scalar field of BN254 of EIP-2494,
+// in the coordinates of circomlib: 168700 x^2 + y^2 = 1 + 168696 x^2 y^2, with the generator Base8
+// of the subgroup of prime order.
+// Reference: https://eips.ethereum.org/EIPS/eip-2494
+//
+// The curve is isomorphic to the twisted Edwards curve of gnark: -x^2 + y^2 = 1 + d x^2 y^2, where
+// d = -168696 / 168700, by scaling x by sqrt(-168700), which maps Base8 to the base of gnark. The
+// API computes on the curve of gnark and converts the coordinates of its points, so that the keys
+// and the points match those of circomlib, such as the public keys of its EdDSA.
+package babyjubjub
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	stdtwistededwards "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	// The order of the subgroup generated by Base8.
+	Order, _ = new(big.Int).SetString("2736030358979909402780800718157159386076813972158567259200215660948447373041", 10)
+
+	// sqrt(-168700), the factor of the x coordinates of gnark over those of circomlib.
+	scale, _ = new(big.Int).SetString("15527681003928902128179717624703512672403908117992798440346960750464748824729", 10)
+)
+
+// A point of the curve in the coordinates of circomlib outside of circuits.
+type PointValue struct {
+	X *big.Int
+	Y *big.Int
+}
+
+// Returns the identity of the curve.
+func Identity() PointValue {
+	return PointValue{X: big.NewInt(0), Y: big.NewInt(1)}
+}
+
+// Returns Base8, the generator of the subgroup of prime order.
+func Base8() PointValue {
+	base := edbn254.GetEdwardsCurve().Base
+	return fromAffine(&base)
+}
+
+// Returns the multiple of Base8 by the scalar, such as the public key of a secret scalar.
+func MulBase(scalar *big.Int) PointValue {
+	return Base8().Mul(scalar)
+}
+
+// Returns the multiple of the point by the scalar.
+func (p PointValue) Mul(scalar *big.Int) PointValue {
+	var result edbn254.PointAffine
+	point := p.toAffine()
+	result.ScalarMultiplication(&point, scalar)
+	return fromAffine(&result)
+}
+
+// Returns the sum of the points.
+func (p PointValue) Add(q PointValue) PointValue {
+	var result edbn254.PointAffine
+	p1, p2 := p.toAffine(), q.toAffine()
+	result.Add(&p1, &p2)
+	return fromAffine(&result)
+}
+
+// Returns the negation of the point.
+func (p PointValue) Neg() PointValue {
+	return PointValue{X: new(big.Int).Mod(new(big.Int).Neg(p.X), fr.Modulus()), Y: new(big.Int).Set(p.Y)}
+}
+
+// Returns whether the point is on the curve.
+func (p PointValue) IsOnCurve() bool {
+	point := p.toAffine()
+	return point.IsOnCurve()
+}
+
+// Returns whether the points are equal.
+func (p PointValue) Equal(q PointValue) bool {
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+func (p PointValue) toAffine() edbn254.PointAffine {
+	var point edbn254.PointAffine
+	var s fr.Element
+	point.X.SetBigInt(p.X)
+	point.X.Mul(&point.X, s.SetBigInt(scale))
+	point.Y.SetBigInt(p.Y)
+	return point
+}
+
+func fromAffine(point *edbn254.PointAffine) PointValue {
+	var x, s fr.Element
+	s.SetBigInt(scale)
+	x.Div(&point.X, &s)
+	return PointValue{X: x.BigInt(new(big.Int)), Y: point.Y.BigInt(new(big.Int))}
+}
+
+// A point of the curve in the coordinates of circomlib in the circuit.
+type Point struct {
+	X vars.Variable
+	Y vars.Variable
+}
+
+// Creates a new point.
+func NewPoint() Point {
+	return Point{X: vars.NewVariable(), Y: vars.NewVariable()}
+}
+
+// Creates a new constant point.
+func NewPointFrom(value PointValue) Point {
+	return Point{X: vars.Variable{Value: value.X}, Y: vars.Variable{Value: value.Y}}
+}
+
+// Sets the point.
+func (p *Point) Set(value PointValue) {
+	p.X.Set(value.X)
+	p.Y.Set(value.Y)
+}
+
+// BabyJubjubAPI is a wrapper around succinct.API that provides methods for Baby Jubjub.
+type BabyJubjubAPI struct {
+	api   builder.API
+	curve stdtwistededwards.Curve
+}
+
+// Creates a new BabyJubjubAPI.
+func NewAPI(api *builder.API) *BabyJubjubAPI {
+	curve, err := stdtwistededwards.NewEdCurve(api.FrontendAPI(), twistededwards.BN254)
+	if err != nil {
+		panic(err)
+	}
+	return &BabyJubjubAPI{api: *api, curve: curve}
+}
+
+// Returns the sum of the points.
+func (a *BabyJubjubAPI) Add(p Point, q Point) Point {
+	return a.fromEdwards(a.curve.Add(a.toEdwards(p), a.toEdwards(q)))
+}
+
+// Returns the double of the point.
+func (a *BabyJubjubAPI) Double(p Point) Point {
+	return a.fromEdwards(a.curve.Double(a.toEdwards(p)))
+}
+
+// Returns the negation of the point.
+func (a *BabyJubjubAPI) Neg(p Point) Point {
+	return Point{X: a.api.Neg(p.X), Y: p.Y}
+}
+
+// Returns the multiple of the point by the scalar, an element of the scalar field of BN254.
+func (a *BabyJubjubAPI) ScalarMul(p Point, scalar vars.Variable) Point {
+	return a.fromEdwards(a.curve.ScalarMul(a.toEdwards(p), scalar.Value))
+}
+
+// Returns the multiple of Base8 by the scalar, an element of the scalar field of BN254.
+func (a *BabyJubjubAPI) ScalarMulBase(scalar vars.Variable) Point {
+	return a.ScalarMul(NewPointFrom(Base8()), scalar)
+}
+
+// Asserts that the point is on the curve.
+func (a *BabyJubjubAPI) AssertIsOnCurve(p Point) {
+	a.curve.AssertIsOnCurve(a.toEdwards(p))
+}
+
+// Asserts that the points are equal.
+func (a *BabyJubjubAPI) AssertIsEqual(p Point, q Point) {
+	a.api.AssertIsEqual(p.X, q.X)
+	a.api.AssertIsEqual(p.Y, q.Y)
+}
+
+// Returns whether the point is on the curve, for points of untrusted data that must not fail the
+// circuit.
+func (a *BabyJubjubAPI) IsOnCurve(p Point) vars.Bool {
+	api := a.api
+	x2 := api.Mul(p.X, p.X)
+	y2 := api.Mul(p.Y, p.Y)
+	left := api.Add(api.Mul(vars.NewVariableFromInt(168700), x2), y2)
+	right := api.Add(vars.NewVariableFromInt(1), api.Mul(vars.NewVariableFromInt(168696), x2, y2))
+	return api.IsZero(api.Sub(left, right))
+}
+
+// Returns whether the points are equal.
+func (a *BabyJubjubAPI) IsEqual(p Point, q Point) vars.Bool {
+	return a.api.And(a.api.IsZero(a.api.Sub(p.X, q.X)), a.api.IsZero(a.api.Sub(p.Y, q.Y)))
+}
+
+// Returns p if the selector is true, q otherwise.
+func (a *BabyJubjubAPI) Select(selector vars.Bool, p Point, q Point) Point {
+	return Point{X: a.api.Select(selector, p.X, q.X), Y: a.api.Select(selector, p.Y, q.Y)}
+}
+
+// Returns the point multiplied by the cofactor 8, which is in the subgroup of prime order.
+func (a *BabyJubjubAPI) MulCofactor(p Point) Point {
+	return a.Double(a.Double(a.Double(p)))
+}
+
+func (a *BabyJubjubAPI) toEdwards(p Point) stdtwistededwards.Point {
+	return stdtwistededwards.Point{X: a.api.Mul(p.X, vars.Variable{Value: scale}).Value, Y: p.Y.Value}
+}
+
+func (a *BabyJubjubAPI) fromEdwards(p stdtwistededwards.Point) Point {
+	inverse := new(big.Int).ModInverse(scale, fr.Modulus())
+	return Point{X: a.api.Mul(vars.Variable{Value: p.X}, vars.Variable{Value: inverse}), Y: vars.Variable{Value: p.Y}}
+}
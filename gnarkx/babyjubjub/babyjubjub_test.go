@@ -0,0 +1,80 @@
+package babyjubjub
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func fromString(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 10)
+	return v
+}
+
+// Returns whether the point is on the curve of circomlib.
+func isOnCircomCurve(p PointValue) bool {
+	modulus := fr.Modulus()
+	x2 := new(big.Int).Mul(p.X, p.X)
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	left := new(big.Int).Add(new(big.Int).Mul(big.NewInt(168700), x2), y2)
+	right := new(big.Int).Add(big.NewInt(1), new(big.Int).Mul(big.NewInt(168696), new(big.Int).Mul(x2, y2)))
+	diff := new(big.Int).Sub(left, right)
+	return diff.Mod(diff, modulus).Sign() == 0
+}
+
+func TestPointValue(t *testing.T) {
+	// The generator and Base8 of EIP-2494.
+	generator := PointValue{
+		X: fromString("995203441582195749578291179787384436505546430278305826713579947235728471134"),
+		Y: fromString("5472060717959818805561601436314318772137091100104008585924551046643952123905"),
+	}
+	base := PointValue{
+		X: fromString("5299619240641551281634865583518297030282874472190772894086521144482721001553"),
+		Y: fromString("16950150798460657717958625567821834550301663161624707787222815936182638968203"),
+	}
+	assert.Equal(t, base, Base8())
+	assert.True(t, isOnCircomCurve(generator))
+	assert.True(t, generator.IsOnCurve())
+	assert.Equal(t, base, generator.Mul(big.NewInt(8)))
+	assert.True(t, MulBase(Order).Equal(Identity()))
+
+	p := MulBase(big.NewInt(12345))
+	assert.True(t, isOnCircomCurve(p))
+	assert.Equal(t, MulBase(big.NewInt(12346)), p.Add(base))
+	assert.True(t, p.Add(p.Neg()).Equal(Identity()))
+}
+
+type testCircuit struct {
+	Scalar   vars.Variable
+	Point    Point
+	Expected Point
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := NewAPI(api)
+	curve.AssertIsOnCurve(c.Point)
+	p := curve.Add(curve.ScalarMulBase(c.Scalar), c.Point)
+	curve.AssertIsEqual(p, c.Expected)
+	curve.AssertIsEqual(curve.MulCofactor(c.Point), curve.ScalarMul(c.Point, vars.NewVariableFromInt(8)))
+	return nil
+}
+
+func TestScalarMul(t *testing.T) {
+	point := MulBase(big.NewInt(7))
+	circuit := &testCircuit{}
+	assignment := &testCircuit{Scalar: vars.NewVariableFromInt(12345)}
+	assignment.Point.Set(point)
+	assignment.Expected.Set(MulBase(big.NewInt(12352)))
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	assignment.Expected.Set(MulBase(big.NewInt(12353)))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
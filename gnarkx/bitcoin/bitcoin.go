@@ -0,0 +1,101 @@
+// The API for Bitcoin block headers: their double SHA256 hashes and the verification of a chain of
+// headers by the links to their parents and their proofs of work, for bridges that follow Bitcoin,
+// and of the inclusion of transactions in the blocks of the headers, for SPV payment proofs.
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	sha256gadget "github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The length of a serialized block header: the version, the hash of the parent, the Merkle root of
+// the transactions, the time, the bits of the target and the nonce.
+const HeaderLength = 80
+
+// The offsets of the fields of a header that are checked by VerifyHeaderChain.
+const (
+	prevHashOffset   = 4
+	merkleRootOffset = 36
+	bitsOffset       = 72
+)
+
+// Computes the hash of a header, sha256(sha256(header)). As all hashes of Bitcoin, it is a
+// little-endian integer, which explorers display reversed.
+func HashBlockHeader(api builder.API, header [HeaderLength]vars.Byte) [32]vars.Byte {
+	return hash256(api, header[:])
+}
+
+// Computes sha256(sha256(in)), the hash of headers, transactions and the nodes of Merkle trees.
+func hash256(api builder.API, in []vars.Byte) [32]vars.Byte {
+	hash := sha256gadget.Hash(api, in)
+	return sha256gadget.Hash(api, hash[:])
+}
+
+// Computes HashBlockHeader outside of the circuit.
+func HashBlockHeaderValue(header [HeaderLength]byte) [32]byte {
+	return hash256Value(header[:])
+}
+
+func hash256Value(in []byte) [32]byte {
+	hash := sha256.Sum256(in)
+	return sha256.Sum256(hash[:])
+}
+
+// Returns the target encoded by the compact bits of a header, mantissa * 256^(exponent - 3), where
+// the exponent is the high byte of the bits and the mantissa the low 3 bytes.
+func TargetValue(bits uint32) *big.Int {
+	mantissa := big.NewInt(int64(bits & 0x007fffff))
+	exponent := int(bits >> 24)
+	if exponent < 3 {
+		return mantissa.Rsh(mantissa, uint(8*(3-exponent)))
+	}
+	return mantissa.Lsh(mantissa, uint(8*(exponent-3)))
+}
+
+// Verifies that each header links to the previous one by the hash of its parent and that the hash
+// of each header is at most the target of its bits, and returns the hashes of the headers. The
+// number of headers is a compile time constant. The parent of the first header and the bits are not
+// otherwise constrained, so callers check them, e.g. against a checkpoint and the difficulty of the
+// period of the headers. The bytes of the headers are assumed to be range checked.
+func VerifyHeaderChain(api builder.API, headers [][HeaderLength]vars.Byte) [][32]vars.Byte {
+	hashes := make([][32]vars.Byte, len(headers))
+	for i := range headers {
+		hashes[i] = HashBlockHeader(api, headers[i])
+		if i > 0 {
+			var prevHash [32]vars.Byte
+			copy(prevHash[:], headers[i][prevHashOffset:prevHashOffset+32])
+			api.AssertIsEqualBytes32(prevHash, hashes[i-1])
+		}
+		hash := api.ToU256FromBytes32(vars.ReverseBytes32(hashes[i]))
+		target := api.ToU256FromBytes32(vars.ReverseBytes32(target(api, headers[i])))
+		api.AssertIsEqual(api.IsLessU256(target, hash).Value, vars.ZERO)
+	}
+	return hashes
+}
+
+// Returns the target of the bits of the header as 32 little-endian bytes, where the mantissa is
+// placed at byte exponent - 3. The exponent is checked to be between 3 and 32, so that the mantissa
+// fits, and the mantissa to be non-negative, as the consensus rules require of targets.
+func target(api builder.API, header [HeaderLength]vars.Byte) [32]vars.Byte {
+	mantissa := header[bitsOffset : bitsOffset+3]
+	shift := api.Sub(header[bitsOffset+3].Value, vars.NewVariableFromInt(3))
+	api.ToBinaryLE(shift, 5)
+	api.ToBinaryLE(api.Sub(vars.NewVariableFromInt(29), shift), 5)
+	api.AssertIsEqual(api.ToBitsFromByte(mantissa[2])[7].Value, vars.ZERO)
+
+	var result [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		result[i] = vars.Byte{Value: vars.ZERO}
+	}
+	for k := 0; k < 30; k++ {
+		isShift := api.IsZero(api.Sub(shift, vars.NewVariableFromInt(k))).Value
+		for j := 0; j < 3; j++ {
+			result[k+j] = vars.Byte{Value: api.Add(result[k+j].Value, api.Mul(isShift, mantissa[j].Value))}
+		}
+	}
+	return result
+}
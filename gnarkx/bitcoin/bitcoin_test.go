@@ -0,0 +1,176 @@
+package bitcoin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testChainCircuit struct {
+	Headers  [][HeaderLength]vars.Byte
+	LastHash [32]vars.Byte
+}
+
+func (c *testChainCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	hashes := VerifyHeaderChain(*api, c.Headers)
+	api.AssertIsEqualBytes32(hashes[len(hashes)-1], c.LastHash)
+	return nil
+}
+
+func isSolved(headers [][HeaderLength]byte) error {
+	circuit := testChainCircuit{Headers: make([][HeaderLength]vars.Byte, len(headers))}
+	assignment := testChainCircuit{Headers: make([][HeaderLength]vars.Byte, len(headers))}
+	for i := range headers {
+		for j := 0; j < HeaderLength; j++ {
+			assignment.Headers[i][j] = vars.NewByte()
+			assignment.Headers[i][j].Set(headers[i][j])
+		}
+	}
+	vars.SetBytes32(&assignment.LastHash, HashBlockHeaderValue(headers[len(headers)-1]))
+	return test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+}
+
+func decodeHeader(t *testing.T, s string) [HeaderLength]byte {
+	bytes, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	var header [HeaderLength]byte
+	copy(header[:], bytes)
+	return header
+}
+
+// Returns the hash displayed by explorers, the reversed bytes of the hash.
+func displayHash(hash [32]byte) string {
+	for i := 0; i < 16; i++ {
+		hash[i], hash[31-i] = hash[31-i], hash[i]
+	}
+	return hex.EncodeToString(hash[:])
+}
+
+func TestMainnet(t *testing.T) {
+	// The genesis block and blocks 1 and 2 of mainnet.
+	headers := [][HeaderLength]byte{
+		decodeHeader(t, "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"),
+		decodeHeader(t, "010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"),
+		decodeHeader(t, "010000004860eb18bf1b1620e37e9490fc8a427514416fd75159ab86688e9a8300000000d5fdcc541e25de1c7a5addedf24858b8bb665c9f36ef744ee42c316022c90f9bb0bc6649ffff001d08d2bd61"),
+	}
+	assert.Equal(t, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", displayHash(HashBlockHeaderValue(headers[0])))
+	assert.Equal(t, "00000000ffff0000000000000000000000000000000000000000000000000000", hex.EncodeToString(TargetValue(0x1d00ffff).FillBytes(make([]byte, 32))))
+	assert.NoError(t, isSolved(headers))
+
+	// The headers must link to their parents.
+	assert.Error(t, isSolved([][HeaderLength]byte{headers[0], headers[2]}))
+}
+
+// Returns a header with the bits of regtest, whose target is about 2^255, linked to the parent and
+// whose hash is at most the target if valid is set.
+func mineHeader(parent [32]byte, valid bool) [HeaderLength]byte {
+	var header [HeaderLength]byte
+	binary.LittleEndian.PutUint32(header[0:], 0x20000000)
+	copy(header[prevHashOffset:], parent[:])
+	binary.LittleEndian.PutUint32(header[bitsOffset:], 0x207fffff)
+	target := TargetValue(0x207fffff)
+	for nonce := uint32(0); ; nonce++ {
+		binary.LittleEndian.PutUint32(header[76:], nonce)
+		hash := HashBlockHeaderValue(header)
+		for i := 0; i < 16; i++ {
+			hash[i], hash[31-i] = hash[31-i], hash[i]
+		}
+		if (new(big.Int).SetBytes(hash[:]).Cmp(target) <= 0) == valid {
+			return header
+		}
+	}
+}
+
+func TestProofOfWork(t *testing.T) {
+	first := mineHeader([32]byte{}, true)
+	second := mineHeader(HashBlockHeaderValue(first), true)
+	assert.NoError(t, isSolved([][HeaderLength]byte{first, second}))
+
+	// The hash of each header must be at most its target.
+	invalid := mineHeader(HashBlockHeaderValue(first), false)
+	assert.Error(t, isSolved([][HeaderLength]byte{first, invalid}))
+
+	// The exponent of the bits must fit the target in 32 bytes.
+	tooLarge := second
+	tooLarge[bitsOffset+3] = 33
+	assert.Error(t, isSolved([][HeaderLength]byte{first, tooLarge}))
+}
+
+type testSPVCircuit struct {
+	Header     [HeaderLength]vars.Byte
+	Tx         []vars.Byte
+	VariableTx vars.VariableBytes
+	Branch     [][32]vars.Byte
+	Index      vars.U64
+}
+
+func (c *testSPVCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	txid := TxID(*api, c.Tx)
+	api.AssertIsEqualBytes32(TxIDVariable(*api, c.VariableTx), txid)
+	VerifyTxInclusionInHeader(*api, c.Header, txid, c.Branch, c.Index)
+	return nil
+}
+
+func isIncluded(header [HeaderLength]byte, tx []byte, branch [][32]byte, index int) error {
+	circuit := testSPVCircuit{Tx: make([]vars.Byte, len(tx)), VariableTx: vars.NewVariableBytes(len(tx) + 10), Branch: make([][32]vars.Byte, len(branch))}
+	assignment := testSPVCircuit{Tx: vars.NewBytesFrom(tx), VariableTx: vars.NewVariableBytes(len(tx) + 10), Branch: vars.NewBytes32Array(len(branch))}
+	for i := 0; i < HeaderLength; i++ {
+		assignment.Header[i] = vars.NewByte()
+		assignment.Header[i].Set(header[i])
+	}
+	vars.SetVariableBytes(&assignment.VariableTx, tx)
+	vars.SetBytes32Array(&assignment.Branch, branch)
+	assignment.Index.Set(uint64(index))
+	return test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+}
+
+func TestGenesisCoinbase(t *testing.T) {
+	header := decodeHeader(t, "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c")
+	tx, err := hex.DecodeString("01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000")
+	assert.NoError(t, err)
+	txid := TxIDValue(tx)
+	assert.Equal(t, "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", displayHash(txid))
+	assert.Equal(t, txid, MerkleRootValue([][32]byte{txid}))
+
+	// The coinbase is the only transaction of the block, whose root is its txid.
+	assert.NoError(t, isIncluded(header, tx, nil, 0))
+}
+
+func TestVerifyTxInclusion(t *testing.T) {
+	// Five transactions, so that the last one is paired with itself.
+	txs := make([][]byte, 5)
+	txids := make([][32]byte, len(txs))
+	for i := range txs {
+		txs[i] = []byte(fmt.Sprintf("transaction %d", i))
+		txids[i] = TxIDValue(txs[i])
+	}
+	var header [HeaderLength]byte
+	root := MerkleRootValue(txids)
+	copy(header[merkleRootOffset:], root[:])
+
+	for _, index := range []int{0, 3, 4} {
+		branch, err := MerkleBranchValue(txids, index)
+		assert.NoError(t, err)
+		assert.Len(t, branch, 3)
+		assert.NoError(t, isIncluded(header, txs[index], branch, index))
+	}
+
+	// The transaction must be at the index.
+	branch, err := MerkleBranchValue(txids, 3)
+	assert.NoError(t, err)
+	assert.Error(t, isIncluded(header, txs[3], branch, 2))
+	assert.Error(t, isIncluded(header, txs[2], branch, 3))
+	_, err = MerkleBranchValue(txids, 5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package bitcoin
+
+import (
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	sha256gadget "github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the txid of a raw transaction, sha256(sha256(tx)), where len(tx) is a compile time
+// constant. The transaction must be serialized without its witness, as the txid of a segwit
+// transaction does not commit to it.
+func TxID(api builder.API, tx []vars.Byte) [32]vars.Byte {
+	return hash256(api, tx)
+}
+
+// Computes the txid of a raw transaction of variable length, at most len(tx.Data) bytes.
+func TxIDVariable(api builder.API, tx vars.VariableBytes) [32]vars.Byte {
+	hash := sha256gadget.HashVariable(api, tx.Data, vars.U64{Value: tx.Length}, len(tx.Data))
+	return sha256gadget.Hash(api, hash[:])
+}
+
+// Computes TxID outside of the circuit.
+func TxIDValue(tx []byte) [32]byte {
+	return hash256Value(tx)
+}
+
+// Verifies that the transaction of the txid is at the index of the block of the Merkle root, by the
+// branch of the siblings of its path from the leaf to the root, as in the merkleblock messages of
+// BIP 37. The depth of the tree, len(branch), is a compile time constant, and the index is checked
+// to be less than 2^depth.
+//
+// As in Bitcoin, an inner node of the tree is the hash of 64 bytes, so callers check that the
+// transactions they prove are not 64 bytes long, or that the depth is that of the number of
+// transactions of the block, lest an inner node pass for a txid.
+func VerifyTxInclusion(api builder.API, merkleRoot [32]vars.Byte, txid [32]vars.Byte, branch [][32]vars.Byte, index vars.U64) {
+	bits := api.ToBinaryLE(index.Value, len(branch))
+	node := txid
+	for i := range branch {
+		left := api.SelectBytes32(bits[i], branch[i], node)
+		right := api.SelectBytes32(bits[i], node, branch[i])
+		node = hash256(api, append(left[:], right[:]...))
+	}
+	api.AssertIsEqualBytes32(node, merkleRoot)
+}
+
+// Verifies that the transaction of the txid is at the index of the block of the header, the SPV
+// proof of a payment, where the header is typically one of VerifyHeaderChain.
+func VerifyTxInclusionInHeader(api builder.API, header [HeaderLength]vars.Byte, txid [32]vars.Byte, branch [][32]vars.Byte, index vars.U64) {
+	var merkleRoot [32]vars.Byte
+	copy(merkleRoot[:], header[merkleRootOffset:merkleRootOffset+32])
+	VerifyTxInclusion(api, merkleRoot, txid, branch, index)
+}
+
+// Returns the Merkle root of the txids of a block, where the last node of a level with an odd
+// number of nodes is paired with itself.
+func MerkleRootValue(txids [][32]byte) [32]byte {
+	if len(txids) == 0 {
+		panic("a block has at least one transaction")
+	}
+	level := txids
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+	return level[0]
+}
+
+// Returns the branch of the txid at the index of the txids of a block, from the leaf to the root.
+func MerkleBranchValue(txids [][32]byte, index int) ([][32]byte, error) {
+	if index < 0 || index >= len(txids) {
+		return nil, fmt.Errorf("index %d of %d transactions", index, len(txids))
+	}
+	var branch [][32]byte
+	level := txids
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling == len(level) {
+			sibling = index
+		}
+		branch = append(branch, level[sibling])
+		level = nextLevel(level)
+		index /= 2
+	}
+	return branch, nil
+}
+
+func nextLevel(level [][32]byte) [][32]byte {
+	next := make([][32]byte, (len(level)+1)/2)
+	for i := range next {
+		left := level[2*i]
+		right := left
+		if 2*i+1 < len(level) {
+			right = level[2*i+1]
+		}
+		next[i] = hash256Value(append(left[:], right[:]...))
+	}
+	return next
+}
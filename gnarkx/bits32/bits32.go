@@ -2,6 +2,8 @@
 package bits32
 
 import (
+	"math/bits"
+
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
@@ -53,24 +55,55 @@ func (a *API) Not(i1 [32]vars.Bool) [32]vars.Bool {
 	return result
 }
 
-// Computes the binary sum of two 32 bit arrays. Equivalently, think of this as addition modulo
-// 2^32.
+// Computes the binary sum of 32 bit arrays. Equivalently, think of this as addition modulo 2^32.
+//
+// The arrays are packed into field elements and summed, so that only the sum is decomposed into
+// bits, with enough bits for the carry, instead of adding the arrays bit by bit.
 func (a *API) Add(in ...[32]vars.Bool) [32]vars.Bool {
 	if len(in) == 1 {
 		return in[0]
-	} else {
-		return a.add(in[0], a.Add(in[1:]...))
 	}
+	sum := vars.ZERO
+	for i := range in {
+		sum = a.api.Add(sum, a.pack(in[i]))
+	}
+	sumBits := a.api.ToBinaryLE(sum, 32+bits.Len(uint(len(in)-1)))
+	var result [32]vars.Bool
+	for i := 0; i < 32; i++ {
+		result[31-i] = sumBits[i]
+	}
+	return result
+}
+
+// Packs a 32 bit array into a field element.
+func (a *API) pack(i1 [32]vars.Bool) vars.Variable {
+	result := vars.ZERO
+	for i := 0; i < 32; i++ {
+		result = a.api.Add(result, a.api.Mul(i1[i].Value, vars.NewVariableFromInt(1<<(31-i))))
+	}
+	return result
 }
 
-func (a *API) add(i1, i2 [32]vars.Bool) [32]vars.Bool {
+// Computes the bits of i1 where selector is set and the bits of i2 elsewhere, or
+// (selector & i1) ^ (!selector & i2), with a single constraint per bit.
+func (a *API) Select(selector, i1, i2 [32]vars.Bool) [32]vars.Bool {
 	var result [32]vars.Bool
-	carry := vars.ZERO
-	for i := 31; i >= 0; i-- {
-		sum := a.api.Add(i1[i].Value, i2[i].Value, carry)
-		sumBin := a.api.ToBinaryLE(sum, 2)
-		result[i] = sumBin[0]
-		carry = sumBin[1].Value
+	for i := 0; i < 32; i++ {
+		diff := a.api.Sub(i1[i].Value, i2[i].Value)
+		result[i] = vars.Bool{Value: a.api.Add(i2[i].Value, a.api.Mul(selector[i].Value, diff))}
+	}
+	return result
+}
+
+// Computes the majority of the bits of three 32 bit arrays, or (i1 & i2) ^ (i1 & i3) ^ (i2 & i3),
+// with two constraints per bit.
+func (a *API) Majority(i1, i2, i3 [32]vars.Bool) [32]vars.Bool {
+	var result [32]vars.Bool
+	for i := 0; i < 32; i++ {
+		// maj = t + i3 * (i1 + i2 - 2t) with t = i1 & i2.
+		t := a.api.Mul(i1[i].Value, i2[i].Value)
+		xor := a.api.Sub(a.api.Add(i1[i].Value, i2[i].Value), a.api.Mul(t, vars.TWO))
+		result[i] = vars.Bool{Value: a.api.Add(t, a.api.Mul(i3[i].Value, xor))}
 	}
 	return result
 }
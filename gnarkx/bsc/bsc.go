@@ -0,0 +1,189 @@
+// A circuit template proving the finality of blocks of BNB Smart Chain, whose Parlia consensus
+// attests each block with an aggregate BLS signature of the validators on the vote for its parent.
+//
+// The input is the hash of the validator set that signed and the hash of a block, and the output
+// is the number and hash of the target of the attestation in the extra data of the block, which is
+// its parent, followed by the hash of the validator set after the block. The hash of a validator
+// set is the keccak256 of its encoding in the extra data of epoch blocks: the number of validators
+// as one byte followed by the address and the compressed BLS public key of each validator.
+//
+// Epoch blocks carry the next validator set in their extra data, whose hash is then the output.
+// Parlia switches to it only after a number of blocks proportional to the size of the set, so the
+// client tracks which set signs each block from the outputs before chaining proofs.
+package bsc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	// The lengths of the vanity prefix and the seal suffix of the extra data.
+	extraVanity = 32
+	extraSeal   = 65
+
+	// The length of a validator in the extra data: the address and the BLS public key.
+	ValidatorLength = 20 + bls.G1Length
+
+	// The maximum length of the RLP encoding of the vote data.
+	maxVoteDataLength = 86
+
+	// The position of the number and of the extra data in the list of fields of a header.
+	numberField = 8
+	extraField  = 12
+)
+
+// Config describes the chain and the shape of the headers a circuit accepts.
+type Config struct {
+	// The length of an epoch in blocks, e.g. 200 before and 500 after the Lorentz hard fork of
+	// mainnet.
+	Epoch uint64
+
+	// Whether epoch blocks carry the turn length after the validators, as after the Bohr hard
+	// fork.
+	TurnLength bool
+
+	// The maximum number of validators of a set, and the maximum length of the RLP encoding of a
+	// header, which must fit the validators of epoch blocks and exceeds eth.MaxHeaderLength.
+	MaxValidators   int
+	MaxHeaderLength int
+}
+
+// The data of a vote of the fast finality mechanism of Parlia.
+type VoteData struct {
+	SourceNumber uint64
+	SourceHash   common.Hash
+	TargetNumber uint64
+	TargetHash   common.Hash
+}
+
+// Returns the hash of the vote data that the validators sign.
+func (d *VoteData) Hash() common.Hash {
+	encoded, err := rlp.EncodeToBytes(d)
+	if err != nil {
+		panic(err)
+	}
+	return crypto.Keccak256Hash(encoded)
+}
+
+// The attestation of a block by the aggregate signature of the validators whose bits are set in
+// VoteAddressSet, indexed in the order of the validators in the extra data of the epoch.
+type Attestation struct {
+	VoteAddressSet uint64
+	AggSignature   [bls.G2Length]byte
+	Data           *VoteData
+	Extra          []byte
+}
+
+// Returns the input of the circuit.
+func (c *Config) Input(validatorSetHash common.Hash, blockHash common.Hash) []byte {
+	return append(validatorSetHash.Bytes(), blockHash.Bytes()...)
+}
+
+// Returns the hash of the encoding of a validator set as in the extra data of epoch blocks.
+func ValidatorSetHash(validators []byte) common.Hash {
+	return crypto.Keccak256Hash(validators)
+}
+
+// The parts of the extra data of a header.
+type extra struct {
+	number uint64
+
+	// The validators of an epoch block, or nil.
+	validators []byte
+
+	attestation *Attestation
+}
+
+// Parses the extra data of the RLP encoding of a header.
+func (c *Config) parseExtra(encoded []byte) (*extra, error) {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(encoded, &fields); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if len(fields) <= extraField {
+		return nil, fmt.Errorf("header has %d fields", len(fields))
+	}
+	var result extra
+	var data []byte
+	if err := rlp.DecodeBytes(fields[numberField], &result.number); err != nil {
+		return nil, fmt.Errorf("invalid number: %w", err)
+	}
+	if err := rlp.DecodeBytes(fields[extraField], &data); err != nil {
+		return nil, fmt.Errorf("invalid extra data: %w", err)
+	}
+	if len(data) < extraVanity+extraSeal {
+		return nil, fmt.Errorf("extra data of %d bytes", len(data))
+	}
+	data = data[extraVanity : len(data)-extraSeal]
+
+	if result.number%c.Epoch == 0 {
+		if len(data) == 0 {
+			return nil, fmt.Errorf("epoch block without validators")
+		}
+		length := 1 + ValidatorLength*int(data[0])
+		if c.TurnLength {
+			length++
+		}
+		if len(data) < length {
+			return nil, fmt.Errorf("extra data of %d bytes for %d validators", len(data), data[0])
+		}
+		if err := c.checkValidators(data[:1+ValidatorLength*int(data[0])]); err != nil {
+			return nil, err
+		}
+		result.validators = data[:1+ValidatorLength*int(data[0])]
+		data = data[length:]
+	}
+
+	result.attestation = new(Attestation)
+	if err := rlp.DecodeBytes(data, result.attestation); err != nil {
+		return nil, fmt.Errorf("invalid attestation: %w", err)
+	}
+	if result.attestation.Data == nil {
+		return nil, fmt.Errorf("attestation without vote data")
+	}
+	return &result, nil
+}
+
+// Checks that the encoding of a validator set fits the config.
+func (c *Config) checkValidators(validators []byte) error {
+	if len(validators) == 0 || validators[0] == 0 {
+		return fmt.Errorf("empty validator set")
+	}
+	if int(validators[0]) > c.MaxValidators {
+		return fmt.Errorf("%d validators, expected at most %d", validators[0], c.MaxValidators)
+	}
+	if len(validators) != 1+ValidatorLength*int(validators[0]) {
+		return fmt.Errorf("validator set of %d bytes for %d validators", len(validators), validators[0])
+	}
+	return nil
+}
+
+// Returns the outputs of the circuit for a header attested by the validators: the number and hash
+// of the target, and the hash of the validators after the block.
+func (c *Config) outputs(header *eth.Header, validators []byte) ([]byte, error) {
+	length := int(header.Length.Value.(*big.Int).Int64())
+	extra, err := c.parseExtra(vars.GetValuesUnsafe(header.RLP[:length]))
+	if err != nil {
+		return nil, err
+	}
+	data := extra.attestation.Data
+	if data.TargetNumber+1 != extra.number {
+		return nil, fmt.Errorf("attestation of block %d in block %d", data.TargetNumber, extra.number)
+	}
+	next := ValidatorSetHash(validators)
+	if extra.validators != nil {
+		next = ValidatorSetHash(extra.validators)
+	}
+	output := binary.BigEndian.AppendUint64(nil, data.TargetNumber)
+	output = append(output, data.TargetHash.Bytes()...)
+	return append(output, next.Bytes()...), nil
+}
@@ -0,0 +1,124 @@
+package bsc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Returns the secret keys and the encoding of a validator set of n validators.
+func testValidators(n int, seed int64) ([]*big.Int, []byte) {
+	_, _, g1, _ := bls12381.Generators()
+	secrets := make([]*big.Int, n)
+	validators := []byte{byte(n)}
+	for i := 0; i < n; i++ {
+		secrets[i] = big.NewInt(seed*1000 + int64(i)*7919 + 11)
+		var address common.Address
+		address[0] = byte(i + 1)
+		var pubkey bls12381.G1Affine
+		pubkey.ScalarMultiplication(&g1, secrets[i])
+		compressed := pubkey.Bytes()
+		validators = append(append(validators, address.Bytes()...), compressed[:]...)
+	}
+	return secrets, validators
+}
+
+// Returns a header of the number whose extra data holds the next validators, if any, and the
+// attestation of its parent by the keys whose bits are set.
+func testHeader(t *testing.T, config *Config, number uint64, next []byte, secrets []*big.Int, bits uint64) *eth.Header {
+	parentHash := common.HexToHash("0x8b1d2cc2f9c7a4b8d4b14b0e1bcbc0e4d8e58f0b3e6e8d9c0e3f1e2d7c6b5a49")
+	data := &VoteData{
+		SourceNumber: number - 2,
+		SourceHash:   common.HexToHash("0x01"),
+		TargetNumber: number - 1,
+		TargetHash:   parentHash,
+	}
+	hash, err := bls12381.HashToG2(data.Hash().Bytes(), bls.DST)
+	assert.NoError(t, err)
+	var signature bls12381.G2Affine
+	for i := range secrets {
+		if bits&(1<<i) != 0 {
+			var partial bls12381.G2Affine
+			partial.ScalarMultiplication(&hash, secrets[i])
+			signature.Add(&signature, &partial)
+		}
+	}
+	attestation, err := rlp.EncodeToBytes(&Attestation{
+		VoteAddressSet: bits,
+		AggSignature:   signature.Bytes(),
+		Data:           data,
+	})
+	assert.NoError(t, err)
+
+	extra := make([]byte, extraVanity)
+	if next != nil {
+		extra = append(extra, next...)
+		if config.TurnLength {
+			extra = append(extra, 4)
+		}
+	}
+	extra = append(append(extra, attestation...), make([]byte, extraSeal)...)
+	header := &types.Header{
+		ParentHash: parentHash,
+		Root:       common.HexToHash("0x02"),
+		Difficulty: big.NewInt(2),
+		Number:     new(big.Int).SetUint64(number),
+		GasLimit:   140000000,
+		GasUsed:    21000,
+		Time:       1700000000 + 3*number,
+		Extra:      extra,
+	}
+	result := eth.NewHeader(config.MaxHeaderLength)
+	assert.NoError(t, result.Set(header))
+	return &result
+}
+
+func testSolved(t *testing.T, config *Config, header *eth.Header, validators []byte) error {
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetProofs(header, validators))
+	blockHash := common.BytesToHash(vars.GetValuesUnsafe(header.Hash[:]))
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(config.Input(ValidatorSetHash(validators), blockHash))
+	return test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+}
+
+func TestCircuit(t *testing.T) {
+	config := &Config{Epoch: 200, TurnLength: true, MaxValidators: 4, MaxHeaderLength: 1280}
+	secrets, validators := testValidators(4, 1)
+
+	// Three of four validators attest a block within an epoch.
+	header := testHeader(t, config, 1001, nil, secrets, 0b1011)
+	assert.NoError(t, testSolved(t, config, header, validators))
+	outputs, err := config.outputs(header, validators)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), new(big.Int).SetBytes(outputs[:8]).Uint64())
+	assert.Equal(t, ValidatorSetHash(validators).Bytes(), outputs[40:])
+
+	// An epoch block carries the next validator set.
+	_, next := testValidators(3, 2)
+	header = testHeader(t, config, 1000, next, secrets, 0b0111)
+	assert.NoError(t, testSolved(t, config, header, validators))
+	outputs, err = config.outputs(header, validators)
+	assert.NoError(t, err)
+	assert.Equal(t, ValidatorSetHash(next).Bytes(), outputs[40:])
+
+	// Two of four validators are not a quorum.
+	header = testHeader(t, config, 1001, nil, secrets, 0b0011)
+	assert.Error(t, testSolved(t, config, header, validators))
+
+	// The attestation is not by the validator set.
+	_, other := testValidators(4, 3)
+	header = testHeader(t, config, 1001, nil, secrets, 0b1011)
+	assert.Error(t, testSolved(t, config, header, other))
+}
@@ -0,0 +1,239 @@
+package bsc
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Circuit proves the attestation of the parent of a block by a validator set.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The header of the block, and the encoding of the validator set of the input right padded
+	// with zeros.
+	Header     eth.Header
+	Validators []vars.Byte
+
+	config     *Config     `gnark:"-"`
+	header     *eth.Header `gnark:"-"`
+	validators []byte      `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.Epoch == 0 || config.MaxValidators < 1 || config.MaxValidators > 64 {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	return &Circuit{
+		InputBytes:  vars.NewBytes(32 + 32),
+		OutputBytes: vars.NewBytes(8 + 32 + 32),
+		Header:      eth.NewHeader(config.MaxHeaderLength),
+		Validators:  vars.NewBytes(1 + ValidatorLength*config.MaxValidators),
+		config:      config,
+	}
+}
+
+// Sets the header and the validator set, encoded as in the extra data of epoch blocks, that the
+// next call to SetWitness assigns.
+func (c *Circuit) SetProofs(header *eth.Header, validators []byte) error {
+	if len(header.RLP) != len(c.Header.RLP) {
+		return fmt.Errorf("header max length %d, expected %d", len(header.RLP), len(c.Header.RLP))
+	}
+	if err := c.config.checkValidators(validators); err != nil {
+		return err
+	}
+	if _, err := c.config.outputs(header, validators); err != nil {
+		return err
+	}
+	c.header = header
+	c.validators = validators
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the header and validators given to SetProofs.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.header == nil {
+		panic("proofs must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.Header = *c.header
+	validators := make([]byte, len(c.Validators))
+	copy(validators, c.validators)
+	vars.SetBytes(&c.Validators, validators)
+	outputs, err := c.config.outputs(c.header, c.validators)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.OutputBytes, outputs)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	validatorSetHash := inputReader.ReadBytes32()
+	blockHash := inputReader.ReadBytes32()
+	maxValidators := c.config.MaxValidators
+
+	headerAPI := header.NewAPI(api)
+	headerAPI.Verify(c.Header)
+	api.AssertIsEqualBytes32(c.Header.Hash, blockHash)
+
+	// The validator set of the input, of between 1 and MaxValidators validators.
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(c.Validators); i++ {
+		rc.Check(c.Validators[i].Value.Value, 8)
+	}
+	nbValidators := c.Validators[0].Value
+	api.AssertIsDifferent(nbValidators, vars.NewVariableFromInt(0))
+	api.AssertIsLessOrEqual(nbValidators, vars.NewVariableFromInt(maxValidators))
+	validatorsLength := api.Add(vars.NewVariableFromInt(1), api.Mul(nbValidators, vars.NewVariableFromInt(ValidatorLength)))
+	digest := keccak256.HashVariable(*api, c.Validators, validatorsLength)
+	api.AssertIsEqualBytes32(digest, validatorSetHash)
+
+	// The extra data is the vanity, the validators of epoch blocks, the attestation and the seal,
+	// where the table is padded to allow reading the largest validator set from any position.
+	table := byteslice.NewTable(*api, c.Header.RLP, 32, len(c.Validators)+bls.G2Length+maxVoteDataLength)
+	extra := headerAPI.Extra(table)
+	isEpoch := c.isEpoch(*api, c.Header.Number)
+
+	// The next validator set if the block is an epoch block.
+	start := api.Add(extra.Offset, vars.NewVariableFromInt(extraVanity))
+	nbNext := api.Mul(isEpoch.Value, table.At(start, 0).Value)
+	api.AssertIsLessOrEqual(nbNext, vars.NewVariableFromInt(maxValidators))
+	nextLength := api.Add(api.Mul(nbNext, vars.NewVariableFromInt(ValidatorLength)), isEpoch.Value)
+	nextDigest := keccak256.HashVariable(*api, table.Read(start, len(c.Validators)), nextLength)
+	nextValidatorSetHash := api.SelectBytes32(isEpoch, nextDigest, validatorSetHash)
+	if c.config.TurnLength {
+		nextLength = api.Add(nextLength, isEpoch.Value)
+	}
+
+	// The attestation fills the extra data up to the seal.
+	attestation := rlp.ReadItem(*api, table, api.Add(start, nextLength))
+	api.AssertIsEqual(attestation.IsList.Value, vars.NewVariableFromInt(1))
+	api.AssertIsEqual(
+		api.Add(attestation.End, vars.NewVariableFromInt(extraSeal)),
+		api.Add(extra.Offset, extra.Length),
+	)
+	voteAddressSet := rlp.ReadItem(*api, table, attestation.Offset)
+	signature := rlp.ReadItem(*api, table, voteAddressSet.End)
+	api.AssertIsEqual(signature.IsList.Value, vars.NewVariableFromInt(0))
+	api.AssertIsEqual(signature.Length, vars.NewVariableFromInt(bls.G2Length))
+	data := rlp.ReadItem(*api, table, signature.End)
+	api.AssertIsEqual(data.IsList.Value, vars.NewVariableFromInt(1))
+	attestationExtra := rlp.ReadItem(*api, table, data.End)
+	api.AssertIsEqual(attestationExtra.End, attestation.End)
+
+	// The vote is for the parent of the block.
+	sourceNumber := rlp.ReadItem(*api, table, data.Offset)
+	sourceHash := rlp.ReadItem(*api, table, sourceNumber.End)
+	api.AssertIsEqual(sourceHash.Length, vars.NewVariableFromInt(32))
+	targetNumber := rlp.ReadItem(*api, table, sourceHash.End)
+	targetHash := rlp.ReadItem(*api, table, targetNumber.End)
+	api.AssertIsEqual(targetHash.IsList.Value, vars.NewVariableFromInt(0))
+	api.AssertIsEqual(targetHash.Length, vars.NewVariableFromInt(32))
+	api.AssertIsEqual(targetHash.End, data.End)
+	number := rlp.ReadUint64(*api, table, targetNumber)
+	api.AssertIsEqual(api.Add(number.Value, vars.NewVariableFromInt(1)), c.Header.Number.Value)
+	table.AssertEqualAt(targetHash.Offset, c.Header.ParentHash[:])
+
+	// More than two thirds of the validators sign the hash of the vote data.
+	bits := api.ToBinaryLE(rlp.ReadUint64(*api, table, voteAddressSet).Value, 64)
+	active := vars.NewVariableFromInt(1)
+	nbVotes := vars.NewVariableFromInt(0)
+	pubkeys := make([]sw_bls12381.G1Affine, maxValidators)
+	blsAPI := bls.NewAPI(api)
+	generator := generatorBytes()
+	for i := 0; i < 64; i++ {
+		if i >= maxValidators {
+			api.AssertIsEqual(bits[i].Value, vars.NewVariableFromInt(0))
+			continue
+		}
+		if i > 0 {
+			active = api.Sub(active, api.IsZero(api.Sub(nbValidators, vars.NewVariableFromInt(i))).Value)
+		}
+		api.AssertIsEqual(api.Mul(bits[i].Value, api.Sub(vars.NewVariableFromInt(1), active)), vars.NewVariableFromInt(0))
+		nbVotes = api.Add(nbVotes, bits[i].Value)
+
+		// The key of an unused slot is replaced by the generator, which is never selected.
+		var pubkey [bls.G1Length]vars.Byte
+		for j := 0; j < bls.G1Length; j++ {
+			b := c.Validators[1+ValidatorLength*i+20+j]
+			pubkey[j] = api.SelectByte(vars.Bool{Value: active}, b, generator[j])
+		}
+		pubkeys[i] = *blsAPI.DecompressG1(pubkey)
+	}
+	api.AssertIsLessOrEqual(
+		api.Mul(nbValidators, vars.NewVariableFromInt(2)),
+		api.Mul(nbVotes, vars.NewVariableFromInt(3)),
+	)
+	var sig [bls.G2Length]vars.Byte
+	copy(sig[:], table.Read(signature.Offset, bls.G2Length))
+	voteHash := keccak256.HashVariable(*api, table.Read(signature.End, maxVoteDataLength), api.Sub(data.End, signature.End))
+	pubkey := blsAPI.AggregatePubkeys(pubkeys, bits[:maxValidators])
+	blsAPI.Verify(pubkey, voteHash[:], blsAPI.DecompressG2(sig))
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteU64(number)
+	outputWriter.WriteBytes32(c.Header.ParentHash)
+	outputWriter.WriteBytes32(nextValidatorSetHash)
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Returns whether the number is a multiple of the epoch.
+func (c *Circuit) isEpoch(api builder.API, number vars.U64) vars.Bool {
+	epoch := vars.NewVariableFromInt(int(c.config.Epoch))
+	out := api.HintU64(divModHint, 2, number, vars.U64{Value: epoch})
+	api.AssertIsEqual(api.Add(api.Mul(out[0].Value, epoch), out[1].Value), number.Value)
+	api.ToBinaryLE(api.Sub(api.Sub(epoch, out[1].Value), vars.NewVariableFromInt(1)), 64)
+	return api.IsZero(out[1].Value)
+}
+
+// Returns the compressed generator of G1.
+func generatorBytes() []vars.Byte {
+	_, _, g1, _ := bls12381.Generators()
+	compressed := g1.Bytes()
+	return vars.NewBytesFrom(compressed[:])
+}
+
+// Returns the quotient and remainder of the division of a u64 by a positive u64.
+var divModHint = builder.NewHint("bsc.divmod", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 2 || len(outputs) != 2 {
+		return fmt.Errorf("divmod: invalid number of inputs or outputs")
+	}
+	if inputs[1].Sign() == 0 {
+		return fmt.Errorf("divmod: division by zero")
+	}
+	outputs[0].DivMod(inputs[0], inputs[1], outputs[1])
+	return nil
+})
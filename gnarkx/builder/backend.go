@@ -0,0 +1,47 @@
+package builder
+
+import (
+	"reflect"
+	"strings"
+)
+
+// The backend that a circuit is being compiled for.
+type Backend int
+
+const (
+	// The test engine of gnark, which solves the circuit without compiling it.
+	BackendTest Backend = iota
+
+	// The R1CS of Groth16.
+	BackendR1CS
+
+	// The sparse R1CS of PLONK.
+	BackendPLONK
+)
+
+// Returns the name of the backend.
+func (b Backend) String() string {
+	switch b {
+	case BackendR1CS:
+		return "r1cs"
+	case BackendPLONK:
+		return "plonk"
+	}
+	return "test"
+}
+
+// Returns the backend that the circuit is being compiled for, from the package of the builder of
+// gnark behind the API.
+func (a *API) Backend() Backend {
+	typ := reflect.TypeOf(a.api.Compiler())
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case strings.HasSuffix(typ.PkgPath(), "/frontend/cs/r1cs"):
+		return BackendR1CS
+	case strings.HasSuffix(typ.PkgPath(), "/frontend/cs/scs"):
+		return BackendPLONK
+	}
+	return BackendTest
+}
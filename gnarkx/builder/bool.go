@@ -24,6 +24,11 @@ func (a *API) Not(i1 vars.Bool) vars.Bool {
 	return vars.Bool{Value: a.Sub(vars.ONE, i1.Value)}
 }
 
+// Returns i1 if the selector is set and i2 otherwise.
+func (a *API) SelectBool(selector vars.Bool, i1, i2 vars.Bool) vars.Bool {
+	return vars.Bool{Value: a.Select(selector, i1.Value, i2.Value)}
+}
+
 // Decomposes a variable in the circuit into a number of bits with little-endian ordering. This
 // function can also be used for "range-checking", in other words checking that some value
 // is less than 2**n.
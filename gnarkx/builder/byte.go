@@ -1,6 +1,8 @@
 package builder
 
 import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
@@ -14,18 +16,127 @@ func (a *API) ToByteFromBits(i1 [8]vars.Bool) vars.Byte {
 	return vars.Byte{Value: value}
 }
 
+// Returns the integer of the big-endian bytes, such as a uint64 of an ABI word. The integer must be
+// less than the modulus of the scalar field, i.e. it must have at most 31 bytes on BN254.
+func (a *API) ToVariableFromBytesBE(i1 []vars.Byte) vars.Variable {
+	value := frontend.Variable(0)
+	for i := 0; i < len(i1); i++ {
+		value = a.api.Add(a.api.Mul(value, 256), i1[i].Value.Value)
+	}
+	return vars.Variable{Value: value}
+}
+
+// Returns the big-endian bytes of an element of the scalar field, such as a Poseidon root written
+// to the output as a bytes32.
+func (a *API) ToBytes32FromVariableBE(i1 vars.Variable) [32]vars.Byte {
+	bits := a.ToBinaryLE(i1, 256)
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[8*(31-i):8*(32-i)])
+		out[i] = a.ToByteFromBits(byteBits)
+	}
+	return out
+}
+
+// Asserts that a byte is in [0, 256). With batched range checks, the check is deferred to the
+// lookup of all the checks of the circuit.
+func (a *API) AssertIsByte(i1 vars.Byte) {
+	a.assertIsInRange(i1.Value, 8)
+}
+
+// Asserts that a variable is less than 2^nbBits, by decomposing it into bits or, with batched
+// range checks, by the range checker of gnark, which is shared by the whole circuit.
+func (a *API) assertIsInRange(i1 vars.Variable, nbBits int) {
+	if a.batchedRangeChecks {
+		rangecheck.New(a.api).Check(i1.Value, nbBits)
+		return
+	}
+	a.ToBinaryLE(i1, nbBits)
+}
+
 func (a *API) SelectByte(selector vars.Bool, i1 vars.Byte, i2 vars.Byte) vars.Byte {
 	return vars.Byte{Value: a.Select(selector, i1.Value, i2.Value)}
 }
 
-func (a *API) SelectBytes32(selector vars.Bool, i1 [32]vars.Byte, i2 [32]vars.Byte) [32]vars.Byte {
-	var result [32]vars.Byte
+// Returns i1 if the selector is set and i2 otherwise, byte by byte.
+func (a *API) SelectBytes32(selector vars.Bool, i1 vars.Bytes32, i2 vars.Bytes32) vars.Bytes32 {
+	var result vars.Bytes32
 	for i := 0; i < 32; i++ {
 		result[i] = a.SelectByte(selector, i1[i], i2[i])
 	}
 	return result
 }
 
+// Returns i1 if the selector is set and i2 otherwise, byte by byte. The slices must have the same
+// length.
+func (a *API) SelectBytes(selector vars.Bool, i1 []vars.Byte, i2 []vars.Byte) []vars.Byte {
+	if len(i1) != len(i2) {
+		panic("selecting between byte slices of different lengths")
+	}
+	result := make([]vars.Byte, len(i1))
+	for i := range i1 {
+		result[i] = a.SelectByte(selector, i1[i], i2[i])
+	}
+	return result
+}
+
+// Returns in[index], asserting that the index is less than len(in).
+func (a *API) MuxByte(index vars.Variable, in ...vars.Byte) vars.Byte {
+	values := make([]vars.Variable, len(in))
+	for i := range in {
+		values[i] = in[i].Value
+	}
+	return vars.Byte{Value: a.Mux(index, values...)}
+}
+
 func (a *API) AssertIsEqualByte(i1, i2 vars.Byte) {
 	a.AssertIsEqual(i1.Value, i2.Value)
 }
+
+// Asserts that two bytes32 are equal.
+func (a *API) AssertIsEqualBytes32(i1, i2 vars.Bytes32) {
+	for i := 0; i < 32; i++ {
+		a.AssertIsEqualByte(i1[i], i2[i])
+	}
+}
+
+// Returns whether two bytes32 are equal.
+func (a *API) IsEqualBytes32(i1, i2 vars.Bytes32) vars.Bool {
+	result := vars.TRUE
+	for i := 0; i < 32; i++ {
+		result = a.And(result, a.IsZero(a.Sub(i1[i].Value, i2[i].Value)))
+	}
+	return result
+}
+
+// Packs a digest into two elements, the big-endian integers of its first and last 16 bytes, ie.
+// uint256(digest) >> 128 and uint128(uint256(digest)) in Solidity, so that verifier contracts and
+// recursive circuits take two public inputs rather than 32. Both are less than 2^128, so they are
+// canonical elements of the field. The bytes are assumed to be range checked.
+func (a *API) PackDigest(digest vars.Bytes32) [2]vars.Variable {
+	var result [2]vars.Variable
+	for i := 0; i < 2; i++ {
+		value := vars.ZERO
+		for j := 0; j < 16; j++ {
+			value = a.Add(a.Mul(value, vars.NewVariableFromInt(256)), digest[16*i+j].Value)
+		}
+		result[i] = value
+	}
+	return result
+}
+
+// Unpacks the two elements of PackDigest into the digest, which also checks that both are less than
+// 2^128.
+func (a *API) UnpackDigest(packed [2]vars.Variable) vars.Bytes32 {
+	var result vars.Bytes32
+	for i := 0; i < 2; i++ {
+		bits := a.ToBinaryLE(packed[i], 128)
+		for j := 0; j < 16; j++ {
+			var byteBits [8]vars.Bool
+			copy(byteBits[:], bits[8*j:8*(j+1)])
+			result[16*i+15-j] = a.ToByteFromBits(byteBits)
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestBytes32Circuit struct {
+	A, B     vars.Bytes32
+	Selector vars.Bool
+	Selected vars.Bytes32
+	IsEqual  vars.Bool
+	Packed   [2]vars.Variable
+}
+
+func (c *TestBytes32Circuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	api.AssertIsEqualBytes32(api.SelectBytes32(c.Selector, c.A, c.B), c.Selected)
+	api.AssertIsEqualBool(api.IsEqualBytes32(c.A, c.B), c.IsEqual)
+	packed := api.PackDigest(c.A)
+	api.AssertIsEqual(packed[0], c.Packed[0])
+	api.AssertIsEqual(packed[1], c.Packed[1])
+	api.AssertIsEqual(api.ToVariableFromBytesBE(c.A[:16]), c.Packed[0])
+	low := c.A
+	for i := 0; i < 16; i++ {
+		low[i] = vars.Byte{Value: vars.ZERO}
+	}
+	api.AssertIsEqualBytes32(api.ToBytes32FromVariableBE(c.Packed[1]), low)
+	api.AssertIsEqualBytes32(api.UnpackDigest(c.Packed), c.A)
+	return nil
+}
+
+func TestBytes32(t *testing.T) {
+	var a, b [32]byte
+	for i := 0; i < 32; i++ {
+		a[i] = byte(255 - i)
+		b[i] = byte(i)
+	}
+	for _, testCase := range []struct {
+		b        [32]byte
+		selector bool
+	}{{b, true}, {b, false}, {a, false}} {
+		var witness TestBytes32Circuit
+		vars.SetBytes32(&witness.A, a)
+		vars.SetBytes32(&witness.B, testCase.b)
+		witness.Selector = vars.NewBool(testCase.selector)
+		selected := testCase.b
+		if testCase.selector {
+			selected = a
+		}
+		vars.SetBytes32(&witness.Selected, selected)
+		witness.IsEqual = vars.NewBool(a == testCase.b)
+		witness.Packed[0].Set(new(big.Int).SetBytes(a[:16]))
+		witness.Packed[1].Set(new(big.Int).SetBytes(a[16:]))
+		assert.NoError(t, test.IsSolved(&TestBytes32Circuit{}, &witness, ecc.BN254.ScalarField()))
+
+		// The packed elements are unpacked to the digest.
+		witness.Packed[1].Set(new(big.Int).SetBytes(b[16:]))
+		assert.Error(t, test.IsSolved(&TestBytes32Circuit{}, &witness, ecc.BN254.ScalarField()))
+	}
+}
+
+type TestAssertIsByteCircuit struct {
+	Batched bool `gnark:"-"`
+	In      []vars.Byte
+}
+
+func (c *TestAssertIsByteCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	if c.Batched {
+		api = NewAPI(baseAPI, WithBatchedRangeChecks())
+	}
+	for i := range c.In {
+		api.AssertIsByte(c.In[i])
+	}
+	return nil
+}
+
+func TestAssertIsByte(t *testing.T) {
+	for _, batched := range []bool{false, true} {
+		witness := TestAssertIsByteCircuit{In: vars.NewBytesFrom([]byte{0, 1, 128, 255})}
+		circuit := &TestAssertIsByteCircuit{Batched: batched, In: make([]vars.Byte, 4)}
+		assert.NoError(t, test.IsSolved(circuit, &witness, ecc.BN254.ScalarField()))
+
+		witness.In[2] = vars.Byte{Value: vars.NewVariableFromInt(256)}
+		assert.Error(t, test.IsSolved(circuit, &witness, ecc.BN254.ScalarField()))
+	}
+
+	compile := func(batched bool, n int) int {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &TestAssertIsByteCircuit{Batched: batched, In: make([]vars.Byte, n)})
+		assert.NoError(t, err)
+		return cs.GetNbConstraints()
+	}
+
+	// Past the fixed cost of the table, batched checks take fewer constraints than bits.
+	bitsCost := compile(false, 1024) - compile(false, 1)
+	batchedCost := compile(true, 1024) - compile(true, 1)
+	assert.Less(t, 2*batchedCost, bitsCost)
+}
@@ -0,0 +1,38 @@
+package builder
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Returns the scalar field that the circuit is compiled over.
+func (a *API) Field() *big.Int {
+	return a.api.Compiler().Field()
+}
+
+// Returns the number of bits of the elements of the scalar field.
+func (a *API) FieldBitLen() int {
+	return a.api.Compiler().FieldBitLen()
+}
+
+// Returns the curve whose scalar field the circuit is compiled over, or ecc.UNKNOWN.
+func (a *API) Curve() ecc.ID {
+	return CurveOf(a.Field())
+}
+
+// Returns the number of bytes that every element of the scalar field can hold, which is 31 for
+// BN254, BLS12-377 and BLS12-381 and 47 for BW6-761.
+func (a *API) BytesPerElement() int {
+	return (a.FieldBitLen() - 1) / 8
+}
+
+// Returns the curve of the scalar field, or ecc.UNKNOWN.
+func CurveOf(field *big.Int) ecc.ID {
+	for _, curve := range ecc.Implemented() {
+		if curve.ScalarField().Cmp(field) == 0 {
+			return curve
+		}
+	}
+	return ecc.UNKNOWN
+}
@@ -0,0 +1,32 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFieldCircuit struct {
+	X     frontend.Variable
+	curve ecc.ID `gnark:"-"`
+	bytes int    `gnark:"-"`
+}
+
+func (c *testFieldCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	if api.Curve() != c.curve || api.BytesPerElement() != c.bytes || api.Field().Cmp(c.curve.ScalarField()) != 0 {
+		panic("unexpected field")
+	}
+	return nil
+}
+
+func TestField(t *testing.T) {
+	for curve, bytes := range map[ecc.ID]int{ecc.BN254: 31, ecc.BLS12_377: 31, ecc.BLS12_381: 31, ecc.BW6_761: 47} {
+		circuit := &testFieldCircuit{curve: curve, bytes: bytes}
+		assert.NoError(t, test.IsSolved(circuit, &testFieldCircuit{X: 0}, curve.ScalarField()))
+	}
+	assert.Equal(t, ecc.UNKNOWN, CurveOf(ecc.BN254.BaseField()))
+}
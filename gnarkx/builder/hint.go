@@ -0,0 +1,147 @@
+package builder
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	hints   = make(map[solver.HintID]*Hint)
+	hintsMu sync.RWMutex
+)
+
+// A Hint is a function computed outside of the circuit whose results are provided to the circuit
+// as unconstrained witness values. Hints are identified by name (rather than by the Go function
+// pointer, as raw gnark hints are) so that closures can safely be used as hints.
+//
+// Hints are registered with the gnark solver when they are created, so circuits do not need to
+// pass them to the prover explicitly.
+type Hint struct {
+	name string
+	id   solver.HintID
+	fn   solver.Hint
+}
+
+// Creates and registers a new hint from a raw gnark hint function. Panics if a hint with the same
+// name was already registered.
+func NewHint(name string, fn solver.Hint) *Hint {
+	hf := fnv.New32a()
+	hf.Write([]byte("succinctx/" + name))
+	h := &Hint{name: name, id: solver.HintID(hf.Sum32()), fn: fn}
+
+	hintsMu.Lock()
+	defer hintsMu.Unlock()
+	if _, ok := hints[h.id]; ok {
+		panic(fmt.Sprintf("hint %q already registered", name))
+	}
+	solver.RegisterNamedHint(fn, h.id)
+	hints[h.id] = h
+	return h
+}
+
+// Creates and registers a new hint operating on bytes. The function receives the input bytes and
+// the number of expected output bytes, and must return exactly that many bytes.
+func NewBytesHint(name string, fn func(in []byte, nbOutputs int) ([]byte, error)) *Hint {
+	return NewHint(name, func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+		in := make([]byte, len(inputs))
+		for i := 0; i < len(inputs); i++ {
+			if !inputs[i].IsUint64() || inputs[i].Uint64() > 255 {
+				return fmt.Errorf("hint %s: input %d is not a byte", name, i)
+			}
+			in[i] = byte(inputs[i].Uint64())
+		}
+		out, err := fn(in, len(outputs))
+		if err != nil {
+			return err
+		}
+		if len(out) != len(outputs) {
+			return fmt.Errorf("hint %s: returned %d bytes, expected %d", name, len(out), len(outputs))
+		}
+		for i := 0; i < len(out); i++ {
+			outputs[i].SetUint64(uint64(out[i]))
+		}
+		return nil
+	})
+}
+
+// Returns the name of the hint.
+func (h *Hint) Name() string {
+	return h.name
+}
+
+// Returns the id of the hint in the gnark solver registry.
+func (h *Hint) ID() solver.HintID {
+	return h.id
+}
+
+// Returns all hints created through this package. This is useful for passing hints explicitly to
+// a solver, e.g. with solver.WithHints(builder.RegisteredHints()...) on a remote prover.
+func RegisteredHints() []solver.Hint {
+	hintsMu.RLock()
+	defer hintsMu.RUnlock()
+	result := make([]solver.Hint, 0, len(hints))
+	for _, h := range hints {
+		result = append(result, h.fn)
+	}
+	return result
+}
+
+func (a *API) hint(h *Hint, nbOutputs int, in []frontend.Variable) []frontend.Variable {
+	out, err := a.api.Compiler().NewHintForId(h.id, nbOutputs, in...)
+	if err != nil {
+		panic(fmt.Sprintf("hint %s: %v", h.name, err))
+	}
+	return out
+}
+
+// Calls a hint on variables. The outputs are not constrained in any way.
+func (a *API) HintVariables(h *Hint, nbOutputs int, in ...vars.Variable) []vars.Variable {
+	fvars := make([]frontend.Variable, len(in))
+	for i := 0; i < len(in); i++ {
+		fvars[i] = in[i].Value
+	}
+	out := a.hint(h, nbOutputs, fvars)
+	result := make([]vars.Variable, nbOutputs)
+	for i := 0; i < nbOutputs; i++ {
+		result[i] = vars.Variable{Value: out[i]}
+	}
+	return result
+}
+
+// Calls a hint on bytes. The outputs are range checked to be bytes but are otherwise not
+// constrained.
+func (a *API) HintBytes(h *Hint, nbOutputs int, in ...vars.Byte) []vars.Byte {
+	fvars := make([]frontend.Variable, len(in))
+	for i := 0; i < len(in); i++ {
+		fvars[i] = in[i].Value.Value
+	}
+	out := a.hint(h, nbOutputs, fvars)
+	result := make([]vars.Byte, nbOutputs)
+	for i := 0; i < nbOutputs; i++ {
+		result[i] = vars.Byte{Value: vars.Variable{Value: out[i]}}
+		a.AssertIsByte(result[i])
+	}
+	return result
+}
+
+// Calls a hint on u64s. The outputs are range checked to be in [0, 2^64) but are otherwise not
+// constrained.
+func (a *API) HintU64(h *Hint, nbOutputs int, in ...vars.U64) []vars.U64 {
+	fvars := make([]frontend.Variable, len(in))
+	for i := 0; i < len(in); i++ {
+		fvars[i] = in[i].Value.Value
+	}
+	out := a.hint(h, nbOutputs, fvars)
+	result := make([]vars.U64, nbOutputs)
+	for i := 0; i < nbOutputs; i++ {
+		result[i] = vars.U64{Value: vars.Variable{Value: out[i]}}
+		a.assertIsInRange(result[i].Value, 64)
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var reverseHint = NewBytesHint("test/reverse", func(in []byte, nbOutputs int) ([]byte, error) {
+	out := make([]byte, nbOutputs)
+	for i := 0; i < nbOutputs; i++ {
+		out[i] = in[len(in)-1-i]
+	}
+	return out, nil
+})
+
+type TestHintCircuit struct {
+	In  [4]vars.Byte
+	Out [4]vars.Byte
+}
+
+func (c *TestHintCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	out := api.HintBytes(reverseHint, 4, c.In[:]...)
+	for i := 0; i < 4; i++ {
+		api.AssertIsEqualByte(out[i], c.Out[i])
+		api.AssertIsEqualByte(out[i], c.In[3-i])
+	}
+	return nil
+}
+
+func TestHintBytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := TestHintCircuit{}
+	witness := TestHintCircuit{}
+	for i := 0; i < 4; i++ {
+		witness.In[i].Set(byte(i + 1))
+		witness.Out[i].Set(byte(4 - i))
+	}
+	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16), test.NoFuzzing())
+}
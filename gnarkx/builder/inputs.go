@@ -1,11 +1,14 @@
 package builder
 
 import (
+	"fmt"
+
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
 // InputReader is used for reading inputs into a circuit that were provided at the time of the
 // request, either on-chain or off-chain.
+// The bytes are not range checked by the reader, as the commitment of the inputs checks them.
 type InputReader struct {
 	api   API
 	ptr   int
@@ -28,6 +31,15 @@ func (r *InputReader) readByte() vars.Byte {
 	return out
 }
 
+// Reads n bytes from the input stream.
+func (r *InputReader) ReadBytes(n int) []vars.Byte {
+	out := make([]vars.Byte, n)
+	for i := 0; i < n; i++ {
+		out[i] = r.readByte()
+	}
+	return out
+}
+
 // Reads a byte32 from the input stream.
 func (r *InputReader) ReadBytes32() [32]vars.Byte {
 	var out [32]vars.Byte
@@ -47,3 +59,61 @@ func (r *InputReader) ReadUint64() vars.U64 {
 	}
 	return out
 }
+
+// Reads an address from the input stream.
+func (r *InputReader) ReadAddress() [20]vars.Byte {
+	var out [20]vars.Byte
+	for i := 0; i < 20; i++ {
+		out[i] = r.readByte()
+	}
+	return out
+}
+
+// Reads an array of n bits from the input stream, with the bits of each byte in big-endian order
+// as in a Solidity bytes. n must be a multiple of 8.
+func (r *InputReader) ReadBitArray(n int) []vars.Bool {
+	if n%8 != 0 {
+		panic("the number of bits must be a multiple of 8")
+	}
+	out := make([]vars.Bool, 0, n)
+	for i := 0; i < n/8; i++ {
+		bits := r.api.ToBitsFromByte(r.readByte())
+		for j := 7; j >= 0; j-- {
+			out = append(out, bits[j])
+		}
+	}
+	return out
+}
+
+// Reads bytes of up to maxLength bytes from the input stream, as written by WriteVariableBytes: their
+// length as a big-endian uint64 followed by maxLength bytes, whose bytes past the length must be
+// zero.
+func (r *InputReader) ReadVariableBytes(maxLength int) vars.VariableBytes {
+	length := r.ReadUint64()
+	data := r.ReadBytes(maxLength)
+	masked := maskVariableBytes(r.api, vars.VariableBytes{Data: data, Length: length.Value})
+	for i := range data {
+		r.api.AssertIsEqualByte(masked[i], data[i])
+	}
+	return vars.VariableBytes{Data: data, Length: length.Value}
+}
+
+// Returns the data of the bytes with the bytes past their length set to zero, asserting that the
+// length is at most the maximum length, as byteslice.Mask, which imports this package.
+func maskVariableBytes(api API, in vars.VariableBytes) []vars.Byte {
+	api.AssertIsLessOrEqual(in.Length, vars.NewVariableFromInt(len(in.Data)))
+	data := make([]vars.Byte, len(in.Data))
+	isActive := vars.NewVariableFromInt(1)
+	for i := range in.Data {
+		isActive = api.Sub(isActive, api.IsZero(api.Sub(in.Length, vars.NewVariableFromInt(i))).Value)
+		data[i] = vars.Byte{Value: api.Mul(isActive, in.Data[i].Value)}
+	}
+	return data
+}
+
+// Checks that the whole input stream was read, which is known at compile time.
+func (r *InputReader) Close() {
+	if r.ptr != len(r.bytes) {
+		panic(fmt.Sprintf("read %d of the %d input bytes", r.ptr, len(r.bytes)))
+	}
+}
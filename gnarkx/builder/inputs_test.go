@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestInputsCircuit struct {
+	In      []vars.Byte
+	Number  vars.U64
+	Hash    [32]vars.Byte
+	Address [20]vars.Byte
+	Bits    [16]vars.Bool
+	Data    [3]vars.Byte
+}
+
+func (c *TestInputsCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	r := NewInputReader(*api, c.In)
+	api.AssertIsEqual(r.ReadUint64().Value, c.Number.Value)
+	hash := r.ReadBytes32()
+	address := r.ReadAddress()
+	bits := r.ReadBitArray(16)
+	data := r.ReadBytes(3)
+	r.Close()
+	for i := range hash {
+		api.AssertIsEqualByte(hash[i], c.Hash[i])
+	}
+	for i := range address {
+		api.AssertIsEqualByte(address[i], c.Address[i])
+	}
+	for i := range bits {
+		api.AssertIsEqual(bits[i].Value, c.Bits[i].Value)
+	}
+	for i := range data {
+		api.AssertIsEqualByte(data[i], c.Data[i])
+	}
+	return nil
+}
+
+func TestInputReader(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	number := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	hash := common.HexToHash("0xaa")
+	address := common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa")
+	in := append(append(append(number, hash.Bytes()...), address.Bytes()...), 0x80, 0x05, 'a', 'b', 'c')
+
+	circuit := TestInputsCircuit{In: vars.NewBytes(len(in))}
+	witness := TestInputsCircuit{In: vars.NewBytesFrom(in)}
+	witness.Number.Set(0x0102030405060708)
+	vars.SetBytes32(&witness.Hash, hash)
+	for i := range address {
+		witness.Address[i].Set(address[i])
+	}
+	for i := range witness.Bits {
+		witness.Bits[i] = vars.Bool{Value: vars.ZERO}
+	}
+	witness.Bits[0] = vars.Bool{Value: vars.ONE}
+	witness.Bits[13] = vars.Bool{Value: vars.ONE}
+	witness.Bits[15] = vars.Bool{Value: vars.ONE}
+	for i, b := range []byte("abc") {
+		witness.Data[i].Set(b)
+	}
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	witness.In[len(in)-1].Set(0)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+
+	// The input must be read entirely.
+	circuit.In = vars.NewBytes(len(in) + 1)
+	witness.In = vars.NewBytesFrom(append(in, 0))
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+type TestVariableInputsCircuit struct {
+	In   []vars.Byte
+	Data vars.VariableBytes
+}
+
+func (c *TestVariableInputsCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	r := NewInputReader(*api, c.In)
+	data := r.ReadVariableBytes(len(c.Data.Data))
+	r.Close()
+	api.AssertIsEqual(data.Length, c.Data.Length)
+	for i := range data.Data {
+		api.AssertIsEqualByte(data.Data[i], c.Data.Data[i])
+	}
+	return nil
+}
+
+func TestReadVariableBytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const maxLength = 8
+	in := []byte{0, 0, 0, 0, 0, 0, 0, 3, 'a', 'b', 'c', 0, 0, 0, 0, 0}
+	circuit := TestVariableInputsCircuit{In: vars.NewBytes(len(in)), Data: vars.NewVariableBytes(maxLength)}
+	witness := TestVariableInputsCircuit{In: vars.NewBytesFrom(in), Data: vars.NewVariableBytes(maxLength)}
+	vars.SetVariableBytes(&witness.Data, []byte("abc"))
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// The bytes past the length must be zero.
+	witness.In[len(in)-1].Set(1)
+	witness.Data.Data[maxLength-1].Set(1)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+
+	// The length must be at most the maximum length.
+	in[7] = maxLength + 1
+	witness.In = vars.NewBytesFrom(in)
+	witness.Data.Data[maxLength-1].Set(0)
+	witness.Data.Length = vars.NewVariableFromInt(maxLength + 1)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
@@ -7,10 +7,32 @@ import (
 // OutputWriter is used for writing outputs from a circuit that need to be read on-chain. In
 // particular, the struct is used for writing to a a list of output bytes which is then hashed
 // to produce a commitment to the outputs of the circuit.
+//
+// By default, the values are packed as abi.encodePacked. A writer created by NewABIOutputWriter
+// lays them out as abi.encode of the values in the order they are written instead, so that the
+// callback can abi.decode the output: every static value takes a word of the head, and every
+// dynamic value takes a word of the head with the offset of its encoding in the tail.
+//
+// A writer created by NewChunkedOutputWriter commits to large outputs with a single bytes32
+// instead: the packed values are split into chunks of a fixed size, and the output is the head of
+// a hash chain of their digests (see NextOutputChunkHead), so that a consumer of the output can
+// verify any chunk against the chain independently of the others.
 type OutputWriter struct {
 	api   API
 	ptr   int
 	bytes []vars.Byte
+
+	// In ABI mode, the tail of the dynamic values and, for the offset words of the head, their
+	// positions in bytes and the offsets of the values in the tail.
+	abi      bool
+	tail     []vars.Byte
+	pointers [][2]int
+
+	// In chunked mode, the size of the chunks, the hash of the digests and of the chain and the
+	// head of the chain of the chunks that are already finalized.
+	chunkSize int
+	hash      func(api API, in []vars.Byte) [32]vars.Byte
+	head      [32]vars.Byte
 }
 
 // Creates a new OutputWriter.
@@ -22,25 +44,235 @@ func NewOutputWriter(api API) *OutputWriter {
 	}
 }
 
-// Writes a single u64 to the output stream.
+// Creates a new OutputWriter that lays out the values as abi.encode.
+func NewABIOutputWriter(api API) *OutputWriter {
+	w := NewOutputWriter(api)
+	w.abi = true
+	return w
+}
+
+// Creates a new OutputWriter that finalizes the digest of every chunkSize bytes of the packed
+// values into a hash chain with hash, e.g. sha256.Hash, and whose output is the final head of the
+// chain.
+func NewChunkedOutputWriter(api API, chunkSize int, hash func(api API, in []vars.Byte) [32]vars.Byte) *OutputWriter {
+	if chunkSize <= 0 {
+		panic("chunk size must be positive")
+	}
+	w := NewOutputWriter(api)
+	w.chunkSize = chunkSize
+	w.hash = hash
+	for i := range w.head {
+		w.head[i] = vars.Byte{Value: vars.ZERO}
+	}
+	return w
+}
+
+// Writes a single u64 to the output stream, as a uint64.
 func (w *OutputWriter) WriteU64(i1 vars.U64) {
 	bytes := w.api.ToBytes32FromU64LE(i1)
+	if w.abi {
+		w.writeZeros(24)
+	}
 	for i := 0; i < 8; i++ {
 		w.bytes = append(w.bytes, bytes[8-i-1])
 	}
+	w.finalizeChunks()
+}
+
+// Writes a single byte to the output stream, as a uint8.
+func (w *OutputWriter) WriteU8(b vars.Byte) {
+	if w.abi {
+		w.writeZeros(31)
+	}
+	w.bytes = append(w.bytes, b)
+	w.finalizeChunks()
 }
 
+// Writes a bool to the output stream, as a byte that is 0 or 1.
+func (w *OutputWriter) WriteBool(b vars.Bool) {
+	w.WriteU8(vars.Byte{Value: b.Value})
+}
+
+// Writes a bytes32 to the output stream.
 func (w *OutputWriter) WriteBytes32(bytes [32]vars.Byte) {
 	for i := 0; i < 32; i++ {
 		w.bytes = append(w.bytes, bytes[i])
 	}
+	w.finalizeChunks()
+}
+
+// Writes an address to the output stream.
+func (w *OutputWriter) WriteAddress(address [20]vars.Byte) {
+	if w.abi {
+		w.writeZeros(12)
+	}
+	w.bytes = append(w.bytes, address[:]...)
+	w.finalizeChunks()
+}
+
+// Writes a bytes of constant length to the output stream.
+func (w *OutputWriter) WriteBytes(bytes []vars.Byte) {
+	if !w.abi {
+		w.bytes = append(w.bytes, bytes...)
+		w.finalizeChunks()
+		return
+	}
+	w.writePointer()
+	w.tail = append(w.tail, constantWord(len(bytes))...)
+	w.tail = append(w.tail, bytes...)
+	for i := len(bytes); i%32 != 0; i++ {
+		w.tail = append(w.tail, vars.Byte{Value: vars.ZERO})
+	}
+}
+
+// Writes a bytes of constant length to the output stream prefixed by its length, as a uint64 when
+// the values are packed. The ABI encoding of a bytes is always prefixed by its length, so this is
+// the same as WriteBytes in ABI mode.
+func (w *OutputWriter) WriteBytesWithLength(bytes []vars.Byte) {
+	if !w.abi {
+		var length vars.U64
+		length.Set(uint64(len(bytes)))
+		w.WriteU64(length)
+	}
+	w.WriteBytes(bytes)
+}
+
+// Writes bytes whose length is only known at proving time to the output stream, prefixed by their
+// length as a uint64 and padded with zeros to their maximum length, so that the number of output
+// bytes is a compile time constant. In ABI mode, they are a bytes whose length word is the length
+// and whose tail is padded to the maximum length, which abi.decode ignores.
+func (w *OutputWriter) WriteVariableBytes(bytes vars.VariableBytes) {
+	data := maskVariableBytes(w.api, bytes)
+	if !w.abi {
+		w.WriteU64(vars.U64{Value: bytes.Length})
+		w.bytes = append(w.bytes, data...)
+		w.finalizeChunks()
+		return
+	}
+	w.writePointer()
+	length := w.api.ToBytes32FromU64LE(vars.U64{Value: bytes.Length})
+	for i := 0; i < 24; i++ {
+		w.tail = append(w.tail, vars.Byte{Value: vars.ZERO})
+	}
+	for i := 0; i < 8; i++ {
+		w.tail = append(w.tail, length[8-i-1])
+	}
+	w.tail = append(w.tail, data...)
+	for i := len(data); i%32 != 0; i++ {
+		w.tail = append(w.tail, vars.Byte{Value: vars.ZERO})
+	}
+}
+
+// Writes a bytes32[] of constant length to the output stream.
+func (w *OutputWriter) WriteBytes32Array(array [][32]vars.Byte) {
+	if !w.abi {
+		for i := range array {
+			w.WriteBytes32(array[i])
+		}
+		return
+	}
+	w.writePointer()
+	w.tail = append(w.tail, constantWord(len(array))...)
+	for i := range array {
+		w.tail = append(w.tail, array[i][:]...)
+	}
+}
+
+// Finalizes the complete chunks of the buffered bytes into the chain in chunked mode.
+func (w *OutputWriter) finalizeChunks() {
+	for w.hash != nil && len(w.bytes) >= w.chunkSize {
+		w.finalizeChunk(w.bytes[:w.chunkSize])
+		w.bytes = w.bytes[w.chunkSize:]
+	}
+}
+
+// Sets the head of the chain to hash(head || hash(chunk)).
+func (w *OutputWriter) finalizeChunk(chunk []vars.Byte) {
+	digest := w.hash(w.api, chunk)
+	w.head = w.hash(w.api, append(append([]vars.Byte{}, w.head[:]...), digest[:]...))
+}
+
+func (w *OutputWriter) writeZeros(n int) {
+	for i := 0; i < n; i++ {
+		w.bytes = append(w.bytes, vars.Byte{Value: vars.ZERO})
+	}
+}
+
+// Writes the word of the head of a dynamic value, whose offset is only known once the head is
+// complete, and which starts at the end of the tail.
+func (w *OutputWriter) writePointer() {
+	w.pointers = append(w.pointers, [2]int{len(w.bytes), len(w.tail)})
+	w.writeZeros(32)
 }
 
+// Returns the big-endian word of a constant.
+func constantWord(n int) []vars.Byte {
+	word := make([]vars.Byte, 32)
+	for i := 31; i >= 0; i-- {
+		word[i] = vars.Byte{Value: vars.NewVariableFromInt(n & 0xff)}
+		n >>= 8
+	}
+	return word
+}
+
+// Returns the output bytes. In chunked mode, the last chunk may be shorter than the others, and
+// the output is the bytes32 of the final head of the chain.
+func (w *OutputWriter) output() []vars.Byte {
+	if w.hash != nil {
+		if len(w.bytes) > 0 {
+			w.finalizeChunk(w.bytes)
+			w.bytes = nil
+		}
+		return w.head[:]
+	}
+	if !w.abi {
+		return w.bytes
+	}
+	bytes := append(append([]vars.Byte{}, w.bytes...), w.tail...)
+	for _, pointer := range w.pointers {
+		copy(bytes[pointer[0]:pointer[0]+32], constantWord(len(w.bytes)+pointer[1]))
+	}
+	return bytes
+}
+
+// Asserts that the output bytes are expectedBytes.
 func (w *OutputWriter) Close(expectedBytes []vars.Byte) {
-	if len(w.bytes) != len(expectedBytes) {
+	bytes := w.output()
+	if len(bytes) != len(expectedBytes) {
 		panic("unexpected number of output bytes")
 	}
-	for i := 0; i < len(w.bytes); i++ {
-		w.api.AssertIsEqualByte(w.bytes[i], expectedBytes[i])
+	for i := 0; i < len(bytes); i++ {
+		w.api.AssertIsEqualByte(bytes[i], expectedBytes[i])
+	}
+}
+
+// Asserts that the commitment of the output bytes is outputHash, for circuits whose public input
+// is the hash of their outputs rather than the outputs. The commitment is e.g.
+// succinct.SHA256Commitment{}.Commit, the convention of the gateway contracts.
+func (w *OutputWriter) Commit(outputHash vars.Variable, commit func(api API, bytes []vars.Byte) vars.Variable) {
+	w.api.AssertIsEqual(commit(w.api, w.output()), outputHash)
+}
+
+// Returns the head of the chain of an output of a chunked OutputWriter after the chunk, which is
+// hash(head || hash(chunk)), where the chain starts with 32 zero bytes. A consumer verifies a chunk
+// against the heads before and after it, and the last head is the output of the circuit.
+func NextOutputChunkHead(head [32]byte, chunk []byte, hash func(in []byte) [32]byte) [32]byte {
+	digest := hash(chunk)
+	return hash(append(append([]byte{}, head[:]...), digest[:]...))
+}
+
+// Returns the heads of the chain of an output of a chunked OutputWriter after each of its chunks,
+// outside of the circuit.
+func OutputChunkHeads(data []byte, chunkSize int, hash func(in []byte) [32]byte) [][32]byte {
+	var heads [][32]byte
+	var head [32]byte
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		head = NextOutputChunkHead(head, data[i:end], hash)
+		heads = append(heads, head)
 	}
+	return heads
 }
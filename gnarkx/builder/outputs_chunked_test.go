@@ -0,0 +1,60 @@
+package builder_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	sha256gadget "github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testChunkedOutputsCircuit struct {
+	Hash      [32]vars.Byte
+	Data      []vars.Byte
+	Out       [32]vars.Byte
+	chunkSize int `gnark:"-"`
+}
+
+func (c *testChunkedOutputsCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	w := builder.NewChunkedOutputWriter(*api, c.chunkSize, sha256gadget.Hash)
+	w.WriteBytes32(c.Hash)
+	w.WriteBytes(c.Data)
+	w.Close(c.Out[:])
+	return nil
+}
+
+func TestChunkedOutputWriter(t *testing.T) {
+	data := make([]byte, 70)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	output := append(make([]byte, 32), data...)
+	output[0] = 0xaa
+
+	// The chunks are finalized across the written values, with a shorter last chunk.
+	for _, chunkSize := range []int{40, 51, 102} {
+		heads := builder.OutputChunkHeads(output, chunkSize, sha256.Sum256)
+		assert.Len(t, heads, (len(output)+chunkSize-1)/chunkSize)
+		// The first chunk is verified against the initial head.
+		first := output
+		if len(first) > chunkSize {
+			first = first[:chunkSize]
+		}
+		assert.Equal(t, heads[0], builder.NextOutputChunkHead([32]byte{}, first, sha256.Sum256))
+
+		circuit := testChunkedOutputsCircuit{Data: vars.NewBytes(len(data)), chunkSize: chunkSize}
+		witness := testChunkedOutputsCircuit{Data: vars.NewBytesFrom(data)}
+		vars.SetBytes32(&witness.Hash, [32]byte(output[:32]))
+		vars.SetBytes32(&witness.Out, heads[len(heads)-1])
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+		witness.Data[len(data)-1].Set(0)
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+}
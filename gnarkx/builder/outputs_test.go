@@ -0,0 +1,161 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestOutputsCircuit struct {
+	Number  vars.U64
+	Address [20]vars.Byte
+	Data    [5]vars.Byte
+	Hash    [32]vars.Byte
+	Hashes  [2][32]vars.Byte
+	Small   vars.Byte
+	Flag    vars.Bool
+	Out     []vars.Byte
+	abi     bool `gnark:"-"`
+}
+
+func (c *TestOutputsCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	w := NewOutputWriter(*api)
+	if c.abi {
+		w = NewABIOutputWriter(*api)
+	}
+	w.WriteU64(c.Number)
+	w.WriteAddress(c.Address)
+	w.WriteBytes(c.Data[:])
+	w.WriteBytes32(c.Hash)
+	w.WriteBytes32Array(c.Hashes[:])
+	w.WriteU8(c.Small)
+	w.WriteBool(c.Flag)
+	w.WriteBytesWithLength(c.Data[:])
+	w.Close(c.Out)
+	return nil
+}
+
+func TestABIOutputWriter(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	number := uint64(0x0102030405060708)
+	address := common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa")
+	data := []byte("hello")
+	hash := common.HexToHash("0xaa")
+	hashes := [][32]byte{common.HexToHash("0xbb"), common.HexToHash("0xcc")}
+
+	newType := func(name string) abi.Type {
+		typ, err := abi.NewType(name, "", nil)
+		assert.NoError(err)
+		return typ
+	}
+	arguments := abi.Arguments{
+		{Type: newType("uint64")},
+		{Type: newType("address")},
+		{Type: newType("bytes")},
+		{Type: newType("bytes32")},
+		{Type: newType("bytes32[]")},
+		{Type: newType("uint8")},
+		{Type: newType("bool")},
+		{Type: newType("bytes")},
+	}
+	encoded, err := arguments.Pack(number, address, data, hash, hashes, uint8(0x2a), true, data)
+	assert.NoError(err)
+	packed := append(common.BigToHash(new(big.Int).SetUint64(number)).Bytes()[24:], address.Bytes()...)
+	packed = append(append(append(packed, data...), hash.Bytes()...), append(hashes[0][:], hashes[1][:]...)...)
+	packed = append(append(packed, 0x2a, 1, 0, 0, 0, 0, 0, 0, 0, byte(len(data))), data...)
+
+	for _, out := range [][]byte{encoded, packed} {
+		isABI := len(out) == len(encoded)
+		circuit := TestOutputsCircuit{Out: vars.NewBytes(len(out)), abi: isABI}
+		witness := TestOutputsCircuit{Out: vars.NewBytesFrom(out)}
+		witness.Number.Set(number)
+		for i := 0; i < 20; i++ {
+			witness.Address[i].Set(address[i])
+		}
+		for i := range data {
+			witness.Data[i].Set(data[i])
+		}
+		vars.SetBytes32(&witness.Hash, hash)
+		for i := range hashes {
+			vars.SetBytes32(&witness.Hashes[i], hashes[i])
+		}
+		witness.Small.Set(0x2a)
+		witness.Flag = vars.TRUE
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+
+		// The words of the encoding must match.
+		witness.Out[len(out)-1].Set(0xdd)
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.Error(err)
+	}
+}
+
+type TestVariableOutputsCircuit struct {
+	Small vars.Byte
+	Data  vars.VariableBytes
+	Out   []vars.Byte
+	abi   bool `gnark:"-"`
+}
+
+func (c *TestVariableOutputsCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	w := NewOutputWriter(*api)
+	if c.abi {
+		w = NewABIOutputWriter(*api)
+	}
+	w.WriteU8(c.Small)
+	w.WriteVariableBytes(c.Data)
+	w.WriteU8(c.Small)
+	w.Close(c.Out)
+	return nil
+}
+
+func TestWriteVariableBytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const maxLength = 40
+	data := []byte("hello")
+	uint8Type, err := abi.NewType("uint8", "", nil)
+	assert.NoError(err)
+	bytesType, err := abi.NewType("bytes", "", nil)
+	assert.NoError(err)
+	arguments := abi.Arguments{{Type: uint8Type}, {Type: bytesType}, {Type: uint8Type}}
+	encoded, err := arguments.Pack(uint8(0x2a), data, uint8(0x2a))
+	assert.NoError(err)
+
+	// The tail is padded to the maximum length, which abi.decode ignores.
+	encoded = append(encoded, make([]byte, 32)...)
+	values, err := arguments.Unpack(encoded)
+	assert.NoError(err)
+	assert.Equal(data, values[1])
+	packed := append([]byte{0x2a, 0, 0, 0, 0, 0, 0, 0, byte(len(data))}, data...)
+	packed = append(append(packed, make([]byte, maxLength-len(data))...), 0x2a)
+
+	for _, out := range [][]byte{encoded, packed} {
+		isABI := len(out) == len(encoded)
+		circuit := TestVariableOutputsCircuit{Data: vars.NewVariableBytes(maxLength), Out: vars.NewBytes(len(out)), abi: isABI}
+		witness := TestVariableOutputsCircuit{Data: vars.NewVariableBytes(maxLength), Out: vars.NewBytesFrom(out)}
+		witness.Small.Set(0x2a)
+		vars.SetVariableBytes(&witness.Data, data)
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+
+		// The bytes past the length are written as zeros.
+		witness.Data.Data[maxLength-1].Set(0xdd)
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+
+		witness.Data.Length = vars.NewVariableFromInt(len(data) + 1)
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.Error(err)
+	}
+}
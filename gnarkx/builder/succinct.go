@@ -10,12 +10,50 @@ import (
 // the gnark frontend API. Additional methods can be accessed by importing other packages such
 // as sha256 or ssz.
 type API struct {
-	api frontend.API
+	api                frontend.API
+	lookups            bool
+	batchedRangeChecks bool
+}
+
+// An Option configures the API created by NewAPI.
+type Option func(*API)
+
+// Makes the bitwise operations and byte decompositions of u32s use lookup tables of bytes, shared
+// across the circuit, instead of decomposing into bits. The tables cost a fixed number of
+// constraints, so this only pays off for circuits with many such operations, and is best suited to
+// the backends with lookup arguments such as PLONK. The SHA-256 compressions of the sha256 package
+// use the tables too, which only makes them cheaper with Groth16, and keccak256 always uses them,
+// so a circuit that hashes with Keccak-256 gets them for free.
+func WithLookups() Option {
+	return func(a *API) {
+		a.lookups = true
+	}
+}
+
+// Makes AssertIsByte and the range checks of the outputs of HintBytes and HintU64 deferred to a
+// single log-derivative lookup over all the checks of the circuit, done when it is compiled, instead
+// of decomposing every value into bits. Like lookups, this has a fixed cost and pays off for
+// circuits that check many bytes.
+//
+// Only these checks of the API are affected. The gadgets that check the bytes of their witnesses in
+// bulk, such as the proofs of the mpt package and the tokens of the jwt and tls packages, call the
+// range checker of gnark directly and so always share its lookup, whether or not the API was
+// created with this option. The bytes of an InputReader are not checked when they are read, but by
+// the commitment of the inputs, which hashes them. Decompositions whose bits are used, such as
+// ToBitsFromByte and those of the hash gadgets, are unaffected.
+func WithBatchedRangeChecks() Option {
+	return func(a *API) {
+		a.batchedRangeChecks = true
+	}
 }
 
 // Creates a new succinct.API object.
-func NewAPI(api frontend.API) *API {
-	return &API{api: api}
+func NewAPI(api frontend.API, options ...Option) *API {
+	a := &API{api: api}
+	for _, option := range options {
+		option(a)
+	}
+	return a
 }
 
 // Returns the underlying gnark frontend.FrontendAPI object. Most developers should not need to
@@ -23,3 +61,9 @@ func NewAPI(api frontend.API) *API {
 func (a *API) FrontendAPI() frontend.API {
 	return a.api
 }
+
+// Returns whether the API was created WithLookups, so that gadgets built on it, such as the hashes,
+// can use lookup tables too.
+func (a *API) UsesLookups() bool {
+	return a.lookups
+}
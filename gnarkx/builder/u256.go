@@ -0,0 +1,245 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The limbs of a u256 are 64 bits, so that products of two limbs fit in the scalar field.
+const (
+	u256Limbs    = 4
+	u256LimbBits = 64
+)
+
+// Returns the u256 of big-endian bytes, which are assumed to be range checked.
+func (a *API) ToU256FromBytes32(i1 [32]vars.Byte) vars.U256 {
+	var result vars.U256
+	for i := 0; i < u256Limbs; i++ {
+		limb := vars.ZERO
+		for j := 0; j < 8; j++ {
+			limb = a.Add(a.Mul(limb, vars.NewVariableFromInt(256)), i1[32-8*(i+1)+j].Value)
+		}
+		result.Limbs[i] = limb
+	}
+	return result
+}
+
+// Returns the big-endian bytes of a u256, which also checks that its limbs are 64 bits.
+func (a *API) ToBytes32FromU256(i1 vars.U256) [32]vars.Byte {
+	var result [32]vars.Byte
+	for i := 0; i < u256Limbs; i++ {
+		bits := a.ToBinaryLE(i1.Limbs[i], u256LimbBits)
+		for j := 0; j < 8; j++ {
+			var byteBits [8]vars.Bool
+			copy(byteBits[:], bits[j*8:(j+1)*8])
+			result[32-8*i-1-j] = a.ToByteFromBits(byteBits)
+		}
+	}
+	return result
+}
+
+// Returns the u256 of a u64.
+func (a *API) ToU256FromU64(i1 vars.U64) vars.U256 {
+	return vars.U256{Limbs: [4]vars.Variable{i1.Value, vars.ZERO, vars.ZERO, vars.ZERO}}
+}
+
+// Splits a value of at most nbBits bits into its low 64 bits and the rest.
+func (a *API) splitLimb(i1 vars.Variable, nbBits int) (vars.Variable, vars.Variable) {
+	bits := a.ToBinaryLE(i1, nbBits)
+	low, high := vars.ZERO, vars.ZERO
+	for i := nbBits - 1; i >= 0; i-- {
+		if i >= u256LimbBits {
+			high = a.Add(a.Mul(high, vars.TWO), bits[i].Value)
+		} else {
+			low = a.Add(a.Mul(low, vars.TWO), bits[i].Value)
+		}
+	}
+	return low, high
+}
+
+// Computes (x + y) mod 2^256, as the ADD opcode of the EVM. The limbs of x and y are assumed to be
+// range checked.
+func (a *API) AddU256(x, y vars.U256) vars.U256 {
+	var result vars.U256
+	carry := vars.ZERO
+	for i := 0; i < u256Limbs; i++ {
+		result.Limbs[i], carry = a.splitLimb(a.Add(x.Limbs[i], y.Limbs[i], carry), u256LimbBits+1)
+	}
+	return result
+}
+
+// Computes x - y and whether it borrows, i.e. whether x < y.
+func (a *API) subU256(x, y vars.U256) (vars.U256, vars.Bool) {
+	var result vars.U256
+	borrow := vars.ZERO
+	base := vars.NewVariableFromString(new(big.Int).Lsh(big.NewInt(1), u256LimbBits).String())
+	for i := 0; i < u256Limbs; i++ {
+		// The difference is offset by 2^64, so that its 65th bit is set iff it does not borrow.
+		var noBorrow vars.Variable
+		result.Limbs[i], noBorrow = a.splitLimb(a.Sub(a.Add(x.Limbs[i], base), y.Limbs[i], borrow), u256LimbBits+1)
+		borrow = a.Sub(vars.ONE, noBorrow)
+	}
+	return result, vars.Bool{Value: borrow}
+}
+
+// Computes (x - y) mod 2^256, as the SUB opcode of the EVM. The limbs of x and y are assumed to be
+// range checked.
+func (a *API) SubU256(x, y vars.U256) vars.U256 {
+	result, _ := a.subU256(x, y)
+	return result
+}
+
+// Computes (x * y) mod 2^256, as the MUL opcode of the EVM. The limbs of x and y are assumed to be
+// range checked.
+func (a *API) MulU256(x, y vars.U256) vars.U256 {
+	var result vars.U256
+	carry := vars.ZERO
+	for k := 0; k < u256Limbs; k++ {
+		acc := carry
+		for i := 0; i <= k; i++ {
+			acc = a.Add(acc, a.Mul(x.Limbs[i], y.Limbs[k-i]))
+		}
+		// At most 4 products of two limbs and a carry of 67 bits.
+		result.Limbs[k], carry = a.splitLimb(acc, 2*u256LimbBits+3)
+	}
+	return result
+}
+
+// Returns whether x < y. The limbs of x and y are assumed to be range checked.
+func (a *API) IsLessU256(x, y vars.U256) vars.Bool {
+	_, borrow := a.subU256(x, y)
+	return borrow
+}
+
+// Returns x if the selector is set and y otherwise.
+func (a *API) SelectU256(selector vars.Bool, x, y vars.U256) vars.U256 {
+	var result vars.U256
+	for i := 0; i < u256Limbs; i++ {
+		result.Limbs[i] = a.Select(selector, x.Limbs[i], y.Limbs[i])
+	}
+	return result
+}
+
+// Returns whether x = y.
+func (a *API) IsEqualU256(x, y vars.U256) vars.Bool {
+	result := vars.ONE
+	for i := 0; i < u256Limbs; i++ {
+		result = a.Mul(result, a.IsZero(a.Sub(x.Limbs[i], y.Limbs[i])).Value)
+	}
+	return vars.Bool{Value: result}
+}
+
+// Asserts that x = y.
+func (a *API) AssertIsEqualU256(x, y vars.U256) {
+	for i := 0; i < u256Limbs; i++ {
+		a.AssertIsEqual(x.Limbs[i], y.Limbs[i])
+	}
+}
+
+// Computes x / y rounded down, asserting that y is not zero. The limbs of x and y are assumed to
+// be range checked.
+func (a *API) DivU256(x, y vars.U256) vars.U256 {
+	q, _ := a.divModU256(x, y)
+	return q
+}
+
+// Computes x mod y, asserting that y is not zero. The limbs of x and y are assumed to be range
+// checked.
+func (a *API) ModU256(x, y vars.U256) vars.U256 {
+	_, r := a.divModU256(x, y)
+	return r
+}
+
+// The offset added to the carries of x = q * y + r so that they are non-negative, and the number
+// of bits they are range checked to.
+const (
+	u256CarryOffsetBits = 68
+	u256CarryBits       = 70
+)
+
+// Returns the quotient and the remainder of x by y, checking x = q * y + r over the integers and
+// r < y, which also implies that y is not zero.
+func (a *API) divModU256(x, y vars.U256) (vars.U256, vars.U256) {
+	in := append(append([]vars.Variable{}, x.Limbs[:]...), y.Limbs[:]...)
+	out := a.HintVariables(divModU256Hint, 4*u256Limbs, in...)
+	var q, r vars.U256
+	for i := 0; i < u256Limbs; i++ {
+		q.Limbs[i], r.Limbs[i] = out[i], out[u256Limbs+i]
+		a.ToBinaryLE(q.Limbs[i], u256LimbBits)
+		a.ToBinaryLE(r.Limbs[i], u256LimbBits)
+	}
+	carries := out[2*u256Limbs:]
+
+	// q * y + r - x = 0, one limb position at a time:
+	//   qy_k + r_k - x_k + carry_{k-1} = carry_k * 2^64
+	offset := vars.NewVariableFromString(new(big.Int).Lsh(big.NewInt(1), u256CarryOffsetBits).String())
+	base := vars.NewVariableFromString(new(big.Int).Lsh(big.NewInt(1), u256LimbBits).String())
+	prevCarry := vars.ZERO
+	for k := 0; k < 2*u256Limbs; k++ {
+		acc := prevCarry
+		for i := 0; i <= k; i++ {
+			if i < u256Limbs && k-i < u256Limbs {
+				acc = a.Add(acc, a.Mul(q.Limbs[i], y.Limbs[k-i]))
+			}
+		}
+		if k < u256Limbs {
+			acc = a.Sub(a.Add(acc, r.Limbs[k]), x.Limbs[k])
+		}
+		a.ToBinaryLE(carries[k], u256CarryBits)
+		carry := a.Sub(carries[k], offset)
+		a.AssertIsEqual(acc, a.Mul(carry, base))
+		prevCarry = carry
+	}
+	a.AssertIsEqual(prevCarry, vars.ZERO)
+	a.AssertIsEqual(a.IsLessU256(r, y).Value, vars.ONE)
+	return q, r
+}
+
+// Computes the quotient and the remainder of x by y, and the carries of x = q * y + r offset by
+// 2^u256CarryOffsetBits. The inputs are the limbs of x and y.
+var divModU256Hint = NewHint("builder.u256divmod", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 2*u256Limbs || len(outputs) != 4*u256Limbs {
+		return fmt.Errorf("u256divmod: invalid number of inputs or outputs")
+	}
+	fromLimbs := func(limbs []*big.Int) *big.Int {
+		result := new(big.Int)
+		for i := len(limbs) - 1; i >= 0; i-- {
+			result.Lsh(result, u256LimbBits).Add(result, limbs[i])
+		}
+		return result
+	}
+	toLimbs := func(v *big.Int, out []*big.Int) {
+		mask := new(big.Int).SetUint64(^uint64(0))
+		for i := range out {
+			out[i].And(new(big.Int).Rsh(v, uint(u256LimbBits*i)), mask)
+		}
+	}
+	x, y := inputs[:u256Limbs], inputs[u256Limbs:]
+	if fromLimbs(y).Sign() == 0 {
+		return fmt.Errorf("u256divmod: division by zero")
+	}
+	qInt, rInt := new(big.Int).QuoRem(fromLimbs(x), fromLimbs(y), new(big.Int))
+	q, r := outputs[:u256Limbs], outputs[u256Limbs:2*u256Limbs]
+	toLimbs(qInt, q)
+	toLimbs(rInt, r)
+
+	offset := new(big.Int).Lsh(big.NewInt(1), u256CarryOffsetBits)
+	carry := new(big.Int)
+	for k := 0; k < 2*u256Limbs; k++ {
+		acc := new(big.Int).Set(carry)
+		for i := 0; i <= k; i++ {
+			if i < u256Limbs && k-i < u256Limbs {
+				acc.Add(acc, new(big.Int).Mul(q[i], y[k-i]))
+			}
+		}
+		if k < u256Limbs {
+			acc.Add(acc, r[k]).Sub(acc, x[k])
+		}
+		// The accumulator is always divisible by 2^64, so the shift is exact.
+		carry = acc.Rsh(acc, u256LimbBits)
+		outputs[2*u256Limbs+k].Add(carry, offset)
+	}
+	return nil
+})
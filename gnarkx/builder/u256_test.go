@@ -0,0 +1,78 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestU256Circuit struct {
+	X, Y                [32]vars.Byte
+	Sum, Diff, Product  vars.U256
+	Quotient, Remainder vars.U256
+	IsLess, IsEqual     vars.Variable
+}
+
+func (c *TestU256Circuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	x, y := api.ToU256FromBytes32(c.X), api.ToU256FromBytes32(c.Y)
+	api.AssertIsEqualU256(api.AddU256(x, y), c.Sum)
+	api.AssertIsEqualU256(api.SubU256(x, y), c.Diff)
+	api.AssertIsEqualU256(api.MulU256(x, y), c.Product)
+	api.AssertIsEqualU256(api.DivU256(x, y), c.Quotient)
+	api.AssertIsEqualU256(api.ModU256(x, y), c.Remainder)
+	api.AssertIsEqual(api.IsLessU256(x, y).Value, c.IsLess)
+	api.AssertIsEqual(api.IsEqualU256(x, y).Value, c.IsEqual)
+	bytes := api.ToBytes32FromU256(x)
+	for i := range bytes {
+		api.AssertIsEqualByte(bytes[i], c.X[i])
+	}
+	return nil
+}
+
+func TestU256(t *testing.T) {
+	modulus := new(big.Int).Lsh(big.NewInt(1), 256)
+	max := new(big.Int).Sub(modulus, big.NewInt(1))
+	parse := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 0)
+		return v
+	}
+	for _, v := range [][2]*big.Int{
+		{big.NewInt(7), big.NewInt(3)},
+		{big.NewInt(3), big.NewInt(7)},
+		{max, max},
+		{max, big.NewInt(1)},
+		{parse("0xde0b6b3a7640000123456789abcdef0123456789abcdef"), parse("0xffffffffffffffffff1")},
+	} {
+		x, y := v[0], v[1]
+		var witness TestU256Circuit
+		vars.SetBytes32(&witness.X, [32]byte(x.FillBytes(make([]byte, 32))))
+		vars.SetBytes32(&witness.Y, [32]byte(y.FillBytes(make([]byte, 32))))
+		witness.Sum.Set(new(big.Int).Mod(new(big.Int).Add(x, y), modulus))
+		witness.Diff.Set(new(big.Int).Mod(new(big.Int).Sub(x, y), modulus))
+		witness.Product.Set(new(big.Int).Mod(new(big.Int).Mul(x, y), modulus))
+		witness.Quotient.Set(new(big.Int).Div(x, y))
+		witness.Remainder.Set(new(big.Int).Mod(x, y))
+		witness.IsLess = vars.NewVariableFromInt(0)
+		if x.Cmp(y) < 0 {
+			witness.IsLess = vars.NewVariableFromInt(1)
+		}
+		witness.IsEqual = vars.NewVariableFromInt(0)
+		if x.Cmp(y) == 0 {
+			witness.IsEqual = vars.NewVariableFromInt(1)
+		}
+		assert.NoError(t, test.IsSolved(&TestU256Circuit{}, &witness, ecc.BN254.ScalarField()))
+
+		// The remainder must be reduced.
+		if x.Cmp(y) >= 0 {
+			witness.Remainder.Set(new(big.Int).Add(new(big.Int).Mod(x, y), y))
+			witness.Quotient.Set(new(big.Int).Sub(new(big.Int).Div(x, y), big.NewInt(1)))
+			assert.Error(t, test.IsSolved(&TestU256Circuit{}, &witness, ecc.BN254.ScalarField()))
+		}
+	}
+}
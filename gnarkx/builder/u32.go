@@ -0,0 +1,150 @@
+package builder
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Packs 32 bits in big-endian order, as the arrays of the bits32 package, into a u32.
+func (a *API) ToU32FromBits(i1 [32]vars.Bool) vars.U32 {
+	value := vars.ZERO
+	for i := 0; i < 32; i++ {
+		value = a.Add(value, a.Mul(i1[i].Value, vars.NewVariableFromInt(1<<(31-i))))
+	}
+	return vars.U32{Value: value}
+}
+
+// Decomposes a u32 into 32 bits in big-endian order, which also checks that it is in [0, 2^32).
+func (a *API) ToBitsFromU32(i1 vars.U32) [32]vars.Bool {
+	values := a.ToBinaryBE(i1.Value, 32)
+	var bits [32]vars.Bool
+	copy(bits[:], values)
+	return bits
+}
+
+// Decomposes a u32 into 4 bytes in little-endian order, which also checks that it is in [0, 2^32).
+// With lookups, the bytes are range checked against a table instead of decomposed into bits.
+func (a *API) ToBytesFromU32LE(i1 vars.U32) [4]vars.Byte {
+	var bytes [4]vars.Byte
+	if a.lookups {
+		for i, v := range a.toLookupU32(i1) {
+			bytes[i] = vars.Byte{Value: vars.Variable{Value: v.Val}}
+		}
+		return bytes
+	}
+	bits := a.ToBinaryLE(i1.Value, 32)
+	for i := 0; i < 4; i++ {
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[i*8:i*8+8])
+		bytes[i] = a.ToByteFromBits(byteBits)
+	}
+	return bytes
+}
+
+// Returns i1 if the selector is set and i2 otherwise.
+func (a *API) SelectU32(selector vars.Bool, i1, i2 vars.U32) vars.U32 {
+	return vars.U32{Value: a.Select(selector, i1.Value, i2.Value)}
+}
+
+// Computes a_1 + ... + a_n mod 2^32 where a_i \in [0, 2^32). The sum is decomposed into bits once,
+// so accumulating more terms into a single add is cheaper than chaining adds.
+func (a *API) AddU32(in ...vars.U32) vars.U32 {
+	acc := vars.ZERO
+	for i := 0; i < len(in); i++ {
+		acc = a.Add(acc, in[i].Value)
+	}
+	sumBits := a.ToBinaryLE(acc, 32+bits.Len(uint(len(in)-1)))
+	reduced := vars.ZERO
+	for i := 0; i < 32; i++ {
+		reduced = a.Add(reduced, a.Mul(sumBits[i].Value, vars.NewVariableFromInt(1<<i)))
+	}
+	return vars.U32{Value: reduced}
+}
+
+// Computes a_1 ^ ... ^ a_n.
+func (a *API) XorU32(i1 vars.U32, i2 vars.U32, in ...vars.U32) vars.U32 {
+	if a.lookups {
+		return a.bitwiseU32(func(f *uints.BinaryField[uints.U32], v ...uints.U32) uints.U32 {
+			return f.Xor(v...)
+		}, append([]vars.U32{i1, i2}, in...))
+	}
+	result := a.ToBitsFromU32(i1)
+	for _, v := range append([]vars.U32{i2}, in...) {
+		bits := a.ToBitsFromU32(v)
+		for j := 0; j < 32; j++ {
+			result[j] = a.Xor(result[j], bits[j])
+		}
+	}
+	return a.ToU32FromBits(result)
+}
+
+// Computes a_1 & ... & a_n.
+func (a *API) AndU32(i1 vars.U32, i2 vars.U32, in ...vars.U32) vars.U32 {
+	if a.lookups {
+		return a.bitwiseU32(func(f *uints.BinaryField[uints.U32], v ...uints.U32) uints.U32 {
+			return f.And(v...)
+		}, append([]vars.U32{i1, i2}, in...))
+	}
+	result := a.ToBitsFromU32(i1)
+	for _, v := range append([]vars.U32{i2}, in...) {
+		bits := a.ToBitsFromU32(v)
+		for j := 0; j < 32; j++ {
+			result[j] = a.And(result[j], bits[j])
+		}
+	}
+	return a.ToU32FromBits(result)
+}
+
+// Rotates a u32 by a given offset to the right.
+func (a *API) RotateU32(i1 vars.U32, offset int) vars.U32 {
+	bits := a.ToBitsFromU32(i1)
+	var result [32]vars.Bool
+	for i := 0; i < 32; i++ {
+		result[(i+offset)%32] = bits[i]
+	}
+	return a.ToU32FromBits(result)
+}
+
+// Shifts a u32 by a given offset to the right.
+func (a *API) ShrU32(i1 vars.U32, offset int) vars.U32 {
+	bits := a.ToBitsFromU32(i1)
+	var result [32]vars.Bool
+	for i := 0; i < 32; i++ {
+		if i < offset {
+			result[i] = vars.FALSE
+		} else {
+			result[i] = bits[i-offset]
+		}
+	}
+	return a.ToU32FromBits(result)
+}
+
+// Returns the field of u32s made of bytes whose bitwise operations are lookups, whose tables are
+// shared by all the fields of the circuit.
+func (a *API) u32Field() *uints.BinaryField[uints.U32] {
+	field, err := uints.New[uints.U32](a.api)
+	if err != nil {
+		panic(err)
+	}
+	return field
+}
+
+// Decomposes a u32 into range checked bytes of the field, asserting that they recompose into it.
+func (a *API) toLookupU32(i1 vars.U32) uints.U32 {
+	field := a.u32Field()
+	value := field.ValueOf(i1.Value.Value)
+	a.api.AssertIsEqual(field.ToValue(value), i1.Value.Value)
+	return value
+}
+
+// Applies a bitwise operation of the field to the decompositions of the inputs.
+func (a *API) bitwiseU32(op func(*uints.BinaryField[uints.U32], ...uints.U32) uints.U32, in []vars.U32) vars.U32 {
+	values := make([]uints.U32, len(in))
+	for i := range in {
+		values[i] = a.toLookupU32(in[i])
+	}
+	field := a.u32Field()
+	return vars.U32{Value: vars.Variable{Value: field.ToValue(op(field, values...))}}
+}
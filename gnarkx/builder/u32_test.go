@@ -0,0 +1,95 @@
+package builder
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestU32Circuit struct {
+	Lookups bool `gnark:"-"`
+	A, B, C vars.U32
+	Sum     vars.U32
+	Xor     vars.U32
+	And     vars.U32
+	Bytes   [4]vars.Byte
+	Rotate  vars.U32
+	Shr     vars.U32
+}
+
+func (c *TestU32Circuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	if c.Lookups {
+		api = NewAPI(baseAPI, WithLookups())
+	}
+	api.AssertIsEqual(api.AddU32(c.A, c.B, c.C).Value, c.Sum.Value)
+	api.AssertIsEqual(api.XorU32(c.A, c.B, c.C).Value, c.Xor.Value)
+	api.AssertIsEqual(api.AndU32(c.A, c.B, c.C).Value, c.And.Value)
+	bytes := api.ToBytesFromU32LE(c.A)
+	for i := range bytes {
+		api.AssertIsEqualByte(bytes[i], c.Bytes[i])
+	}
+	api.AssertIsEqual(api.RotateU32(c.A, 7).Value, c.Rotate.Value)
+	api.AssertIsEqual(api.ShrU32(c.B, 10).Value, c.Shr.Value)
+	api.AssertIsEqual(api.ToU32FromBits(api.ToBitsFromU32(c.C)).Value, c.C.Value)
+	return nil
+}
+
+func TestU32(t *testing.T) {
+	for _, lookups := range []bool{false, true} {
+		for _, v := range [][3]uint32{{0, 0, 0}, {0xffffffff, 0xffffffff, 0xffffffff}, {0x6a09e667, 0xbb67ae85, 0x3c6ef372}} {
+			var witness TestU32Circuit
+			witness.A.Set(v[0])
+			witness.B.Set(v[1])
+			witness.C.Set(v[2])
+			witness.Sum.Set(v[0] + v[1] + v[2])
+			witness.Xor.Set(v[0] ^ v[1] ^ v[2])
+			witness.And.Set(v[0] & v[1] & v[2])
+			for i := range witness.Bytes {
+				witness.Bytes[i].Set(byte(v[0] >> (8 * i)))
+			}
+			witness.Rotate.Set(bits.RotateLeft32(v[0], -7))
+			witness.Shr.Set(v[1] >> 10)
+			assert.NoError(t, test.IsSolved(&TestU32Circuit{Lookups: lookups}, &witness, ecc.BN254.ScalarField()))
+
+			// The inputs must be 32 bit integers.
+			witness.C.Value = vars.NewVariableFromInt(int(v[2]) + 1<<32)
+			assert.Error(t, test.IsSolved(&TestU32Circuit{Lookups: lookups}, &witness, ecc.BN254.ScalarField()))
+		}
+	}
+}
+
+type TestXorChainCircuit struct {
+	Lookups bool `gnark:"-"`
+	In      []vars.U32
+}
+
+func (c *TestXorChainCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	if c.Lookups {
+		api = NewAPI(baseAPI, WithLookups())
+	}
+	for i := 2; i < len(c.In); i++ {
+		api.AssertIsEqual(api.XorU32(c.In[i-2], c.In[i-1]).Value, c.In[i].Value)
+	}
+	return nil
+}
+
+func TestU32Lookups(t *testing.T) {
+	compile := func(lookups bool, n int) int {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &TestXorChainCircuit{Lookups: lookups, In: make([]vars.U32, n)})
+		assert.NoError(t, err)
+		return cs.GetNbConstraints()
+	}
+
+	// Past the fixed cost of the tables, xors by lookups take fewer constraints than by bits.
+	bitsCost := compile(false, 131) - compile(false, 3)
+	lookupsCost := compile(true, 131) - compile(true, 3)
+	assert.Less(t, 3*lookupsCost, 2*bitsCost)
+}
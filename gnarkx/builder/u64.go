@@ -2,6 +2,7 @@ package builder
 
 import (
 	"math"
+	"math/big"
 
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
@@ -33,28 +34,69 @@ func (a *API) AddU64(in ...vars.U64) vars.U64 {
 	return vars.U64{Value: reduced}
 }
 
+// Computes a_1 * ... * a_n mod 2^64 where a_i \in [0, 2^64). The product is reduced whenever
+// another factor could overflow the field, so any number of factors can be multiplied.
 func (a *API) MulU64(in ...vars.U64) vars.U64 {
-	// Find the maximum number of bits needed to represent the product of all inputs.
-	nbTerms := len(in)
-	nbMaxBits := 64 * nbTerms
-
-	// Compute the product of all inputs over the field.
 	acc := vars.ONE
+	nbBits := 0
 	for i := 0; i < len(in); i++ {
+		if nbBits+64 >= a.FieldBitLen() {
+			acc = a.reduceU64(acc, nbBits)
+			nbBits = 64
+		}
 		acc = a.Mul(acc, in[i].Value)
+		nbBits += 64
 	}
+	return vars.U64{Value: a.reduceU64(acc, nbBits)}
+}
 
-	// Convert the sum to binary with the calculated number of maximum bits.
-	bits := a.ToBinaryLE(acc, int(nbMaxBits))
+// Computes a - b mod 2^64 where a, b \in [0, 2^64).
+func (a *API) SubU64(i1, i2 vars.U64) vars.U64 {
+	diff, _ := a.subU64(i1, i2)
+	return diff
+}
 
-	// Compute acc % 2^64.
-	reduced := vars.ZERO
+// Computes a - b mod 2^64 and whether it borrowed, that is whether a < b.
+func (a *API) subU64(i1, i2 vars.U64) (vars.U64, vars.Bool) {
+	// 2^64 + a - b is in [1, 2^65), and its bit 64 is set iff a >= b.
+	shifted := a.Add(a.Sub(i1.Value, i2.Value), vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), 64)})
+	bits := a.ToBinaryLE(shifted, 65)
+	return vars.U64{Value: a.fromBitsLE(bits[:64])}, a.Not(bits[64])
+}
+
+// Returns whether a < b where a, b \in [0, 2^64).
+func (a *API) IsLessU64(i1, i2 vars.U64) vars.Bool {
+	_, borrow := a.subU64(i1, i2)
+	return borrow
+}
+
+// Returns whether a <= b where a, b \in [0, 2^64).
+func (a *API) IsLessOrEqualU64(i1, i2 vars.U64) vars.Bool {
+	return a.Not(a.IsLessU64(i2, i1))
+}
+
+// Returns the low 64 bits of a value of at most nbBits bits.
+func (a *API) reduceU64(i1 vars.Variable, nbBits int) vars.Variable {
+	if nbBits < 64 {
+		nbBits = 64
+	}
+	return a.fromBitsLE(a.ToBinaryLE(i1, nbBits)[:64])
+}
+
+// Packs little-endian bits into a value.
+func (a *API) fromBitsLE(bits []vars.Bool) vars.Variable {
+	result := vars.ZERO
 	power := vars.ONE
-	for i := 0; i < 64; i++ {
-		reduced = a.Add(reduced, a.Mul(bits[i].Value, power))
+	for i := 0; i < len(bits); i++ {
+		result = a.Add(result, a.Mul(bits[i].Value, power))
 		power = a.Mul(power, vars.TWO)
 	}
-	return vars.U64{Value: reduced}
+	return result
+}
+
+// Returns i1 if the selector is set and i2 otherwise.
+func (a *API) SelectU64(selector vars.Bool, i1, i2 vars.U64) vars.U64 {
+	return vars.U64{Value: a.Select(selector, i1.Value, i2.Value)}
 }
 
 // Converts a U64 to a Bytes32 in little-endian format. In particular, the u64 is decomposed into
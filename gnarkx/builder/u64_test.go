@@ -0,0 +1,51 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestU64Circuit struct {
+	A, B          vars.U64
+	Sum           vars.U64
+	Diff          vars.U64
+	Product       vars.U64
+	Power         vars.U64
+	IsLess        vars.Bool
+	IsLessOrEqual vars.Bool
+}
+
+func (c *TestU64Circuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	api.AssertIsEqual(api.AddU64(c.A, c.B).Value, c.Sum.Value)
+	api.AssertIsEqual(api.SubU64(c.A, c.B).Value, c.Diff.Value)
+	api.AssertIsEqual(api.MulU64(c.A, c.B).Value, c.Product.Value)
+	api.AssertIsEqual(api.MulU64(c.A, c.A, c.A, c.A, c.A).Value, c.Power.Value)
+	api.AssertIsEqualBool(api.IsLessU64(c.A, c.B), c.IsLess)
+	api.AssertIsEqualBool(api.IsLessOrEqualU64(c.A, c.B), c.IsLessOrEqual)
+	return nil
+}
+
+func TestU64(t *testing.T) {
+	for _, v := range [][2]uint64{{0, 0}, {1, 2}, {2, 1}, {^uint64(0), ^uint64(0)}, {^uint64(0), 1}, {1 << 63, 1<<63 + 5}} {
+		var witness TestU64Circuit
+		witness.A.Set(v[0])
+		witness.B.Set(v[1])
+		witness.Sum.Set(v[0] + v[1])
+		witness.Diff.Set(v[0] - v[1])
+		witness.Product.Set(v[0] * v[1])
+		witness.Power.Set(v[0] * v[0] * v[0] * v[0] * v[0])
+		witness.IsLess = vars.NewBool(v[0] < v[1])
+		witness.IsLessOrEqual = vars.NewBool(v[0] <= v[1])
+		assert.NoError(t, test.IsSolved(&TestU64Circuit{}, &witness, ecc.BN254.ScalarField()))
+
+		// The comparisons must be consistent with the values.
+		witness.IsLess = vars.NewBool(!(v[0] < v[1]))
+		assert.Error(t, test.IsSolved(&TestU64Circuit{}, &witness, ecc.BN254.ScalarField()))
+	}
+}
@@ -2,6 +2,7 @@ package builder
 
 import (
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/selector"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
@@ -52,6 +53,16 @@ func (a *API) Select(selector vars.Bool, i1 vars.Variable, i2 vars.Variable) var
 	return vars.Variable{Value: a.api.Select(selector.Value.Value, i1.Value, i2.Value)}
 }
 
+// Returns in[index], asserting that the index is less than len(in). Its cost is linear in len(in),
+// so tables read many times are cheaper as byteslice.Table.
+func (a *API) Mux(index vars.Variable, in ...vars.Variable) vars.Variable {
+	values := make([]frontend.Variable, len(in))
+	for i := range in {
+		values[i] = in[i].Value
+	}
+	return vars.Variable{Value: selector.Mux(a.api, index.Value, values...)}
+}
+
 // Lookup2 performs a 2-bit lookup between i1, i2, i3, i4 based on bits b0
 // and b1. Returns i0 if b0=b1=0, i1 if b0=1 and b1=0, i2 if b0=0 and b1=1
 // and i3 if b0=b1=1.
@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestSelectCircuit struct {
+	Selector     vars.Bool
+	BoolA, BoolB vars.Bool
+	BytesA       []vars.Byte
+	BytesB       []vars.Byte
+	U32A, U32B   vars.U32
+	U64A, U64B   vars.U64
+	U256A, U256B vars.U256
+	Index        vars.Variable
+	Table        []vars.Byte
+	Entry        vars.Byte
+}
+
+func (c *TestSelectCircuit) Define(baseAPI frontend.API) error {
+	api := NewAPI(baseAPI)
+	isSelected := func(selected, i1, i2 vars.Variable) {
+		api.AssertIsEqual(selected, api.Select(c.Selector, i1, i2))
+	}
+	isSelected(api.SelectBool(c.Selector, c.BoolA, c.BoolB).Value, c.BoolA.Value, c.BoolB.Value)
+	bytes := api.SelectBytes(c.Selector, c.BytesA, c.BytesB)
+	for i := range bytes {
+		isSelected(bytes[i].Value, c.BytesA[i].Value, c.BytesB[i].Value)
+	}
+	isSelected(api.SelectU32(c.Selector, c.U32A, c.U32B).Value, c.U32A.Value, c.U32B.Value)
+	isSelected(api.SelectU64(c.Selector, c.U64A, c.U64B).Value, c.U64A.Value, c.U64B.Value)
+	u256 := api.SelectU256(c.Selector, c.U256A, c.U256B)
+	for i := range u256.Limbs {
+		isSelected(u256.Limbs[i], c.U256A.Limbs[i], c.U256B.Limbs[i])
+	}
+
+	values := make([]vars.Variable, len(c.Table))
+	for i := range c.Table {
+		values[i] = c.Table[i].Value
+	}
+	api.AssertIsEqual(api.Mux(c.Index, values...), c.Entry.Value)
+	api.AssertIsEqualByte(api.MuxByte(c.Index, c.Table...), c.Entry)
+	return nil
+}
+
+func TestSelect(t *testing.T) {
+	table := []byte{10, 20, 30, 40, 50}
+	newWitness := func(selector bool, index int) *TestSelectCircuit {
+		witness := &TestSelectCircuit{
+			Selector: vars.NewBool(selector),
+			BoolA:    vars.NewBool(true),
+			BoolB:    vars.NewBool(false),
+			BytesA:   vars.NewBytesFrom([]byte{1, 2, 3}),
+			BytesB:   vars.NewBytesFrom([]byte{4, 5, 6}),
+			Index:    vars.NewVariableFromInt(index),
+			Table:    vars.NewBytesFrom(table),
+		}
+		witness.U32A.Set(0xdeadbeef)
+		witness.U32B.Set(7)
+		witness.U64A.Set(1 << 40)
+		witness.U64B.Set(3)
+		witness.U256A.Set(new(big.Int).Lsh(big.NewInt(1), 200))
+		witness.U256B.Set(big.NewInt(9))
+		witness.Entry = vars.NewByte()
+		if index < len(table) {
+			witness.Entry.Set(table[index])
+		}
+		return witness
+	}
+	circuit := &TestSelectCircuit{BytesA: vars.NewBytes(3), BytesB: vars.NewBytes(3), Table: vars.NewBytes(len(table))}
+	for _, selector := range []bool{true, false} {
+		for index := range table {
+			assert.NoError(t, test.IsSolved(circuit, newWitness(selector, index), ecc.BN254.ScalarField()))
+		}
+	}
+
+	// The index must be in the table.
+	assert.Error(t, test.IsSolved(circuit, newWitness(true, len(table)), ecc.BN254.ScalarField()))
+}
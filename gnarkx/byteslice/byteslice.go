@@ -0,0 +1,61 @@
+// Reads of byte slices at positions that are only known at proving time, backed by lookup tables.
+package byteslice
+
+import (
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Table allows reading bytes at positions that are only known at proving time. The data is
+// padded with zeros so that reads slightly before the start or past the end are well defined, and
+// reading outside of the padding makes the circuit unsatisfiable.
+type Table struct {
+	api    builder.API
+	table  *logderivlookup.Table
+	offset int
+}
+
+// Creates a new table over data, padded with before zeros at the start and after zeros at the end.
+func NewTable(api builder.API, data []vars.Byte, before int, after int) *Table {
+	table := logderivlookup.New(api.FrontendAPI())
+	for i := 0; i < before; i++ {
+		table.Insert(0)
+	}
+	for i := 0; i < len(data); i++ {
+		table.Insert(data[i].Value.Value)
+	}
+	for i := 0; i < after; i++ {
+		table.Insert(0)
+	}
+	return &Table{api: api, table: table, offset: before}
+}
+
+// Returns the byte at position index + k.
+func (t *Table) At(index vars.Variable, k int) vars.Byte {
+	value := t.table.Lookup(t.api.FrontendAPI().Add(index.Value, t.offset+k))[0]
+	return vars.Byte{Value: vars.Variable{Value: value}}
+}
+
+// Returns the n bytes starting at index.
+func (t *Table) Read(index vars.Variable, n int) []vars.Byte {
+	result := make([]vars.Byte, n)
+	for k := 0; k < n; k++ {
+		result[k] = t.At(index, k)
+	}
+	return result
+}
+
+// Asserts that the bytes starting at index equal the given bytes.
+func (t *Table) AssertEqualAt(index vars.Variable, in []vars.Byte) {
+	for k := 0; k < len(in); k++ {
+		t.api.AssertIsEqualByte(t.At(index, k), in[k])
+	}
+}
+
+// Asserts that the bytes starting at index equal the literal.
+func (t *Table) AssertLiteralAt(index vars.Variable, literal []byte) {
+	for k := 0; k < len(literal); k++ {
+		t.api.FrontendAPI().AssertIsEqual(t.At(index, k).Value.Value, int(literal[k]))
+	}
+}
@@ -0,0 +1,114 @@
+package byteslice
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Data  []vars.Byte
+	Index vars.Variable
+	Out   []vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	table := NewTable(*api, c.Data, 1, len(c.Out))
+	table.AssertEqualAt(c.Index, c.Out)
+	table.AssertLiteralAt(c.Index, []byte("ll"))
+	api.AssertIsEqualByte(table.At(c.Index, -1), vars.Byte{Value: vars.NewVariableFromInt('e')})
+	return nil
+}
+
+func TestTable(t *testing.T) {
+	data := []byte("hello world")
+	circuit := testCircuit{Data: vars.NewBytes(len(data)), Out: vars.NewBytes(3)}
+
+	assignment := testCircuit{
+		Data:  vars.NewBytesFrom(data),
+		Index: vars.NewVariableFromInt(2),
+		Out:   vars.NewBytesFrom([]byte("llo")),
+	}
+	err := test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	assignment.Index = vars.NewVariableFromInt(3)
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+type testVariableCircuit struct {
+	A, B     vars.VariableBytes
+	Start    vars.Variable
+	Length   vars.Variable
+	Slice    vars.VariableBytes
+	Concat   vars.VariableBytes
+	IsEqual  vars.Bool
+	MaxSlice int `gnark:"-"`
+}
+
+func (c *testVariableCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	a, b := Mask(*api, c.A), Mask(*api, c.B)
+	AssertIsEqual(*api, Slice(*api, a, c.Start, c.Length, c.MaxSlice), c.Slice)
+	AssertIsEqual(*api, Concat(*api, a, b), c.Concat)
+	api.AssertIsEqualBool(IsEqual(*api, a, b), c.IsEqual)
+	return nil
+}
+
+func TestVariableBytes(t *testing.T) {
+	newCircuit := func() *testVariableCircuit {
+		return &testVariableCircuit{
+			A:        vars.NewVariableBytes(8),
+			B:        vars.NewVariableBytes(6),
+			Slice:    vars.NewVariableBytes(4),
+			Concat:   vars.NewVariableBytes(14),
+			MaxSlice: 4,
+		}
+	}
+	newAssignment := func(a, b []byte, start, length int) *testVariableCircuit {
+		assignment := newCircuit()
+		// The bytes past the lengths are ignored.
+		vars.SetVariableBytes(&assignment.A, append(append([]byte{}, a...), 0xff))
+		assignment.A.Length = vars.NewVariableFromInt(len(a))
+		vars.SetVariableBytes(&assignment.B, b)
+		assignment.Start = vars.NewVariableFromInt(start)
+		assignment.Length = vars.NewVariableFromInt(length)
+		vars.SetVariableBytes(&assignment.Slice, a[start:start+length])
+		vars.SetVariableBytes(&assignment.Concat, append(append([]byte{}, a...), b...))
+		assignment.IsEqual = vars.NewBool(string(a) == string(b))
+		return assignment
+	}
+
+	for _, testCase := range []struct {
+		a, b          string
+		start, length int
+	}{
+		{"hello", "world", 1, 3},
+		{"hello", "hello", 0, 4},
+		{"", "", 0, 0},
+		{"abcdefg", "abc", 3, 4},
+		{"abc", "abcd", 3, 0},
+	} {
+		assignment := newAssignment([]byte(testCase.a), []byte(testCase.b), testCase.start, testCase.length)
+		assert.NoError(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+	}
+
+	// The slice must be within the bytes.
+	assignment := newAssignment([]byte("hello"), []byte("world"), 1, 3)
+	assignment.Length = vars.NewVariableFromInt(4)
+	assignment.Start = vars.NewVariableFromInt(2)
+	vars.SetVariableBytes(&assignment.Slice, []byte("llo\xff"))
+	assert.Error(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The bytes must match up to the length only.
+	assignment = newAssignment([]byte("hello"), []byte("world"), 1, 3)
+	vars.SetVariableBytes(&assignment.Slice, []byte("elx"))
+	assert.Error(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+}
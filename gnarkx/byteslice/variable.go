@@ -0,0 +1,82 @@
+package byteslice
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Returns in with the bytes past its length set to zero, asserting that the length is at most the
+// maximum length, as the functions that take variable bytes from witnesses must do first.
+func Mask(api builder.API, in vars.VariableBytes) vars.VariableBytes {
+	api.AssertIsLessOrEqual(in.Length, vars.NewVariableFromInt(len(in.Data)))
+	result := vars.VariableBytes{Data: make([]vars.Byte, len(in.Data)), Length: in.Length}
+	isActive := vars.NewVariableFromInt(1)
+	for i := 0; i < len(in.Data); i++ {
+		isActive = api.Sub(isActive, api.IsZero(api.Sub(in.Length, vars.NewVariableFromInt(i))).Value)
+		result.Data[i] = vars.Byte{Value: api.Mul(isActive, in.Data[i].Value)}
+	}
+	return result
+}
+
+// Returns the length bytes of in from start, where length is at most maxLength, a compile time
+// constant, and start + length is at most the length of in.
+func Slice(api builder.API, in vars.VariableBytes, start vars.Variable, length vars.Variable, maxLength int) vars.VariableBytes {
+	api.AssertIsLessOrEqual(api.Add(start, length), in.Length)
+	table := NewTable(api, in.Data, 0, maxLength)
+	return Mask(api, vars.VariableBytes{Data: table.Read(start, maxLength), Length: length})
+}
+
+// Returns the concatenation of the items, whose maximum length is the sum of theirs. The bytes of
+// the items past their lengths must be zero, as for the results of Mask.
+func Concat(api builder.API, items ...vars.VariableBytes) vars.VariableBytes {
+	maxLength := 0
+	for _, item := range items {
+		maxLength += len(item.Data)
+	}
+	data := make([]vars.Byte, maxLength)
+	for j := range data {
+		data[j] = vars.NewByte()
+	}
+
+	// Item i starts at the sum of the lengths of the items before it and is read from a table of
+	// its bytes.
+	offset := vars.NewVariableFromInt(0)
+	for _, item := range items {
+		table := NewTable(api, item.Data, maxLength, maxLength)
+		start := api.Neg(offset)
+		for j := range data {
+			data[j] = vars.Byte{Value: api.Add(data[j].Value, table.At(start, j).Value)}
+		}
+		offset = api.Add(offset, item.Length)
+	}
+	return vars.VariableBytes{Data: data, Length: offset}
+}
+
+// Returns whether a and b have the same length and the same bytes up to it. Their lengths are
+// assumed to be at most their maximum lengths.
+func IsEqual(api builder.API, a vars.VariableBytes, b vars.VariableBytes) vars.Bool {
+	// If the lengths are equal, they are at most the smaller maximum length.
+	result := api.IsZero(api.Sub(a.Length, b.Length))
+	isActive := vars.NewVariableFromInt(1)
+	for i := 0; i < len(a.Data) && i < len(b.Data); i++ {
+		isActive = api.Sub(isActive, api.IsZero(api.Sub(a.Length, vars.NewVariableFromInt(i))).Value)
+		isDifferent := api.Not(api.IsZero(api.Sub(a.Data[i].Value, b.Data[i].Value)))
+		result = api.And(result, api.Not(api.And(vars.Bool{Value: isActive}, isDifferent)))
+	}
+	return result
+}
+
+// Asserts that a and b have the same length and the same bytes up to it.
+func AssertIsEqual(api builder.API, a vars.VariableBytes, b vars.VariableBytes) {
+	n := len(a.Data)
+	if len(b.Data) < n {
+		n = len(b.Data)
+	}
+	api.AssertIsEqual(a.Length, b.Length)
+	api.AssertIsLessOrEqual(a.Length, vars.NewVariableFromInt(n))
+	isActive := vars.NewVariableFromInt(1)
+	for i := 0; i < n; i++ {
+		isActive = api.Sub(isActive, api.IsZero(api.Sub(a.Length, vars.NewVariableFromInt(i))).Value)
+		api.AssertIsEqual(api.Mul(isActive, api.Sub(a.Data[i].Value, b.Data[i].Value)), vars.NewVariableFromInt(0))
+	}
+}
@@ -0,0 +1,172 @@
+// A circuit template verifying aggregate attestations of the Beacon Chain for a Casper FFG link
+// from a source to a target checkpoint, and summing the effective balances of the validators that
+// attested, for light clients that follow full finality rather than the sync committee.
+//
+// The input is the Poseidon commitment to the committees, the total active balance, and the
+// source and target checkpoints, each an epoch and a root. The output is the attesting balance,
+// counting each validator that attests in the committee of an attestation once, and 1 if it is a
+// supermajority of the total active balance, that is 3 * attesting >= 2 * total, or 0 otherwise.
+//
+// The committees are those of the epoch of the target, as computed from the beacon state by the
+// client, which must commit to each committee at most once since committees are disjoint. Each
+// committee is attested by at most one aggregate of the circuit, and committees without any
+// attesting validator are skipped. The Poseidon commitment to the committees is computed as:
+//
+//  1. Each member has the leaf poseidon(hi, lo, balance), where hi and lo are the big-endian
+//     integers of the halves of its compressed BLS public key and balance is its effective
+//     balance in Gwei. Members past the size of the committee are zero.
+//  2. Each committee has the leaf poseidon(slot, index, size, root), where root is the root of
+//     the binary Merkle tree of poseidon(left, right) over the MaxCommitteeSize leaves of the
+//     members.
+//  3. The commitment is the root of the binary Merkle tree over the MaxCommittees leaves of the
+//     committees, where unused committees have no members.
+//
+// Reference: https://github.com/ethereum/consensus-specs/blob/dev/specs/phase0/beacon-chain.md
+package casper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sszutils"
+)
+
+// The domain type of attestations.
+var domainBeaconAttester = [4]byte{0x01, 0x00, 0x00, 0x00}
+
+// The length of each of the two halves of a public key, which are elements of the scalar field.
+const limbLength = bls.G1Length / 2
+
+// Config describes the chain and the number of committees and validators a circuit accepts.
+type Config struct {
+	// The fork version of the epoch of the target and the genesis validators root of the chain,
+	// which determine the signing domain of attestations.
+	ForkVersion           [4]byte
+	GenesisValidatorsRoot common.Hash
+
+	// Whether attestations are in the format of Electra, where the index of the attestation data
+	// is 0 and the committee is given separately.
+	Electra bool
+
+	// The maximum number of committees and of members of a committee, which are powers of 2.
+	MaxCommittees    int
+	MaxCommitteeSize int
+}
+
+// A checkpoint of Casper FFG.
+type Checkpoint struct {
+	Epoch uint64
+	Root  common.Hash
+}
+
+// A committee of the epoch of the target, with the compressed public keys and the effective
+// balances of its members.
+type Committee struct {
+	Slot     uint64
+	Index    uint64
+	Pubkeys  [][bls.G1Length]byte
+	Balances []uint64
+}
+
+// An aggregate attestation of a committee for the link of the circuit, where the bits of the
+// attesting members are set.
+type Attestation struct {
+	BeaconBlockRoot common.Hash
+	Bits            []bool
+	Signature       [bls.G2Length]byte
+}
+
+// Returns the input of the circuit.
+func (c *Config) Input(commitment *big.Int, totalBalance uint64, source Checkpoint, target Checkpoint) []byte {
+	input := commitment.FillBytes(make([]byte, 32))
+	input = binary.BigEndian.AppendUint64(input, totalBalance)
+	input = append(binary.BigEndian.AppendUint64(input, source.Epoch), source.Root[:]...)
+	return append(binary.BigEndian.AppendUint64(input, target.Epoch), target.Root[:]...)
+}
+
+// Checks that the committees fit the config.
+func (c *Config) checkCommittees(committees []Committee) error {
+	if len(committees) > c.MaxCommittees {
+		return fmt.Errorf("%d committees, expected at most %d", len(committees), c.MaxCommittees)
+	}
+	for i, committee := range committees {
+		if len(committee.Pubkeys) > c.MaxCommitteeSize || len(committee.Balances) != len(committee.Pubkeys) {
+			return fmt.Errorf("committee %d has %d public keys and %d balances", i, len(committee.Pubkeys), len(committee.Balances))
+		}
+	}
+	return nil
+}
+
+// Computes the Poseidon commitment to the committees.
+func (c *Config) Commitment(committees []Committee) (*big.Int, error) {
+	if err := c.checkCommittees(committees); err != nil {
+		return nil, err
+	}
+	leaves := make([]*big.Int, c.MaxCommittees)
+	for i := 0; i < len(leaves); i++ {
+		var committee Committee
+		if i < len(committees) {
+			committee = committees[i]
+		}
+		members := make([]*big.Int, c.MaxCommitteeSize)
+		for j := 0; j < len(members); j++ {
+			var pubkey [bls.G1Length]byte
+			var balance uint64
+			if j < len(committee.Pubkeys) {
+				pubkey, balance = committee.Pubkeys[j], committee.Balances[j]
+			}
+			hi := new(big.Int).SetBytes(pubkey[:limbLength])
+			lo := new(big.Int).SetBytes(pubkey[limbLength:])
+			members[j] = poseidon.HashValues(hi, lo, new(big.Int).SetUint64(balance))
+		}
+		leaves[i] = poseidon.HashValues(
+			new(big.Int).SetUint64(committee.Slot),
+			new(big.Int).SetUint64(committee.Index),
+			big.NewInt(int64(len(committee.Pubkeys))),
+			merkleRoot(members),
+		)
+	}
+	return merkleRoot(leaves), nil
+}
+
+func merkleRoot(nodes []*big.Int) *big.Int {
+	nodes = append([]*big.Int{}, nodes...)
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = poseidon.HashValues(nodes[2*i], nodes[2*i+1])
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
+
+// Returns the signing domain of attestations.
+func (c *Config) domain() [32]byte {
+	var version [32]byte
+	copy(version[:], c.ForkVersion[:])
+	forkDataRoot := sszutils.HashTreeRoot([][32]byte{version, c.GenesisValidatorsRoot})
+	var domain [32]byte
+	copy(domain[:], domainBeaconAttester[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// Returns the signing root of the attestation data of a committee for the link, which the members
+// sign.
+func (c *Config) SigningRoot(committee *Committee, beaconBlockRoot common.Hash, source Checkpoint, target Checkpoint) [32]byte {
+	index := committee.Index
+	if c.Electra {
+		index = 0
+	}
+	sourceRoot := sszutils.HashTreeRoot([][32]byte{sszutils.NewBytes32FromU64LE(source.Epoch), source.Root})
+	targetRoot := sszutils.HashTreeRoot([][32]byte{sszutils.NewBytes32FromU64LE(target.Epoch), target.Root})
+	dataRoot := sszutils.HashTreeRoot([][32]byte{
+		sszutils.NewBytes32FromU64LE(committee.Slot), sszutils.NewBytes32FromU64LE(index), beaconBlockRoot,
+		sourceRoot, targetRoot, {}, {}, {},
+	})
+	return sszutils.HashTreeRoot([][32]byte{dataRoot, c.domain()})
+}
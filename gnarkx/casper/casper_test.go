@@ -0,0 +1,99 @@
+package casper
+
+import (
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+)
+
+var (
+	config = &Config{
+		ForkVersion:           [4]byte{0x04, 0x00, 0x00, 0x00},
+		GenesisValidatorsRoot: common.HexToHash("0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"),
+		MaxCommittees:         2,
+		MaxCommitteeSize:      4,
+	}
+	source = Checkpoint{Epoch: 250000, Root: common.HexToHash("0x01")}
+	target = Checkpoint{Epoch: 250001, Root: common.HexToHash("0x02")}
+)
+
+// Returns a committee of n members with the secret keys of its members.
+func testCommittee(slot uint64, index uint64, n int) (Committee, []*big.Int) {
+	_, _, g1, _ := bls12381.Generators()
+	committee := Committee{Slot: slot, Index: index}
+	secrets := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		secrets[i] = big.NewInt(int64(index)*1000 + int64(i)*7919 + 11)
+		var pubkey bls12381.G1Affine
+		pubkey.ScalarMultiplication(&g1, secrets[i])
+		committee.Pubkeys = append(committee.Pubkeys, pubkey.Bytes())
+		committee.Balances = append(committee.Balances, 32e9)
+	}
+	return committee, secrets
+}
+
+// Returns the attestation of the committee for the link by the members whose bits are set.
+func testAttestation(t *testing.T, committee *Committee, secrets []*big.Int, bits []bool) Attestation {
+	attestation := Attestation{BeaconBlockRoot: common.HexToHash("0x03"), Bits: bits}
+	root := config.SigningRoot(committee, attestation.BeaconBlockRoot, source, target)
+	hash, err := bls12381.HashToG2(root[:], bls.DST)
+	assert.NoError(t, err)
+	var signature bls12381.G2Affine
+	for i := range secrets {
+		if bits[i] {
+			var partial bls12381.G2Affine
+			partial.ScalarMultiplication(&hash, secrets[i])
+			signature.Add(&signature, &partial)
+		}
+	}
+	attestation.Signature = signature.Bytes()
+	return attestation
+}
+
+func isSolved(committees []Committee, attestations []Attestation, input []byte) error {
+	circuit := NewCircuit(config)
+	if err := circuit.SetAttestations(committees, attestations); err != nil {
+		return err
+	}
+	return functiontest.IsSolved(circuit, input)
+}
+
+func TestCircuit(t *testing.T) {
+	first, firstSecrets := testCommittee(8000032, 0, 3)
+	second, secondSecrets := testCommittee(8000032, 1, 4)
+	committees := []Committee{first, second}
+	commitment, err := config.Commitment(committees)
+	assert.NoError(t, err)
+	input := config.Input(commitment, 7*32e9, source, target)
+
+	// Five of seven validators are a supermajority.
+	attestations := []Attestation{
+		testAttestation(t, &first, firstSecrets, []bool{true, true, false}),
+		testAttestation(t, &second, secondSecrets, []bool{true, false, true, true}),
+	}
+	assert.NoError(t, isSolved(committees, attestations, input))
+	result := outputs(input, committees, attestations)
+	assert.Equal(t, uint64(5*32e9), new(big.Int).SetBytes(result[:8]).Uint64())
+	assert.Equal(t, uint64(1), new(big.Int).SetBytes(result[8:]).Uint64())
+
+	// A committee without attestation is skipped, and two of seven are not a supermajority.
+	skipped := []Attestation{attestations[0], {}}
+	assert.NoError(t, isSolved(committees, skipped, input))
+	result = outputs(input, committees, skipped)
+	assert.Equal(t, uint64(2*32e9), new(big.Int).SetBytes(result[:8]).Uint64())
+	assert.Equal(t, uint64(0), new(big.Int).SetBytes(result[8:]).Uint64())
+
+	// The bits must match the signature.
+	forged := []Attestation{attestations[0], attestations[1]}
+	forged[0].Bits = []bool{true, true, true}
+	assert.Error(t, isSolved(committees, forged, input))
+
+	// The committees must match the commitment.
+	other := config.Input(big.NewInt(1), 7*32e9, source, target)
+	assert.Error(t, isSolved(committees, attestations, other))
+}
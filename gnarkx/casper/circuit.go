@@ -0,0 +1,298 @@
+package casper
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/ssz"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A committee of the commitment and its aggregate attestation, where the members past the size
+// are zero and a committee without any bit set is skipped.
+type Aggregate struct {
+	Slot     vars.U64
+	Index    vars.U64
+	Size     vars.Variable
+	Pubkeys  [][bls.G1Length]vars.Byte
+	Balances []vars.U64
+
+	BeaconBlockRoot [32]vars.Byte
+	Bits            []vars.Bool
+	Signature       [bls.G2Length]vars.Byte
+}
+
+// Circuit proves the attesting balance of a link from aggregate attestations of committees.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The committees of the commitment, one per position.
+	Aggregates []Aggregate
+
+	config       *Config       `gnark:"-"`
+	committees   []Committee   `gnark:"-"`
+	attestations []Attestation `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	isPowerOf2 := func(n int) bool { return n > 0 && n&(n-1) == 0 }
+	if !isPowerOf2(config.MaxCommittees) || !isPowerOf2(config.MaxCommitteeSize) {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	aggregates := make([]Aggregate, config.MaxCommittees)
+	for i := 0; i < len(aggregates); i++ {
+		aggregates[i] = Aggregate{
+			Slot:            vars.NewU64(),
+			Index:           vars.NewU64(),
+			Size:            vars.NewVariable(),
+			Pubkeys:         make([][bls.G1Length]vars.Byte, config.MaxCommitteeSize),
+			Balances:        make([]vars.U64, config.MaxCommitteeSize),
+			BeaconBlockRoot: vars.NewBytes32(),
+			Bits:            make([]vars.Bool, config.MaxCommitteeSize),
+		}
+		for j := 0; j < config.MaxCommitteeSize; j++ {
+			for k := 0; k < bls.G1Length; k++ {
+				aggregates[i].Pubkeys[j][k] = vars.NewByte()
+			}
+			aggregates[i].Balances[j] = vars.NewU64()
+			aggregates[i].Bits[j] = vars.NewBool(false)
+		}
+		for k := 0; k < bls.G2Length; k++ {
+			aggregates[i].Signature[k] = vars.NewByte()
+		}
+	}
+	return &Circuit{
+		InputBytes:  vars.NewBytes(32 + 8 + 2*(8+32)),
+		OutputBytes: vars.NewBytes(8 + 8),
+		Aggregates:  aggregates,
+		config:      config,
+	}
+}
+
+// Sets the committees of the commitment and their attestations, aligned with the committees,
+// that the next call to SetWitness assigns. An attestation without bits skips its committee.
+func (c *Circuit) SetAttestations(committees []Committee, attestations []Attestation) error {
+	if err := c.config.checkCommittees(committees); err != nil {
+		return err
+	}
+	if len(attestations) != len(committees) {
+		return fmt.Errorf("%d attestations of %d committees", len(attestations), len(committees))
+	}
+	for i, attestation := range attestations {
+		if len(attestation.Bits) == 0 {
+			continue
+		}
+		if len(attestation.Bits) != len(committees[i].Pubkeys) {
+			return fmt.Errorf("attestation %d has %d bits, expected %d", i, len(attestation.Bits), len(committees[i].Pubkeys))
+		}
+		var signature bls12381.G2Affine
+		if _, err := signature.SetBytes(attestation.Signature[:]); err != nil {
+			return fmt.Errorf("attestation %d: invalid signature: %w", i, err)
+		}
+	}
+	c.committees = committees
+	c.attestations = attestations
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the committees and attestations given to SetAttestations.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.committees == nil {
+		panic("attestations must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	for i := 0; i < len(c.Aggregates); i++ {
+		var committee Committee
+		var attestation Attestation
+		if i < len(c.committees) {
+			committee, attestation = c.committees[i], c.attestations[i]
+		}
+		aggregate := &c.Aggregates[i]
+		aggregate.Slot.Set(committee.Slot)
+		aggregate.Index.Set(committee.Index)
+		aggregate.Size = vars.NewVariableFromInt(len(committee.Pubkeys))
+		for j := 0; j < c.config.MaxCommitteeSize; j++ {
+			var pubkey [bls.G1Length]byte
+			var balance uint64
+			if j < len(committee.Pubkeys) {
+				pubkey, balance = committee.Pubkeys[j], committee.Balances[j]
+			}
+			for k := 0; k < bls.G1Length; k++ {
+				aggregate.Pubkeys[j][k].Set(pubkey[k])
+			}
+			aggregate.Balances[j].Set(balance)
+			aggregate.Bits[j] = vars.NewBool(j < len(attestation.Bits) && attestation.Bits[j])
+		}
+		vars.SetBytes32(&aggregate.BeaconBlockRoot, attestation.BeaconBlockRoot)
+		for k := 0; k < bls.G2Length; k++ {
+			aggregate.Signature[k].Set(attestation.Signature[k])
+		}
+	}
+	vars.SetBytes(&c.OutputBytes, outputs(inputBytes, c.committees, c.attestations))
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	commitment := inputReader.ReadBytes32()
+	totalBalance := inputReader.ReadUint64()
+	sourceEpoch := inputReader.ReadUint64()
+	sourceRoot := inputReader.ReadBytes32()
+	targetEpoch := inputReader.ReadUint64()
+	targetRoot := inputReader.ReadBytes32()
+	config := c.config
+
+	sszAPI := ssz.NewAPI(api)
+	sourceLeaf := sszAPI.HashTreeRoot([][32]vars.Byte{api.ToBytes32FromU64LE(sourceEpoch), sourceRoot}, 2)
+	targetLeaf := sszAPI.HashTreeRoot([][32]vars.Byte{api.ToBytes32FromU64LE(targetEpoch), targetRoot}, 2)
+	domain := config.domain()
+	var domainBytes [32]vars.Byte
+	copy(domainBytes[:], vars.NewBytesFrom(domain[:]))
+
+	rc := rangecheck.New(fapi)
+	blsAPI := bls.NewAPI(api)
+	pubkeyGenerator, signatureGenerator := generatorBytes()
+	one := vars.NewVariableFromInt(1)
+	zero := vars.NewVariableFromInt(0)
+	attesting := zero
+	leaves := make([]vars.Variable, config.MaxCommittees)
+	for i := 0; i < config.MaxCommittees; i++ {
+		aggregate := &c.Aggregates[i]
+		api.AssertIsLessOrEqual(aggregate.Size, vars.NewVariableFromInt(config.MaxCommitteeSize))
+
+		// The leaves of the members, where only the bits of members of the committee may be set.
+		active := one
+		nbBits := zero
+		members := make([]vars.Variable, config.MaxCommitteeSize)
+		pubkeys := make([]sw_bls12381.G1Affine, config.MaxCommitteeSize)
+		for j := 0; j < config.MaxCommitteeSize; j++ {
+			active = api.Sub(active, api.IsZero(api.Sub(aggregate.Size, vars.NewVariableFromInt(j))).Value)
+			bit := aggregate.Bits[j].Value
+			fapi.AssertIsBoolean(bit.Value)
+			api.AssertIsEqual(api.Mul(bit, api.Sub(one, active)), zero)
+			nbBits = api.Add(nbBits, bit)
+
+			for k := 0; k < bls.G1Length; k++ {
+				rc.Check(aggregate.Pubkeys[j][k].Value.Value, 8)
+			}
+			balance := aggregate.Balances[j].Value
+			api.ToBinaryLE(balance, 64)
+			attesting = api.Add(attesting, api.Mul(bit, balance))
+			members[j] = poseidon.Hash(*api, []vars.Variable{
+				api.ToVariableFromBytesBE(aggregate.Pubkeys[j][:limbLength]),
+				api.ToVariableFromBytesBE(aggregate.Pubkeys[j][limbLength:]),
+				balance,
+			})
+
+			// The key of a member past the size is replaced by the generator, which is never selected.
+			var pubkey [bls.G1Length]vars.Byte
+			for k := 0; k < bls.G1Length; k++ {
+				pubkey[k] = api.SelectByte(vars.Bool{Value: active}, aggregate.Pubkeys[j][k], pubkeyGenerator[k])
+			}
+			pubkeys[j] = *blsAPI.DecompressG1(pubkey)
+		}
+		index := api.ToBytes32FromU64LE(aggregate.Index)
+		if config.Electra {
+			index = vars.NewBytes32()
+		}
+		leaves[i] = poseidon.Hash(*api, []vars.Variable{
+			aggregate.Slot.Value,
+			aggregate.Index.Value,
+			aggregate.Size,
+			merkleRootOf(*api, members),
+		})
+
+		// The members whose bits are set sign the attestation data of the link, unless none is.
+		dataRoot := sszAPI.HashTreeRoot([][32]vars.Byte{
+			api.ToBytes32FromU64LE(aggregate.Slot), index, aggregate.BeaconBlockRoot,
+			sourceLeaf, targetLeaf, vars.NewBytes32(), vars.NewBytes32(), vars.NewBytes32(),
+		}, 8)
+		signingRoot := sha256.Hash(*api, append(dataRoot[:], domainBytes[:]...))
+		enabled := api.Sub(one, api.IsZero(nbBits).Value)
+		var signature [bls.G2Length]vars.Byte
+		for k := 0; k < bls.G2Length; k++ {
+			rc.Check(aggregate.Signature[k].Value.Value, 8)
+			signature[k] = api.SelectByte(vars.Bool{Value: enabled}, aggregate.Signature[k], signatureGenerator[k])
+		}
+		for k := 0; k < 32; k++ {
+			rc.Check(aggregate.BeaconBlockRoot[k].Value.Value, 8)
+		}
+		pubkey := blsAPI.AggregatePubkeys(pubkeys, aggregate.Bits)
+		blsAPI.VerifyIf(enabled.Value, pubkey, signingRoot[:], blsAPI.DecompressG2(signature))
+	}
+	api.AssertIsEqual(merkleRootOf(*api, leaves), api.ToVariableFromBytesBE(commitment[:]))
+
+	// The link has a supermajority if 3 * attesting >= 2 * total.
+	cmp := api.Cmp(api.Mul(attesting, vars.NewVariableFromInt(3)), api.Mul(totalBalance.Value, vars.NewVariableFromInt(2)))
+	supermajority := api.Sub(one, api.IsZero(api.Add(cmp, one)).Value)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteU64(vars.U64{Value: attesting})
+	outputWriter.WriteU64(vars.U64{Value: supermajority})
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Returns the outputs of the circuit for the input and the committees and their attestations.
+func outputs(inputBytes []byte, committees []Committee, attestations []Attestation) []byte {
+	totalBalance := new(big.Int).SetBytes(inputBytes[32:40])
+	attesting := new(big.Int)
+	for i, attestation := range attestations {
+		for j, bit := range attestation.Bits {
+			if bit {
+				attesting.Add(attesting, new(big.Int).SetUint64(committees[i].Balances[j]))
+			}
+		}
+	}
+	supermajority := uint64(0)
+	if new(big.Int).Mul(attesting, big.NewInt(3)).Cmp(new(big.Int).Mul(totalBalance, big.NewInt(2))) >= 0 {
+		supermajority = 1
+	}
+	output := attesting.FillBytes(make([]byte, 8))
+	return append(output, new(big.Int).SetUint64(supermajority).FillBytes(make([]byte, 8))...)
+}
+
+// Computes the root of the binary Merkle tree of Poseidon over the nodes in the circuit.
+func merkleRootOf(api builder.API, nodes []vars.Variable) vars.Variable {
+	nodes = append([]vars.Variable{}, nodes...)
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = poseidon.Hash(api, []vars.Variable{nodes[2*i], nodes[2*i+1]})
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
+
+// Returns the compressed generators of G1 and G2.
+func generatorBytes() ([]vars.Byte, []vars.Byte) {
+	_, _, g1, g2 := bls12381.Generators()
+	compressedG1, compressedG2 := g1.Bytes(), g2.Bytes()
+	return vars.NewBytesFrom(compressedG1[:]), vars.NewBytesFrom(compressedG2[:])
+}
@@ -0,0 +1,317 @@
+// The API for verifying reports of Chainlink Offchain Reporting (OCR), which the oracles of a DON
+// sign and transmit on-chain, so that circuits can prove that a feed reported a price at a time.
+//
+// As in OCR2Abstract and OCR2Aggregator, a report is signed under the report context, the config
+// digest followed by the epoch and round and the extra hash, and the signers sign the hash
+// keccak256(keccak256(report) || reportContext). A report is accepted with f + 1 signatures by
+// distinct signers of the config, which is identified by its digest: the keccak256 hash of the ABI
+// encoding of the arguments of the config, with its first two bytes replaced by a prefix of the
+// kind of contract. The same scheme is used by the Data Streams (OCR3) verifiers with a prefix
+// and a config that starts with the ID of the feed. Reference:
+// https://github.com/smartcontractkit/libocr/blob/master/contract2/OCR2Aggregator.sol
+package chainlink
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum number of oracles of a config.
+const MaxOracles = 31
+
+// The layout of the configs of a kind of contract: the prefix of its config digests, the number of
+// words of the head of the ABI encoding of a config, and the indices of the words of the offset of
+// the signers, which are the first dynamic argument, and of f.
+type ConfigFormat struct {
+	Prefix      uint16
+	HeadWords   int
+	SignersWord int
+	FWord       int
+}
+
+var (
+	// The configs of OCR2 contracts such as OCR2Aggregator: chainId, contractAddress, configCount,
+	// signers, transmitters, f, onchainConfig, offchainConfigVersion and offchainConfig.
+	OCR2 = ConfigFormat{Prefix: 0x0001, HeadWords: 9, SignersWord: 3, FWord: 5}
+
+	// The configs of the Data Streams verifiers, which start with the feedId and have the same
+	// arguments as OCR2 otherwise.
+	DataStreams = ConfigFormat{Prefix: 0x0006, HeadWords: 10, SignersWord: 4, FWord: 6}
+)
+
+// ChainlinkAPI is a wrapper around succinct.API that provides methods for verifying OCR reports.
+type ChainlinkAPI struct {
+	api builder.API
+}
+
+// Creates a new ChainlinkAPI.
+func NewAPI(api *builder.API) *ChainlinkAPI {
+	return &ChainlinkAPI{api: *api}
+}
+
+// Returns the digest of the ABI encoded config.
+func (f *ConfigFormat) Digest(encoding []byte) common.Hash {
+	digest := crypto.Keccak256Hash(encoding)
+	digest[0], digest[1] = byte(f.Prefix>>8), byte(f.Prefix)
+	return digest
+}
+
+// Returns the hash the signers sign for the report under the report context.
+func ReportHash(report []byte, reportContext [3]common.Hash) common.Hash {
+	return crypto.Keccak256Hash(crypto.Keccak256(report), reportContext[0][:], reportContext[1][:], reportContext[2][:])
+}
+
+// An ABI encoded config in the circuit, right padded with zeros.
+type Config struct {
+	Encoding []vars.Byte
+	Length   vars.Variable
+}
+
+// Creates a new config of at most maxLength bytes.
+func NewConfig(maxLength int) Config {
+	return Config{Encoding: vars.NewBytes(maxLength), Length: vars.NewVariable()}
+}
+
+// Sets the ABI encoded config.
+func (c *Config) Set(encoding []byte) error {
+	if len(encoding) > len(c.Encoding) {
+		return fmt.Errorf("config of %d bytes, expected at most %d", len(encoding), len(c.Encoding))
+	}
+	padded := make([]byte, len(c.Encoding))
+	copy(padded, encoding)
+	vars.SetBytes(&c.Encoding, padded)
+	c.Length = vars.NewVariableFromInt(len(encoding))
+	return nil
+}
+
+// A report in the circuit, right padded with zeros, with its report context.
+type Report struct {
+	Context [3][32]vars.Byte
+	Data    []vars.Byte
+	Length  vars.Variable
+}
+
+// Creates a new report of at most maxLength bytes.
+func NewReport(maxLength int) Report {
+	var context [3][32]vars.Byte
+	for i := 0; i < len(context); i++ {
+		context[i] = vars.NewBytes32()
+	}
+	return Report{Context: context, Data: vars.NewBytes(maxLength), Length: vars.NewVariable()}
+}
+
+// Sets the report and its report context.
+func (r *Report) Set(report []byte, reportContext [3]common.Hash) error {
+	if len(report) > len(r.Data) {
+		return fmt.Errorf("report of %d bytes, expected at most %d", len(report), len(r.Data))
+	}
+	for i := 0; i < len(reportContext); i++ {
+		vars.SetBytes32(&r.Context[i], reportContext[i])
+	}
+	padded := make([]byte, len(r.Data))
+	copy(padded, report)
+	vars.SetBytes(&r.Data, padded)
+	r.Length = vars.NewVariableFromInt(len(report))
+	return nil
+}
+
+// A signature of a report by the signer of the config at the index, where v is 0 or 1 as in the
+// rawVs of transmit.
+type Signature struct {
+	Index vars.Variable
+	R     [32]vars.Byte
+	S     [32]vars.Byte
+	V     vars.Variable
+}
+
+// Creates new signatures of reports of configs where f < maxSignatures.
+func NewSignatures(maxSignatures int) []Signature {
+	signatures := make([]Signature, maxSignatures)
+	for i := 0; i < maxSignatures; i++ {
+		signatures[i] = Signature{Index: vars.NewVariable(), R: vars.NewBytes32(), S: vars.NewBytes32(), V: vars.NewVariable()}
+	}
+	return signatures
+}
+
+// Sets the signatures r || s || v by the signers at the indices, which are ascending, of which
+// the circuit reads the first f + 1.
+func SetSignatures(s *[]Signature, indices []int, signatures [][]byte) error {
+	if len(indices) != len(signatures) || len(signatures) > len(*s) {
+		return fmt.Errorf("%d indices and %d signatures, expected at most %d", len(indices), len(signatures), len(*s))
+	}
+	for i := 0; i < len(*s); i++ {
+		signature := &(*s)[i]
+		if i >= len(signatures) {
+			*signature = NewSignatures(1)[0]
+			continue
+		}
+		if len(signatures[i]) != 65 || signatures[i][64] > 1 || (i > 0 && indices[i] <= indices[i-1]) {
+			return fmt.Errorf("signature %d is invalid", i)
+		}
+		signature.Index = vars.NewVariableFromInt(indices[i])
+		vars.SetBytes32(&signature.R, [32]byte(signatures[i][:32]))
+		vars.SetBytes32(&signature.S, [32]byte(signatures[i][32:64]))
+		signature.V = vars.NewVariableFromInt(int(signatures[i][64]))
+	}
+	return nil
+}
+
+// Verifies that the report was signed under the config of the format by f + 1 distinct signers,
+// where the config digest of the report context is the digest of the config. The caller verifies
+// the config digest, for instance against the latest config of the contract.
+func (a *ChainlinkAPI) VerifyReport(format ConfigFormat, config Config, report Report, signatures []Signature) {
+	api := a.api
+	one := vars.NewVariableFromInt(1)
+	zero := vars.NewVariableFromInt(0)
+	encoding := config.Encoding
+	word := func(i int) []vars.Byte {
+		return encoding[32*i : 32*(i+1)]
+	}
+
+	// The config has the digest of the report context, and its signers are the first dynamic
+	// argument.
+	digest := keccak256.HashVariable(api, encoding, config.Length)
+	configDigest := report.Context[0]
+	api.AssertIsEqual(configDigest[0].Value, vars.NewVariableFromInt(int(format.Prefix>>8)))
+	api.AssertIsEqual(configDigest[1].Value, vars.NewVariableFromInt(int(format.Prefix&0xff)))
+	for i := 2; i < 32; i++ {
+		api.AssertIsEqualByte(configDigest[i], digest[i])
+	}
+	signersOffset := new(big.Int).SetUint64(uint64(32 * format.HeadWords)).FillBytes(make([]byte, 32))
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(word(format.SignersWord)[i].Value, vars.NewVariableFromInt(int(signersOffset[i])))
+	}
+	nbSigners := fromWord(api, word(format.HeadWords))
+	f := fromWord(api, word(format.FWord))
+	api.AssertIsDifferent(nbSigners, zero)
+	api.AssertIsLessOrEqual(nbSigners, vars.NewVariableFromInt(MaxOracles))
+	api.AssertIsDifferent(f, zero)
+	api.AssertIsLessOrEqual(f, vars.NewVariableFromInt(len(signatures)-1))
+	nbPositions := MaxOracles
+	if available := len(encoding)/32 - format.HeadWords - 1; available < nbPositions {
+		nbPositions = available
+	}
+	api.AssertIsLessOrEqual(nbSigners, vars.NewVariableFromInt(nbPositions))
+	signersEnd := api.Mul(api.Add(nbSigners, vars.NewVariableFromInt(format.HeadWords+1)), vars.NewVariableFromInt(32))
+	api.AssertIsLessOrEqual(signersEnd, config.Length)
+
+	reportHash := keccak256.HashVariable(api, report.Data, report.Length)
+	message := append([]vars.Byte{}, reportHash[:]...)
+	for i := 0; i < len(report.Context); i++ {
+		message = append(message, report.Context[i][:]...)
+	}
+	hash := keccak256.Hash(api, message)
+
+	var dummyHash, dummyR, dummyS [32]vars.Byte
+	copy(dummyHash[:], vars.NewBytesFrom(dummyDigest))
+	copy(dummyR[:], vars.NewBytesFrom(dummySignature[:32]))
+	copy(dummyS[:], vars.NewBytesFrom(dummySignature[32:64]))
+	dummyV := vars.NewVariableFromInt(int(dummySignature[64]))
+
+	// The first f + 1 signatures are by signers at ascending indices, and others recover a
+	// constant signature.
+	used := one
+	for k, signature := range signatures {
+		if k > 0 {
+			used = api.Sub(used, api.IsZero(api.Sub(f, vars.NewVariableFromInt(k-1))).Value)
+			next := api.Add(signatures[k-1].Index, one)
+			api.AssertIsLessOrEqual(next, api.Select(vars.Bool{Value: used}, signature.Index, next))
+		}
+		isUsed := vars.Bool{Value: used}
+		api.AssertIsLessOrEqual(api.Mul(used, api.Add(signature.Index, one)), nbSigners)
+		x, y := compat.ECRecover(
+			api,
+			api.SelectBytes32(isUsed, hash, dummyHash),
+			api.Select(isUsed, api.Add(signature.V, vars.NewVariableFromInt(27)), dummyV),
+			api.SelectBytes32(isUsed, signature.R, dummyR),
+			api.SelectBytes32(isUsed, signature.S, dummyS),
+		)
+		key := keccak256.Hash(api, append(x[:], y[:]...))
+
+		// The recovered address is the signer at the index.
+		var signer [20]vars.Variable
+		for i := 0; i < 20; i++ {
+			signer[i] = zero
+		}
+		for j := 0; j < nbPositions; j++ {
+			isIndex := api.IsZero(api.Sub(signature.Index, vars.NewVariableFromInt(j))).Value
+			for i := 0; i < 20; i++ {
+				signer[i] = api.Add(signer[i], api.Mul(isIndex, word(format.HeadWords + 1 + j)[12+i].Value))
+			}
+		}
+		for i := 0; i < 20; i++ {
+			api.AssertIsEqual(api.Mul(used, api.Sub(key[12+i].Value, signer[i])), zero)
+		}
+	}
+}
+
+// Decodes the median report of OCR2Aggregator, abi.encode(uint32 observationsTimestamp, bytes32
+// rawObservers, int192[] observations, int192 juelsPerFeeCoin), of at most len(report.Data) / 32 -
+// 5 observations sorted by value, and returns the timestamp of the observations and the median
+// observation, the answer of the round, as a sign extended ABI word.
+func (a *ChainlinkAPI) MedianAnswer(report Report) (vars.U64, [32]vars.Byte) {
+	api := a.api
+	data := report.Data
+	zero := vars.NewVariableFromInt(0)
+	maxObservations := len(data)/32 - 5
+	api.AssertIsEqual(fromWord(api, data[64:96]), vars.NewVariableFromInt(128))
+	nbObservations := fromWord(api, data[128:160])
+	api.AssertIsDifferent(nbObservations, zero)
+	api.AssertIsLessOrEqual(nbObservations, vars.NewVariableFromInt(maxObservations))
+	api.AssertIsEqual(report.Length, api.Add(api.Mul(nbObservations, vars.NewVariableFromInt(32)), vars.NewVariableFromInt(160)))
+
+	// The median is the observation at index floor(n / 2).
+	var median [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		median[i] = vars.Byte{Value: zero}
+	}
+	for k := 0; 2*k <= maxObservations && k < maxObservations; k++ {
+		isMedian := api.Add(
+			api.IsZero(api.Sub(nbObservations, vars.NewVariableFromInt(2*k))).Value,
+			api.IsZero(api.Sub(nbObservations, vars.NewVariableFromInt(2*k+1))).Value,
+		)
+		observation := data[160+32*k : 192+32*k]
+		for i := 0; i < 32; i++ {
+			median[i] = vars.Byte{Value: api.Add(median[i].Value, api.Mul(isMedian, observation[i].Value))}
+		}
+	}
+	return vars.U64{Value: fromWord(api, data[:32])}, median
+}
+
+// Returns the integer of an ABI word of a uint64, whose other bytes are asserted to be zero.
+func fromWord(api builder.API, word []vars.Byte) vars.Variable {
+	value := vars.NewVariableFromInt(0)
+	for i := 0; i < 32; i++ {
+		if i < 24 {
+			api.AssertIsEqual(word[i].Value, vars.NewVariableFromInt(0))
+			continue
+		}
+		api.ToBinaryLE(word[i].Value, 8)
+		value = api.Add(api.Mul(value, vars.NewVariableFromInt(256)), word[i].Value)
+	}
+	return value
+}
+
+// A valid signature of a constant hash, recovered in place of unused signatures.
+var (
+	dummyDigest    = crypto.Keccak256([]byte("chainlink"))
+	dummySignature = func() []byte {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{1}, 32))
+		if err != nil {
+			panic(err)
+		}
+		signature, err := crypto.Sign(dummyDigest, key)
+		if err != nil {
+			panic(err)
+		}
+		signature[64] += 27
+		return signature
+	}()
+)
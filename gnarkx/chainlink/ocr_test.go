@@ -0,0 +1,130 @@
+package chainlink
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Config     Config
+	Report     Report
+	Signatures []Signature
+	Timestamp  vars.U64
+	Median     [32]vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	chainlinkAPI := NewAPI(api)
+	chainlinkAPI.VerifyReport(OCR2, c.Config, c.Report, c.Signatures)
+	timestamp, median := chainlinkAPI.MedianAnswer(c.Report)
+	api.AssertIsEqual(timestamp.Value, c.Timestamp.Value)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(median[i], c.Median[i])
+	}
+	return nil
+}
+
+func newTestCircuit() *testCircuit {
+	return &testCircuit{Config: NewConfig(1024), Report: NewReport(160 + 32*8), Signatures: NewSignatures(3)}
+}
+
+// Returns the keys of four oracles and the ABI encoding of their config with f = 1.
+func testConfig(t *testing.T) ([]*ecdsa.PrivateKey, []byte) {
+	newType := func(name string) abi.Type {
+		typ, err := abi.NewType(name, "", nil)
+		assert.NoError(t, err)
+		return typ
+	}
+	arguments := abi.Arguments{
+		{Type: newType("uint256")}, {Type: newType("address")}, {Type: newType("uint64")},
+		{Type: newType("address[]")}, {Type: newType("address[]")}, {Type: newType("uint8")},
+		{Type: newType("bytes")}, {Type: newType("uint64")}, {Type: newType("bytes")},
+	}
+	keys := make([]*ecdsa.PrivateKey, 4)
+	signers := make([]common.Address, len(keys))
+	transmitters := make([]common.Address, len(keys))
+	for i := 0; i < len(keys); i++ {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{byte(i + 2)}, 32))
+		assert.NoError(t, err)
+		keys[i] = key
+		signers[i] = crypto.PubkeyToAddress(key.PublicKey)
+		transmitters[i] = common.BigToAddress(big.NewInt(int64(100 + i)))
+	}
+	onchainConfig := append(common.LeftPadBytes([]byte{1}, 32), make([]byte, 64)...)
+	encoding, err := arguments.Pack(
+		big.NewInt(1), common.HexToAddress("0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419"), uint64(3),
+		signers, transmitters, uint8(1), onchainConfig, uint64(2), []byte("offchain config of the test"),
+	)
+	assert.NoError(t, err)
+	return keys, encoding
+}
+
+// Returns a median report of the observations at the timestamp.
+func testReport(timestamp uint64, observations []int64) []byte {
+	report := math.U256Bytes(new(big.Int).SetUint64(timestamp))
+	report = append(report, common.LeftPadBytes([]byte{0, 1, 2, 3}, 32)...)
+	report = append(report, math.U256Bytes(big.NewInt(128))...)
+	report = append(report, math.U256Bytes(big.NewInt(1e18))...)
+	report = append(report, math.U256Bytes(big.NewInt(int64(len(observations))))...)
+	for _, observation := range observations {
+		report = append(report, math.U256Bytes(big.NewInt(observation))...)
+	}
+	return report
+}
+
+func TestVerifyReport(t *testing.T) {
+	keys, encoding := testConfig(t)
+	digest := OCR2.Digest(encoding)
+	context := [3]common.Hash{digest, common.HexToHash("0x0100"), {}}
+	report := testReport(1700000000, []int64{100, 101, 103, 110})
+	hash := ReportHash(report, context)
+	sign := func(signers ...int) [][]byte {
+		var signatures [][]byte
+		for _, i := range signers {
+			signature, err := crypto.Sign(hash[:], keys[i])
+			assert.NoError(t, err)
+			signatures = append(signatures, signature)
+		}
+		return signatures
+	}
+	newAssignment := func(indices []int, signatures [][]byte) *testCircuit {
+		assignment := newTestCircuit()
+		assert.NoError(t, assignment.Config.Set(encoding))
+		assert.NoError(t, assignment.Report.Set(report, context))
+		assert.NoError(t, SetSignatures(&assignment.Signatures, indices, signatures))
+		assignment.Timestamp.Set(1700000000)
+		vars.SetBytes32(&assignment.Median, common.BigToHash(big.NewInt(103)))
+		return assignment
+	}
+
+	// Two of four oracles sign, and the median is the third observation.
+	assignment := newAssignment([]int{1, 3}, sign(1, 3))
+	assert.NoError(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The signers must be at their indices.
+	assignment = newAssignment([]int{1, 2}, sign(1, 3))
+	assert.Error(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The signers must be distinct.
+	assignment = newAssignment([]int{1, 3}, sign(1, 3))
+	assignment.Signatures[1] = assignment.Signatures[0]
+	assert.Error(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The config must have the digest of the report context.
+	assignment = newAssignment([]int{1, 3}, sign(1, 3))
+	assignment.Config.Encoding[0].Set(7)
+	assert.Error(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,196 @@
+// The AES-128 block cipher and the GCM authenticated encryption mode, on states of bits so that the
+// xors of the rounds cost a single constraint per bit. The S-box is a lookup table.
+package aes
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of rounds of AES-128.
+const rounds = 10
+
+// A block as 16 bytes of little-endian bits.
+type block [16][8]frontend.Variable
+
+// The AES S-box, computed from the multiplicative inverses in GF(2^8) and the affine transformation.
+var sbox = func() [256]byte {
+	var s [256]byte
+	rotl := func(x byte, n uint) byte { return x<<n | x>>(8-n) }
+	p, q := byte(1), byte(1)
+	for {
+		// p is multiplied by 3 and q divided by 3, so that q is the inverse of p.
+		if p&0x80 != 0 {
+			p = p ^ p<<1 ^ 0x1b
+		} else {
+			p = p ^ p<<1
+		}
+		q ^= q << 1
+		q ^= q << 2
+		q ^= q << 4
+		if q&0x80 != 0 {
+			q ^= 0x09
+		}
+		s[p] = q ^ rotl(q, 1) ^ rotl(q, 2) ^ rotl(q, 3) ^ rotl(q, 4) ^ 0x63
+		if p == 1 {
+			break
+		}
+	}
+	s[0] = 0x63
+	return s
+}()
+
+// An AES-128 cipher with a key only known at proving time.
+type Cipher struct {
+	api       builder.API
+	sbox      *logderivlookup.Table
+	roundKeys [rounds + 1]block
+}
+
+// Creates a new cipher and expands the key. The bytes of the key are assumed to be range checked.
+func NewCipher(api builder.API, key [16]vars.Byte) *Cipher {
+	fapi := api.FrontendAPI()
+	table := logderivlookup.New(fapi)
+	for i := 0; i < len(sbox); i++ {
+		table.Insert(int(sbox[i]))
+	}
+	c := &Cipher{api: api, sbox: table}
+
+	// The key schedule of FIPS 197, section 5.2, with words w[i] = roundKeys[i/4][4(i%4):4(i%4)+4].
+	for i := 0; i < 16; i++ {
+		c.roundKeys[0][i] = c.toBits(key[i].Value.Value)
+	}
+	rcon := byte(1)
+	for r := 1; r <= rounds; r++ {
+		prev := c.roundKeys[r-1]
+		var temp [4][8]frontend.Variable
+		for j := 0; j < 4; j++ {
+			temp[j] = c.subByte(prev[12+(j+1)%4])
+		}
+		temp[0] = xorConstant(fapi, temp[0], rcon)
+		for j := 0; j < 16; j++ {
+			if j >= 4 {
+				temp[j%4] = c.roundKeys[r][j-4]
+			}
+			c.roundKeys[r][j] = xorByte(fapi, prev[j], temp[j%4])
+		}
+		rcon = xtimeConstant(rcon)
+	}
+	return c
+}
+
+// Encrypts a block.
+func (c *Cipher) Encrypt(in [16]vars.Byte) [16]vars.Byte {
+	var state block
+	for i := 0; i < 16; i++ {
+		state[i] = c.toBits(in[i].Value.Value)
+	}
+	return fromBlock(c.api, c.encrypt(state))
+}
+
+func (c *Cipher) encrypt(in block) block {
+	fapi := c.api.FrontendAPI()
+	state := xorBlock(fapi, in, c.roundKeys[0])
+	for r := 1; r <= rounds; r++ {
+		// SubBytes and ShiftRows, where byte i of the state is row i % 4 of column i / 4.
+		var shifted block
+		for row := 0; row < 4; row++ {
+			for col := 0; col < 4; col++ {
+				shifted[row+4*col] = c.subByte(state[row+4*((col+row)%4)])
+			}
+		}
+		if r < rounds {
+			shifted = mixColumns(fapi, shifted)
+		}
+		state = xorBlock(fapi, shifted, c.roundKeys[r])
+	}
+	return state
+}
+
+func (c *Cipher) toBits(value frontend.Variable) [8]frontend.Variable {
+	bits := c.api.ToBinaryLE(vars.Variable{Value: value}, 8)
+	var result [8]frontend.Variable
+	for i := 0; i < 8; i++ {
+		result[i] = bits[i].Value.Value
+	}
+	return result
+}
+
+func (c *Cipher) subByte(in [8]frontend.Variable) [8]frontend.Variable {
+	return c.toBits(c.sbox.Lookup(fromBits(c.api.FrontendAPI(), in))[0])
+}
+
+func mixColumns(api frontend.API, in block) block {
+	var out block
+	for col := 0; col < 4; col++ {
+		a := in[4*col : 4*col+4]
+		var t [4][8]frontend.Variable
+		for i := 0; i < 4; i++ {
+			t[i] = xtime(api, a[i])
+		}
+		// 2a0 + 3a1 + a2 + a3, and its rotations.
+		for row := 0; row < 4; row++ {
+			out[4*col+row] = xorByte(api, t[row], t[(row+1)%4], a[(row+1)%4], a[(row+2)%4], a[(row+3)%4])
+		}
+	}
+	return out
+}
+
+// Multiplies a byte by x in GF(2^8) modulo x^8 + x^4 + x^3 + x + 1.
+func xtime(api frontend.API, a [8]frontend.Variable) [8]frontend.Variable {
+	return [8]frontend.Variable{
+		a[7], api.Xor(a[0], a[7]), a[1], api.Xor(a[2], a[7]), api.Xor(a[3], a[7]), a[4], a[5], a[6],
+	}
+}
+
+func xtimeConstant(a byte) byte {
+	if a&0x80 != 0 {
+		return a<<1 ^ 0x1b
+	}
+	return a << 1
+}
+
+func xorByte(api frontend.API, in ...[8]frontend.Variable) [8]frontend.Variable {
+	result := in[0]
+	for i := 1; i < len(in); i++ {
+		for j := 0; j < 8; j++ {
+			result[j] = api.Xor(result[j], in[i][j])
+		}
+	}
+	return result
+}
+
+func xorConstant(api frontend.API, a [8]frontend.Variable, b byte) [8]frontend.Variable {
+	for j := 0; j < 8; j++ {
+		if b>>j&1 == 1 {
+			a[j] = api.Sub(1, a[j])
+		}
+	}
+	return a
+}
+
+func xorBlock(api frontend.API, a, b block) block {
+	var result block
+	for i := 0; i < 16; i++ {
+		result[i] = xorByte(api, a[i], b[i])
+	}
+	return result
+}
+
+func fromBits(api frontend.API, bits [8]frontend.Variable) frontend.Variable {
+	result := frontend.Variable(0)
+	for j := 7; j >= 0; j-- {
+		result = api.Add(api.Mul(result, 2), bits[j])
+	}
+	return result
+}
+
+func fromBlock(api builder.API, b block) [16]vars.Byte {
+	var result [16]vars.Byte
+	for i := 0; i < 16; i++ {
+		result[i] = vars.Byte{Value: vars.Variable{Value: fromBits(api.FrontendAPI(), b[i])}}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package aes
+
+import (
+	goaes "crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Key            [16]vars.Byte
+	Nonce          [12]vars.Byte
+	Block          [16]vars.Byte
+	EncryptedBlock [16]vars.Byte
+	Ciphertext     []vars.Byte
+	Tag            [TagSize]vars.Byte
+	AdditionalData []vars.Byte
+	Plaintext      []vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	cipher := NewCipher(*api, c.Key)
+	encrypted := cipher.Encrypt(c.Block)
+	for i := 0; i < 16; i++ {
+		api.AssertIsEqualByte(encrypted[i], c.EncryptedBlock[i])
+	}
+	plaintext := cipher.OpenGCM(c.Nonce, c.Ciphertext, c.Tag, c.AdditionalData)
+	for i := 0; i < len(plaintext); i++ {
+		api.AssertIsEqualByte(plaintext[i], c.Plaintext[i])
+	}
+	return nil
+}
+
+func setBytes(out []vars.Byte, in []byte) {
+	for i := 0; i < len(in); i++ {
+		out[i].Set(in[i])
+	}
+}
+
+func TestAESGCM(t *testing.T) {
+	key := make([]byte, 16)
+	nonce := make([]byte, 12)
+	block := make([]byte, 16)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	_, err = rand.Read(nonce)
+	assert.NoError(t, err)
+	_, err = rand.Read(block)
+	assert.NoError(t, err)
+
+	c, err := goaes.NewCipher(key)
+	assert.NoError(t, err)
+	encrypted := make([]byte, 16)
+	c.Encrypt(encrypted, block)
+	gcm, err := cipher.NewGCM(c)
+	assert.NoError(t, err)
+	plaintext := []byte("HTTP/1.1 200 OK\r\nContent-Length: 11\r\n\r\nhello world")
+	additionalData := []byte{0x17, 0x03, 0x03, 0x00, 0x44}
+	sealed := gcm.Seal(nil, nonce, plaintext, additionalData)
+
+	circuit := &testCircuit{
+		Ciphertext:     vars.NewBytes(len(plaintext)),
+		AdditionalData: vars.NewBytes(len(additionalData)),
+		Plaintext:      vars.NewBytes(len(plaintext)),
+	}
+	assignment := &testCircuit{
+		Ciphertext:     vars.NewBytesFrom(sealed[:len(plaintext)]),
+		AdditionalData: vars.NewBytesFrom(additionalData),
+		Plaintext:      vars.NewBytesFrom(plaintext),
+	}
+	setBytes(assignment.Key[:], key)
+	setBytes(assignment.Nonce[:], nonce)
+	setBytes(assignment.Block[:], block)
+	setBytes(assignment.EncryptedBlock[:], encrypted)
+	setBytes(assignment.Tag[:], sealed[len(plaintext):])
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// A modified tag is rejected.
+	assignment.Tag[0].Set(sealed[len(plaintext)] ^ 1)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
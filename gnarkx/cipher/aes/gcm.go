@@ -0,0 +1,143 @@
+package aes
+
+import (
+	"encoding/binary"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The size of a GCM tag in bytes.
+const TagSize = 16
+
+// Decrypts the ciphertext in GCM mode with a 12 byte nonce, asserting that the tag authenticates
+// the ciphertext and the additional data, and returns the plaintext. The lengths of the ciphertext
+// and additional data are fixed when the circuit is compiled, and all bytes are assumed to be range
+// checked.
+func (c *Cipher) OpenGCM(nonce [12]vars.Byte, ciphertext []vars.Byte, tag [TagSize]vars.Byte, additionalData []vars.Byte) []vars.Byte {
+	fapi := c.api.FrontendAPI()
+	var zero block
+	for i := 0; i < 16; i++ {
+		zero[i] = [8]frontend.Variable{0, 0, 0, 0, 0, 0, 0, 0}
+	}
+	h := toGCMOrder(c.encrypt(zero))
+
+	// The counter blocks are nonce || uint32(i) with the first one, J0, for the tag.
+	var counter block
+	for i := 0; i < 12; i++ {
+		counter[i] = c.toBits(nonce[i].Value.Value)
+	}
+	counterBlock := func(i uint32) block {
+		b := counter
+		for j := 0; j < 4; j++ {
+			b[12+j] = constantBits(byte(i >> (24 - 8*j)))
+		}
+		return b
+	}
+
+	data := make([][8]frontend.Variable, len(ciphertext))
+	for i := 0; i < len(ciphertext); i++ {
+		data[i] = c.toBits(ciphertext[i].Value.Value)
+	}
+	plaintext := make([]vars.Byte, len(ciphertext))
+	for i := 0; i < len(ciphertext); i += 16 {
+		keystream := c.encrypt(counterBlock(uint32(i/16 + 2)))
+		for j := i; j < len(ciphertext) && j < i+16; j++ {
+			plaintext[j] = vars.Byte{Value: vars.Variable{Value: fromBits(fapi, xorByte(fapi, data[j], keystream[j-i]))}}
+		}
+	}
+
+	// GHASH over the padded additional data and ciphertext, and their lengths in bits.
+	aad := make([][8]frontend.Variable, len(additionalData))
+	for i := 0; i < len(additionalData); i++ {
+		aad[i] = c.toBits(additionalData[i].Value.Value)
+	}
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+	var lengthBlock block
+	for i := 0; i < 16; i++ {
+		lengthBlock[i] = constantBits(lengths[i])
+	}
+
+	var x [128]frontend.Variable
+	for i := 0; i < 128; i++ {
+		x[i] = 0
+	}
+	blocks := append(padBlocks(aad), padBlocks(data)...)
+	blocks = append(blocks, lengthBlock)
+	for _, b := range blocks {
+		y := toGCMOrder(b)
+		for i := 0; i < 128; i++ {
+			y[i] = fapi.Xor(x[i], y[i])
+		}
+		x = c.mulGF128(y, h)
+	}
+
+	mask := toGCMOrder(c.encrypt(counterBlock(1)))
+	for i := 0; i < TagSize; i++ {
+		expected := c.toBits(tag[i].Value.Value)
+		for j := 0; j < 8; j++ {
+			fapi.AssertIsEqual(fapi.Xor(x[8*i+7-j], mask[8*i+7-j]), expected[j])
+		}
+	}
+	return plaintext
+}
+
+// Returns the bits of a block in the order of the coefficients of GF(2^128), where the first bit
+// is the most significant bit of the first byte.
+func toGCMOrder(b block) [128]frontend.Variable {
+	var result [128]frontend.Variable
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 8; j++ {
+			result[8*i+j] = b[i][7-j]
+		}
+	}
+	return result
+}
+
+// Multiplies two elements of GF(2^128) modulo x^128 + x^7 + x^2 + x + 1. The coefficients of the
+// product are summed as integers and reduced to their parities at the end.
+func (c *Cipher) mulGF128(a, b [128]frontend.Variable) [128]frontend.Variable {
+	fapi := c.api.FrontendAPI()
+	var sums [255]frontend.Variable
+	for k := 0; k < len(sums); k++ {
+		sums[k] = 0
+	}
+	for i := 0; i < 128; i++ {
+		for j := 0; j < 128; j++ {
+			sums[i+j] = fapi.Add(sums[i+j], fapi.Mul(a[i], b[j]))
+		}
+	}
+	for k := len(sums) - 1; k >= 128; k-- {
+		for _, shift := range []int{121, 126, 127, 128} {
+			sums[k-shift] = fapi.Add(sums[k-shift], sums[k])
+		}
+	}
+	// The sums are at most 128 * 9 < 2^11.
+	var result [128]frontend.Variable
+	for k := 0; k < 128; k++ {
+		result[k] = c.api.ToBinaryLE(vars.Variable{Value: sums[k]}, 11)[0].Value.Value
+	}
+	return result
+}
+
+func padBlocks(data [][8]frontend.Variable) []block {
+	blocks := make([]block, (len(data)+15)/16)
+	for i := 0; i < len(blocks)*16; i++ {
+		if i < len(data) {
+			blocks[i/16][i%16] = data[i]
+		} else {
+			blocks[i/16][i%16] = constantBits(0)
+		}
+	}
+	return blocks
+}
+
+func constantBits(b byte) [8]frontend.Variable {
+	var result [8]frontend.Variable
+	for j := 0; j < 8; j++ {
+		result[j] = int(b >> j & 1)
+	}
+	return result
+}
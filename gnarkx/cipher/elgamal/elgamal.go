@@ -0,0 +1,162 @@
+// ElGamal encryption over Baby Jubjub, with the keys of the babyjubjub package: the public key of a
+// secret scalar s is A = s * Base8.
+//
+// A point M is encrypted with a random scalar r as (C1, C2) = (r * Base8, M + r * A) and decrypted
+// as C2 - s * C1. The ciphertexts are additively homomorphic, so that the sum of the encryptions of
+// r1 * Base8 and r2 * Base8 decrypts to (r1 + r2) * Base8, such as for encrypted tallies whose small
+// totals are recovered by a discrete log outside of circuits.
+//
+// Elements of the scalar field are encrypted with the key agreed as in hashed ElGamal: the
+// ephemeral key is E = r * Base8, the shared key is K = r * A = s * E, and the i-th element is
+// masked with poseidon(K.X, K.Y, i), as the messages of MACI.
+package elgamal
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A ciphertext of a point outside of circuits.
+type CiphertextValue struct {
+	C1 babyjubjub.PointValue
+	C2 babyjubjub.PointValue
+}
+
+// Encrypts the point for the public key with the random scalar.
+func Encrypt(publicKey babyjubjub.PointValue, message babyjubjub.PointValue, randomness *big.Int) CiphertextValue {
+	return CiphertextValue{C1: babyjubjub.MulBase(randomness), C2: message.Add(publicKey.Mul(randomness))}
+}
+
+// Decrypts the ciphertext with the secret scalar.
+func Decrypt(secret *big.Int, ciphertext CiphertextValue) babyjubjub.PointValue {
+	return ciphertext.C2.Add(ciphertext.C1.Mul(secret).Neg())
+}
+
+// Returns the ciphertext of the sum of the points of the ciphertexts.
+func (c CiphertextValue) Add(d CiphertextValue) CiphertextValue {
+	return CiphertextValue{C1: c.C1.Add(d.C1), C2: c.C2.Add(d.C2)}
+}
+
+// A ciphertext of elements of the scalar field outside of circuits.
+type ElementsCiphertextValue struct {
+	Ephemeral babyjubjub.PointValue
+	Elements  []*big.Int
+}
+
+// Encrypts the elements for the public key with the random scalar.
+func EncryptElements(publicKey babyjubjub.PointValue, elements []*big.Int, randomness *big.Int) ElementsCiphertextValue {
+	shared := publicKey.Mul(randomness)
+	out := make([]*big.Int, len(elements))
+	for i := range elements {
+		out[i] = new(big.Int).Add(elements[i], mask(shared, i))
+		out[i].Mod(out[i], fr.Modulus())
+	}
+	return ElementsCiphertextValue{Ephemeral: babyjubjub.MulBase(randomness), Elements: out}
+}
+
+// Decrypts the elements with the secret scalar.
+func DecryptElements(secret *big.Int, ciphertext ElementsCiphertextValue) []*big.Int {
+	shared := ciphertext.Ephemeral.Mul(secret)
+	out := make([]*big.Int, len(ciphertext.Elements))
+	for i := range ciphertext.Elements {
+		out[i] = new(big.Int).Sub(ciphertext.Elements[i], mask(shared, i))
+		out[i].Mod(out[i], fr.Modulus())
+	}
+	return out
+}
+
+func mask(shared babyjubjub.PointValue, i int) *big.Int {
+	return poseidon.HashValues(shared.X, shared.Y, big.NewInt(int64(i)))
+}
+
+// A ciphertext of a point in the circuit.
+type Ciphertext struct {
+	C1 babyjubjub.Point
+	C2 babyjubjub.Point
+}
+
+// Creates a new ciphertext.
+func NewCiphertext() Ciphertext {
+	return Ciphertext{C1: babyjubjub.NewPoint(), C2: babyjubjub.NewPoint()}
+}
+
+// Sets the ciphertext.
+func (c *Ciphertext) Set(value CiphertextValue) {
+	c.C1.Set(value.C1)
+	c.C2.Set(value.C2)
+}
+
+// A ciphertext of elements of the scalar field in the circuit.
+type ElementsCiphertext struct {
+	Ephemeral babyjubjub.Point
+	Elements  []vars.Variable
+}
+
+// Creates a new ciphertext of n elements.
+func NewElementsCiphertext(n int) ElementsCiphertext {
+	elements := make([]vars.Variable, n)
+	for i := 0; i < n; i++ {
+		elements[i] = vars.NewVariable()
+	}
+	return ElementsCiphertext{Ephemeral: babyjubjub.NewPoint(), Elements: elements}
+}
+
+// Sets the ciphertext, which must have the number of elements of the circuit.
+func (c *ElementsCiphertext) Set(value ElementsCiphertextValue) {
+	if len(value.Elements) != len(c.Elements) {
+		panic("the ciphertext must have the number of elements of the circuit")
+	}
+	c.Ephemeral.Set(value.Ephemeral)
+	for i := range value.Elements {
+		c.Elements[i].Set(value.Elements[i])
+	}
+}
+
+// ElGamalAPI is a wrapper around succinct.API that provides methods for ElGamal encryption.
+type ElGamalAPI struct {
+	api builder.API
+}
+
+// Creates a new ElGamalAPI.
+func NewAPI(api *builder.API) *ElGamalAPI {
+	return &ElGamalAPI{api: *api}
+}
+
+// Encrypts the point for the public key with the random scalar. The points are assumed to be on the
+// curve.
+func (a *ElGamalAPI) Encrypt(publicKey babyjubjub.Point, message babyjubjub.Point, randomness vars.Variable) Ciphertext {
+	curve := babyjubjub.NewAPI(&a.api)
+	return Ciphertext{
+		C1: curve.ScalarMulBase(randomness),
+		C2: curve.Add(message, curve.ScalarMul(publicKey, randomness)),
+	}
+}
+
+// Decrypts the ciphertext with the secret scalar. The points are assumed to be on the curve.
+func (a *ElGamalAPI) Decrypt(secret vars.Variable, ciphertext Ciphertext) babyjubjub.Point {
+	curve := babyjubjub.NewAPI(&a.api)
+	return curve.Add(ciphertext.C2, curve.Neg(curve.ScalarMul(ciphertext.C1, secret)))
+}
+
+// Returns the ciphertext of the sum of the points of the ciphertexts.
+func (a *ElGamalAPI) Add(c Ciphertext, d Ciphertext) Ciphertext {
+	curve := babyjubjub.NewAPI(&a.api)
+	return Ciphertext{C1: curve.Add(c.C1, d.C1), C2: curve.Add(c.C2, d.C2)}
+}
+
+// Decrypts the elements with the secret scalar. The ephemeral key is assumed to be on the curve.
+func (a *ElGamalAPI) DecryptElements(secret vars.Variable, ciphertext ElementsCiphertext) []vars.Variable {
+	api := a.api
+	shared := babyjubjub.NewAPI(&api).ScalarMul(ciphertext.Ephemeral, secret)
+	out := make([]vars.Variable, len(ciphertext.Elements))
+	for i := range ciphertext.Elements {
+		mask := poseidon.Hash(api, []vars.Variable{shared.X, shared.Y, vars.NewVariableFromInt(i)})
+		out[i] = api.Sub(ciphertext.Elements[i], mask)
+	}
+	return out
+}
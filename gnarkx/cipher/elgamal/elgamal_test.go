@@ -0,0 +1,85 @@
+package elgamal
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	secret    = big.NewInt(987654321)
+	publicKey = babyjubjub.MulBase(secret)
+)
+
+func TestEncrypt(t *testing.T) {
+	// The sum of the encryptions of 3 and 4 decrypts to 7.
+	c := Encrypt(publicKey, babyjubjub.MulBase(big.NewInt(3)), big.NewInt(111))
+	d := Encrypt(publicKey, babyjubjub.MulBase(big.NewInt(4)), big.NewInt(222))
+	assert.Equal(t, babyjubjub.MulBase(big.NewInt(3)), Decrypt(secret, c))
+	assert.Equal(t, babyjubjub.MulBase(big.NewInt(7)), Decrypt(secret, c.Add(d)))
+	assert.NotEqual(t, babyjubjub.MulBase(big.NewInt(3)), Decrypt(big.NewInt(1), c))
+
+	elements := []*big.Int{big.NewInt(0), big.NewInt(42), new(big.Int).Lsh(big.NewInt(1), 250)}
+	ciphertext := EncryptElements(publicKey, elements, big.NewInt(333))
+	decrypted := DecryptElements(secret, ciphertext)
+	for i := range elements {
+		assert.Zero(t, elements[i].Cmp(decrypted[i]))
+	}
+}
+
+type testCircuit struct {
+	Secret     vars.Variable
+	Randomness vars.Variable
+	Message    babyjubjub.Point
+	Ciphertext Ciphertext
+	Elements   ElementsCiphertext
+	Expected   []vars.Variable
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := babyjubjub.NewAPI(api)
+	elgamal := NewAPI(api)
+	publicKey := curve.ScalarMulBase(c.Secret)
+
+	// The message is recovered from a fresh encryption and from the sum with the ciphertext of the
+	// identity.
+	ciphertext := elgamal.Encrypt(publicKey, c.Message, c.Randomness)
+	curve.AssertIsEqual(elgamal.Decrypt(c.Secret, ciphertext), c.Message)
+	curve.AssertIsEqual(elgamal.Decrypt(c.Secret, elgamal.Add(ciphertext, c.Ciphertext)), c.Message)
+
+	elements := elgamal.DecryptElements(c.Secret, c.Elements)
+	for i := range elements {
+		api.AssertIsEqual(elements[i], c.Expected[i])
+	}
+	return nil
+}
+
+func TestDecrypt(t *testing.T) {
+	elements := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	circuit := &testCircuit{Elements: NewElementsCiphertext(3), Expected: make([]vars.Variable, 3)}
+	assignment := &testCircuit{
+		Secret:     vars.Variable{Value: secret},
+		Randomness: vars.NewVariableFromInt(444),
+		Elements:   NewElementsCiphertext(3),
+		Expected:   make([]vars.Variable, 3),
+	}
+	assignment.Message.Set(babyjubjub.MulBase(big.NewInt(5)))
+	assignment.Ciphertext.Set(Encrypt(publicKey, babyjubjub.Identity(), big.NewInt(555)))
+	assignment.Elements.Set(EncryptElements(publicKey, elements, big.NewInt(666)))
+	for i := range elements {
+		assignment.Expected[i] = vars.Variable{Value: elements[i]}
+	}
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The elements must be decrypted with the secret of the public key.
+	assignment.Elements.Set(EncryptElements(babyjubjub.MulBase(big.NewInt(1)), elements, big.NewInt(666)))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
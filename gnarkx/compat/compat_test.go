@@ -0,0 +1,130 @@
+package compat
+
+import (
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gosha256 "crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestSha256Circuit struct {
+	In  []vars.Byte
+	Out [32]vars.Byte
+}
+
+func (c *TestSha256Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	out := Sha256(*api, c.In)
+	expected := sha256.Hash(*api, c.In)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(out[i], c.Out[i])
+		api.AssertIsEqualByte(out[i], expected[i])
+	}
+	return nil
+}
+
+func TestSha256(t *testing.T) {
+	in := []byte("mixing gadgets from both ecosystems")
+	out := gosha256.Sum256(in)
+
+	circuit := TestSha256Circuit{In: vars.NewBytes(len(in))}
+	assignment := TestSha256Circuit{In: vars.NewBytes(len(in))}
+	vars.SetBytes(&assignment.In, in)
+	assignment.Out = vars.NewBytes32()
+	vars.SetBytes32(&assignment.Out, out)
+
+	err := test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
+
+type TestSignatureCircuit struct {
+	MsgHash [32]vars.Byte
+	V       vars.Variable
+	R       [32]vars.Byte
+	S       [32]vars.Byte
+	PubX    [32]vars.Byte
+	PubY    [32]vars.Byte
+}
+
+func (c *TestSignatureCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	AssertValidECDSA(*api, c.MsgHash, c.R, c.S, c.PubX, c.PubY)
+	x, y := ECRecover(*api, c.MsgHash, c.V, c.R, c.S)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(x[i], c.PubX[i])
+		api.AssertIsEqualByte(y[i], c.PubY[i])
+	}
+	return nil
+}
+
+func TestSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	msgHash := crypto.Keccak256([]byte("hello"))
+	sig, err := crypto.Sign(msgHash, key)
+	assert.NoError(t, err)
+	pub := crypto.FromECDSAPub(&key.PublicKey)
+
+	circuit := TestSignatureCircuit{}
+	assignment := TestSignatureCircuit{}
+	vars.SetBytes32(&assignment.MsgHash, [32]byte(msgHash))
+	assignment.V = vars.NewVariableFromInt(int(sig[64]) + 27)
+	vars.SetBytes32(&assignment.R, [32]byte(sig[0:32]))
+	vars.SetBytes32(&assignment.S, [32]byte(sig[32:64]))
+	vars.SetBytes32(&assignment.PubX, [32]byte(pub[1:33]))
+	vars.SetBytes32(&assignment.PubY, [32]byte(pub[33:65]))
+
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	assignment.V = vars.NewVariableFromInt(int(1-sig[64]) + 27)
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+type TestP256Circuit struct {
+	MsgHash [32]vars.Byte
+	R       [32]vars.Byte
+	S       [32]vars.Byte
+	PubX    [32]vars.Byte
+	PubY    [32]vars.Byte
+}
+
+func (c *TestP256Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	AssertValidP256ECDSA(*api, c.MsgHash, c.R, c.S, c.PubX, c.PubY)
+	return nil
+}
+
+func TestP256Signature(t *testing.T) {
+	key, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	msgHash := gosha256.Sum256([]byte("hello"))
+	r, s, err := goecdsa.Sign(rand.Reader, key, msgHash[:])
+	assert.NoError(t, err)
+
+	circuit := TestP256Circuit{}
+	assignment := TestP256Circuit{}
+	vars.SetBytes32(&assignment.MsgHash, msgHash)
+	vars.SetBytes32(&assignment.R, [32]byte(r.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.S, [32]byte(s.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.PubX, [32]byte(key.X.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.PubY, [32]byte(key.Y.FillBytes(make([]byte, 32))))
+
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	vars.SetBytes32(&assignment.MsgHash, gosha256.Sum256([]byte("world")))
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
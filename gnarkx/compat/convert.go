@@ -0,0 +1,70 @@
+// Adapters for invoking gnark std gadgets through builder.API with vars types, so that circuits can
+// mix the gadgets of this module with upstream ones. Conversions assume that the vars.Byte values
+// are already range checked, as is the case for all bytes produced by builder.API.
+package compat
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Converts bytes into the byte type of gnark std/math/uints.
+func ToU8s(in []vars.Byte) []uints.U8 {
+	out := make([]uints.U8, len(in))
+	for i := 0; i < len(in); i++ {
+		out[i] = uints.U8{Val: in[i].Value.Value}
+	}
+	return out
+}
+
+// Converts bytes of gnark std/math/uints into bytes.
+func FromU8s(in []uints.U8) []vars.Byte {
+	out := make([]vars.Byte, len(in))
+	for i := 0; i < len(in); i++ {
+		out[i] = vars.Byte{Value: vars.Variable{Value: in[i].Val}}
+	}
+	return out
+}
+
+// Converts a big-endian bytes32 into an emulated field element. The value is not reduced, so it
+// may be larger than the modulus of the field.
+func ToElement[T emulated.FieldParams](api builder.API, in [32]vars.Byte) *emulated.Element[T] {
+	field, err := emulated.NewField[T](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	bits := make([]frontend.Variable, 0, 256)
+	for i := 31; i >= 0; i-- {
+		byteBits := api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			bits = append(bits, byteBits[j].Value.Value)
+		}
+	}
+	return field.FromBits(bits...)
+}
+
+// Converts an emulated field element into a big-endian bytes32. The element is reduced first, so
+// the internal representation of the element does not change the result.
+func FromElement[T emulated.FieldParams](api builder.API, in *emulated.Element[T]) [32]vars.Byte {
+	field, err := emulated.NewField[T](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	bits := field.ToBits(field.Reduce(in))
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			if i*8+j < len(bits) {
+				byteBits[j] = vars.Bool{Value: vars.Variable{Value: bits[i*8+j]}}
+			} else {
+				byteBits[j] = vars.FALSE
+			}
+		}
+		out[31-i] = api.ToByteFromBits(byteBits)
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package compat
+
+import (
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/evmprecompiles"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes sha256(in) with std/hash/sha2, which uses lookup tables instead of bit decompositions.
+func Sha256(api builder.API, in []vars.Byte) [32]vars.Byte {
+	h, err := sha2.New(api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	h.Write(ToU8s(in))
+	var out [32]vars.Byte
+	copy(out[:], FromU8s(h.Sum()))
+	return out
+}
+
+// Asserts that (r, s) is a valid secp256k1 ECDSA signature of msgHash under the public key
+// (pubX, pubY) with std/signature/ecdsa. All values are big-endian bytes32.
+func AssertValidECDSA(
+	api builder.API,
+	msgHash [32]vars.Byte,
+	r [32]vars.Byte,
+	s [32]vars.Byte,
+	pubX [32]vars.Byte,
+	pubY [32]vars.Byte,
+) {
+	assertValidECDSA[emulated.Secp256k1Fp, emulated.Secp256k1Fr](
+		api, sw_emulated.GetSecp256k1Params(), msgHash, r, s, pubX, pubY,
+	)
+}
+
+// Asserts that (r, s) is a valid P-256 ECDSA signature of msgHash under the public key
+// (pubX, pubY) with std/signature/ecdsa. All values are big-endian bytes32.
+func AssertValidP256ECDSA(
+	api builder.API,
+	msgHash [32]vars.Byte,
+	r [32]vars.Byte,
+	s [32]vars.Byte,
+	pubX [32]vars.Byte,
+	pubY [32]vars.Byte,
+) {
+	assertValidECDSA[emulated.P256Fp, emulated.P256Fr](
+		api, sw_emulated.GetP256Params(), msgHash, r, s, pubX, pubY,
+	)
+}
+
+func assertValidECDSA[Base, Scalar emulated.FieldParams](
+	api builder.API,
+	params sw_emulated.CurveParams,
+	msgHash [32]vars.Byte,
+	r [32]vars.Byte,
+	s [32]vars.Byte,
+	pubX [32]vars.Byte,
+	pubY [32]vars.Byte,
+) {
+	pk := ecdsa.PublicKey[Base, Scalar]{
+		X: *ToElement[Base](api, pubX),
+		Y: *ToElement[Base](api, pubY),
+	}
+	sig := ecdsa.Signature[Scalar]{
+		R: *ToElement[Scalar](api, r),
+		S: *ToElement[Scalar](api, s),
+	}
+	msg := ToElement[Scalar](api, msgHash)
+	pk.Verify(api.FrontendAPI(), params, msg, &sig)
+}
+
+// Recovers the public key (x, y) of a secp256k1 signature with the ECRECOVER precompile of
+// std/evmprecompiles. The recovery id v must be 27 or 28, as in the EVM, and s is checked to be
+// in the lower half of the scalar field.
+func ECRecover(
+	api builder.API,
+	msgHash [32]vars.Byte,
+	v vars.Variable,
+	r [32]vars.Byte,
+	s [32]vars.Byte,
+) ([32]vars.Byte, [32]vars.Byte) {
+	pk := evmprecompiles.ECRecover(
+		api.FrontendAPI(),
+		*ToElement[emulated.Secp256k1Fr](api, msgHash),
+		v.Value,
+		*ToElement[emulated.Secp256k1Fr](api, r),
+		*ToElement[emulated.Secp256k1Fr](api, s),
+		1,
+	)
+	return FromElement[emulated.Secp256k1Fp](api, &pk.X), FromElement[emulated.Secp256k1Fp](api, &pk.Y)
+}
@@ -0,0 +1,85 @@
+// Comparisons of the fields of signed credentials with public thresholds, for selective disclosure
+// such as a birthdate before a cutoff or a score above a minimum, without revealing the field.
+//
+// JWTCircuit is a circuit template comparing an integer claim of a JSON Web Token, and the
+// Conditions of the configs of the eas package compare the fields of EIP-712 or on-chain
+// attestations. Credentials encoded in CBOR, such as the mobile driving licences of ISO 18013-5,
+// are not supported as there is no CBOR gadget yet.
+package credential
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Comparison of the value of a field with a threshold.
+type Comparison int
+
+const (
+	LessThan Comparison = iota
+	LessOrEqual
+	GreaterThan
+	GreaterOrEqual
+)
+
+// Returns whether the comparison of the value with the threshold holds outside of circuits.
+func (c Comparison) Holds(value *big.Int, threshold *big.Int) bool {
+	cmp := value.Cmp(threshold)
+	switch c {
+	case LessThan:
+		return cmp < 0
+	case LessOrEqual:
+		return cmp <= 0
+	case GreaterThan:
+		return cmp > 0
+	case GreaterOrEqual:
+		return cmp >= 0
+	}
+	panic(fmt.Sprintf("unknown comparison %d", c))
+}
+
+// Asserts that the comparison of the value with the threshold holds, as integers less than the
+// modulus of the field.
+func (c Comparison) Assert(api builder.API, value vars.Variable, threshold vars.Variable) {
+	c.assertCmp(api, api.Cmp(value, threshold))
+}
+
+// Asserts that the comparison holds for big-endian unsigned integers of the same length, such as
+// ABI words, whose bytes are assumed to be range checked.
+func (c Comparison) AssertBytes(api builder.API, value []vars.Byte, threshold []vars.Byte) {
+	if len(value) != len(threshold) {
+		panic("the value and the threshold must have the same length")
+	}
+
+	// The chunks of 16 bytes are compared from the most significant, and the first difference
+	// decides.
+	cmp := vars.NewVariableFromInt(0)
+	for i := 0; i < len(value); i += 16 {
+		end := i + 16
+		if end > len(value) {
+			end = len(value)
+		}
+		chunk := api.Cmp(api.ToVariableFromBytesBE(value[i:end]), api.ToVariableFromBytesBE(threshold[i:end]))
+		cmp = api.Select(api.IsZero(cmp), chunk, cmp)
+	}
+	c.assertCmp(api, cmp)
+}
+
+// Asserts that the comparison holds for the result of Cmp, -1, 0 or 1.
+func (c Comparison) assertCmp(api builder.API, cmp vars.Variable) {
+	switch c {
+	case LessThan:
+		api.AssertIsEqual(cmp, vars.NewVariableFromInt(-1))
+	case LessOrEqual:
+		api.AssertIsDifferent(cmp, vars.NewVariableFromInt(1))
+	case GreaterThan:
+		api.AssertIsEqual(cmp, vars.NewVariableFromInt(1))
+	case GreaterOrEqual:
+		api.AssertIsDifferent(cmp, vars.NewVariableFromInt(-1))
+	default:
+		panic(fmt.Sprintf("unknown comparison %d", c))
+	}
+}
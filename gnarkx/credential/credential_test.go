@@ -0,0 +1,53 @@
+package credential
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Value      [32]vars.Byte
+	Threshold  [32]vars.Byte
+	comparison Comparison `gnark:"-"`
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	c.comparison.AssertBytes(*api, c.Value[:], c.Threshold[:])
+	return nil
+}
+
+func TestAssertBytes(t *testing.T) {
+	// Values that differ in either chunk of 16 bytes.
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(750),
+		big.NewInt(751),
+		new(big.Int).Lsh(big.NewInt(1), 200),
+		new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 200), big.NewInt(750)),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+	}
+	for _, comparison := range []Comparison{LessThan, LessOrEqual, GreaterThan, GreaterOrEqual} {
+		for _, value := range values {
+			for _, threshold := range values {
+				circuit := &testCircuit{comparison: comparison}
+				assignment := &testCircuit{}
+				vars.SetBytes32(&assignment.Value, [32]byte(value.FillBytes(make([]byte, 32))))
+				vars.SetBytes32(&assignment.Threshold, [32]byte(threshold.FillBytes(make([]byte, 32))))
+				err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+				if comparison.Holds(value, threshold) {
+					assert.NoError(t, err, "%d %s %s", comparison, value, threshold)
+				} else {
+					assert.Error(t, err, "%d %s %s", comparison, value, threshold)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package credential
+
+import (
+	"bytes"
+	"crypto"
+	gosha256 "crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/jwt"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	"github.com/succinctlabs/succinctx/gnarkx/x509"
+)
+
+// JWTConfig describes the tokens a JWTCircuit accepts and the comparison of their claim.
+type JWTConfig struct {
+	// The expected iss claim and the signing key of the issuer, an *rsa.PublicKey for RS256 or a
+	// P-256 *ecdsa.PublicKey for ES256, pinned as a constant of the circuit.
+	Issuer string
+	Key    crypto.PublicKey
+
+	// The name of the compared claim, a non-negative integer of at most MaxDigits digits, such as a
+	// birthdate encoded as YYYYMMDD, and the comparison of its value with the threshold.
+	Claim      string
+	MaxDigits  int
+	Comparison Comparison
+
+	// The maximum lengths of the signing input of the token and of the sub claim.
+	MaxTokenLength int
+	MaxSubLength   int
+}
+
+// JWTCircuit proves that an integer claim of a JSON Web Token satisfies the comparison of the
+// config with a threshold. Its input is the threshold and a unix timestamp, as uint64, at which
+// the token must not be expired. It outputs the SHA-256 digest of the sub claim, so that the proof
+// is bound to the subject of the token but reveals nothing else about it.
+type JWTCircuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Token jwt.Token
+	Iss   jwt.Claim
+	Sub   jwt.Claim
+	Exp   jwt.Claim
+	Value jwt.Claim
+
+	config *JWTConfig `gnark:"-"`
+	token  string     `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*JWTCircuit)(nil)
+
+// Returns the pinned signing key of the config.
+func (c *JWTConfig) key() (jwt.Algorithm, x509.PublicKey, int) {
+	key, err := x509.ConstantPublicKey(c.Key)
+	if err != nil {
+		panic(err)
+	}
+	if key.Type.Algorithm == x509.RSA {
+		return jwt.RS256, key, key.Type.Bits / 8
+	}
+	return jwt.ES256, key, 64
+}
+
+// Creates a new circuit for tokens described by the config.
+func NewJWTCircuit(config *JWTConfig) *JWTCircuit {
+	if config.MaxDigits < 1 || config.MaxDigits > 18 {
+		panic(fmt.Sprintf("unsupported max digits %d", config.MaxDigits))
+	}
+	_, _, signatureLength := config.key()
+	return &JWTCircuit{
+		InputBytes:  vars.NewBytes(16),
+		OutputBytes: vars.NewBytes(32),
+		Token:       jwt.NewToken(config.MaxTokenLength, signatureLength),
+		Iss:         jwt.NewClaim(),
+		Sub:         jwt.NewClaim(),
+		Exp:         jwt.NewClaim(),
+		Value:       jwt.NewClaim(),
+		config:      config,
+	}
+}
+
+// Returns the names of the claims read by the circuit.
+func (c *JWTCircuit) claimNames() []string {
+	return []string{"iss", "sub", "exp", c.config.Claim}
+}
+
+// Sets the token that the next call to SetWitness assigns. The token is checked to have the claims
+// read by the circuit, but its signature and the comparison are only checked by the circuit.
+func (c *JWTCircuit) SetToken(token string) error {
+	t := jwt.NewToken(len(c.Token.SigningInput), len(c.Token.Signature))
+	if err := t.Set(token); err != nil {
+		return err
+	}
+	payload, err := jwt.ParsePayload(token)
+	if err != nil {
+		return err
+	}
+	for _, name := range c.claimNames() {
+		claim := jwt.NewClaim()
+		if err := claim.Set(payload, name); err != nil {
+			return err
+		}
+	}
+	sub, value, err := c.claims(payload)
+	if err != nil {
+		return err
+	}
+	if len(sub) > c.config.MaxSubLength {
+		return fmt.Errorf("sub length %d exceeds %d", len(sub), c.config.MaxSubLength)
+	}
+	if len(value) > c.config.MaxDigits {
+		return fmt.Errorf("claim %s of %d digits exceeds %d", c.config.Claim, len(value), c.config.MaxDigits)
+	}
+	c.token = token
+	return nil
+}
+
+// Returns the sub claim and the digits of the compared claim of a decoded payload.
+func (c *JWTCircuit) claims(payload []byte) ([]byte, string, error) {
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, "", fmt.Errorf("invalid payload: %w", err)
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("sub claim is not a string")
+	}
+	value, ok := claims[c.config.Claim].(json.Number)
+	if !ok {
+		return nil, "", fmt.Errorf("claim %s is not a number", c.config.Claim)
+	}
+	for _, r := range value.String() {
+		if r < '0' || r > '9' {
+			return nil, "", fmt.Errorf("claim %s is not a non-negative integer", c.config.Claim)
+		}
+	}
+	return []byte(sub), value.String(), nil
+}
+
+func (c *JWTCircuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *JWTCircuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *JWTCircuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the token given to SetToken.
+func (c *JWTCircuit) SetWitness(inputBytes []byte) {
+	if c.token == "" {
+		panic("token must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	if err := c.Token.Set(c.token); err != nil {
+		panic(err)
+	}
+	payload, err := jwt.ParsePayload(c.token)
+	if err != nil {
+		panic(err)
+	}
+	claims := []*jwt.Claim{&c.Iss, &c.Sub, &c.Exp, &c.Value}
+	for i, name := range c.claimNames() {
+		if err := claims[i].Set(payload, name); err != nil {
+			panic(err)
+		}
+	}
+	sub, _, err := c.claims(payload)
+	if err != nil {
+		panic(err)
+	}
+	digest := gosha256.Sum256(sub)
+	vars.SetBytes(&c.OutputBytes, digest[:])
+}
+
+func (c *JWTCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	threshold := inputReader.ReadUint64()
+	now := inputReader.ReadUint64()
+
+	algorithm, key, _ := c.config.key()
+	jwt.Verify(*api, c.Token, jwt.PublicKey{Algorithm: algorithm, Modulus: key.Modulus, X: key.X, Y: key.Y})
+	payload := jwt.DecodePayload(*api, c.Token)
+	payload.AssertNotExpired(c.Exp, now.Value)
+
+	issuer := []byte(c.config.Issuer)
+	api.AssertIsEqual(c.Iss.Length, vars.NewVariableFromInt(len(issuer)))
+	iss := payload.String("iss", c.Iss, len(issuer))
+	for i := 0; i < len(issuer); i++ {
+		api.AssertIsEqualByte(iss[i], vars.Byte{Value: vars.NewVariableFromInt(int(issuer[i]))})
+	}
+
+	// The claim has at most 18 digits, so it is compared as an integer with the threshold.
+	value := payload.Integer(c.config.Claim, c.Value, c.config.MaxDigits)
+	c.config.Comparison.Assert(*api, value, threshold.Value)
+
+	sub := payload.String("sub", c.Sub, c.config.MaxSubLength)
+	digest := sha256.HashVariable(*api, sub, vars.U64{Value: c.Sub.Length}, len(sub))
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(digest)
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
@@ -0,0 +1,72 @@
+package credential
+
+import (
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gosha256 "crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+)
+
+const (
+	issuer = "https://issuer.example.com"
+	sub    = "did:example:123456789"
+)
+
+func newToken(t *testing.T, key *goecdsa.PrivateKey, payload string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","typ":"JWT"}`))
+	input := header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	digest := gosha256.Sum256([]byte(input))
+	r, s, err := goecdsa.Sign(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func input(threshold uint64, now uint64) []byte {
+	return binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, threshold), now)
+}
+
+func TestJWT(t *testing.T) {
+	key, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	// The holder was born before 2006-01-01, ie. is of age on 2024-01-01.
+	config := &JWTConfig{
+		Issuer:         issuer,
+		Key:            key.Public(),
+		Claim:          "birthdate",
+		MaxDigits:      8,
+		Comparison:     LessThan,
+		MaxTokenLength: 320,
+		MaxSubLength:   32,
+	}
+	circuit := NewJWTCircuit(config)
+	payload := `{"iss":"` + issuer + `","sub":"` + sub + `","birthdate":19990521,"exp":1800000000}`
+	assert.NoError(t, circuit.SetToken(newToken(t, key, payload)))
+	assert.NoError(t, functiontest.IsSolved(circuit, input(20060101, 1704067200)))
+	digest := gosha256.Sum256([]byte(sub))
+	for i := 0; i < 32; i++ {
+		assert.Equal(t, digest[i], circuit.OutputBytes[i].GetValueUnsafe())
+	}
+
+	// The claim must satisfy the comparison, and the token must not be expired.
+	assert.Error(t, functiontest.IsSolved(circuit, input(19990521, 1704067200)))
+	assert.Error(t, functiontest.IsSolved(circuit, input(20060101, 1800000000)))
+
+	// The token must be of the issuer.
+	payload = `{"iss":"https://issuer.example.org","sub":"` + sub + `","birthdate":19990521,"exp":1800000000}`
+	assert.NoError(t, circuit.SetToken(newToken(t, key, payload)))
+	assert.Error(t, functiontest.IsSolved(circuit, input(20060101, 1704067200)))
+
+	// The claim must be an integer of at most 8 digits.
+	payload = `{"iss":"` + issuer + `","sub":"` + sub + `","birthdate":"1999-05-21","exp":1800000000}`
+	assert.Error(t, circuit.SetToken(newToken(t, key, payload)))
+	payload = `{"iss":"` + issuer + `","sub":"` + sub + `","birthdate":119990521,"exp":1800000000}`
+	assert.Error(t, circuit.SetToken(newToken(t, key, payload)))
+}
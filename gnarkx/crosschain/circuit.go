@@ -0,0 +1,299 @@
+package crosschain
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/receipts"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Circuit proves that an event was emitted at or before the block of the input.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The headers from the block of the event to the block of the input, where the headers after
+	// the first NbHeaders are unused copies of the last one.
+	Headers   []eth.Header
+	NbHeaders vars.Variable
+
+	// The proof of the receipt of the transaction at TxIndex in the block of the event, and the
+	// index of the log of the event in the receipt.
+	ReceiptProof eth.MPTProof
+	TxIndex      vars.U64
+	LogIndex     vars.Variable
+
+	config       *Config       `gnark:"-"`
+	layout       layout        `gnark:"-"`
+	headers      []*eth.Header `gnark:"-"`
+	receiptProof *eth.MPTProof `gnark:"-"`
+	txIndex      uint64        `gnark:"-"`
+	logIndex     int           `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the events described by the config.
+func NewCircuit(config *Config) *Circuit {
+	l := config.layout()
+	headers := make([]eth.Header, config.MaxAncestors+1)
+	for i := 0; i < len(headers); i++ {
+		headers[i] = eth.NewHeader(config.MaxHeaderLength)
+	}
+	return &Circuit{
+		InputBytes:   vars.NewBytes(32),
+		OutputBytes:  vars.NewBytes(8 + 32*l.nbIndexed + 32),
+		Headers:      headers,
+		NbHeaders:    vars.NewVariable(),
+		ReceiptProof: eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, config.MaxReceiptLength),
+		TxIndex:      vars.NewU64(),
+		LogIndex:     vars.NewVariable(),
+		config:       config,
+		layout:       l,
+	}
+}
+
+// Sets the witness that the next call to SetWitness assigns: the headers from the block of the
+// event to the block of the input, the proof of the receipt of the transaction at txIndex in the
+// block of the event, as returned by eth.Client.ReceiptProof, and the index of the log of the
+// event in the receipt.
+func (c *Circuit) SetProofs(headers []*eth.Header, receiptProof *eth.MPTProof, txIndex uint64, logIndex int) error {
+	if len(headers) == 0 || len(headers) > len(c.Headers) {
+		return fmt.Errorf("expected 1 to %d headers, got %d", len(c.Headers), len(headers))
+	}
+	for i := 0; i < len(headers); i++ {
+		if len(headers[i].RLP) != len(c.Headers[i].RLP) {
+			return fmt.Errorf("header %d max length %d, expected %d", i, len(headers[i].RLP), len(c.Headers[i].RLP))
+		}
+	}
+	if len(receiptProof.Nodes) != len(c.ReceiptProof.Nodes) || len(receiptProof.Nodes[0]) != len(c.ReceiptProof.Nodes[0]) ||
+		len(receiptProof.Value) != len(c.ReceiptProof.Value) {
+		return fmt.Errorf("receipt proof does not have the shape of the config")
+	}
+	if txIndex >= 1<<16 {
+		return fmt.Errorf("transaction index %d exceeds 2^16", txIndex)
+	}
+	if logIndex < 0 || logIndex >= c.config.MaxLogs {
+		return fmt.Errorf("log index %d exceeds max logs %d", logIndex, c.config.MaxLogs)
+	}
+	c.headers = headers
+	c.receiptProof = receiptProof
+	c.txIndex = txIndex
+	c.logIndex = logIndex
+	if _, err := c.outputs(); err != nil {
+		c.headers = nil
+		return err
+	}
+	return nil
+}
+
+// Returns the outputs of the witness set with SetProofs.
+func (c *Circuit) outputs() ([]byte, error) {
+	length := int(c.headers[0].Length.Value.(*big.Int).Int64())
+	var header types.Header
+	if err := rlp.DecodeBytes(vars.GetValuesUnsafe(c.headers[0].RLP[:length]), &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	length = int(c.receiptProof.ValueLength.Value.(*big.Int).Int64())
+	var receipt types.Receipt
+	if err := receipt.UnmarshalBinary(vars.GetValuesUnsafe(c.receiptProof.Value[:length])); err != nil {
+		return nil, fmt.Errorf("invalid receipt: %w", err)
+	}
+	if c.logIndex >= len(receipt.Logs) {
+		return nil, fmt.Errorf("receipt has %d logs, no log %d", len(receipt.Logs), c.logIndex)
+	}
+	log := receipt.Logs[c.logIndex]
+	if log.Address != c.config.Contract || len(log.Topics) != 1+c.layout.nbIndexed || log.Topics[0] != c.layout.topic {
+		return nil, fmt.Errorf("log %d is not the event of the config", c.logIndex)
+	}
+	if len(log.Data) > c.config.MaxDataLength {
+		return nil, fmt.Errorf("data length %d exceeds max length %d", len(log.Data), c.config.MaxDataLength)
+	}
+	payload, err := c.layout.decodePayload(log.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := binary.BigEndian.AppendUint64(nil, header.Number.Uint64())
+	for i := 1; i < len(log.Topics); i++ {
+		outputs = append(outputs, log.Topics[i][:]...)
+	}
+	return append(outputs, crypto.Keccak256(payload)...), nil
+}
+
+// Returns the payload of the data of a log.
+func (l layout) decodePayload(data []byte) ([]byte, error) {
+	if l.payload < 0 {
+		return data, nil
+	}
+	word := func(offset uint64) (uint64, error) {
+		if offset+32 > uint64(len(data)) {
+			return 0, fmt.Errorf("data is too short")
+		}
+		value := new(big.Int).SetBytes(data[offset : offset+32])
+		if value.BitLen() > 32 {
+			return 0, fmt.Errorf("invalid offset or length")
+		}
+		return value.Uint64(), nil
+	}
+	if len(data) < 32*l.nbArgument {
+		return nil, fmt.Errorf("data is too short")
+	}
+	offset, err := word(32 * uint64(l.payload))
+	if err != nil {
+		return nil, err
+	}
+	length, err := word(offset)
+	if err != nil {
+		return nil, err
+	}
+	if offset+32+length > uint64(len(data)) {
+		return nil, fmt.Errorf("payload exceeds the data")
+	}
+	return data[offset+32 : offset+32+length], nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the proofs given to SetProofs.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.headers == nil {
+		panic("proofs must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	for i := 0; i < len(c.Headers); i++ {
+		c.Headers[i] = *c.headers[len(c.headers)-1]
+		if i < len(c.headers) {
+			c.Headers[i] = *c.headers[i]
+		}
+	}
+	c.NbHeaders.Set(big.NewInt(int64(len(c.headers))))
+	c.ReceiptProof = *c.receiptProof
+	c.TxIndex.Set(c.txIndex)
+	c.LogIndex = vars.NewVariableFromInt(c.logIndex)
+	outputs, err := c.outputs()
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.OutputBytes, outputs)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	blockHash := inputReader.ReadBytes32()
+
+	// The headers are a chain of parents from the block of the input to the block of the event.
+	headerAPI := header.NewAPI(api)
+	for i := 0; i < len(c.Headers); i++ {
+		headerAPI.Verify(c.Headers[i])
+	}
+	api.AssertIsLessOrEqual(c.NbHeaders, vars.NewVariableFromInt(len(c.Headers)))
+	api.AssertIsDifferent(c.NbHeaders, vars.NewVariableFromInt(0))
+	isActive := frontend.Variable(1)
+	var last [32]frontend.Variable
+	for j := 0; j < 32; j++ {
+		last[j] = 0
+	}
+	for i := 0; i < len(c.Headers); i++ {
+		if i > 0 {
+			isActive = fapi.Sub(isActive, fapi.IsZero(fapi.Sub(c.NbHeaders.Value, i)))
+			for j := 0; j < 32; j++ {
+				diff := fapi.Sub(c.Headers[i].ParentHash[j].Value.Value, c.Headers[i-1].Hash[j].Value.Value)
+				fapi.AssertIsEqual(fapi.Mul(isActive, diff), 0)
+			}
+		}
+		isLast := fapi.IsZero(fapi.Sub(c.NbHeaders.Value, i+1))
+		for j := 0; j < 32; j++ {
+			last[j] = fapi.Add(last[j], fapi.Mul(isLast, c.Headers[i].Hash[j].Value.Value))
+		}
+	}
+	for j := 0; j < 32; j++ {
+		fapi.AssertIsEqual(last[j], blockHash[j].Value.Value)
+	}
+
+	rc := receipts.NewAPI(api)
+	receipt := rc.VerifyReceipt(c.Headers[0].ReceiptsRoot, c.TxIndex, c.ReceiptProof, c.config.MaxDataLength+64)
+	api.AssertIsEqual(receipt.Status.Value, vars.NewVariableFromInt(1))
+	log := c.decodeLog(*api, receipt.Table, rc.ReadLog(receipt, c.LogIndex, c.config.MaxLogs, 1+c.layout.nbIndexed))
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteU64(c.Headers[0].Number)
+	for i := 0; i < len(log.topics); i++ {
+		outputWriter.WriteBytes32(log.topics[i])
+	}
+	outputWriter.WriteBytes32(log.payloadHash)
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// The decoded log of the event.
+type decodedLog struct {
+	topics      [][32]vars.Byte
+	payloadHash [32]vars.Byte
+}
+
+// Decodes a log of the receipt in the table, asserting that it is the event of the config.
+func (c *Circuit) decodeLog(api builder.API, table *byteslice.Table, log receipts.Log) decodedLog {
+	fapi := api.FrontendAPI()
+	contract := c.config.Contract.Bytes()
+	for i := 0; i < 20; i++ {
+		fapi.AssertIsEqual(log.Address[i].Value.Value, int(contract[i]))
+	}
+	for j := 0; j < 32; j++ {
+		fapi.AssertIsEqual(log.Topics[0][j].Value.Value, int(c.layout.topic[j]))
+	}
+	result := decodedLog{topics: log.Topics[1:]}
+
+	// The payload is the whole data or the tail of a bytes argument, at the offset in its head.
+	data := log.Data
+	start, length := data.Offset, data.Length
+	if c.layout.payload >= 0 {
+		api.AssertIsLessOrEqual(vars.NewVariableFromInt(32*c.layout.nbArgument), data.Length)
+		offset := readWord(api, table, api.Add(data.Offset, vars.NewVariableFromInt(32*c.layout.payload)))
+		start = api.Add(data.Offset, offset)
+		length = readWord(api, table, start)
+		start = api.Add(start, vars.NewVariableFromInt(32))
+		api.AssertIsLessOrEqual(api.Add(api.Add(offset, vars.NewVariableFromInt(32)), length), data.Length)
+	}
+	result.payloadHash = keccak256.HashVariable(api, table.Read(start, c.config.MaxDataLength), length)
+	return result
+}
+
+// Returns the ABI word at offset, asserting that it fits in 32 bits as offsets and lengths do.
+func readWord(api builder.API, table *byteslice.Table, offset vars.Variable) vars.Variable {
+	fapi := api.FrontendAPI()
+	word := table.Read(offset, 32)
+	result := frontend.Variable(0)
+	for i := 0; i < 32; i++ {
+		if i < 28 {
+			fapi.AssertIsEqual(word[i].Value.Value, 0)
+		} else {
+			result = fapi.Add(fapi.Mul(result, 256), word[i].Value.Value)
+		}
+	}
+	return vars.Variable{Value: result}
+}
@@ -0,0 +1,139 @@
+// A circuit template proving that an event with a payload was emitted by a contract on a source
+// chain at or before a block, for bridges that relay messages to other chains.
+//
+// The input is the hash of a block of the source chain that the destination trusts, such as the
+// latest header of a light client of the source chain. The circuit verifies a chain of up to
+// MaxAncestors parent headers from that block back to the block of the event, the proof of the
+// receipt of the transaction against the receipts root of that block, and decodes the log of the
+// event from the receipt. It checks the address and the signature of the event, and outputs the
+// number of the block of the event as a uint64 followed by the indexed arguments of the event and
+// the keccak256 hash of the payload as bytes32.
+//
+// The payload is either the ABI encoded non-indexed arguments of the event, i.e. the whole data of
+// the log, or the value of a bytes or string argument, which is ABI decoded from the data.
+package crosschain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Config describes the events a circuit accepts.
+type Config struct {
+	// The contract emitting the event and the declaration of the event, such as
+	// "MessageSent(uint64 indexed nonce,address indexed sender,bytes message)".
+	Contract common.Address
+	Event    string
+
+	// The name of a non-indexed bytes or string argument of the event whose value is the payload,
+	// or empty if the payload is the whole data of the log.
+	Payload string
+
+	// The maximum number of blocks between the block of the event and the block of the input.
+	MaxAncestors int
+
+	// The shape of the witness, where eth.Client returns receipt proofs of eth.MaxDepth,
+	// eth.MaxReceiptNodeLength and eth.MaxReceiptLength. The receipt may have up to MaxLogs logs
+	// and the data of the log up to MaxDataLength bytes.
+	MaxHeaderLength  int
+	MaxDepth         int
+	MaxNodeLength    int
+	MaxReceiptLength int
+	MaxLogs          int
+	MaxDataLength    int
+}
+
+// An argument of an event.
+type argument struct {
+	name    string
+	typ     string
+	indexed bool
+}
+
+// Parses the declaration of an event into its canonical signature and its arguments. Arguments
+// of tuple and fixed array types are not supported, so that every non-indexed argument has one
+// word in the head of the data.
+func parseEvent(event string) (string, []argument, error) {
+	open := strings.Index(event, "(")
+	if open <= 0 || !strings.HasSuffix(event, ")") {
+		return "", nil, fmt.Errorf("invalid event %q", event)
+	}
+	var arguments []argument
+	var types []string
+	if body := strings.TrimSpace(event[open+1 : len(event)-1]); body != "" {
+		for _, declaration := range strings.Split(body, ",") {
+			parts := strings.Fields(declaration)
+			if len(parts) == 0 || len(parts) > 3 {
+				return "", nil, fmt.Errorf("invalid argument %q", declaration)
+			}
+			a := argument{typ: parts[0]}
+			rest := parts[1:]
+			if len(rest) > 0 && rest[0] == "indexed" {
+				a.indexed = true
+				rest = rest[1:]
+			}
+			if len(rest) > 1 {
+				return "", nil, fmt.Errorf("invalid argument %q", declaration)
+			}
+			if len(rest) == 1 {
+				a.name = rest[0]
+			}
+			switch a.typ {
+			case "uint":
+				a.typ = "uint256"
+			case "int":
+				a.typ = "int256"
+			}
+			if strings.Contains(a.typ, "(") || (strings.HasSuffix(a.typ, "]") && !strings.HasSuffix(a.typ, "[]")) {
+				return "", nil, fmt.Errorf("unsupported type %q", a.typ)
+			}
+			arguments = append(arguments, a)
+			types = append(types, a.typ)
+		}
+	}
+	return strings.TrimSpace(event[:open]) + "(" + strings.Join(types, ",") + ")", arguments, nil
+}
+
+// The layout of the log of the event of a config.
+type layout struct {
+	// The topic of the signature, and the number of indexed and non-indexed arguments.
+	topic      common.Hash
+	nbIndexed  int
+	nbArgument int
+
+	// The position of the payload argument among the non-indexed arguments, or -1 for the whole
+	// data.
+	payload int
+}
+
+// Returns the layout of the event of the config, panicking if it is invalid.
+func (c *Config) layout() layout {
+	signature, arguments, err := parseEvent(c.Event)
+	if err != nil {
+		panic(err)
+	}
+	l := layout{topic: crypto.Keccak256Hash([]byte(signature)), payload: -1}
+	for _, a := range arguments {
+		if a.indexed {
+			l.nbIndexed++
+			continue
+		}
+		if c.Payload != "" && a.name == c.Payload {
+			if a.typ != "bytes" && a.typ != "string" {
+				panic(fmt.Sprintf("payload %s is not bytes or string", c.Payload))
+			}
+			l.payload = l.nbArgument
+		}
+		l.nbArgument++
+	}
+	if l.nbIndexed > 3 {
+		panic("events have at most 3 indexed arguments")
+	}
+	if c.Payload != "" && l.payload < 0 {
+		panic(fmt.Sprintf("payload %s is not a non-indexed argument of the event", c.Payload))
+	}
+	return l
+}
@@ -0,0 +1,149 @@
+package crosschain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+)
+
+var (
+	contract = common.HexToAddress("0x4200000000000000000000000000000000000007")
+	sender   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	message  = []byte("hello from the source chain, relayed by a proof")
+)
+
+const event = "MessageSent(uint64 indexed nonce,address indexed sender,uint256 fee,bytes message)"
+
+func newConfig(payload string) *Config {
+	return &Config{
+		Contract:         contract,
+		Event:            event,
+		Payload:          payload,
+		MaxAncestors:     2,
+		MaxHeaderLength:  eth.MaxHeaderLength,
+		MaxDepth:         3,
+		MaxNodeLength:    1040,
+		MaxReceiptLength: 1024,
+		MaxLogs:          4,
+		MaxDataLength:    192,
+	}
+}
+
+// Returns the ABI encoding of the non-indexed arguments of the event.
+func newData() []byte {
+	data := common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+	data = append(data, common.LeftPadBytes([]byte{0x40}, 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(message))).Bytes(), 32)...)
+	return append(data, common.RightPadBytes(message, 64)...)
+}
+
+// Returns the headers of the block of the event and of its child, and the proof of the receipt
+// of the event, emitted by the transaction at index 1 in its second log.
+func newProofs(t *testing.T) ([]*eth.Header, *eth.MPTProof, common.Hash) {
+	topic := crypto.Keccak256Hash([]byte("MessageSent(uint64,address,uint256,bytes)"))
+	logs := []*types.Log{
+		{Address: common.HexToAddress("0x02"), Topics: []common.Hash{common.HexToHash("0x03")}, Data: []byte{1, 2, 3}},
+		{Address: contract, Topics: []common.Hash{topic, common.BigToHash(big.NewInt(7)), common.BytesToHash(sender.Bytes())}, Data: newData()},
+	}
+	receipts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	var encoded []byte
+	for i := 0; i < 3; i++ {
+		receipt := &types.Receipt{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: uint64(21000 * (i + 1))}
+		if i == 0 {
+			receipt.Type = types.LegacyTxType
+		}
+		if i == 1 {
+			receipt.Logs = logs
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		value, err := receipt.MarshalBinary()
+		assert.NoError(t, err)
+		key, err := rlp.EncodeToBytes(uint(i))
+		assert.NoError(t, err)
+		receipts.MustUpdate(key, value)
+		if i == 1 {
+			encoded = value
+		}
+	}
+	key, err := rlp.EncodeToBytes(uint(1))
+	assert.NoError(t, err)
+	var nodes eth.ProofNodes
+	assert.NoError(t, receipts.Prove(key, 0, &nodes))
+	proof := eth.NewMPTProof(3, 1040, 1024)
+	assert.NoError(t, proof.SetUnhashed(receipts.Hash(), key, encoded, nodes))
+
+	parent := &types.Header{
+		ReceiptHash: receipts.Hash(),
+		Difficulty:  big.NewInt(0),
+		Number:      big.NewInt(18000000),
+		GasLimit:    30000000,
+		Time:        1700000000,
+		BaseFee:     big.NewInt(20e9),
+	}
+	child := &types.Header{
+		ParentHash: parent.Hash(),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(18000001),
+		GasLimit:   30000000,
+		Time:       1700000012,
+		BaseFee:    big.NewInt(20e9),
+	}
+	var headers []*eth.Header
+	for _, h := range []*types.Header{parent, child} {
+		header := eth.NewHeader(eth.MaxHeaderLength)
+		assert.NoError(t, header.Set(h))
+		headers = append(headers, &header)
+	}
+	return headers, &proof, child.Hash()
+}
+
+func TestMessage(t *testing.T) {
+	headers, proof, blockHash := newProofs(t)
+	for _, payload := range []string{"message", ""} {
+		circuit := NewCircuit(newConfig(payload))
+		assert.NoError(t, circuit.SetProofs(headers, proof, 1, 1))
+		function := succinct.NewCircuitFunction(circuit)
+		function.SetWitness(blockHash[:])
+		err := test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+
+		expected := append([]byte{0, 0, 0, 0, 0x01, 0x12, 0xa8, 0x80}, common.BigToHash(big.NewInt(7)).Bytes()...)
+		expected = append(expected, common.LeftPadBytes(sender.Bytes(), 32)...)
+		if payload == "" {
+			expected = append(expected, crypto.Keccak256(newData())...)
+		} else {
+			expected = append(expected, crypto.Keccak256(message)...)
+		}
+		for i := 0; i < len(expected); i++ {
+			assert.Equal(t, expected[i], circuit.OutputBytes[i].GetValueUnsafe(), "byte %d", i)
+		}
+	}
+
+	// The log must be the event of the config.
+	circuit := NewCircuit(newConfig("message"))
+	assert.Error(t, circuit.SetProofs(headers, proof, 1, 0))
+	assert.NoError(t, circuit.SetProofs(headers, proof, 1, 1))
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(blockHash[:])
+	circuit.LogIndex.Set(big.NewInt(0))
+	err := test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The event must be at or before the block of the input.
+	assert.NoError(t, circuit.SetProofs(headers[:1], proof, 1, 1))
+	function = succinct.NewCircuitFunction(circuit)
+	function.SetWitness(blockHash[:])
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
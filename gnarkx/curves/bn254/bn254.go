@@ -0,0 +1,383 @@
+// The API for BN254 (alt_bn128) over its emulated base field: the arithmetic of G1 and G2, pairing
+// checks and the encodings of the precompiles of the EVM (EIP-196 and EIP-197), for the recursive
+// verification of proofs and for the BLS signatures over BN254 that some rollups verify on chain.
+//
+// Points are affine, as in gnark, with the identity as (0, 0), which is on neither curve. As for
+// secp256k1, the additions use the complete formulas of the weierstrass package in projective
+// coordinates, over Fp for G1, y^2 = x^3 + 3, and over Fp2 for G2, y^2 = x^3 + 3 / (9 + u), since
+// the unified formulas of sw_emulated do not hold for all points of curves with a = 0. The pairings
+// are those of sw_bn254, whose Miller loops take neither the identity nor points with y = 0.
+package bn254
+
+import (
+	"math/big"
+
+	gobn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/curves/weierstrass"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The emulated base field of the coordinates of the points.
+type Fp = emulated.BN254Fp
+
+// The emulated scalar field of the order of the groups.
+type Fr = emulated.BN254Fr
+
+// A point of G1 in the circuit, in affine coordinates where the identity is (0, 0).
+type G1Affine = sw_bn254.G1Affine
+
+// A point of G2 in the circuit, in affine coordinates over Fp2 where the identity is (0, 0).
+type G2Affine = sw_bn254.G2Affine
+
+// An element of the target group GT of the pairing.
+type GTEl = sw_bn254.GTEl
+
+// A scalar in the circuit.
+type Scalar = emulated.Element[Fr]
+
+// The lengths of the encodings of the points of G1 and G2 in the precompiles of the EVM.
+const (
+	G1Length = 64
+	G2Length = 128
+)
+
+var (
+	// 3 * 3, the coefficient of the complete formulas for G1.
+	b3 = big.NewInt(9)
+
+	// 3 * 3 / (9 + u), the coefficient of the complete formulas for G2.
+	b3Twist gobn254.E2
+)
+
+func init() {
+	b3Twist.A0.SetUint64(9)
+	b3Twist.A1.SetUint64(1)
+	b3Twist.Inverse(&b3Twist)
+	b3Twist.MulByElement(&b3Twist, new(fp.Element).SetUint64(9))
+}
+
+// Creates a point of G1 from its value, where the point at infinity is (0, 0).
+func NewG1Affine(p gobn254.G1Affine) G1Affine {
+	return sw_bn254.NewG1Affine(p)
+}
+
+// Creates a point of G2 from its value, where the point at infinity is (0, 0).
+func NewG2Affine(p gobn254.G2Affine) G2Affine {
+	return sw_bn254.NewG2Affine(p)
+}
+
+// Creates a scalar.
+func NewScalar(s *big.Int) Scalar {
+	return emulated.ValueOf[Fr](s)
+}
+
+// BN254API is a wrapper around succinct.API that provides methods for the points of BN254 and its
+// pairing.
+type BN254API struct {
+	api     builder.API
+	g1      *weierstrass.Curve[*emulated.Element[Fp]]
+	g2      *weierstrass.Curve[*fields_bn254.E2]
+	fp      weierstrass.Emulated[Fp]
+	fp2     g2Field
+	fr      *emulated.Field[Fr]
+	pairing *sw_bn254.Pairing
+}
+
+// Creates a new BN254API.
+func NewAPI(api *builder.API) *BN254API {
+	fp, err := emulated.NewField[Fp](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[Fr](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	pairing, err := sw_bn254.NewPairing(api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	fp1 := weierstrass.NewEmulated[Fp](api.FrontendAPI(), fp)
+	fp2 := g2Field{Ext2: fields_bn254.NewExt2(api.FrontendAPI()), api: api.FrontendAPI(), fp: fp1}
+	twistB3 := fields_bn254.FromE2(&b3Twist)
+	return &BN254API{
+		api: *api,
+		g1: weierstrass.New[*emulated.Element[Fp]](fp1, func(x *emulated.Element[Fp]) *emulated.Element[Fp] {
+			return fp.MulConst(x, b3)
+		}),
+		g2: weierstrass.New[*fields_bn254.E2](fp2, func(x *fields_bn254.E2) *fields_bn254.E2 {
+			return fp2.Mul(x, &twistB3)
+		}),
+		fp:      fp1,
+		fp2:     fp2,
+		fr:      fr,
+		pairing: pairing,
+	}
+}
+
+// Returns the generator of G1.
+func (a *BN254API) G1Generator() *G1Affine {
+	_, _, g, _ := gobn254.Generators()
+	p := NewG1Affine(g)
+	return &p
+}
+
+// Returns the generator of G2.
+func (a *BN254API) G2Generator() *G2Affine {
+	_, _, _, g := gobn254.Generators()
+	p := NewG2Affine(g)
+	return &p
+}
+
+// Returns p + q for any points of G1.
+func (a *BN254API) AddG1(p, q *G1Affine) *G1Affine {
+	x, y := a.g1.ToAffine(a.g1.Add(a.toProjectiveG1(p), a.toProjectiveG1(q)))
+	return &G1Affine{X: *x, Y: *y}
+}
+
+// Returns 2p for any point of G1.
+func (a *BN254API) DoubleG1(p *G1Affine) *G1Affine {
+	x, y := a.g1.ToAffine(a.g1.Double(a.toProjectiveG1(p)))
+	return &G1Affine{X: *x, Y: *y}
+}
+
+// Returns -p for a point of G1.
+func (a *BN254API) NegG1(p *G1Affine) *G1Affine {
+	return &G1Affine{X: p.X, Y: *a.fp.Neg(&p.Y)}
+}
+
+// Returns p if the selector is set and q otherwise.
+func (a *BN254API) SelectG1(selector vars.Bool, p, q *G1Affine) *G1Affine {
+	s := selector.Value.Value
+	return &G1Affine{X: *a.fp.Select(s, &p.X, &q.X), Y: *a.fp.Select(s, &p.Y, &q.Y)}
+}
+
+// Returns whether p is the identity of G1.
+func (a *BN254API) IsIdentityG1(p *G1Affine) vars.Bool {
+	isIdentity := a.api.FrontendAPI().And(a.fp.IsZero(&p.X), a.fp.IsZero(&p.Y))
+	return vars.Bool{Value: vars.Variable{Value: isIdentity}}
+}
+
+// Asserts that p is a point of G1 or the identity. As G1 is the whole curve, this is the check of
+// the subgroup too.
+func (a *BN254API) AssertIsOnG1(p *G1Affine) {
+	fp := a.fp
+	isIdentity := a.IsIdentityG1(p).Value.Value
+	y2 := fp.Mul(&p.Y, &p.Y)
+	x3 := fp.Add(fp.Mul(fp.Mul(&p.X, &p.X), &p.X), fp.NewElement(3))
+	fp.AssertIsEqual(fp.Select(isIdentity, fp.Zero(), y2), fp.Select(isIdentity, fp.Zero(), x3))
+}
+
+// Asserts that two points of G1 are equal.
+func (a *BN254API) AssertIsEqualG1(p, q *G1Affine) {
+	a.fp.AssertIsEqual(&p.X, &q.X)
+	a.fp.AssertIsEqual(&p.Y, &q.Y)
+}
+
+// Returns [s]p for any point of G1 and any scalar, taking the bits of the scalar two at a time.
+func (a *BN254API) ScalarMulG1(p *G1Affine, s *Scalar) *G1Affine {
+	fr := a.fr
+	sr := fr.Reduce(s)
+	fr.AssertIsInRange(sr)
+	bits := fr.ToBits(sr)
+
+	g1 := a.g1
+	q := a.toProjectiveG1(p)
+	var table [4]weierstrass.Projective[*emulated.Element[Fp]]
+	table[0] = g1.Identity()
+	table[1] = q
+	table[2] = g1.Double(q)
+	table[3] = g1.Add(table[2], q)
+
+	n := len(bits) - len(bits)%2
+	result := g1.Lookup(bits[n-2:n], table[:])
+	for i := n - 4; i >= 0; i -= 2 {
+		result = g1.Double(g1.Double(result))
+		result = g1.Add(result, g1.Lookup(bits[i:i+2], table[:]))
+	}
+	x, y := g1.ToAffine(result)
+	return &G1Affine{X: *x, Y: *y}
+}
+
+// Returns the sum of [scalars[i]]points[i], as for the linear combinations of the public inputs of
+// Groth16 proofs.
+func (a *BN254API) MultiScalarMulG1(points []*G1Affine, scalars []*Scalar) *G1Affine {
+	if len(points) != len(scalars) {
+		panic("the numbers of points and scalars differ")
+	}
+	result := &G1Affine{X: *a.fp.Zero(), Y: *a.fp.Zero()}
+	for i := range points {
+		result = a.AddG1(result, a.ScalarMulG1(points[i], scalars[i]))
+	}
+	return result
+}
+
+// Returns p + q for any points of G2.
+func (a *BN254API) AddG2(p, q *G2Affine) *G2Affine {
+	x, y := a.g2.ToAffine(a.g2.Add(a.toProjectiveG2(p), a.toProjectiveG2(q)))
+	return &G2Affine{X: *x, Y: *y}
+}
+
+// Returns 2p for any point of G2.
+func (a *BN254API) DoubleG2(p *G2Affine) *G2Affine {
+	x, y := a.g2.ToAffine(a.g2.Double(a.toProjectiveG2(p)))
+	return &G2Affine{X: *x, Y: *y}
+}
+
+// Returns -p for a point of G2.
+func (a *BN254API) NegG2(p *G2Affine) *G2Affine {
+	return &G2Affine{X: p.X, Y: *a.fp2.Neg(&p.Y)}
+}
+
+// Returns whether p is the identity of G2.
+func (a *BN254API) IsIdentityG2(p *G2Affine) vars.Bool {
+	isIdentity := a.api.FrontendAPI().And(a.fp2.IsZero(&p.X), a.fp2.IsZero(&p.Y))
+	return vars.Bool{Value: vars.Variable{Value: isIdentity}}
+}
+
+// Asserts that p is a point of the twist or the identity, without checking that it is in G2.
+func (a *BN254API) AssertIsOnTwist(p *G2Affine) {
+	a.pairing.AssertIsOnTwist(p)
+}
+
+// Asserts that p is a point of G2, which must not be the identity.
+func (a *BN254API) AssertIsOnG2(p *G2Affine) {
+	a.pairing.AssertIsOnG2(p)
+}
+
+// Asserts that two points of G2 are equal.
+func (a *BN254API) AssertIsEqualG2(p, q *G2Affine) {
+	a.fp2.AssertIsEqual(&p.X, &q.X)
+	a.fp2.AssertIsEqual(&p.Y, &q.Y)
+}
+
+// Returns the product of the pairings e(p[i], q[i]). The points are not checked to be in G1 and
+// G2, and must not be the identity.
+func (a *BN254API) Pair(p []*G1Affine, q []*G2Affine) *GTEl {
+	result, err := a.pairing.Pair(p, q)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Asserts that the product of the pairings e(p[i], q[i]) is one, as the precompile of EIP-197
+// checks. The points are not checked to be in G1 and G2, and must not be the identity.
+func (a *BN254API) AssertPairingCheck(p []*G1Affine, q []*G2Affine) {
+	if err := a.pairing.PairingCheck(p, q); err != nil {
+		panic(err)
+	}
+}
+
+// Asserts that the signature in G1 of the hash of a message in G1 is valid for the public key in
+// G2, e(signature, G2) = e(hashedMessage, pubkey), with the lines of the generator of G2
+// precomputed. The message is hashed to the curve by the caller, with the hash of the scheme.
+//
+// The public key is checked to be in G2, lest it be a point of small order. None of the points
+// may be the identity.
+func (a *BN254API) AssertBLSSignature(pubkey *G2Affine, hashedMessage, signature *G1Affine) {
+	a.AssertIsOnG1(hashedMessage)
+	a.AssertIsOnG1(signature)
+	a.AssertIsOnG2(pubkey)
+	result, err := a.pairing.DoublePairFixedQ(hashedMessage, a.NegG1(signature), pubkey)
+	if err != nil {
+		panic(err)
+	}
+	a.pairing.AssertIsEqual(result, a.pairing.One())
+}
+
+// Returns the point of G1 of its encoding in the EVM, the big-endian x and y, which must be less
+// than the modulus. The point is not checked to be on the curve.
+func (a *BN254API) G1FromBytes(in [G1Length]vars.Byte) *G1Affine {
+	return &G1Affine{X: *a.fromBytes(in[0:32]), Y: *a.fromBytes(in[32:64])}
+}
+
+// Returns the encoding of a point of G1 in the EVM, which is zero for the identity.
+func (a *BN254API) G1ToBytes(p *G1Affine) [G1Length]vars.Byte {
+	var out [G1Length]vars.Byte
+	x, y := a.toBytes(&p.X), a.toBytes(&p.Y)
+	copy(out[0:32], x[:])
+	copy(out[32:64], y[:])
+	return out
+}
+
+// Returns the point of G2 of its encoding in the EVM, the big-endian x and y with the imaginary
+// parts first, x.A1 || x.A0 || y.A1 || y.A0. The point is not checked to be on the twist.
+func (a *BN254API) G2FromBytes(in [G2Length]vars.Byte) *G2Affine {
+	return &G2Affine{
+		X: fields_bn254.E2{A0: *a.fromBytes(in[32:64]), A1: *a.fromBytes(in[0:32])},
+		Y: fields_bn254.E2{A0: *a.fromBytes(in[96:128]), A1: *a.fromBytes(in[64:96])},
+	}
+}
+
+// Returns the encoding of a point of G2 in the EVM, which is zero for the identity.
+func (a *BN254API) G2ToBytes(p *G2Affine) [G2Length]vars.Byte {
+	var out [G2Length]vars.Byte
+	for i, e := range []*emulated.Element[Fp]{&p.X.A1, &p.X.A0, &p.Y.A1, &p.Y.A0} {
+		b := a.toBytes(e)
+		copy(out[32*i:32*i+32], b[:])
+	}
+	return out
+}
+
+// Returns the scalar of big-endian bytes, reduced modulo the order, as the multiplication
+// precompile of the EVM takes any 256-bit scalar.
+func (a *BN254API) ScalarFromBytes(in [32]vars.Byte) *Scalar {
+	return a.fr.Reduce(a.fr.FromBits(bitsLE(a.api, in[:])...))
+}
+
+// Returns the scalar of a variable, such as a public input of a recursively verified proof.
+func (a *BN254API) ScalarFromVariable(v vars.Variable) *Scalar {
+	bits := a.api.FrontendAPI().ToBinary(v.Value)
+	return a.fr.FromBits(bits...)
+}
+
+// Returns the element of big-endian bytes, asserting that it is less than the modulus.
+func (a *BN254API) fromBytes(in []vars.Byte) *emulated.Element[Fp] {
+	e := a.fp.FromBits(bitsLE(a.api, in)...)
+	a.fp.AssertIsInRange(e)
+	return e
+}
+
+// Returns the big-endian bytes of the canonical element.
+func (a *BN254API) toBytes(e *emulated.Element[Fp]) [32]vars.Byte {
+	fp := a.fp
+	e = fp.Reduce(e)
+	fp.AssertIsInRange(e)
+	bits := fp.ToBits(e)
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			byteBits[j] = vars.Bool{Value: vars.Variable{Value: bits[8*i+j]}}
+		}
+		out[31-i] = a.api.ToByteFromBits(byteBits)
+	}
+	return out
+}
+
+// Returns the little-endian bits of big-endian bytes, which range checks them.
+func bitsLE(api builder.API, in []vars.Byte) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, 8*len(in))
+	for i := len(in) - 1; i >= 0; i-- {
+		byteBits := api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			bits = append(bits, byteBits[j].Value.Value)
+		}
+	}
+	return bits
+}
+
+func (a *BN254API) toProjectiveG1(p *G1Affine) weierstrass.Projective[*emulated.Element[Fp]] {
+	return a.g1.FromAffine(a.IsIdentityG1(p).Value.Value, &p.X, &p.Y)
+}
+
+func (a *BN254API) toProjectiveG2(p *G2Affine) weierstrass.Projective[*fields_bn254.E2] {
+	return a.g2.FromAffine(a.IsIdentityG2(p).Value.Value, &p.X, &p.Y)
+}
@@ -0,0 +1,170 @@
+package bn254
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gobn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testG1Circuit struct {
+	P, Q     G1Affine
+	S        Scalar
+	Sum      G1Affine
+	Doubled  G1Affine
+	Mul      G1Affine
+	Encoding [G1Length]vars.Byte
+}
+
+func (c *testG1Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := NewAPI(api)
+	curve.AssertIsOnG1(&c.P)
+	curve.AssertIsOnG1(&c.Q)
+	sum := curve.AddG1(&c.P, &c.Q)
+	curve.AssertIsEqualG1(sum, &c.Sum)
+	curve.AssertIsEqualG1(curve.DoubleG1(&c.P), &c.Doubled)
+	curve.AssertIsEqualG1(curve.ScalarMulG1(&c.P, &c.S), &c.Mul)
+	encoding := curve.G1ToBytes(sum)
+	for i := range encoding {
+		api.AssertIsEqualByte(encoding[i], c.Encoding[i])
+	}
+	curve.AssertIsEqualG1(curve.G1FromBytes(c.Encoding), sum)
+	return nil
+}
+
+type testG2Circuit struct {
+	P, Q     G2Affine
+	Sum      G2Affine
+	Doubled  G2Affine
+	Encoding [G2Length]vars.Byte
+}
+
+func (c *testG2Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := NewAPI(api)
+	curve.AssertIsOnTwist(&c.P)
+	curve.AssertIsOnTwist(&c.Q)
+	sum := curve.AddG2(&c.P, &c.Q)
+	curve.AssertIsEqualG2(sum, &c.Sum)
+	curve.AssertIsEqualG2(curve.DoubleG2(&c.P), &c.Doubled)
+	encoding := curve.G2ToBytes(sum)
+	for i := range encoding {
+		api.AssertIsEqualByte(encoding[i], c.Encoding[i])
+	}
+	curve.AssertIsEqualG2(curve.G2FromBytes(c.Encoding), sum)
+	return nil
+}
+
+type testBLSCircuit struct {
+	Pubkey        G2Affine
+	HashedMessage G1Affine
+	Signature     G1Affine
+}
+
+func (c *testBLSCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := NewAPI(api)
+	curve.AssertBLSSignature(&c.Pubkey, &c.HashedMessage, &c.Signature)
+	return nil
+}
+
+func mulG1(s int64) gobn254.G1Affine {
+	_, _, g, _ := gobn254.Generators()
+	var p gobn254.G1Affine
+	return *p.ScalarMultiplication(&g, big.NewInt(s))
+}
+
+func mulG2(s int64) gobn254.G2Affine {
+	_, _, _, g := gobn254.Generators()
+	var p gobn254.G2Affine
+	return *p.ScalarMultiplication(&g, big.NewInt(s))
+}
+
+func isSolvedG1(p, q gobn254.G1Affine, s *big.Int, mul gobn254.G1Affine) error {
+	var sum, doubled gobn254.G1Affine
+	sum.Add(&p, &q)
+	doubled.Double(&p)
+	encoding := sum.RawBytes()
+	assignment := &testG1Circuit{
+		P:       NewG1Affine(p),
+		Q:       NewG1Affine(q),
+		S:       NewScalar(s),
+		Sum:     NewG1Affine(sum),
+		Doubled: NewG1Affine(doubled),
+		Mul:     NewG1Affine(mul),
+	}
+	copy(assignment.Encoding[:], vars.NewBytesFrom(encoding[:]))
+	return test.IsSolved(&testG1Circuit{}, assignment, ecc.BN254.ScalarField())
+}
+
+func isSolvedG2(p, q gobn254.G2Affine) error {
+	var sum, doubled gobn254.G2Affine
+	sum.Add(&p, &q)
+	doubled.Double(&p)
+	// The encoding of the EVM, which is that of gnark-crypto without its flags of compression.
+	encoding := sum.RawBytes()
+	assignment := &testG2Circuit{
+		P:       NewG2Affine(p),
+		Q:       NewG2Affine(q),
+		Sum:     NewG2Affine(sum),
+		Doubled: NewG2Affine(doubled),
+	}
+	copy(assignment.Encoding[:], vars.NewBytesFrom(encoding[:]))
+	return test.IsSolved(&testG2Circuit{}, assignment, ecc.BN254.ScalarField())
+}
+
+func TestG1(t *testing.T) {
+	p, q := mulG1(12345), mulG1(67890)
+	s, _ := new(big.Int).SetString("fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210", 16)
+	var mul gobn254.G1Affine
+	mul.ScalarMultiplication(&p, s)
+	assert.NoError(t, isSolvedG1(p, q, s, mul))
+
+	// The scalar multiplication must be correct.
+	assert.Error(t, isSolvedG1(p, q, s, q))
+
+	// The additions are complete: equal and opposite points, the identity and zero scalars.
+	var neg gobn254.G1Affine
+	neg.Neg(&p)
+	assert.NoError(t, isSolvedG1(p, p, big.NewInt(-1), neg))
+	assert.NoError(t, isSolvedG1(p, neg, big.NewInt(0), gobn254.G1Affine{}))
+	assert.NoError(t, isSolvedG1(gobn254.G1Affine{}, q, big.NewInt(5), gobn254.G1Affine{}))
+}
+
+func TestG2(t *testing.T) {
+	p, q := mulG2(12345), mulG2(67890)
+	assert.NoError(t, isSolvedG2(p, q))
+
+	var neg gobn254.G2Affine
+	neg.Neg(&p)
+	assert.NoError(t, isSolvedG2(p, p))
+	assert.NoError(t, isSolvedG2(p, neg))
+	assert.NoError(t, isSolvedG2(gobn254.G2Affine{}, q))
+}
+
+func TestBLSSignature(t *testing.T) {
+	hashedMessage, err := gobn254.HashToG1([]byte("message"), []byte("BLS_SIG_BN254G1_XMD:KECCAK-256_SSWU_RO_NUL_"))
+	assert.NoError(t, err)
+	sk := big.NewInt(123456789)
+	var signature gobn254.G1Affine
+	signature.ScalarMultiplication(&hashedMessage, sk)
+	pubkey := mulG2(sk.Int64())
+
+	assignment := &testBLSCircuit{
+		Pubkey:        NewG2Affine(pubkey),
+		HashedMessage: NewG1Affine(hashedMessage),
+		Signature:     NewG1Affine(signature),
+	}
+	assert.NoError(t, test.IsSolved(&testBLSCircuit{}, assignment, ecc.BN254.ScalarField()))
+
+	// The signature of another key.
+	assignment.Pubkey = NewG2Affine(mulG2(sk.Int64() + 1))
+	assert.Error(t, test.IsSolved(&testBLSCircuit{}, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,24 @@
+package bn254
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bn254"
+	"github.com/succinctlabs/succinctx/gnarkx/curves/weierstrass"
+)
+
+// The field of the coordinates of G2, Fp2, for the complete formulas.
+type g2Field struct {
+	*fields_bn254.Ext2
+	api frontend.API
+	fp  weierstrass.Emulated[Fp]
+}
+
+func (f g2Field) Div(x, y *fields_bn254.E2) *fields_bn254.E2 {
+	return f.DivUnchecked(x, y)
+}
+
+// Returns whether the element is zero, as both of its coordinates are. Ext2.IsZero is not used as
+// it relies on fp.IsZero, which in gnark v0.9.1 only checks the first limb.
+func (f g2Field) IsZero(x *fields_bn254.E2) frontend.Variable {
+	return f.api.And(f.fp.IsZero(&x.A0), f.fp.IsZero(&x.A1))
+}
@@ -0,0 +1,365 @@
+// The API for the arithmetic of secp256k1, y^2 = x^3 + 7, over its emulated base field: additions,
+// doublings, scalar multiplications and the decompression of points, which the ECDSA, Schnorr and
+// Taproot gadgets build on.
+//
+// Points are affine, as in gnark, with the identity as (0, 0), which is not on the curve. The
+// additions use the complete formulas of the weierstrass package in projective coordinates, so that
+// they hold for all points of the curve, including equal, opposite and identity points, which the
+// incomplete formulas of sw_emulated do not. The scalar multiplication of a point uses the
+// endomorphism (x, y) -> (beta * x, y) = [lambda](x, y) to split the scalar into two halves of 130
+// bits (GLV), whose bits are then taken two at a time from a table of 16 points. The scalar
+// multiplication of the generator takes the scalar four bits at a time from precomputed tables of
+// the multiples of the generator, without doublings.
+package secp256k1
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gosecp256k1 "github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/curves/weierstrass"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The emulated base field of the coordinates of the points.
+type Fp = emulated.Secp256k1Fp
+
+// The emulated scalar field of the order of the curve.
+type Fr = emulated.Secp256k1Fr
+
+// A point of the curve in the circuit, in affine coordinates where the identity is (0, 0).
+type Point = sw_emulated.AffinePoint[Fp]
+
+// A scalar in the circuit.
+type Scalar = emulated.Element[Fr]
+
+var (
+	// The order of the curve.
+	Order, _ = new(big.Int).SetString("115792089237316195423570985008687907852837564279074904382605163141518161494337", 10)
+
+	// beta is a cube root of unity of the base field and lambda one of the scalar field, for which
+	// (beta * x, y) = [lambda](x, y).
+	beta, _   = new(big.Int).SetString("55594575648329892869085402983802832744385952214688224221778511981742606582254", 10)
+	lambda, _ = new(big.Int).SetString("37718080363155996902926221483475020450927657555482586988616620542887997980018", 10)
+
+	// The short basis of the lattice of the decompositions of zero, k1 + k2 * lambda = 0.
+	lattice ecc.Lattice
+
+	// baseTables[i][j] = [j * 16^i]G.
+	baseTables [64][16]gosecp256k1.G1Affine
+)
+
+// The number of bits of the halves of scalars, which the decompositions of the lattice fit in.
+const glvBits = 130
+
+// 3 * 7, the coefficient of the complete formulas for y^2 = x^3 + 7.
+var b3 = big.NewInt(21)
+
+func init() {
+	ecc.PrecomputeLattice(Order, lambda, &lattice)
+	_, g := gosecp256k1.Generators()
+	for i := range baseTables {
+		for j := 1; j < 16; j++ {
+			baseTables[i][j].Add(&baseTables[i][j-1], &g)
+		}
+		g.Double(&g).Double(&g).Double(&g).Double(&g)
+	}
+}
+
+// Creates a point from its coordinates, which are zero for the identity.
+func NewPoint(x, y *big.Int) Point {
+	return Point{X: emulated.ValueOf[Fp](x), Y: emulated.ValueOf[Fp](y)}
+}
+
+// Creates a scalar.
+func NewScalar(s *big.Int) Scalar {
+	return emulated.ValueOf[Fr](s)
+}
+
+// A point in projective coordinates (X : Y : Z), where the identity is (0 : 1 : 0).
+type projective = weierstrass.Projective[*emulated.Element[Fp]]
+
+// Secp256k1API is a wrapper around succinct.API that provides methods for the points of secp256k1.
+type Secp256k1API struct {
+	api   builder.API
+	fp    weierstrass.Emulated[Fp]
+	fr    *emulated.Field[Fr]
+	curve *weierstrass.Curve[*emulated.Element[Fp]]
+}
+
+// Creates a new Secp256k1API.
+func NewAPI(api *builder.API) *Secp256k1API {
+	fp, err := emulated.NewField[Fp](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[Fr](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	field := weierstrass.NewEmulated[Fp](api.FrontendAPI(), fp)
+	curve := weierstrass.New[*emulated.Element[Fp]](field, func(x *emulated.Element[Fp]) *emulated.Element[Fp] {
+		return fp.MulConst(x, b3)
+	})
+	return &Secp256k1API{api: *api, fp: field, fr: fr, curve: curve}
+}
+
+// Returns the generator G.
+func (a *Secp256k1API) Generator() *Point {
+	_, g := gosecp256k1.Generators()
+	return &Point{X: *a.fp.NewElement(g.X.BigInt(new(big.Int))), Y: *a.fp.NewElement(g.Y.BigInt(new(big.Int)))}
+}
+
+// Returns the identity (0, 0).
+func (a *Secp256k1API) Identity() *Point {
+	return &Point{X: *a.fp.Zero(), Y: *a.fp.Zero()}
+}
+
+// Returns p + q for any points of the curve.
+func (a *Secp256k1API) Add(p, q *Point) *Point {
+	return a.toAffine(a.curve.Add(a.toProjective(p), a.toProjective(q)))
+}
+
+// Returns 2p for any point of the curve.
+func (a *Secp256k1API) Double(p *Point) *Point {
+	return a.toAffine(a.curve.Double(a.toProjective(p)))
+}
+
+// Returns -p.
+func (a *Secp256k1API) Neg(p *Point) *Point {
+	return &Point{X: p.X, Y: *a.fp.Neg(&p.Y)}
+}
+
+// Returns p if the selector is set and q otherwise.
+func (a *Secp256k1API) Select(selector vars.Bool, p, q *Point) *Point {
+	s := selector.Value.Value
+	return &Point{X: *a.fp.Select(s, &p.X, &q.X), Y: *a.fp.Select(s, &p.Y, &q.Y)}
+}
+
+// Returns whether p is the identity.
+func (a *Secp256k1API) IsIdentity(p *Point) vars.Bool {
+	isIdentity := a.api.FrontendAPI().And(a.fp.IsZero(&p.X), a.fp.IsZero(&p.Y))
+	return vars.Bool{Value: vars.Variable{Value: isIdentity}}
+}
+
+// Asserts that p is a point of the curve or the identity.
+func (a *Secp256k1API) AssertIsOnCurve(p *Point) {
+	fp := a.fp
+	isIdentity := a.IsIdentity(p).Value.Value
+	y2 := fp.Mul(&p.Y, &p.Y)
+	x3 := fp.Add(fp.Mul(fp.Mul(&p.X, &p.X), &p.X), fp.NewElement(7))
+	fp.AssertIsEqual(fp.Select(isIdentity, fp.Zero(), y2), fp.Select(isIdentity, fp.Zero(), x3))
+}
+
+// Asserts that two points are equal.
+func (a *Secp256k1API) AssertIsEqual(p, q *Point) {
+	a.fp.AssertIsEqual(&p.X, &q.X)
+	a.fp.AssertIsEqual(&p.Y, &q.Y)
+}
+
+// Returns [s]p for any point of the curve and any scalar.
+func (a *Secp256k1API) ScalarMul(p *Point, s *Scalar) *Point {
+	fp, fr := a.fp, a.fr
+
+	// s = ±k1 ± k2 * lambda, where the halves and their signs are hinted and checked.
+	limbs := fr.Reduce(s).Limbs
+	in := make([]vars.Variable, len(limbs))
+	for i := range limbs {
+		in[i] = vars.Variable{Value: limbs[i]}
+	}
+	out := a.api.HintVariables(glvHint, 4, in...)
+	var bits [2][]frontend.Variable
+	var halves [2]*emulated.Element[Fr]
+	for i := 0; i < 2; i++ {
+		kBits := a.api.ToBinaryLE(out[2*i], glvBits)
+		bits[i] = make([]frontend.Variable, glvBits)
+		for j := range kBits {
+			bits[i][j] = kBits[j].Value.Value
+		}
+		a.api.AssertIsBoolean(out[2*i+1])
+		k := fr.FromBits(bits[i]...)
+		halves[i] = fr.Select(out[2*i+1].Value, fr.Neg(k), k)
+	}
+	fr.AssertIsEqual(fr.Add(halves[0], fr.Mul(halves[1], fr.NewElement(lambda))), s)
+
+	// The table of [i](±p) + [j](±[lambda]p) for i, j < 4 at i + 4j.
+	curve := a.curve
+	q := a.toProjective(p)
+	phi := projective{X: fp.Mul(q.X, fp.NewElement(beta)), Y: q.Y, Z: q.Z}
+	q1 := curve.Select(out[1].Value, curve.Neg(q), q)
+	q2 := curve.Select(out[3].Value, curve.Neg(phi), phi)
+	var table [16]projective
+	table[0] = curve.Identity()
+	table[1] = q1
+	table[2] = curve.Double(q1)
+	table[3] = curve.Add(table[2], q1)
+	table[4] = q2
+	table[8] = curve.Double(q2)
+	table[12] = curve.Add(table[8], q2)
+	for i := 1; i < 4; i++ {
+		for j := 1; j < 4; j++ {
+			table[i+4*j] = curve.Add(table[i], table[4*j])
+		}
+	}
+
+	window := func(i int) []frontend.Variable {
+		return []frontend.Variable{bits[0][i], bits[0][i+1], bits[1][i], bits[1][i+1]}
+	}
+	result := curve.Lookup(window(glvBits-2), table[:])
+	for i := glvBits - 4; i >= 0; i -= 2 {
+		result = curve.Double(curve.Double(result))
+		result = curve.Add(result, curve.Lookup(window(i), table[:]))
+	}
+	return a.toAffine(result)
+}
+
+// Returns [s]G for any scalar.
+func (a *Secp256k1API) ScalarMulBase(s *Scalar) *Point {
+	fr := a.fr
+	sr := fr.Reduce(s)
+	fr.AssertIsInRange(sr)
+	bits := fr.ToBits(sr)
+
+	var result projective
+	for i := range baseTables {
+		var table [16]projective
+		for j := range table {
+			table[j] = a.constant(&baseTables[i][j])
+		}
+		term := a.curve.Lookup(bits[4*i:4*i+4], table[:])
+		if i == 0 {
+			result = term
+		} else {
+			result = a.curve.Add(result, term)
+		}
+	}
+	return a.toAffine(result)
+}
+
+// Returns [s1]G + [s2]p, as for the verification of signatures.
+func (a *Secp256k1API) JointScalarMulBase(p *Point, s2, s1 *Scalar) *Point {
+	return a.Add(a.ScalarMulBase(s1), a.ScalarMul(p, s2))
+}
+
+// Returns the point of the curve with the x coordinate whose y coordinate has the parity. The
+// circuit fails if x is not the x coordinate of a point.
+func (a *Secp256k1API) Decompress(x *emulated.Element[Fp], isOdd vars.Bool) *Point {
+	fp := a.fp
+	y2 := fp.Add(fp.Mul(fp.Mul(x, x), x), fp.NewElement(7))
+	y := fp.Reduce(fp.Sqrt(y2))
+	fp.AssertIsInRange(y)
+	parity := fp.ToBits(y)[0]
+	y = fp.Select(a.api.FrontendAPI().Xor(parity, isOdd.Value.Value), fp.Neg(y), y)
+	return &Point{X: *x, Y: *y}
+}
+
+// Returns the point of a compressed SEC1 encoding, 0x02 or 0x03 for the parity of y followed by the
+// big-endian x, which must be less than the modulus.
+func (a *Secp256k1API) DecompressBytes(encoding [33]vars.Byte) *Point {
+	isOdd := vars.Bool{Value: a.api.Sub(encoding[0].Value, vars.NewVariableFromInt(2))}
+	a.api.AssertIsBoolean(isOdd.Value)
+	var x [32]vars.Byte
+	copy(x[:], encoding[1:])
+	return a.Decompress(a.fromBytes(x), isOdd)
+}
+
+// Returns the point of big-endian coordinates, which must be less than the modulus. The point is
+// not checked to be on the curve.
+func (a *Secp256k1API) PointFromBytes(x, y [32]vars.Byte) *Point {
+	return &Point{X: *a.fromBytes(x), Y: *a.fromBytes(y)}
+}
+
+// Returns the big-endian coordinates of a point, which are zero for the identity.
+func (a *Secp256k1API) PointToBytes(p *Point) ([32]vars.Byte, [32]vars.Byte) {
+	return a.toBytes(&p.X), a.toBytes(&p.Y)
+}
+
+// Returns the scalar of big-endian bytes, reduced modulo the order.
+func (a *Secp256k1API) ScalarFromBytes(in [32]vars.Byte) *Scalar {
+	return a.fr.Reduce(a.fr.FromBits(bitsLE(a.api, in)...))
+}
+
+// Returns the scalar of big-endian bytes, asserting that it is less than the order, as for the
+// scalars of signatures.
+func (a *Secp256k1API) ScalarFromCanonicalBytes(in [32]vars.Byte) *Scalar {
+	s := a.fr.FromBits(bitsLE(a.api, in)...)
+	a.fr.AssertIsInRange(s)
+	return s
+}
+
+// Returns the element of big-endian bytes, asserting that it is less than the modulus.
+func (a *Secp256k1API) fromBytes(in [32]vars.Byte) *emulated.Element[Fp] {
+	e := a.fp.FromBits(bitsLE(a.api, in)...)
+	a.fp.AssertIsInRange(e)
+	return e
+}
+
+// Returns the big-endian bytes of the canonical element.
+func (a *Secp256k1API) toBytes(e *emulated.Element[Fp]) [32]vars.Byte {
+	e = a.fp.Reduce(e)
+	a.fp.AssertIsInRange(e)
+	bits := a.fp.ToBits(e)
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			byteBits[j] = vars.Bool{Value: vars.Variable{Value: bits[8*i+j]}}
+		}
+		out[31-i] = a.api.ToByteFromBits(byteBits)
+	}
+	return out
+}
+
+// Returns the little-endian bits of big-endian bytes, which range checks them.
+func bitsLE(api builder.API, in [32]vars.Byte) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, 256)
+	for i := 31; i >= 0; i-- {
+		byteBits := api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			bits = append(bits, byteBits[j].Value.Value)
+		}
+	}
+	return bits
+}
+
+func (a *Secp256k1API) toProjective(p *Point) projective {
+	return a.curve.FromAffine(a.IsIdentity(p).Value.Value, &p.X, &p.Y)
+}
+
+func (a *Secp256k1API) toAffine(p projective) *Point {
+	x, y := a.curve.ToAffine(p)
+	return &Point{X: *x, Y: *y}
+}
+
+func (a *Secp256k1API) constant(p *gosecp256k1.G1Affine) projective {
+	fp := a.fp
+	if p.IsInfinity() {
+		return projective{X: fp.Zero(), Y: fp.One(), Z: fp.Zero()}
+	}
+	return projective{X: fp.NewElement(p.X.BigInt(new(big.Int))), Y: fp.NewElement(p.Y.BigInt(new(big.Int))), Z: fp.One()}
+}
+
+// Returns the halves and the signs of the decomposition of a scalar, given by its limbs of 64 bits,
+// as |k1|, k1 < 0, |k2|, k2 < 0 for s = k1 + k2 * lambda modulo the order.
+var glvHint = builder.NewHint("secp256k1.glv", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	var fr Fr
+	s := new(big.Int)
+	for i := len(inputs) - 1; i >= 0; i-- {
+		s.Lsh(s, fr.BitsPerLimb())
+		s.Add(s, inputs[i])
+	}
+	s.Mod(s, Order)
+	k := ecc.SplitScalar(s, &lattice)
+	for i := 0; i < 2; i++ {
+		outputs[2*i].Abs(&k[i])
+		outputs[2*i+1].SetUint64(0)
+		if k[i].Sign() < 0 {
+			outputs[2*i+1].SetUint64(1)
+		}
+	}
+	return nil
+})
@@ -0,0 +1,129 @@
+package secp256k1
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gosecp256k1 "github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	P, Q    Point
+	S       Scalar
+	Sum     Point
+	Doubled Point
+	Mul     Point
+	MulBase Point
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := NewAPI(api)
+	curve.AssertIsOnCurve(&c.P)
+	curve.AssertIsOnCurve(&c.Q)
+	curve.AssertIsEqual(curve.Add(&c.P, &c.Q), &c.Sum)
+	curve.AssertIsEqual(curve.Double(&c.P), &c.Doubled)
+	curve.AssertIsEqual(curve.ScalarMul(&c.P, &c.S), &c.Mul)
+	curve.AssertIsEqual(curve.ScalarMulBase(&c.S), &c.MulBase)
+	return nil
+}
+
+type testDecompressCircuit struct {
+	Encoding [33]vars.Byte
+	X, Y     [32]vars.Byte
+}
+
+func (c *testDecompressCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve := NewAPI(api)
+	x, y := curve.PointToBytes(curve.DecompressBytes(c.Encoding))
+	api.AssertIsEqualBytes32(x, c.X)
+	api.AssertIsEqualBytes32(y, c.Y)
+	return nil
+}
+
+func pointOf(p gosecp256k1.G1Affine) Point {
+	return NewPoint(p.X.BigInt(new(big.Int)), p.Y.BigInt(new(big.Int)))
+}
+
+func mulBase(s *big.Int) gosecp256k1.G1Affine {
+	_, g := gosecp256k1.Generators()
+	var result gosecp256k1.G1Affine
+	return *result.ScalarMultiplication(&g, s)
+}
+
+func isSolved(p, q gosecp256k1.G1Affine, s *big.Int, mul gosecp256k1.G1Affine) error {
+	var sum, doubled gosecp256k1.G1Affine
+	sum.Add(&p, &q)
+	doubled.Double(&p)
+	assignment := &testCircuit{
+		P:       pointOf(p),
+		Q:       pointOf(q),
+		S:       NewScalar(s),
+		Sum:     pointOf(sum),
+		Doubled: pointOf(doubled),
+		Mul:     pointOf(mul),
+		MulBase: pointOf(mulBase(s)),
+	}
+	return test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField())
+}
+
+func TestEndomorphism(t *testing.T) {
+	p := mulBase(big.NewInt(12345))
+	var phi gosecp256k1.G1Affine
+	phi.X.Mul(&p.X, new(fp.Element).SetBigInt(beta))
+	phi.Y = p.Y
+	var expected gosecp256k1.G1Affine
+	expected.ScalarMultiplication(&p, lambda)
+	assert.True(t, phi.Equal(&expected))
+}
+
+func TestArithmetic(t *testing.T) {
+	p := mulBase(big.NewInt(12345))
+	q := mulBase(new(big.Int).Lsh(big.NewInt(987654321), 200))
+	s, _ := new(big.Int).SetString("fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210", 16)
+	var mul gosecp256k1.G1Affine
+	mul.ScalarMultiplication(&p, s)
+	assert.NoError(t, isSolved(p, q, s, mul))
+
+	// The scalar multiplication must be correct.
+	assert.Error(t, isSolved(p, q, s, q))
+
+	// The additions are complete: the sum of (x, y) and (beta * x, -y), which the unified formulas
+	// of sw_emulated take for the identity, opposite points, the identity and zero scalars.
+	var phi gosecp256k1.G1Affine
+	phi.X.Mul(&p.X, new(fp.Element).SetBigInt(beta))
+	phi.Y.Neg(&p.Y)
+	var neg gosecp256k1.G1Affine
+	neg.Neg(&p)
+	assert.NoError(t, isSolved(p, phi, new(big.Int).Sub(Order, big.NewInt(1)), neg))
+	assert.NoError(t, isSolved(gosecp256k1.G1Affine{}, neg, big.NewInt(0), gosecp256k1.G1Affine{}))
+	var endo gosecp256k1.G1Affine
+	endo.Neg(&phi)
+	assert.NoError(t, isSolved(p, neg, lambda, endo))
+}
+
+func TestDecompressBytes(t *testing.T) {
+	for _, s := range []int64{1, 2, 12345} {
+		p := mulBase(big.NewInt(s))
+		x := p.X.Bytes()
+		y := p.Y.Bytes()
+		encoding := append([]byte{2 + byte(p.Y.BigInt(new(big.Int)).Bit(0))}, x[:]...)
+		assignment := &testDecompressCircuit{}
+		copy(assignment.Encoding[:], vars.NewBytesFrom(encoding))
+		vars.SetBytes32(&assignment.X, x)
+		vars.SetBytes32(&assignment.Y, y)
+		assert.NoError(t, test.IsSolved(&testDecompressCircuit{}, assignment, ecc.BN254.ScalarField()))
+
+		// The prefix gives the parity of y.
+		assignment.Encoding[0].Set(5 - encoding[0])
+		assert.Error(t, test.IsSolved(&testDecompressCircuit{}, assignment, ecc.BN254.ScalarField()))
+	}
+}
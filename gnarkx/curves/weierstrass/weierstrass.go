@@ -0,0 +1,158 @@
+// The arithmetic of the points of short Weierstrass curves with a = 0, y^2 = x^3 + b, over any
+// field of coordinates, such as an emulated base field or its quadratic extension, which the curve
+// packages build on.
+//
+// The additions use the complete formulas of Renes, Costello and Batina in projective coordinates,
+// so that they hold for all points of the curve, including equal, opposite and identity points,
+// which the incomplete and unified formulas of sw_emulated do not.
+//
+// Reference: https://eprint.iacr.org/2015/1060 (Algorithms 7 and 9)
+package weierstrass
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// The arithmetic of the field of the coordinates. Its methods are those of emulated.Field and
+// fields_bn254.Ext2, with Div and IsZero as below.
+type Field[E any] interface {
+	Add(x, y E) E
+	Sub(x, y E) E
+	Mul(x, y E) E
+	Neg(x E) E
+	Select(selector frontend.Variable, x, y E) E
+	Zero() E
+	One() E
+
+	// Returns x / y for y not zero.
+	Div(x, y E) E
+	// Returns whether x is zero modulo the modulus.
+	IsZero(x E) frontend.Variable
+}
+
+// Emulated is the Field of an emulated field.
+type Emulated[T emulated.FieldParams] struct {
+	*emulated.Field[T]
+	api frontend.API
+}
+
+// Creates the Field of the emulated field.
+func NewEmulated[T emulated.FieldParams](api frontend.API, f *emulated.Field[T]) Emulated[T] {
+	return Emulated[T]{Field: f, api: api}
+}
+
+// Returns whether the element is zero modulo the modulus. emulated.Field.IsZero is not used as in
+// gnark v0.9.1 it only checks the first limb.
+func (f Emulated[T]) IsZero(x *emulated.Element[T]) frontend.Variable {
+	x = f.Reduce(x)
+	f.AssertIsInRange(x)
+	result := frontend.Variable(1)
+	for i := range x.Limbs {
+		result = f.api.Mul(result, f.api.IsZero(x.Limbs[i]))
+	}
+	return result
+}
+
+// A point in projective coordinates (X : Y : Z), where the identity is (0 : 1 : 0).
+type Projective[E any] struct {
+	X, Y, Z E
+}
+
+// A Curve y^2 = x^3 + b over the field of its coordinates.
+type Curve[E any] struct {
+	f     Field[E]
+	mulB3 func(x E) E
+}
+
+// Creates the curve over the field whose coefficient b is given by mulB3, which returns 3b * x,
+// such as a product by a constant of the field.
+func New[E any](f Field[E], mulB3 func(x E) E) *Curve[E] {
+	return &Curve[E]{f: f, mulB3: mulB3}
+}
+
+// Returns the identity (0 : 1 : 0).
+func (c *Curve[E]) Identity() Projective[E] {
+	return Projective[E]{X: c.f.Zero(), Y: c.f.One(), Z: c.f.Zero()}
+}
+
+// Returns the projective point of the affine point (x, y), which is the identity if it is set.
+func (c *Curve[E]) FromAffine(isIdentity frontend.Variable, x, y E) Projective[E] {
+	f := c.f
+	return Projective[E]{X: x, Y: f.Select(isIdentity, f.One(), y), Z: f.Select(isIdentity, f.Zero(), f.One())}
+}
+
+// Returns the affine point, dividing by Z only where it is not zero, as 0 / 0 would be any element.
+// The identity is (0, 0).
+func (c *Curve[E]) ToAffine(p Projective[E]) (E, E) {
+	f := c.f
+	isIdentity := f.IsZero(p.Z)
+	z := f.Select(isIdentity, f.One(), p.Z)
+	x := f.Select(isIdentity, f.Zero(), f.Div(p.X, z))
+	y := f.Select(isIdentity, f.Zero(), f.Div(p.Y, z))
+	return x, y
+}
+
+// Returns p + q with the complete formulas of Algorithm 7.
+func (c *Curve[E]) Add(p, q Projective[E]) Projective[E] {
+	f := c.f
+	t0 := f.Mul(p.X, q.X)
+	t1 := f.Mul(p.Y, q.Y)
+	t2 := f.Mul(p.Z, q.Z)
+	t3 := f.Sub(f.Mul(f.Add(p.X, p.Y), f.Add(q.X, q.Y)), f.Add(t0, t1))
+	t4 := f.Sub(f.Mul(f.Add(p.Y, p.Z), f.Add(q.Y, q.Z)), f.Add(t1, t2))
+	y3 := f.Sub(f.Mul(f.Add(p.X, p.Z), f.Add(q.X, q.Z)), f.Add(t0, t2))
+	t0 = f.Add(f.Add(t0, t0), t0)
+	t2 = c.mulB3(t2)
+	z3 := f.Add(t1, t2)
+	t1 = f.Sub(t1, t2)
+	y3 = c.mulB3(y3)
+	x3 := f.Sub(f.Mul(t3, t1), f.Mul(t4, y3))
+	y3 = f.Add(f.Mul(t1, z3), f.Mul(y3, t0))
+	z3 = f.Add(f.Mul(z3, t4), f.Mul(t0, t3))
+	return Projective[E]{X: x3, Y: y3, Z: z3}
+}
+
+// Returns 2p with the complete formulas of Algorithm 9.
+func (c *Curve[E]) Double(p Projective[E]) Projective[E] {
+	f := c.f
+	t0 := f.Mul(p.Y, p.Y)
+	z3 := f.Add(t0, t0)
+	z3 = f.Add(z3, z3)
+	z3 = f.Add(z3, z3)
+	t1 := f.Mul(p.Y, p.Z)
+	t2 := c.mulB3(f.Mul(p.Z, p.Z))
+	x3 := f.Mul(t2, z3)
+	y3 := f.Add(t0, t2)
+	z3 = f.Mul(t1, z3)
+	t0 = f.Sub(t0, f.Add(f.Add(t2, t2), t2))
+	y3 = f.Add(x3, f.Mul(t0, y3))
+	x3 = f.Mul(t0, f.Mul(p.X, p.Y))
+	x3 = f.Add(x3, x3)
+	return Projective[E]{X: x3, Y: y3, Z: z3}
+}
+
+// Returns -p.
+func (c *Curve[E]) Neg(p Projective[E]) Projective[E] {
+	return Projective[E]{X: p.X, Y: c.f.Neg(p.Y), Z: p.Z}
+}
+
+// Returns p if the selector is set and q otherwise.
+func (c *Curve[E]) Select(selector frontend.Variable, p, q Projective[E]) Projective[E] {
+	f := c.f
+	return Projective[E]{X: f.Select(selector, p.X, q.X), Y: f.Select(selector, p.Y, q.Y), Z: f.Select(selector, p.Z, q.Z)}
+}
+
+// Returns table[b0 + 2 * b1 + ...] with a tree of selections, where the table has 2^len(bits)
+// points. Lookup2 is not used as in gnark v0.9.1 it drops the limbs past those of its first input.
+func (c *Curve[E]) Lookup(bits []frontend.Variable, table []Projective[E]) Projective[E] {
+	level := table
+	for _, bit := range bits {
+		next := make([]Projective[E], len(level)/2)
+		for i := range next {
+			next[i] = c.Select(bit, level[2*i+1], level[2*i])
+		}
+		level = next
+	}
+	return level[0]
+}
@@ -0,0 +1,83 @@
+package weierstrass
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gosecp256k1 "github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type Fp = emulated.Secp256k1Fp
+
+// An affine point, where the identity is (0, 0).
+type point struct {
+	X, Y emulated.Element[Fp]
+}
+
+// The circuit checks P + Q, 2P and the selection of [2]P from a table over secp256k1, y^2 = x^3 + 7.
+type testCircuit struct {
+	P, Q, Sum, Doubled point
+	Bits               [2]frontend.Variable
+}
+
+func (c *testCircuit) Define(api frontend.API) error {
+	fp, err := emulated.NewField[Fp](api)
+	if err != nil {
+		return err
+	}
+	f := NewEmulated[Fp](api, fp)
+	curve := New[*emulated.Element[Fp]](f, func(x *emulated.Element[Fp]) *emulated.Element[Fp] {
+		return fp.MulConst(x, big.NewInt(21))
+	})
+	projective := func(p *point) Projective[*emulated.Element[Fp]] {
+		return curve.FromAffine(api.And(f.IsZero(&p.X), f.IsZero(&p.Y)), &p.X, &p.Y)
+	}
+	assertIsEqual := func(p Projective[*emulated.Element[Fp]], expected *point) {
+		x, y := curve.ToAffine(p)
+		fp.AssertIsEqual(x, &expected.X)
+		fp.AssertIsEqual(y, &expected.Y)
+	}
+	p, q := projective(&c.P), projective(&c.Q)
+	assertIsEqual(curve.Add(p, q), &c.Sum)
+	assertIsEqual(curve.Double(p), &c.Doubled)
+	table := []Projective[*emulated.Element[Fp]]{curve.Identity(), p, curve.Double(p), curve.Neg(p)}
+	assertIsEqual(curve.Lookup(c.Bits[:], table), &c.Doubled)
+	return nil
+}
+
+func pointOf(p gosecp256k1.G1Affine) point {
+	return point{X: emulated.ValueOf[Fp](p.X.BigInt(new(big.Int))), Y: emulated.ValueOf[Fp](p.Y.BigInt(new(big.Int)))}
+}
+
+func TestCompleteFormulas(t *testing.T) {
+	_, g := gosecp256k1.Generators()
+	var p, q, neg, identity gosecp256k1.G1Affine
+	p.ScalarMultiplication(&g, big.NewInt(1234))
+	q.ScalarMultiplication(&g, big.NewInt(5678))
+	neg.Neg(&p)
+
+	// The formulas hold for distinct, equal and opposite points and for the identity.
+	for _, pair := range [][2]gosecp256k1.G1Affine{{p, q}, {p, p}, {p, neg}, {p, identity}, {identity, q}, {identity, identity}} {
+		var sum, doubled gosecp256k1.G1Affine
+		sum.Add(&pair[0], &pair[1])
+		doubled.Double(&pair[0])
+		assignment := &testCircuit{
+			P:       pointOf(pair[0]),
+			Q:       pointOf(pair[1]),
+			Sum:     pointOf(sum),
+			Doubled: pointOf(doubled),
+			Bits:    [2]frontend.Variable{0, 1},
+		}
+		assert.NoError(t, test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField()))
+	}
+
+	var sum gosecp256k1.G1Affine
+	sum.Add(&p, &p)
+	assignment := &testCircuit{P: pointOf(p), Q: pointOf(q), Sum: pointOf(sum), Doubled: pointOf(sum), Bits: [2]frontend.Variable{0, 1}}
+	assert.Error(t, test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,493 @@
+// A circuit verifying a DNSSEC chain of trust from the root trust anchor to a TXT record, so that
+// claims anchored in DNS, like domain ownership, can be proven without trusting a resolver.
+//
+// Every zone of the chain signs its DNSKEY RRset with its key signing key (KSK), which is
+// authenticated by a DS record in the parent zone, or by the trust anchor for the root. The DS
+// RRset of the next zone and the final TXT RRset are signed by the zone signing key (ZSK) found in
+// the DNSKEY RRset. The zone names, algorithms, and key sizes are fixed when the circuit is
+// compiled, while the keys, records, and signatures are part of the witness.
+//
+// The circuit takes a uint64 unix timestamp as its input and checks that all signatures are valid
+// at that time. It outputs the length of the first string of the TXT record as a uint64 followed
+// by the string right-padded with zeros to MaxTXTLength bytes. Supported algorithms are RSA/SHA-256
+// with exponent 65537 and ECDSA P-256/SHA-256, and every RRset must be given as its RFC 4034
+// signing input, i.e. the RRSIG RDATA without the signature followed by the canonical records.
+package dnssec
+
+import (
+	"bytes"
+	gosha256 "crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/rsa"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A zone of the chain of trust.
+type Zone struct {
+	// The name of the zone, e.g. "." for the root or "example.com.".
+	Name string
+
+	// The algorithm of the keys of the zone, and for RSA the lengths of their moduli in bits.
+	Algorithm int
+	KSKBits   int
+	ZSKBits   int
+}
+
+// Config describes the chain of trust and the record that a circuit verifies.
+type Config struct {
+	// The SHA-256 digest of the DS record of the root KSK.
+	TrustAnchor [32]byte
+
+	// The zones of the chain, starting with the root.
+	Zones []Zone
+
+	// The owner name of the TXT record, which must be in the last zone.
+	Name string
+
+	// The maximum length of a signing input and of the TXT string, which must be a multiple of 32.
+	MaxRRSetLength int
+	MaxTXTLength   int
+}
+
+// A signing input of an RRset and its RRSIG signature.
+type RRSet struct {
+	SigningInput []byte
+	Signature    []byte
+}
+
+// The records of a zone that authenticate the next zone or the TXT record.
+type ZoneProof struct {
+	// The DNSKEY RRset signed by the KSK.
+	Keys RRSet
+
+	// The DNSKEY RDATA of the KSK and ZSK.
+	KSK []byte
+	ZSK []byte
+
+	// The DS RRset of the next zone signed by the ZSK. Unused for the last zone.
+	DS RRSet
+}
+
+// A chain of trust from the root to a TXT record.
+type Proof struct {
+	Zones []ZoneProof
+	TXT   RRSet
+}
+
+// The witness of a signed RRset.
+type RRSetWitness struct {
+	Data      []vars.Byte
+	Length    vars.Variable
+	Signature []vars.Byte
+}
+
+// The witness of a zone, with the positions of the KSK, ZSK, and DS records in their RRsets.
+type ZoneWitness struct {
+	Keys     RRSetWitness
+	KSK      []vars.Byte
+	KSKIndex vars.Variable
+	ZSK      []vars.Byte
+	ZSKIndex vars.Variable
+	DS       RRSetWitness
+	DSIndex  vars.Variable
+}
+
+// Circuit verifies a DNSSEC chain of trust and outputs the value of a TXT record.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Zones    []ZoneWitness
+	TXT      RRSetWitness
+	TXTIndex vars.Variable
+
+	config *Config `gnark:"-"`
+	proof  *Proof  `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+func newRRSetWitness(maxLength int, signatureLength int) RRSetWitness {
+	return RRSetWitness{
+		Data:      vars.NewBytes(maxLength),
+		Length:    vars.NewVariable(),
+		Signature: vars.NewBytes(signatureLength),
+	}
+}
+
+// Creates a new circuit for the chain of trust described by the config.
+func NewCircuit(config *Config) *Circuit {
+	if len(config.Zones) == 0 {
+		panic("the chain must contain at least one zone")
+	}
+	if config.MaxTXTLength <= 0 || config.MaxTXTLength%32 != 0 || config.MaxTXTLength > 255 {
+		panic(fmt.Sprintf("max txt length %d must be a positive multiple of 32 below 256", config.MaxTXTLength))
+	}
+	if !bytes.HasSuffix(encodeName(config.Name), encodeName(config.Zones[len(config.Zones)-1].Name)) {
+		panic(fmt.Sprintf("%s is not in the last zone", config.Name))
+	}
+
+	circuit := &Circuit{
+		InputBytes:  vars.NewBytes(8),
+		OutputBytes: vars.NewBytes(8 + config.MaxTXTLength),
+		Zones:       make([]ZoneWitness, len(config.Zones)),
+		TXTIndex:    vars.NewVariable(),
+		config:      config,
+	}
+	for i, zone := range config.Zones {
+		circuit.Zones[i] = ZoneWitness{
+			Keys:     newRRSetWitness(config.MaxRRSetLength, signatureLength(zone.Algorithm, zone.KSKBits)),
+			KSK:      vars.NewBytes(keyLength(zone.Algorithm, zone.KSKBits)),
+			KSKIndex: vars.NewVariable(),
+			ZSK:      vars.NewBytes(keyLength(zone.Algorithm, zone.ZSKBits)),
+			ZSKIndex: vars.NewVariable(),
+			DSIndex:  vars.NewVariable(),
+		}
+		if i < len(config.Zones)-1 {
+			circuit.Zones[i].DS = newRRSetWitness(config.MaxRRSetLength, signatureLength(zone.Algorithm, zone.ZSKBits))
+		} else {
+			circuit.Zones[i].DS = newRRSetWitness(0, 0)
+		}
+	}
+	last := config.Zones[len(config.Zones)-1]
+	circuit.TXT = newRRSetWitness(config.MaxRRSetLength, signatureLength(last.Algorithm, last.ZSKBits))
+	return circuit
+}
+
+// Sets the chain of trust that the next call to SetWitness assigns. The records are checked to
+// match the config, but the signatures are only checked by the circuit.
+func (c *Circuit) SetProof(proof *Proof) error {
+	if len(proof.Zones) != len(c.config.Zones) {
+		return fmt.Errorf("expected %d zones, got %d", len(c.config.Zones), len(proof.Zones))
+	}
+	if _, err := c.locate(proof); err != nil {
+		return err
+	}
+	c.proof = proof
+	return nil
+}
+
+// The positions of the records of a proof in their signing inputs.
+type positions struct {
+	ksk, zsk, ds []int
+	txt          int
+}
+
+// Finds the records that link the zones of the proof.
+func (c *Circuit) locate(proof *Proof) (*positions, error) {
+	p := &positions{
+		ksk: make([]int, len(c.config.Zones)),
+		zsk: make([]int, len(c.config.Zones)),
+		ds:  make([]int, len(c.config.Zones)),
+	}
+	for i, zone := range c.config.Zones {
+		z := proof.Zones[i]
+		name := encodeName(zone.Name)
+		if len(z.KSK) != keyLength(zone.Algorithm, zone.KSKBits) || len(z.ZSK) != keyLength(zone.Algorithm, zone.ZSKBits) {
+			return nil, fmt.Errorf("zone %s: unexpected key length", zone.Name)
+		}
+		rrsets := []RRSet{z.Keys}
+		if i < len(c.config.Zones)-1 {
+			rrsets = append(rrsets, z.DS)
+		} else {
+			rrsets = append(rrsets, proof.TXT)
+		}
+		for _, rrset := range rrsets {
+			if len(rrset.SigningInput) > c.config.MaxRRSetLength {
+				return nil, fmt.Errorf("zone %s: signing input length %d exceeds %d",
+					zone.Name, len(rrset.SigningInput), c.config.MaxRRSetLength)
+			}
+		}
+
+		var err error
+		if p.ksk[i], err = findRecord(z.Keys.SigningInput, name, typeDNSKEY, equal(z.KSK)); err != nil {
+			return nil, fmt.Errorf("zone %s: ksk: %w", zone.Name, err)
+		}
+		if p.zsk[i], err = findRecord(z.Keys.SigningInput, name, typeDNSKEY, equal(z.ZSK)); err != nil {
+			return nil, fmt.Errorf("zone %s: zsk: %w", zone.Name, err)
+		}
+		if i == len(c.config.Zones)-1 {
+			break
+		}
+
+		child := c.config.Zones[i+1]
+		childName := encodeName(child.Name)
+		digest := gosha256.Sum256(append(append([]byte{}, childName...), proof.Zones[i+1].KSK...))
+		p.ds[i], err = findRecord(z.DS.SigningInput, childName, typeDS, func(rdata []byte) bool {
+			return len(rdata) == dsLength && int(rdata[2]) == child.Algorithm && rdata[3] == digestSHA256 &&
+				bytes.Equal(rdata[4:], digest[:])
+		})
+		if err != nil {
+			return nil, fmt.Errorf("zone %s: ds: %w", zone.Name, err)
+		}
+	}
+
+	var err error
+	p.txt, err = findRecord(proof.TXT.SigningInput, encodeName(c.config.Name), typeTXT, func(rdata []byte) bool {
+		return len(rdata) > 1 && int(rdata[0])+1 == len(rdata) && int(rdata[0]) <= c.config.MaxTXTLength
+	})
+	if err != nil {
+		return nil, fmt.Errorf("txt: %w", err)
+	}
+	return p, nil
+}
+
+func equal(expected []byte) func([]byte) bool {
+	return func(rdata []byte) bool {
+		return bytes.Equal(rdata, expected)
+	}
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+func setRRSet(w *RRSetWitness, rrset RRSet, maxLength int) {
+	data := make([]byte, maxLength)
+	copy(data, rrset.SigningInput)
+	vars.SetBytes(&w.Data, data)
+	w.Length = vars.NewVariableFromInt(len(rrset.SigningInput))
+	vars.SetBytes(&w.Signature, rrset.Signature)
+}
+
+// Sets the witness for the proof given to SetProof at the timestamp in inputBytes.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.proof == nil {
+		panic("proof must be set before the witness")
+	}
+	p, err := c.locate(c.proof)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+
+	for i := range c.config.Zones {
+		z := c.proof.Zones[i]
+		w := &c.Zones[i]
+		setRRSet(&w.Keys, z.Keys, c.config.MaxRRSetLength)
+		vars.SetBytes(&w.KSK, z.KSK)
+		w.KSKIndex = vars.NewVariableFromInt(p.ksk[i])
+		vars.SetBytes(&w.ZSK, z.ZSK)
+		w.ZSKIndex = vars.NewVariableFromInt(p.zsk[i])
+		if i < len(c.config.Zones)-1 {
+			setRRSet(&w.DS, z.DS, c.config.MaxRRSetLength)
+		} else {
+			w.DS.Length = vars.NewVariableFromInt(0)
+		}
+		w.DSIndex = vars.NewVariableFromInt(p.ds[i])
+	}
+	setRRSet(&c.TXT, c.proof.TXT, c.config.MaxRRSetLength)
+	c.TXTIndex = vars.NewVariableFromInt(p.txt)
+
+	// The TXT RDATA is the length of the string followed by the string.
+	nameLength := len(encodeName(c.config.Name))
+	start := p.txt + nameLength + recordHeaderLength
+	length := int(c.proof.TXT.SigningInput[start])
+	output := make([]byte, 8+c.config.MaxTXTLength)
+	binary.BigEndian.PutUint64(output, uint64(length))
+	copy(output[8:], c.proof.TXT.SigningInput[start+1:start+1+length])
+	vars.SetBytes(&c.OutputBytes, output)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	now := builder.NewInputReader(*api, c.InputBytes).ReadUint64()
+
+	// The digest of the DS record that authenticates the KSK of the current zone.
+	var expectedDigest [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		expectedDigest[i] = vars.Byte{Value: vars.NewVariableFromInt(int(c.config.TrustAnchor[i]))}
+	}
+
+	for i, zone := range c.config.Zones {
+		w := c.Zones[i]
+		name := encodeName(zone.Name)
+
+		// The KSK is authenticated by the digest and signs the DNSKEY RRset containing the ZSK.
+		verifyRRSet(*api, w.Keys, typeDNSKEY, name, zone.Algorithm, w.KSK, now.Value)
+		keys := byteslice.NewTable(*api, w.Keys.Data, 0, len(name)+recordHeaderLength+len(w.KSK)+len(w.ZSK))
+		assertKey(*api, w.KSK, flagsKSK, zone.Algorithm)
+		assertKey(*api, w.ZSK, flagsZSK, zone.Algorithm)
+		assertRecord(*api, keys, w.Keys.Length, w.KSKIndex, name, typeDNSKEY, w.KSK)
+		assertRecord(*api, keys, w.Keys.Length, w.ZSKIndex, name, typeDNSKEY, w.ZSK)
+		digest := sha256.Hash(*api, append(vars.NewBytesFrom(name), w.KSK...))
+		api.AssertIsEqualBytes32(digest, expectedDigest)
+		if i == len(c.config.Zones)-1 {
+			break
+		}
+
+		// The ZSK signs the DS record of the next zone, which holds the digest of its KSK.
+		child := c.config.Zones[i+1]
+		childName := encodeName(child.Name)
+		verifyRRSet(*api, w.DS, typeDS, name, zone.Algorithm, w.ZSK, now.Value)
+		ds := byteslice.NewTable(*api, w.DS.Data, 0, len(childName)+recordHeaderLength+dsLength)
+		rdata := ds.Read(api.Add(w.DSIndex, vars.NewVariableFromInt(len(childName)+recordHeaderLength)), dsLength)
+		assertRecord(*api, ds, w.DS.Length, w.DSIndex, childName, typeDS, rdata)
+		api.FrontendAPI().AssertIsEqual(rdata[2].Value.Value, child.Algorithm)
+		api.FrontendAPI().AssertIsEqual(rdata[3].Value.Value, digestSHA256)
+		copy(expectedDigest[:], rdata[4:])
+	}
+
+	// The ZSK of the last zone signs the TXT RRset.
+	last := c.Zones[len(c.Zones)-1]
+	lastZone := c.config.Zones[len(c.config.Zones)-1]
+	txtName := encodeName(c.config.Name)
+	verifyRRSet(*api, c.TXT, typeTXT, encodeName(lastZone.Name), lastZone.Algorithm, last.ZSK, now.Value)
+	txt := byteslice.NewTable(*api, c.TXT.Data, 0, len(txtName)+recordHeaderLength+1+c.config.MaxTXTLength)
+	value, length := readTXT(*api, txt, c.TXT.Length, c.TXTIndex, txtName, c.config.MaxTXTLength)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteU64(vars.U64{Value: length})
+	for i := 0; i < len(value); i += 32 {
+		var chunk [32]vars.Byte
+		copy(chunk[:], value[i:i+32])
+		outputWriter.WriteBytes32(chunk)
+	}
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Verifies the RRSIG signature of an RRset of the type signed by the zone with the key, and checks
+// that the signature is valid at the time.
+func verifyRRSet(
+	api builder.API,
+	rrset RRSetWitness,
+	rrType int,
+	signer []byte,
+	algorithm int,
+	key []vars.Byte,
+	now vars.Variable,
+) {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(rrset.Data); i++ {
+		rc.Check(rrset.Data[i].Value.Value, 8)
+	}
+	for i := 0; i < len(rrset.Signature); i++ {
+		rc.Check(rrset.Signature[i].Value.Value, 8)
+	}
+
+	// The RRSIG RDATA is at the start of the signing input.
+	if len(rrset.Data) < rrsigHeaderLength+len(signer) {
+		panic("max rrset length is too short for the rrsig rdata")
+	}
+	fapi.AssertIsEqual(rrset.Data[0].Value.Value, rrType>>8)
+	fapi.AssertIsEqual(rrset.Data[1].Value.Value, rrType&0xff)
+	fapi.AssertIsEqual(rrset.Data[2].Value.Value, algorithm)
+	for i := 0; i < len(signer); i++ {
+		fapi.AssertIsEqual(rrset.Data[rrsigHeaderLength+i].Value.Value, int(signer[i]))
+	}
+	api.AssertIsLessOrEqual(vars.NewVariableFromInt(rrsigHeaderLength+len(signer)), rrset.Length)
+	api.AssertIsLessOrEqual(readUint32(api, rrset.Data[inceptionOffset:]), now)
+	api.AssertIsLessOrEqual(now, readUint32(api, rrset.Data[expirationOffset:]))
+
+	digest := sha256.HashVariable(api, rrset.Data, vars.U64{Value: rrset.Length}, len(rrset.Data))
+	switch algorithm {
+	case AlgorithmRSASHA256:
+		exponent := []int{3, 0x01, 0x00, 0x01}
+		for i := 0; i < len(exponent); i++ {
+			fapi.AssertIsEqual(key[4+i].Value.Value, exponent[i])
+		}
+		rsa.AssertValidPKCS1v15(api, key[8:], rrset.Signature, digest)
+	case AlgorithmECDSAP256SHA256:
+		var r, s, x, y [32]vars.Byte
+		copy(r[:], rrset.Signature[:32])
+		copy(s[:], rrset.Signature[32:])
+		copy(x[:], key[4:36])
+		copy(y[:], key[36:68])
+		compat.AssertValidP256ECDSA(api, digest, r, s, x, y)
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %d", algorithm))
+	}
+}
+
+// Asserts that the DNSKEY RDATA has the flags, protocol, and algorithm.
+func assertKey(api builder.API, key []vars.Byte, flags int, algorithm int) {
+	fapi := api.FrontendAPI()
+	fapi.AssertIsEqual(key[0].Value.Value, flags>>8)
+	fapi.AssertIsEqual(key[1].Value.Value, flags&0xff)
+	fapi.AssertIsEqual(key[2].Value.Value, protocolDNSSEC)
+	fapi.AssertIsEqual(key[3].Value.Value, algorithm)
+}
+
+// Asserts that the record at index of the signing input has the owner name, type, class IN, and
+// RDATA, and that it is within the length of the signing input.
+func assertRecord(
+	api builder.API,
+	t *byteslice.Table,
+	length vars.Variable,
+	index vars.Variable,
+	name []byte,
+	rrType int,
+	rdata []vars.Byte,
+) {
+	header := make([]byte, 0, len(name)+4)
+	header = append(header, name...)
+	header = append(header, byte(rrType>>8), byte(rrType), 0, classIN)
+	t.AssertLiteralAt(index, header)
+	rdLength := []byte{byte(len(rdata) >> 8), byte(len(rdata))}
+	t.AssertLiteralAt(api.Add(index, vars.NewVariableFromInt(len(name)+8)), rdLength)
+	t.AssertEqualAt(api.Add(index, vars.NewVariableFromInt(len(name)+recordHeaderLength)), rdata)
+	end := api.Add(index, vars.NewVariableFromInt(len(name)+recordHeaderLength+len(rdata)))
+	api.AssertIsLessOrEqual(end, length)
+}
+
+// Reads the first string of the TXT record at index of the signing input, which must be the only
+// string of the record. Returns the string padded with zeros to maxLength bytes and its length.
+func readTXT(
+	api builder.API,
+	t *byteslice.Table,
+	length vars.Variable,
+	index vars.Variable,
+	name []byte,
+	maxLength int,
+) ([]vars.Byte, vars.Variable) {
+	fapi := api.FrontendAPI()
+	header := make([]byte, 0, len(name)+4)
+	header = append(header, name...)
+	header = append(header, 0, typeTXT, 0, classIN)
+	t.AssertLiteralAt(index, header)
+
+	// The RDATA length is one more than the string length, which is at most maxLength < 255.
+	rdLength := t.Read(api.Add(index, vars.NewVariableFromInt(len(name)+8)), 2)
+	start := api.Add(index, vars.NewVariableFromInt(len(name)+recordHeaderLength))
+	stringLength := t.At(start, 0).Value
+	fapi.AssertIsEqual(rdLength[0].Value.Value, 0)
+	fapi.AssertIsEqual(rdLength[1].Value.Value, fapi.Add(stringLength.Value, 1))
+	api.AssertIsLessOrEqual(stringLength, vars.NewVariableFromInt(maxLength))
+	api.AssertIsLessOrEqual(api.Add(api.Add(start, stringLength), vars.ONE), length)
+
+	value := make([]vars.Byte, maxLength)
+	inRange := frontend.Variable(1)
+	for k := 0; k < maxLength; k++ {
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(stringLength.Value, k)))
+		value[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, t.At(start, 1+k).Value.Value)}}
+	}
+	return value, stringLength
+}
+
+// Reads a big-endian uint32.
+func readUint32(api builder.API, in []vars.Byte) vars.Variable {
+	result := frontend.Variable(0)
+	for i := 0; i < 4; i++ {
+		result = api.FrontendAPI().Add(api.FrontendAPI().Mul(result, 256), in[i].Value.Value)
+	}
+	return vars.Variable{Value: result}
+}
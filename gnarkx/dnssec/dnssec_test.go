@@ -0,0 +1,150 @@
+package dnssec
+
+import (
+	"crypto"
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	gosha256 "crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+)
+
+const (
+	inception  = 1690000000
+	expiration = 1710000000
+)
+
+func rsaKey(t *testing.T, flags uint16) (*gorsa.PrivateKey, []byte) {
+	key, err := gorsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rdata := binary.BigEndian.AppendUint16(nil, flags)
+	rdata = append(rdata, protocolDNSSEC, AlgorithmRSASHA256, 3, 0x01, 0x00, 0x01)
+	return key, append(rdata, key.N.FillBytes(make([]byte, 256))...)
+}
+
+func ecdsaKey(t *testing.T, flags uint16) (*goecdsa.PrivateKey, []byte) {
+	key, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	rdata := binary.BigEndian.AppendUint16(nil, flags)
+	rdata = append(rdata, protocolDNSSEC, AlgorithmECDSAP256SHA256)
+	rdata = append(rdata, key.X.FillBytes(make([]byte, 32))...)
+	return key, append(rdata, key.Y.FillBytes(make([]byte, 32))...)
+}
+
+func resourceRecord(name string, rrType uint16, rdata []byte) []byte {
+	rr := encodeName(name)
+	rr = binary.BigEndian.AppendUint16(rr, rrType)
+	rr = binary.BigEndian.AppendUint16(rr, classIN)
+	rr = binary.BigEndian.AppendUint32(rr, 3600)
+	rr = binary.BigEndian.AppendUint16(rr, uint16(len(rdata)))
+	return append(rr, rdata...)
+}
+
+func sign(t *testing.T, key crypto.Signer, algorithm byte, rrType uint16, signer string, rrs ...[]byte) RRSet {
+	data := binary.BigEndian.AppendUint16(nil, rrType)
+	data = append(data, algorithm, 1)
+	data = binary.BigEndian.AppendUint32(data, 3600)
+	data = binary.BigEndian.AppendUint32(data, expiration)
+	data = binary.BigEndian.AppendUint32(data, inception)
+	data = binary.BigEndian.AppendUint16(data, 12345)
+	data = append(data, encodeName(signer)...)
+	for _, rr := range rrs {
+		data = append(data, rr...)
+	}
+
+	digest := gosha256.Sum256(data)
+	var signature []byte
+	switch k := key.(type) {
+	case *gorsa.PrivateKey:
+		var err error
+		signature, err = gorsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case *goecdsa.PrivateKey:
+		r, s, err := goecdsa.Sign(rand.Reader, k, digest[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return RRSet{SigningInput: data, Signature: signature}
+}
+
+func TestDNSSEC(t *testing.T) {
+	rootKSK, rootKSKData := rsaKey(t, flagsKSK)
+	rootZSK, rootZSKData := rsaKey(t, flagsZSK)
+	zoneKSK, zoneKSKData := ecdsaKey(t, flagsKSK)
+	zoneZSK, zoneZSKData := ecdsaKey(t, flagsZSK)
+
+	zoneDigest := gosha256.Sum256(append(encodeName("example."), zoneKSKData...))
+	ds := append([]byte{0x30, 0x39, AlgorithmECDSAP256SHA256, digestSHA256}, zoneDigest[:]...)
+	txt := append([]byte{15}, "did:example:123"...)
+	proof := &Proof{
+		Zones: []ZoneProof{
+			{
+				Keys: sign(t, rootKSK, AlgorithmRSASHA256, typeDNSKEY, ".",
+					resourceRecord(".", typeDNSKEY, rootZSKData), resourceRecord(".", typeDNSKEY, rootKSKData)),
+				KSK: rootKSKData,
+				ZSK: rootZSKData,
+				DS:  sign(t, rootZSK, AlgorithmRSASHA256, typeDS, ".", resourceRecord("example.", typeDS, ds)),
+			},
+			{
+				Keys: sign(t, zoneKSK, AlgorithmECDSAP256SHA256, typeDNSKEY, "example.",
+					resourceRecord("example.", typeDNSKEY, zoneKSKData), resourceRecord("example.", typeDNSKEY, zoneZSKData)),
+				KSK: zoneKSKData,
+				ZSK: zoneZSKData,
+			},
+		},
+		TXT: sign(t, zoneZSK, AlgorithmECDSAP256SHA256, typeTXT, "example.", resourceRecord("_id.example.", typeTXT, txt)),
+	}
+
+	config := &Config{
+		TrustAnchor: gosha256.Sum256(append(encodeName("."), rootKSKData...)),
+		Zones: []Zone{
+			{Name: ".", Algorithm: AlgorithmRSASHA256, KSKBits: 2048, ZSKBits: 2048},
+			{Name: "example.", Algorithm: AlgorithmECDSAP256SHA256},
+		},
+		Name:           "_id.example.",
+		MaxRRSetLength: 576,
+		MaxTXTLength:   32,
+	}
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetProof(proof))
+	function := succinct.NewCircuitFunction(circuit)
+
+	now := binary.BigEndian.AppendUint64(nil, 1700000000)
+	function.SetWitness(now)
+	expected := binary.BigEndian.AppendUint64(nil, 15)
+	expected = append(expected, "did:example:123"...)
+	expected = append(expected, make([]byte, 17)...)
+	output := make([]byte, len(circuit.OutputBytes))
+	for i := range circuit.OutputBytes {
+		output[i] = circuit.OutputBytes[i].GetValueUnsafe()
+	}
+	assert.Equal(t, expected, output)
+
+	err := test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// Signatures are not valid after their expiration.
+	function.SetWitness(binary.BigEndian.AppendUint64(nil, expiration+1))
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// A chain whose root KSK does not match the trust anchor is rejected.
+	config.TrustAnchor[0] ^= 1
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetProof(proof))
+	function = succinct.NewCircuitFunction(circuit)
+	function.SetWitness(now)
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The DS record must authenticate the KSK of the next zone.
+	proof.Zones[1].KSK = zoneZSKData
+	assert.Error(t, circuit.SetProof(proof))
+}
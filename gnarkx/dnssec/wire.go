@@ -0,0 +1,155 @@
+package dnssec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// The DNSSEC algorithm numbers of RFC 8624 supported by the circuit.
+const (
+	AlgorithmRSASHA256       = 8
+	AlgorithmECDSAP256SHA256 = 13
+)
+
+const (
+	typeTXT    = 16
+	typeDS     = 43
+	typeDNSKEY = 48
+	classIN    = 1
+
+	// The DS digest type of SHA-256.
+	digestSHA256 = 2
+
+	// The flags of a zone signing key and of a key signing key, which also has the SEP bit set.
+	flagsZSK = 0x0100
+	flagsKSK = 0x0101
+
+	// The protocol field of every DNSKEY.
+	protocolDNSSEC = 3
+
+	// The length of the fixed fields of the RRSIG RDATA that precede the signer's name, and the
+	// offsets of the signature expiration and inception times.
+	rrsigHeaderLength = 18
+	expirationOffset  = 8
+	inceptionOffset   = 12
+
+	// The length of the fixed fields of a resource record that follow its owner name.
+	recordHeaderLength = 10
+
+	// The length of the RDATA of a DS record with a SHA-256 digest.
+	dsLength = 36
+)
+
+// Encodes a domain name in canonical wire format, i.e. as lowercase length-prefixed labels ending
+// with the empty root label.
+func encodeName(name string) []byte {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	var result []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 {
+				panic(fmt.Sprintf("invalid label in %q", name))
+			}
+			result = append(result, byte(len(label)))
+			result = append(result, label...)
+		}
+	}
+	return append(result, 0)
+}
+
+// Returns the length of the public key RDATA of a DNSKEY. For RSA keys this is the flags,
+// protocol, and algorithm, followed by the 3 byte exponent 65537 and the modulus.
+func keyLength(algorithm int, bits int) int {
+	switch algorithm {
+	case AlgorithmRSASHA256:
+		return 4 + 4 + bits/8
+	case AlgorithmECDSAP256SHA256:
+		return 4 + 64
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %d", algorithm))
+	}
+}
+
+// Returns the length of a signature made with a key of the algorithm.
+func signatureLength(algorithm int, bits int) int {
+	switch algorithm {
+	case AlgorithmRSASHA256:
+		return bits / 8
+	case AlgorithmECDSAP256SHA256:
+		return 64
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %d", algorithm))
+	}
+}
+
+// A resource record of a signing input, located at index.
+type record struct {
+	index  int
+	name   []byte
+	rrType uint16
+	rdata  []byte
+}
+
+// Parses the records of an RRSIG signing input, which is the RRSIG RDATA without the signature
+// followed by the records of the RRset in canonical form.
+func parseSigningInput(data []byte) (signer []byte, records []record, err error) {
+	if len(data) < rrsigHeaderLength {
+		return nil, nil, fmt.Errorf("signing input too short")
+	}
+	signerLength, err := nameLength(data[rrsigHeaderLength:])
+	if err != nil {
+		return nil, nil, err
+	}
+	signer = data[rrsigHeaderLength : rrsigHeaderLength+signerLength]
+	for i := rrsigHeaderLength + signerLength; i < len(data); {
+		n, err := nameLength(data[i:])
+		if err != nil {
+			return nil, nil, err
+		}
+		if i+n+recordHeaderLength > len(data) {
+			return nil, nil, fmt.Errorf("record at %d is truncated", i)
+		}
+		header := data[i+n : i+n+recordHeaderLength]
+		rdLength := int(binary.BigEndian.Uint16(header[8:10]))
+		if i+n+recordHeaderLength+rdLength > len(data) {
+			return nil, nil, fmt.Errorf("record at %d is truncated", i)
+		}
+		records = append(records, record{
+			index:  i,
+			name:   data[i : i+n],
+			rrType: binary.BigEndian.Uint16(header[0:2]),
+			rdata:  data[i+n+recordHeaderLength : i+n+recordHeaderLength+rdLength],
+		})
+		i += n + recordHeaderLength + rdLength
+	}
+	return signer, records, nil
+}
+
+// Returns the length of the uncompressed wire format name at the start of data.
+func nameLength(data []byte) (int, error) {
+	for i := 0; i < len(data); i += int(data[i]) + 1 {
+		if data[i] == 0 {
+			return i + 1, nil
+		}
+		if data[i] > 63 {
+			return 0, fmt.Errorf("invalid label length %d", data[i])
+		}
+	}
+	return 0, fmt.Errorf("name is not terminated")
+}
+
+// Finds the index of the record with the owner name and type for which match returns true.
+func findRecord(data []byte, name []byte, rrType uint16, match func(rdata []byte) bool) (int, error) {
+	_, records, err := parseSigningInput(data)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		if bytes.Equal(r.name, name) && r.rrType == rrType && match(r.rdata) {
+			return r.index, nil
+		}
+	}
+	return 0, fmt.Errorf("no matching record of type %d", rrType)
+}
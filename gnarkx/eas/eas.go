@@ -0,0 +1,271 @@
+// Circuit templates verifying an Ethereum Attestation Service attestation and exposing selected
+// fields of its data, for reputation and identity apps that consume attestations on other chains
+// or without revealing the whole attestation.
+//
+// An attestation is either signed off-chain by its attester with EIP-712, see OffchainCircuit, or
+// stored on-chain in the EAS contract and proven against a block hash, see OnchainCircuit. Both
+// circuits check that the attestation has the schema of the config and has not expired, decode its
+// schema-encoded data, and output the attester and the recipient as left padded bytes32 followed
+// by the ABI words of the revealed fields, in the order of the config. The Conditions of the config
+// compare unsigned fields with thresholds of the input without revealing them, such as a score
+// above a minimum.
+//
+// The schema must only have static fields (uintN, intN, bool, address and bytesN), so that the
+// data of all attestations has the same length. Reference: https://docs.attest.org
+package eas
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/credential"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Config describes the attestations a circuit accepts.
+type Config struct {
+	// The schema of the attestations, such as "uint256 score,bool verified", and the resolver and
+	// revocability it was registered with, which determine its UID.
+	Schema    string
+	Resolver  common.Address
+	Revocable bool
+
+	// The names of the fields of the schema that the circuit outputs.
+	Reveal []string
+
+	// The comparisons of unsigned fields of the schema with thresholds, which follow the input of
+	// the circuit as ABI words in the order of the config.
+	Conditions []Condition
+
+	// The EAS contract, which is the verifying contract of off-chain attestations.
+	Contract common.Address
+
+	// The chain of the contract, the version of the contract in the EIP-712 domain, such as
+	// "1.0.1", and the version of the off-chain attestation type, 1 or 2. Only used off-chain.
+	ChainID         *big.Int
+	ContractVersion string
+	OffchainVersion int
+
+	// The storage slot of the mapping from UIDs to attestations of the contract, which depends on
+	// the version of the contract, and the shape of the proofs. Only used on-chain.
+	DBSlot          int
+	MaxHeaderLength int
+	MaxDepth        int
+	MaxNodeLength   int
+}
+
+// A Condition compares the value of a field with a threshold.
+type Condition struct {
+	Field      string
+	Comparison credential.Comparison
+}
+
+// The kind of an ABI type of a field of a schema.
+type kind int
+
+const (
+	kindUint kind = iota
+	kindInt
+	kindBool
+	kindAddress
+	kindBytes
+)
+
+// A static field of a schema, where size is the number of significant bytes of its ABI word.
+type field struct {
+	name string
+	kind kind
+	size int
+}
+
+// Parses a schema of static fields.
+func parseSchema(schema string) ([]field, error) {
+	var fields []field
+	for _, definition := range strings.Split(schema, ",") {
+		parts := strings.Fields(definition)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid field %q", definition)
+		}
+		f := field{name: parts[1]}
+		typ := parts[0]
+		switch {
+		case typ == "bool":
+			f.kind, f.size = kindBool, 1
+		case typ == "address":
+			f.kind, f.size = kindAddress, 20
+		case strings.HasPrefix(typ, "uint"):
+			f.kind, f.size = kindUint, bitSize(typ[4:])
+		case strings.HasPrefix(typ, "int"):
+			f.kind, f.size = kindInt, bitSize(typ[3:])
+		case strings.HasPrefix(typ, "bytes") && typ != "bytes":
+			f.kind = kindBytes
+			f.size, _ = strconv.Atoi(typ[5:])
+			if f.size < 1 || f.size > 32 {
+				f.size = 0
+			}
+		}
+		if f.size == 0 {
+			return nil, fmt.Errorf("unsupported type %q of field %s", typ, f.name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// Returns the size in bytes of an integer type with the given bit size suffix, or 0 if invalid.
+func bitSize(suffix string) int {
+	if suffix == "" {
+		return 32
+	}
+	bits, err := strconv.Atoi(suffix)
+	if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+		return 0
+	}
+	return bits / 8
+}
+
+// Returns the fields of the schema and the indices of the revealed fields.
+func (c *Config) fields() ([]field, []int) {
+	fields, err := parseSchema(c.Schema)
+	if err != nil {
+		panic(err)
+	}
+	var reveal []int
+	for _, name := range c.Reveal {
+		reveal = append(reveal, fieldIndex(fields, name))
+	}
+	return fields, reveal
+}
+
+// Returns the indices of the fields of the conditions, which must be unsigned integers.
+func (c *Config) conditions() []int {
+	fields, _ := c.fields()
+	var indices []int
+	for _, condition := range c.Conditions {
+		index := fieldIndex(fields, condition.Field)
+		if fields[index].kind != kindUint {
+			panic(fmt.Sprintf("field %s of a condition is not an unsigned integer", condition.Field))
+		}
+		indices = append(indices, index)
+	}
+	return indices
+}
+
+// Returns the length of the thresholds of the conditions in the input.
+func (c *Config) thresholdsLength() int {
+	return 32 * len(c.conditions())
+}
+
+func fieldIndex(fields []field, name string) int {
+	for i := 0; i < len(fields); i++ {
+		if fields[i].name == name {
+			return i
+		}
+	}
+	panic(fmt.Sprintf("field %s is not in the schema", name))
+}
+
+// Returns the length of the data of attestations.
+func (c *Config) dataLength() int {
+	fields, _ := c.fields()
+	return 32 * len(fields)
+}
+
+// Returns the UID of the schema, keccak256(abi.encodePacked(schema, resolver, revocable)).
+func (c *Config) SchemaUID() common.Hash {
+	revocable := byte(0)
+	if c.Revocable {
+		revocable = 1
+	}
+	return crypto.Keccak256Hash([]byte(c.Schema), c.Resolver.Bytes(), []byte{revocable})
+}
+
+// Returns the outputs of an attestation.
+func (c *Config) outputs(attester common.Address, recipient common.Address, data []byte) []byte {
+	_, reveal := c.fields()
+	outputs := append(common.LeftPadBytes(attester.Bytes(), 32), common.LeftPadBytes(recipient.Bytes(), 32)...)
+	for _, index := range reveal {
+		outputs = append(outputs, data[index*32:(index+1)*32]...)
+	}
+	return outputs
+}
+
+// Checks that the data of an attestation has the length of the schema.
+func (c *Config) checkData(data []byte) error {
+	if len(data) != c.dataLength() {
+		return fmt.Errorf("data length %d, expected %d", len(data), c.dataLength())
+	}
+	return nil
+}
+
+// Asserts that the ABI words of the data are canonical encodings of the fields of the schema that
+// satisfy the conditions with the thresholds, and writes the attester, the recipient and the
+// revealed words to the output.
+func writeOutputs(
+	api builder.API,
+	config *Config,
+	attester [20]vars.Byte,
+	recipient [20]vars.Byte,
+	data []vars.Byte,
+	thresholds []vars.Byte,
+	outputBytes []vars.Byte,
+) {
+	fapi := api.FrontendAPI()
+	fields, reveal := config.fields()
+	words := make([][32]vars.Byte, len(fields))
+	for i, f := range fields {
+		copy(words[i][:], data[i*32:(i+1)*32])
+		word := words[i]
+		switch f.kind {
+		case kindUint, kindBool, kindAddress:
+			for j := 0; j < 32-f.size; j++ {
+				fapi.AssertIsEqual(word[j].Value.Value, 0)
+			}
+			if f.kind == kindBool {
+				fapi.AssertIsBoolean(word[31].Value.Value)
+			}
+		case kindInt:
+			// The padding is the sign extension of the most significant byte.
+			bits := api.ToBitsFromByte(word[32-f.size])
+			padding := fapi.Mul(bits[7].Value.Value, 0xff)
+			for j := 0; j < 32-f.size; j++ {
+				fapi.AssertIsEqual(word[j].Value.Value, padding)
+			}
+		case kindBytes:
+			for j := f.size; j < 32; j++ {
+				fapi.AssertIsEqual(word[j].Value.Value, 0)
+			}
+		}
+	}
+
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(thresholds); i++ {
+		rc.Check(thresholds[i].Value.Value, 8)
+	}
+	for i, index := range config.conditions() {
+		config.Conditions[i].Comparison.AssertBytes(api, words[index][:], thresholds[32*i:32*(i+1)])
+	}
+
+	outputWriter := builder.NewOutputWriter(api)
+	outputWriter.WriteBytes32(storage.AddressWord(attester))
+	outputWriter.WriteBytes32(storage.AddressWord(recipient))
+	for _, index := range reveal {
+		outputWriter.WriteBytes32(words[index])
+	}
+	outputWriter.Close(outputBytes)
+}
+
+// Asserts that an attestation with the expiration time has not expired at now, where an
+// expiration time of zero never expires.
+func assertNotExpired(api builder.API, expirationTime vars.Variable, now vars.Variable) {
+	next := api.Add(now, vars.NewVariableFromInt(1))
+	bound := api.Select(api.IsZero(expirationTime), next, expirationTime)
+	api.AssertIsLessOrEqual(next, bound)
+}
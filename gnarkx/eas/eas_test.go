@@ -0,0 +1,253 @@
+package eas
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/credential"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	recipient = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	wallet    = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func newConfig() *Config {
+	return &Config{
+		Schema:          "uint256 score,bool verified,address wallet,int16 delta",
+		Revocable:       true,
+		Reveal:          []string{"score", "wallet"},
+		Contract:        common.HexToAddress("0xa1207f3bba224e2c9c3c6d5af63d0eb1582ce587"),
+		ChainID:         big.NewInt(1),
+		ContractVersion: "0.26",
+		OffchainVersion: 1,
+		DBSlot:          2,
+		MaxHeaderLength: eth.MaxHeaderLength,
+		MaxDepth:        4,
+		MaxNodeLength:   eth.MaxNodeLength,
+	}
+}
+
+// Returns the ABI encoding of a score of 750, verified, the wallet and a delta of -5.
+func newData(verified byte) []byte {
+	data := make([]byte, 128)
+	big.NewInt(750).FillBytes(data[:32])
+	data[63] = verified
+	copy(data[76:96], wallet.Bytes())
+	for i := 96; i < 128; i++ {
+		data[i] = 0xff
+	}
+	data[127] = 0xfb
+	return data
+}
+
+func assertOutputs(t *testing.T, circuit succinct.Circuit, attester common.Address) {
+	expected := common.LeftPadBytes(attester.Bytes(), 32)
+	expected = append(expected, common.LeftPadBytes(recipient.Bytes(), 32)...)
+	expected = append(expected, common.LeftPadBytes(big.NewInt(750).Bytes(), 32)...)
+	expected = append(expected, common.LeftPadBytes(wallet.Bytes(), 32)...)
+	outputs := *circuit.GetOutputBytes()
+	for i := 0; i < len(expected); i++ {
+		assert.Equal(t, expected[i], outputs[i].GetValueUnsafe())
+	}
+}
+
+func TestOffchain(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	attester := crypto.PubkeyToAddress(key.PublicKey)
+	config := newConfig()
+	sign := func(a *OffchainAttestation) {
+		digest := config.Digest(a)
+		signature, err := crypto.Sign(digest[:], key)
+		assert.NoError(t, err)
+		signature[64] += 27
+		a.Signature = signature
+	}
+	attestation := &OffchainAttestation{
+		Recipient:      recipient,
+		Time:           1700000000,
+		ExpirationTime: 1800000000,
+		Revocable:      true,
+		RefUID:         common.HexToHash("0x01"),
+		Data:           newData(1),
+	}
+	sign(attestation)
+	now := binary.BigEndian.AppendUint64(nil, 1750000000)
+
+	circuit := NewOffchainCircuit(config)
+	assert.NoError(t, circuit.SetAttestation(attestation))
+	assert.NoError(t, functiontest.IsSolved(circuit, now))
+	assertOutputs(t, circuit, attester)
+}
+
+// The circuit checks the data of an attestation and the outputs written for it, as writeOutputs.
+type dataCircuit struct {
+	Attester    [20]vars.Byte
+	Recipient   [20]vars.Byte
+	Data        []vars.Byte
+	Thresholds  []vars.Byte
+	OutputBytes []vars.Byte
+
+	config *Config `gnark:"-"`
+}
+
+func (c *dataCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	writeOutputs(*api, c.config, c.Attester, c.Recipient, c.Data, c.Thresholds, c.OutputBytes)
+	return nil
+}
+
+func newDataCircuit(config *Config) *dataCircuit {
+	_, reveal := config.fields()
+	return &dataCircuit{
+		Data:        vars.NewBytes(config.dataLength()),
+		Thresholds:  vars.NewBytes(config.thresholdsLength()),
+		OutputBytes: vars.NewBytes(32 * (2 + len(reveal))),
+		config:      config,
+	}
+}
+
+func isDataSolved(config *Config, data []byte, thresholds []byte) error {
+	attester := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	assignment := newDataCircuit(config)
+	copy(assignment.Attester[:], vars.NewBytesFrom(attester.Bytes()))
+	copy(assignment.Recipient[:], vars.NewBytesFrom(recipient.Bytes()))
+	vars.SetBytes(&assignment.Data, data)
+	vars.SetBytes(&assignment.Thresholds, thresholds)
+	vars.SetBytes(&assignment.OutputBytes, config.outputs(attester, recipient, data))
+	return test.IsSolved(newDataCircuit(config), assignment, ecc.BN254.ScalarField())
+}
+
+func TestData(t *testing.T) {
+	config := newConfig()
+	assert.NoError(t, isDataSolved(config, newData(1), nil))
+
+	// The data must be a canonical encoding of the schema.
+	assert.Error(t, isDataSolved(config, newData(2), nil))
+}
+
+func TestConditions(t *testing.T) {
+	config := newConfig()
+	config.Conditions = []Condition{{Field: "score", Comparison: credential.GreaterOrEqual}}
+	threshold := func(threshold int64) []byte {
+		return big.NewInt(threshold).FillBytes(make([]byte, 32))
+	}
+	assert.NoError(t, isDataSolved(config, newData(1), threshold(750)))
+
+	// The score of 750 is less than the threshold.
+	assert.Error(t, isDataSolved(config, newData(1), threshold(751)))
+
+	// Only unsigned fields can be compared.
+	config.Conditions = []Condition{{Field: "delta", Comparison: credential.LessThan}}
+	assert.Panics(t, func() { NewOffchainCircuit(config) })
+}
+
+// The circuit checks that an attestation with the times is active at Now, as assertActive.
+type timesCircuit struct {
+	Times [32]vars.Byte
+	Now   vars.Variable
+}
+
+func (c *timesCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	assertActive(*api, c.Times, c.Now)
+	return nil
+}
+
+func TestActive(t *testing.T) {
+	isActive := func(revocationTime uint64, expirationTime uint64, now int) error {
+		var times [32]byte
+		binary.BigEndian.PutUint64(times[8:16], revocationTime)
+		binary.BigEndian.PutUint64(times[16:24], expirationTime)
+		binary.BigEndian.PutUint64(times[24:32], 1700000000)
+		assignment := &timesCircuit{Now: vars.NewVariableFromInt(now)}
+		copy(assignment.Times[:], vars.NewBytesFrom(times[:]))
+		return test.IsSolved(&timesCircuit{}, assignment, ecc.BN254.ScalarField())
+	}
+	assert.NoError(t, isActive(0, 1800000000, 1750000000))
+	assert.NoError(t, isActive(0, 0, 1750000000))
+
+	// The attestation must not be expired or revoked.
+	assert.Error(t, isActive(0, 1800000000, 1800000000))
+	assert.Error(t, isActive(1740000000, 1800000000, 1750000000))
+}
+
+func TestOnchain(t *testing.T) {
+	// A schema of two fields and small tries keep the number and the size of the proofs down.
+	config := newConfig()
+	config.Schema = "uint256 score,address wallet"
+	config.MaxDepth = 3
+	config.MaxNodeLength = 300
+	data := append(newData(1)[:32], newData(1)[64:96]...)
+	uid := common.HexToHash("0xfeed")
+	attester := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	// The words of the attestation in the order of the slots.
+	times := new(big.Int).Lsh(big.NewInt(1800000000), 64)
+	times.Add(times, big.NewInt(1700000000))
+	words := []*big.Int{
+		uid.Big(),
+		config.SchemaUID().Big(),
+		times,
+		recipient.Big(),
+		new(big.Int).Add(attester.Big(), new(big.Int).Lsh(big.NewInt(1), 160)),
+		big.NewInt(2*64 + 1),
+	}
+	for i := 0; i < len(data); i += 32 {
+		words = append(words, new(big.Int).SetBytes(data[i:i+32]))
+	}
+
+	slots := config.Slots(uid)
+	values := make([][]byte, len(slots))
+	storage := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := 0; i < len(slots); i++ {
+		value, err := rlp.EncodeToBytes(words[i])
+		assert.NoError(t, err)
+		storage.MustUpdate(crypto.Keccak256(slots[i][:]), value)
+		values[i] = value
+	}
+	accounts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	account, err := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(0), storage.Hash(), types.EmptyCodeHash})
+	assert.NoError(t, err)
+	accounts.MustUpdate(crypto.Keccak256(config.Contract.Bytes()), account)
+
+	header := eth.NewHeader(config.MaxHeaderLength)
+	assert.NoError(t, header.Set(&types.Header{
+		Root:       accounts.Hash(),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(18000000),
+		GasLimit:   30000000,
+		Time:       1750000000,
+		BaseFee:    big.NewInt(20e9),
+	}))
+	accountProof := functiontest.Prove(t, accounts, config.Contract.Bytes(), account, config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength)
+	storageProofs := make([]*eth.MPTProof, len(slots))
+	for i := 0; i < len(slots); i++ {
+		storageProofs[i] = functiontest.Prove(t, storage, slots[i][:], values[i], config.MaxDepth, config.MaxNodeLength, eth.MaxStorageValueLength)
+	}
+	circuit := NewOnchainCircuit(config)
+	assert.NoError(t, circuit.SetProofs(&header, accountProof, uid, storageProofs))
+	blockHash := make([]byte, 32)
+	for i := 0; i < 32; i++ {
+		blockHash[i] = circuit.header.Hash[i].GetValueUnsafe()
+	}
+	assert.NoError(t, functiontest.IsSolved(circuit, blockHash))
+	assertOutputs(t, circuit, attester)
+}
@@ -0,0 +1,257 @@
+package eas
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The EIP-712 domain of off-chain attestations.
+const (
+	domainType = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+	domainName = "EAS Attestation"
+)
+
+// The EIP-712 types of off-chain attestations by version.
+var offchainTypes = map[int]string{
+	1: "Attest(uint16 version,bytes32 schema,address recipient,uint64 time,uint64 expirationTime," +
+		"bool revocable,bytes32 refUID,bytes data)",
+	2: "Attest(uint16 version,bytes32 schema,address recipient,uint64 time,uint64 expirationTime," +
+		"bool revocable,bytes32 refUID,bytes data,bytes32 salt)",
+}
+
+// An attestation signed off-chain, whose schema and version are those of the config.
+type OffchainAttestation struct {
+	Recipient      common.Address
+	Time           uint64
+	ExpirationTime uint64
+	Revocable      bool
+	RefUID         common.Hash
+	Data           []byte
+
+	// The salt of version 2 attestations.
+	Salt common.Hash
+
+	// The signature r || s || v of the attester.
+	Signature []byte
+}
+
+// Returns the EIP-712 domain separator of off-chain attestations.
+func (c *Config) domainSeparator() common.Hash {
+	return crypto.Keccak256Hash(
+		crypto.Keccak256([]byte(domainType)),
+		crypto.Keccak256([]byte(domainName)),
+		crypto.Keccak256([]byte(c.ContractVersion)),
+		math.U256Bytes(c.ChainID),
+		common.LeftPadBytes(c.Contract.Bytes(), 32),
+	)
+}
+
+// Returns the EIP-712 digest of an attestation, which is signed by its attester.
+func (c *Config) Digest(a *OffchainAttestation) common.Hash {
+	word := func(v uint64) []byte {
+		return binary.BigEndian.AppendUint64(make([]byte, 24), v)
+	}
+	revocable := uint64(0)
+	if a.Revocable {
+		revocable = 1
+	}
+	schema := c.SchemaUID()
+	encoded := append(crypto.Keccak256([]byte(offchainTypes[c.OffchainVersion])), word(uint64(c.OffchainVersion))...)
+	encoded = append(encoded, schema[:]...)
+	encoded = append(encoded, common.LeftPadBytes(a.Recipient.Bytes(), 32)...)
+	encoded = append(encoded, word(a.Time)...)
+	encoded = append(encoded, word(a.ExpirationTime)...)
+	encoded = append(encoded, word(revocable)...)
+	encoded = append(encoded, a.RefUID[:]...)
+	encoded = append(encoded, crypto.Keccak256(a.Data)...)
+	if c.OffchainVersion == 2 {
+		encoded = append(encoded, a.Salt[:]...)
+	}
+	domain := c.domainSeparator()
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], crypto.Keccak256(encoded))
+}
+
+// OffchainCircuit verifies an attestation signed off-chain. Its input is the uint64 unix timestamp
+// at which the attestation must be valid, followed by the thresholds of the conditions.
+type OffchainCircuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Recipient      [20]vars.Byte
+	Time           vars.U64
+	ExpirationTime vars.U64
+	Revocable      vars.Variable
+	RefUID         [32]vars.Byte
+	Data           []vars.Byte
+	Salt           [32]vars.Byte
+
+	// The signature of the digest, with v of 27 or 28.
+	V vars.Variable
+	R [32]vars.Byte
+	S [32]vars.Byte
+
+	config      *Config              `gnark:"-"`
+	attestation *OffchainAttestation `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*OffchainCircuit)(nil)
+
+// Creates a new circuit for off-chain attestations described by the config.
+func NewOffchainCircuit(config *Config) *OffchainCircuit {
+	if _, ok := offchainTypes[config.OffchainVersion]; !ok {
+		panic(fmt.Sprintf("unsupported off-chain attestation version %d", config.OffchainVersion))
+	}
+	_, reveal := config.fields()
+	return &OffchainCircuit{
+		InputBytes:  vars.NewBytes(8 + config.thresholdsLength()),
+		OutputBytes: vars.NewBytes(32 * (2 + len(reveal))),
+		Data:        vars.NewBytes(config.dataLength()),
+		config:      config,
+	}
+}
+
+// Sets the attestation that the next call to SetWitness assigns. The signature is checked to be
+// well formed, but only the circuit checks that the attestation is valid.
+func (c *OffchainCircuit) SetAttestation(attestation *OffchainAttestation) error {
+	if err := c.config.checkData(attestation.Data); err != nil {
+		return err
+	}
+	if _, err := recoverAttester(c.config, attestation); err != nil {
+		return err
+	}
+	c.attestation = attestation
+	return nil
+}
+
+// Returns the address that signed an attestation.
+func recoverAttester(config *Config, attestation *OffchainAttestation) (common.Address, error) {
+	signature := attestation.Signature
+	if len(signature) != 65 || (signature[64] != 27 && signature[64] != 28) {
+		return common.Address{}, fmt.Errorf("invalid signature")
+	}
+	sig := append(append([]byte{}, signature[:64]...), signature[64]-27)
+	digest := config.Digest(attestation)
+	key, err := crypto.SigToPub(digest[:], sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
+
+func (c *OffchainCircuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *OffchainCircuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *OffchainCircuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the attestation given to SetAttestation.
+func (c *OffchainCircuit) SetWitness(inputBytes []byte) {
+	a := c.attestation
+	if a == nil {
+		panic("attestation must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	for i := 0; i < 20; i++ {
+		c.Recipient[i].Set(a.Recipient[i])
+	}
+	c.Time.Set(a.Time)
+	c.ExpirationTime.Set(a.ExpirationTime)
+	c.Revocable = vars.NewVariableFromInt(0)
+	if a.Revocable {
+		c.Revocable = vars.NewVariableFromInt(1)
+	}
+	vars.SetBytes32(&c.RefUID, a.RefUID)
+	vars.SetBytes(&c.Data, a.Data)
+	vars.SetBytes32(&c.Salt, a.Salt)
+	c.V = vars.NewVariableFromInt(int(a.Signature[64]))
+	vars.SetBytes32(&c.R, [32]byte(a.Signature[:32]))
+	vars.SetBytes32(&c.S, [32]byte(a.Signature[32:64]))
+
+	attester, err := recoverAttester(c.config, a)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.OutputBytes, c.config.outputs(attester, a.Recipient, a.Data))
+}
+
+func (c *OffchainCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	witnessBytes := append(append(append(c.Recipient[:], c.RefUID[:]...), c.Salt[:]...), c.Data...)
+	for i := 0; i < len(witnessBytes); i++ {
+		rc.Check(witnessBytes[i].Value.Value, 8)
+	}
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	now := inputReader.ReadUint64()
+
+	// The struct hash of the attestation, with the version and schema of the config.
+	config := c.config
+	version := binary.BigEndian.AppendUint64(make([]byte, 24), uint64(config.OffchainVersion))
+	schema := config.SchemaUID()
+	fapi.AssertIsBoolean(c.Revocable.Value)
+	revocable := append(vars.NewBytesFrom(make([]byte, 31)), vars.Byte{Value: c.Revocable})
+	dataHash := keccak256.Hash(*api, c.Data)
+	encoded := vars.NewBytesFrom(crypto.Keccak256([]byte(offchainTypes[config.OffchainVersion])))
+	encoded = append(encoded, vars.NewBytesFrom(version)...)
+	encoded = append(encoded, vars.NewBytesFrom(schema[:])...)
+	recipient := storage.AddressWord(c.Recipient)
+	encoded = append(encoded, recipient[:]...)
+	time := uint64Word(*api, c.Time)
+	encoded = append(encoded, time[:]...)
+	expirationTime := uint64Word(*api, c.ExpirationTime)
+	encoded = append(encoded, expirationTime[:]...)
+	encoded = append(encoded, revocable...)
+	encoded = append(encoded, c.RefUID[:]...)
+	encoded = append(encoded, dataHash[:]...)
+	if config.OffchainVersion == 2 {
+		encoded = append(encoded, c.Salt[:]...)
+	}
+	structHash := keccak256.Hash(*api, encoded)
+	domain := config.domainSeparator()
+	message := append(vars.NewBytesFrom(append([]byte{0x19, 0x01}, domain[:]...)), structHash[:]...)
+	digest := keccak256.Hash(*api, message)
+
+	x, y := compat.ECRecover(*api, digest, c.V, c.R, c.S)
+	key := keccak256.Hash(*api, append(x[:], y[:]...))
+	var attester [20]vars.Byte
+	copy(attester[:], key[12:])
+
+	api.AssertIsLessOrEqual(c.Time.Value, now.Value)
+	assertNotExpired(*api, c.ExpirationTime.Value, now.Value)
+	writeOutputs(*api, config, attester, c.Recipient, c.Data, c.InputBytes[8:], c.OutputBytes)
+	return nil
+}
+
+// Returns the ABI word of a u64, which is checked to fit in 64 bits.
+func uint64Word(api builder.API, v vars.U64) [32]vars.Byte {
+	bits := api.ToBinaryLE(v.Value, 64)
+	var word [32]vars.Byte
+	for i := 0; i < 24; i++ {
+		word[i] = vars.Byte{Value: vars.NewVariableFromInt(0)}
+	}
+	for i := 0; i < 8; i++ {
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[(7-i)*8:(8-i)*8])
+		word[24+i] = api.ToByteFromBits(byteBits)
+	}
+	return word
+}
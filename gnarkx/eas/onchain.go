@@ -0,0 +1,238 @@
+package eas
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The offsets from the slot of an attestation of the slots read by the circuit, in the storage
+// layout of the Attestation struct: uid, schema, time || expirationTime || revocationTime,
+// recipient, attester || revocable, and data, skipping refUID.
+var fieldOffsets = []int{0, 1, 2, 4, 5, 6}
+
+// OnchainCircuit verifies an attestation stored in the EAS contract at a block. Its input is the
+// hash of the block followed by the thresholds of the conditions, and the attestation must not be
+// revoked or expired at the timestamp of the block.
+type OnchainCircuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Header       eth.Header
+	AccountProof eth.MPTProof
+	UID          [32]vars.Byte
+
+	// The proofs of the slots of the attestation, in the order of Config.Slots.
+	StorageProofs []eth.MPTProof
+
+	config        *Config         `gnark:"-"`
+	header        *eth.Header     `gnark:"-"`
+	accountProof  *eth.MPTProof   `gnark:"-"`
+	uid           common.Hash     `gnark:"-"`
+	storageProofs []*eth.MPTProof `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*OnchainCircuit)(nil)
+
+// Creates a new circuit for on-chain attestations described by the config.
+func NewOnchainCircuit(config *Config) *OnchainCircuit {
+	_, reveal := config.fields()
+	storageProofs := make([]eth.MPTProof, len(fieldOffsets)+config.dataLength()/32)
+	for i := 0; i < len(storageProofs); i++ {
+		storageProofs[i] = eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxStorageValueLength)
+	}
+	return &OnchainCircuit{
+		InputBytes:    vars.NewBytes(32 + config.thresholdsLength()),
+		OutputBytes:   vars.NewBytes(32 * (2 + len(reveal))),
+		Header:        eth.NewHeader(config.MaxHeaderLength),
+		AccountProof:  eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength),
+		StorageProofs: storageProofs,
+		config:        config,
+	}
+}
+
+// Returns the storage slots of the attestation with the UID that the circuit reads, in order.
+func (c *Config) Slots(uid common.Hash) []common.Hash {
+	offset := func(slot common.Hash, offset int) common.Hash {
+		return common.BigToHash(new(big.Int).Add(slot.Big(), big.NewInt(int64(offset))))
+	}
+	db := common.BigToHash(big.NewInt(int64(c.DBSlot)))
+	base := crypto.Keccak256Hash(uid[:], db[:])
+	var slots []common.Hash
+	for _, o := range fieldOffsets {
+		slots = append(slots, offset(base, o))
+	}
+	data := crypto.Keccak256Hash(offset(base, 6).Bytes())
+	for i := 0; i < c.dataLength()/32; i++ {
+		slots = append(slots, offset(data, i))
+	}
+	return slots
+}
+
+// Sets the proofs that the next call to SetWitness assigns: the header of the block, the proof of
+// the contract account and the proofs of the slots of the attestation with the UID, as returned
+// by eth.Client.StorageProofs for Config.Slots.
+func (c *OnchainCircuit) SetProofs(
+	header *eth.Header,
+	accountProof *eth.MPTProof,
+	uid common.Hash,
+	storageProofs []*eth.MPTProof,
+) error {
+	if len(header.RLP) != len(c.Header.RLP) {
+		return fmt.Errorf("header max length %d, expected %d", len(header.RLP), len(c.Header.RLP))
+	}
+	if len(storageProofs) != len(c.StorageProofs) {
+		return fmt.Errorf("expected %d storage proofs, got %d", len(c.StorageProofs), len(storageProofs))
+	}
+	proofs := append([]*eth.MPTProof{accountProof}, storageProofs...)
+	expected := append([]eth.MPTProof{c.AccountProof}, c.StorageProofs...)
+	for i := 0; i < len(proofs); i++ {
+		if len(proofs[i].Nodes) != len(expected[i].Nodes) || len(proofs[i].Nodes[0]) != len(expected[i].Nodes[0]) ||
+			len(proofs[i].Value) != len(expected[i].Value) {
+			return fmt.Errorf("proof %d does not have the shape of the config", i)
+		}
+	}
+	if _, err := storageValues(storageProofs); err != nil {
+		return err
+	}
+	c.header = header
+	c.accountProof = accountProof
+	c.uid = uid
+	c.storageProofs = storageProofs
+	return nil
+}
+
+// Returns the values of the storage slots of proofs.
+func storageValues(proofs []*eth.MPTProof) ([][]byte, error) {
+	values := make([][]byte, len(proofs))
+	for i := 0; i < len(proofs); i++ {
+		length := int(proofs[i].ValueLength.Value.(*big.Int).Int64())
+		var value big.Int
+		if err := rlp.DecodeBytes(vars.GetValuesUnsafe(proofs[i].Value[:length]), &value); err != nil {
+			return nil, fmt.Errorf("invalid value of storage proof %d: %w", i, err)
+		}
+		if value.BitLen() > 256 {
+			return nil, fmt.Errorf("value of storage proof %d exceeds 256 bits", i)
+		}
+		values[i] = value.FillBytes(make([]byte, 32))
+	}
+	return values, nil
+}
+
+func (c *OnchainCircuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *OnchainCircuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *OnchainCircuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the proofs given to SetProofs.
+func (c *OnchainCircuit) SetWitness(inputBytes []byte) {
+	if c.header == nil {
+		panic("proofs must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.Header = *c.header
+	c.AccountProof = *c.accountProof
+	vars.SetBytes32(&c.UID, c.uid)
+	for i := 0; i < len(c.storageProofs); i++ {
+		c.StorageProofs[i] = *c.storageProofs[i]
+	}
+
+	values, err := storageValues(c.storageProofs)
+	if err != nil {
+		panic(err)
+	}
+	var data []byte
+	for i := len(fieldOffsets); i < len(values); i++ {
+		data = append(data, values[i]...)
+	}
+	recipient := common.BytesToAddress(values[3])
+	attester := common.BytesToAddress(values[4])
+	vars.SetBytes(&c.OutputBytes, c.config.outputs(attester, recipient, data))
+}
+
+func (c *OnchainCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	blockHash := inputReader.ReadBytes32()
+
+	header.NewAPI(api).Verify(c.Header)
+	st := storage.NewAPI(api)
+	api.AssertIsEqualBytes32(c.Header.Hash, blockHash)
+	var contract [20]vars.Byte
+	copy(contract[:], vars.NewBytesFrom(c.config.Contract.Bytes()))
+	account := st.VerifyAccount(c.Header.StateRoot, contract, c.AccountProof)
+
+	// The values of the slots of the attestation, followed by the words of its data.
+	config := c.config
+	base := st.MappingSlot(storage.ConstantSlot(big.NewInt(int64(config.DBSlot))), c.UID)
+	values := make([][32]vars.Byte, len(c.StorageProofs))
+	for i, offset := range fieldOffsets {
+		slot := st.OffsetSlot(base, offset)
+		values[i] = st.VerifyStorage(account.StorageRoot, slot, c.StorageProofs[i])
+	}
+	lengthSlot := st.OffsetSlot(base, 6)
+	dataSlot := keccak256.Hash(*api, lengthSlot[:])
+	var data []vars.Byte
+	for i := len(fieldOffsets); i < len(values); i++ {
+		slot := st.OffsetSlot(dataSlot, i-len(fieldOffsets))
+		values[i] = st.VerifyStorage(account.StorageRoot, slot, c.StorageProofs[i])
+		data = append(data, values[i][:]...)
+	}
+	uid, schema, times, recipient, attester, length := values[0], values[1], values[2], values[3], values[4], values[5]
+
+	// The attestation exists: its uid is stored and is not zero.
+	sum := frontend.Variable(0)
+	api.AssertIsEqualBytes32(uid, c.UID)
+	for i := 0; i < 32; i++ {
+		sum = fapi.Add(sum, uid[i].Value.Value)
+	}
+	fapi.AssertIsDifferent(sum, 0)
+
+	schemaUID := config.SchemaUID()
+	for i := 0; i < 32; i++ {
+		fapi.AssertIsEqual(schema[i].Value.Value, int(schemaUID[i]))
+	}
+
+	// The data is a long bytes value, whose slot holds 2 * length + 1.
+	var encodedLength [32]byte
+	big.NewInt(int64(2*len(data) + 1)).FillBytes(encodedLength[:])
+	for i := 0; i < 32; i++ {
+		fapi.AssertIsEqual(length[i].Value.Value, int(encodedLength[i]))
+	}
+
+	assertActive(*api, times, c.Header.Timestamp.Value)
+
+	var recipientAddress, attesterAddress [20]vars.Byte
+	copy(recipientAddress[:], recipient[12:])
+	copy(attesterAddress[:], attester[12:])
+	writeOutputs(*api, config, attesterAddress, recipientAddress, data, c.InputBytes[32:], c.OutputBytes)
+	return nil
+}
+
+// Asserts that the attestation whose times are packed in the slot is not revoked and has not
+// expired at now. The times are packed from the least significant bytes of their slot.
+func assertActive(api builder.API, times [32]vars.Byte, now vars.Variable) {
+	revocationTime := api.ToVariableFromBytesBE(times[8:16])
+	expirationTime := api.ToVariableFromBytesBE(times[16:24])
+	api.AssertIsEqual(revocationTime, vars.NewVariableFromInt(0))
+	assertNotExpired(api, expirationTime, now)
+}
@@ -0,0 +1,148 @@
+package email
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A header field that a circuit outputs, by its lowercase name.
+type FieldConfig struct {
+	Name      string
+	MaxLength int
+}
+
+// Config describes the emails a circuit accepts and the header fields it outputs.
+type Config struct {
+	MaxHeaderLength int
+	Fields          []FieldConfig
+}
+
+// Circuit verifies the DKIM signature of an email under the 2048-bit RSA modulus of its input and
+// outputs the values of the header fields of the config, each written with WriteVariableBytes.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Message Message
+	Fields  []Field
+
+	config *Config `gnark:"-"`
+	email  *Email  `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// An email as its DKIM signing input and signature, see Message.Set.
+type Email struct {
+	Header    []byte
+	Signature []byte
+}
+
+// Creates a new circuit for emails described by the config.
+func NewCircuit(config *Config) *Circuit {
+	outputLength := 0
+	fields := make([]Field, len(config.Fields))
+	for i, field := range config.Fields {
+		outputLength += 8 + field.MaxLength
+		fields[i] = NewField()
+	}
+	return &Circuit{
+		InputBytes:  vars.NewBytes(ModulusLength),
+		OutputBytes: vars.NewBytes(outputLength),
+		Message:     NewMessage(config.MaxHeaderLength),
+		Fields:      fields,
+		config:      config,
+	}
+}
+
+// Sets the email that the next call to SetWitness assigns. The email is checked to contain the
+// header fields of the config, but the signature is only checked by the circuit.
+func (c *Circuit) SetEmail(email *Email) error {
+	message := NewMessage(c.config.MaxHeaderLength)
+	if err := message.Set(email.Header, email.Signature); err != nil {
+		return err
+	}
+	if _, err := c.values(email); err != nil {
+		return err
+	}
+	c.email = email
+	return nil
+}
+
+// Returns the values of the header fields of the config in the email.
+func (c *Circuit) values(email *Email) ([][]byte, error) {
+	values := make([][]byte, len(c.config.Fields))
+	for i, field := range c.config.Fields {
+		index, length, err := findField(email.Header, field.Name)
+		if err != nil {
+			return nil, err
+		}
+		if length > field.MaxLength {
+			return nil, fmt.Errorf("header field %s length %d exceeds %d", field.Name, length, field.MaxLength)
+		}
+		start := index + len(field.Name) + 1
+		values[i] = email.Header[start : start+length]
+	}
+	return values, nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the email given to SetEmail, signed under the modulus in inputBytes.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.email == nil {
+		panic("email must be set before the witness")
+	}
+	values, err := c.values(c.email)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	if err := c.Message.Set(c.email.Header, c.email.Signature); err != nil {
+		panic(err)
+	}
+	for i, field := range c.config.Fields {
+		if err := c.Fields[i].Set(c.email.Header, field.Name); err != nil {
+			panic(err)
+		}
+	}
+
+	var output []byte
+	for i, field := range c.config.Fields {
+		var length [8]byte
+		for j := 0; j < 8; j++ {
+			length[7-j] = byte(len(values[i]) >> (8 * j))
+		}
+		value := make([]byte, field.MaxLength)
+		copy(value, values[i])
+		output = append(output, length[:]...)
+		output = append(output, value...)
+	}
+	vars.SetBytes(&c.OutputBytes, output)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	Verify(*api, c.Message, c.InputBytes)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	for i, field := range c.config.Fields {
+		outputWriter.WriteVariableBytes(HeaderValue(*api, c.Message, field.Name, c.Fields[i], field.MaxLength))
+	}
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
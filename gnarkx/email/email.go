@@ -0,0 +1,70 @@
+// A gadget for verifying the DKIM signatures of emails and reading their signed header fields, for
+// circuits proving that a domain sent an email, such as proofs of email ownership.
+//
+// The signing input is verified as given: it is the header fields listed in the h= tag of the
+// DKIM-Signature, canonicalized, followed by the DKIM-Signature header field with an empty b= tag.
+// Only the relaxed header canonicalization, in which header field names are lowercase and unfolded
+// with no whitespace around the colon, and rsa-sha256 with 2048-bit keys are supported. The body
+// hash is not checked, see package zkemail for a circuit that also reads the body.
+package email
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/rsa"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The length of a 2048-bit RSA modulus and signature in bytes.
+const ModulusLength = 256
+
+// Verifies the DKIM signature of the message under the big-endian modulus of the public key of
+// the signing domain, published in its DNS records. The bytes of the modulus are assumed to be
+// range checked, as those of the inputs of a circuit.
+func Verify(api builder.API, message Message, modulus []vars.Byte) {
+	rc := rangecheck.New(api.FrontendAPI())
+	for _, data := range [][]vars.Byte{message.Header, message.Signature} {
+		for i := 0; i < len(data); i++ {
+			rc.Check(data[i].Value.Value, 8)
+		}
+	}
+	digest := sha256.HashVariable(api, message.Header, vars.U64{Value: message.Length}, len(message.Header))
+	rsa.AssertValidPKCS1v15(api, modulus, message.Signature, digest)
+}
+
+// Returns the value of the header field with the lowercase name at the position of the field, of
+// at most maxLength bytes. The field must start a line of the signing input and its value runs up
+// to the CRLF that ends the line or to the end of the signing input. If the name occurs more than
+// once, the prover chooses the occurrence. The message must be checked with Verify.
+func HeaderValue(api builder.API, message Message, name string, field Field, maxLength int) vars.VariableBytes {
+	fapi := api.FrontendAPI()
+	key := name + ":"
+	header := byteslice.NewTable(api, message.Header, 2, len(key)+maxLength+1)
+
+	// The name is at the start of the signing input or just after a CRLF.
+	notStart := fapi.Sub(1, fapi.IsZero(field.Index.Value))
+	fapi.AssertIsEqual(fapi.Mul(notStart, fapi.Sub(header.At(field.Index, -2).Value.Value, '\r')), 0)
+	fapi.AssertIsEqual(fapi.Mul(notStart, fapi.Sub(header.At(field.Index, -1).Value.Value, '\n')), 0)
+	header.AssertLiteralAt(field.Index, []byte(key))
+
+	start := api.Add(field.Index, vars.NewVariableFromInt(len(key)))
+	end := api.Add(start, field.Length)
+	api.AssertIsLessOrEqual(field.Length, vars.NewVariableFromInt(maxLength))
+	api.AssertIsLessOrEqual(end, message.Length)
+
+	// The value has no CR and is followed by one, unless it ends the signing input.
+	value := make([]vars.Byte, maxLength)
+	inRange := frontend.Variable(1)
+	for k := 0; k < maxLength; k++ {
+		c := header.At(start, k).Value.Value
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(field.Length.Value, k)))
+		fapi.AssertIsEqual(fapi.Mul(inRange, fapi.IsZero(fapi.Sub(c, '\r'))), 0)
+		value[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, c)}}
+	}
+	next := header.At(end, 0).Value.Value
+	fapi.AssertIsEqual(fapi.Mul(fapi.Sub(next, '\r'), fapi.Sub(end.Value, message.Length.Value)), 0)
+	return vars.VariableBytes{Data: value, Length: field.Length}
+}
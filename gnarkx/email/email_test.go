@@ -0,0 +1,72 @@
+package email
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var testConfig = &Config{
+	MaxHeaderLength: 320,
+	Fields: []FieldConfig{
+		{Name: "from", MaxLength: 32},
+		{Name: "subject", MaxLength: 16},
+	},
+}
+
+func signEmail(t *testing.T, key *rsa.PrivateKey) *Email {
+	header := "from:Alice <alice@example.com>\r\n" +
+		"to:bob@example.org\r\n" +
+		"subject:verification\r\n" +
+		"dkim-signature:v=1; a=rsa-sha256; c=relaxed/relaxed; d=example.com; s=sel; h=from:to:subject; " +
+		"bh=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=; b="
+	digest := sha256.Sum256([]byte(header))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return &Email{Header: []byte(header), Signature: signature}
+}
+
+func TestEmail(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	modulus := key.N.FillBytes(make([]byte, ModulusLength))
+	email := signEmail(t, key)
+
+	circuit := NewCircuit(testConfig)
+	assert.NoError(t, circuit.SetEmail(email))
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(modulus)
+
+	expected := make([]byte, 8+32+8+16)
+	expected[7] = 25
+	copy(expected[8:], "Alice <alice@example.com>")
+	expected[47] = 12
+	copy(expected[48:], "verification")
+	assert.Equal(t, expected, vars.GetValuesUnsafe(circuit.OutputBytes))
+
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// A signature under a different key must be rejected.
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	function.SetWitness(otherKey.N.FillBytes(make([]byte, ModulusLength)))
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// A field that does not start a line must be rejected, such as the subject in the h= tag.
+	function.SetWitness(modulus)
+	circuit.Fields[1].Index = vars.NewVariableFromInt(len(email.Header) - len("subject; bh=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=; b="))
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	assert.Error(t, NewCircuit(testConfig).SetEmail(&Email{Header: []byte("to:bob@example.org"), Signature: email.Signature}))
+}
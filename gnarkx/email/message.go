@@ -0,0 +1,87 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Message is the witness of a DKIM signed email: its DKIM signing input, padded with zeros, and
+// the RSA signature of the b= tag.
+type Message struct {
+	Header []vars.Byte
+	Length vars.Variable
+
+	Signature []vars.Byte
+}
+
+// Creates a new message of up to maxHeaderLength bytes of signing input, with a signature of
+// ModulusLength bytes.
+func NewMessage(maxHeaderLength int) Message {
+	return Message{
+		Header:    vars.NewBytes(maxHeaderLength),
+		Length:    vars.NewVariable(),
+		Signature: vars.NewBytes(ModulusLength),
+	}
+}
+
+// Sets the message from its signing input, i.e. the canonicalized signed header fields followed by
+// the DKIM-Signature header field with an empty b= tag and without its CRLF, and the decoded
+// signature.
+func (m *Message) Set(header []byte, signature []byte) error {
+	if len(header) > len(m.Header) {
+		return fmt.Errorf("header length %d exceeds %d", len(header), len(m.Header))
+	}
+	if len(signature) != len(m.Signature) {
+		return fmt.Errorf("signature length %d, expected %d", len(signature), len(m.Signature))
+	}
+	data := make([]byte, len(m.Header))
+	copy(data, header)
+	vars.SetBytes(&m.Header, data)
+	m.Length = vars.NewVariableFromInt(len(header))
+	vars.SetBytes(&m.Signature, signature)
+	return nil
+}
+
+// A Field is the witness of the position of a header field in the signing input: the index of its
+// name and the length of its value.
+type Field struct {
+	Index  vars.Variable
+	Length vars.Variable
+}
+
+// Creates a new field.
+func NewField() Field {
+	return Field{Index: vars.NewVariable(), Length: vars.NewVariable()}
+}
+
+// Sets the field to the first header field with the lowercase name in the signing input, whose
+// value runs up to the end of its line.
+func (f *Field) Set(header []byte, name string) error {
+	index, length, err := findField(header, name)
+	if err != nil {
+		return err
+	}
+	f.Index = vars.NewVariableFromInt(index)
+	f.Length = vars.NewVariableFromInt(length)
+	return nil
+}
+
+// Returns the index of the first header field with the name in the signing input and the length
+// of its value.
+func findField(header []byte, name string) (int, int, error) {
+	key := []byte(name + ":")
+	for i := 0; i+len(key) <= len(header); i++ {
+		if !bytes.HasPrefix(header[i:], key) || (i > 0 && !bytes.HasSuffix(header[:i], []byte("\r\n"))) {
+			continue
+		}
+		value := header[i+len(key):]
+		length := bytes.IndexByte(value, '\r')
+		if length < 0 {
+			length = len(value)
+		}
+		return i, length, nil
+	}
+	return 0, 0, fmt.Errorf("header field %s not found", name)
+}
@@ -0,0 +1,180 @@
+// Base64 encoding and decoding of bytes as of RFC 4648, with the standard and URL and filename safe
+// alphabets, with or without padding, as the encodings of package encoding/base64:
+//
+//	encoded := base64.RawURLEncoding.Encode(api, in)
+//	decoded := base64.StdEncoding.Decode(api, vars.VariableBytes{Data: encoded, Length: length})
+//
+// Decoding validates the input in the circuit: every character must be in the alphabet, the
+// padding must be well formed, and the unused bits of the last character must be zero, so that
+// every decoded value has a single encoding.
+package base64
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	stdAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	urlAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+	// The padding character.
+	padChar = '='
+)
+
+// An Encoding is a base64 alphabet, with or without padding.
+type Encoding struct {
+	alphabet string
+	padding  bool
+}
+
+var (
+	// The standard encoding, with padding.
+	StdEncoding = &Encoding{alphabet: stdAlphabet, padding: true}
+
+	// The standard encoding without padding.
+	RawStdEncoding = &Encoding{alphabet: stdAlphabet}
+
+	// The URL and filename safe encoding, with padding.
+	URLEncoding = &Encoding{alphabet: urlAlphabet, padding: true}
+
+	// The URL and filename safe encoding without padding, as of JSON Web Tokens.
+	RawURLEncoding = &Encoding{alphabet: urlAlphabet}
+)
+
+// Returns the length of the encoding of n bytes.
+func (e *Encoding) EncodedLen(n int) int {
+	if e.padding {
+		return (n + 2) / 3 * 4
+	}
+	return (n*8 + 5) / 6
+}
+
+// Returns the maximum length of the decoding of n characters.
+func (e *Encoding) DecodedLen(n int) int {
+	return n * 6 / 8
+}
+
+// Encodes the bytes, whose length is a compile time constant. The bytes are assumed to be range
+// checked.
+func (e *Encoding) Encode(api builder.API, in []vars.Byte) []vars.Byte {
+	if len(in) == 0 {
+		return []vars.Byte{}
+	}
+	fapi := api.FrontendAPI()
+	alphabet := logderivlookup.New(fapi)
+	for i := 0; i < len(e.alphabet); i++ {
+		alphabet.Insert(int(e.alphabet[i]))
+	}
+
+	var bits []frontend.Variable
+	for i := 0; i < len(in); i++ {
+		byteBits := api.ToBitsFromByte(in[i])
+		for j := 7; j >= 0; j-- {
+			bits = append(bits, byteBits[j].Value.Value)
+		}
+	}
+	for len(bits)%6 != 0 {
+		bits = append(bits, 0)
+	}
+
+	result := make([]vars.Byte, e.EncodedLen(len(in)))
+	for i := 0; i < len(bits)/6; i++ {
+		sextet := frontend.Variable(0)
+		for j := 0; j < 6; j++ {
+			sextet = fapi.Add(sextet, fapi.Mul(bits[6*i+j], 1<<(5-j)))
+		}
+		result[i] = vars.Byte{Value: vars.Variable{Value: alphabet.Lookup(sextet)[0]}}
+	}
+	for i := len(bits) / 6; i < len(result); i++ {
+		result[i] = vars.Byte{Value: vars.NewVariableFromInt(padChar)}
+	}
+	return result
+}
+
+// Decodes the first in.Length characters of in.Data, which must be at most len(in.Data). The
+// decoded bytes are padded with zeros to DecodedLen(len(in.Data)) bytes. The characters are
+// assumed to be range checked, and characters past the length are ignored.
+func (e *Encoding) Decode(api builder.API, in vars.VariableBytes) vars.VariableBytes {
+	fapi := api.FrontendAPI()
+	maxChars := len(in.Data)
+	api.AssertIsLessOrEqual(in.Length, vars.NewVariableFromInt(maxChars))
+
+	// Maps characters to their 6-bit values, the padding character to 64 and other characters to
+	// 128, which fails the decomposition into 7 bits.
+	var values [256]int
+	for i := 0; i < len(values); i++ {
+		values[i] = 128
+	}
+	for i := 0; i < len(e.alphabet); i++ {
+		values[e.alphabet[i]] = i
+	}
+	if e.padding {
+		values[padChar] = 64
+	}
+	table := logderivlookup.New(fapi)
+	for i := 0; i < len(values); i++ {
+		table.Insert(values[i])
+	}
+
+	var sextets [][]vars.Bool
+	isPad := make([]frontend.Variable, maxChars)
+	nbPad := frontend.Variable(0)
+	inRange := frontend.Variable(1)
+	for k := 0; k < maxChars; k++ {
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(in.Length.Value, k)))
+
+		// Characters past the length are decoded as 'A', i.e. zero bits.
+		c := fapi.Select(inRange, in.Data[k].Value.Value, 'A')
+		value := api.ToBinaryLE(vars.Variable{Value: table.Lookup(c)[0]}, 7)
+		sextets = append(sextets, value[:6])
+		isPad[k] = value[6].Value.Value
+		nbPad = fapi.Add(nbPad, isPad[k])
+
+		// Padding is only allowed in the last two characters, and is not followed by characters.
+		isLastTwo := fapi.Add(fapi.IsZero(fapi.Sub(in.Length.Value, k+1)), fapi.IsZero(fapi.Sub(in.Length.Value, k+2)))
+		fapi.AssertIsEqual(fapi.Mul(isPad[k], fapi.Sub(1, isLastTwo)), 0)
+		if k > 0 {
+			fapi.AssertIsEqual(fapi.Mul(isPad[k-1], inRange, fapi.Sub(1, isPad[k])), 0)
+		}
+	}
+
+	// The n characters without padding decode to 3 * (n / 4) + n % 4 - 1 bytes if n % 4 is not
+	// zero, and n % 4 must not be 1. The padded encoding has a multiple of 4 characters.
+	nbBits := bits.Len(uint(maxChars)) + 2
+	n := api.ToBinaryLE(vars.Variable{Value: fapi.Sub(in.Length.Value, nbPad)}, nbBits)
+	if e.padding {
+		lengthBits := api.ToBinaryLE(in.Length, nbBits)
+		fapi.AssertIsEqual(fapi.Add(lengthBits[0].Value.Value, lengthBits[1].Value.Value), 0)
+	}
+	r0, r1 := n[0].Value.Value, n[1].Value.Value
+	fapi.AssertIsEqual(fapi.Mul(r0, fapi.Sub(1, r1)), 0)
+	length := fapi.Sub(fapi.Add(r0, fapi.Mul(r1, 2)), fapi.Or(r0, r1))
+	for i := 2; i < nbBits; i++ {
+		length = fapi.Add(length, fapi.Mul(n[i].Value.Value, 3<<(i-2)))
+	}
+
+	// The bytes from the length on, including the unused bits of the last character in the byte
+	// at the length, are zero.
+	data := make([]vars.Byte, e.DecodedLen(maxChars))
+	inData := frontend.Variable(1)
+	for i := 0; i < (6*maxChars+7)/8; i++ {
+		value := frontend.Variable(0)
+		for j := 0; j < 8; j++ {
+			value = fapi.Mul(value, 2)
+			if bit := 8*i + j; bit < 6*maxChars {
+				value = fapi.Add(value, sextets[bit/6][5-bit%6].Value.Value)
+			}
+		}
+		inData = fapi.Sub(inData, fapi.IsZero(fapi.Sub(length, i)))
+		fapi.AssertIsEqual(fapi.Mul(fapi.Sub(1, inData), value), 0)
+		if i < len(data) {
+			data[i] = vars.Byte{Value: vars.Variable{Value: value}}
+		}
+	}
+	return vars.VariableBytes{Data: data, Length: vars.Variable{Value: length}}
+}
@@ -0,0 +1,106 @@
+package base64
+
+import (
+	gobase64 "encoding/base64"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const maxChars = 12
+
+type testCircuit struct {
+	Data    []vars.Byte
+	Encoded vars.VariableBytes
+	Decoded vars.VariableBytes
+
+	encoding *Encoding `gnark:"-"`
+}
+
+// The circuit checks that Data encodes to the first bytes of Encoded, and that Encoded decodes to
+// Decoded.
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	encoded := c.encoding.Encode(*api, c.Data)
+	for i := range encoded {
+		api.AssertIsEqualByte(encoded[i], c.Encoded.Data[i])
+	}
+	decoded := c.encoding.Decode(*api, c.Encoded)
+	api.AssertIsEqual(decoded.Length, c.Decoded.Length)
+	for i := range decoded.Data {
+		api.AssertIsEqualByte(decoded.Data[i], c.Decoded.Data[i])
+	}
+	return nil
+}
+
+func newTestCircuit(encoding *Encoding, data []byte) *testCircuit {
+	return &testCircuit{
+		Data:     vars.NewBytes(len(data)),
+		Encoded:  vars.NewVariableBytes(maxChars),
+		Decoded:  vars.NewVariableBytes(encoding.DecodedLen(maxChars)),
+		encoding: encoding,
+	}
+}
+
+func newAssignment(encoding *Encoding, data []byte, encoded string) *testCircuit {
+	assignment := newTestCircuit(encoding, data)
+	vars.SetBytes(&assignment.Data, data)
+	vars.SetVariableBytes(&assignment.Encoded, []byte(encoded))
+	vars.SetVariableBytes(&assignment.Decoded, data)
+	return assignment
+}
+
+func TestEncoding(t *testing.T) {
+	encodings := map[*Encoding]*gobase64.Encoding{
+		StdEncoding:    gobase64.StdEncoding,
+		RawStdEncoding: gobase64.RawStdEncoding,
+		URLEncoding:    gobase64.URLEncoding,
+		RawURLEncoding: gobase64.RawURLEncoding,
+	}
+	for encoding, goEncoding := range encodings {
+		for n := 0; n <= 8; n++ {
+			data := make([]byte, n)
+			for i := range data {
+				data[i] = byte(0xfb - 37*i)
+			}
+			encoded := goEncoding.EncodeToString(data)
+			assert.Equal(t, len(encoded), encoding.EncodedLen(n))
+			err := test.IsSolved(newTestCircuit(encoding, data), newAssignment(encoding, data, encoded), ecc.BN254.ScalarField())
+			assert.NoError(t, err, encoded)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	// The decoded values are those of a lenient decoder, so that only the validation fails.
+	for _, tc := range []struct {
+		encoding *Encoding
+		encoded  string
+		decoded  string
+	}{
+		{StdEncoding, "QQ=A", "A"},
+		{StdEncoding, "Q===", ""},
+		{StdEncoding, "QR==", "A"},
+		{StdEncoding, "QQ=", "A"},
+		{StdEncoding, "QUJD-A==", "ABC\xf8"},
+		{RawStdEncoding, "QQ==", "A"},
+		{RawStdEncoding, "QUJDR", "ABC"},
+		{RawURLEncoding, "QUJD+A", "ABC\xf8"},
+	} {
+		circuit := newTestCircuit(tc.encoding, nil)
+		assignment := newAssignment(tc.encoding, nil, tc.encoded)
+		vars.SetVariableBytes(&assignment.Decoded, []byte(tc.decoded))
+		err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.Error(t, err, tc.encoded)
+	}
+
+	// The same values are accepted with valid encodings.
+	assignment := newAssignment(StdEncoding, nil, "QUJD+A==")
+	vars.SetVariableBytes(&assignment.Decoded, []byte("ABC\xf8"))
+	assert.NoError(t, test.IsSolved(newTestCircuit(StdEncoding, nil), assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,92 @@
+// Hexadecimal encoding and decoding of bytes as ASCII strings, as of package encoding/hex, such as
+// the addresses and quantities of JSON-RPC responses or the identifiers of human-readable data.
+//
+// Decoding validates the input in the circuit: every character must be a hexadecimal digit, in
+// either case.
+package hex
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	lowerDigits = "0123456789abcdef"
+	upperDigits = "0123456789ABCDEF"
+)
+
+// Encodes the bytes as lowercase hexadecimal characters. The bytes are assumed to be range checked.
+func Encode(api builder.API, in []vars.Byte) []vars.Byte {
+	return encode(api, in, lowerDigits)
+}
+
+// Encodes the bytes as uppercase hexadecimal characters. The bytes are assumed to be range checked.
+func EncodeUpper(api builder.API, in []vars.Byte) []vars.Byte {
+	return encode(api, in, upperDigits)
+}
+
+func encode(api builder.API, in []vars.Byte, digits string) []vars.Byte {
+	if len(in) == 0 {
+		return []vars.Byte{}
+	}
+	fapi := api.FrontendAPI()
+	table := logderivlookup.New(fapi)
+	for i := 0; i < len(digits); i++ {
+		table.Insert(int(digits[i]))
+	}
+
+	result := make([]vars.Byte, 0, 2*len(in))
+	for i := 0; i < len(in); i++ {
+		bits := api.ToBitsFromByte(in[i])
+		hi := frontend.Variable(0)
+		for j := 7; j >= 4; j-- {
+			hi = fapi.Add(fapi.Mul(hi, 2), bits[j].Value.Value)
+		}
+		lo := fapi.Sub(in[i].Value.Value, fapi.Mul(hi, 16))
+		for _, nibble := range table.Lookup(hi, lo) {
+			result = append(result, vars.Byte{Value: vars.Variable{Value: nibble}})
+		}
+	}
+	return result
+}
+
+// Decodes the hexadecimal characters, whose number must be even, in either case. The characters
+// are assumed to be range checked.
+func Decode(api builder.API, in []vars.Byte) []vars.Byte {
+	if len(in)%2 != 0 {
+		panic(fmt.Sprintf("odd length %d", len(in)))
+	}
+	if len(in) == 0 {
+		return []vars.Byte{}
+	}
+	fapi := api.FrontendAPI()
+
+	// Maps digits to their values and other characters to 16, which fails the decomposition into
+	// 4 bits.
+	var values [256]int
+	for i := 0; i < len(values); i++ {
+		values[i] = 16
+	}
+	for i := 0; i < 16; i++ {
+		values[lowerDigits[i]] = i
+		values[upperDigits[i]] = i
+	}
+	table := logderivlookup.New(fapi)
+	for i := 0; i < len(values); i++ {
+		table.Insert(values[i])
+	}
+
+	result := make([]vars.Byte, len(in)/2)
+	for i := range result {
+		nibbles := table.Lookup(in[2*i].Value.Value, in[2*i+1].Value.Value)
+		for _, nibble := range nibbles {
+			api.ToBinaryLE(vars.Variable{Value: nibble}, 4)
+		}
+		result[i] = vars.Byte{Value: vars.Variable{Value: fapi.Add(fapi.Mul(nibbles[0], 16), nibbles[1])}}
+	}
+	return result
+}
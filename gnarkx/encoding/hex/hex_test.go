@@ -0,0 +1,88 @@
+package hex
+
+import (
+	gohex "encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Data    []vars.Byte
+	Lower   []vars.Byte
+	Upper   []vars.Byte
+	Decoded []vars.Byte
+}
+
+// The circuit checks that Data encodes to Lower and Upper, and that Lower decodes to Decoded.
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	lower, upper := Encode(*api, c.Data), EncodeUpper(*api, c.Data)
+	for i := range lower {
+		api.AssertIsEqualByte(lower[i], c.Lower[i])
+		api.AssertIsEqualByte(upper[i], c.Upper[i])
+	}
+	decoded := Decode(*api, c.Lower)
+	for i := range decoded {
+		api.AssertIsEqualByte(decoded[i], c.Decoded[i])
+	}
+	return nil
+}
+
+func newAssignment(data []byte, lower string) *testCircuit {
+	return &testCircuit{
+		Data:    vars.NewBytesFrom(data),
+		Lower:   vars.NewBytesFrom([]byte(lower)),
+		Upper:   vars.NewBytesFrom([]byte(strings.ToUpper(gohex.EncodeToString(data)))),
+		Decoded: vars.NewBytesFrom(data),
+	}
+}
+
+func TestHex(t *testing.T) {
+	data := make([]byte, 256)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	circuit := &testCircuit{
+		Data:    vars.NewBytes(256),
+		Lower:   vars.NewBytes(512),
+		Upper:   vars.NewBytes(512),
+		Decoded: vars.NewBytes(256),
+	}
+	err := test.IsSolved(circuit, newAssignment(data, gohex.EncodeToString(data)), ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
+
+type decodeCircuit struct {
+	Encoded []vars.Byte
+	Decoded []vars.Byte
+}
+
+func (c *decodeCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	decoded := Decode(*api, c.Encoded)
+	for i := range decoded {
+		api.AssertIsEqualByte(decoded[i], c.Decoded[i])
+	}
+	return nil
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	circuit := &decodeCircuit{Encoded: vars.NewBytes(2), Decoded: vars.NewBytes(1)}
+	assignment := &decodeCircuit{Encoded: vars.NewBytesFrom([]byte("Af")), Decoded: vars.NewBytesFrom([]byte{0xaf})}
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The characters next to the digits in ASCII are rejected.
+	for _, encoded := range []string{"/f", ":f", "@f", "Gf", "`f", "gf"} {
+		assignment := &decodeCircuit{Encoded: vars.NewBytesFrom([]byte(encoded)), Decoded: vars.NewBytesFrom([]byte{0xaf})}
+		err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.Error(t, err, encoded)
+	}
+	assert.Panics(t, func() { Decode(builder.API{}, vars.NewBytes(3)) })
+}
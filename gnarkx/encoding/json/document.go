@@ -0,0 +1,114 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Field is the witness of the position of the value at a key path in a document: the indices of
+// the opening quotes of the keys and of the starts of their values, and the length of the value.
+type Field struct {
+	KeyIndex   []vars.Variable
+	ValueIndex []vars.Variable
+	Length     vars.Variable
+}
+
+// Creates a new field for a key path of depth keys.
+func NewField(depth int) Field {
+	f := Field{KeyIndex: make([]vars.Variable, depth), ValueIndex: make([]vars.Variable, depth), Length: vars.NewVariable()}
+	for i := 0; i < depth; i++ {
+		f.KeyIndex[i] = vars.NewVariable()
+		f.ValueIndex[i] = vars.NewVariable()
+	}
+	return f
+}
+
+// Sets the field to the value at the key path in the document, choosing the first member with each
+// key. The value must be a string or a literal, see Document.Value.
+func (f *Field) Set(document []byte, path ...string) error {
+	if len(path) != len(f.KeyIndex) {
+		return fmt.Errorf("path of %d keys, expected %d", len(path), len(f.KeyIndex))
+	}
+	inString, escaped, depth := scan(document)
+	start := 0
+	for j, key := range path {
+		literal := []byte(`"` + key + `"`)
+		index := -1
+		// The members of the object of the previous key end when it is closed.
+		for i := start; i < len(document) && (j == 0 || depth[i] > j); i++ {
+			if inString[i] || depth[i] != j+1 || !bytes.HasPrefix(document[i:], literal) {
+				continue
+			}
+			value := i + len(literal)
+			for value < len(document) && isWhitespace(document[value]) {
+				value++
+			}
+			if value < len(document) && document[value] == ':' {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("key %s not found", key)
+		}
+		value := index + len(key) + 2
+		for value < len(document) && (isWhitespace(document[value]) || document[value] == ':') {
+			value++
+		}
+		if value == len(document) {
+			return fmt.Errorf("key %s has no value", key)
+		}
+		f.KeyIndex[j] = vars.NewVariableFromInt(index)
+		f.ValueIndex[j] = vars.NewVariableFromInt(value)
+		start = value + 1
+		if j < len(path)-1 && document[value] != '{' {
+			return fmt.Errorf("value of key %s is not an object", key)
+		}
+	}
+
+	value := start - 1
+	length := 0
+	if document[value] == '"' {
+		for value+1+length < len(document) && (document[value+1+length] != '"' || escaped[value+1+length]) {
+			length++
+		}
+		if value+1+length == len(document) {
+			return fmt.Errorf("value of key %s is not terminated", path[len(path)-1])
+		}
+	} else {
+		for value+length < len(document) && classes[document[value+length]]>>literalBit&1 == 1 {
+			length++
+		}
+		if length == 0 || value+length == len(document) {
+			return fmt.Errorf("value of key %s is not a string or a literal", path[len(path)-1])
+		}
+	}
+	f.Length = vars.NewVariableFromInt(length)
+	return nil
+}
+
+// Returns whether each byte of the document is in a string, is escaped, and the number of open
+// objects and arrays before it, as the circuit computes them.
+func scan(document []byte) ([]bool, []bool, []int) {
+	inString := make([]bool, len(document)+1)
+	escaped := make([]bool, len(document)+1)
+	depth := make([]int, len(document)+1)
+	for i, c := range document {
+		inString[i+1] = inString[i] != (c == '"' && !escaped[i])
+		escaped[i+1] = inString[i] && !escaped[i] && c == '\\'
+		depth[i+1] = depth[i]
+		if !inString[i] && (c == '{' || c == '[') {
+			depth[i+1]++
+		}
+		if !inString[i] && (c == '}' || c == ']') {
+			depth[i+1]--
+		}
+	}
+	return inString, escaped, depth
+}
+
+func isWhitespace(c byte) bool {
+	return classes[c]>>whitespaceBit&1 == 1
+}
@@ -0,0 +1,238 @@
+// Extraction of values from JSON documents at static key paths, for circuits that read fields of
+// API responses, such as the price of a web proof, before hashing or comparing them.
+//
+// The document is scanned once in the circuit, tracking for every byte whether it is in a string,
+// whether it is escaped by a backslash and the number of objects and arrays that are open before
+// it, and values are located by witness positions that are checked against the scan. The document
+// is assumed to be valid JSON, such as a response authenticated by the circuit, and the grammar of
+// the values is bounded:
+//   - the path is a sequence of keys of nested objects, without array indices, and the keys are
+//     compared literally, so a key written with escape sequences does not match
+//   - the value is a string, whose raw content between the quotes is extracted without decoding
+//     its escape sequences, or a literal such as a number, true, false or null
+//   - if a key occurs more than once in an object, the prover chooses the member
+package json
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The bits of the classes of the bytes of a document.
+const (
+	quoteBit = iota
+	backslashBit
+	openBit
+	closeBit
+	whitespaceBit
+	colonBit
+	// The bytes of literals, i.e. neither whitespace nor structural characters nor quotes.
+	literalBit
+	// The bytes that may follow a literal: whitespace, a comma or the end of an object or array.
+	delimiterBit
+
+	nbClasses = iota
+)
+
+// The classes of every byte.
+var classes = func() [256]int {
+	var result [256]int
+	for c := 0; c < 256; c++ {
+		switch {
+		case c == '"':
+			result[c] = 1 << quoteBit
+		case c == '{' || c == '[':
+			result[c] = 1 << openBit
+		case c == '}' || c == ']':
+			result[c] = 1<<closeBit | 1<<delimiterBit
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			result[c] = 1<<whitespaceBit | 1<<delimiterBit
+		case c == ':':
+			result[c] = 1 << colonBit
+		case c == ',':
+			result[c] = 1 << delimiterBit
+		default:
+			result[c] = 1 << literalBit
+		}
+	}
+	result['\\'] |= 1 << backslashBit
+	return result
+}()
+
+// A Document is a JSON document scanned in the circuit, from which values are extracted.
+type Document struct {
+	api    builder.API
+	length vars.Variable
+	data   *byteslice.Table
+
+	// The classes of the bytes of the document, and whether each byte is in a string, is escaped
+	// and the number of objects and arrays open before it. The states have one more entry, after
+	// the last byte.
+	classes  [][nbClasses]frontend.Variable
+	inString []frontend.Variable
+	escaped  []frontend.Variable
+	depth    []frontend.Variable
+
+	// Lookup tables of the classes of the bytes and of the states, for reads at positions only
+	// known at proving time.
+	classTable    *logderivlookup.Table
+	inStringTable *logderivlookup.Table
+	escapedTable  *logderivlookup.Table
+	depthTable    *logderivlookup.Table
+}
+
+// Scans the first in.Length bytes of in.Data, which must be at most len(in.Data). The bytes are
+// assumed to be range checked.
+func NewDocument(api builder.API, in vars.VariableBytes) *Document {
+	fapi := api.FrontendAPI()
+	api.AssertIsLessOrEqual(in.Length, vars.NewVariableFromInt(len(in.Data)))
+	table := logderivlookup.New(fapi)
+	for c := 0; c < 256; c++ {
+		table.Insert(classes[c])
+	}
+
+	n := len(in.Data)
+	d := &Document{
+		api:        api,
+		length:     in.Length,
+		classTable: table,
+		data:       byteslice.NewTable(api, in.Data, 0, n+1),
+		classes:    make([][nbClasses]frontend.Variable, n),
+		inString:   make([]frontend.Variable, n+1),
+		escaped:    make([]frontend.Variable, n+1),
+		depth:      make([]frontend.Variable, n+1),
+	}
+	d.inString[0], d.escaped[0], d.depth[0] = 0, 0, 0
+	for i := 0; i < n; i++ {
+		bits := api.ToBinaryLE(vars.Variable{Value: table.Lookup(in.Data[i].Value.Value)[0]}, nbClasses)
+		for j := range bits {
+			d.classes[i][j] = bits[j].Value.Value
+		}
+		isQuote, isBackslash := d.classes[i][quoteBit], d.classes[i][backslashBit]
+		isOpen, isClose := d.classes[i][openBit], d.classes[i][closeBit]
+
+		// An unescaped quote toggles the string, and an unescaped backslash in a string escapes
+		// the next byte. Brackets outside of strings open and close objects and arrays.
+		unescaped := fapi.Sub(1, d.escaped[i])
+		toggle := fapi.Mul(isQuote, unescaped)
+		d.inString[i+1] = fapi.Sub(fapi.Add(d.inString[i], toggle), fapi.Mul(2, toggle, d.inString[i]))
+		d.escaped[i+1] = fapi.Mul(d.inString[i], unescaped, isBackslash)
+		d.depth[i+1] = fapi.Add(d.depth[i], fapi.Mul(fapi.Sub(1, d.inString[i]), fapi.Sub(isOpen, isClose)))
+	}
+
+	d.inStringTable = logderivlookup.New(fapi)
+	d.escapedTable = logderivlookup.New(fapi)
+	d.depthTable = logderivlookup.New(fapi)
+	for i := 0; i <= n; i++ {
+		d.inStringTable.Insert(d.inString[i])
+		d.escapedTable.Insert(d.escaped[i])
+		d.depthTable.Insert(d.depth[i])
+	}
+	return d
+}
+
+// Returns the value of 1 for the positions of the interval [start, end), which must have
+// start <= end, and 0 for the other positions of the document.
+func (d *Document) interval(start, end vars.Variable) []frontend.Variable {
+	fapi := d.api.FrontendAPI()
+	result := make([]frontend.Variable, len(d.classes))
+	in := frontend.Variable(0)
+	for i := range result {
+		in = fapi.Add(in, fapi.IsZero(fapi.Sub(start.Value, i)))
+		in = fapi.Sub(in, fapi.IsZero(fapi.Sub(end.Value, i)))
+		result[i] = in
+	}
+	return result
+}
+
+// Returns the value at the key path, of at most maxLength bytes, at the position of the field.
+// For a string, the value is its raw content between the quotes, and for a literal, its text.
+func (d *Document) Value(path []string, field Field, maxLength int) vars.VariableBytes {
+	if len(path) == 0 || len(field.KeyIndex) != len(path) {
+		panic(fmt.Sprintf("field of %d keys for a path of %d keys", len(field.KeyIndex), len(path)))
+	}
+	if maxLength > len(d.classes) {
+		panic("the maximum length of a value must be at most the maximum length of the document")
+	}
+	api := d.api
+	fapi := api.FrontendAPI()
+	offset := func(v vars.Variable, k int) vars.Variable {
+		return api.Add(v, vars.NewVariableFromInt(k))
+	}
+
+	for j, key := range path {
+		if strings.ContainsAny(key, "\"\\") {
+			panic(fmt.Sprintf("unsupported key %q", key))
+		}
+		// The key is a string outside of strings in the object at depth j + 1, i.e. the object that
+		// is the value of the previous key.
+		keyIndex, valueIndex := field.KeyIndex[j], field.ValueIndex[j]
+		d.data.AssertLiteralAt(keyIndex, []byte(`"`+key+`"`))
+		fapi.AssertIsEqual(d.inStringTable.Lookup(keyIndex.Value)[0], 0)
+		fapi.AssertIsEqual(d.depthTable.Lookup(keyIndex.Value)[0], j+1)
+
+		// The key is followed by a colon and whitespace up to its value, so it is the key of a
+		// member.
+		keyEnd := offset(keyIndex, len(key)+2)
+		api.AssertIsLessOrEqual(offset(keyEnd, 1), valueIndex)
+		gap := d.interval(keyEnd, valueIndex)
+		nbColons := frontend.Variable(0)
+		for i := range gap {
+			isWhitespace, isColon := d.classes[i][whitespaceBit], d.classes[i][colonBit]
+			fapi.AssertIsEqual(fapi.Mul(gap[i], fapi.Sub(1, fapi.Add(isWhitespace, isColon))), 0)
+			nbColons = fapi.Add(nbColons, fapi.Mul(gap[i], isColon))
+		}
+		fapi.AssertIsEqual(nbColons, 1)
+
+		// The value of a key before the last is an object that is not closed before the next key.
+		if j < len(path)-1 {
+			fapi.AssertIsEqual(d.data.At(valueIndex, 0).Value.Value, '{')
+			next := field.KeyIndex[j+1]
+			api.AssertIsLessOrEqual(offset(valueIndex, 1), next)
+			inside := d.interval(offset(valueIndex, 1), next)
+			for i := range inside {
+				fapi.AssertIsEqual(fapi.Mul(inside[i], fapi.IsZero(fapi.Sub(d.depth[i], j+1))), 0)
+			}
+		}
+	}
+
+	// The content of the value starts after the quote of a string, and is followed by the closing
+	// quote of a string or a delimiter of a literal.
+	start := field.ValueIndex[len(path)-1]
+	isString := fapi.IsZero(fapi.Sub(d.data.At(start, 0).Value.Value, '"'))
+	contentStart := api.Add(start, vars.Variable{Value: isString})
+	contentEnd := api.Add(contentStart, field.Length)
+	api.AssertIsLessOrEqual(field.Length, vars.NewVariableFromInt(maxLength))
+	api.AssertIsLessOrEqual(offset(contentEnd, 1), d.length)
+	fapi.AssertIsEqual(fapi.Mul(fapi.Sub(1, isString), fapi.IsZero(field.Length.Value)), 0)
+
+	content := d.interval(contentStart, contentEnd)
+	for i := range content {
+		// The bytes of a string are in the string, and those of a literal are literal bytes.
+		isLiteral := d.classes[i][literalBit]
+		valid := fapi.Select(isString, d.inString[i], isLiteral)
+		fapi.AssertIsEqual(fapi.Mul(content[i], fapi.Sub(1, valid)), 0)
+	}
+	end := d.data.At(contentEnd, 0).Value.Value
+	isClosingQuote := fapi.Mul(
+		fapi.IsZero(fapi.Sub(end, '"')),
+		d.inStringTable.Lookup(contentEnd.Value)[0],
+		fapi.Sub(1, d.escapedTable.Lookup(contentEnd.Value)[0]),
+	)
+	isDelimiter := api.ToBinaryLE(vars.Variable{Value: d.classTable.Lookup(end)[0]}, nbClasses)[delimiterBit].Value.Value
+	fapi.AssertIsEqual(fapi.Select(isString, isClosingQuote, isDelimiter), 1)
+
+	value := make([]vars.Byte, maxLength)
+	inRange := frontend.Variable(1)
+	for k := 0; k < maxLength; k++ {
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(field.Length.Value, k)))
+		value[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, d.data.At(contentStart, k).Value.Value)}}
+	}
+	return vars.VariableBytes{Data: value, Length: field.Length}
+}
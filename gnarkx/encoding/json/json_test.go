@@ -0,0 +1,151 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	maxDocumentLength = 192
+	maxValueLength    = 32
+)
+
+type testCircuit struct {
+	Document vars.VariableBytes
+	Field    Field
+	Value    vars.VariableBytes
+
+	path []string `gnark:"-"`
+}
+
+// The circuit checks that the value at the path of the document is Value.
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	value := NewDocument(*api, c.Document).Value(c.path, c.Field, maxValueLength)
+	api.AssertIsEqual(value.Length, c.Value.Length)
+	for i := range value.Data {
+		api.AssertIsEqualByte(value.Data[i], c.Value.Data[i])
+	}
+	return nil
+}
+
+func newCircuit(path []string) *testCircuit {
+	return &testCircuit{
+		Document: vars.NewVariableBytes(maxDocumentLength),
+		Field:    NewField(len(path)),
+		Value:    vars.NewVariableBytes(maxValueLength),
+		path:     path,
+	}
+}
+
+func newAssignment(document string, field Field, value string) *testCircuit {
+	c := newCircuit(nil)
+	c.Field = field
+	vars.SetVariableBytes(&c.Document, []byte(document))
+	vars.SetVariableBytes(&c.Value, []byte(value))
+	return c
+}
+
+const document = `{"name": "a \"quoted\" name", "a\"price": 9, "data" : {"name":"inner",` +
+	` "list": [{"price": 1}],` + "\n\t" + `"price": 1234.5, "ok":true}, "other": {"price": 5}, "price": "-"}`
+
+func TestValue(t *testing.T) {
+	for _, tc := range []struct {
+		path  []string
+		value string
+	}{
+		{[]string{"name"}, `a \"quoted\" name`},
+		{[]string{"price"}, "-"},
+		{[]string{"data", "name"}, "inner"},
+		{[]string{"data", "price"}, "1234.5"},
+		{[]string{"data", "ok"}, "true"},
+	} {
+		field := NewField(len(tc.path))
+		assert.NoError(t, field.Set([]byte(document), tc.path...))
+		err := test.IsSolved(newCircuit(tc.path), newAssignment(document, field, tc.value), ecc.BN254.ScalarField())
+		assert.NoError(t, err, tc.path)
+	}
+}
+
+func TestValueInvalid(t *testing.T) {
+	// The index of the nth occurrence of s in the document.
+	index := func(s string, n int) vars.Variable {
+		i := -1
+		for ; n > 0; n-- {
+			i += 1 + strings.Index(document[i+1:], s)
+		}
+		return vars.NewVariableFromInt(i)
+	}
+	for _, tc := range []struct {
+		name  string
+		path  []string
+		field Field
+		value string
+	}{
+		{
+			// The key is the end of the key a"price.
+			name:  "in string",
+			path:  []string{"price"},
+			field: Field{KeyIndex: []vars.Variable{index(`"price"`, 1)}, ValueIndex: []vars.Variable{index(`9}`, 1)}, Length: vars.NewVariableFromInt(1)},
+			value: "9",
+		},
+		{
+			// The price of the other object is not a member of data.
+			name: "outside of the parent",
+			path: []string{"data", "price"},
+			field: Field{
+				KeyIndex:   []vars.Variable{index(`"data"`, 1), index(`"price"`, 4)},
+				ValueIndex: []vars.Variable{index(`{"name":"inner"`, 1), index(`5}`, 1)},
+				Length:     vars.NewVariableFromInt(1),
+			},
+			value: "5",
+		},
+		{
+			// The price in the list is not a member of data.
+			name: "nested",
+			path: []string{"data", "price"},
+			field: Field{
+				KeyIndex:   []vars.Variable{index(`"data"`, 1), index(`"price"`, 2)},
+				ValueIndex: []vars.Variable{index(`{"name":"inner"`, 1), index(`1}`, 1)},
+				Length:     vars.NewVariableFromInt(1),
+			},
+			value: "1",
+		},
+		{
+			// A part of a literal.
+			name: "truncated literal",
+			path: []string{"data", "price"},
+			field: Field{
+				KeyIndex:   []vars.Variable{index(`"data"`, 1), index(`"price"`, 3)},
+				ValueIndex: []vars.Variable{index(`{"name":"inner"`, 1), index(`1234`, 1)},
+				Length:     vars.NewVariableFromInt(4),
+			},
+			value: "1234",
+		},
+		{
+			// A part of a string, ending at an escaped quote.
+			name:  "truncated string",
+			path:  []string{"name"},
+			field: Field{KeyIndex: []vars.Variable{index(`"name"`, 1)}, ValueIndex: []vars.Variable{index(`"a `, 1)}, Length: vars.NewVariableFromInt(3)},
+			value: `a \`,
+		},
+	} {
+		err := test.IsSolved(newCircuit(tc.path), newAssignment(document, tc.field, tc.value), ecc.BN254.ScalarField())
+		assert.Error(t, err, tc.name)
+	}
+}
+
+func TestFieldSet(t *testing.T) {
+	field := NewField(2)
+	assert.EqualError(t, field.Set([]byte(document), "data", "list"), "value of key list is not a string or a literal")
+	assert.EqualError(t, field.Set([]byte(document), "name", "data"), "value of key name is not an object")
+	assert.EqualError(t, field.Set([]byte(document), "data", "missing"), "key missing not found")
+	assert.EqualError(t, field.Set([]byte(document), "data"), "path of 1 keys, expected 2")
+}
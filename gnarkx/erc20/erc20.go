@@ -0,0 +1,214 @@
+// A circuit template proving the ERC-20 balance of a holder, or the allowance of a spender, at a
+// block, by verifying the header of the block, the proof of the token account against its state
+// root and the proof of the storage slot of the balance against the storage root of the token.
+//
+// The input is the block hash, the token address and the holder address, followed by the spender
+// address for allowances, and the output is the balance or allowance as a uint256. The slot of
+// the balances or allowances mapping and the storage layout of the compiler of the token are fixed
+// by the config, so that one circuit serves all tokens sharing a layout.
+package erc20
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The storage layout of mappings, which depends on the compiler of the token.
+type Layout int
+
+const (
+	// Solidity stores mapping[key] at keccak256(key || slot).
+	Solidity Layout = iota
+	// Vyper stores mapping[key] at keccak256(slot || key).
+	Vyper
+)
+
+// Config describes the storage layout of the tokens a circuit accepts.
+type Config struct {
+	// The slot of the balances mapping, or of the allowances mapping if Allowance is set, e.g. 0
+	// for OpenZeppelin balances and 1 for OpenZeppelin allowances.
+	Slot      int
+	Allowance bool
+	Layout    Layout
+
+	// The shape of the proofs, where eth.Client returns proofs of eth.MaxHeaderLength,
+	// eth.MaxDepth and eth.MaxNodeLength.
+	MaxHeaderLength int
+	MaxDepth        int
+	MaxNodeLength   int
+}
+
+// Circuit proves the balance or allowance of a token at a block.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Header       eth.Header
+	AccountProof eth.MPTProof
+	StorageProof eth.MPTProof
+
+	config       *Config       `gnark:"-"`
+	header       *eth.Header   `gnark:"-"`
+	accountProof *eth.MPTProof `gnark:"-"`
+	storageProof *eth.MPTProof `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.Layout != Solidity && config.Layout != Vyper {
+		panic(fmt.Sprintf("unsupported layout %d", config.Layout))
+	}
+	inputLength := 32 + 20 + 20
+	if config.Allowance {
+		inputLength += 20
+	}
+	return &Circuit{
+		InputBytes:   vars.NewBytes(inputLength),
+		OutputBytes:  vars.NewBytes(32),
+		Header:       eth.NewHeader(config.MaxHeaderLength),
+		AccountProof: eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength),
+		StorageProof: eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxStorageValueLength),
+		config:       config,
+	}
+}
+
+// Returns the input of the circuit for a balance, or for an allowance of the spender.
+func (c *Config) Input(blockHash common.Hash, token common.Address, holder common.Address, spender common.Address) []byte {
+	input := append(append(blockHash.Bytes(), token.Bytes()...), holder.Bytes()...)
+	if c.Allowance {
+		input = append(input, spender.Bytes()...)
+	}
+	return input
+}
+
+// Returns the storage slot of the balance of the holder, or of the allowance of the spender.
+func (c *Config) StorageSlot(holder common.Address, spender common.Address) common.Hash {
+	slot := mappingSlot(c.Layout, common.BigToHash(big.NewInt(int64(c.Slot))), common.BytesToHash(holder.Bytes()))
+	if c.Allowance {
+		slot = mappingSlot(c.Layout, slot, common.BytesToHash(spender.Bytes()))
+	}
+	return slot
+}
+
+func mappingSlot(layout Layout, slot common.Hash, key common.Hash) common.Hash {
+	if layout == Vyper {
+		return crypto.Keccak256Hash(slot[:], key[:])
+	}
+	return crypto.Keccak256Hash(key[:], slot[:])
+}
+
+// Sets the proofs that the next call to SetWitness assigns: the header of the block, and the proof
+// of the token account and of its slot StorageSlot, as returned by eth.Client.StorageProof.
+func (c *Circuit) SetProofs(header *eth.Header, accountProof *eth.MPTProof, storageProof *eth.MPTProof) error {
+	if len(header.RLP) != len(c.Header.RLP) {
+		return fmt.Errorf("header max length %d, expected %d", len(header.RLP), len(c.Header.RLP))
+	}
+	proofs := []*eth.MPTProof{accountProof, storageProof}
+	expected := []eth.MPTProof{c.AccountProof, c.StorageProof}
+	for i := 0; i < len(proofs); i++ {
+		if len(proofs[i].Nodes) != len(expected[i].Nodes) || len(proofs[i].Nodes[0]) != len(expected[i].Nodes[0]) ||
+			len(proofs[i].Value) != len(expected[i].Value) {
+			return fmt.Errorf("proof %d does not have the shape of the config", i)
+		}
+	}
+	if _, err := storageValue(storageProof); err != nil {
+		return err
+	}
+	c.header = header
+	c.accountProof = accountProof
+	c.storageProof = storageProof
+	return nil
+}
+
+// Returns the value of the storage slot of a proof.
+func storageValue(proof *eth.MPTProof) ([]byte, error) {
+	length := int(proof.ValueLength.Value.(*big.Int).Int64())
+	var value big.Int
+	if err := rlp.DecodeBytes(vars.GetValuesUnsafe(proof.Value[:length]), &value); err != nil {
+		return nil, fmt.Errorf("invalid value of storage proof: %w", err)
+	}
+	if value.BitLen() > 256 {
+		return nil, fmt.Errorf("value of storage proof exceeds 256 bits")
+	}
+	return value.FillBytes(make([]byte, 32)), nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the proofs given to SetProofs.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.header == nil {
+		panic("proofs must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.Header = *c.header
+	c.AccountProof = *c.accountProof
+	c.StorageProof = *c.storageProof
+	value, err := storageValue(c.storageProof)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.OutputBytes, value)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	blockHash := inputReader.ReadBytes32()
+	token := inputReader.ReadAddress()
+	holder := inputReader.ReadAddress()
+
+	header.NewAPI(api).Verify(c.Header)
+	st := storage.NewAPI(api)
+	api.AssertIsEqualBytes32(c.Header.Hash, blockHash)
+	slot := c.mappingSlot(st, storage.ConstantSlot(big.NewInt(int64(c.config.Slot))), holder)
+	if c.config.Allowance {
+		spender := inputReader.ReadAddress()
+		slot = c.mappingSlot(st, slot, spender)
+	}
+	value := st.VerifySlot(c.Header.StateRoot, token, slot, c.AccountProof, c.StorageProof)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(value)
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Returns the slot of mapping[address] in the layout of the config.
+func (c *Circuit) mappingSlot(st *storage.StorageAPI, slot [32]vars.Byte, address [20]vars.Byte) [32]vars.Byte {
+	var key [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		if i < 12 {
+			key[i] = vars.Byte{Value: vars.NewVariableFromInt(0)}
+		} else {
+			key[i] = address[i-12]
+		}
+	}
+	if c.config.Layout == Vyper {
+		return st.MappingSlot(key, slot)
+	}
+	return st.MappingSlot(slot, key)
+}
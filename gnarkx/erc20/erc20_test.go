@@ -0,0 +1,95 @@
+package erc20
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+)
+
+var (
+	token   = common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	holder  = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	spender = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// Returns a circuit with the proofs of the slot of the holder and spender in a block where the
+// slot holds the value, and the hash of the block.
+func newCircuit(t *testing.T, config *Config, value *big.Int) (*Circuit, common.Hash) {
+	slot := config.StorageSlot(holder, spender)
+	storage := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := 0; i < 20; i++ {
+		other := config.StorageSlot(common.BigToAddress(big.NewInt(int64(i))), spender)
+		storage.MustUpdate(crypto.Keccak256(other[:]), []byte{byte(i + 1)})
+	}
+	encoded, err := rlp.EncodeToBytes(value)
+	assert.NoError(t, err)
+	storage.MustUpdate(crypto.Keccak256(slot[:]), encoded)
+
+	accounts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	account, err := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(0), storage.Hash(), types.EmptyCodeHash})
+	assert.NoError(t, err)
+	accounts.MustUpdate(crypto.Keccak256(token.Bytes()), account)
+	for i := 0; i < 10; i++ {
+		other, err := rlp.EncodeToBytes([]interface{}{uint64(0), big.NewInt(int64(i)), types.EmptyRootHash, types.EmptyCodeHash})
+		assert.NoError(t, err)
+		accounts.MustUpdate(crypto.Keccak256(common.BigToAddress(big.NewInt(int64(i))).Bytes()), other)
+	}
+
+	block := &types.Header{
+		Root:       accounts.Hash(),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(18000000),
+		GasLimit:   30000000,
+		Time:       1700000000,
+		BaseFee:    big.NewInt(20e9),
+	}
+	header := eth.NewHeader(config.MaxHeaderLength)
+	assert.NoError(t, header.Set(block))
+	circuit := NewCircuit(config)
+	err = circuit.SetProofs(
+		&header,
+		functiontest.Prove(t, accounts, token.Bytes(), account, 4, eth.MaxNodeLength, eth.MaxAccountLength),
+		functiontest.Prove(t, storage, slot[:], encoded, 4, eth.MaxNodeLength, eth.MaxStorageValueLength),
+	)
+	assert.NoError(t, err)
+	return circuit, block.Hash()
+}
+
+func TestBalance(t *testing.T) {
+	config := &Config{Slot: 9, MaxHeaderLength: eth.MaxHeaderLength, MaxDepth: 4, MaxNodeLength: eth.MaxNodeLength}
+	balance, _ := new(big.Int).SetString("123456789000000000000000000", 10)
+	circuit, blockHash := newCircuit(t, config, balance)
+	assert.NoError(t, functiontest.IsSolved(circuit, config.Input(blockHash, token, holder, common.Address{})))
+	for i := 0; i < 32; i++ {
+		assert.Equal(t, balance.FillBytes(make([]byte, 32))[i], circuit.OutputBytes[i].GetValueUnsafe())
+	}
+
+	// The proof must be of the balance of the holder of the input.
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(blockHash, token, spender, common.Address{})))
+}
+
+func TestVyperAllowance(t *testing.T) {
+	config := &Config{
+		Slot:            4,
+		Allowance:       true,
+		Layout:          Vyper,
+		MaxHeaderLength: eth.MaxHeaderLength,
+		MaxDepth:        4,
+		MaxNodeLength:   eth.MaxNodeLength,
+	}
+	allowance := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	circuit, blockHash := newCircuit(t, config, allowance)
+	assert.NoError(t, functiontest.IsSolved(circuit, config.Input(blockHash, token, holder, spender)))
+	for i := 0; i < 32; i++ {
+		assert.Equal(t, byte(0xff), circuit.OutputBytes[i].GetValueUnsafe())
+	}
+}
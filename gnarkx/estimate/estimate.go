@@ -0,0 +1,206 @@
+// Estimation of the resources needed to prove a circuit. Estimates are derived from the constraint
+// count of the circuit and a per-machine calibration, so that services can schedule proving jobs
+// to appropriately sized workers without running the (much more expensive) setup and prover.
+package estimate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// A Calibration is a linear model of the Groth16 prover on a specific machine. Both the prove time
+// and peak memory grow roughly linearly in the number of constraints.
+type Calibration struct {
+	// An identifier of the machine the calibration was measured on.
+	Machine string `json:"machine"`
+
+	// The prove time per constraint in nanoseconds.
+	NsPerConstraint float64 `json:"nsPerConstraint"`
+
+	// The fixed prove time in nanoseconds.
+	BaseNs float64 `json:"baseNs"`
+
+	// The peak memory per constraint in bytes.
+	BytesPerConstraint float64 `json:"bytesPerConstraint"`
+
+	// The fixed peak memory in bytes.
+	BaseBytes float64 `json:"baseBytes"`
+}
+
+// A conservative calibration for when no measurements of the machine are available.
+var DefaultCalibration = Calibration{
+	Machine:            "default",
+	NsPerConstraint:    10000,
+	BaseNs:             float64(time.Second),
+	BytesPerConstraint: 2048,
+	BaseBytes:          256 << 20,
+}
+
+// The estimated resources needed to prove a circuit.
+type Resources struct {
+	NbConstraints int           `json:"nbConstraints"`
+	PeakMemory    uint64        `json:"peakMemory"`
+	ProveTime     time.Duration `json:"proveTime"`
+}
+
+// Estimates the resources needed to prove the given circuit over BN254. The circuit is compiled to
+// count its constraints, but no setup is run. If calibration is nil, DefaultCalibration is used.
+func Estimate(circuit frontend.Circuit, calibration *Calibration) (*Resources, error) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile circuit: %w", err)
+	}
+	if calibration == nil {
+		calibration = &DefaultCalibration
+	}
+	return calibration.Resources(ccs.GetNbConstraints()), nil
+}
+
+// Estimates the resources needed to prove a circuit with the given number of constraints.
+func (c *Calibration) Resources(nbConstraints int) *Resources {
+	n := float64(nbConstraints)
+	return &Resources{
+		NbConstraints: nbConstraints,
+		PeakMemory:    uint64(c.BaseBytes + c.BytesPerConstraint*n),
+		ProveTime:     time.Duration(c.BaseNs + c.NsPerConstraint*n),
+	}
+}
+
+// Export saves the calibration to a file.
+func (c *Calibration) Export(file string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal calibration: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Loads a calibration that was previously exported to a file.
+func LoadCalibration(file string) (*Calibration, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	c := &Calibration{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal calibration: %w", err)
+	}
+	return c, nil
+}
+
+// Calibrates the prover on the current machine by proving reference circuits with the given
+// small and large numbers of constraints and fitting a line through the measurements. Larger
+// sizes give more accurate calibrations at the cost of a longer run.
+func Calibrate(machine string, small int, large int) (*Calibration, error) {
+	if small <= 0 || large <= small {
+		return nil, fmt.Errorf("invalid calibration sizes %d and %d", small, large)
+	}
+	smallTime, smallMemory, err := measure(small)
+	if err != nil {
+		return nil, err
+	}
+	largeTime, largeMemory, err := measure(large)
+	if err != nil {
+		return nil, err
+	}
+
+	dn := float64(large - small)
+	c := &Calibration{Machine: machine}
+	c.NsPerConstraint = nonNegative(float64(largeTime-smallTime) / dn)
+	c.BaseNs = nonNegative(float64(smallTime) - c.NsPerConstraint*float64(small))
+	c.BytesPerConstraint = nonNegative((float64(largeMemory) - float64(smallMemory)) / dn)
+	c.BaseBytes = nonNegative(float64(smallMemory) - c.BytesPerConstraint*float64(small))
+	return c, nil
+}
+
+// A reference circuit made of a chain of multiplications, one constraint each.
+type referenceCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+
+	n int `gnark:"-"`
+}
+
+func (c *referenceCircuit) Define(api frontend.API) error {
+	acc := c.Y
+	for i := 0; i < c.n; i++ {
+		acc = api.Mul(acc, c.Y)
+	}
+	api.AssertIsDifferent(acc, c.X)
+	return nil
+}
+
+// Measures the prove time and peak heap usage of a reference circuit with n constraints.
+func measure(n int) (time.Duration, uint64, error) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &referenceCircuit{n: n})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compile reference circuit: %w", err)
+	}
+	pk, _, err := groth16.Setup(ccs)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to setup reference circuit: %w", err)
+	}
+	w, err := frontend.NewWitness(&referenceCircuit{X: 0, Y: 2}, ecc.BN254.ScalarField())
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create witness: %w", err)
+	}
+
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+
+	var peak uint64
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		var s runtime.MemStats
+		for {
+			runtime.ReadMemStats(&s)
+			if s.HeapAlloc > peak {
+				peak = s.HeapAlloc
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	start := time.Now()
+	_, err = groth16.Prove(ccs, pk, w)
+	elapsed := time.Since(start)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prove reference circuit: %w", err)
+	}
+	if peak < baseline {
+		return elapsed, 0, nil
+	}
+	return elapsed, peak - baseline, nil
+}
+
+func nonNegative(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return x
+}
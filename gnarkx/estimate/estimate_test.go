@@ -0,0 +1,43 @@
+package estimate
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *testCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.X, api.Mul(c.Y, c.Y, c.Y))
+	return nil
+}
+
+func TestEstimate(t *testing.T) {
+	calibration, err := Calibrate("test", 1<<10, 1<<12)
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "calibration.json")
+	assert.NoError(t, calibration.Export(file))
+	loaded, err := LoadCalibration(file)
+	assert.NoError(t, err)
+	assert.Equal(t, calibration, loaded)
+
+	resources, err := Estimate(&testCircuit{}, loaded)
+	assert.NoError(t, err)
+	assert.Positive(t, resources.NbConstraints)
+
+	// Larger circuits never need fewer resources.
+	small := loaded.Resources(1 << 10)
+	large := loaded.Resources(1 << 20)
+	assert.LessOrEqual(t, small.ProveTime, large.ProveTime)
+	assert.LessOrEqual(t, small.PeakMemory, large.PeakMemory)
+
+	_, err = Calibrate("test", 10, 5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+// The API for verifying the block headers of the Ethereum execution layer, which hash to the block
+// hashes and hold the roots that the proofs of accounts, storage slots and receipts are against.
+package header
+
+import (
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The prefixes of the fields of a header up to the logs bloom, which all have a fixed length, and
+// the offset of the difficulty, the first field of variable length.
+var headerPrefixes = []struct {
+	offset int
+	prefix []byte
+}{
+	{3, []byte{0xa0}},               // parentHash
+	{36, []byte{0xa0}},              // ommersHash
+	{69, []byte{0x94}},              // coinbase
+	{90, []byte{0xa0}},              // stateRoot
+	{123, []byte{0xa0}},             // transactionsRoot
+	{156, []byte{0xa0}},             // receiptsRoot
+	{189, []byte{0xb9, 0x01, 0x00}}, // logsBloom
+}
+
+const (
+	parentHashOffset   = 4
+	stateRootOffset    = 91
+	receiptsRootOffset = 157
+	difficultyOffset   = 448
+)
+
+// HeaderAPI is a wrapper around succinct.API that provides methods for verifying block headers
+// against their block hashes.
+type HeaderAPI struct {
+	api builder.API
+}
+
+// Creates a new HeaderAPI.
+func NewAPI(api *builder.API) *HeaderAPI {
+	return &HeaderAPI{api: *api}
+}
+
+// Verifies that the RLP encoding of the header hashes to header.Hash and holds the parent hash,
+// state root, receipts root, number and timestamp of the header, so that they can be read from
+// its fields.
+func (a *HeaderAPI) Verify(header eth.Header) {
+	api := a.api
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(header.RLP); i++ {
+		rc.Check(header.RLP[i].Value.Value, 8)
+	}
+	if len(header.RLP) < difficultyOffset {
+		panic("header is too short")
+	}
+
+	digest := keccak256.HashVariable(api, header.RLP, header.Length)
+	api.AssertIsEqualBytes32(digest, header.Hash)
+
+	// The header is a list of more than 255 bytes.
+	fapi.AssertIsEqual(header.RLP[0].Value.Value, 0xf9)
+	listLength := fapi.Add(fapi.Mul(header.RLP[1].Value.Value, 256), header.RLP[2].Value.Value)
+	fapi.AssertIsEqual(fapi.Add(listLength, 3), header.Length.Value)
+	for _, field := range headerPrefixes {
+		for i := 0; i < len(field.prefix); i++ {
+			fapi.AssertIsEqual(header.RLP[field.offset+i].Value.Value, int(field.prefix[i]))
+		}
+	}
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(header.RLP[parentHashOffset+i], header.ParentHash[i])
+		api.AssertIsEqualByte(header.RLP[stateRootOffset+i], header.StateRoot[i])
+		api.AssertIsEqualByte(header.RLP[receiptsRootOffset+i], header.ReceiptsRoot[i])
+	}
+
+	// The difficulty, number, gas limit, gas used and timestamp follow the logs bloom.
+	table := byteslice.NewTable(api, header.RLP, 32, 3)
+	difficulty := rlp.ReadItem(api, table, vars.NewVariableFromInt(difficultyOffset))
+	number := rlp.ReadItem(api, table, difficulty.End)
+	gasLimit := rlp.ReadItem(api, table, number.End)
+	gasUsed := rlp.ReadItem(api, table, gasLimit.End)
+	timestamp := rlp.ReadItem(api, table, gasUsed.End)
+	api.AssertIsEqual(rlp.ReadUint64(api, table, number).Value, header.Number.Value)
+	api.AssertIsEqual(rlp.ReadUint64(api, table, timestamp).Value, header.Timestamp.Value)
+}
+
+// Returns the item of the extra data of a header verified by Verify, where table is over the RLP
+// of the header and padded with at least 32 bytes before and 3 bytes after.
+func (a *HeaderAPI) Extra(table *byteslice.Table) rlp.Item {
+	api := a.api
+	difficulty := rlp.ReadItem(api, table, vars.NewVariableFromInt(difficultyOffset))
+	number := rlp.ReadItem(api, table, difficulty.End)
+	gasLimit := rlp.ReadItem(api, table, number.End)
+	gasUsed := rlp.ReadItem(api, table, gasLimit.End)
+	timestamp := rlp.ReadItem(api, table, gasUsed.End)
+	return rlp.ReadItem(api, table, timestamp.End)
+}
@@ -0,0 +1,73 @@
+package header
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+)
+
+type testCircuit struct {
+	Header eth.Header
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).Verify(c.Header)
+	return nil
+}
+
+func newCircuit() *testCircuit {
+	return &testCircuit{Header: eth.NewHeader(eth.MaxHeaderLength)}
+}
+
+func TestHeader(t *testing.T) {
+	header := &types.Header{
+		ParentHash: common.HexToHash("0x01"),
+		Root:       common.HexToHash("0x02"),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(18000000),
+		GasLimit:   30000000,
+		GasUsed:    12000000,
+		Time:       1700000000,
+		Extra:      []byte("succinct"),
+		BaseFee:    big.NewInt(20e9),
+	}
+	newAssignment := func() *testCircuit {
+		assignment := newCircuit()
+		assert.NoError(t, assignment.Header.Set(header))
+		return assignment
+	}
+
+	assignment := newAssignment()
+	err := test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The header must hash to the block hash.
+	assignment.Header.Hash[0].Set(assignment.Header.Hash[0].GetValueUnsafe() ^ 1)
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The state root, number and timestamp must be those of the header.
+	assignment = newAssignment()
+	assignment.Header.StateRoot[31].Set(3)
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	assignment = newAssignment()
+	assignment.Header.Number.Set(header.Number.Uint64() + 1)
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	assignment = newAssignment()
+	assignment.Header.Timestamp.Set(header.Time + 1)
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
@@ -1,3 +1,246 @@
 // The API for operations related to Merkle Patricia Tries, a serialization method used by the
 // Ethereum execution layer.
 package mpt
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of nibbles of a key of the state and storage tries, which are hashed with keccak256.
+const keyNibbles = 64
+
+// The maximum number of bytes of a branch node read past the start of its payload: 17 items of
+// at most 33 bytes each.
+const branchLength = 17 * 33
+
+// MerklePatriciaTrieAPI is a wrapper around succinct.API that provides methods related to
+// Merkle Patricia Tries. For more information and details, see:
+// https://ethereum.org/en/developers/docs/data-structures-and-encoding/patricia-merkle-trie/
+type MerklePatriciaTrieAPI struct {
+	api builder.API
+}
+
+// Creates a new MerklePatriciaTrieAPI.
+func NewAPI(api *builder.API) *MerklePatriciaTrieAPI {
+	return &MerklePatriciaTrieAPI{api: *api}
+}
+
+// Verifies that the proof is a path from proof.Root to proof.Value under proof.Key, which is the
+// hashed key of a state or storage trie. The caller must constrain the root, the key and the
+// interpretation of the value.
+//
+// A key that is not in the trie is proven by a path ending at an empty child of a branch or at a
+// leaf or extension that diverges from the key, and a value of 0x80, the RLP encoding of the empty
+// string, which is also the encoding of a zero storage slot. Children of nodes must be referenced
+// by hash, which holds for all nodes of tries with hashed keys.
+func (a *MerklePatriciaTrieAPI) VerifyProof(proof eth.MPTProof) {
+	a.VerifyProofWithKeyLength(proof, vars.NewVariableFromInt(keyNibbles))
+}
+
+// Verifies a proof as VerifyProof for a trie with unhashed keys of varying lengths, such as the
+// transaction and receipt tries keyed by the RLP encoding of the index, where proof.Key holds the
+// key right padded with zeros and keyLength is its length in nibbles. Keys must not be prefixes of
+// each other, and children must still be referenced by hash, which holds for receipt tries since
+// every receipt has a bloom filter of 256 bytes.
+func (a *MerklePatriciaTrieAPI) VerifyProofWithKeyLength(proof eth.MPTProof, keyLength vars.Variable) {
+	api := a.api
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < 32; i++ {
+		rc.Check(proof.Key[i].Value.Value, 8)
+	}
+	for i := 0; i < len(proof.Value); i++ {
+		rc.Check(proof.Value[i].Value.Value, 8)
+	}
+	for i := 0; i < len(proof.Nodes); i++ {
+		for j := 0; j < len(proof.Nodes[i]); j++ {
+			rc.Check(proof.Nodes[i][j].Value.Value, 8)
+		}
+	}
+
+	// The nibbles of the key, padded for reads of paths past the end of the key.
+	nibbles := make([]vars.Byte, 0, keyNibbles)
+	for i := 0; i < 32; i++ {
+		bits := api.ToBitsFromByte(proof.Key[i])
+		high := vars.Byte{Value: vars.Variable{Value: nibble(fapi, bits[4:])}}
+		low := vars.Byte{Value: vars.Variable{Value: nibble(fapi, bits[:4])}}
+		nibbles = append(nibbles, high, low)
+	}
+	key := byteslice.NewTable(api, nibbles, 0, keyNibbles)
+
+	depth := len(proof.Nodes)
+	api.AssertIsLessOrEqual(proof.Depth, vars.NewVariableFromInt(depth))
+	fapi.AssertIsDifferent(proof.Depth.Value, 0)
+
+	expected := proof.Root
+	keyIndex := frontend.Variable(0)
+	isActive := frontend.Variable(1)
+	for i := 0; i < depth; i++ {
+		isLast := fapi.IsZero(fapi.Sub(proof.Depth.Value, i+1))
+		node := decodeNode(api, proof.Nodes[i], proof.NodeLengths[i], key, keyLength, vars.Variable{Value: keyIndex})
+
+		digest := keccak256.HashVariable(api, proof.Nodes[i], proof.NodeLengths[i])
+		for j := 0; j < 32; j++ {
+			fapi.AssertIsEqual(fapi.Mul(isActive, fapi.Sub(digest[j].Value.Value, expected[j].Value.Value)), 0)
+		}
+		fapi.AssertIsEqual(fapi.Mul(isActive, fapi.Sub(node.isNode, 1)), 0)
+
+		// Nodes before the last one must lead to the next node along the key.
+		isInner := fapi.Sub(isActive, isLast)
+		fapi.AssertIsEqual(fapi.Mul(isInner, fapi.Sub(node.isChild, 1)), 0)
+
+		// The last node must either hold the value or show that the key is not in the trie.
+		isIncluded := fapi.Mul(isLast, node.isIncluded)
+		isExcluded := fapi.Mul(isLast, node.isExcluded)
+		fapi.AssertIsEqual(fapi.Add(isIncluded, isExcluded), isLast)
+		fapi.AssertIsEqual(fapi.Mul(isIncluded, fapi.Sub(proof.ValueLength.Value, node.value.Length.Value)), 0)
+		inValue := frontend.Variable(1)
+		for j := 0; j < len(proof.Value); j++ {
+			inValue = fapi.Sub(inValue, fapi.IsZero(fapi.Sub(proof.ValueLength.Value, j)))
+			diff := fapi.Sub(proof.Value[j].Value.Value, node.table.At(node.value.Offset, j).Value.Value)
+			fapi.AssertIsEqual(fapi.Mul(fapi.Mul(isIncluded, inValue), diff), 0)
+		}
+		fapi.AssertIsEqual(fapi.Mul(isExcluded, fapi.Sub(proof.ValueLength.Value, 1)), 0)
+		fapi.AssertIsEqual(fapi.Mul(isExcluded, fapi.Sub(proof.Value[0].Value.Value, 0x80)), 0)
+
+		expected = node.child
+		keyIndex = fapi.Add(keyIndex, node.consumed)
+		isActive = fapi.Sub(isActive, isLast)
+	}
+}
+
+// A node decoded along a key. All flags are 0 or 1.
+type node struct {
+	table *byteslice.Table
+
+	// Whether the node is a valid branch, extension or leaf.
+	isNode frontend.Variable
+
+	// Whether the node is a branch or extension that leads to child along the key, and the number
+	// of nibbles of the key it consumes.
+	isChild  frontend.Variable
+	child    [32]vars.Byte
+	consumed frontend.Variable
+
+	// Whether the node is a leaf holding the value of the key, or shows that the key is not in the
+	// trie.
+	isIncluded frontend.Variable
+	value      rlp.Item
+	isExcluded frontend.Variable
+}
+
+// Decodes a node and follows the key of keyLength nibbles from the nibble at keyIndex.
+func decodeNode(
+	api builder.API,
+	data []vars.Byte,
+	length vars.Variable,
+	key *byteslice.Table,
+	keyLength vars.Variable,
+	keyIndex vars.Variable,
+) node {
+	fapi := api.FrontendAPI()
+	table := byteslice.NewTable(api, data, 0, branchLength+3)
+	list := rlp.ReadItem(api, table, vars.NewVariableFromInt(0))
+	isList := fapi.Mul(list.IsList.Value.Value, fapi.IsZero(fapi.Sub(list.End.Value, length.Value)))
+
+	// A branch has 16 children that are empty or hashes, and an empty value.
+	isBranch := isList
+	offset := list.Offset
+	isEmpty := make([]frontend.Variable, 16)
+	offsets := make([]vars.Variable, 16)
+	for j := 0; j < 17; j++ {
+		header := table.At(offset, 0).Value.Value
+		isEmptyItem := fapi.IsZero(fapi.Sub(header, 0x80))
+		isHash := fapi.IsZero(fapi.Sub(header, 0xa0))
+		if j < 16 {
+			isEmpty[j] = isEmptyItem
+			offsets[j] = offset
+			isBranch = fapi.Mul(isBranch, fapi.Add(isEmptyItem, isHash))
+		} else {
+			isBranch = fapi.Mul(isBranch, isEmptyItem)
+		}
+		offset = vars.Variable{Value: fapi.Add(offset.Value, fapi.Add(1, fapi.Mul(isHash, 32)))}
+	}
+	isBranch = fapi.Mul(isBranch, fapi.IsZero(fapi.Sub(offset.Value, list.End.Value)))
+
+	branchNibble := key.At(keyIndex, 0).Value.Value
+	childOffset := frontend.Variable(0)
+	isEmptyChild := frontend.Variable(0)
+	for j := 0; j < 16; j++ {
+		isSelected := fapi.IsZero(fapi.Sub(branchNibble, j))
+		childOffset = fapi.Add(childOffset, fapi.Mul(isSelected, offsets[j].Value))
+		isEmptyChild = fapi.Add(isEmptyChild, fapi.Mul(isSelected, isEmpty[j]))
+	}
+	branchChild := table.Read(vars.Variable{Value: fapi.Add(childOffset, 1)}, 32)
+
+	// Extensions and leaves have a hex prefix encoded path and a hash or a value.
+	path := rlp.ReadItem(api, table, list.Offset)
+	value := rlp.ReadItem(api, table, path.End)
+	isPair := fapi.Mul(isList, fapi.IsZero(fapi.Sub(value.End.Value, list.End.Value)))
+	isPair = fapi.Mul(isPair, fapi.Sub(1, fapi.Add(path.IsList.Value.Value, value.IsList.Value.Value)))
+	isPair = fapi.Mul(isPair, fapi.Sub(1, isBranch))
+	pathBytes := table.Read(path.Offset, 33)
+	flag := api.ToBitsFromByte(pathBytes[0])
+	isPair = fapi.Mul(isPair, fapi.IsZero(fapi.Add(flag[7].Value.Value, flag[6].Value.Value)))
+	isOdd := flag[4].Value.Value
+	isLeaf := fapi.Mul(isPair, flag[5].Value.Value)
+	isExtension := fapi.Sub(isPair, isLeaf)
+	nbPath := fapi.Add(fapi.Mul(fapi.Sub(path.Length.Value, 1), 2), isOdd)
+
+	// The nibbles of the path after the flag, starting at the low nibble of the flag for odd paths.
+	var pathNibbles []frontend.Variable
+	for j := 0; j < len(pathBytes); j++ {
+		bits := api.ToBitsFromByte(pathBytes[j])
+		pathNibbles = append(pathNibbles, nibble(fapi, bits[4:]), nibble(fapi, bits[:4]))
+	}
+	inPath := frontend.Variable(1)
+	nbMismatch := frontend.Variable(0)
+	for m := 0; m < keyNibbles; m++ {
+		inPath = fapi.Sub(inPath, fapi.IsZero(fapi.Sub(nbPath, m)))
+		pathNibble := fapi.Select(isOdd, pathNibbles[m+1], pathNibbles[m+2])
+		keyNibble := key.At(keyIndex, m).Value.Value
+		isDifferent := fapi.Sub(1, fapi.IsZero(fapi.Sub(pathNibble, keyNibble)))
+		nbMismatch = fapi.Add(nbMismatch, fapi.Mul(inPath, isDifferent))
+	}
+	isMatch := fapi.IsZero(nbMismatch)
+	// The child of an extension is a hash, with no single byte or long encoding.
+	isHashLength := fapi.IsZero(fapi.Sub(value.Length.Value, 32))
+	isHashHeader := fapi.IsZero(fapi.Sub(value.Offset.Value, fapi.Add(path.End.Value, 1)))
+	isExtension = fapi.Mul(isExtension, fapi.Mul(isHashLength, isHashHeader))
+	isEnd := fapi.IsZero(fapi.Sub(fapi.Add(keyIndex.Value, nbPath), keyLength.Value))
+
+	var child [32]vars.Byte
+	extensionChild := table.Read(value.Offset, 32)
+	for j := 0; j < 32; j++ {
+		value := fapi.Select(isBranch, branchChild[j].Value.Value, extensionChild[j].Value.Value)
+		child[j] = vars.Byte{Value: vars.Variable{Value: value}}
+	}
+	isBranchEmpty := fapi.Mul(isBranch, isEmptyChild)
+	isBranchChild := fapi.Sub(isBranch, isBranchEmpty)
+	return node{
+		table:      table,
+		isNode:     fapi.Add(isBranch, fapi.Add(isLeaf, isExtension)),
+		isChild:    fapi.Add(isBranchChild, fapi.Mul(isExtension, isMatch)),
+		child:      child,
+		consumed:   fapi.Select(isBranch, 1, nbPath),
+		isIncluded: fapi.Mul(fapi.Mul(isLeaf, isMatch), isEnd),
+		value:      value,
+		isExcluded: fapi.Add(isBranchEmpty, fapi.Mul(fapi.Add(isLeaf, isExtension), fapi.Sub(1, isMatch))),
+	}
+}
+
+// Returns the nibble of 4 little-endian bits.
+func nibble(fapi frontend.API, bits []vars.Bool) frontend.Variable {
+	result := frontend.Variable(0)
+	for i := 0; i < 4; i++ {
+		result = fapi.Add(result, fapi.Mul(bits[i].Value.Value, 1<<i))
+	}
+	return result
+}
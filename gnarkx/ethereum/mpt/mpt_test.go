@@ -0,0 +1,132 @@
+package mpt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+)
+
+// The shape of the proofs of the test tries.
+const (
+	maxDepth       = 4
+	maxNodeLength  = 532
+	maxValueLength = 80
+)
+
+type testCircuit struct {
+	Proof eth.MPTProof
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).VerifyProof(c.Proof)
+	return nil
+}
+
+func newTrie(t *testing.T, values map[string][]byte) *trie.Trie {
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for key, value := range values {
+		tr.MustUpdate(crypto.Keccak256([]byte(key)), value)
+	}
+	return tr
+}
+
+func newProof(t *testing.T, tr *trie.Trie, key []byte, value []byte) *testCircuit {
+	var nodes eth.ProofNodes
+	assert.NoError(t, tr.Prove(crypto.Keccak256(key), 0, &nodes))
+	proof := eth.NewMPTProof(maxDepth, maxNodeLength, maxValueLength)
+	assert.NoError(t, proof.Set(tr.Hash(), key, value, nodes))
+	return &testCircuit{Proof: proof}
+}
+
+func TestStorageProof(t *testing.T) {
+	values := make(map[string][]byte)
+	for i := 0; i < 100; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		value, err := rlp.EncodeToBytes(new(big.Int).Lsh(big.NewInt(int64(i+1)), uint(i*2)))
+		assert.NoError(t, err)
+		values[string(slot.Bytes())] = value
+	}
+	tr := newTrie(t, values)
+	circuit := &testCircuit{Proof: eth.NewMPTProof(maxDepth, maxNodeLength, maxValueLength)}
+
+	// Values of a single byte and of several bytes.
+	for _, i := range []int{0, 42, 99} {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		assignment := newProof(t, tr, slot.Bytes(), values[string(slot.Bytes())])
+		err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+	}
+
+	// The value must be the value under the key.
+	slot := common.BigToHash(big.NewInt(42))
+	assignment := newProof(t, tr, slot.Bytes(), values[string(common.BigToHash(big.NewInt(41)).Bytes())])
+	err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// A slot that is not in the trie is zero, whether its path ends at an empty child of a branch
+	// or at a leaf of another slot.
+	endsAtBranch := map[bool]bool{}
+	for i := 100; len(endsAtBranch) < 2; i++ {
+		slot = common.BigToHash(big.NewInt(int64(i)))
+		var nodes eth.ProofNodes
+		assert.NoError(t, tr.Prove(crypto.Keccak256(slot.Bytes()), 0, &nodes))
+		isBranch := len(nodes[len(nodes)-1]) > 2*33
+		if endsAtBranch[isBranch] {
+			continue
+		}
+		endsAtBranch[isBranch] = true
+		assignment = newProof(t, tr, slot.Bytes(), []byte{0x80})
+		err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+	}
+
+	assignment = newProof(t, tr, slot.Bytes(), []byte{0x01})
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// A slot that is in the trie can not be proven to be zero.
+	slot = common.BigToHash(big.NewInt(42))
+	assignment = newProof(t, tr, slot.Bytes(), []byte{0x80})
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+func TestAccountProof(t *testing.T) {
+	values := make(map[string][]byte)
+	for i := 0; i < 50; i++ {
+		address := common.BigToAddress(big.NewInt(int64(i)))
+		account, err := rlp.EncodeToBytes([]interface{}{
+			uint64(i),
+			new(big.Int).Mul(big.NewInt(int64(i)), big.NewInt(1e18)),
+			types.EmptyRootHash,
+			types.EmptyCodeHash,
+		})
+		assert.NoError(t, err)
+		values[string(address.Bytes())] = account
+	}
+	tr := newTrie(t, values)
+	circuit := &testCircuit{Proof: eth.NewMPTProof(maxDepth, maxNodeLength, maxValueLength)}
+
+	address := common.BigToAddress(big.NewInt(7))
+	assignment := newProof(t, tr, address.Bytes(), values[string(address.Bytes())])
+	err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The proof must start at the root.
+	assignment.Proof.Root[0].Set(assignment.Proof.Root[0].GetValueUnsafe() ^ 1)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
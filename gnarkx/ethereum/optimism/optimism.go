@@ -0,0 +1,100 @@
+// The API for verifying the state of OP Stack chains against the output roots that their output
+// oracles or dispute games post on L1, such as the withdrawals initiated on L2.
+package optimism
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The address of the L2ToL1MessagePasser predeploy, whose sentMessages mapping at slot 0 is set to
+// true for the hash of every withdrawal initiated on L2.
+var MessagePasser = common.HexToAddress("0x4200000000000000000000000000000000000016")
+
+// The slot of the sentMessages mapping of the L2ToL1MessagePasser.
+const SentMessagesSlot = 0
+
+// OptimismAPI is a wrapper around succinct.API that provides methods for verifying output roots
+// and the withdrawals they commit to.
+type OptimismAPI struct {
+	api     builder.API
+	storage *storage.StorageAPI
+}
+
+// Creates a new OptimismAPI.
+func NewAPI(api *builder.API) *OptimismAPI {
+	return &OptimismAPI{api: *api, storage: storage.NewAPI(api)}
+}
+
+// The preimage of an output root of version 0, the only version, of an L2 block.
+type OutputRootProof struct {
+	Version                  [32]vars.Byte
+	StateRoot                [32]vars.Byte
+	MessagePasserStorageRoot [32]vars.Byte
+	LatestBlockhash          [32]vars.Byte
+}
+
+// Sets the preimage of an output root of version 0.
+func (p *OutputRootProof) Set(stateRoot common.Hash, messagePasserStorageRoot common.Hash, latestBlockhash common.Hash) {
+	vars.SetBytes32(&p.Version, [32]byte{})
+	vars.SetBytes32(&p.StateRoot, stateRoot)
+	vars.SetBytes32(&p.MessagePasserStorageRoot, messagePasserStorageRoot)
+	vars.SetBytes32(&p.LatestBlockhash, latestBlockhash)
+}
+
+// Returns the output root of version 0 of an L2 block, keccak256(version || stateRoot ||
+// messagePasserStorageRoot || latestBlockhash).
+func OutputRoot(stateRoot common.Hash, messagePasserStorageRoot common.Hash, latestBlockhash common.Hash) common.Hash {
+	var version common.Hash
+	return crypto.Keccak256Hash(version[:], stateRoot[:], messagePasserStorageRoot[:], latestBlockhash[:])
+}
+
+// Returns the storage slot of the L2ToL1MessagePasser that is set for a withdrawal.
+func WithdrawalSlot(withdrawalHash common.Hash) common.Hash {
+	slot := common.BigToHash(big.NewInt(SentMessagesSlot))
+	return crypto.Keccak256Hash(withdrawalHash[:], slot[:])
+}
+
+// Recomputes the output root of the preimage.
+func (a *OptimismAPI) OutputRoot(proof OutputRootProof) [32]vars.Byte {
+	in := append(proof.Version[:], proof.StateRoot[:]...)
+	in = append(in, proof.MessagePasserStorageRoot[:]...)
+	in = append(in, proof.LatestBlockhash[:]...)
+	return keccak256.Hash(a.api, in)
+}
+
+// Verifies that the preimage is of the output root and has version 0.
+func (a *OptimismAPI) VerifyOutputRoot(outputRoot [32]vars.Byte, proof OutputRootProof) {
+	api := a.api
+	root := a.OutputRoot(proof)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(proof.Version[i].Value, vars.NewVariableFromInt(0))
+		api.AssertIsEqualByte(root[i], outputRoot[i])
+	}
+}
+
+// Verifies that the withdrawal with the hash was initiated in the L2 block of the output root,
+// given the preimage of the output root and the proof of the slot of the withdrawal in the storage
+// of the L2ToL1MessagePasser, such as a storage proof of eth.Client for WithdrawalSlot.
+func (a *OptimismAPI) VerifyWithdrawal(
+	outputRoot [32]vars.Byte,
+	proof OutputRootProof,
+	withdrawalHash [32]vars.Byte,
+	storageProof eth.MPTProof,
+) {
+	api := a.api
+	a.VerifyOutputRoot(outputRoot, proof)
+	slot := a.storage.MappingSlot(storage.ConstantSlot(big.NewInt(SentMessagesSlot)), withdrawalHash)
+	value := a.storage.VerifyStorage(proof.MessagePasserStorageRoot, slot, storageProof)
+	for i := 0; i < 31; i++ {
+		api.AssertIsEqual(value[i].Value, vars.NewVariableFromInt(0))
+	}
+	api.AssertIsEqual(value[31].Value, vars.NewVariableFromInt(1))
+}
@@ -0,0 +1,73 @@
+package optimism
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	OutputRoot     [32]vars.Byte
+	Proof          OutputRootProof
+	WithdrawalHash [32]vars.Byte
+	StorageProof   eth.MPTProof
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).VerifyWithdrawal(c.OutputRoot, c.Proof, c.WithdrawalHash, c.StorageProof)
+	return nil
+}
+
+func TestVerifyWithdrawal(t *testing.T) {
+	withdrawals := []common.Hash{
+		crypto.Keccak256Hash([]byte("withdrawal 0")),
+		crypto.Keccak256Hash([]byte("withdrawal 1")),
+		crypto.Keccak256Hash([]byte("withdrawal 2")),
+	}
+	storage := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	value, err := rlp.EncodeToBytes(big.NewInt(1))
+	assert.NoError(t, err)
+	for _, w := range withdrawals {
+		slot := WithdrawalSlot(w)
+		storage.MustUpdate(crypto.Keccak256(slot[:]), value)
+	}
+	slot := WithdrawalSlot(withdrawals[1])
+	var nodes eth.ProofNodes
+	assert.NoError(t, storage.Prove(crypto.Keccak256(slot[:]), 0, &nodes))
+	storageProof := eth.NewMPTProof(4, eth.MaxNodeLength, eth.MaxStorageValueLength)
+	assert.NoError(t, storageProof.Set(storage.Hash(), slot[:], value, nodes))
+
+	stateRoot := common.HexToHash("0x01")
+	blockHash := common.HexToHash("0x02")
+	circuit := &testCircuit{StorageProof: eth.NewMPTProof(4, eth.MaxNodeLength, eth.MaxStorageValueLength)}
+	assignment := &testCircuit{StorageProof: storageProof}
+	assignment.Proof.Set(stateRoot, storage.Hash(), blockHash)
+	vars.SetBytes32(&assignment.OutputRoot, OutputRoot(stateRoot, storage.Hash(), blockHash))
+	vars.SetBytes32(&assignment.WithdrawalHash, withdrawals[1])
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The proof must be of the slot of the withdrawal.
+	vars.SetBytes32(&assignment.WithdrawalHash, withdrawals[2])
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The preimage must be of the output root.
+	vars.SetBytes32(&assignment.WithdrawalHash, withdrawals[1])
+	vars.SetBytes32(&assignment.OutputRoot, OutputRoot(blockHash, storage.Hash(), stateRoot))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
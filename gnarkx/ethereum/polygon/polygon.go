@@ -0,0 +1,449 @@
+// The API for verifying the checkpoints of Polygon PoS, which the Heimdall validators sign and
+// submit to the RootChain contract on Ethereum, and the Bor blocks they commit to, so that proofs
+// against the state of Bor blocks can be anchored to the checkpoints on Ethereum.
+//
+// As in RootChain.submitCheckpoint and StakeManager.checkSignatures, the validators sign the vote
+// hash keccak256(0x01 || abi.encode(proposer, start, end, rootHash, accountRootHash, borChainID))
+// and the signers must hold more than two thirds of the total stake. The root hash of a checkpoint
+// is the root of the binary Merkle tree of keccak256(left || right) over the leaves
+// keccak256(abi.encodePacked(number, time, txRoot, receiptRoot)) of the blocks from start to end,
+// right padded with zero leaves to a power of 2. Reference:
+// https://github.com/maticnetwork/contracts/blob/main/contracts/root/RootChain.sol
+package polygon
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The length of a signature r || s || v of a validator.
+const SignatureLength = 65
+
+// PolygonAPI is a wrapper around succinct.API that provides methods for verifying checkpoints and
+// the Bor blocks they commit to.
+type PolygonAPI struct {
+	api builder.API
+}
+
+// Creates a new PolygonAPI.
+func NewAPI(api *builder.API) *PolygonAPI {
+	return &PolygonAPI{api: *api}
+}
+
+// A checkpoint as submitted to the RootChain.
+type CheckpointData struct {
+	Proposer        common.Address
+	Start           uint64
+	End             uint64
+	RootHash        common.Hash
+	AccountRootHash common.Hash
+	BorChainID      uint64
+}
+
+// A Bor block as committed to by the root hash of checkpoints.
+type Block struct {
+	Number      uint64
+	Time        uint64
+	TxHash      common.Hash
+	ReceiptHash common.Hash
+}
+
+// A validator of the current validator set of the StakeManager, with its total stake including
+// delegations.
+type Validator struct {
+	Signer common.Address
+	Stake  *big.Int
+}
+
+// Returns the ABI encoding of the checkpoint, the data of submitCheckpoint.
+func (d *CheckpointData) Encode() []byte {
+	data := common.LeftPadBytes(d.Proposer.Bytes(), 32)
+	data = append(data, math.U256Bytes(new(big.Int).SetUint64(d.Start))...)
+	data = append(data, math.U256Bytes(new(big.Int).SetUint64(d.End))...)
+	data = append(data, d.RootHash[:]...)
+	data = append(data, d.AccountRootHash[:]...)
+	return append(data, math.U256Bytes(new(big.Int).SetUint64(d.BorChainID))...)
+}
+
+// Returns the hash the validators sign for the checkpoint.
+func (d *CheckpointData) VoteHash() common.Hash {
+	return crypto.Keccak256Hash([]byte{0x01}, d.Encode())
+}
+
+// Returns the leaf of the block in the root hash of checkpoints.
+func (b *Block) Leaf() common.Hash {
+	return crypto.Keccak256Hash(
+		math.U256Bytes(new(big.Int).SetUint64(b.Number)),
+		math.U256Bytes(new(big.Int).SetUint64(b.Time)),
+		b.TxHash[:],
+		b.ReceiptHash[:],
+	)
+}
+
+// Returns the root hash of a checkpoint of the consecutive blocks.
+func RootHash(blocks []Block) common.Hash {
+	nodes := leaves(blocks)
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = crypto.Keccak256Hash(nodes[2*i][:], nodes[2*i+1][:])
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
+
+// Returns the siblings of the i-th of the consecutive blocks in the tree of their root hash, from
+// the leaf to the root.
+func BlockProofOf(blocks []Block, i int) []common.Hash {
+	nodes := leaves(blocks)
+	var siblings []common.Hash
+	for len(nodes) > 1 {
+		siblings = append(siblings, nodes[i^1])
+		for j := 0; j < len(nodes)/2; j++ {
+			nodes[j] = crypto.Keccak256Hash(nodes[2*j][:], nodes[2*j+1][:])
+		}
+		nodes = nodes[:len(nodes)/2]
+		i /= 2
+	}
+	return siblings
+}
+
+// Returns the leaves of the blocks, padded with zeros to a power of 2.
+func leaves(blocks []Block) []common.Hash {
+	n := 1
+	for n < len(blocks) {
+		n *= 2
+	}
+	nodes := make([]common.Hash, n)
+	for i := range blocks {
+		nodes[i] = blocks[i].Leaf()
+	}
+	return nodes
+}
+
+// Returns the signatures of the vote hash aligned with the validators, where validators without a
+// signature have none, as the circuit reads them. Signatures by other signers are ignored, as in
+// checkSignatures.
+func AlignSignatures(validators []Validator, voteHash common.Hash, signatures [][]byte) ([][]byte, error) {
+	aligned := make([][]byte, len(validators))
+	for i, signature := range signatures {
+		if len(signature) != SignatureLength || (signature[64] != 27 && signature[64] != 28) {
+			return nil, fmt.Errorf("signature %d is invalid", i)
+		}
+		sig := append(append([]byte{}, signature[:64]...), signature[64]-27)
+		key, err := crypto.SigToPub(voteHash[:], sig)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+		signer := crypto.PubkeyToAddress(*key)
+		for j := range validators {
+			if validators[j].Signer == signer {
+				aligned[j] = signature
+			}
+		}
+	}
+	return aligned, nil
+}
+
+// A checkpoint in the circuit.
+type Checkpoint struct {
+	Proposer        [20]vars.Byte
+	Start           vars.U64
+	End             vars.U64
+	RootHash        [32]vars.Byte
+	AccountRootHash [32]vars.Byte
+	BorChainID      vars.U64
+}
+
+// Sets the checkpoint.
+func (c *Checkpoint) Set(data *CheckpointData) {
+	for i := 0; i < 20; i++ {
+		c.Proposer[i].Set(data.Proposer[i])
+	}
+	c.Start.Set(data.Start)
+	c.End.Set(data.End)
+	vars.SetBytes32(&c.RootHash, data.RootHash)
+	vars.SetBytes32(&c.AccountRootHash, data.AccountRootHash)
+	c.BorChainID.Set(data.BorChainID)
+}
+
+// The current validator set in the circuit, sorted by ascending signer address, of Size validators
+// followed by padding.
+type ValidatorSet struct {
+	Size    vars.Variable
+	Signers [][20]vars.Byte
+	Stakes  []vars.Variable
+}
+
+// Creates a new validator set of at most maxValidators validators.
+func NewValidatorSet(maxValidators int) ValidatorSet {
+	return ValidatorSet{
+		Size:    vars.NewVariable(),
+		Signers: make([][20]vars.Byte, maxValidators),
+		Stakes:  make([]vars.Variable, maxValidators),
+	}
+}
+
+// Sets the validators, which must be sorted by ascending signer address and have stakes of at
+// most 128 bits.
+func (s *ValidatorSet) Set(validators []Validator) error {
+	if len(validators) == 0 || len(validators) > len(s.Signers) {
+		return fmt.Errorf("%d validators, expected between 1 and %d", len(validators), len(s.Signers))
+	}
+	for i, validator := range validators {
+		if i > 0 && bytes.Compare(validators[i-1].Signer[:], validator.Signer[:]) >= 0 {
+			return fmt.Errorf("validator %d is not in ascending order", i)
+		}
+		if validator.Stake.Sign() < 0 || validator.Stake.BitLen() > 128 {
+			return fmt.Errorf("validator %d has stake %s", i, validator.Stake)
+		}
+	}
+	s.Size = vars.NewVariableFromInt(len(validators))
+	for i := 0; i < len(s.Signers); i++ {
+		var validator Validator
+		if i < len(validators) {
+			validator = validators[i]
+		} else {
+			validator.Stake = new(big.Int)
+		}
+		for j := 0; j < 20; j++ {
+			s.Signers[i][j].Set(validator.Signer[j])
+		}
+		s.Stakes[i].Set(validator.Stake)
+	}
+	return nil
+}
+
+// The signature of a validator in the circuit, if it signed.
+type Signature struct {
+	Signed vars.Bool
+	R      [32]vars.Byte
+	S      [32]vars.Byte
+	V      vars.Variable
+}
+
+// Creates new signatures of the validators of a set of at most maxValidators validators.
+func NewSignatures(maxValidators int) []Signature {
+	signatures := make([]Signature, maxValidators)
+	for i := 0; i < maxValidators; i++ {
+		signatures[i] = Signature{
+			Signed: vars.NewBool(false),
+			R:      vars.NewBytes32(),
+			S:      vars.NewBytes32(),
+			V:      vars.NewVariable(),
+		}
+	}
+	return signatures
+}
+
+// Sets the signatures, aligned with the validators as by AlignSignatures.
+func SetSignatures(s *[]Signature, signatures [][]byte) {
+	if len(signatures) > len(*s) {
+		panic(fmt.Sprintf("%d signatures, expected at most %d", len(signatures), len(*s)))
+	}
+	for i := 0; i < len(*s); i++ {
+		signature := &(*s)[i]
+		if i >= len(signatures) || signatures[i] == nil {
+			*signature = NewSignatures(1)[0]
+			continue
+		}
+		signature.Signed = vars.NewBool(true)
+		vars.SetBytes32(&signature.R, [32]byte(signatures[i][:32]))
+		vars.SetBytes32(&signature.S, [32]byte(signatures[i][32:64]))
+		signature.V = vars.NewVariableFromInt(int(signatures[i][64]))
+	}
+}
+
+// A proof of the inclusion of a Bor block in the root hash of a checkpoint in the circuit, with
+// the depth of the tree of the checkpoint.
+type BlockProof struct {
+	Number      vars.U64
+	Time        vars.U64
+	TxHash      [32]vars.Byte
+	ReceiptHash [32]vars.Byte
+	Depth       vars.Variable
+	Siblings    [][32]vars.Byte
+}
+
+// Creates a new block proof for checkpoints of at most 2^maxDepth blocks.
+func NewBlockProof(maxDepth int) BlockProof {
+	return BlockProof{
+		Number:      vars.NewU64(),
+		Time:        vars.NewU64(),
+		TxHash:      vars.NewBytes32(),
+		ReceiptHash: vars.NewBytes32(),
+		Depth:       vars.NewVariable(),
+		Siblings:    vars.NewBytes32Array(maxDepth),
+	}
+}
+
+// Sets the proof of the i-th of the consecutive blocks of a checkpoint.
+func (p *BlockProof) Set(blocks []Block, i int) error {
+	siblings := BlockProofOf(blocks, i)
+	if len(siblings) > len(p.Siblings) {
+		return fmt.Errorf("%d blocks, expected at most %d", len(blocks), 1<<len(p.Siblings))
+	}
+	p.Number.Set(blocks[i].Number)
+	p.Time.Set(blocks[i].Time)
+	vars.SetBytes32(&p.TxHash, blocks[i].TxHash)
+	vars.SetBytes32(&p.ReceiptHash, blocks[i].ReceiptHash)
+	p.Depth = vars.NewVariableFromInt(len(siblings))
+	padded := make([][32]byte, len(p.Siblings))
+	for j := range siblings {
+		padded[j] = siblings[j]
+	}
+	vars.SetBytes32Array(&p.Siblings, padded)
+	return nil
+}
+
+// Returns the vote hash of the checkpoint.
+func (a *PolygonAPI) VoteHash(checkpoint Checkpoint) [32]vars.Byte {
+	api := a.api
+	data := []vars.Byte{{Value: vars.NewVariableFromInt(1)}}
+	proposer := storage.AddressWord(checkpoint.Proposer)
+	data = append(data, proposer[:]...)
+	data = append(data, a.uint64Word(checkpoint.Start)...)
+	data = append(data, a.uint64Word(checkpoint.End)...)
+	data = append(data, checkpoint.RootHash[:]...)
+	data = append(data, checkpoint.AccountRootHash[:]...)
+	data = append(data, a.uint64Word(checkpoint.BorChainID)...)
+	return keccak256.Hash(api, data)
+}
+
+// Verifies that validators of the set holding more than two thirds of its total stake signed the
+// checkpoint. The validator set must be the current one of the StakeManager, which the caller
+// verifies, for instance against the storage of the StakeManager or a commitment in the input.
+func (a *PolygonAPI) VerifyCheckpoint(checkpoint Checkpoint, validators ValidatorSet, signatures []Signature) {
+	api := a.api
+	fapi := api.FrontendAPI()
+	if len(validators.Signers) != len(validators.Stakes) || len(signatures) != len(validators.Signers) {
+		panic(fmt.Sprintf("%d signers, %d stakes and %d signatures", len(validators.Signers), len(validators.Stakes), len(signatures)))
+	}
+	one := vars.NewVariableFromInt(1)
+	zero := vars.NewVariableFromInt(0)
+	api.AssertIsDifferent(validators.Size, zero)
+	api.AssertIsLessOrEqual(validators.Size, vars.NewVariableFromInt(len(validators.Signers)))
+	voteHash := a.VoteHash(checkpoint)
+
+	var dummyHash, dummyR, dummyS [32]vars.Byte
+	copy(dummyHash[:], vars.NewBytesFrom(dummyDigest))
+	copy(dummyR[:], vars.NewBytesFrom(dummySignature[:32]))
+	copy(dummyS[:], vars.NewBytesFrom(dummySignature[32:64]))
+	dummyV := vars.NewVariableFromInt(int(dummySignature[64]))
+
+	active := one
+	last := zero
+	totalStake := zero
+	signedStake := zero
+	for i, signature := range signatures {
+		if i > 0 {
+			active = api.Sub(active, api.IsZero(api.Sub(validators.Size, vars.NewVariableFromInt(i))).Value)
+		}
+		for j := 0; j < 20; j++ {
+			api.ToBinaryLE(validators.Signers[i][j].Value, 8)
+		}
+		signer := api.ToVariableFromBytesBE(validators.Signers[i][:])
+		if i > 0 {
+			next := api.Add(last, one)
+			api.AssertIsLessOrEqual(next, api.Select(vars.Bool{Value: active}, signer, next))
+		}
+		last = signer
+		api.ToBinaryLE(validators.Stakes[i], 128)
+		totalStake = api.Add(totalStake, api.Mul(active, validators.Stakes[i]))
+
+		// Validators that did not sign recover a constant signature instead.
+		signed := signature.Signed
+		fapi.AssertIsBoolean(signed.Value.Value)
+		api.AssertIsEqual(api.Mul(signed.Value, api.Sub(one, active)), zero)
+		signedStake = api.Add(signedStake, api.Mul(signed.Value, validators.Stakes[i]))
+		digest := api.SelectBytes32(signed, voteHash, dummyHash)
+		x, y := compat.ECRecover(
+			api,
+			digest,
+			api.Select(signed, signature.V, dummyV),
+			api.SelectBytes32(signed, signature.R, dummyR),
+			api.SelectBytes32(signed, signature.S, dummyS),
+		)
+		key := keccak256.Hash(api, append(x[:], y[:]...))
+		api.AssertIsEqual(api.Mul(signed.Value, api.Sub(api.ToVariableFromBytesBE(key[12:]), signer)), zero)
+	}
+
+	// The signed stake is at least floor(2 * total / 3) + 1.
+	api.AssertIsLessOrEqual(
+		api.Add(api.Mul(totalStake, vars.NewVariableFromInt(2)), one),
+		api.Mul(signedStake, vars.NewVariableFromInt(3)),
+	)
+}
+
+// Verifies that the block of the proof is in the root hash of the checkpoint.
+func (a *PolygonAPI) VerifyBlock(checkpoint Checkpoint, proof BlockProof) {
+	api := a.api
+	one := vars.NewVariableFromInt(1)
+	zero := vars.NewVariableFromInt(0)
+	maxDepth := len(proof.Siblings)
+
+	// The depth is the log2 of the number of blocks rounded up to a power of 2.
+	nbBlocks := api.Add(api.Sub(checkpoint.End.Value, checkpoint.Start.Value), one)
+	api.ToBinaryLE(api.Sub(nbBlocks, one), 64)
+	isDepth := make([]vars.Bool, maxDepth+1)
+	nbLeaves := zero
+	sum := zero
+	for k := 0; k <= maxDepth; k++ {
+		isDepth[k] = api.IsZero(api.Sub(proof.Depth, vars.NewVariableFromInt(k)))
+		nbLeaves = api.Add(nbLeaves, api.Mul(isDepth[k].Value, vars.NewVariableFromInt(1<<k)))
+		sum = api.Add(sum, isDepth[k].Value)
+	}
+	api.AssertIsEqual(sum, one)
+	api.AssertIsLessOrEqual(nbBlocks, nbLeaves)
+	api.AssertIsLessOrEqual(api.Add(nbLeaves, one), api.Mul(nbBlocks, vars.NewVariableFromInt(2)))
+
+	// The block is between the start and the end of the checkpoint.
+	index := api.Sub(proof.Number.Value, checkpoint.Start.Value)
+	api.ToBinaryLE(api.Sub(checkpoint.End.Value, proof.Number.Value), 64)
+	bits := api.ToBinaryLE(index, maxDepth)
+
+	leaf := append(a.uint64Word(proof.Number), a.uint64Word(proof.Time)...)
+	leaf = append(append(leaf, proof.TxHash[:]...), proof.ReceiptHash[:]...)
+	hash := keccak256.Hash(api, leaf)
+	root := hash
+	for k := 0; k < maxDepth; k++ {
+		left := keccak256.Hash(api, append(append([]vars.Byte{}, proof.Siblings[k][:]...), hash[:]...))
+		right := keccak256.Hash(api, append(append([]vars.Byte{}, hash[:]...), proof.Siblings[k][:]...))
+		hash = api.SelectBytes32(bits[k], left, right)
+		root = api.SelectBytes32(isDepth[k+1], hash, root)
+	}
+	api.AssertIsEqualBytes32(root, checkpoint.RootHash)
+}
+
+// Returns the ABI word of a u64.
+func (a *PolygonAPI) uint64Word(v vars.U64) []vars.Byte {
+	word := vars.ReverseBytes32(a.api.ToBytes32FromU64LE(v))
+	return word[:]
+}
+
+// A valid signature of a constant hash, recovered in place of missing signatures.
+var (
+	dummyDigest    = crypto.Keccak256([]byte("polygon"))
+	dummySignature = func() []byte {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{1}, 32))
+		if err != nil {
+			panic(err)
+		}
+		signature, err := crypto.Sign(dummyDigest, key)
+		if err != nil {
+			panic(err)
+		}
+		signature[64] += 27
+		return signature
+	}()
+)
@@ -0,0 +1,116 @@
+package polygon
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+type testCircuit struct {
+	Checkpoint Checkpoint
+	Validators ValidatorSet
+	Signatures []Signature
+	Block      BlockProof
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	polygonAPI := NewAPI(api)
+	polygonAPI.VerifyCheckpoint(c.Checkpoint, c.Validators, c.Signatures)
+	polygonAPI.VerifyBlock(c.Checkpoint, c.Block)
+	return nil
+}
+
+func newTestCircuit() *testCircuit {
+	return &testCircuit{
+		Validators: NewValidatorSet(4),
+		Signatures: NewSignatures(4),
+		Block:      NewBlockProof(4),
+	}
+}
+
+// Returns the keys and the validators of a set of three validators sorted by signer.
+func testValidators(t *testing.T) ([]*ecdsa.PrivateKey, []Validator) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{byte(i + 2)}, 32))
+		assert.NoError(t, err)
+		keys[i] = key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(keys[i].PublicKey), crypto.PubkeyToAddress(keys[j].PublicKey)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	validators := make([]Validator, len(keys))
+	for i := 0; i < len(keys); i++ {
+		stake := new(big.Int).Mul(big.NewInt(int64(10*(i+1))), big.NewInt(1e18))
+		validators[i] = Validator{Signer: crypto.PubkeyToAddress(keys[i].PublicKey), Stake: stake}
+	}
+	return keys, validators
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	keys, validators := testValidators(t)
+	blocks := make([]Block, 5)
+	for i := 0; i < len(blocks); i++ {
+		blocks[i] = Block{
+			Number:      uint64(100 + i),
+			Time:        uint64(1700000000 + 2*i),
+			TxHash:      crypto.Keccak256Hash([]byte{byte(i), 1}),
+			ReceiptHash: crypto.Keccak256Hash([]byte{byte(i), 2}),
+		}
+	}
+	data := &CheckpointData{
+		Proposer:        validators[0].Signer,
+		Start:           100,
+		End:             104,
+		RootHash:        RootHash(blocks),
+		AccountRootHash: common.HexToHash("0x01"),
+		BorChainID:      137,
+	}
+	voteHash := data.VoteHash()
+	sign := func(signers ...int) [][]byte {
+		var signatures [][]byte
+		for _, i := range signers {
+			signature, err := crypto.Sign(voteHash[:], keys[i])
+			assert.NoError(t, err)
+			signature[64] += 27
+			signatures = append(signatures, signature)
+		}
+		aligned, err := AlignSignatures(validators, voteHash, signatures)
+		assert.NoError(t, err)
+		return aligned
+	}
+	newAssignment := func(signatures [][]byte, block int) *testCircuit {
+		assignment := newTestCircuit()
+		assignment.Checkpoint.Set(data)
+		assert.NoError(t, assignment.Validators.Set(validators))
+		SetSignatures(&assignment.Signatures, signatures)
+		assert.NoError(t, assignment.Block.Set(blocks, block))
+		return assignment
+	}
+
+	// The second and third validators hold 50 of 60 of the stake.
+	assignment := newAssignment(sign(2, 1), 2)
+	assert.NoError(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The first and third validators hold 40 of 60, which is not more than two thirds.
+	assignment = newAssignment(sign(0, 2), 4)
+	assert.Error(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The block must be in the root hash.
+	assignment = newAssignment(sign(1, 2), 3)
+	blocks[3].TxHash = common.HexToHash("0x02")
+	assert.NoError(t, assignment.Block.Set(blocks, 3))
+	assert.Error(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+}
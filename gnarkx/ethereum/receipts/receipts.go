@@ -0,0 +1,146 @@
+// The API for verifying the receipts of the Ethereum execution layer against the receipts root of
+// a block and for reading the logs of the events they hold, as for cross-chain messages.
+package receipts
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/mpt"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// ReceiptsAPI is a wrapper around succinct.API that provides methods for verifying receipts and
+// reading their logs.
+type ReceiptsAPI struct {
+	api builder.API
+}
+
+// Creates a new ReceiptsAPI.
+func NewAPI(api *builder.API) *ReceiptsAPI {
+	return &ReceiptsAPI{api: *api}
+}
+
+// A Receipt is the consensus encoding of a receipt verified by VerifyReceipt.
+type Receipt struct {
+	// A table of the encoding, padded with 32 bytes before it and the padding given to
+	// VerifyReceipt after it.
+	Table *byteslice.Table
+
+	// Whether the transaction succeeded. It is never set for the receipts before Byzantium, which
+	// hold a state root instead of a status.
+	Status vars.Bool
+
+	// The list of the logs of the receipt.
+	Logs rlp.Item
+}
+
+// A Log of a receipt, whose data is an item of the table of the receipt.
+type Log struct {
+	Address [20]vars.Byte
+	Topics  [][32]vars.Byte
+	Data    rlp.Item
+}
+
+// Verifies the proof of the receipt of the transaction at txIndex, which must be less than 2^16,
+// against the receipts root of a block, and decodes the receipt. The table of the receipt is padded
+// with padding bytes after it, so that reads of up to padding bytes from its items are in range.
+func (a *ReceiptsAPI) VerifyReceipt(receiptsRoot [32]vars.Byte, txIndex vars.U64, proof eth.MPTProof, padding int) Receipt {
+	api := a.api
+	fapi := api.FrontendAPI()
+	api.AssertIsEqualBytes32(proof.Root, receiptsRoot)
+
+	// The key is the RLP encoding of the index: 0x80 for 0, the index for indices below 128 and
+	// the index prefixed by 0x81 or 0x82 for indices of 1 or 2 bytes.
+	bits := api.ToBinaryLE(txIndex.Value, 16)
+	var lowBits, highBits [8]vars.Bool
+	copy(lowBits[:], bits[:8])
+	copy(highBits[:], bits[8:])
+	low := api.ToByteFromBits(lowBits).Value.Value
+	high := api.ToByteFromBits(highBits).Value.Value
+	isZero := fapi.IsZero(txIndex.Value.Value)
+	isOneByte := fapi.IsZero(high)
+	isSingle := fapi.Mul(isOneByte, fapi.Sub(1, bits[7].Value.Value))
+	prefix := fapi.Select(isOneByte, 0x81, 0x82)
+	key := []frontend.Variable{
+		fapi.Select(isZero, 0x80, fapi.Select(isSingle, low, prefix)),
+		fapi.Select(isOneByte, fapi.Mul(fapi.Sub(1, isSingle), low), high),
+		fapi.Mul(fapi.Sub(1, isOneByte), low),
+	}
+	for i := 0; i < 32; i++ {
+		expected := frontend.Variable(0)
+		if i < len(key) {
+			expected = key[i]
+		}
+		fapi.AssertIsEqual(proof.Key[i].Value.Value, expected)
+	}
+	keyLength := fapi.Add(2, fapi.Mul(2, fapi.Sub(1, isSingle)), fapi.Mul(2, fapi.Sub(1, isOneByte)))
+	mpt.NewAPI(&api).VerifyProofWithKeyLength(proof, vars.Variable{Value: keyLength})
+
+	// A typed receipt is prefixed by its type, followed by [status, gasUsed, bloom, logs].
+	table := byteslice.NewTable(api, proof.Value, 32, padding)
+	isTyped := fapi.Sub(1, api.ToBitsFromByte(proof.Value[0])[7].Value.Value)
+	receipt := rlp.ReadItem(api, table, vars.Variable{Value: isTyped})
+	api.AssertIsEqual(receipt.IsList.Value, vars.NewVariableFromInt(1))
+	api.AssertIsEqual(receipt.End, proof.ValueLength)
+	status := rlp.ReadItem(api, table, receipt.Offset)
+	isSuccess := api.And(
+		api.IsZero(api.Sub(status.Length, vars.NewVariableFromInt(1))),
+		api.IsZero(api.Sub(table.At(status.Offset, 0).Value, vars.NewVariableFromInt(1))),
+	)
+	gasUsed := rlp.ReadItem(api, table, status.End)
+	bloom := rlp.ReadItem(api, table, gasUsed.End)
+	logs := rlp.ReadItem(api, table, bloom.End)
+	api.AssertIsEqual(logs.IsList.Value, vars.NewVariableFromInt(1))
+	api.AssertIsEqual(logs.End, receipt.End)
+	return Receipt{Table: table, Status: isSuccess, Logs: logs}
+}
+
+// Reads the log at logIndex of the receipt, which must be less than maxLogs and the number of logs
+// of the receipt, asserting that it has nbTopics topics.
+func (a *ReceiptsAPI) ReadLog(receipt Receipt, logIndex vars.Variable, maxLogs int, nbTopics int) Log {
+	api := a.api
+	fapi := api.FrontendAPI()
+	table := receipt.Table
+	zero := vars.NewVariableFromInt(0)
+
+	// Walk the logs up to the selected one, which must end within the list of logs.
+	offset := receipt.Logs.Offset
+	var log rlp.Item
+	log.Offset, log.End, log.IsList.Value = zero, zero, zero
+	isBefore := frontend.Variable(1)
+	for k := 0; k < maxLogs; k++ {
+		item := rlp.ReadItem(api, table, offset)
+		isSelected := fapi.IsZero(fapi.Sub(logIndex.Value, k))
+		log.Offset = api.Add(log.Offset, vars.Variable{Value: fapi.Mul(isSelected, item.Offset.Value)})
+		log.End = api.Add(log.End, vars.Variable{Value: fapi.Mul(isSelected, item.End.Value)})
+		log.IsList.Value = api.Add(log.IsList.Value, vars.Variable{Value: fapi.Mul(isSelected, item.IsList.Value.Value)})
+		isBefore = fapi.Sub(isBefore, isSelected)
+		offset = vars.Variable{Value: fapi.Add(offset.Value, fapi.Mul(isBefore, fapi.Sub(item.End.Value, offset.Value)))}
+	}
+	fapi.AssertIsEqual(isBefore, 0)
+	api.AssertIsEqual(log.IsList.Value, vars.NewVariableFromInt(1))
+	api.AssertIsLessOrEqual(log.End, receipt.Logs.End)
+
+	// A log is [address, topics, data].
+	var result Log
+	address := rlp.ReadItem(api, table, log.Offset)
+	api.AssertIsEqual(address.Length, vars.NewVariableFromInt(20))
+	copy(result.Address[:], table.Read(address.Offset, 20))
+	topics := rlp.ReadItem(api, table, address.End)
+	api.AssertIsEqual(topics.IsList.Value, vars.NewVariableFromInt(1))
+	api.AssertIsEqual(topics.Length, vars.NewVariableFromInt(33*nbTopics))
+	for i := 0; i < nbTopics; i++ {
+		topicOffset := api.Add(topics.Offset, vars.NewVariableFromInt(33*i))
+		fapi.AssertIsEqual(table.At(topicOffset, 0).Value.Value, 0xa0)
+		var topic [32]vars.Byte
+		copy(topic[:], table.Read(api.Add(topicOffset, vars.NewVariableFromInt(1)), 32))
+		result.Topics = append(result.Topics, topic)
+	}
+	result.Data = rlp.ReadItem(api, table, topics.End)
+	api.AssertIsEqual(result.Data.IsList.Value, zero)
+	api.AssertIsEqual(result.Data.End, log.End)
+	return result
+}
@@ -0,0 +1,141 @@
+package receipts
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	maxLogs       = 3
+	maxDataLength = 8
+)
+
+type testCircuit struct {
+	ReceiptsRoot [32]vars.Byte
+	TxIndex      vars.U64
+	Proof        eth.MPTProof
+	LogIndex     vars.Variable
+	Status       vars.Bool
+	Address      [20]vars.Byte
+	Topics       [2][32]vars.Byte
+	Data         [maxDataLength]vars.Byte
+	DataLength   vars.Variable
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	rc := NewAPI(api)
+	receipt := rc.VerifyReceipt(c.ReceiptsRoot, c.TxIndex, c.Proof, maxDataLength)
+	api.AssertIsEqualBool(receipt.Status, c.Status)
+	log := rc.ReadLog(receipt, c.LogIndex, maxLogs, len(c.Topics))
+	for i := 0; i < 20; i++ {
+		api.AssertIsEqualByte(log.Address[i], c.Address[i])
+	}
+	for i := range c.Topics {
+		api.AssertIsEqualBytes32(log.Topics[i], c.Topics[i])
+	}
+	api.AssertIsEqual(log.Data.Length, c.DataLength)
+	// The bytes past the data are those of the rest of the receipt.
+	data := receipt.Table.Read(log.Data.Offset, maxDataLength)
+	isData := vars.NewVariableFromInt(1)
+	for i := 0; i < maxDataLength; i++ {
+		isData = api.Sub(isData, api.IsZero(api.Sub(c.DataLength, vars.NewVariableFromInt(i))).Value)
+		api.AssertIsEqual(api.Mul(isData, api.Sub(data[i].Value, c.Data[i].Value)), vars.NewVariableFromInt(0))
+	}
+	return nil
+}
+
+func newCircuit() *testCircuit {
+	return &testCircuit{Proof: eth.NewMPTProof(6, 1040, 1024)}
+}
+
+func TestReceipts(t *testing.T) {
+	logs := []*types.Log{
+		{Address: common.HexToAddress("0x02"), Topics: []common.Hash{common.HexToHash("0x03")}, Data: []byte{1, 2, 3}},
+		{Address: common.HexToAddress("0x04"), Topics: []common.Hash{common.HexToHash("0x05"), common.HexToHash("0x06")}, Data: []byte{7, 8}},
+		{Address: common.HexToAddress("0x09"), Topics: []common.Hash{common.HexToHash("0x0a"), common.HexToHash("0x0b")}},
+	}
+
+	// The receipts of 300 transactions, so that indices take 0 to 2 bytes, where the receipts at
+	// even indices are typed and those at multiples of 3 failed.
+	receipts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	encoded := map[uint64][]byte{}
+	for i := uint64(0); i < 300; i++ {
+		receipt := &types.Receipt{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000 * (i + 1), Logs: logs}
+		if i%2 == 1 {
+			receipt.Type = types.LegacyTxType
+		}
+		if i%3 == 0 {
+			receipt.Status = types.ReceiptStatusFailed
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		value, err := receipt.MarshalBinary()
+		assert.NoError(t, err)
+		key, err := rlp.EncodeToBytes(uint(i))
+		assert.NoError(t, err)
+		receipts.MustUpdate(key, value)
+		encoded[i] = value
+	}
+
+	newAssignment := func(txIndex uint64, logIndex int) *testCircuit {
+		key, err := rlp.EncodeToBytes(uint(txIndex))
+		assert.NoError(t, err)
+		var nodes eth.ProofNodes
+		assert.NoError(t, receipts.Prove(key, 0, &nodes))
+		assignment := newCircuit()
+		assert.NoError(t, assignment.Proof.SetUnhashed(receipts.Hash(), key, encoded[txIndex], nodes))
+		vars.SetBytes32(&assignment.ReceiptsRoot, receipts.Hash())
+		assignment.TxIndex.Set(txIndex)
+		assignment.LogIndex = vars.NewVariableFromInt(logIndex)
+		assignment.Status = vars.NewBool(txIndex%3 != 0)
+		log := logs[logIndex]
+		for i := 0; i < 20; i++ {
+			assignment.Address[i].Set(log.Address[i])
+		}
+		for i := range assignment.Topics {
+			vars.SetBytes32(&assignment.Topics[i], log.Topics[i])
+		}
+		data := make([]byte, maxDataLength)
+		copy(data, log.Data)
+		for i := 0; i < maxDataLength; i++ {
+			assignment.Data[i].Set(data[i])
+		}
+		assignment.DataLength = vars.NewVariableFromInt(len(log.Data))
+		return assignment
+	}
+
+	for _, testCase := range []struct {
+		txIndex  uint64
+		logIndex int
+	}{{0, 1}, {1, 2}, {128, 2}, {299, 1}} {
+		assignment := newAssignment(testCase.txIndex, testCase.logIndex)
+		assert.NoError(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+	}
+
+	// The receipt must be at the index.
+	assignment := newAssignment(128, 1)
+	assignment.TxIndex.Set(129)
+	assert.Error(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The log must have the number of topics.
+	assignment = newAssignment(1, 1)
+	assignment.LogIndex = vars.NewVariableFromInt(0)
+	assert.Error(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// The status must match the receipt.
+	assignment = newAssignment(3, 1)
+	assignment.Status = vars.NewBool(true)
+	assert.Error(t, test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField()))
+}
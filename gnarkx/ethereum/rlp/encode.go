@@ -0,0 +1,112 @@
+package rlp
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An Encoded item is the RLP encoding of a string or a list of a length only known at proving
+// time: the first Length bytes of Data, where the bytes of Data past Length are zero.
+type Encoded = vars.VariableBytes
+
+// The maximum length of the content of an encoded item, which matches the 2 bytes of length of
+// ReadItem.
+const maxContentLength = 1<<16 - 1
+
+// Encodes the first length bytes of in as an RLP string, where length is at most len(in). The
+// bytes of in are assumed to be range checked, and the bytes past length are ignored.
+func EncodeString(api builder.API, in []vars.Byte, length vars.Variable) Encoded {
+	if len(in) > maxContentLength {
+		panic("string longer than the maximum length of an item")
+	}
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(len(in)))
+	content := Encoded{Data: make([]vars.Byte, len(in)), Length: length}
+	active := vars.NewVariableFromInt(1)
+	for i := 0; i < len(in); i++ {
+		active = api.Sub(active, api.IsZero(api.Sub(length, vars.NewVariableFromInt(i))).Value)
+		content.Data[i] = vars.Byte{Value: api.Mul(active, in[i].Value)}
+	}
+
+	// A single byte below 0x80 is its own encoding.
+	isSingle := vars.FALSE
+	if len(in) > 0 {
+		bits := api.ToBitsFromByte(content.Data[0])
+		isSingle = api.And(api.IsZero(api.Sub(length, vars.NewVariableFromInt(1))), api.Not(bits[7]))
+	}
+	return byteslice.Concat(api, encodePrefix(api, length, 0x80, isSingle), content)
+}
+
+// Encodes a u64 as an RLP string of its big-endian bytes without leading zeros, so that zero is
+// the empty string.
+func EncodeUint64(api builder.API, i1 vars.U64) Encoded {
+	bytes := api.ToBytes32FromU64LE(i1)
+	in := make([]vars.Byte, 8)
+	for i := 0; i < 8; i++ {
+		in[i] = bytes[7-i]
+	}
+	return encodeInteger(api, in)
+}
+
+// Encodes a big-endian uint256 as an RLP string of its bytes without leading zeros. The bytes are
+// assumed to be range checked.
+func EncodeUint256(api builder.API, i1 [32]vars.Byte) Encoded {
+	return encodeInteger(api, i1[:])
+}
+
+// Encodes the concatenation of the encoded items as an RLP list.
+func EncodeList(api builder.API, items []Encoded) Encoded {
+	content := byteslice.Concat(api, items...)
+	if len(content.Data) > maxContentLength {
+		panic("list longer than the maximum length of an item")
+	}
+	return byteslice.Concat(api, encodePrefix(api, content.Length, 0xc0, vars.FALSE), content)
+}
+
+// Encodes a big-endian integer without its leading zero bytes.
+func encodeInteger(api builder.API, in []vars.Byte) Encoded {
+	nbZeros := vars.NewVariableFromInt(0)
+	isLeading := vars.NewVariableFromInt(1)
+	for i := 0; i < len(in); i++ {
+		isLeading = api.Mul(isLeading, api.IsZero(in[i].Value).Value)
+		nbZeros = api.Add(nbZeros, isLeading)
+	}
+	table := byteslice.NewTable(api, in, 0, len(in))
+	return EncodeString(api, table.Read(nbZeros, len(in)), api.Sub(vars.NewVariableFromInt(len(in)), nbZeros))
+}
+
+// Returns the prefix of an item whose content has the length, where offset is 0x80 for strings
+// and 0xc0 for lists, or an empty prefix if isSingle is set.
+func encodePrefix(api builder.API, length vars.Variable, offset int, isSingle vars.Bool) Encoded {
+	bits := api.ToBinaryLE(length, 16)
+	low, high := vars.NewVariableFromInt(0), vars.NewVariableFromInt(0)
+	for i := 7; i >= 0; i-- {
+		low = api.Add(api.Mul(low, vars.TWO), bits[i].Value)
+		high = api.Add(api.Mul(high, vars.TWO), bits[i+8].Value)
+	}
+	isShort := isLess(api, length, 56)
+	isLong1 := api.And(api.Not(isShort), api.IsZero(high))
+	isLong2 := api.Not(api.Or(isShort, isLong1))
+
+	// A short item is prefixed by offset + length, and a long item by offset + 55 + the number of
+	// bytes of its length followed by its big-endian length.
+	first := api.Add(
+		api.Mul(isShort.Value, api.Add(vars.NewVariableFromInt(offset), length)),
+		api.Mul(isLong1.Value, vars.NewVariableFromInt(offset+56)),
+		api.Mul(isLong2.Value, vars.NewVariableFromInt(offset+57)),
+	)
+	first = api.Mul(api.Not(isSingle).Value, first)
+	second := api.Add(api.Mul(isLong1.Value, low), api.Mul(isLong2.Value, high))
+	third := api.Mul(isLong2.Value, low)
+	prefixLength := api.Add(api.Not(isSingle).Value, isLong1.Value, api.Mul(isLong2.Value, vars.TWO))
+	return Encoded{
+		Data:   []vars.Byte{{Value: first}, {Value: second}, {Value: third}},
+		Length: prefixLength,
+	}
+}
+
+// Returns whether a value of at most 16 bits is less than the bound.
+func isLess(api builder.API, i1 vars.Variable, bound int) vars.Bool {
+	bits := api.ToBinaryLE(api.Add(i1, vars.NewVariableFromInt(1<<16-bound)), 17)
+	return api.Not(bits[16])
+}
@@ -0,0 +1,87 @@
+package rlp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The circuit encodes the list [string, u64, u256] and hashes it, as the hash of a header.
+type testEncodeCircuit struct {
+	String   []vars.Byte
+	Length   vars.Variable
+	U64      vars.U64
+	U256     [32]vars.Byte
+	Expected []vars.Byte
+	Hash     [32]vars.Byte
+}
+
+func (c *testEncodeCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	encoded := EncodeList(*api, []Encoded{
+		EncodeString(*api, c.String, c.Length),
+		EncodeUint64(*api, c.U64),
+		EncodeUint256(*api, c.U256),
+	})
+	api.AssertIsEqual(encoded.Length, vars.NewVariableFromInt(len(c.Expected)))
+	for i := 0; i < len(c.Expected); i++ {
+		api.AssertIsEqualByte(encoded.Data[i], c.Expected[i])
+	}
+	hash := keccak256.HashVariable(*api, encoded.Data, encoded.Length)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(hash[i], c.Hash[i])
+	}
+	return nil
+}
+
+func TestEncode(t *testing.T) {
+	long := make([]byte, 300)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	large, _ := new(big.Int).SetString("8000000000000000000000000000000000000000000000000000000000000001", 16)
+	testCases := []struct {
+		string []byte
+		u64    uint64
+		u256   *big.Int
+	}{
+		{[]byte{}, 0, big.NewInt(0)},
+		{[]byte{0x05}, 127, big.NewInt(1)},
+		{[]byte{0x85}, 128, big.NewInt(0x80)},
+		{long[1:56], 1 << 40, large},
+		{long[:56], 1<<56 + 1, big.NewInt(1 << 20)},
+		{long, 255, big.NewInt(256)},
+	}
+	for _, testCase := range testCases {
+		expected, err := rlp.EncodeToBytes([]interface{}{testCase.string, testCase.u64, testCase.u256})
+		assert.NoError(t, err)
+		str := make([]byte, len(long)+1)
+		copy(str, testCase.string)
+		// The bytes past the length are ignored.
+		str[len(str)-1] = 0xff
+
+		circuit := testEncodeCircuit{String: vars.NewBytes(len(long) + 1), Expected: vars.NewBytes(len(expected))}
+		assignment := testEncodeCircuit{
+			String:   vars.NewBytesFrom(str),
+			Length:   vars.NewVariableFromInt(len(testCase.string)),
+			Expected: vars.NewBytesFrom(expected),
+		}
+		assignment.U64.Set(testCase.u64)
+		var u256 [32]byte
+		testCase.u256.FillBytes(u256[:])
+		vars.SetBytes32(&assignment.U256, u256)
+		var hash [32]byte
+		copy(hash[:], crypto.Keccak256(expected))
+		vars.SetBytes32(&assignment.Hash, hash)
+		assert.NoError(t, test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField()))
+	}
+}
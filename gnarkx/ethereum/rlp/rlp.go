@@ -0,0 +1,96 @@
+// The API for decoding RLP, the serialization method of the Ethereum execution layer, at positions
+// that are only known at proving time, and for encoding items of lengths that are only known at
+// proving time, e.g. to hash a header from its fields.
+// Reference: https://ethereum.org/en/developers/docs/data-structures-and-encoding/rlp/
+package rlp
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An Item is a decoded RLP item header: the position and length of the content of the item.
+type Item struct {
+	Offset vars.Variable
+	Length vars.Variable
+
+	// The position right after the item.
+	End vars.Variable
+
+	// Whether the item is a list rather than a string.
+	IsList vars.Bool
+}
+
+// Decodes the header of the item at offset. Long items may have up to 2 bytes of length, which
+// covers all items of blocks and tries; the circuit is unsatisfiable for longer items. The bytes
+// of the table must be range checked.
+func ReadItem(api builder.API, table *byteslice.Table, offset vars.Variable) Item {
+	fapi := api.FrontendAPI()
+	prefix := table.At(offset, 0)
+	bits := api.ToBitsFromByte(prefix)
+	b := func(i int) frontend.Variable {
+		return bits[i].Value.Value
+	}
+
+	// 0x00-0x7f is a single byte, 0x80-0xb7 and 0xc0-0xf7 are short strings and lists, and
+	// 0xb8-0xbf and 0xf8-0xff are long strings and lists.
+	isSingle := fapi.Sub(1, b(7))
+	isList := fapi.Mul(b(7), b(6))
+	isLong := fapi.Mul(b(7), fapi.Mul(b(5), fapi.Mul(b(4), b(3))))
+	fapi.AssertIsEqual(fapi.Mul(isLong, fapi.Add(b(2), b(1))), 0)
+	lengthOfLength := fapi.Mul(isLong, fapi.Add(1, b(0)))
+
+	shortLength := fapi.Sub(fapi.Sub(prefix.Value.Value, 0x80), fapi.Mul(isList, 0x40))
+	first := table.At(offset, 1).Value.Value
+	second := table.At(offset, 2).Value.Value
+	longLength := fapi.Select(b(0), fapi.Add(fapi.Mul(first, 256), second), first)
+
+	length := fapi.Select(isSingle, 1, fapi.Select(isLong, longLength, shortLength))
+	contentOffset := fapi.Add(offset.Value, fapi.Mul(fapi.Sub(1, isSingle), fapi.Add(1, lengthOfLength)))
+	return Item{
+		Offset: vars.Variable{Value: contentOffset},
+		Length: vars.Variable{Value: length},
+		End:    vars.Variable{Value: fapi.Add(contentOffset, length)},
+		IsList: vars.Bool{Value: vars.Variable{Value: isList}},
+	}
+}
+
+// Returns the value of a string item of up to 32 bytes as a big-endian bytes32, left padded with
+// zeros, as for the integers of accounts and storage slots. The circuit is unsatisfiable if the
+// item is a list or longer than 32 bytes. The table must be padded with at least 32 bytes before
+// the item.
+func ReadUint256(api builder.API, table *byteslice.Table, item Item) [32]vars.Byte {
+	fapi := api.FrontendAPI()
+	fapi.AssertIsEqual(item.IsList.Value.Value, 0)
+	api.AssertIsLessOrEqual(item.Length, vars.NewVariableFromInt(32))
+
+	// Byte i of the result is byte i - (32 - length) of the content.
+	start := api.Sub(item.Offset, api.Sub(vars.NewVariableFromInt(32), item.Length))
+	var result [32]vars.Byte
+	inContent := frontend.Variable(0)
+	for i := 0; i < 32; i++ {
+		inContent = fapi.Add(inContent, fapi.IsZero(fapi.Sub(item.Length.Value, 32-i)))
+		value := table.At(start, i).Value.Value
+		result[i] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inContent, value)}}
+	}
+	return result
+}
+
+// Returns the value of a string item of up to 8 bytes as a u64. The circuit is unsatisfiable if
+// the item is a list or its value does not fit in 64 bits. The table must be padded with at least
+// 32 bytes before the item.
+func ReadUint64(api builder.API, table *byteslice.Table, item Item) vars.U64 {
+	fapi := api.FrontendAPI()
+	value := ReadUint256(api, table, item)
+	result := frontend.Variable(0)
+	for i := 0; i < 32; i++ {
+		if i < 24 {
+			fapi.AssertIsEqual(value[i].Value.Value, 0)
+		} else {
+			result = fapi.Add(fapi.Mul(result, 256), value[i].Value.Value)
+		}
+	}
+	return vars.U64{Value: vars.Variable{Value: result}}
+}
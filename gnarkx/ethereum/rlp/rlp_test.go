@@ -0,0 +1,143 @@
+package rlp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testItemCircuit struct {
+	Data   []vars.Byte
+	Offset vars.Variable
+	Length vars.Variable
+	IsList vars.Variable
+}
+
+func (c *testItemCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	table := byteslice.NewTable(*api, c.Data, 0, 3)
+	item := ReadItem(*api, table, vars.NewVariableFromInt(0))
+	api.AssertIsEqual(item.Offset, c.Offset)
+	api.AssertIsEqual(item.Length, c.Length)
+	api.AssertIsEqual(item.End, api.Add(c.Offset, c.Length))
+	api.AssertIsEqual(item.IsList.Value, c.IsList)
+	return nil
+}
+
+func TestReadItem(t *testing.T) {
+	long := make([]byte, 300)
+	testCases := []struct {
+		value  interface{}
+		offset int
+		length int
+		isList int
+	}{
+		{uint64(5), 0, 1, 0},
+		{uint64(0), 1, 0, 0},
+		{long[:55], 1, 55, 0},
+		{long[:56], 2, 56, 0},
+		{long, 3, 300, 0},
+		{[]uint64{1, 2, 3}, 1, 3, 1},
+		{[][]byte{long[:100], long[:100]}, 2, 204, 1},
+		{[][]byte{long, long}, 3, 606, 1},
+	}
+	for _, testCase := range testCases {
+		encoded, err := rlp.EncodeToBytes(testCase.value)
+		assert.NoError(t, err)
+		circuit := testItemCircuit{Data: vars.NewBytes(len(encoded))}
+		assignment := testItemCircuit{
+			Data:   vars.NewBytesFrom(encoded),
+			Offset: vars.NewVariableFromInt(testCase.offset),
+			Length: vars.NewVariableFromInt(testCase.length),
+			IsList: vars.NewVariableFromInt(testCase.isList),
+		}
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+	}
+}
+
+type testUint256Circuit struct {
+	Data  []vars.Byte
+	Value [32]vars.Byte
+}
+
+func (c *testUint256Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	table := byteslice.NewTable(*api, c.Data, 32, 3)
+	item := ReadItem(*api, table, vars.NewVariableFromInt(0))
+	value := ReadUint256(*api, table, item)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(value[i], c.Value[i])
+	}
+	return nil
+}
+
+type testUint64Circuit struct {
+	Data  []vars.Byte
+	Value vars.U64
+}
+
+func (c *testUint64Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	table := byteslice.NewTable(*api, c.Data, 32, 3)
+	item := ReadItem(*api, table, vars.NewVariableFromInt(0))
+	api.AssertIsEqual(ReadUint64(*api, table, item).Value, c.Value.Value)
+	return nil
+}
+
+func TestReadUint256(t *testing.T) {
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(0x7f),
+		big.NewInt(0x80),
+		new(big.Int).Lsh(big.NewInt(3), 100),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+	}
+	for _, value := range values {
+		encoded, err := rlp.EncodeToBytes(value)
+		assert.NoError(t, err)
+		circuit := testUint256Circuit{Data: vars.NewBytes(len(encoded))}
+		assignment := testUint256Circuit{Data: vars.NewBytesFrom(encoded)}
+		vars.SetBytes32(&assignment.Value, [32]byte(value.FillBytes(make([]byte, 32))))
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+	}
+
+	// Lists are not integers.
+	encoded, err := rlp.EncodeToBytes([]uint64{1, 2})
+	assert.NoError(t, err)
+	circuit := testUint256Circuit{Data: vars.NewBytes(len(encoded))}
+	assignment := testUint256Circuit{Data: vars.NewBytesFrom(encoded)}
+	vars.SetBytes32(&assignment.Value, [32]byte{})
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+func TestReadUint64(t *testing.T) {
+	for _, value := range []uint64{0, 0x7f, 17000000, 1<<63 - 1} {
+		encoded, err := rlp.EncodeToBytes(value)
+		assert.NoError(t, err)
+		circuit := testUint64Circuit{Data: vars.NewBytes(len(encoded))}
+		assignment := testUint64Circuit{Data: vars.NewBytesFrom(encoded)}
+		assignment.Value.Set(value)
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+	}
+
+	// Values of more than 64 bits do not fit.
+	encoded, err := rlp.EncodeToBytes(new(big.Int).Lsh(big.NewInt(1), 64))
+	assert.NoError(t, err)
+	circuit := testUint64Circuit{Data: vars.NewBytes(len(encoded))}
+	assignment := testUint64Circuit{Data: vars.NewBytesFrom(encoded)}
+	assignment.Value.Set(0)
+	err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
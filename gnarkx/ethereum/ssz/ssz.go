@@ -5,6 +5,7 @@ package ssz
 import (
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
 	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sszutils"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
@@ -27,10 +28,7 @@ func (a *SimpleSerializeAPI) VerifyProof(
 	proof [][32]vars.Byte,
 	gindex int,
 ) {
-	restoredRoot := a.RestoreMerkleRoot(leaf, proof, gindex)
-	for i := 0; i < 32; i++ {
-		a.api.AssertIsEqual(root[i].Value, restoredRoot[i].Value)
-	}
+	a.api.AssertIsEqualBytes32(root, a.RestoreMerkleRoot(leaf, proof, gindex))
 }
 
 // Verifies an ssz proof with a gindex that is a circuit variable. Note that the depth of the proof
@@ -41,10 +39,7 @@ func (a *SimpleSerializeAPI) VerifyProofWithGIndexVariable(
 	proof [][32]vars.Byte,
 	gindex vars.U64,
 ) {
-	restoredRoot := a.RestoreMerkleRootWithGIndexVariable(leaf, proof, gindex)
-	for i := 0; i < 32; i++ {
-		a.api.AssertIsEqual(root[i].Value, restoredRoot[i].Value)
-	}
+	a.api.AssertIsEqualBytes32(root, a.RestoreMerkleRootWithGIndexVariable(leaf, proof, gindex))
 }
 
 func (a *SimpleSerializeAPI) RestoreMerkleRootWithGIndexVariable(
@@ -94,3 +89,81 @@ func (a *SimpleSerializeAPI) HashTreeRoot(
 	}
 	return leaves[0]
 }
+
+// Returns the root of a subtree of the given depth whose leaves are all zero, as a constant.
+func zeroHash(depth int) [32]vars.Byte {
+	hash := sszutils.ZeroHash(depth)
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		out[i] = vars.Byte{Value: vars.NewVariableFromInt(int(hash[i]))}
+	}
+	return out
+}
+
+// Computes the root of the chunks as a tree of limit leaves, rounded up to a power of 2, where
+// the leaves past the chunks are zero. The roots of the zero subtrees are constants, so only the
+// subtrees of the chunks are hashed. The root of a container is the root of the roots of its
+// fields with a limit of the number of fields, and the root of a vector is the root of its packed
+// chunks with a limit of the number of chunks.
+func (a *SimpleSerializeAPI) Merkleize(chunks [][32]vars.Byte, limit int) [32]vars.Byte {
+	if len(chunks) > limit {
+		panic("more chunks than the limit")
+	}
+	layer := append([][32]vars.Byte{}, chunks...)
+	for depth := 0; depth < sszutils.Depth(limit); depth++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHash(depth))
+		}
+		next := make([][32]vars.Byte, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Hash(a.api, append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	if len(layer) == 0 {
+		return zeroHash(sszutils.Depth(limit))
+	}
+	return layer[0]
+}
+
+// Mixes the length of a list into the root of its chunks.
+func (a *SimpleSerializeAPI) MixInLength(root [32]vars.Byte, length vars.U64) [32]vars.Byte {
+	lengthBytes := a.api.ToBytes32FromU64LE(length)
+	return sha256.Hash(a.api, append(root[:], lengthBytes[:]...))
+}
+
+// Computes the root of a list of length elements whose packed chunks fit in limit chunks, where
+// len(chunks) is the maximum number of chunks of the circuit. The chunks past the elements of the
+// list, and the bytes of its last chunk past its elements, must be zero.
+func (a *SimpleSerializeAPI) HashTreeRootList(chunks [][32]vars.Byte, limit int, length vars.U64) [32]vars.Byte {
+	return a.MixInLength(a.Merkleize(chunks, limit), length)
+}
+
+// Packs bytes into chunks, right padding the last chunk with zeros.
+func (a *SimpleSerializeAPI) PackBytes(data []vars.Byte) [][32]vars.Byte {
+	chunks := make([][32]vars.Byte, (len(data)+31)/32)
+	for i := range chunks {
+		for j := 0; j < 32; j++ {
+			chunks[i][j] = vars.ZERO_BYTE
+			if i*32+j < len(data) {
+				chunks[i][j] = data[i*32+j]
+			}
+		}
+	}
+	return chunks
+}
+
+// Packs u64s into chunks of four little-endian values.
+func (a *SimpleSerializeAPI) PackU64s(values []vars.U64) [][32]vars.Byte {
+	chunks := make([][32]vars.Byte, (len(values)+3)/4)
+	for i := range chunks {
+		for j := 0; j < 32; j++ {
+			chunks[i][j] = vars.ZERO_BYTE
+		}
+	}
+	for i := range values {
+		bytes := a.api.ToBytes32FromU64LE(values[i])
+		copy(chunks[i/4][(i%4)*8:], bytes[:8])
+	}
+	return chunks
+}
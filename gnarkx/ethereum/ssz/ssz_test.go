@@ -10,7 +10,9 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
 	"github.com/succinctlabs/succinctx/gnarkx/ethereum/ssz"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
 	"github.com/succinctlabs/succinctx/gnarkx/utils/byteutils"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sszutils"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
@@ -99,3 +101,101 @@ func TestCircuit(t *testing.T) {
 	// 	t.Errorf("badAssignment should be invalid")
 	// }
 }
+
+// Fuzzes proof verification with inputs of the form root || leaf || proof. Almost all mutations of
+// the seed are malformed proofs, which the circuit must reject.
+func FuzzVerifyProof(f *testing.F) {
+	testData := GetTestData()
+	nbBytes := (testData.depth + 2) * 32
+	split := func(in []byte) ([32]byte, [32]byte, [][32]byte) {
+		var root, leaf [32]byte
+		copy(root[:], in[0:32])
+		copy(leaf[:], in[32:64])
+		proof := make([][32]byte, testData.depth)
+		for i := 0; i < testData.depth; i++ {
+			copy(proof[i][:], in[64+i*32:64+(i+1)*32])
+		}
+		return root, leaf, proof
+	}
+
+	target := fuzz.Target{
+		Name: "ssz",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			var root, leaf [32]vars.Byte
+			copy(root[:], in[0:32])
+			copy(leaf[:], in[32:64])
+			proof := make([][32]vars.Byte, testData.depth)
+			for i := 0; i < testData.depth; i++ {
+				copy(proof[i][:], in[64+i*32:64+(i+1)*32])
+			}
+			ssz.NewAPI(&api).VerifyProof(root, leaf, proof, testData.gindex)
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			root, leaf, proof := split(in)
+			return []byte{}, sszutils.RestoreMerkleRoot(leaf, proof, testData.gindex) == root
+		},
+		ValidLength: func(n int) bool {
+			return n == nbBytes
+		},
+	}
+
+	seed := append(testData.root[:], testData.leaf[:]...)
+	for i := 0; i < testData.depth; i++ {
+		seed = append(seed, testData.proof[i][:]...)
+	}
+	target.Fuzz(f, seed)
+}
+
+// A container of a list of bytes of at most 256 bytes, a vector of five u64s and a bytes32.
+type TestMerkleizeCircuit struct {
+	Data     []vars.Byte
+	Length   vars.U64
+	Balances [5]vars.U64
+	Hash     [32]vars.Byte
+	Root     [32]vars.Byte
+}
+
+func (circuit *TestMerkleizeCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sszAPI := ssz.NewAPI(succinctAPI)
+	dataRoot := sszAPI.HashTreeRootList(sszAPI.PackBytes(circuit.Data), 8, circuit.Length)
+	balancesRoot := sszAPI.Merkleize(sszAPI.PackU64s(circuit.Balances[:]), 2)
+	root := sszAPI.Merkleize([][32]vars.Byte{dataRoot, balancesRoot, circuit.Hash}, 3)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(root[i], circuit.Root[i])
+	}
+	return nil
+}
+
+func TestMerkleize(t *testing.T) {
+	// The root of two zero chunks.
+	zero := sszutils.ZeroHash(1)
+	if hexutil.Encode(zero[:]) != "0xf5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a92759fb4b" {
+		t.Errorf("unexpected zero hash %x", zero)
+	}
+
+	// The circuit holds at most 96 bytes of the list, so that the last chunk is partially filled.
+	data := []byte("the quick brown fox jumps over the lazy dog, the quick brown fox jumps")
+	balances := []uint64{32000000000, 31999999999, 0, 1, 1 << 40}
+	hash := byteutils.ToBytes32FromBytes(hexutil.MustDecode("0x88475251bcec25245a44bddd92b2c36db6c9c48bc6d91b5d0da78af3229ff783"))
+	dataRoot := sszutils.MixInLength(sszutils.Merkleize(sszutils.PackBytes(data), 8), uint64(len(data)))
+	balancesRoot := sszutils.Merkleize(sszutils.PackU64s(balances), 2)
+	root := sszutils.Merkleize([][32]byte{dataRoot, balancesRoot, hash}, 3)
+
+	circuit := TestMerkleizeCircuit{Data: vars.NewBytes(96)}
+	assignment := TestMerkleizeCircuit{Data: vars.NewBytesFrom(append(data, make([]byte, 96-len(data))...))}
+	assignment.Length.Set(uint64(len(data)))
+	for i := range balances {
+		assignment.Balances[i].Set(balances[i])
+	}
+	vars.SetBytes32(&assignment.Hash, hash)
+	vars.SetBytes32(&assignment.Root, root)
+	if err := test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Error(err)
+	}
+
+	assignment.Length.Set(uint64(len(data) + 1))
+	if err := test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField()); err == nil {
+		t.Error("the root must depend on the length")
+	}
+}
@@ -0,0 +1,150 @@
+// The API for verifying the storage of the Ethereum execution layer: accounts in the state trie and
+// slots in the storage tries of accounts, against the state root of a header verified by the
+// header package, and the slots of the state variables of contracts.
+package storage
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/mpt"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// StorageAPI is a wrapper around succinct.API that provides methods for verifying accounts and
+// storage slots against a state root.
+type StorageAPI struct {
+	api builder.API
+}
+
+// Creates a new StorageAPI.
+func NewAPI(api *builder.API) *StorageAPI {
+	return &StorageAPI{api: *api}
+}
+
+// An account of the state trie.
+type Account struct {
+	Nonce       vars.U64
+	Balance     [32]vars.Byte
+	StorageRoot [32]vars.Byte
+	CodeHash    [32]vars.Byte
+}
+
+// Verifies the proof of the account at address against the state root and returns the account.
+// The account must exist.
+func (a *StorageAPI) VerifyAccount(stateRoot [32]vars.Byte, address [20]vars.Byte, proof eth.MPTProof) Account {
+	api := a.api
+	api.AssertIsEqualBytes32(proof.Root, stateRoot)
+	api.AssertIsEqualBytes32(proof.Key, keccak256.Hash(api, address[:]))
+	mpt.NewAPI(&api).VerifyProof(proof)
+
+	table := byteslice.NewTable(api, proof.Value, 32, 3)
+	list := rlp.ReadItem(api, table, vars.NewVariableFromInt(0))
+	api.AssertIsEqual(list.IsList.Value, vars.NewVariableFromInt(1))
+	api.AssertIsEqual(list.End, proof.ValueLength)
+	nonce := rlp.ReadItem(api, table, list.Offset)
+	balance := rlp.ReadItem(api, table, nonce.End)
+	storageRoot := rlp.ReadItem(api, table, balance.End)
+	codeHash := rlp.ReadItem(api, table, storageRoot.End)
+	api.AssertIsEqual(codeHash.End, list.End)
+	api.AssertIsEqual(storageRoot.Length, vars.NewVariableFromInt(32))
+	api.AssertIsEqual(codeHash.Length, vars.NewVariableFromInt(32))
+	return Account{
+		Nonce:       rlp.ReadUint64(api, table, nonce),
+		Balance:     rlp.ReadUint256(api, table, balance),
+		StorageRoot: rlp.ReadUint256(api, table, storageRoot),
+		CodeHash:    rlp.ReadUint256(api, table, codeHash),
+	}
+}
+
+// Verifies the proof of a storage slot against the storage root of an account and returns the
+// value of the slot, which is zero for slots that are not in the trie.
+func (a *StorageAPI) VerifyStorage(storageRoot [32]vars.Byte, slot [32]vars.Byte, proof eth.MPTProof) [32]vars.Byte {
+	api := a.api
+	api.AssertIsEqualBytes32(proof.Root, storageRoot)
+	api.AssertIsEqualBytes32(proof.Key, keccak256.Hash(api, slot[:]))
+	mpt.NewAPI(&api).VerifyProof(proof)
+
+	table := byteslice.NewTable(api, proof.Value, 32, 3)
+	item := rlp.ReadItem(api, table, vars.NewVariableFromInt(0))
+	api.AssertIsEqual(item.End, proof.ValueLength)
+	return rlp.ReadUint256(api, table, item)
+}
+
+// Verifies the proof of the account at address against the state root and the proof of the slot
+// against the storage root of the account, and returns the value of the slot. The account must
+// exist, while the slot may be empty.
+func (a *StorageAPI) VerifySlot(
+	stateRoot [32]vars.Byte,
+	address [20]vars.Byte,
+	slot [32]vars.Byte,
+	accountProof eth.MPTProof,
+	storageProof eth.MPTProof,
+) [32]vars.Byte {
+	account := a.VerifyAccount(stateRoot, address, accountProof)
+	return a.VerifyStorage(account.StorageRoot, slot, storageProof)
+}
+
+// Verifies that the slot of the contract at address holds value under the state root, with the
+// proof of the account and the proof of the slot, as StorageAPI.VerifySlot.
+func VerifySlot(
+	api builder.API,
+	stateRoot [32]vars.Byte,
+	address [20]vars.Byte,
+	slot [32]vars.Byte,
+	value [32]vars.Byte,
+	accountProof eth.MPTProof,
+	storageProof eth.MPTProof,
+) {
+	api.AssertIsEqualBytes32(NewAPI(&api).VerifySlot(stateRoot, address, slot, accountProof, storageProof), value)
+}
+
+// Returns the slot of the value of a mapping at slot under key, keccak256(key || slot), where
+// key is the key padded to 32 bytes as in the ABI encoding.
+func (a *StorageAPI) MappingSlot(slot [32]vars.Byte, key [32]vars.Byte) [32]vars.Byte {
+	return keccak256.Hash(a.api, append(key[:], slot[:]...))
+}
+
+// Returns slot + offset modulo 2^256, the slot of a field of a struct or an element of an array.
+func (a *StorageAPI) OffsetSlot(slot [32]vars.Byte, offset int) [32]vars.Byte {
+	api := a.api
+	fapi := api.FrontendAPI()
+	var offsetBytes [32]byte
+	big.NewInt(int64(offset)).FillBytes(offsetBytes[:])
+	var result [32]vars.Byte
+	carry := frontend.Variable(0)
+	for i := 31; i >= 0; i-- {
+		sum := fapi.Add(slot[i].Value.Value, int(offsetBytes[i]), carry)
+		bits := api.ToBinaryLE(vars.Variable{Value: sum}, 9)
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[:8])
+		result[i] = api.ToByteFromBits(byteBits)
+		carry = bits[8].Value.Value
+	}
+	return result
+}
+
+// Returns the slot of a 32 byte constant, such as the slot of a state variable.
+func ConstantSlot(slot *big.Int) [32]vars.Byte {
+	var result [32]vars.Byte
+	vars.SetBytes32(&result, [32]byte(slot.FillBytes(make([]byte, 32))))
+	return result
+}
+
+// Returns the ABI word of an address, the key of a mapping from addresses such as balances.
+func AddressWord(address [20]vars.Byte) [32]vars.Byte {
+	var word [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		if i < 12 {
+			word[i] = vars.Byte{Value: vars.NewVariableFromInt(0)}
+		} else {
+			word[i] = address[i-12]
+		}
+	}
+	return word
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	StateRoot    [32]vars.Byte
+	Address      [20]vars.Byte
+	AccountProof eth.MPTProof
+	StorageProof eth.MPTProof
+	Value        [32]vars.Byte
+	Balance      [32]vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	st := NewAPI(api)
+	account := st.VerifyAccount(c.StateRoot, c.Address, c.AccountProof)
+
+	// The value of balances[holder] for a mapping at slot 3, where the holder is the account.
+	slot := st.MappingSlot(ConstantSlot(big.NewInt(3)), AddressWord(c.Address))
+	value := st.VerifyStorage(account.StorageRoot, slot, c.StorageProof)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(value[i], c.Value[i])
+		api.AssertIsEqualByte(account.Balance[i], c.Balance[i])
+	}
+	return nil
+}
+
+type testSlotCircuit struct {
+	StateRoot    [32]vars.Byte
+	Address      [20]vars.Byte
+	Slot         [32]vars.Byte
+	Value        [32]vars.Byte
+	AccountProof eth.MPTProof
+	StorageProof eth.MPTProof
+}
+
+func (c *testSlotCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	VerifySlot(*api, c.StateRoot, c.Address, c.Slot, c.Value, c.AccountProof, c.StorageProof)
+	return nil
+}
+
+func newSlotCircuit() *testSlotCircuit {
+	return &testSlotCircuit{
+		AccountProof: eth.NewMPTProof(4, eth.MaxNodeLength, eth.MaxAccountLength),
+		StorageProof: eth.NewMPTProof(4, eth.MaxNodeLength, 33),
+	}
+}
+
+func newCircuit() *testCircuit {
+	return &testCircuit{
+		AccountProof: eth.NewMPTProof(4, eth.MaxNodeLength, eth.MaxAccountLength),
+		StorageProof: eth.NewMPTProof(4, eth.MaxNodeLength, 33),
+	}
+}
+
+func TestStorage(t *testing.T) {
+	address := common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	balance := big.NewInt(1234567890)
+	slot := crypto.Keccak256(common.LeftPadBytes(address.Bytes(), 32), common.LeftPadBytes([]byte{3}, 32))
+
+	storage := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := 0; i < 20; i++ {
+		value, err := rlp.EncodeToBytes(big.NewInt(int64(i + 1)))
+		assert.NoError(t, err)
+		storage.MustUpdate(crypto.Keccak256(common.BigToHash(big.NewInt(int64(i))).Bytes()), value)
+	}
+	value, err := rlp.EncodeToBytes(balance)
+	assert.NoError(t, err)
+	storage.MustUpdate(crypto.Keccak256(slot), value)
+
+	accounts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	var account []byte
+	for i := 0; i < 20; i++ {
+		a := common.BigToAddress(big.NewInt(int64(i)))
+		root := types.EmptyRootHash
+		if i == 0 {
+			a = address
+			root = storage.Hash()
+		}
+		encoded, err := rlp.EncodeToBytes([]interface{}{uint64(i), big.NewInt(int64(i) * 1000), root, types.EmptyCodeHash})
+		assert.NoError(t, err)
+		accounts.MustUpdate(crypto.Keccak256(a.Bytes()), encoded)
+		if i == 0 {
+			account = encoded
+		}
+	}
+
+	newAssignment := func() *testCircuit {
+		assignment := newCircuit()
+		vars.SetBytes32(&assignment.StateRoot, accounts.Hash())
+		for i := 0; i < 20; i++ {
+			assignment.Address[i].Set(address[i])
+		}
+		assignment.AccountProof = *functiontest.Prove(t, accounts, address.Bytes(), account, 4, eth.MaxNodeLength, eth.MaxAccountLength)
+		assignment.StorageProof = *functiontest.Prove(t, storage, slot, value, 4, eth.MaxNodeLength, 33)
+		vars.SetBytes32(&assignment.Value, [32]byte(balance.FillBytes(make([]byte, 32))))
+		vars.SetBytes32(&assignment.Balance, [32]byte{})
+		return assignment
+	}
+
+	assignment := newAssignment()
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The account proof must be against the state root.
+	assignment.StateRoot[0].Set(assignment.StateRoot[0].GetValueUnsafe() ^ 1)
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The storage proof must be against the storage root of the account.
+	assignment = newAssignment()
+	assignment.StorageProof = *functiontest.Prove(t, accounts, slot, value, 4, eth.MaxNodeLength, 33)
+	err = test.IsSolved(newCircuit(), assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The slot must hold the value.
+	assignment = newAssignment()
+	slotAssignment := &testSlotCircuit{
+		StateRoot:    assignment.StateRoot,
+		Address:      assignment.Address,
+		Value:        assignment.Value,
+		AccountProof: assignment.AccountProof,
+		StorageProof: assignment.StorageProof,
+	}
+	vars.SetBytes32(&slotAssignment.Slot, [32]byte(slot))
+	err = test.IsSolved(newSlotCircuit(), slotAssignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	slotAssignment.Value[31].Set(slotAssignment.Value[31].GetValueUnsafe() ^ 1)
+	err = test.IsSolved(newSlotCircuit(), slotAssignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+func TestOffsetSlot(t *testing.T) {
+	circuit := &testOffsetCircuit{}
+	slot := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(2))
+	assignment := &testOffsetCircuit{}
+	vars.SetBytes32(&assignment.Slot, [32]byte(slot.FillBytes(make([]byte, 32))))
+	expected := new(big.Int).Add(slot, big.NewInt(300))
+	vars.SetBytes32(&assignment.Expected, [32]byte(expected.FillBytes(make([]byte, 32))))
+	err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
+
+type testOffsetCircuit struct {
+	Slot     [32]vars.Byte
+	Expected [32]vars.Byte
+}
+
+func (c *testOffsetCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	result := NewAPI(api).OffsetSlot(c.Slot, 300)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(result[i], c.Expected[i])
+	}
+	return nil
+}
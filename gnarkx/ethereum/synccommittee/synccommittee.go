@@ -0,0 +1,92 @@
+// Commitments to the sync committee of the Beacon Chain, which the light client circuits use to
+// pass a committee from the circuit rotating to it to the circuits verifying its signatures
+// without rehashing the SSZ encoding of its 512 public keys with SHA-256.
+//
+// The Poseidon commitment to a committee is computed from the compressed BLS12-381 public keys of
+// its members, in the order of the committee:
+//
+//  1. Each public key of 48 bytes is split into two big-endian integers of 24 bytes, hi and lo,
+//     and its leaf is poseidon(hi, lo).
+//  2. The commitment is the root of the binary Merkle tree over the 512 leaves, where every node
+//     is poseidon(left, right).
+//
+// Poseidon is the hash of the poseidon package, which matches circomlib, and ComputeCommitment is
+// the reference implementation of the commitment outside of circuits.
+package synccommittee
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	// The number of validators of a sync committee.
+	Size = 512
+
+	// The length of a compressed BLS12-381 public key.
+	PubkeyLength = 48
+)
+
+// The length of each of the two halves of a public key, which are elements of the scalar field.
+const limbLength = PubkeyLength / 2
+
+// Computes the Poseidon commitment to the compressed public keys of a sync committee.
+func ComputeCommitment(pubkeys [Size][PubkeyLength]byte) *big.Int {
+	return ComputeCommitmentOf(pubkeys[:])
+}
+
+// Computes the Poseidon commitment to the compressed public keys of a committee of any size that
+// is a power of 2, such as the smaller committees of tests and devnets.
+func ComputeCommitmentOf(pubkeys [][PubkeyLength]byte) *big.Int {
+	checkSize(len(pubkeys))
+	nodes := make([]*big.Int, len(pubkeys))
+	for i := 0; i < len(pubkeys); i++ {
+		hi := new(big.Int).SetBytes(pubkeys[i][:limbLength])
+		lo := new(big.Int).SetBytes(pubkeys[i][limbLength:])
+		nodes[i] = poseidon.HashValues(hi, lo)
+	}
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = poseidon.HashValues(nodes[2*i], nodes[2*i+1])
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
+
+// Computes the Poseidon commitment to the compressed public keys of a sync committee in the
+// circuit. The bytes of the public keys are assumed to be range checked.
+func Commitment(api builder.API, pubkeys [Size][PubkeyLength]vars.Byte) vars.Variable {
+	return CommitmentOf(api, pubkeys[:])
+}
+
+// Computes the Poseidon commitment to the compressed public keys of a committee of any size that
+// is a power of 2 in the circuit, as ComputeCommitmentOf. The bytes of the public keys are assumed
+// to be range checked.
+func CommitmentOf(api builder.API, pubkeys [][PubkeyLength]vars.Byte) vars.Variable {
+	checkSize(len(pubkeys))
+	nodes := make([]vars.Variable, len(pubkeys))
+	for i := 0; i < len(pubkeys); i++ {
+		hi := api.ToVariableFromBytesBE(pubkeys[i][:limbLength])
+		lo := api.ToVariableFromBytesBE(pubkeys[i][limbLength:])
+		nodes[i] = poseidon.Hash(api, []vars.Variable{hi, lo})
+	}
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = poseidon.Hash(api, []vars.Variable{nodes[2*i], nodes[2*i+1]})
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
+
+// Panics unless the size of a committee is a power of 2.
+func checkSize(n int) {
+	if n <= 0 || n&(n-1) != 0 {
+		panic(fmt.Sprintf("committee of %d public keys, expected a power of 2", n))
+	}
+}
@@ -0,0 +1,70 @@
+package synccommittee
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCommitmentCircuit struct {
+	Pubkeys    [Size][PubkeyLength]vars.Byte
+	Commitment vars.Variable
+}
+
+func (c *testCommitmentCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(Commitment(*api, c.Pubkeys), c.Commitment)
+	return nil
+}
+
+func newAssignment(pubkeys [Size][PubkeyLength]byte) *testCommitmentCircuit {
+	assignment := &testCommitmentCircuit{Commitment: vars.Variable{Value: ComputeCommitment(pubkeys)}}
+	for i := 0; i < Size; i++ {
+		copy(assignment.Pubkeys[i][:], vars.NewBytesFrom(pubkeys[i][:]))
+	}
+	return assignment
+}
+
+func TestComputeCommitment(t *testing.T) {
+	// A committee of a single repeated key has the same leaf everywhere.
+	var pubkeys [Size][PubkeyLength]byte
+	for i := 0; i < Size; i++ {
+		pubkeys[i][0] = 0xc0
+	}
+	hi, _ := new(big.Int).SetString("c0"+strings.Repeat("00", limbLength-1), 16)
+	node := poseidon.HashValues(hi, new(big.Int))
+	for i := 0; i < 9; i++ {
+		node = poseidon.HashValues(node, node)
+	}
+	assert.Equal(t, node, ComputeCommitment(pubkeys))
+
+	// Smaller committees have shallower trees, and their size must be a power of 2.
+	leaf := poseidon.HashValues(hi, new(big.Int))
+	assert.Equal(t, poseidon.HashValues(leaf, leaf), ComputeCommitmentOf(pubkeys[:2]))
+	assert.Panics(t, func() { ComputeCommitmentOf(pubkeys[:3]) })
+}
+
+func TestCommitment(t *testing.T) {
+	var pubkeys [Size][PubkeyLength]byte
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < Size; i++ {
+		r.Read(pubkeys[i][:])
+	}
+	err := test.IsSolved(&testCommitmentCircuit{}, newAssignment(pubkeys), ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The commitment binds the order of the committee.
+	assignment := newAssignment(pubkeys)
+	assignment.Pubkeys[0], assignment.Pubkeys[1] = assignment.Pubkeys[1], assignment.Pubkeys[0]
+	err = test.IsSolved(&testCommitmentCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
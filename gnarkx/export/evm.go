@@ -0,0 +1,262 @@
+// Encoding of proofs and public inputs into the calldata expected by the Solidity verifiers that
+// gnark and this package export, along with a decoder for round-trip testing, and export of
+// constraint systems and witnesses to the circom formats read by other toolchains.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The method of a verifier contract that a call targets.
+type Method int
+
+const (
+	// Verifier.verifyProof(uint256[8] proof, uint256[N] input) of the gnark Groth16 verifier.
+	Groth16VerifyProof Method = iota
+
+	// PlonkVerifier.Verify(bytes proof, uint256[] public_inputs) of the gnark PLONK verifier.
+	PlonkVerify
+
+	// FunctionVerifier.verify(bytes32 inputHash, bytes32 outputHash, bytes proof) of the verifier
+	// exported by succinct.SuccinctVerifyingKey.
+	FunctionVerify
+)
+
+// A decoded verifier call.
+type Call struct {
+	Method Method
+
+	// The serialized proof. For Groth16 this is the 256 byte uncompressed (A, B, C) encoding and
+	// for PLONK it is the encoding of plonk_bn254.Proof.MarshalSolidity.
+	Proof []byte
+
+	// The public inputs. For FunctionVerify these are the input hash and output hash.
+	PublicInputs []*big.Int
+}
+
+// Encodes a call to the gnark verifier contract for the given BN254 Groth16 or PLONK proof and
+// public inputs.
+func EVMCall(proof interface{}, publicInputs []*big.Int) ([]byte, error) {
+	switch p := proof.(type) {
+	case *groth16_bn254.Proof:
+		proofWords, err := groth16Words(p)
+		if err != nil {
+			return nil, err
+		}
+		inputs, err := toWords(publicInputs)
+		if err != nil {
+			return nil, err
+		}
+		return pack(groth16Signature(len(inputs)), []string{"uint256[8]", fmt.Sprintf("uint256[%d]", len(inputs))},
+			toArray8(proofWords), toArrayN(inputs))
+	case *plonk_bn254.Proof:
+		inputs, err := toWords(publicInputs)
+		if err != nil {
+			return nil, err
+		}
+		return pack(plonkSignature, []string{"bytes", "uint256[]"}, p.MarshalSolidity(), inputs)
+	default:
+		return nil, fmt.Errorf("unsupported proof type %T", proof)
+	}
+}
+
+// Encodes a call to FunctionVerifier.verify for the given BN254 Groth16 proof of a circuit
+// function.
+func FunctionVerifierCall(proof *groth16_bn254.Proof, inputHash *big.Int, outputHash *big.Int) ([]byte, error) {
+	proofWords, err := groth16Words(proof)
+	if err != nil {
+		return nil, err
+	}
+	a := [2]*big.Int{proofWords[0], proofWords[1]}
+	b := [2][2]*big.Int{{proofWords[2], proofWords[3]}, {proofWords[4], proofWords[5]}}
+	c := [2]*big.Int{proofWords[6], proofWords[7]}
+	encodedProof, err := encode([]string{"uint256[2]", "uint256[2][2]", "uint256[2]"}, a, b, c)
+	if err != nil {
+		return nil, err
+	}
+	return pack(functionSignature, []string{"bytes32", "bytes32", "bytes"},
+		toBytes32(inputHash), toBytes32(outputHash), encodedProof)
+}
+
+// Decodes calldata produced by EVMCall or FunctionVerifierCall.
+func DecodeEVMCall(calldata []byte) (*Call, error) {
+	if len(calldata) < 4 {
+		return nil, fmt.Errorf("calldata too short: %d bytes", len(calldata))
+	}
+	selector, data := calldata[:4], calldata[4:]
+
+	switch {
+	case bytes.Equal(selector, methodID(plonkSignature)):
+		values, err := decode([]string{"bytes", "uint256[]"}, data)
+		if err != nil {
+			return nil, err
+		}
+		return &Call{Method: PlonkVerify, Proof: values[0].([]byte), PublicInputs: values[1].([]*big.Int)}, nil
+	case bytes.Equal(selector, methodID(functionSignature)):
+		values, err := decode([]string{"bytes32", "bytes32", "bytes"}, data)
+		if err != nil {
+			return nil, err
+		}
+		inputHash := values[0].([32]byte)
+		outputHash := values[1].([32]byte)
+		proofValues, err := decode([]string{"uint256[2]", "uint256[2][2]", "uint256[2]"}, values[2].([]byte))
+		if err != nil {
+			return nil, err
+		}
+		a := proofValues[0].([2]*big.Int)
+		b := proofValues[1].([2][2]*big.Int)
+		c := proofValues[2].([2]*big.Int)
+		return &Call{
+			Method:       FunctionVerify,
+			Proof:        fromWords([]*big.Int{a[0], a[1], b[0][0], b[0][1], b[1][0], b[1][1], c[0], c[1]}),
+			PublicInputs: []*big.Int{new(big.Int).SetBytes(inputHash[:]), new(big.Int).SetBytes(outputHash[:])},
+		}, nil
+	}
+
+	// The selector of verifyProof depends on the number of public inputs, which can be inferred
+	// from the length of the static encoding.
+	if len(data)%32 != 0 || len(data) < 8*32 {
+		return nil, fmt.Errorf("unknown selector 0x%x", selector)
+	}
+	n := len(data)/32 - 8
+	if !bytes.Equal(selector, methodID(groth16Signature(n))) {
+		return nil, fmt.Errorf("unknown selector 0x%x", selector)
+	}
+	proofWords := make([]*big.Int, 8)
+	for i := 0; i < 8; i++ {
+		proofWords[i] = new(big.Int).SetBytes(data[i*32 : (i+1)*32])
+	}
+	inputs := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		inputs[i] = new(big.Int).SetBytes(data[(8+i)*32 : (9+i)*32])
+	}
+	return &Call{Method: Groth16VerifyProof, Proof: fromWords(proofWords), PublicInputs: inputs}, nil
+}
+
+const (
+	plonkSignature    = "Verify(bytes,uint256[])"
+	functionSignature = "verify(bytes32,bytes32,bytes)"
+)
+
+func groth16Signature(nbPublicInputs int) string {
+	return fmt.Sprintf("verifyProof(uint256[8],uint256[%d])", nbPublicInputs)
+}
+
+func methodID(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// Returns the proof as the 8 words (A.X, A.Y, B.X.A1, B.X.A0, B.Y.A1, B.Y.A0, C.X, C.Y) expected by
+// the EVM pairing precompile.
+func groth16Words(proof *groth16_bn254.Proof) ([]*big.Int, error) {
+	var buf bytes.Buffer
+	if _, err := proof.WriteRawTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write proof: %w", err)
+	}
+	raw := buf.Bytes()
+	if len(raw) < 8*32 {
+		return nil, fmt.Errorf("unexpected proof length %d", len(raw))
+	}
+	words := make([]*big.Int, 8)
+	for i := 0; i < 8; i++ {
+		words[i] = new(big.Int).SetBytes(raw[i*32 : (i+1)*32])
+	}
+	return words, nil
+}
+
+func toWords(values []*big.Int) ([]*big.Int, error) {
+	words := make([]*big.Int, len(values))
+	for i, v := range values {
+		if v.Sign() < 0 || v.BitLen() > 256 {
+			return nil, fmt.Errorf("public input %d does not fit in a uint256", i)
+		}
+		words[i] = new(big.Int).Set(v)
+	}
+	return words, nil
+}
+
+func fromWords(words []*big.Int) []byte {
+	result := make([]byte, 32*len(words))
+	for i, w := range words {
+		w.FillBytes(result[i*32 : (i+1)*32])
+	}
+	return result
+}
+
+func toBytes32(v *big.Int) [32]byte {
+	var result [32]byte
+	v.FillBytes(result[:])
+	return result
+}
+
+func toArray8(words []*big.Int) [8]*big.Int {
+	var result [8]*big.Int
+	copy(result[:], words)
+	return result
+}
+
+// Converts the words into a fixed size array value, as expected by the abi package for static
+// array types.
+func toArrayN(words []*big.Int) interface{} {
+	arrayType, err := abi.NewType(fmt.Sprintf("uint256[%d]", len(words)), "", nil)
+	if err != nil {
+		panic(err)
+	}
+	array := reflect.New(arrayType.GetType()).Elem()
+	for i, w := range words {
+		array.Index(i).Set(reflect.ValueOf(w))
+	}
+	return array.Interface()
+}
+
+func arguments(types []string) (abi.Arguments, error) {
+	args := make(abi.Arguments, len(types))
+	for i, t := range types {
+		abiType, err := abi.NewType(t, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid abi type %s: %w", t, err)
+		}
+		args[i] = abi.Argument{Type: abiType}
+	}
+	return args, nil
+}
+
+func encode(types []string, values ...interface{}) ([]byte, error) {
+	args, err := arguments(types)
+	if err != nil {
+		return nil, err
+	}
+	data, err := args.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	return data, nil
+}
+
+func decode(types []string, data []byte) ([]interface{}, error) {
+	args, err := arguments(types)
+	if err != nil {
+		return nil, err
+	}
+	values, err := args.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode arguments: %w", err)
+	}
+	return values, nil
+}
+
+func pack(signature string, types []string, values ...interface{}) ([]byte, error) {
+	data, err := encode(types, values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(methodID(signature), data...), nil
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCircuit struct {
+	A frontend.Variable `gnark:",public"`
+	B frontend.Variable `gnark:",public"`
+	C frontend.Variable
+}
+
+func (c *testCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.B, api.Mul(c.A, c.C))
+	return nil
+}
+
+var assignment = &testCircuit{A: 3, B: 21, C: 7}
+var publicInputs = []*big.Int{big.NewInt(3), big.NewInt(21)}
+
+func TestGroth16Call(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testCircuit{})
+	assert.NoError(t, err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(t, err)
+
+	var raw bytes.Buffer
+	_, err = proof.WriteRawTo(&raw)
+	assert.NoError(t, err)
+
+	calldata, err := EVMCall(proof.(*groth16_bn254.Proof), publicInputs)
+	assert.NoError(t, err)
+	assert.Equal(t, 4+10*32, len(calldata))
+	call, err := DecodeEVMCall(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, Groth16VerifyProof, call.Method)
+	assert.Equal(t, raw.Bytes()[:8*32], call.Proof)
+	assert.Equal(t, publicInputs, call.PublicInputs)
+
+	calldata, err = FunctionVerifierCall(proof.(*groth16_bn254.Proof), publicInputs[0], publicInputs[1])
+	assert.NoError(t, err)
+	call, err = DecodeEVMCall(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, FunctionVerify, call.Method)
+	assert.Equal(t, raw.Bytes()[:8*32], call.Proof)
+	assert.Equal(t, publicInputs, call.PublicInputs)
+
+	_, err = DecodeEVMCall([]byte{1, 2, 3, 4})
+	assert.Error(t, err)
+}
+
+func TestPlonkCall(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &testCircuit{})
+	assert.NoError(t, err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(t, err)
+	pk, _, err := plonk.Setup(ccs, srs)
+	assert.NoError(t, err)
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	proof, err := plonk.Prove(ccs, pk, w)
+	assert.NoError(t, err)
+
+	calldata, err := EVMCall(proof.(*plonk_bn254.Proof), publicInputs)
+	assert.NoError(t, err)
+	call, err := DecodeEVMCall(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, PlonkVerify, call.Method)
+	assert.Equal(t, proof.(*plonk_bn254.Proof).MarshalSolidity(), call.Proof)
+	assert.Equal(t, publicInputs, call.PublicInputs)
+
+	_, err = EVMCall(proof, []*big.Int{big.NewInt(-1)})
+	assert.Error(t, err)
+}
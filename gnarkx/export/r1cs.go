@@ -0,0 +1,215 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+)
+
+// The wires of gnark and circom follow the same order: the constant one, the public inputs, the
+// secret inputs and then the internal wires. The public inputs are exported as circom public
+// inputs, without public outputs, so the wire ids and the labels are the gnark wire ids.
+//
+// gnark commitments (e.g. of the rangecheck and lookup gadgets) are solved by hints and bound by
+// the Groth16 backend rather than by constraints, so the commitment wires of an exported system
+// are unconstrained private wires. The export of these systems is suitable to cross-check the
+// constraints and the witness, but not to prove them with another prover.
+
+// A factor coeff * wire of a linear combination.
+type factor struct {
+	wire  int
+	coeff *big.Int
+}
+
+// The linear combinations a, b and c of a constraint a * b = c.
+type r1c [3][]factor
+
+// Returns the constraints of a BN254 R1CS, with the factors of each linear combination sorted by
+// wire and merged.
+func constraints(ccs constraint.ConstraintSystem) ([]r1c, error) {
+	r1cs, ok := ccs.(*cs_bn254.R1CS)
+	if !ok || r1cs.Type != constraint.SystemR1CS {
+		return nil, fmt.Errorf("unsupported constraint system %T, expected a BN254 R1CS", ccs)
+	}
+	var out []r1c
+	for _, c := range r1cs.GetR1Cs() {
+		var exported r1c
+		for i, l := range []constraint.LinearExpression{c.L, c.R, c.O} {
+			exported[i] = linearCombination(r1cs, l)
+		}
+		out = append(out, exported)
+	}
+	return out, nil
+}
+
+func linearCombination(r1cs *cs_bn254.R1CS, l constraint.LinearExpression) []factor {
+	coeffs := make(map[int]*big.Int)
+	for _, t := range l {
+		coeff := r1cs.ToBigInt(r1cs.GetCoefficient(t.CoeffID()))
+		if c, ok := coeffs[t.WireID()]; ok {
+			coeff.Add(coeff, c).Mod(coeff, r1cs.Field())
+		}
+		coeffs[t.WireID()] = coeff
+	}
+	var out []factor
+	for wire, coeff := range coeffs {
+		if coeff.Sign() != 0 {
+			out = append(out, factor{wire: wire, coeff: coeff})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].wire < out[j].wire })
+	return out
+}
+
+// Writes the R1CS in the binary .r1cs format of circom, which snarkjs and the other circom
+// toolchains read.
+func WriteR1CS(w io.Writer, ccs constraint.ConstraintSystem) error {
+	cs, err := constraints(ccs)
+	if err != nil {
+		return err
+	}
+	internal, secret, public := ccs.GetNbVariables()
+	nbWires := internal + secret + public
+
+	var header bytes.Buffer
+	writeUint32(&header, 32)
+	header.Write(toLE(ccs.Field()))
+	writeUint32(&header, uint32(nbWires))
+	writeUint32(&header, 0)
+	writeUint32(&header, uint32(public-1))
+	writeUint32(&header, uint32(secret))
+	writeUint64(&header, uint64(nbWires))
+	writeUint32(&header, uint32(len(cs)))
+
+	var body bytes.Buffer
+	for _, c := range cs {
+		for _, l := range c {
+			writeUint32(&body, uint32(len(l)))
+			for _, f := range l {
+				writeUint32(&body, uint32(f.wire))
+				body.Write(toLE(f.coeff))
+			}
+		}
+	}
+
+	var labels bytes.Buffer
+	for i := 0; i < nbWires; i++ {
+		writeUint64(&labels, uint64(i))
+	}
+
+	return writeSections(w, "r1cs", 1, header.Bytes(), body.Bytes(), labels.Bytes())
+}
+
+// The R1CS in the JSON format of snarkjs r1cs export json.
+type r1csJSON struct {
+	N8             int                    `json:"n8"`
+	Prime          string                 `json:"prime"`
+	NVars          int                    `json:"nVars"`
+	NOutputs       int                    `json:"nOutputs"`
+	NPubInputs     int                    `json:"nPubInputs"`
+	NPrvInputs     int                    `json:"nPrvInputs"`
+	NLabels        int                    `json:"nLabels"`
+	NConstraints   int                    `json:"nConstraints"`
+	UseCustomGates bool                   `json:"useCustomGates"`
+	Constraints    [][3]map[string]string `json:"constraints"`
+	Map            []int                  `json:"map"`
+}
+
+// Writes the R1CS in the JSON format of snarkjs r1cs export json, where each constraint is the
+// three linear combinations a, b and c of a * b = c as maps from the wire ids to the coefficients.
+func WriteR1CSJSON(w io.Writer, ccs constraint.ConstraintSystem) error {
+	cs, err := constraints(ccs)
+	if err != nil {
+		return err
+	}
+	internal, secret, public := ccs.GetNbVariables()
+	out := r1csJSON{
+		N8:           32,
+		Prime:        ccs.Field().String(),
+		NVars:        internal + secret + public,
+		NPubInputs:   public - 1,
+		NPrvInputs:   secret,
+		NLabels:      internal + secret + public,
+		NConstraints: len(cs),
+		Constraints:  make([][3]map[string]string, len(cs)),
+		Map:          make([]int, internal+secret+public),
+	}
+	for i, c := range cs {
+		for j, l := range c {
+			out.Constraints[i][j] = make(map[string]string)
+			for _, f := range l {
+				out.Constraints[i][j][fmt.Sprint(f.wire)] = f.coeff.String()
+			}
+		}
+	}
+	for i := range out.Map {
+		out.Map[i] = i
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+// Solves the R1CS for the witness and writes the values of all of its wires in the binary .wtns
+// format of circom, which snarkjs reads to prove the exported R1CS.
+func WriteWTNS(w io.Writer, ccs constraint.ConstraintSystem, fullWitness witness.Witness) error {
+	if _, err := constraints(ccs); err != nil {
+		return err
+	}
+	solution, err := ccs.Solve(fullWitness)
+	if err != nil {
+		return fmt.Errorf("failed to solve the constraint system: %w", err)
+	}
+	values := solution.(*cs_bn254.R1CSSolution).W
+
+	var header bytes.Buffer
+	writeUint32(&header, 32)
+	header.Write(toLE(ccs.Field()))
+	writeUint32(&header, uint32(len(values)))
+
+	var body bytes.Buffer
+	for i := range values {
+		body.Write(toLE(values[i].BigInt(new(big.Int))))
+	}
+
+	return writeSections(w, "wtns", 2, header.Bytes(), body.Bytes())
+}
+
+// Writes a file of the iden3 binary format: the magic, the version, the number of sections and
+// the sections numbered from 1, each with its type and its length.
+func writeSections(w io.Writer, magic string, version uint32, sections ...[]byte) error {
+	var out bytes.Buffer
+	out.WriteString(magic)
+	writeUint32(&out, version)
+	writeUint32(&out, uint32(len(sections)))
+	for i, section := range sections {
+		writeUint32(&out, uint32(i+1))
+		writeUint64(&out, uint64(len(section)))
+		out.Write(section)
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func writeUint32(b *bytes.Buffer, v uint32) {
+	b.Write(binary.LittleEndian.AppendUint32(nil, v))
+}
+
+func writeUint64(b *bytes.Buffer, v uint64) {
+	b.Write(binary.LittleEndian.AppendUint64(nil, v))
+}
+
+// Returns the 32 byte little-endian encoding of a field element.
+func toLE(v *big.Int) []byte {
+	out := v.FillBytes(make([]byte, 32))
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/assert"
+)
+
+type testR1CSCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+// Checks that x^3 + x + 5 == y.
+func (c *testR1CSCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, c.X, 5))
+	return nil
+}
+
+// Reads the sections of a file of the iden3 binary format.
+func readSections(t *testing.T, data []byte, magic string) [][]byte {
+	assert.Equal(t, magic, string(data[:4]))
+	n := binary.LittleEndian.Uint32(data[8:])
+	data = data[12:]
+	var sections [][]byte
+	for i := uint32(0); i < n; i++ {
+		assert.Equal(t, i+1, binary.LittleEndian.Uint32(data))
+		length := binary.LittleEndian.Uint64(data[4:])
+		sections = append(sections, data[12:12+length])
+		data = data[12+length:]
+	}
+	assert.Empty(t, data)
+	return sections
+}
+
+func fromLE(data []byte) *big.Int {
+	be := make([]byte, len(data))
+	for i := range data {
+		be[len(data)-1-i] = data[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func TestR1CS(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	ccs, err := frontend.Compile(field, r1cs.NewBuilder, &testR1CSCircuit{})
+	assert.NoError(t, err)
+	w, err := frontend.NewWitness(&testR1CSCircuit{X: 3, Y: 35}, field)
+	assert.NoError(t, err)
+
+	var r1csJSONBuffer, r1csBuffer, wtnsBuffer bytes.Buffer
+	assert.NoError(t, WriteR1CSJSON(&r1csJSONBuffer, ccs))
+	assert.NoError(t, WriteR1CS(&r1csBuffer, ccs))
+	assert.NoError(t, WriteWTNS(&wtnsBuffer, ccs, w))
+
+	// The witness starts with the constant one, the public and then the secret inputs.
+	wtns := readSections(t, wtnsBuffer.Bytes(), "wtns")
+	assert.Equal(t, field, fromLE(wtns[0][4:36]))
+	var values []*big.Int
+	for i := 0; i < len(wtns[1]); i += 32 {
+		values = append(values, fromLE(wtns[1][i:i+32]))
+	}
+	assert.Equal(t, int(binary.LittleEndian.Uint32(wtns[0][36:])), len(values))
+	assert.Equal(t, []string{"1", "3", "35"}, []string{values[0].String(), values[1].String(), values[2].String()})
+
+	var exported r1csJSON
+	assert.NoError(t, json.Unmarshal(r1csJSONBuffer.Bytes(), &exported))
+	assert.Equal(t, len(values), exported.NVars)
+	assert.Equal(t, 1, exported.NPubInputs)
+	assert.Equal(t, 1, exported.NPrvInputs)
+
+	// The binary and the JSON constraints match and are satisfied by the witness.
+	r1csSections := readSections(t, r1csBuffer.Bytes(), "r1cs")
+	assert.Equal(t, exported.NConstraints, int(binary.LittleEndian.Uint32(r1csSections[0][60:])))
+	assert.Equal(t, 8*exported.NLabels, len(r1csSections[2]))
+	body := r1csSections[1]
+	evaluate := func(l map[string]string) *big.Int {
+		sum := new(big.Int)
+		n := int(binary.LittleEndian.Uint32(body))
+		body = body[4:]
+		assert.Equal(t, len(l), n)
+		for i := 0; i < n; i++ {
+			wire := binary.LittleEndian.Uint32(body)
+			coeff := fromLE(body[4:36])
+			body = body[36:]
+			assert.Equal(t, l[big.NewInt(int64(wire)).String()], coeff.String())
+			sum.Add(sum, new(big.Int).Mul(coeff, values[wire]))
+		}
+		return sum.Mod(sum, field)
+	}
+	for _, c := range exported.Constraints {
+		a, b, o := evaluate(c[0]), evaluate(c[1]), evaluate(c[2])
+		assert.Equal(t, o.String(), a.Mul(a, b).Mod(a, field).String())
+	}
+	assert.Empty(t, body)
+
+	// The witness must satisfy the constraints.
+	w, err = frontend.NewWitness(&testR1CSCircuit{X: 3, Y: 36}, field)
+	assert.NoError(t, err)
+	assert.Error(t, WriteWTNS(&wtnsBuffer, ccs, w))
+
+	// Only R1CS are supported.
+	ccs, err = frontend.Compile(field, scs.NewBuilder, &testR1CSCircuit{})
+	assert.NoError(t, err)
+	assert.Error(t, WriteR1CS(&r1csBuffer, ccs))
+}
@@ -0,0 +1,229 @@
+// Unsigned fixed-point arithmetic on 256-bit integers, in the style of Uniswap's FullMath. A
+// Qm.n number is the integer x * 2^n, so multiplying two fixed-point numbers is a MulDiv by a
+// power of two. Integers are little-endian limbs of 64 bits, and products are checked over the
+// integers with a hinted quotient and remainder, since they overflow the scalar field.
+package fixedpoint
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/limbutils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	// The number of limbs of an integer and the number of bits per limb.
+	nbLimbs  = 4
+	limbBits = 64
+
+	// The offset added to carries so that they are non-negative, and the number of bits they are
+	// range checked to. Carries of a product of 4 limbs are bounded by 2^68 in absolute value.
+	carryOffsetBits = 70
+	carryBits       = 72
+)
+
+// An unsigned 256-bit integer as little-endian limbs of 64 bits.
+type Uint256 [nbLimbs]frontend.Variable
+
+// Returns the constant integer x, which must fit in 256 bits.
+func Constant(x *big.Int) Uint256 {
+	if x.Sign() < 0 || x.BitLen() > 256 {
+		panic(fmt.Sprintf("constant %s does not fit in 256 bits", x))
+	}
+	var result Uint256
+	limbs := limbutils.ToLimbs(x, nbLimbs, limbBits)
+	for i := 0; i < nbLimbs; i++ {
+		result[i] = limbs[i]
+	}
+	return result
+}
+
+// Returns the integer of a variable of at most 64 bits, which is range checked.
+func FromVariable(api builder.API, v vars.Variable, nbBits int) Uint256 {
+	if nbBits > limbBits {
+		panic(fmt.Sprintf("variable of %d bits does not fit in a limb", nbBits))
+	}
+	api.ToBinaryLE(v, nbBits)
+	return Uint256{v.Value, 0, 0, 0}
+}
+
+// Returns the integer of big-endian bytes, which are assumed to be range checked.
+func FromBytes(api builder.API, in [32]vars.Byte) Uint256 {
+	fapi := api.FrontendAPI()
+	var result Uint256
+	for i := 0; i < nbLimbs; i++ {
+		limb := frontend.Variable(0)
+		for j := 0; j < limbBits/8; j++ {
+			limb = fapi.Add(fapi.Mul(limb, 256), in[32-(i+1)*limbBits/8+j].Value.Value)
+		}
+		result[i] = limb
+	}
+	return result
+}
+
+// Returns the big-endian bytes of an integer, whose limbs are range checked to 64 bits.
+func ToBytes(api builder.API, x Uint256) [32]vars.Byte {
+	var result [32]vars.Byte
+	for i := 0; i < nbLimbs; i++ {
+		bits := api.ToBinaryLE(vars.Variable{Value: x[i]}, limbBits)
+		for j := 0; j < limbBits/8; j++ {
+			var byteBits [8]vars.Bool
+			copy(byteBits[:], bits[j*8:(j+1)*8])
+			result[32-i*limbBits/8-1-j] = api.ToByteFromBits(byteBits)
+		}
+	}
+	return result
+}
+
+// Returns the limbs of x if the selector is true, and of y otherwise.
+func Select(api builder.API, selector vars.Bool, x Uint256, y Uint256) Uint256 {
+	var result Uint256
+	for i := 0; i < nbLimbs; i++ {
+		result[i] = api.FrontendAPI().Select(selector.Value.Value, x[i], y[i])
+	}
+	return result
+}
+
+// Returns x + y, asserting that the sum fits in 256 bits. The limbs of x and y are assumed to be
+// range checked.
+func Add(api builder.API, x Uint256, y Uint256) Uint256 {
+	fapi := api.FrontendAPI()
+	var result Uint256
+	carry := frontend.Variable(0)
+	for i := 0; i < nbLimbs; i++ {
+		sum := fapi.Add(x[i], y[i], carry)
+		bits := api.ToBinaryLE(vars.Variable{Value: sum}, limbBits+1)
+		carry = bits[limbBits].Value.Value
+		result[i] = fapi.Sub(sum, fapi.Mul(carry, new(big.Int).Lsh(big.NewInt(1), limbBits)))
+	}
+	fapi.AssertIsEqual(carry, 0)
+	return result
+}
+
+// Returns floor(x * y / d), asserting that d is not zero and that the result fits in 256 bits.
+// The limbs of x, y and d are assumed to be range checked.
+func MulDiv(api builder.API, x Uint256, y Uint256, d Uint256) Uint256 {
+	q, _ := mulDiv(api, x, y, d)
+	return q
+}
+
+// Returns ceil(x * y / d), asserting that d is not zero and that the result fits in 256 bits.
+// The limbs of x, y and d are assumed to be range checked.
+func MulDivRoundingUp(api builder.API, x Uint256, y Uint256, d Uint256) Uint256 {
+	q, r := mulDiv(api, x, y, d)
+	fapi := api.FrontendAPI()
+	sum := frontend.Variable(0)
+	for i := 0; i < nbLimbs; i++ {
+		sum = fapi.Add(sum, r[i])
+	}
+	// The limbs of the remainder are non-negative and small, so their sum is zero iff r is.
+	roundUp := fapi.Sub(1, fapi.IsZero(sum))
+	return Add(api, q, Uint256{roundUp, 0, 0, 0})
+}
+
+// Returns the quotient and remainder of x * y by d.
+func mulDiv(api builder.API, x Uint256, y Uint256, d Uint256) (Uint256, Uint256) {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	in := make([]vars.Variable, 0, 3*nbLimbs)
+	for _, limbs := range []Uint256{x, y, d} {
+		for i := 0; i < nbLimbs; i++ {
+			in = append(in, vars.Variable{Value: limbs[i]})
+		}
+	}
+	out := api.HintVariables(mulDivHint, 3*nbLimbs+2*nbLimbs, in...)
+	var q, r, diff Uint256
+	for i := 0; i < nbLimbs; i++ {
+		q[i], r[i], diff[i] = out[i].Value, out[nbLimbs+i].Value, out[2*nbLimbs+i].Value
+		rc.Check(q[i], limbBits)
+		rc.Check(r[i], limbBits)
+		rc.Check(diff[i], limbBits)
+	}
+	carries := out[3*nbLimbs:]
+
+	// Check x * y = q * d + r over the integers, one limb position at a time:
+	//   xy_k - qd_k - r_k + carry_{k-1} = carry_k * 2^limbBits
+	offset := new(big.Int).Lsh(big.NewInt(1), carryOffsetBits)
+	base := new(big.Int).Lsh(big.NewInt(1), limbBits)
+	prevCarry := frontend.Variable(0)
+	for k := 0; k < 2*nbLimbs; k++ {
+		acc := prevCarry
+		for i := 0; i <= k; i++ {
+			j := k - i
+			if i < nbLimbs && j < nbLimbs {
+				acc = fapi.Add(acc, fapi.Mul(x[i], y[j]))
+				acc = fapi.Sub(acc, fapi.Mul(q[i], d[j]))
+			}
+		}
+		if k < nbLimbs {
+			acc = fapi.Sub(acc, r[k])
+		}
+		rc.Check(carries[k].Value, carryBits)
+		carry := fapi.Sub(carries[k].Value, offset)
+		fapi.AssertIsEqual(acc, fapi.Mul(carry, base))
+		prevCarry = carry
+	}
+	fapi.AssertIsEqual(prevCarry, 0)
+
+	// Check r < d with r + diff + 1 = d, which also implies that d is not zero.
+	carry := frontend.Variable(1)
+	for i := 0; i < nbLimbs; i++ {
+		sum := fapi.Add(r[i], diff[i], carry)
+		bits := api.ToBinaryLE(vars.Variable{Value: fapi.Sub(sum, d[i])}, limbBits+1)
+		// sum - d_i is either 0 or 2^limbBits, the carry into the next limb.
+		for j := 0; j < limbBits; j++ {
+			fapi.AssertIsEqual(bits[j].Value.Value, 0)
+		}
+		carry = bits[limbBits].Value.Value
+	}
+	fapi.AssertIsEqual(carry, 0)
+	return q, r
+}
+
+// Computes the quotient and remainder of x * y by d, the difference d - r - 1, and the carries of
+// x * y = q * d + r. The inputs are the limbs of x, y and d.
+var mulDivHint = builder.NewHint("fixedpoint.muldiv", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 3*nbLimbs || len(outputs) != 5*nbLimbs {
+		return fmt.Errorf("muldiv: invalid number of inputs or outputs")
+	}
+	x, y, d := inputs[:nbLimbs], inputs[nbLimbs:2*nbLimbs], inputs[2*nbLimbs:]
+	dInt := limbutils.FromLimbs(d, limbBits)
+	if dInt.Sign() == 0 {
+		return fmt.Errorf("muldiv: division by zero")
+	}
+	qInt, rInt := new(big.Int).QuoRem(new(big.Int).Mul(limbutils.FromLimbs(x, limbBits), limbutils.FromLimbs(y, limbBits)), dInt, new(big.Int))
+	if qInt.BitLen() > 256 {
+		return fmt.Errorf("muldiv: result overflows 256 bits")
+	}
+	q, r := limbutils.ToLimbs(qInt, nbLimbs, limbBits), limbutils.ToLimbs(rInt, nbLimbs, limbBits)
+	diff := limbutils.ToLimbs(new(big.Int).Sub(new(big.Int).Sub(dInt, rInt), big.NewInt(1)), nbLimbs, limbBits)
+	for i := 0; i < nbLimbs; i++ {
+		outputs[i].Set(q[i])
+		outputs[nbLimbs+i].Set(r[i])
+		outputs[2*nbLimbs+i].Set(diff[i])
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(1), carryOffsetBits)
+	carry := new(big.Int)
+	for k := 0; k < 2*nbLimbs; k++ {
+		acc := new(big.Int).Set(carry)
+		for i := 0; i <= k; i++ {
+			j := k - i
+			if i < nbLimbs && j < nbLimbs {
+				acc.Add(acc, new(big.Int).Mul(x[i], y[j]))
+				acc.Sub(acc, new(big.Int).Mul(q[i], d[j]))
+			}
+		}
+		if k < nbLimbs {
+			acc.Sub(acc, r[k])
+		}
+		// The accumulator is always divisible by 2^limbBits, so the shift is exact.
+		carry = acc.Rsh(acc, limbBits)
+		outputs[3*nbLimbs+k].Add(carry, offset)
+	}
+	return nil
+})
@@ -0,0 +1,82 @@
+package fixedpoint
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	X, Y, D     [32]vars.Byte
+	Floor, Ceil [32]vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	x, y, d := FromBytes(*api, c.X), FromBytes(*api, c.Y), FromBytes(*api, c.D)
+	floor := ToBytes(*api, MulDiv(*api, x, y, d))
+	ceil := ToBytes(*api, MulDivRoundingUp(*api, x, y, d))
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(floor[i], c.Floor[i])
+		api.AssertIsEqualByte(ceil[i], c.Ceil[i])
+	}
+	return nil
+}
+
+func newAssignment(x, y, d, floor, ceil *big.Int) *testCircuit {
+	assignment := &testCircuit{}
+	for _, v := range []struct {
+		bytes *[32]vars.Byte
+		value *big.Int
+	}{{&assignment.X, x}, {&assignment.Y, y}, {&assignment.D, d}, {&assignment.Floor, floor}, {&assignment.Ceil, ceil}} {
+		vars.SetBytes32(v.bytes, [32]byte(v.value.FillBytes(make([]byte, 32))))
+	}
+	return assignment
+}
+
+func TestMulDiv(t *testing.T) {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	q128 := new(big.Int).Lsh(big.NewInt(1), 128)
+	ratio, _ := new(big.Int).SetString("fffcb933bd6fad37aa2d162d1a594001", 16)
+	testCases := []struct {
+		x, y, d *big.Int
+	}{
+		{big.NewInt(7), big.NewInt(3), big.NewInt(2)},
+		{big.NewInt(6), big.NewInt(1), big.NewInt(3)},
+		{ratio, ratio, q128},
+		{max, big.NewInt(1), ratio},
+		{max, max, max},
+		{big.NewInt(0), max, big.NewInt(1)},
+	}
+	for _, tc := range testCases {
+		floor, remainder := new(big.Int).QuoRem(new(big.Int).Mul(tc.x, tc.y), tc.d, new(big.Int))
+		ceil := new(big.Int).Set(floor)
+		if remainder.Sign() != 0 {
+			ceil.Add(ceil, big.NewInt(1))
+		}
+		assignment := newAssignment(tc.x, tc.y, tc.d, floor, ceil)
+		err := test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+	}
+
+	// The results must be exact.
+	assignment := newAssignment(big.NewInt(7), big.NewInt(3), big.NewInt(2), big.NewInt(11), big.NewInt(10))
+	err := test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The result must fit in 256 bits.
+	assignment = newAssignment(max, max, big.NewInt(1), big.NewInt(0), big.NewInt(0))
+	err = test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The divisor must not be zero.
+	assignment = newAssignment(big.NewInt(1), big.NewInt(1), big.NewInt(0), big.NewInt(0), big.NewInt(0))
+	err = test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
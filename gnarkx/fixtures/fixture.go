@@ -0,0 +1,206 @@
+// Versioned fixtures of on-chain data (headers, proofs, receipts) for use as circuit inputs in
+// tests. Fixtures are captured once from an RPC endpoint with a Recorder, checked into the repo as
+// JSON, and loaded in tests without any network access.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The current version of the fixture format. Fixtures with a different version are rejected when
+// loading so that stale data is regenerated instead of silently misinterpreted.
+const Version = 1
+
+// A Fixture is a named collection of byte values and lists of byte values, along with free-form
+// metadata describing where the data came from (chain id, block number, rpc, etc).
+type Fixture struct {
+	Version  int                        `json:"version"`
+	Metadata map[string]string          `json:"metadata,omitempty"`
+	Values   map[string]hexutil.Bytes   `json:"values,omitempty"`
+	Lists    map[string][]hexutil.Bytes `json:"lists,omitempty"`
+}
+
+// Creates a new empty fixture with the current version.
+func New() *Fixture {
+	return &Fixture{
+		Version:  Version,
+		Metadata: make(map[string]string),
+		Values:   make(map[string]hexutil.Bytes),
+		Lists:    make(map[string][]hexutil.Bytes),
+	}
+}
+
+// Load reads a fixture from a JSON file.
+func Load(file string) (*Fixture, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	f := New()
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fixture: %w", err)
+	}
+	if f.Version != Version {
+		return nil, fmt.Errorf("unsupported fixture version %d, expected %d", f.Version, Version)
+	}
+	return f, nil
+}
+
+// MustLoad is like Load but panics on failure. It is intended to be used in tests.
+func MustLoad(file string) *Fixture {
+	f, err := Load(file)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Export writes the fixture to a JSON file.
+func (f *Fixture) Export(file string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write data: %w", err)
+	}
+	return nil
+}
+
+// Sets a byte value in the fixture.
+func (f *Fixture) Set(key string, value []byte) {
+	f.Values[key] = value
+}
+
+// Sets a list of byte values in the fixture.
+func (f *Fixture) SetList(key string, values [][]byte) {
+	list := make([]hexutil.Bytes, len(values))
+	for i := 0; i < len(values); i++ {
+		list[i] = values[i]
+	}
+	f.Lists[key] = list
+}
+
+// Returns the sorted keys of all values and lists in the fixture.
+func (f *Fixture) Keys() []string {
+	var keys []string
+	for k := range f.Values {
+		keys = append(keys, k)
+	}
+	for k := range f.Lists {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Returns the byte value stored under key. Panics if the key does not exist.
+func (f *Fixture) Bytes(key string) []byte {
+	value, ok := f.Values[key]
+	if !ok {
+		panic(fmt.Sprintf("fixture has no value %q", key))
+	}
+	return value
+}
+
+// Returns the byte value stored under key as a bytes32. Panics if the value is not 32 bytes long.
+func (f *Fixture) Bytes32(key string) [32]byte {
+	value := f.Bytes(key)
+	if len(value) != 32 {
+		panic(fmt.Sprintf("fixture value %q has length %d, expected 32", key, len(value)))
+	}
+	return [32]byte(value)
+}
+
+// Returns the list of byte values stored under key. Panics if the key does not exist.
+func (f *Fixture) List(key string) [][]byte {
+	list, ok := f.Lists[key]
+	if !ok {
+		panic(fmt.Sprintf("fixture has no list %q", key))
+	}
+	result := make([][]byte, len(list))
+	for i := 0; i < len(list); i++ {
+		result[i] = list[i]
+	}
+	return result
+}
+
+// Returns the list of byte values stored under key as bytes32s.
+func (f *Fixture) Bytes32List(key string) [][32]byte {
+	list := f.List(key)
+	result := make([][32]byte, len(list))
+	for i := 0; i < len(list); i++ {
+		if len(list[i]) != 32 {
+			panic(fmt.Sprintf("fixture list %q has element of length %d, expected 32", key, len(list[i])))
+		}
+		result[i] = [32]byte(list[i])
+	}
+	return result
+}
+
+// Returns the byte value stored under key as circuit variables.
+func (f *Fixture) VarBytes(key string) []vars.Byte {
+	return vars.NewBytesFrom(f.Bytes(key))
+}
+
+// Returns the byte value stored under key as a bytes32 of circuit variables.
+func (f *Fixture) VarBytes32(key string) [32]vars.Byte {
+	result := vars.NewBytes32()
+	vars.SetBytes32(&result, f.Bytes32(key))
+	return result
+}
+
+// Returns the list stored under key as circuit variables, right padding each element with zeros
+// to maxLength bytes so that it can be assigned to a circuit of fixed shape.
+func (f *Fixture) VarBytesArray(key string, maxLength int) [][]vars.Byte {
+	list := f.List(key)
+	result := vars.NewBytesArray(len(list), maxLength)
+	for i := 0; i < len(list); i++ {
+		if len(list[i]) > maxLength {
+			panic(fmt.Sprintf("fixture list %q has element of length %d, max is %d", key, len(list[i]), maxLength))
+		}
+		vars.SetBytes(&result[i], list[i])
+	}
+	return result
+}
+
+// Returns the list stored under key as an array of bytes32s of circuit variables.
+func (f *Fixture) VarBytes32Array(key string) [][32]vars.Byte {
+	list := f.Bytes32List(key)
+	result := vars.NewBytes32Array(len(list))
+	vars.SetBytes32Array(&result, list)
+	return result
+}
+
+// Redact replaces the values stored under the given keys with zeros of the same length. This is
+// useful for removing data that should not be checked in while keeping the fixture's shape.
+func (f *Fixture) Redact(keys ...string) {
+	for _, key := range keys {
+		if value, ok := f.Values[key]; ok {
+			f.Values[key] = make(hexutil.Bytes, len(value))
+		}
+		if list, ok := f.Lists[key]; ok {
+			for i := 0; i < len(list); i++ {
+				list[i] = make(hexutil.Bytes, len(list[i]))
+			}
+		}
+		f.Metadata["redacted:"+key] = "true"
+	}
+}
+
+// Truncate shortens the value or list stored under key to at most n elements. This is useful for
+// keeping fixtures small, for example by keeping only the first few receipts of a block.
+func (f *Fixture) Truncate(key string, n int) {
+	if value, ok := f.Values[key]; ok && len(value) > n {
+		f.Values[key] = value[:n]
+	}
+	if list, ok := f.Lists[key]; ok && len(list) > n {
+		f.Lists[key] = list[:n]
+	}
+}
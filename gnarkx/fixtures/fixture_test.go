@@ -0,0 +1,54 @@
+package fixtures
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtureRoundTrip(t *testing.T) {
+	f := New()
+	f.Metadata["chainId"] = "1"
+	f.Set("header.hash", make([]byte, 32))
+	f.SetList("proof.accountProof", [][]byte{{1, 2, 3}, {4, 5}})
+
+	file := filepath.Join(t.TempDir(), "fixture.json")
+	assert.NoError(t, f.Export(file))
+
+	loaded, err := Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", loaded.Metadata["chainId"])
+	assert.Equal(t, [32]byte{}, loaded.Bytes32("header.hash"))
+	assert.Equal(t, [][]byte{{1, 2, 3}, {4, 5}}, loaded.List("proof.accountProof"))
+	assert.Equal(t, []string{"header.hash", "proof.accountProof"}, loaded.Keys())
+
+	padded := loaded.VarBytesArray("proof.accountProof", 4)
+	assert.Len(t, padded, 2)
+	assert.Len(t, padded[1], 4)
+	assert.Equal(t, byte(5), padded[1][1].GetValueUnsafe())
+	assert.Equal(t, byte(0), padded[1][3].GetValueUnsafe())
+}
+
+func TestFixtureVersionMismatch(t *testing.T) {
+	f := New()
+	f.Version = Version + 1
+	file := filepath.Join(t.TempDir(), "fixture.json")
+	assert.NoError(t, f.Export(file))
+
+	_, err := Load(file)
+	assert.Error(t, err)
+}
+
+func TestFixtureRedactAndTruncate(t *testing.T) {
+	f := New()
+	f.Set("secret", []byte{1, 2, 3})
+	f.SetList("receipts", [][]byte{{1}, {2}, {3}})
+
+	f.Redact("secret")
+	f.Truncate("receipts", 2)
+
+	assert.Equal(t, []byte{0, 0, 0}, f.Bytes("secret"))
+	assert.Equal(t, "true", f.Metadata["redacted:secret"])
+	assert.Equal(t, [][]byte{{1}, {2}}, f.List("receipts"))
+}
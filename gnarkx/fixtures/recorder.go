@@ -0,0 +1,142 @@
+//go:build !js
+
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// A Recorder captures on-chain data from an Ethereum JSON-RPC endpoint into a fixture. Each
+// captured object is stored under a caller-chosen name so that multiple headers, proofs, or
+// receipts can live in the same fixture.
+type Recorder struct {
+	client  *ethclient.Client
+	gclient *gethclient.Client
+	fixture *Fixture
+}
+
+// Creates a new Recorder connected to the given RPC url.
+func NewRecorder(ctx context.Context, rpcURL string) (*Recorder, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial rpc: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	fixture := New()
+	fixture.Metadata["chainId"] = chainID.String()
+	return &Recorder{
+		client:  client,
+		gclient: gethclient.New(client.Client()),
+		fixture: fixture,
+	}, nil
+}
+
+// Returns the fixture the recorder has captured so far.
+func (r *Recorder) Fixture() *Fixture {
+	return r.fixture
+}
+
+// Captures the block header at the given block number. The following keys are set:
+//   - <name>.rlp: the RLP-encoded header.
+//   - <name>.hash: the block hash.
+func (r *Recorder) CaptureHeader(ctx context.Context, name string, number *big.Int) error {
+	header, err := r.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return fmt.Errorf("failed to get header: %w", err)
+	}
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode header: %w", err)
+	}
+	r.fixture.Set(name+".rlp", encoded)
+	r.fixture.Set(name+".hash", header.Hash().Bytes())
+	r.fixture.Metadata[name+".number"] = header.Number.String()
+	return nil
+}
+
+// Captures an account proof and storage proofs for the given slots at the given block number.
+// The following keys are set:
+//   - <name>.accountProof: the list of RLP-encoded account proof nodes.
+//   - <name>.storageHash: the storage root of the account.
+//   - <name>.storageProof.<i>: the list of RLP-encoded storage proof nodes for slot i.
+//   - <name>.storageValue.<i>: the value stored in slot i as a bytes32.
+func (r *Recorder) CaptureProof(
+	ctx context.Context,
+	name string,
+	address common.Address,
+	slots []common.Hash,
+	number *big.Int,
+) error {
+	keys := make([]string, len(slots))
+	for i := 0; i < len(slots); i++ {
+		keys[i] = slots[i].Hex()
+	}
+	result, err := r.gclient.GetProof(ctx, address, keys, number)
+	if err != nil {
+		return fmt.Errorf("failed to get proof: %w", err)
+	}
+
+	accountProof, err := decodeHexList(result.AccountProof)
+	if err != nil {
+		return err
+	}
+	r.fixture.SetList(name+".accountProof", accountProof)
+	r.fixture.Set(name+".storageHash", result.StorageHash.Bytes())
+	for i, storage := range result.StorageProof {
+		storageProof, err := decodeHexList(storage.Proof)
+		if err != nil {
+			return err
+		}
+		suffix := "." + strconv.Itoa(i)
+		r.fixture.SetList(name+".storageProof"+suffix, storageProof)
+		r.fixture.Set(name+".storageValue"+suffix, common.BigToHash(storage.Value).Bytes())
+	}
+	r.fixture.Metadata[name+".address"] = address.Hex()
+	if number != nil {
+		r.fixture.Metadata[name+".number"] = number.String()
+	}
+	return nil
+}
+
+// Captures the receipt of the given transaction. The following keys are set:
+//   - <name>.receipt: the consensus encoding of the receipt.
+//   - <name>.blockHash: the hash of the block containing the transaction.
+func (r *Recorder) CaptureReceipt(ctx context.Context, name string, txHash common.Hash) error {
+	receipt, err := r.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+	encoded, err := receipt.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode receipt: %w", err)
+	}
+	r.fixture.Set(name+".receipt", encoded)
+	r.fixture.Set(name+".blockHash", receipt.BlockHash.Bytes())
+	r.fixture.Metadata[name+".txHash"] = txHash.Hex()
+	r.fixture.Metadata[name+".transactionIndex"] = strconv.FormatUint(uint64(receipt.TransactionIndex), 10)
+	return nil
+}
+
+func decodeHexList(list []string) ([][]byte, error) {
+	result := make([][]byte, len(list))
+	for i := 0; i < len(list); i++ {
+		decoded, err := hexutil.Decode(list[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode proof node: %w", err)
+		}
+		result[i] = decoded
+	}
+	return result, nil
+}
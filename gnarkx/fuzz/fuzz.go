@@ -0,0 +1,128 @@
+// Tools for fuzzing circuit gadgets against native reference implementations. A target pairs a
+// gadget with the out of circuit code it is supposed to mirror, and the harness checks that the
+// gadget accepts exactly the outputs the reference produces (and rejects malformed inputs).
+package fuzz
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A gadget under test. Implementations should constrain that out is the result of applying the
+// gadget to in (for example, by asserting equality with the gadget's output).
+type Gadget func(api builder.API, in []vars.Byte, out []vars.Byte)
+
+// A native reference implementation. If ok is false, the input is considered malformed and the
+// gadget must not be satisfiable for it.
+type Reference func(in []byte) (out []byte, ok bool)
+
+// A Target describes a gadget, its reference implementation, and the shape of its inputs.
+type Target struct {
+	// The name of the target, used in error messages.
+	Name string
+
+	// The gadget being fuzzed.
+	Gadget Gadget
+
+	// The reference implementation that the gadget is compared against.
+	Reference Reference
+
+	// The length of the output of the gadget in bytes.
+	OutputLength int
+
+	// Optional filter for input lengths the gadget supports. Inputs with unsupported lengths are
+	// skipped rather than reported as failures.
+	ValidLength func(n int) bool
+
+	// The maximum input length to check. Inputs that are longer are skipped since solving large
+	// circuits makes fuzzing impractically slow. Zero means no limit.
+	MaxLength int
+}
+
+type circuit struct {
+	In     []vars.Byte
+	Out    []vars.Byte
+	target *Target `gnark:"-"`
+}
+
+func (c *circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	c.target.Gadget(*api, c.In, c.Out)
+	return nil
+}
+
+// Returns whether the target should be checked against the given input.
+func (t *Target) Supports(in []byte) bool {
+	if t.MaxLength > 0 && len(in) > t.MaxLength {
+		return false
+	}
+	if t.ValidLength != nil && !t.ValidLength(len(in)) {
+		return false
+	}
+	return true
+}
+
+// Checks the gadget against the reference implementation for a single input. For well-formed
+// inputs, the gadget must accept the reference output and reject the same output with a single
+// bit flipped. For malformed inputs, the gadget must reject any output.
+func (t *Target) Check(in []byte) error {
+	expected, ok := t.Reference(in)
+	if !ok {
+		if err := t.solve(in, make([]byte, t.OutputLength)); err == nil {
+			return fmt.Errorf("%s: gadget accepted malformed input %x", t.Name, in)
+		}
+		return nil
+	}
+
+	if len(expected) != t.OutputLength {
+		return fmt.Errorf("%s: reference returned %d bytes, expected %d", t.Name, len(expected), t.OutputLength)
+	}
+	if err := t.solve(in, expected); err != nil {
+		return fmt.Errorf("%s: gadget rejected input %x: %w", t.Name, in, err)
+	}
+
+	if t.OutputLength > 0 {
+		corrupted := make([]byte, len(expected))
+		copy(corrupted, expected)
+		corrupted[len(in)%len(corrupted)] ^= 1
+		if err := t.solve(in, corrupted); err == nil {
+			return fmt.Errorf("%s: gadget accepted corrupted output %x for input %x", t.Name, corrupted, in)
+		}
+	}
+	return nil
+}
+
+// Runs a Go fuzz test for the target using the given seed corpus.
+func (t *Target) Fuzz(f *testing.F, seeds ...[]byte) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(tt *testing.T, in []byte) {
+		if !t.Supports(in) {
+			tt.Skip()
+		}
+		if err := t.Check(in); err != nil {
+			tt.Fatal(err)
+		}
+	})
+}
+
+func (t *Target) solve(in []byte, out []byte) error {
+	c := &circuit{
+		In:     vars.NewBytes(len(in)),
+		Out:    vars.NewBytes(len(out)),
+		target: t,
+	}
+	w := &circuit{
+		In:     vars.NewBytesFrom(in),
+		Out:    vars.NewBytesFrom(out),
+		target: t,
+	}
+	return test.IsSolved(c, w, ecc.BN254.ScalarField())
+}
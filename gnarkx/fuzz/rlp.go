@@ -0,0 +1,71 @@
+package fuzz
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Returns the target of rlp.ReadItem, which decodes the header of the item at the start of the
+// input into its content offset, its 2 byte big-endian content length and whether it is a list.
+// The item must fit in the input, so that truncated items and items whose length takes more than
+// the 2 bytes that ReadItem supports are malformed. As ReadItem, it does not check that the
+// encoding is canonical.
+func RLPTarget() *Target {
+	return &Target{
+		Name: "rlp",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			for i := range in {
+				api.AssertIsByte(in[i])
+			}
+			table := byteslice.NewTable(api, in, 0, 3)
+			item := rlp.ReadItem(api, table, vars.NewVariableFromInt(0))
+			api.AssertIsLessOrEqual(item.End, vars.NewVariableFromInt(len(in)))
+
+			fapi := api.FrontendAPI()
+			fapi.AssertIsEqual(out[0].Value.Value, item.Offset.Value)
+			fapi.AssertIsEqual(fapi.Add(fapi.Mul(out[1].Value.Value, 256), out[2].Value.Value), item.Length.Value)
+			fapi.AssertIsEqual(out[3].Value.Value, item.IsList.Value.Value)
+		},
+		Reference:    readItem,
+		OutputLength: 4,
+		MaxLength:    64,
+	}
+}
+
+// Decodes the header of the item at the start of in as the output of RLPTarget.
+func readItem(in []byte) ([]byte, bool) {
+	if len(in) == 0 {
+		return nil, false
+	}
+	prefix := in[0]
+	var offset, length int
+	var isList byte
+	switch {
+	case prefix < 0x80:
+		offset, length = 0, 1
+	case prefix < 0xb8:
+		offset, length = 1, int(prefix-0x80)
+	case prefix < 0xc0:
+		offset = 1 + int(prefix-0xb7)
+	case prefix < 0xf8:
+		offset, length, isList = 1, int(prefix-0xc0), 1
+	default:
+		offset, isList = 1+int(prefix-0xf7), 1
+	}
+
+	// The length of long items follows the prefix, in at most 2 bytes.
+	if prefix >= 0xb8 && prefix < 0xc0 || prefix >= 0xf8 {
+		if offset > 3 || offset > len(in) {
+			return nil, false
+		}
+		for _, b := range in[1:offset] {
+			length = length*256 + int(b)
+		}
+	}
+	if offset+length > len(in) {
+		return nil, false
+	}
+	return []byte{byte(offset), byte(length >> 8), byte(length), isList}, true
+}
@@ -0,0 +1,56 @@
+package fuzz
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The seeds cover every kind of item, truncated items and prefixes with more than 2 bytes of
+// length.
+var rlpSeeds = [][]byte{
+	{0x05},
+	{0x80},
+	{0x83, 'd', 'o', 'g'},
+	{0xc3, 0x01, 0x02, 0x03},
+	append([]byte{0xb8, 0x38}, bytes.Repeat([]byte{'a'}, 56)...),
+	append([]byte{0xf8, 0x38}, bytes.Repeat([]byte{0x01}, 56)...),
+	{},
+	{0x83, 'd', 'o'},
+	{0xc3, 0x01},
+	{0xb8},
+	{0xb9, 0x01},
+	{0xb8, 0x38, 'a'},
+	{0xf9, 0x01, 0x00, 0x01},
+	{0xba, 0x00, 0x00, 0x01, 'a'},
+	{0xfb, 0x00, 0x00, 0x00, 0x01, 0x01},
+	{0xff},
+}
+
+func TestRLP(t *testing.T) {
+	target := RLPTarget()
+	for _, seed := range rlpSeeds {
+		if err := target.Check(seed); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Check only rejects a zero header for malformed inputs, so the truncated items are also
+	// rejected with the headers that their prefixes decode to.
+	for _, c := range []struct {
+		in     []byte
+		header []byte
+	}{
+		{[]byte{0x83, 'd', 'o'}, []byte{1, 0, 3, 0}},
+		{[]byte{0xc3, 0x01}, []byte{1, 0, 3, 1}},
+		{[]byte{0xb8, 0x38, 'a'}, []byte{2, 0, 0x38, 0}},
+		{[]byte{0xf9, 0x01, 0x00, 0x01}, []byte{3, 1, 0, 1}},
+	} {
+		if err := target.solve(c.in, c.header); err == nil {
+			t.Errorf("accepted truncated item %x", c.in)
+		}
+	}
+}
+
+func FuzzRLP(f *testing.F) {
+	RLPTarget().Fuzz(f, rlpSeeds...)
+}
@@ -0,0 +1,63 @@
+// Gates are the operations of gadgets with an implementation for each backend, so that the same
+// gadget compiles to the cheapest constraints of Groth16 and of PLONK.
+//
+// Every gate has a fallback made of the generic constraints of the API, which is used by the
+// backends without a specific implementation, and may declare an implementation for a backend,
+// e.g. one using lookups or the custom gates of a Plonkish arithmetization. All of the
+// implementations must constrain the same relation between the inputs and the outputs.
+package gate
+
+import (
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An implementation of a gate, which returns the outputs of the inputs.
+type Func func(api builder.API, in []vars.Variable) []vars.Variable
+
+// A Gate is an operation with a fallback and optional implementations for specific backends.
+type Gate struct {
+	name            string
+	nbInputs        int
+	fallback        Func
+	implementations map[builder.Backend]Func
+}
+
+// Creates a gate of nbInputs inputs with the fallback implementation.
+func New(name string, nbInputs int, fallback Func) *Gate {
+	return &Gate{
+		name:            name,
+		nbInputs:        nbInputs,
+		fallback:        fallback,
+		implementations: make(map[builder.Backend]Func),
+	}
+}
+
+// Declares the implementation of the gate for the backend, and returns the gate.
+func (g *Gate) With(backend builder.Backend, f Func) *Gate {
+	if _, ok := g.implementations[backend]; ok {
+		panic(fmt.Sprintf("gate %s already has an implementation for %s", g.name, backend))
+	}
+	g.implementations[backend] = f
+	return g
+}
+
+// Returns whether the gate has a specific implementation for the backend.
+func (g *Gate) Supports(backend builder.Backend) bool {
+	_, ok := g.implementations[backend]
+	return ok
+}
+
+// Applies the gate to the inputs with the implementation of the backend of the circuit, or with
+// the fallback.
+func (g *Gate) Apply(api builder.API, in ...vars.Variable) []vars.Variable {
+	if len(in) != g.nbInputs {
+		panic(fmt.Sprintf("gate %s has %d inputs, got %d", g.name, g.nbInputs, len(in)))
+	}
+	if f, ok := g.implementations[api.Backend()]; ok {
+		return f(api, in)
+	}
+	return g.fallback(api, in)
+}
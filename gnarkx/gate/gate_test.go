@@ -0,0 +1,139 @@
+package gate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the carry of the sum of two 32 bit integers.
+var carryHint = builder.NewHint("gate/u32carry", func(_ *big.Int, in []*big.Int, out []*big.Int) error {
+	out[0].Rsh(in[0], 32)
+	return nil
+})
+
+// u32Add computes (a + b) mod 2^32 of two inputs that are 32 bit integers. The fallback decomposes
+// the sum into 33 bits, while PLONK range checks the result with lookups, which is much cheaper
+// than the bits of a sparse R1CS.
+var u32Add = New("u32add", 2, func(api builder.API, in []vars.Variable) []vars.Variable {
+	bits := api.ToBinaryLE(api.Add(in[0], in[1]), 33)
+	out := vars.ZERO
+	for i := 31; i >= 0; i-- {
+		out = api.Add(api.Mul(out, vars.TWO), bits[i].Value)
+	}
+	return []vars.Variable{out}
+}).With(builder.BackendPLONK, func(api builder.API, in []vars.Variable) []vars.Variable {
+	sum := api.Add(in[0], in[1])
+	carry := api.HintVariables(carryHint, 1, sum)[0]
+	api.AssertIsBoolean(carry)
+	out := api.Sub(sum, api.Mul(carry, vars.NewVariableFromInt(1<<32)))
+	rangecheck.New(api.FrontendAPI()).Check(out.Value, 32)
+	return []vars.Variable{out}
+})
+
+// The circuit checks that Out[i] = (A[i] + B[i]) mod 2^32.
+type testCircuit struct {
+	A    []vars.Variable
+	B    []vars.Variable
+	Out  []vars.Variable
+	gate *Gate `gnark:"-"`
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	for i := range c.A {
+		api.AssertIsEqual(c.gate.Apply(*api, c.A[i], c.B[i])[0], c.Out[i])
+	}
+	return nil
+}
+
+func newTestCircuit(n int, gate *Gate) *testCircuit {
+	return &testCircuit{A: make([]vars.Variable, n), B: make([]vars.Variable, n), Out: make([]vars.Variable, n), gate: gate}
+}
+
+func TestU32Add(t *testing.T) {
+	n := 512
+	assignment := newTestCircuit(n, nil)
+	for i := 0; i < n; i++ {
+		a, b := uint32(i*0x9e3779b9), uint32(i*0x7f4a7c15+0xffff0000)
+		assignment.A[i] = vars.NewVariableFromInt(int(a))
+		assignment.B[i] = vars.NewVariableFromInt(int(b))
+		assignment.Out[i] = vars.NewVariableFromInt(int(a + b))
+	}
+	field := ecc.BN254.ScalarField()
+	assert.NoError(t, test.IsSolved(newTestCircuit(n, u32Add), assignment, field))
+
+	// The gate and its fallback solve with both backends, and the PLONK implementation with lookups
+	// is much cheaper than the bits of the fallback.
+	fallback := New("u32add", 2, u32Add.fallback)
+	witness, err := frontend.NewWitness(assignment, field)
+	assert.NoError(t, err)
+	nbConstraints := make(map[builder.Backend][2]int)
+	for i, gate := range []*Gate{u32Add, fallback} {
+		for backend, newBuilder := range map[builder.Backend]frontend.NewBuilder{
+			builder.BackendR1CS:  r1cs.NewBuilder,
+			builder.BackendPLONK: scs.NewBuilder,
+		} {
+			ccs, err := frontend.Compile(field, newBuilder, newTestCircuit(n, gate))
+			assert.NoError(t, err)
+			_, err = ccs.Solve(witness)
+			assert.NoError(t, err)
+			counts := nbConstraints[backend]
+			counts[i] = ccs.GetNbConstraints()
+			nbConstraints[backend] = counts
+		}
+	}
+	assert.Equal(t, nbConstraints[builder.BackendR1CS][0], nbConstraints[builder.BackendR1CS][1])
+	assert.Less(t, 2*nbConstraints[builder.BackendPLONK][0], nbConstraints[builder.BackendPLONK][1])
+
+	// The sum must be reduced.
+	assignment.Out[0] = vars.NewVariableFromInt(0xffff0000 + 1<<32)
+	assert.Error(t, test.IsSolved(newTestCircuit(n, u32Add), assignment, field))
+}
+
+type testBackendCircuit struct {
+	Backend vars.Variable
+}
+
+func (c *testBackendCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	gate := New("backend", 0, func(api builder.API, in []vars.Variable) []vars.Variable {
+		return []vars.Variable{vars.NewVariableFromInt(int(builder.BackendTest))}
+	}).With(builder.BackendPLONK, func(api builder.API, in []vars.Variable) []vars.Variable {
+		return []vars.Variable{vars.NewVariableFromInt(int(builder.BackendPLONK))}
+	})
+	api.AssertIsEqual(gate.Apply(*api)[0], c.Backend)
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	// The fallback of the gate returns BackendTest, and so does R1CS, which has no implementation.
+	field := ecc.BN254.ScalarField()
+	for backend, newBuilder := range map[builder.Backend]frontend.NewBuilder{
+		builder.BackendTest:  r1cs.NewBuilder,
+		builder.BackendPLONK: scs.NewBuilder,
+	} {
+		ccs, err := frontend.Compile(field, newBuilder, &testBackendCircuit{})
+		assert.NoError(t, err)
+		witness, err := frontend.NewWitness(&testBackendCircuit{Backend: vars.NewVariableFromInt(int(backend))}, field)
+		assert.NoError(t, err)
+		_, err = ccs.Solve(witness)
+		assert.NoError(t, err)
+	}
+	assignment := &testBackendCircuit{Backend: vars.NewVariableFromInt(int(builder.BackendTest))}
+	assert.NoError(t, test.IsSolved(&testBackendCircuit{}, assignment, field))
+
+	assert.True(t, u32Add.Supports(builder.BackendPLONK))
+	assert.False(t, u32Add.Supports(builder.BackendR1CS))
+	assert.Panics(t, func() { u32Add.With(builder.BackendPLONK, nil) })
+	assert.Panics(t, func() { u32Add.Apply(builder.API{}, vars.ZERO) })
+}
@@ -0,0 +1,278 @@
+// An API to evaluate many instances of the same arithmetic circuit with gnark's GKR prover.
+//
+// The wiring of the sub-computation is declared once with Import, Add, Mul and the registered
+// gates, and each wire holds the values of all of its instances. The values are solved by a hint
+// and proven by a GKR proof whose verification costs a number of constraints roughly logarithmic in
+// the number of instances, instead of the constraints of every instance. The GKR proof is only
+// computed by the gnark provers, so circuits using this API must be tested by proving them rather
+// than with the test engine.
+package gkr
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	cryptogkr "github.com/consensys/gnark-crypto/ecc/bn254/fr/gkr"
+	bn254mimc "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/constraint"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	stdgkr "github.com/consensys/gnark/std/gkr"
+	stdhash "github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The name of the hash of the Fiat-Shamir transcript of the GKR proofs.
+const hashName = "succinctx/mimc"
+
+func init() {
+	cs_bn254.HashBuilderRegistry[hashName] = bn254mimc.NewMiMC
+	stdhash.BuilderRegistry[hashName] = func(api frontend.API) (stdhash.FieldHasher, error) {
+		h, err := mimc.NewMiMC(api)
+		return &h, err
+	}
+}
+
+// A Gate is a polynomial of its inputs, which the wires of a GKR circuit compute.
+type Gate struct {
+	name   string
+	degree int
+
+	evaluate       func(api builder.API, in []vars.Variable) vars.Variable
+	evaluateValues func(in []*big.Int) *big.Int
+}
+
+var gatesMu sync.Mutex
+
+// Creates and registers a new gate of the given degree in its inputs. The gate is evaluated by
+// evaluate in the circuit, to verify the GKR proofs, and by evaluateValues outside of the circuit,
+// to solve and prove the instances, so both must compute the same polynomial. Panics if a gate with
+// the same name was already registered.
+func NewGate(
+	name string,
+	degree int,
+	evaluate func(api builder.API, in []vars.Variable) vars.Variable,
+	evaluateValues func(in []*big.Int) *big.Int,
+) *Gate {
+	g := &Gate{name: "succinctx/" + name, degree: degree, evaluate: evaluate, evaluateValues: evaluateValues}
+
+	gatesMu.Lock()
+	defer gatesMu.Unlock()
+	if _, ok := stdgkr.Gates[g.name]; ok {
+		panic(fmt.Sprintf("gate %q already registered", name))
+	}
+	stdgkr.Gates[g.name] = circuitGate{g}
+	cryptogkr.Gates[g.name] = valueGate{g}
+	return g
+}
+
+// The gate in the circuit, for the GKR verifier of gnark.
+type circuitGate struct {
+	*Gate
+}
+
+func (g circuitGate) Evaluate(api frontend.API, in ...frontend.Variable) frontend.Variable {
+	inputs := make([]vars.Variable, len(in))
+	for i := range in {
+		inputs[i] = vars.Variable{Value: in[i]}
+	}
+	return g.evaluate(*builder.NewAPI(api), inputs).Value
+}
+
+func (g circuitGate) Degree() int {
+	return g.degree
+}
+
+// The gate outside of the circuit, for the GKR prover of gnark-crypto.
+type valueGate struct {
+	*Gate
+}
+
+func (g valueGate) Evaluate(in ...fr.Element) fr.Element {
+	inputs := make([]*big.Int, len(in))
+	for i := range in {
+		inputs[i] = in[i].BigInt(new(big.Int))
+	}
+	var out fr.Element
+	out.SetBigInt(g.evaluateValues(inputs))
+	return out
+}
+
+func (g valueGate) Degree() int {
+	return g.degree
+}
+
+// The gates of the inputs, of Add and of Mul.
+var (
+	identityGate = NewGate("identity", 1, func(api builder.API, in []vars.Variable) vars.Variable {
+		return in[0]
+	}, func(in []*big.Int) *big.Int {
+		return in[0]
+	})
+	addGate = NewGate("add", 1, func(api builder.API, in []vars.Variable) vars.Variable {
+		return api.Add(in[0], in[1], in[2:]...)
+	}, func(in []*big.Int) *big.Int {
+		out := new(big.Int)
+		for _, v := range in {
+			out.Add(out, v)
+		}
+		return out
+	})
+	mulGate = NewGate("mul", 2, func(api builder.API, in []vars.Variable) vars.Variable {
+		return api.Mul(in[0], in[1])
+	}, func(in []*big.Int) *big.Int {
+		return new(big.Int).Mul(in[0], in[1])
+	})
+)
+
+// A Wire holds the values of all of the instances of a variable of the GKR circuit.
+type Wire struct {
+	index int
+}
+
+// A wire of the GKR circuit, which is either an input or a gate of other wires.
+type wire struct {
+	assignment []frontend.Variable
+	gate       *Gate
+	in         []Wire
+}
+
+// The API to declare the wiring of a GKR circuit.
+type GKRAPI struct {
+	api         builder.API
+	wires       []wire
+	nbInstances int
+}
+
+// Creates a GKR circuit. The instances of its wires are only computed by Solve, so that all of the
+// gates must be declared before.
+func NewAPI(api *builder.API) *GKRAPI {
+	return &GKRAPI{api: *api, nbInstances: -1}
+}
+
+// Imports the values of an input wire, one per instance. Every input must have the same number of
+// instances, which is padded with zeros to a power of two.
+func (a *GKRAPI) Import(in []vars.Variable) Wire {
+	if a.nbInstances != -1 && a.nbInstances != len(in) {
+		panic(fmt.Sprintf("%d instances, expected %d", len(in), a.nbInstances))
+	}
+	a.nbInstances = len(in)
+	n := 1
+	for n < len(in) {
+		n *= 2
+	}
+	assignment := make([]frontend.Variable, n)
+	for i := range assignment {
+		assignment[i] = 0
+		if i < len(in) {
+			assignment[i] = in[i].Value
+		}
+	}
+	a.wires = append(a.wires, wire{assignment: assignment})
+	return Wire{index: len(a.wires) - 1}
+}
+
+// Returns the wire of in1 + in2 + ... for every instance.
+func (a *GKRAPI) Add(i1 Wire, i2 Wire, in ...Wire) Wire {
+	return a.Gate(addGate, append([]Wire{i1, i2}, in...)...)
+}
+
+// Returns the wire of in1 * in2 for every instance.
+func (a *GKRAPI) Mul(i1 Wire, i2 Wire) Wire {
+	return a.Gate(mulGate, i1, i2)
+}
+
+// Returns the wire of the gate applied to the inputs for every instance.
+func (a *GKRAPI) Gate(g *Gate, in ...Wire) Wire {
+	a.wires = append(a.wires, wire{gate: g, in: in})
+	return Wire{index: len(a.wires) - 1}
+}
+
+// The solved instances of a GKR circuit.
+type Solution struct {
+	solution    stdgkr.Solution
+	variables   []constraint.GkrVariable
+	nbInstances int
+}
+
+// Solves the instances of the GKR circuit and adds the verification of their GKR proof to the
+// circuit. No wires can be declared afterwards.
+//
+// The wires are declared to gnark with the inputs first, each followed by an identity gate that
+// replaces it as the input of the gates. Every input of gnark then has a single claim and needs no
+// sumcheck, which avoids a mismatch between the transcripts of the GKR prover and verifier of gnark
+// when an input with a single claim precedes a wire with a sumcheck.
+func (a *GKRAPI) Solve() *Solution {
+	gkr := stdgkr.NewApi()
+	variables := make([]constraint.GkrVariable, len(a.wires))
+	for i, w := range a.wires {
+		if w.gate == nil {
+			v, err := gkr.Import(w.assignment)
+			if err != nil {
+				panic(err)
+			}
+			variables[i] = v
+		}
+	}
+	isOutput := make([]bool, len(a.wires))
+	for i, w := range a.wires {
+		if w.gate == nil {
+			variables[i] = gkr.NamedGate(identityGate.name, variables[i])
+			continue
+		}
+		in := make([]constraint.GkrVariable, len(w.in))
+		for j := range w.in {
+			in[j] = variables[w.in[j].index]
+			isOutput[w.in[j].index] = false
+		}
+		variables[i] = gkr.NamedGate(w.gate.name, in...)
+		isOutput[i] = true
+	}
+
+	solution, err := gkr.Solve(a.api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+
+	// The transcript of the proof starts with the values of the outputs, which binds the proof to
+	// them. The inputs are bound by the verifier, which evaluates them itself.
+	var outputs []frontend.Variable
+	for i := range a.wires {
+		if isOutput[i] {
+			outputs = append(outputs, solution.Export(variables[i])...)
+		}
+	}
+	if err := solution.Verify(hashName, outputs...); err != nil {
+		panic(err)
+	}
+	return &Solution{solution: solution, variables: variables, nbInstances: a.nbInstances}
+}
+
+// Returns the values of a wire, one per instance.
+func (s *Solution) Export(w Wire) []vars.Variable {
+	values := s.solution.Export(s.variables[w.index])
+	out := make([]vars.Variable, s.nbInstances)
+	for i := range out {
+		out[i] = vars.Variable{Value: values[i]}
+	}
+	return out
+}
+
+// Returns the options to solve a compiled constraint system that uses GKR outside of the gnark
+// provers, e.g. to export its witness, since only the provers solve and prove the GKR circuits.
+func SolverOptions(ccs constraint.ConstraintSystem) []solver.Option {
+	r1cs, ok := ccs.(*cs_bn254.R1CS)
+	if !ok || !r1cs.GkrInfo.Is() {
+		return nil
+	}
+	var data cs_bn254.GkrSolvingData
+	return []solver.Option{
+		solver.OverrideHint(r1cs.GkrInfo.SolveHintID, cs_bn254.GkrSolveHint(r1cs.GkrInfo, &data)),
+		solver.OverrideHint(r1cs.GkrInfo.ProveHintID, cs_bn254.GkrProveHint(r1cs.GkrInfo.HashName, &data)),
+	}
+}
@@ -0,0 +1,82 @@
+package gkr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// x^3 + y.
+var cubeGate = NewGate("test/cube", 3, func(api builder.API, in []vars.Variable) vars.Variable {
+	return api.Add(api.Mul(in[0], in[0], in[0]), in[1])
+}, func(in []*big.Int) *big.Int {
+	out := new(big.Int).Exp(in[0], big.NewInt(3), nil)
+	return out.Add(out, in[1])
+})
+
+// The circuit checks that z = (x^3 + y) * x + x + y for every instance.
+type testCircuit struct {
+	X []vars.Variable
+	Y []vars.Variable
+	Z []vars.Variable
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	gkr := NewAPI(api)
+	x, y := gkr.Import(c.X), gkr.Import(c.Y)
+	z := gkr.Add(gkr.Mul(gkr.Gate(cubeGate, x, y), x), x, y)
+	solution := gkr.Solve()
+	out := solution.Export(z)
+	for i := range out {
+		api.AssertIsEqual(out[i], c.Z[i])
+	}
+	return nil
+}
+
+func newTestCircuit(n int) *testCircuit {
+	return &testCircuit{X: make([]vars.Variable, n), Y: make([]vars.Variable, n), Z: make([]vars.Variable, n)}
+}
+
+func TestGKR(t *testing.T) {
+	// The 5 instances are padded to 8.
+	n := 5
+	circuit, assignment := newTestCircuit(n), newTestCircuit(n)
+	for i := 0; i < n; i++ {
+		x, y := int64(i+2), int64(3*i+1)
+		assignment.X[i] = vars.NewVariableFromInt(int(x))
+		assignment.Y[i] = vars.NewVariableFromInt(int(y))
+		assignment.Z[i] = vars.NewVariableFromInt(int((x*x*x+y)*x + x + y))
+	}
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	_, err = ccs.Solve(witness, SolverOptions(ccs)...)
+	assert.NoError(t, err)
+
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, vk, publicWitness))
+
+	// The outputs of the instances must match.
+	assignment.Z[n-1] = vars.NewVariableFromInt(0)
+	witness, err = frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	_, err = ccs.Solve(witness, SolverOptions(ccs)...)
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { NewGate("test/cube", 3, nil, nil) })
+}
@@ -0,0 +1,199 @@
+// The API for BLAKE2b according to RFC 7693, unkeyed and with the 12 rounds of 64-bit words.
+// Reference: https://www.rfc-editor.org/rfc/rfc7693
+//
+// Words are little-endian arrays of bits. Rotations are free, xors cost a constraint per bit and
+// sums are computed as field elements, so that only the sum is decomposed into bits.
+package blake2b
+
+import (
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes compressed per block.
+const blockSize = 128
+
+// The initialization vector, the first 64 bits of the fractional parts of the square roots of the
+// first 8 primes, as for SHA-512.
+var iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// The permutations of the message words of the rounds.
+var sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// The powers of two of the bits of a word.
+var powers = func() [64]vars.Variable {
+	var powers [64]vars.Variable
+	for i := range powers {
+		powers[i] = vars.NewVariableFromString(new(big.Int).Lsh(big.NewInt(1), uint(i)).String())
+	}
+	return powers
+}()
+
+// A 64-bit word as little-endian bits.
+type word [64]vars.Bool
+
+func constantWord(v uint64) word {
+	var w word
+	for i := 0; i < 64; i++ {
+		w[i] = vars.FALSE
+		if v>>i&1 == 1 {
+			w[i] = vars.TRUE
+		}
+	}
+	return w
+}
+
+func xor(api builder.API, w1, w2 word) word {
+	var w word
+	for i := 0; i < 64; i++ {
+		w[i] = api.Xor(w1[i], w2[i])
+	}
+	return w
+}
+
+// Xors a word with a constant, which only negates bits.
+func xorConstant(api builder.API, w1 word, v uint64) word {
+	var w word
+	for i := 0; i < 64; i++ {
+		w[i] = w1[i]
+		if v>>i&1 == 1 {
+			w[i] = api.Not(w1[i])
+		}
+	}
+	return w
+}
+
+// Rotates a word to the right.
+func rotr(w1 word, offset int) word {
+	var w word
+	for i := 0; i < 64; i++ {
+		w[i] = w1[(i+offset)%64]
+	}
+	return w
+}
+
+// Computes the sum of the words modulo 2^64.
+func add(api builder.API, in ...word) word {
+	sum := vars.ZERO
+	for _, w := range in {
+		for i := 0; i < 64; i++ {
+			sum = api.Add(sum, api.Mul(w[i].Value, powers[i]))
+		}
+	}
+	var w word
+	// The sum of three words has at most 66 bits.
+	copy(w[:], api.ToBinaryLE(sum, 66)[:64])
+	return w
+}
+
+// Computes the mixing function G on v[a], v[b], v[c] and v[d] with the message words x and y.
+func mix(api builder.API, v *[16]word, a, b, c, d int, x, y word) {
+	v[a] = add(api, v[a], v[b], x)
+	v[d] = rotr(xor(api, v[d], v[a]), 32)
+	v[c] = add(api, v[c], v[d])
+	v[b] = rotr(xor(api, v[b], v[c]), 24)
+	v[a] = add(api, v[a], v[b], y)
+	v[d] = rotr(xor(api, v[d], v[a]), 16)
+	v[c] = add(api, v[c], v[d])
+	v[b] = rotr(xor(api, v[b], v[c]), 63)
+}
+
+// Compresses a block into the state, where t is the number of bytes hashed including the block.
+func compress(api builder.API, h *[8]word, m [16]word, t uint64, last bool) {
+	var v [16]word
+	copy(v[:8], h[:])
+	for i := 0; i < 8; i++ {
+		v[8+i] = constantWord(iv[i])
+	}
+	v[12] = xorConstant(api, v[12], t)
+	if last {
+		v[14] = xorConstant(api, v[14], ^uint64(0))
+	}
+	for i := 0; i < 12; i++ {
+		s := sigma[i%10]
+		mix(api, &v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		mix(api, &v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		mix(api, &v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		mix(api, &v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		mix(api, &v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		mix(api, &v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		mix(api, &v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		mix(api, &v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := 0; i < 8; i++ {
+		h[i] = xor(api, h[i], xor(api, v[i], v[i+8]))
+	}
+}
+
+// Computes the BLAKE2b hash of size bytes of the input bytes, where len(in) is a compile time
+// constant.
+func hash(api builder.API, in []vars.Byte, size int) []vars.Byte {
+	var h [8]word
+	for i := 0; i < 8; i++ {
+		h[i] = constantWord(iv[i])
+	}
+	h[0] = xorConstant(api, h[0], 0x01010000^uint64(size))
+
+	nbBlocks := (len(in) + blockSize - 1) / blockSize
+	if nbBlocks == 0 {
+		nbBlocks = 1
+	}
+	for b := 0; b < nbBlocks; b++ {
+		var m [16]word
+		for i := 0; i < 16; i++ {
+			for j := 0; j < 8; j++ {
+				index := b*blockSize + i*8 + j
+				bits := [8]vars.Bool{vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE}
+				if index < len(in) {
+					bits = api.ToBitsFromByte(in[index])
+				}
+				copy(m[i][8*j:8*(j+1)], bits[:])
+			}
+		}
+		t := uint64((b + 1) * blockSize)
+		if b == nbBlocks-1 {
+			t = uint64(len(in))
+		}
+		compress(api, &h, m, t, b == nbBlocks-1)
+	}
+
+	out := make([]vars.Byte, size)
+	for i := range out {
+		var bits [8]vars.Bool
+		copy(bits[:], h[i/8][8*(i%8):8*(i%8+1)])
+		out[i] = api.ToByteFromBits(bits)
+	}
+	return out
+}
+
+// Computes the BLAKE2b-512 hash of the input bytes. Note that at compile time of the circuit,
+// len(in) must be a constant.
+func Hash(api builder.API, in []vars.Byte) [64]vars.Byte {
+	var out [64]vars.Byte
+	copy(out[:], hash(api, in, 64))
+	return out
+}
+
+// Computes the BLAKE2b-256 hash of the input bytes, as the blake2_256 of Substrate. Note that at
+// compile time of the circuit, len(in) must be a constant.
+func Hash256(api builder.API, in []vars.Byte) [32]vars.Byte {
+	var out [32]vars.Byte
+	copy(out[:], hash(api, in, 32))
+	return out
+}
@@ -0,0 +1,87 @@
+package blake2b
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
+	circuittest "github.com/succinctlabs/succinctx/gnarkx/test"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	goblake2b "golang.org/x/crypto/blake2b"
+)
+
+type TestBlake2bCircuit struct {
+	In     []vars.Byte
+	Out    [64]vars.Byte
+	Out256 [32]vars.Byte
+}
+
+func (circuit *TestBlake2bCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := Hash(*succinctAPI, circuit.In)
+	for i := 0; i < 64; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	res256 := Hash256(*succinctAPI, circuit.In)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(res256[i], circuit.Out256[i])
+	}
+	return nil
+}
+
+func TestBlake2bWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(in []byte, output string) {
+		out, err := hex.DecodeString(output)
+		if err != nil {
+			panic(err)
+		}
+		circuit := TestBlake2bCircuit{In: vars.NewBytes(len(in))}
+		witness := TestBlake2bCircuit{In: vars.NewBytesFrom(in)}
+		copy(witness.Out[:], vars.NewBytesFrom(out))
+		vars.SetBytes32(&witness.Out256, goblake2b.Sum256(in))
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+
+	// The test vectors of RFC 7693 and a message of a full block and a byte.
+	testCase([]byte(""), "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce")
+	testCase([]byte("abc"), "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923")
+	in := make([]byte, 129)
+	digest := goblake2b.Sum512(in)
+	testCase(in, hex.EncodeToString(digest[:]))
+}
+
+func TestBlake2bMatches(t *testing.T) {
+	circuittest.AssertHashMatchesRandom(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := Hash256(api, in)
+		return h[:]
+	}, func(in []byte) []byte {
+		h := goblake2b.Sum256(in)
+		return h[:]
+	}, 4, 300)
+}
+
+func FuzzBlake2b(f *testing.F) {
+	target := fuzz.Target{
+		Name: "blake2b",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			res := Hash(api, in)
+			for i := 0; i < 64; i++ {
+				api.AssertIsEqualByte(res[i], out[i])
+			}
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			h := goblake2b.Sum512(in)
+			return h[:], true
+		},
+		OutputLength: 64,
+		MaxLength:    256,
+	}
+	target.Fuzz(f, []byte(""), []byte("Succinct Labs"), make([]byte, 128), make([]byte, 129))
+}
@@ -0,0 +1,72 @@
+// The API for Keccak-256, the original Keccak submission used by Ethereum, which differs from
+// SHA3-256 in its padding. It is the Keccak256 instance of the sponge of the sha3 package, with an
+// alternative permutation on packed lanes. Reference: https://keccak.team/keccak_specs_summary.html
+package keccak256
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha3"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Keccak256API is a wrapper around succinct.API that provides methods for Keccak-256, with the
+// implementation of the permutation chosen when it is created.
+type Keccak256API struct {
+	api    builder.API
+	packed bool
+}
+
+// An Option configures the Keccak256API created by NewAPI.
+type Option func(*Keccak256API)
+
+// Makes the permutation keep the lanes packed in single field elements, whose theta, rho and chi
+// steps are lookups of chunks of lanes in tables shared across the circuit, instead of operating on
+// their bytes (see packed.go). The tables cost a fixed number of constraints, so this pays off for
+// circuits with many permutations, such as storage proofs.
+func WithPackedLanes() Option {
+	return func(a *Keccak256API) {
+		a.packed = true
+	}
+}
+
+// Creates a new Keccak256API.
+func NewAPI(api *builder.API, options ...Option) *Keccak256API {
+	a := &Keccak256API{api: *api}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Computes the Keccak-256 hash of the input bytes, where len(in) is a compile time constant.
+func (a *Keccak256API) Hash(in []vars.Byte) [32]vars.Byte {
+	return a.HashVariable(in, vars.NewVariableFromInt(len(in)))
+}
+
+// Computes the Keccak-256 hash of the first length bytes of in, as HashVariable. The bytes of in
+// are assumed to be range checked, except with packed lanes, whose lookup of the sparse
+// representation of the bytes range checks them.
+func (a *Keccak256API) HashVariable(in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	if a.packed {
+		return hashVariablePacked(a.api, in, length)
+	}
+	return HashVariable(a.api, in, length)
+}
+
+// Computes the Keccak-256 hash of the input bytes, where len(in) is a compile time constant.
+func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
+	return HashVariable(api, in, vars.NewVariableFromInt(len(in)))
+}
+
+// Computes the Keccak-256 hash of the first length bytes of in, where length is only known at
+// proving time and must be at most len(in), with the sponge of sha3.Keccak256. Every block that
+// could be part of the message is absorbed and the digest after the last block is selected. Bytes
+// of in past length are ignored. The permutation operates on bytes whose bitwise operations are lookups in the tables of gnark's
+// uints, the same tables as the u32s of a builder.API created WithLookups, so that a circuit with
+// both pays for the tables once. The bytes of in are assumed to be range checked, as they are
+// packed into lanes as they are: a larger value would spill into the next byte of its lane.
+func HashVariable(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	var result [32]vars.Byte
+	copy(result[:], sha3.Keccak256.HashVariable(api, in, length, 32))
+	return result
+}
@@ -0,0 +1,206 @@
+package keccak256
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestKeccak256Circuit struct {
+	In      []vars.Byte
+	Out     [32]vars.Byte
+	Lookups bool `gnark:"-"`
+}
+
+func (circuit *TestKeccak256Circuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	if circuit.Lookups {
+		succinctAPI = builder.NewAPI(api, builder.WithLookups())
+	}
+	res := Hash(*succinctAPI, circuit.In)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestKeccak256Witness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(in []byte, output string) {
+		out, err := hex.DecodeString(output)
+		if err != nil {
+			panic(err)
+		}
+		for _, lookups := range []bool{false, true} {
+			circuit := TestKeccak256Circuit{In: vars.NewBytes(len(in)), Lookups: lookups}
+			witness := TestKeccak256Circuit{In: vars.NewBytesFrom(in)}
+			vars.SetBytes32(&witness.Out, [32]byte(out))
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}
+	}
+
+	testCase([]byte(""), "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")
+	testCase([]byte("hello"), "1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8")
+	testCase([]byte("Transfer(address,address,uint256)"), "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+}
+
+// The circuit hashes its input and xors pairs of u32s.
+type TestKeccak256XorCircuit struct {
+	In      []vars.Byte
+	X       [][2]vars.U32
+	Lookups bool `gnark:"-"`
+}
+
+func (circuit *TestKeccak256XorCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	if circuit.Lookups {
+		succinctAPI = builder.NewAPI(api, builder.WithLookups())
+	}
+	if len(circuit.In) > 0 {
+		Hash(*succinctAPI, circuit.In)
+	}
+	for _, x := range circuit.X {
+		succinctAPI.XorU32(x[0], x[1])
+	}
+	return nil
+}
+
+func TestKeccak256Lookups(t *testing.T) {
+	compile := func(lookups bool, nbBytes int, nbXors int) int {
+		circuit := TestKeccak256XorCircuit{In: vars.NewBytes(nbBytes), X: make([][2]vars.U32, nbXors), Lookups: lookups}
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ccs.GetNbConstraints()
+	}
+
+	// The hash uses the lookup tables with and without WithLookups.
+	assert.Equal(t, compile(false, 136, 0), compile(true, 136, 0))
+
+	// The xors with lookups share the tables of the hash, so they cost less next to it than alone,
+	// which pays for the tables.
+	alone := compile(true, 0, 1)
+	shared := compile(true, 136, 1) - compile(true, 136, 0)
+	t.Logf("a xor with lookups: %d constraints alone, %d next to a hash", alone, shared)
+	assert.Less(t, 2*shared, alone)
+}
+
+type TestKeccak256VariableCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+}
+
+func (circuit *TestKeccak256VariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestKeccak256VariableWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The lengths cover padding of a single byte at the end of a block and padding that needs an
+	// extra block.
+	maxLength := 280
+	for _, length := range []int{0, 13, 134, 135, 136, 271, 272, 280} {
+		in := make([]byte, maxLength)
+		for i := 0; i < length; i++ {
+			in[i] = byte(i * 7)
+		}
+		// Bytes past the length must not change the digest.
+		for i := length; i < maxLength; i++ {
+			in[i] = 0xff
+		}
+
+		circuit := TestKeccak256VariableCircuit{In: vars.NewBytes(maxLength)}
+		witness := TestKeccak256VariableCircuit{
+			In:     vars.NewBytesFrom(in),
+			Length: vars.NewVariableFromInt(length),
+		}
+		vars.SetBytes32(&witness.Out, [32]byte(crypto.Keccak256(in[:length])))
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+
+	circuit := TestKeccak256VariableCircuit{In: vars.NewBytes(maxLength)}
+	witness := TestKeccak256VariableCircuit{
+		In:     vars.NewBytesFrom(make([]byte, maxLength)),
+		Length: vars.NewVariableFromInt(maxLength + 1),
+	}
+	vars.SetBytes32(&witness.Out, [32]byte(crypto.Keccak256(nil)))
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+func FuzzKeccak256(f *testing.F) {
+	target := fuzz.Target{
+		Name: "keccak256",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			res := Hash(api, in)
+			for i := 0; i < 32; i++ {
+				api.AssertIsEqualByte(res[i], out[i])
+			}
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			return crypto.Keccak256(in), true
+		},
+		OutputLength: 32,
+		MaxLength:    256,
+	}
+	target.Fuzz(f, []byte(""), []byte("Succinct Labs"), make([]byte, 135), make([]byte, 136))
+}
+
+type TestKeccak256PackedCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+}
+
+func (circuit *TestKeccak256PackedCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := NewAPI(succinctAPI, WithPackedLanes()).HashVariable(circuit.In, circuit.Length)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestKeccak256PackedWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	maxLength := 280
+	for _, length := range []int{0, 135, 136, 280} {
+		in := make([]byte, maxLength)
+		for i := 0; i < length; i++ {
+			in[i] = byte(i * 7)
+		}
+		circuit := TestKeccak256PackedCircuit{In: vars.NewBytes(maxLength)}
+		witness := TestKeccak256PackedCircuit{
+			In:     vars.NewBytesFrom(in),
+			Length: vars.NewVariableFromInt(length),
+		}
+		vars.SetBytes32(&witness.Out, [32]byte(crypto.Keccak256(in[:length])))
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+
+		witness.Out[31].Set(witness.Out[31].GetValueUnsafe() ^ 1)
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,276 @@
+package keccak256
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha3"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The permutation with packed lanes. A lane is a single field element in a sparse representation,
+// the sum of bit_i * 10^i, so that the XORs of theta are additions of lanes, whose digits count the
+// set bits, and the chi of the lanes a, b and c is a function of the digits of 4a + 2b + c. The
+// digits are brought back to bits by splitting the lanes into chunks of 4 digits with a hint and
+// looking the chunks up in tables of all the 10^4 chunks, which both range checks them and maps
+// their digits. The rotations of theta and rho are free, as the chunks of a lane are recomposed at
+// their rotated positions, with the chunk at the rotation split in two. The round constant of iota
+// is added to the digits of the first lane and left there until the next lookup, which keeps the
+// digits below 10.
+//
+// Reference: https://github.com/privacy-scaling-explorations/zkevm-circuits (keccak circuit)
+
+const (
+	// The base of the sparse representation of the lanes.
+	base = 10
+
+	// The number of digits of the chunks looked up in the tables.
+	chunkDigits = 4
+
+	// The number of chunks of chunkDigits digits, 10^4.
+	tableSize = 10000
+)
+
+// The round constants of iota.
+var roundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// The rotations of rho of the lane x + 5y.
+var rotations = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// The lookup tables of a circuit, which are shared by all its permutations.
+type tables struct {
+	// The parities of the digits of the chunks, in base 10.
+	parity *logderivlookup.Table
+	// a ^ (^b & c) of the digits 4a + 2b + c of the chunks, in base 10.
+	chi *logderivlookup.Table
+	// The parities of the digits of the chunks, in base 2.
+	binary *logderivlookup.Table
+	// The bits of the bytes, in base 10.
+	sparse *logderivlookup.Table
+}
+
+type tablesKey struct{}
+
+// The key-value store of the gnark builders, as of gnark/internal/kvstore.
+type keyValueStore interface {
+	SetKeyValue(key, value any)
+	GetKeyValue(key any) any
+}
+
+// Returns the tables of the circuit, which are created by its first permutation.
+func getTables(api frontend.API) *tables {
+	kv, ok := api.Compiler().(keyValueStore)
+	if !ok {
+		panic("builder should implement key-value store")
+	}
+	if t, ok := kv.GetKeyValue(tablesKey{}).(*tables); ok {
+		return t
+	}
+	parity := func(d int) int { return d % 2 }
+	chi := func(d int) int { return (d >> 2 & 1) ^ (^(d >> 1) & d & 1) }
+	t := &tables{
+		parity: newChunkTable(api, parity, base),
+		chi:    newChunkTable(api, chi, base),
+		binary: newChunkTable(api, parity, 2),
+		sparse: logderivlookup.New(api),
+	}
+	for i := 0; i < 256; i++ {
+		t.sparse.Insert(sparse(uint64(i)))
+	}
+	kv.SetKeyValue(tablesKey{}, t)
+	return t
+}
+
+// Returns the table of the chunks whose digits are mapped by f to the digits of the output base.
+func newChunkTable(api frontend.API, f func(int) int, outBase int) *logderivlookup.Table {
+	t := logderivlookup.New(api)
+	for i := 0; i < tableSize; i++ {
+		value, weight := 0, 1
+		for j, chunk := 0, i; j < chunkDigits; j, chunk = j+1, chunk/base {
+			value += f(chunk%base) * weight
+			weight *= outBase
+		}
+		t.Insert(value)
+	}
+	return t
+}
+
+// Returns the sparse representation of a word.
+func sparse(word uint64) *big.Int {
+	result := new(big.Int)
+	for i := 63; i >= 0; i-- {
+		result.Mul(result, big.NewInt(base))
+		result.Add(result, new(big.Int).SetUint64(word>>i&1))
+	}
+	return result
+}
+
+// Returns the segments [lo, hi) of the digits of a lane that are looked up together for a
+// rotation by r, the chunks of chunkDigits digits split at 64 - r.
+func segments(r int) [][2]int {
+	cut := (64 - r) % 64
+	var result [][2]int
+	for lo := 0; lo < 64; {
+		hi := lo - lo%chunkDigits + chunkDigits
+		if lo < cut && cut < hi {
+			hi = cut
+		}
+		result = append(result, [2]int{lo, hi})
+		lo = hi
+	}
+	return result
+}
+
+// Returns the segments of a lane, whose digits are less than 10, for the rotation.
+var segmentsHint = builder.NewHint("keccak256.segments", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	segs := segments(int(inputs[1].Int64()))
+	if len(outputs) != len(segs) {
+		return fmt.Errorf("%d outputs, expected %d", len(outputs), len(segs))
+	}
+	var digits [64]int64
+	v := new(big.Int).Set(inputs[0])
+	digit := new(big.Int)
+	for i := range digits {
+		v.DivMod(v, big.NewInt(base), digit)
+		digits[i] = digit.Int64()
+	}
+	if v.Sign() != 0 {
+		return fmt.Errorf("%s is not a lane", inputs[0])
+	}
+	for i, seg := range segs {
+		value := int64(0)
+		for j := seg[1] - 1; j >= seg[0]; j-- {
+			value = value*base + digits[j]
+		}
+		outputs[i].SetInt64(value)
+	}
+	return nil
+})
+
+// Returns the lane whose digits are those of v mapped by the table to the output base, rotated
+// left by r, and the outputs of its segments from the least significant.
+func normalize(api builder.API, table *logderivlookup.Table, outBase int64, v frontend.Variable, r int) (frontend.Variable, []frontend.Variable) {
+	fapi := api.FrontendAPI()
+	pow := func(b int64, e int) *big.Int {
+		return new(big.Int).Exp(big.NewInt(b), big.NewInt(int64(e)), nil)
+	}
+
+	segs := segments(r)
+	chunks := api.HintVariables(segmentsHint, len(segs), vars.Variable{Value: v}, vars.NewVariableFromInt(r))
+	sum := frontend.Variable(0)
+	indices := make([]frontend.Variable, len(segs))
+	for i, seg := range segs {
+		sum = fapi.Add(sum, fapi.Mul(chunks[i].Value, pow(base, seg[0])))
+		indices[i] = chunks[i].Value
+	}
+	// A segment shorter than a chunk, which the lookup checks to be less than 10^4, is also looked
+	// up shifted to the top of the chunk, which checks that it has as many digits as the segment.
+	for i, seg := range segs {
+		if seg[1]-seg[0] < chunkDigits {
+			indices = append(indices, fapi.Mul(chunks[i].Value, pow(base, chunkDigits-(seg[1]-seg[0]))))
+		}
+	}
+	fapi.AssertIsEqual(sum, v)
+
+	outputs := table.Lookup(indices...)[:len(segs)]
+	result := frontend.Variable(0)
+	for i, seg := range segs {
+		result = fapi.Add(result, fapi.Mul(outputs[i], pow(outBase, (seg[0]+r)%64)))
+	}
+	return result, outputs
+}
+
+// Applies the permutation to the lanes in the sparse representation, whose digits are at most 2
+// for the first lane and its bits for the others, and whose first lane has the same bound after.
+func permute(api builder.API, t *tables, a [25]frontend.Variable) [25]frontend.Variable {
+	fapi := api.FrontendAPI()
+	for round := 0; round < 24; round++ {
+		// theta, where the digits of the sums of the columns are at most 6.
+		var c, rotated [5]frontend.Variable
+		for x := 0; x < 5; x++ {
+			c[x] = fapi.Add(a[x], a[x+5], a[x+10], a[x+15], a[x+20])
+			rotated[x], _ = normalize(api, t.parity, base, c[x], 1)
+		}
+
+		// rho and pi, with the digits of theta at most 9.
+		var b [25]frontend.Variable
+		for x := 0; x < 5; x++ {
+			d := fapi.Add(c[(x+4)%5], rotated[(x+1)%5])
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)], _ = normalize(api, t.parity, base, fapi.Add(a[x+5*y], d), rotations[x+5*y])
+			}
+		}
+
+		// chi and iota.
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				in := fapi.Add(fapi.Mul(b[x+5*y], 4), fapi.Mul(b[(x+1)%5+5*y], 2), b[(x+2)%5+5*y])
+				a[x+5*y], _ = normalize(api, t.chi, base, in, 0)
+			}
+		}
+		a[0] = fapi.Add(a[0], sparse(roundConstants[round]))
+	}
+	return a
+}
+
+// Computes HashVariable with the permutation on packed lanes.
+func hashVariablePacked(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	fapi := api.FrontendAPI()
+	t := getTables(fapi)
+	rate := sha3.Keccak256.Rate
+	padded, isLast := sha3.Keccak256.Pad(api, in, length)
+	sparseBytes := t.sparse.Lookup(padded...)
+
+	var state [25]frontend.Variable
+	for i := range state {
+		state[i] = frontend.Variable(0)
+	}
+	var digest [4]frontend.Variable
+	for i := range digest {
+		digest[i] = frontend.Variable(0)
+	}
+	for b := range isLast {
+		for i := 0; i < rate/8; i++ {
+			lane := frontend.Variable(0)
+			for j := 0; j < 8; j++ {
+				lane = fapi.Add(lane, fapi.Mul(sparseBytes[b*rate+8*i+j], new(big.Int).Exp(big.NewInt(base), big.NewInt(int64(8*j)), nil)))
+			}
+			// The lanes of the first block are the input, and the others are brought back to bits.
+			if b == 0 {
+				state[i] = lane
+			} else {
+				state[i], _ = normalize(api, t.parity, base, fapi.Add(state[i], lane), 0)
+			}
+		}
+		state = permute(api, t, state)
+		for i := 0; i < 4; i++ {
+			digest[i] = fapi.Add(digest[i], fapi.Mul(isLast[b], state[i]))
+		}
+	}
+
+	// The little-endian bytes of the lanes, from the nibbles of their segments.
+	var result [32]vars.Byte
+	for i := 0; i < 4; i++ {
+		_, nibbles := normalize(api, t.binary, 2, digest[i], 0)
+		for j := 0; j < 8; j++ {
+			result[8*i+j] = vars.Byte{Value: vars.Variable{Value: fapi.Add(nibbles[2*j], fapi.Mul(nibbles[2*j+1], 16))}}
+		}
+	}
+	return result
+}
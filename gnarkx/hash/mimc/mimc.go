@@ -0,0 +1,106 @@
+// The API for MiMC, the hash of gnark over the scalar field of BN254, which is the Miyaguchi-Preneel
+// compression of the MiMC-p/p block cipher with the x^5 S-box. The digests match the mimc package
+// of gnark-crypto, as used by the PLONK and Groth16 tooling of gnark.
+// Reference: https://eprint.iacr.org/2016/492.pdf
+//
+// Besides the hash of field elements, a byte mode packs the bytes into elements, so that MiMC
+// can stand in for the byte-oriented hashes of this repository where the data is bytes.
+package mimc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	gomimc "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	stdmimc "github.com/consensys/gnark/std/hash/mimc"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes packed into an element in the byte mode, which every element can hold.
+const bytesPerElement = 31
+
+// Computes the MiMC hash of the inputs, which are reduced modulo the scalar field of BN254. This
+// is the reference implementation of Hash.
+func HashValues(in ...*big.Int) *big.Int {
+	h := gomimc.NewMiMC()
+	for i := range in {
+		var e fr.Element
+		e.SetBigInt(in[i])
+		b := e.Bytes()
+		if _, err := h.Write(b[:]); err != nil {
+			panic(err)
+		}
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// Computes the MiMC hash of the inputs in the circuit, where len(in) is a compile time constant.
+func Hash(api builder.API, in []vars.Variable) vars.Variable {
+	if api.Curve() != ecc.BN254 {
+		panic(fmt.Sprintf("mimc is only supported over the scalar field of BN254, not %s", api.Curve()))
+	}
+	h, err := stdmimc.NewMiMC(api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	for i := range in {
+		h.Write(in[i].Value)
+	}
+	return vars.Variable{Value: h.Sum()}
+}
+
+// Returns the elements of the byte mode: the big-endian chunks of 31 bytes of the input, the last
+// of which may be shorter, followed by the length of the input, so that inputs that differ only in
+// trailing zeros have different elements.
+func packValues(in []byte) []*big.Int {
+	var elements []*big.Int
+	for i := 0; i < len(in); i += bytesPerElement {
+		end := i + bytesPerElement
+		if end > len(in) {
+			end = len(in)
+		}
+		elements = append(elements, new(big.Int).SetBytes(in[i:end]))
+	}
+	return append(elements, big.NewInt(int64(len(in))))
+}
+
+// Computes the MiMC hash of the input bytes in the byte mode, as a big-endian bytes32. This is the
+// reference implementation of HashBytes.
+func HashBytesValue(in []byte) [32]byte {
+	var out [32]byte
+	HashValues(packValues(in)...).FillBytes(out[:])
+	return out
+}
+
+// Computes the MiMC hash of the input bytes in the byte mode, where len(in) is a compile time
+// constant, as a big-endian bytes32. The bytes of in are assumed to be range checked.
+func HashBytes(api builder.API, in []vars.Byte) [32]vars.Byte {
+	var elements []vars.Variable
+	for i := 0; i < len(in); i += bytesPerElement {
+		element := vars.ZERO
+		for j := i; j < i+bytesPerElement && j < len(in); j++ {
+			element = api.Add(api.Mul(element, vars.NewVariableFromInt(256)), in[j].Value)
+		}
+		elements = append(elements, element)
+	}
+	elements = append(elements, vars.NewVariableFromInt(len(in)))
+	digest := Hash(api, elements)
+
+	// The decomposition into as many bits as the field checks that they are the canonical ones.
+	bits := api.ToBinaryLE(digest, api.FieldBitLen())
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			byteBits[j] = vars.FALSE
+			if 8*i+j < len(bits) {
+				byteBits[j] = bits[8*i+j]
+			}
+		}
+		out[31-i] = api.ToByteFromBits(byteBits)
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package mimc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	circuittest "github.com/succinctlabs/succinctx/gnarkx/test"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestMiMCCircuit struct {
+	In  []vars.Variable
+	Out vars.Variable
+}
+
+func (c *TestMiMCCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(Hash(*api, c.In), c.Out)
+	return nil
+}
+
+func TestHash(t *testing.T) {
+	modulus := ecc.BN254.ScalarField()
+	for _, in := range [][]*big.Int{
+		{big.NewInt(0)},
+		{big.NewInt(1), big.NewInt(2)},
+		{new(big.Int).Sub(modulus, big.NewInt(1)), big.NewInt(42), big.NewInt(7)},
+	} {
+		circuit := TestMiMCCircuit{In: make([]vars.Variable, len(in))}
+		witness := TestMiMCCircuit{In: make([]vars.Variable, len(in))}
+		for i := range in {
+			witness.In[i].Set(in[i])
+		}
+		witness.Out.Set(HashValues(in...))
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+		witness.Out.Set(new(big.Int).Add(HashValues(in...), big.NewInt(1)))
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestHashBytes(t *testing.T) {
+	circuittest.AssertHashMatches(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := HashBytes(api, in)
+		return h[:]
+	}, func(in []byte) []byte {
+		h := HashBytesValue(in)
+		return h[:]
+	}, []byte(""), []byte("Succinct Labs"), make([]byte, 31), make([]byte, 32), make([]byte, 100))
+
+	// Inputs that differ in trailing zeros have different digests.
+	assert.NotEqual(t, HashBytesValue([]byte{1}), HashBytesValue([]byte{1, 0}))
+	assert.NotEqual(t, HashBytesValue([]byte{}), HashBytesValue([]byte{0}))
+}
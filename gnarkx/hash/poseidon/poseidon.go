@@ -0,0 +1,212 @@
+// The API for Poseidon, a hash function over the scalar field of BN254 designed for circuits.
+// Reference: https://eprint.iacr.org/2019/458.pdf
+//
+// The parameters are those of circomlib and of the reference implementation of the paper: the
+// x^5 S-box, 8 full rounds, the number of partial rounds of circomlib for the width, and the round
+// constants and MDS matrix generated by the Grain LFSR of the reference implementation. The hash of
+// n inputs is the first element of the state after the permutation of [0, in...], so the digests
+// match the Poseidon of circomlib and of the contracts generated from it.
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum number of inputs of a hash.
+const MaxInputs = 16
+
+// The number of full rounds for every width is 8, half of them before the partial rounds.
+const fullRounds = 8
+
+// The number of partial rounds for the widths 2 to MaxInputs+1.
+var partialRounds = [MaxInputs]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+// The round constants and the MDS matrix of a width.
+type parameters struct {
+	partialRounds int
+	constants     []*big.Int
+	mds           [][]*big.Int
+}
+
+var (
+	mu    sync.Mutex
+	cache = make(map[int]*parameters)
+)
+
+// Returns the parameters of the width t, generating them on first use.
+func parametersOf(t int) *parameters {
+	mu.Lock()
+	defer mu.Unlock()
+	if p, ok := cache[t]; ok {
+		return p
+	}
+	modulus := ecc.BN254.ScalarField()
+	nbBits := modulus.BitLen()
+	p := &parameters{partialRounds: partialRounds[t-2]}
+	g := newGrain(t, fullRounds, p.partialRounds, nbBits)
+
+	// Round constants are sampled by rejection, while the points of the Cauchy matrix are reduced.
+	for i := 0; i < (fullRounds+p.partialRounds)*t; i++ {
+		c := g.element(nbBits)
+		for c.Cmp(modulus) >= 0 {
+			c = g.element(nbBits)
+		}
+		p.constants = append(p.constants, c)
+	}
+	points := make([]*big.Int, 2*t)
+	for i := range points {
+		points[i] = g.element(nbBits)
+		points[i].Mod(points[i], modulus)
+	}
+	p.mds = make([][]*big.Int, t)
+	for i := 0; i < t; i++ {
+		p.mds[i] = make([]*big.Int, t)
+		for j := 0; j < t; j++ {
+			entry := new(big.Int).Add(points[i], points[t+j])
+			p.mds[i][j] = entry.ModInverse(entry.Mod(entry, modulus), modulus)
+		}
+	}
+	cache[t] = p
+	return p
+}
+
+// The Grain LFSR of the reference implementation, seeded with the parameters of the instance.
+type grain struct {
+	state [80]byte
+}
+
+func newGrain(t int, fullRounds int, partialRounds int, nbBits int) *grain {
+	g := &grain{}
+	i := 0
+	push := func(value int, n int) {
+		for b := n - 1; b >= 0; b-- {
+			g.state[i] = byte(value>>b) & 1
+			i++
+		}
+	}
+	push(1, 2) // a prime field
+	push(0, 4) // the x^alpha S-box
+	push(nbBits, 12)
+	push(t, 12)
+	push(fullRounds, 10)
+	push(partialRounds, 10)
+	push(1<<30-1, 30)
+	for j := 0; j < 160; j++ {
+		g.step()
+	}
+	return g
+}
+
+func (g *grain) step() byte {
+	s := &g.state
+	bit := s[62] ^ s[51] ^ s[38] ^ s[23] ^ s[13] ^ s[0]
+	copy(s[:], s[1:])
+	s[79] = bit
+	return bit
+}
+
+// Returns the next output bit, which is the second bit of the first pair starting with a one.
+func (g *grain) bit() byte {
+	for {
+		if g.step() == 1 {
+			return g.step()
+		}
+		g.step()
+	}
+}
+
+// Returns the next n output bits as a big endian integer.
+func (g *grain) element(n int) *big.Int {
+	e := new(big.Int)
+	for i := 0; i < n; i++ {
+		e.Lsh(e, 1)
+		e.SetBit(e, 0, uint(g.bit()))
+	}
+	return e
+}
+
+func checkInputs(n int) {
+	if n < 1 || n > MaxInputs {
+		panic(fmt.Sprintf("poseidon takes 1 to %d inputs, got %d", MaxInputs, n))
+	}
+}
+
+// Computes the Poseidon hash of the inputs, which must be elements of the scalar field of BN254.
+// This is the reference implementation of Hash.
+func HashValues(in ...*big.Int) *big.Int {
+	checkInputs(len(in))
+	modulus := ecc.BN254.ScalarField()
+	t := len(in) + 1
+	p := parametersOf(t)
+	state := make([]*big.Int, t)
+	state[0] = new(big.Int)
+	for i := 0; i < len(in); i++ {
+		state[i+1] = new(big.Int).Set(in[i])
+	}
+	five := big.NewInt(5)
+	for r := 0; r < fullRounds+p.partialRounds; r++ {
+		for i := 0; i < t; i++ {
+			state[i].Add(state[i], p.constants[r*t+i])
+			if i == 0 || isFullRound(r, p.partialRounds) {
+				state[i].Exp(state[i], five, modulus)
+			}
+		}
+		next := make([]*big.Int, t)
+		for i := 0; i < t; i++ {
+			next[i] = new(big.Int)
+			for j := 0; j < t; j++ {
+				next[i].Add(next[i], new(big.Int).Mul(p.mds[i][j], state[j]))
+			}
+			next[i].Mod(next[i], modulus)
+		}
+		state = next
+	}
+	return state[0]
+}
+
+// Computes the Poseidon hash of the inputs in the circuit, where len(in) is a compile time
+// constant between 1 and MaxInputs.
+func Hash(api builder.API, in []vars.Variable) vars.Variable {
+	checkInputs(len(in))
+	if api.Curve() != ecc.BN254 {
+		panic(fmt.Sprintf("poseidon is only defined over the scalar field of BN254, not %s", api.Curve()))
+	}
+	fapi := api.FrontendAPI()
+	t := len(in) + 1
+	p := parametersOf(t)
+	state := make([]frontend.Variable, t)
+	state[0] = frontend.Variable(0)
+	for i := 0; i < len(in); i++ {
+		state[i+1] = in[i].Value
+	}
+	for r := 0; r < fullRounds+p.partialRounds; r++ {
+		for i := 0; i < t; i++ {
+			state[i] = fapi.Add(state[i], p.constants[r*t+i])
+			if i == 0 || isFullRound(r, p.partialRounds) {
+				square := fapi.Mul(state[i], state[i])
+				state[i] = fapi.Mul(fapi.Mul(square, square), state[i])
+			}
+		}
+		next := make([]frontend.Variable, t)
+		for i := 0; i < t; i++ {
+			next[i] = frontend.Variable(0)
+			for j := 0; j < t; j++ {
+				next[i] = fapi.Add(next[i], fapi.Mul(p.mds[i][j], state[j]))
+			}
+		}
+		state = next
+	}
+	return vars.Variable{Value: state[0]}
+}
+
+// Returns whether the round r applies the S-box to the whole state.
+func isFullRound(r int, partialRounds int) bool {
+	return r < fullRounds/2 || r >= fullRounds/2+partialRounds
+}
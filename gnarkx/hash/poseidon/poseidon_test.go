@@ -0,0 +1,75 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Digests of the Poseidon of circomlib.
+var testVectors = []struct {
+	in       []int64
+	expected string
+}{
+	{[]int64{1, 2}, "0x115cc0f5e7d690413df64c6b9662e9cf2a3617f2743245519e19607a4417189a"},
+	{[]int64{1, 2, 3, 4}, "0x299c867db6c1fdd79dcefa40e4510b9837e60ebb1ce0663dbaa525df65250465"},
+}
+
+func toBigInts(in []int64) []*big.Int {
+	values := make([]*big.Int, len(in))
+	for i := range in {
+		values[i] = big.NewInt(in[i])
+	}
+	return values
+}
+
+func TestParameters(t *testing.T) {
+	p := parametersOf(3)
+	assert.Equal(t, "0x0ee9a592ba9a9518d05986d656f40c2114c4993c11bb29938d21d47304cd8e6e", fmt.Sprintf("0x%064x", p.constants[0]))
+	assert.Equal(t, "0x109b7f411ba0e4c9b2b70caf5c36a7b194be7c11ad24378bfedb68592ba8118b", fmt.Sprintf("0x%064x", p.mds[0][0]))
+}
+
+func TestHashValues(t *testing.T) {
+	for _, v := range testVectors {
+		expected, _ := new(big.Int).SetString(v.expected[2:], 16)
+		assert.Equal(t, expected, HashValues(toBigInts(v.in)...))
+	}
+}
+
+type testHashCircuit struct {
+	In  []vars.Variable
+	Out vars.Variable
+}
+
+func (c *testHashCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(Hash(*api, c.In), c.Out)
+	return nil
+}
+
+func TestHash(t *testing.T) {
+	for n := 1; n <= MaxInputs; n++ {
+		in := make([]int64, n)
+		for i := range in {
+			in[i] = int64(i * 1000003)
+		}
+		circuit := &testHashCircuit{In: make([]vars.Variable, n)}
+		assignment := &testHashCircuit{In: make([]vars.Variable, n), Out: vars.Variable{Value: HashValues(toBigInts(in)...)}}
+		for i := range in {
+			assignment.In[i] = vars.NewVariableFromInt(int(in[i]))
+		}
+		err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err, "%d inputs", n)
+
+		assignment.Out = vars.Variable{Value: 1}
+		err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.Error(t, err, "%d inputs", n)
+	}
+}
@@ -0,0 +1,163 @@
+// The API for RIPEMD-160 according to https://homes.esat.kuleuven.be/~bosselae/ripemd160.html,
+// with HASH160 = RIPEMD-160(SHA-256(x)) of the public keys and scripts of Bitcoin addresses.
+package ripemd160
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/bits32"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The initial state.
+var H = []uint32{0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0}
+
+// The constants of the rounds of the left and the right lines.
+var K = []uint32{0x00000000, 0x5A827999, 0x6ED9EBA1, 0x8F1BBCDC, 0xA953FD4E}
+var KPrime = []uint32{0x50A28BE6, 0x5C4DD124, 0x6D703EF3, 0x7A6D76E9, 0x00000000}
+
+// The words of the chunk that the steps of the left and the right lines add.
+var r = [80]int{
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+	7, 4, 13, 1, 10, 6, 15, 3, 12, 0, 9, 5, 2, 14, 11, 8,
+	3, 10, 14, 4, 9, 15, 8, 1, 2, 7, 0, 6, 13, 11, 5, 12,
+	1, 9, 11, 10, 0, 8, 12, 4, 13, 3, 7, 15, 14, 5, 6, 2,
+	4, 0, 5, 9, 7, 12, 2, 10, 14, 1, 3, 8, 11, 6, 15, 13,
+}
+var rPrime = [80]int{
+	5, 14, 7, 0, 9, 2, 11, 4, 13, 6, 15, 8, 1, 10, 3, 12,
+	6, 11, 3, 7, 0, 13, 5, 10, 14, 15, 8, 12, 4, 9, 1, 2,
+	15, 5, 1, 3, 7, 14, 6, 9, 11, 8, 12, 2, 10, 0, 4, 13,
+	8, 6, 4, 1, 3, 11, 15, 0, 5, 12, 2, 13, 9, 7, 10, 14,
+	12, 15, 10, 4, 1, 5, 8, 7, 6, 2, 13, 14, 0, 3, 9, 11,
+}
+
+// The left rotations of the steps of the left and the right lines.
+var s = [80]int{
+	11, 14, 15, 12, 5, 8, 7, 9, 11, 13, 14, 15, 6, 7, 9, 8,
+	7, 6, 8, 13, 11, 9, 7, 15, 7, 12, 15, 9, 11, 7, 13, 12,
+	11, 13, 6, 7, 14, 9, 13, 15, 14, 8, 13, 6, 5, 12, 7, 5,
+	11, 12, 14, 15, 14, 15, 9, 8, 9, 14, 5, 6, 8, 6, 5, 12,
+	9, 15, 5, 11, 6, 8, 13, 12, 5, 12, 13, 14, 11, 8, 5, 6,
+}
+var sPrime = [80]int{
+	8, 9, 9, 11, 13, 15, 15, 5, 7, 7, 8, 11, 14, 14, 12, 6,
+	9, 13, 15, 7, 12, 8, 9, 11, 7, 7, 12, 7, 6, 15, 13, 11,
+	9, 7, 15, 11, 8, 6, 6, 14, 12, 13, 5, 14, 13, 13, 7, 5,
+	15, 5, 8, 11, 14, 14, 6, 14, 6, 9, 12, 9, 12, 5, 15, 8,
+	8, 5, 12, 9, 12, 5, 14, 6, 8, 13, 6, 5, 15, 13, 11, 11,
+}
+
+const chunkLength = 64
+
+// Computes the RIPEMD-160 hash of the input bytes. Note that at compile time of the circuit,
+// len(in) must be a constant.
+func Hash(api builder.API, in []vars.Byte) [20]vars.Byte {
+	// The message, a single 0x80 byte, zeros up to 56 bytes modulo 64 and the bit length of the
+	// message as a 64-bit little-endian integer.
+	padded := append([]vars.Byte{}, in...)
+	padded = append(padded, vars.NewBytesFrom([]byte{0x80})...)
+	for len(padded)%chunkLength != 56 {
+		padded = append(padded, vars.NewBytesFrom([]byte{0})...)
+	}
+	bitLength := uint64(len(in)) * 8
+	for i := 0; i < 8; i++ {
+		padded = append(padded, vars.NewBytesFrom([]byte{byte(bitLength >> (8 * i))})...)
+	}
+
+	var h [5][32]vars.Bool
+	for i := range h {
+		h[i] = vars.NewBoolArrayFromU32(H[i])
+	}
+	for chunk := 0; chunk < len(padded); chunk += chunkLength {
+		var x [16][32]vars.Bool
+		for i := range x {
+			x[i] = toWord(api, padded[chunk+4*i:chunk+4*i+4])
+		}
+		compress(api, &h, x)
+	}
+
+	var digest [20]vars.Byte
+	for i := range h {
+		// The words are little-endian.
+		for j := 0; j < 4; j++ {
+			var bits [8]vars.Bool
+			for k := 0; k < 8; k++ {
+				bits[k] = h[i][31-8*j-k]
+			}
+			digest[4*i+j] = api.ToByteFromBits(bits)
+		}
+	}
+	return digest
+}
+
+// Computes HASH160, the RIPEMD-160 hash of the SHA-256 hash of the input bytes, as of the public
+// keys of P2PKH and P2WPKH addresses.
+func Hash160(api builder.API, in []vars.Byte) [20]vars.Byte {
+	digest := sha256.Hash(api, in)
+	return Hash(api, digest[:])
+}
+
+// Returns the bits of the little-endian word of 4 bytes, in the big-endian order of bits32.
+func toWord(api builder.API, in []vars.Byte) [32]vars.Bool {
+	var word [32]vars.Bool
+	for i := 0; i < 4; i++ {
+		bits := api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			word[31-8*i-j] = bits[j]
+		}
+	}
+	return word
+}
+
+// Compresses the 16 words of a chunk into the state h.
+func compress(api builder.API, h *[5][32]vars.Bool, x [16][32]vars.Bool) {
+	bits32 := bits32.NewAPI(api)
+	a, b, c, d, e := h[0], h[1], h[2], h[3], h[4]
+	aPrime, bPrime, cPrime, dPrime, ePrime := h[0], h[1], h[2], h[3], h[4]
+	for j := 0; j < 80; j++ {
+		round := j / 16
+
+		// The left line, with rotations to the left, which are rotations by 32 - s to the right.
+		t := bits32.Add(a, f(api, round, b, c, d), x[r[j]], vars.NewBoolArrayFromU32(K[round]))
+		t = bits32.Add(bits32.Rotate(t, 32-s[j]), e)
+		a, e, d, c, b = e, d, bits32.Rotate(c, 22), b, t
+
+		// The right line, whose rounds take the functions in reverse order.
+		t = bits32.Add(aPrime, f(api, 4-round, bPrime, cPrime, dPrime), x[rPrime[j]], vars.NewBoolArrayFromU32(KPrime[round]))
+		t = bits32.Add(bits32.Rotate(t, 32-sPrime[j]), ePrime)
+		aPrime, ePrime, dPrime, cPrime, bPrime = ePrime, dPrime, bits32.Rotate(cPrime, 22), bPrime, t
+	}
+	t := bits32.Add(h[1], c, dPrime)
+	h[1] = bits32.Add(h[2], d, ePrime)
+	h[2] = bits32.Add(h[3], e, aPrime)
+	h[3] = bits32.Add(h[4], a, bPrime)
+	h[4] = bits32.Add(h[0], b, cPrime)
+	h[0] = t
+}
+
+// Computes the boolean function of a round.
+func f(api builder.API, round int, x, y, z [32]vars.Bool) [32]vars.Bool {
+	bits32 := bits32.NewAPI(api)
+	switch round {
+	case 0:
+		return bits32.Xor(x, y, z)
+	case 1:
+		return bits32.Select(x, y, z)
+	case 2:
+		return bits32.Xor(orNot(api, x, y), z)
+	case 3:
+		return bits32.Select(z, x, y)
+	default:
+		return bits32.Xor(x, orNot(api, y, z))
+	}
+}
+
+// Computes x | ~y, or 1 - (1 - x) * y, with a single constraint per bit.
+func orNot(api builder.API, x, y [32]vars.Bool) [32]vars.Bool {
+	var result [32]vars.Bool
+	for i := 0; i < 32; i++ {
+		result[i] = vars.Bool{Value: api.Sub(vars.ONE, api.Mul(api.Sub(vars.ONE, x[i].Value), y[i].Value))}
+	}
+	return result
+}
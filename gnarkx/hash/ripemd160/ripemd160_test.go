@@ -0,0 +1,47 @@
+package ripemd160
+
+import (
+	gosha256 "crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	circuittest "github.com/succinctlabs/succinctx/gnarkx/test"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	goripemd160 "golang.org/x/crypto/ripemd160"
+)
+
+func ripemd160Value(in []byte) []byte {
+	h := goripemd160.New()
+	h.Write(in)
+	return h.Sum(nil)
+}
+
+func TestHash(t *testing.T) {
+	circuittest.AssertHashMatches(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := Hash(api, in)
+		return h[:]
+	}, ripemd160Value, []byte(""), []byte("abc"), make([]byte, 55), make([]byte, 56), make([]byte, 64))
+	circuittest.AssertHashMatchesRandom(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := Hash(api, in)
+		return h[:]
+	}, ripemd160Value, 4, 150)
+}
+
+func TestHash160(t *testing.T) {
+	// The HASH160 of the compressed public key of the private key 1, whose P2PKH address is
+	// 1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH.
+	pubkey, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	expected, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+	sha := gosha256.Sum256(pubkey)
+	assert.Equal(t, expected, ripemd160Value(sha[:]))
+
+	circuittest.AssertHashMatches(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := Hash160(api, in)
+		return h[:]
+	}, func(in []byte) []byte {
+		sha := gosha256.Sum256(in)
+		return ripemd160Value(sha[:])
+	}, pubkey)
+}
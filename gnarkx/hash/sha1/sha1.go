@@ -0,0 +1,79 @@
+// The API for SHA-1 according to https://en.wikipedia.org/wiki/SHA-1, for legacy protocols such as
+// the DKIM signatures of emails and older certificate chains. SHA-1 is not collision resistant and
+// must not be used for new protocols. Its padding and word layout are those of SHA-256, which are
+// shared with package sha256.
+package sha1
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/bits32"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The initial state.
+var H = []uint32{0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0}
+
+// The constants of the rounds of 20 steps.
+var K = []uint32{0x5A827999, 0x6ED9EBA1, 0x8F1BBCDC, 0xCA62C1D6}
+
+const sha1ChunkLength = 512
+const sha1WordLength = 32
+const sha1MessageScheduleArrayLength = 80
+
+// Computes the SHA-1 hash of the input bytes. Note that at compile time of the circuit, len(in)
+// must be a constant.
+func Hash(api builder.API, in []vars.Byte) [20]vars.Byte {
+	padded := sha256.Pad(api, sha256.ToBits(api, in), len(in))
+
+	var h [5][32]vars.Bool
+	for i := range h {
+		h[i] = vars.NewBoolArrayFromU32(H[i])
+	}
+	for i := 0; i < len(padded); i += sha1ChunkLength {
+		compress(api, &h, padded[i:i+sha1ChunkLength])
+	}
+
+	var digest [20]vars.Byte
+	copy(digest[:], sha256.ToBytes(api, h[:]))
+	return digest
+}
+
+// Compresses a chunk of 512 bits into the state h.
+func compress(api builder.API, h *[5][32]vars.Bool, chunk []vars.Bool) {
+	bits32 := bits32.NewAPI(api)
+
+	// The 80-entry message schedule array of 32-bit words, whose first 16 words are the chunk and
+	// whose other words are rotated to the left by 1, a rotation by 31 to the right.
+	var w [sha1MessageScheduleArrayLength][sha1WordLength]vars.Bool
+	for j := 0; j < 16; j++ {
+		copy(w[j][:], chunk[j*sha1WordLength:(j+1)*sha1WordLength])
+	}
+	for j := 16; j < sha1MessageScheduleArrayLength; j++ {
+		w[j] = bits32.Rotate(bits32.Xor(w[j-3], w[j-8], w[j-14], w[j-16]), 31)
+	}
+
+	a, b, c, d, e := h[0], h[1], h[2], h[3], h[4]
+	for j := 0; j < sha1MessageScheduleArrayLength; j++ {
+		var f [32]vars.Bool
+		switch j / 20 {
+		case 0:
+			f = bits32.Select(b, c, d)
+		case 2:
+			f = bits32.Majority(b, c, d)
+		default:
+			f = bits32.Xor(b, c, d)
+		}
+		k := vars.NewBoolArrayFromU32(K[j/20])
+
+		// The rotations to the left by 5 and 30 are rotations by 27 and 2 to the right.
+		t := bits32.Add(bits32.Rotate(a, 27), f, e, k, w[j])
+		a, b, c, d, e = t, a, bits32.Rotate(b, 2), c, d
+	}
+
+	h[0] = bits32.Add(h[0], a)
+	h[1] = bits32.Add(h[1], b)
+	h[2] = bits32.Add(h[2], c)
+	h[3] = bits32.Add(h[3], d)
+	h[4] = bits32.Add(h[4], e)
+}
@@ -0,0 +1,26 @@
+package sha1
+
+import (
+	gosha1 "crypto/sha1"
+	"testing"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	circuittest "github.com/succinctlabs/succinctx/gnarkx/test"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func sha1Value(in []byte) []byte {
+	h := gosha1.Sum(in)
+	return h[:]
+}
+
+func TestHash(t *testing.T) {
+	circuittest.AssertHashMatches(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := Hash(api, in)
+		return h[:]
+	}, sha1Value, []byte(""), []byte("abc"), make([]byte, 55), make([]byte, 56), make([]byte, 64))
+	circuittest.AssertHashMatchesRandom(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+		h := Hash(api, in)
+		return h[:]
+	}, sha1Value, 4, 150)
+}
@@ -4,6 +4,8 @@ package sha256
 import (
 	"math/big"
 
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/permutation/sha2"
 	"github.com/succinctlabs/succinctx/gnarkx/bits32"
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
@@ -31,167 +33,277 @@ var K = []uint32{
 // Computes the SHA256-2 hash of the input bytes. Note that at compile time of the circuit, len(in)
 // must be a constant.
 func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
-	bits32 := bits32.NewAPI(api)
+	hasher := NewHasher(api)
+	hasher.Write(in)
+	return hasher.Sum()
+}
 
-	// Decompose bytes to bits.
-	inBits := make([]vars.Bool, len(in)*8)
-	for i := 0; i < len(in); i++ {
-		bits := api.ToBitsFromByte(in[i])
-		for j := 0; j < 8; j++ {
-			inBits[i*8+j] = bits[7-j]
-		}
-	}
+const sha256ChunkLength = 512
+const sha256WordLength = 32
+const sha256MessageScheduleArrayLength = 64
 
-	// The length-encoded message length ("L + 1 + 64").
-	const seperatorLength = 1
-	const u64BitLength = 64
-	encodedMessageLength := len(inBits) + seperatorLength + u64BitLength
+// A Hasher computes the SHA256-2 hash of a message written in parts, whose lengths are compile time
+// constants. Each full chunk of 64 bytes is compressed as soon as it is written. If the API uses
+// lookups, the chunks are compressed by sha2.Permute on u32s of bytes, whose bitwise operations are
+// lookups, instead of on bits, which takes fewer constraints per chunk with Groth16 but not with
+// PLONK.
+type Hasher struct {
+	api    builder.API
+	h      [8][32]vars.Bool
+	buffer []vars.Bool
+	length int
 
-	// The multiple of 512-bit padded message length. Padding length is "K".
-	remainderLength := encodedMessageLength % 512
-	paddingLength := 0
-	if remainderLength == 0 {
-		paddingLength = 0
-	} else {
-		paddingLength = 512 - remainderLength
-	}
-	paddedMessageLength := encodedMessageLength + paddingLength
+	// The state and the buffered bytes of the compressions with lookups, where uapi is not nil.
+	uapi  *uints.BinaryField[uints.U32]
+	state [8]uints.U32
+	bytes []uints.U8
+}
 
-	// Initialization of core variables.
-	paddedMessage := make([]vars.Bool, paddedMessageLength)
-	for i := 0; i < paddedMessageLength; i++ {
-		paddedMessage[i] = vars.FALSE
-	}
+// Returns a hasher of the empty message.
+func NewHasher(api builder.API) *Hasher {
+	var h [8]uint32
+	copy(h[:], H)
+	return NewHasherFromMidstate(api, h, 0)
+}
 
-	// Begin with the original message of length "L".
-	copy(paddedMessage, inBits)
+// Returns a hasher that continues from the midstate of a constant prefix of length bytes, a
+// multiple of 64, such as computed by sha256utils.Midstate, so that the chunks of the prefix cost
+// no constraints. The hash is over the prefix followed by the bytes written to the hasher.
+func NewHasherFromMidstate(api builder.API, midstate [8]uint32, length int) *Hasher {
+	if length < 0 || length%(sha256ChunkLength/8) != 0 {
+		panic("the length of the prefix of a midstate must be a multiple of 64")
+	}
+	hasher := &Hasher{api: api, length: length}
+	if api.UsesLookups() {
+		uapi, err := uints.New[uints.U32](api.FrontendAPI())
+		if err != nil {
+			panic(err)
+		}
+		hasher.uapi = uapi
+		for i := 0; i < 8; i++ {
+			hasher.state[i] = uints.NewU32(midstate[i])
+		}
+		return hasher
+	}
+	for i := 0; i < 8; i++ {
+		hasher.h[i] = vars.NewBoolArrayFromU32(midstate[i])
+	}
+	return hasher
+}
 
-	// Append a single '1' bit.
-	paddedMessage[len(inBits)] = vars.TRUE
+// Appends the bytes to the message.
+func (h *Hasher) Write(in []vars.Byte) {
+	h.length += len(in)
+	if h.uapi != nil {
+		h.bytes = append(h.bytes, h.toU8s(in)...)
+		h.compressFullBytes()
+		return
+	}
+	h.buffer = append(h.buffer, ToBits(h.api, in)...)
+	h.compressFull()
+}
 
-	// Append L as a 64-bit big-endian integer.
-	inputLengthBitsBE := api.ToBinaryBE(vars.NewVariableFromInt(len(inBits)), 64)
-	for i := 0; i < len(inputLengthBitsBE); i++ {
-		paddedMessage[len(inBits)+i+1+paddingLength] = inputLengthBitsBE[i]
+// Returns the hash of the message written so far. The hasher must not be used after Sum.
+func (h *Hasher) Sum() [32]vars.Byte {
+	var digest [32]vars.Byte
+	if h.uapi != nil {
+		h.bytes = padBytes(h.bytes, h.length)
+		h.compressFullBytes()
+		for i := 0; i < 8; i++ {
+			for j, b := range h.uapi.UnpackMSB(h.state[i]) {
+				digest[i*4+j] = vars.Byte{Value: vars.Variable{Value: b.Val}}
+			}
+		}
+		return digest
 	}
 
-	// At this point, the padded message should be of the following form.
-	//      <message of length L> 1 <K zeros> <L as 64 bit integer>
-	// Now, we will process the padded message in 512 bit chunks and begin referring to the
-	// padded message as "message".
-	const sha256ChunkLength = 512
-	const sha256WordLength = 32
-	const sha256MessageScheduleArrayLength = 64
+	h.buffer = Pad(h.api, h.buffer, h.length)
+	h.compressFull()
+	copy(digest[:], ToBytes(h.api, h.h[:]))
+	return digest
+}
 
-	message := paddedMessage
-	numChunks := len(message) / sha256ChunkLength
+// Returns the bytes as bytes of the u32s, range checked by the range checker of gnark, which is
+// shared by the whole circuit, except for constants.
+func (h *Hasher) toU8s(in []vars.Byte) []uints.U8 {
+	out := make([]uints.U8, len(in))
+	for i := range in {
+		if c, ok := h.api.FrontendAPI().Compiler().ConstantValue(in[i].Value.Value); ok {
+			if !c.IsUint64() || c.Uint64() > 255 {
+				panic("constant is not a byte")
+			}
+			out[i] = uints.NewU8(uint8(c.Uint64()))
+			continue
+		}
+		out[i] = h.uapi.ByteValueOf(in[i].Value.Value)
+	}
+	return out
+}
 
-	var h [8][32]vars.Bool
+// Pads the bytes of the end of a message of length bytes, as Pad does its bits.
+func padBytes(bytes []uints.U8, length int) []uints.U8 {
+	padded := append([]uints.U8{}, bytes...)
+	padded = append(padded, uints.NewU8(0x80))
+	for len(padded)%(sha256ChunkLength/8) != 56 {
+		padded = append(padded, uints.NewU8(0))
+	}
+	bitLength := uint64(length) * 8
 	for i := 0; i < 8; i++ {
-		h[i] = vars.NewBoolArrayFromU32(H[i])
+		padded = append(padded, uints.NewU8(uint8(bitLength>>(56-8*i))))
 	}
+	return padded
+}
 
-	for i := 0; i < numChunks; i++ {
-		// The 64-entry message schedule array of 32-bit words.
-		var w [sha256MessageScheduleArrayLength][sha256WordLength]vars.Bool
-		for j := 0; j < sha256MessageScheduleArrayLength; j++ {
-			for k := 0; k < sha256WordLength; k++ {
-				w[j][k] = vars.FALSE
-			}
+// Returns the bits of the bytes, with the bits of each byte in big-endian order.
+func ToBits(api builder.API, in []vars.Byte) []vars.Bool {
+	out := make([]vars.Bool, 0, 8*len(in))
+	for i := 0; i < len(in); i++ {
+		bits := api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			out = append(out, bits[7-j])
 		}
+	}
+	return out
+}
 
-		// Copy chunk into first 16 words w[0..15] of the message schedule array.
-		chunkOffset := i * sha256ChunkLength
-		for j := 0; j < 16; j++ {
-			wordOffset := j * 32
-			for k := 0; k < 32; k++ {
-				w[j][k] = message[chunkOffset+wordOffset+k]
+// Returns the big-endian bytes of the words, such as the digest of the state of SHA-1 or SHA-256.
+func ToBytes(api builder.API, words [][32]vars.Bool) []vars.Byte {
+	out := make([]vars.Byte, 0, 4*len(words))
+	for i := range words {
+		for j := 0; j < 4; j++ {
+			var bits [8]vars.Bool
+			for k := 0; k < 8; k++ {
+				bits[7-k] = words[i][8*j+k]
 			}
+			out = append(out, api.ToByteFromBits(bits))
 		}
+	}
+	return out
+}
 
-		// Extend the first 16 words into the remaining 48 words w[16..63].
-		for j := 16; j < sha256MessageScheduleArrayLength; j++ {
-			s0 := bits32.Xor(
-				bits32.Rotate(w[j-15], 7),
-				bits32.Rotate(w[j-15], 18),
-				bits32.Shr(w[j-15], 3),
-			)
-			s1 := bits32.Xor(
-				bits32.Rotate(w[j-2], 17),
-				bits32.Rotate(w[j-2], 19),
-				bits32.Shr(w[j-2], 10),
-			)
-			w[j] = bits32.Add(w[j-16], s0, w[j-7], s1)
-		}
+// Pads the bits of the end of a message of length bytes, shorter than a chunk, to full chunks, as
+// SHA-1 and SHA-256 do: a single '1' bit, "K" zeros and the length in bits "L" as a 64-bit
+// big-endian integer, such that "L + 1 + K + 64" is a multiple of 512.
+//
+//	<message of length L> 1 <K zeros> <L as 64 bit integer>
+func Pad(api builder.API, bits []vars.Bool, length int) []vars.Bool {
+	const seperatorLength = 1
+	const u64BitLength = 64
+	remainderLength := (len(bits) + seperatorLength + u64BitLength) % sha256ChunkLength
+	paddingLength := 0
+	if remainderLength != 0 {
+		paddingLength = sha256ChunkLength - remainderLength
+	}
 
-		sa := h[0]
-		sb := h[1]
-		sc := h[2]
-		sd := h[3]
-		se := h[4]
-		sf := h[5]
-		sg := h[6]
-		sh := h[7]
-
-		numCompressionRounds := 64
-		for j := 0; j < numCompressionRounds; j++ {
-			s1 := bits32.Xor(
-				bits32.Rotate(se, 6),
-				bits32.Rotate(se, 11),
-				bits32.Rotate(se, 25),
-			)
-			ch := bits32.Xor(
-				bits32.And(se, sf),
-				bits32.And(bits32.Not(se), sg),
-			)
-			temp := bits32.Add(sh, s1, ch, vars.NewBoolArrayFromU32(K[j]), w[j])
-			s0 := bits32.Xor(
-				bits32.Rotate(sa, 2),
-				bits32.Rotate(sa, 13),
-				bits32.Rotate(sa, 22),
-			)
-			maj := bits32.Xor(
-				bits32.And(sa, sb),
-				bits32.And(sa, sc),
-				bits32.And(sb, sc),
-			)
-			temp2 := bits32.Add(s0, maj)
-			sh = sg
-			sg = sf
-			sf = se
-			se = bits32.Add(sd, temp)
-			sd = sc
-			sc = sb
-			sb = sa
-			sa = bits32.Add(temp, temp2)
-		}
+	padded := append([]vars.Bool{}, bits...)
+	padded = append(padded, vars.TRUE)
+	for i := 0; i < paddingLength; i++ {
+		padded = append(padded, vars.FALSE)
+	}
+	return append(padded, api.ToBinaryBE(vars.NewVariableFromInt(length*8), u64BitLength)...)
+}
 
-		h[0] = bits32.Add(h[0], sa)
-		h[1] = bits32.Add(h[1], sb)
-		h[2] = bits32.Add(h[2], sc)
-		h[3] = bits32.Add(h[3], sd)
-		h[4] = bits32.Add(h[4], se)
-		h[5] = bits32.Add(h[5], sf)
-		h[6] = bits32.Add(h[6], sg)
-		h[7] = bits32.Add(h[7], sh)
+// Compresses the full chunks of the buffer into the state.
+func (h *Hasher) compressFull() {
+	for len(h.buffer) >= sha256ChunkLength {
+		compress(h.api, &h.h, h.buffer[:sha256ChunkLength])
+		h.buffer = h.buffer[sha256ChunkLength:]
 	}
+}
 
-	var digestBits [256]vars.Bool
-	for i := 0; i < 8; i++ {
-		for j := 0; j < sha256WordLength; j++ {
-			digestBits[i*sha256WordLength+j] = h[i][j]
+// Compresses the full chunks of the buffered bytes into the state, with lookups.
+func (h *Hasher) compressFullBytes() {
+	const chunkBytes = sha256ChunkLength / 8
+	for len(h.bytes) >= chunkBytes {
+		var block [chunkBytes]uints.U8
+		copy(block[:], h.bytes[:chunkBytes])
+		h.state = sha2.Permute(h.uapi, h.state, block)
+		h.bytes = h.bytes[chunkBytes:]
+	}
+}
+
+// Compresses a chunk of 512 bits into the state h.
+func compress(api builder.API, h *[8][32]vars.Bool, chunk []vars.Bool) {
+	bits32 := bits32.NewAPI(api)
+
+	// The 64-entry message schedule array of 32-bit words.
+	var w [sha256MessageScheduleArrayLength][sha256WordLength]vars.Bool
+	for j := 0; j < sha256MessageScheduleArrayLength; j++ {
+		for k := 0; k < sha256WordLength; k++ {
+			w[j][k] = vars.FALSE
 		}
 	}
 
-	var digest [32]vars.Byte
-	for i := 0; i < 32; i++ {
-		var bits [8]vars.Bool
-		for j := 0; j < 8; j++ {
-			bits[7-j] = digestBits[i*8+j]
+	// Copy chunk into first 16 words w[0..15] of the message schedule array.
+	for j := 0; j < 16; j++ {
+		wordOffset := j * 32
+		for k := 0; k < 32; k++ {
+			w[j][k] = chunk[wordOffset+k]
 		}
-		digest[i] = api.ToByteFromBits(bits)
 	}
-	return digest
+
+	// Extend the first 16 words into the remaining 48 words w[16..63].
+	for j := 16; j < sha256MessageScheduleArrayLength; j++ {
+		s0 := bits32.Xor(
+			bits32.Rotate(w[j-15], 7),
+			bits32.Rotate(w[j-15], 18),
+			bits32.Shr(w[j-15], 3),
+		)
+		s1 := bits32.Xor(
+			bits32.Rotate(w[j-2], 17),
+			bits32.Rotate(w[j-2], 19),
+			bits32.Shr(w[j-2], 10),
+		)
+		w[j] = bits32.Add(w[j-16], s0, w[j-7], s1)
+	}
+
+	sa := h[0]
+	sb := h[1]
+	sc := h[2]
+	sd := h[3]
+	se := h[4]
+	sf := h[5]
+	sg := h[6]
+	sh := h[7]
+
+	numCompressionRounds := 64
+	for j := 0; j < numCompressionRounds; j++ {
+		s1 := bits32.Xor(
+			bits32.Rotate(se, 6),
+			bits32.Rotate(se, 11),
+			bits32.Rotate(se, 25),
+		)
+		ch := bits32.Select(se, sf, sg)
+		s0 := bits32.Xor(
+			bits32.Rotate(sa, 2),
+			bits32.Rotate(sa, 13),
+			bits32.Rotate(sa, 22),
+		)
+		maj := bits32.Majority(sa, sb, sc)
+		k := vars.NewBoolArrayFromU32(K[j])
+
+		// The sums temp1 = h + S1 + ch + k + w and temp2 = S0 + maj are only decomposed into
+		// bits as part of e = d + temp1 and a = temp1 + temp2.
+		e := bits32.Add(sd, sh, s1, ch, k, w[j])
+		a := bits32.Add(sh, s1, ch, k, w[j], s0, maj)
+		sh = sg
+		sg = sf
+		sf = se
+		se = e
+		sd = sc
+		sc = sb
+		sb = sa
+		sa = a
+	}
+
+	h[0] = bits32.Add(h[0], sa)
+	h[1] = bits32.Add(h[1], sb)
+	h[2] = bits32.Add(h[2], sc)
+	h[3] = bits32.Add(h[3], sd)
+	h[4] = bits32.Add(h[4], se)
+	h[5] = bits32.Add(h[5], sf)
+	h[6] = bits32.Add(h[6], sg)
+	h[7] = bits32.Add(h[7], sh)
 }
 
 // Computes sha256(in) && ((1 << nbBits) - 1).
@@ -208,12 +320,19 @@ func HashAndTruncate(api builder.API, in []vars.Byte, nbBits int) vars.Variable
 	}
 
 	// Accumulate with bit digits until we get to the last relevant bit.
-	lastByte := hash[nbBytes]
-	lastByteBits := api.ToBitsFromByte(lastByte)
 	lastByteNbBits := nbBits % 8
+	if lastByteNbBits == 0 {
+		return acc
+	}
+	lastByteBits := api.ToBitsFromByte(hash[nbBytes])
 	for i := 0; i < lastByteNbBits; i++ {
 		power := vars.NewVariableFromString(new(big.Int).Lsh(big.NewInt(1), uint(i+nbBytes*8)).String())
 		acc = api.Add(acc, api.Mul(power, lastByteBits[i].Value))
 	}
 	return acc
 }
+
+// Computes sha256(in) packed into two elements by API.PackDigest, the high and the low 128 bits.
+func HashToFieldElements(api builder.API, in []vars.Byte) [2]vars.Variable {
+	return api.PackDigest(Hash(api, in))
+}
@@ -1,24 +1,32 @@
 package sha256
 
 import (
+	gosha256 "crypto/sha256"
 	"encoding/hex"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/test"
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
 type TestSha256Circuit struct {
-	In  []vars.Byte `gnark:"in"`
-	Out []vars.Byte `gnark:"out"`
+	In      []vars.Byte `gnark:"in"`
+	Out     []vars.Byte `gnark:"out"`
+	Lookups bool        `gnark:"-"`
 }
 
 func (circuit *TestSha256Circuit) Define(api frontend.API) error {
 	succinctAPI := builder.NewAPI(api)
+	if circuit.Lookups {
+		succinctAPI = builder.NewAPI(api, builder.WithLookups())
+	}
 	res := Hash(*succinctAPI, circuit.In)
 	if len(res) != 32 {
 		panic("bad length")
@@ -40,21 +48,29 @@ func TestSha256Witness(t *testing.T) {
 		if len(out) != 256/8 {
 			panic("bad output length")
 		}
-		circuit := TestSha256Circuit{
-			In:  vars.NewBytesFrom(in),
-			Out: vars.NewBytesFrom(out),
-		}
-		witness := TestSha256Circuit{
-			In:  vars.NewBytesFrom(in),
-			Out: vars.NewBytesFrom(out),
+		for _, lookups := range []bool{false, true} {
+			circuit := TestSha256Circuit{
+				In:      vars.NewBytes(len(in)),
+				Out:     vars.NewBytes(len(out)),
+				Lookups: lookups,
+			}
+			witness := TestSha256Circuit{
+				In:  vars.NewBytesFrom(in),
+				Out: vars.NewBytesFrom(out),
+			}
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+
+			witness.Out[0].Set(witness.Out[0].GetValueUnsafe() ^ 1)
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.Error(err)
 		}
-		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
-		assert.NoError(err)
 	}
 
 	testCase([]byte("Succinct Labs"), "7fb4acc57b9765e167a716dee0d19c5dce851cfa140dbce7fff42a3e589ab470")
 	testCase([]byte("i love polynomials"), "f9d31346a1b4b014dcdd3d9c700f7c4a017383ac8fb6502257a58596011b598f")
 	testCase([]byte("jtguibas"), "11490498ac6480d6fefe1c01e639875cee3b4ec3f96265eb76701f65da99ea8c")
+	testCase(make([]byte, 64), "f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a92759fb4b")
 }
 
 func TestSha256Proof(t *testing.T) {
@@ -83,3 +99,234 @@ func TestSha256Proof(t *testing.T) {
 
 	testCase([]byte("Succinct Labs"), "7fb4acc57b9765e167a716dee0d19c5dce851cfa140dbce7fff42a3e589ab470")
 }
+
+func TestSha256Constraints(t *testing.T) {
+	// The words are summed as field elements, so a block costs less than 27k constraints instead of
+	// the ~97k of adding them bit by bit.
+	circuit := TestSha256Circuit{In: vars.NewBytes(55), Out: vars.NewBytes(32)}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := ccs.GetNbConstraints(); n > 27000 {
+		t.Fatalf("%d constraints for a block", n)
+	}
+}
+
+func TestSha256Lookups(t *testing.T) {
+	compile := func(lookups bool, n int) int {
+		circuit := TestSha256Circuit{In: vars.NewBytes(n), Out: vars.NewBytes(32), Lookups: lookups}
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ccs.GetNbConstraints()
+	}
+
+	// Past the fixed cost of the tables, the blocks compressed with lookups take fewer constraints
+	// than with bits with Groth16.
+	bitsCost := compile(false, 55+4*64) - compile(false, 55)
+	lookupsCost := compile(true, 55+4*64) - compile(true, 55)
+	t.Logf("4 blocks: %d constraints with bits, %d with lookups", bitsCost, lookupsCost)
+	if lookupsCost >= bitsCost {
+		t.Fatalf("%d constraints with lookups, %d with bits", lookupsCost, bitsCost)
+	}
+}
+
+// The circuit hashes a constant prefix from its midstate followed by the parts of the suffix.
+type TestSha256HasherCircuit struct {
+	Midstate     [8]uint32 `gnark:"-"`
+	PrefixLength int       `gnark:"-"`
+	Parts        [][]vars.Byte
+	Out          [32]vars.Byte
+	Lookups      bool `gnark:"-"`
+}
+
+func (circuit *TestSha256HasherCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	if circuit.Lookups {
+		succinctAPI = builder.NewAPI(api, builder.WithLookups())
+	}
+	hasher := NewHasherFromMidstate(*succinctAPI, circuit.Midstate, circuit.PrefixLength)
+	for _, part := range circuit.Parts {
+		hasher.Write(part)
+	}
+	res := hasher.Sum()
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSha256Hasher(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	message := make([]byte, 300)
+	for i := range message {
+		message[i] = byte(i * 7)
+	}
+	testCase := func(lookups bool, prefixLength int, partLengths ...int) {
+		circuit := TestSha256HasherCircuit{
+			Midstate:     sha256utils.Midstate(message[:prefixLength]),
+			PrefixLength: prefixLength,
+			Lookups:      lookups,
+		}
+		witness := TestSha256HasherCircuit{}
+		offset := prefixLength
+		for _, length := range partLengths {
+			circuit.Parts = append(circuit.Parts, vars.NewBytes(length))
+			witness.Parts = append(witness.Parts, vars.NewBytesFrom(message[offset:offset+length]))
+			offset += length
+		}
+		vars.SetBytes32(&witness.Out, gosha256.Sum256(message[:offset]))
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+
+		// The prefix is part of the message.
+		message[0] ^= 1
+		vars.SetBytes32(&witness.Out, gosha256.Sum256(message[:offset]))
+		message[0] ^= 1
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		if prefixLength > 0 {
+			assert.Error(err)
+		}
+	}
+
+	for _, lookups := range []bool{false, true} {
+		testCase(lookups, 0)
+		testCase(lookups, 0, 10, 60, 0, 70)
+		testCase(lookups, 64, 0)
+		testCase(lookups, 128, 55)
+		testCase(lookups, 192, 40, 40)
+	}
+}
+
+func TestSha256MidstateConstraints(t *testing.T) {
+	// The chunks of the prefix cost no constraints.
+	circuit := TestSha256HasherCircuit{
+		Midstate:     sha256utils.Midstate(make([]byte, 128)),
+		PrefixLength: 128,
+		Parts:        [][]vars.Byte{vars.NewBytes(55)},
+	}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := ccs.GetNbConstraints(); n > 27000 {
+		t.Fatalf("%d constraints for a block after a midstate", n)
+	}
+}
+
+func FuzzSha256(f *testing.F) {
+	target := fuzz.Target{
+		Name: "sha256",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			res := Hash(api, in)
+			for i := 0; i < 32; i++ {
+				api.AssertIsEqualByte(res[i], out[i])
+			}
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			h := gosha256.Sum256(in)
+			return h[:], true
+		},
+		OutputLength: 32,
+		MaxLength:    256,
+	}
+	target.Fuzz(f, []byte(""), []byte("Succinct Labs"), make([]byte, 55), make([]byte, 56), make([]byte, 64))
+}
+
+type TestSha256VariableCircuit struct {
+	In        []vars.Byte
+	Length    vars.U64
+	Out       [32]vars.Byte
+	MaxLength int `gnark:"-"`
+}
+
+func (circuit *TestSha256VariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length, circuit.MaxLength)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSha256VariableWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The lengths cover padding that fits in the last block of the message and padding that
+	// needs an extra block.
+	maxLength := 150
+	for _, length := range []int{0, 13, 55, 56, 64, 119, 120, 141, 150} {
+		in := make([]byte, maxLength)
+		for i := 0; i < length; i++ {
+			in[i] = byte(i * 7)
+		}
+		// Bytes past the length must not change the digest.
+		for i := length; i < maxLength; i++ {
+			in[i] = 0xff
+		}
+		digest := gosha256.Sum256(in[:length])
+
+		circuit := TestSha256VariableCircuit{In: vars.NewBytes(maxLength), MaxLength: maxLength}
+		witness := TestSha256VariableCircuit{In: vars.NewBytesFrom(in)}
+		witness.Length.Set(uint64(length))
+		vars.SetBytes32(&witness.Out, digest)
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+
+	// The length must be at most the max length, even if the input has room for more bytes.
+	in := make([]byte, maxLength)
+	for _, length := range []uint64{101, 120, 1 << 40} {
+		circuit := TestSha256VariableCircuit{In: vars.NewBytes(maxLength), MaxLength: 100}
+		witness := TestSha256VariableCircuit{In: vars.NewBytesFrom(in)}
+		witness.Length.Set(length)
+		vars.SetBytes32(&witness.Out, gosha256.Sum256(in[:length%uint64(maxLength)]))
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.Error(err)
+	}
+
+	// The max length must fit in the input.
+	circuit := TestSha256VariableCircuit{In: vars.NewBytes(maxLength), MaxLength: maxLength + 1}
+	witness := TestSha256VariableCircuit{In: vars.NewBytesFrom(in)}
+	witness.Length.Set(0)
+	vars.SetBytes32(&witness.Out, gosha256.Sum256(nil))
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.ErrorContains(err, "max length 151 exceeds")
+}
+
+type TestSha256FieldElementsCircuit struct {
+	In  []vars.Byte
+	Out [2]vars.Variable
+}
+
+func (circuit *TestSha256FieldElementsCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashToFieldElements(*succinctAPI, circuit.In)
+	for i := 0; i < 2; i++ {
+		succinctAPI.AssertIsEqual(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSha256FieldElementsWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := []byte("Succinct Labs")
+	out := sha256utils.HashToFieldElements(in)
+	assert.Equal("7fb4acc57b9765e167a716dee0d19c5d", out[0].Text(16))
+	assert.Equal("ce851cfa140dbce7fff42a3e589ab470", out[1].Text(16))
+
+	circuit := TestSha256FieldElementsCircuit{In: vars.NewBytes(len(in))}
+	witness := TestSha256FieldElementsCircuit{In: vars.NewBytesFrom(in)}
+	witness.Out[0].Set(out[0])
+	witness.Out[1].Set(out[1])
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	witness.Out[0], witness.Out[1] = witness.Out[1], witness.Out[0]
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
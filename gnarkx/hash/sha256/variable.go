@@ -0,0 +1,99 @@
+package sha256
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/permutation/sha2"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the SHA256-2 hash of the first length bytes of in, where length is only known at proving
+// time. Every block that could hold a message of up to maxLength bytes is compressed and the
+// digest after the last block is selected, so one circuit hashes inputs of any length up to
+// maxLength. The length is range checked against maxLength, which must be at most len(in), and
+// bytes of in past length are ignored.
+func HashVariable(api builder.API, in []vars.Byte, length vars.U64, maxLength int) [32]vars.Byte {
+	if maxLength > len(in) {
+		panic(fmt.Sprintf("max length %d exceeds the %d bytes of the input", maxLength, len(in)))
+	}
+	in = in[:maxLength]
+	fapi := api.FrontendAPI()
+	uapi, err := uints.New[uints.U32](fapi)
+	if err != nil {
+		panic(err)
+	}
+
+	api.AssertIsLessOrEqual(length.Value, vars.NewVariableFromInt(maxLength))
+
+	// The message needs at least 9 more bytes for the separator and the 64-bit length.
+	nbBlocks := (len(in) + 9 + 63) / 64
+	lastBlock := api.ToBinaryLE(api.Add(length.Value, vars.NewVariableFromInt(8)), 32)[6:]
+	lastBlockIndex := frontend.Variable(0)
+	for i := 0; i < len(lastBlock); i++ {
+		lastBlockIndex = fapi.Add(lastBlockIndex, fapi.Mul(lastBlock[i].Value.Value, 1<<i))
+	}
+	isLast := make([]frontend.Variable, nbBlocks)
+	nbLast := frontend.Variable(0)
+	for i := 0; i < nbBlocks; i++ {
+		isLast[i] = fapi.IsZero(fapi.Sub(lastBlockIndex, i))
+		nbLast = fapi.Add(nbLast, isLast[i])
+	}
+	// The last block must be one of the blocks, i.e. length <= 64 * nbBlocks - 9.
+	fapi.AssertIsEqual(nbLast, 1)
+
+	lengthBits := api.ToBinaryBE(api.Mul(length.Value, vars.NewVariableFromInt(8)), 64)
+	lengthBytes := make([]frontend.Variable, 8)
+	for i := 0; i < 8; i++ {
+		var bits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			bits[j] = lengthBits[i*8+7-j]
+		}
+		lengthBytes[i] = api.ToByteFromBits(bits).Value.Value
+	}
+
+	// Builds the padded message: <message> 0x80 <zeros> <length in bits>, where the length is
+	// placed at the end of the last block.
+	inMessage := frontend.Variable(1)
+	padded := make([]uints.U8, nbBlocks*64)
+	for i := 0; i < len(padded); i++ {
+		isEnd := fapi.IsZero(fapi.Sub(length.Value.Value, i))
+		inMessage = fapi.Sub(inMessage, isEnd)
+		value := fapi.Mul(isEnd, 0x80)
+		if i < len(in) {
+			value = fapi.Add(value, fapi.Mul(inMessage, in[i].Value.Value))
+		}
+		if i%64 >= 56 {
+			value = fapi.Add(value, fapi.Mul(isLast[i/64], lengthBytes[i%64-56]))
+		}
+		padded[i] = uints.U8{Val: value}
+	}
+
+	var state [8]uints.U32
+	for i := 0; i < 8; i++ {
+		state[i] = uints.NewU32(H[i])
+	}
+	digest := make([]frontend.Variable, 32)
+	for i := 0; i < 32; i++ {
+		digest[i] = frontend.Variable(0)
+	}
+	for b := 0; b < nbBlocks; b++ {
+		var block [64]uints.U8
+		copy(block[:], padded[b*64:(b+1)*64])
+		state = sha2.Permute(uapi, state, block)
+		for i := 0; i < 8; i++ {
+			bytes := uapi.UnpackMSB(state[i])
+			for j := 0; j < 4; j++ {
+				digest[i*4+j] = fapi.Add(digest[i*4+j], fapi.Mul(isLast[b], bytes[j].Val))
+			}
+		}
+	}
+
+	var result [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		result[i] = vars.Byte{Value: vars.Variable{Value: digest[i]}}
+	}
+	return result
+}
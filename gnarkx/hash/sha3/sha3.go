@@ -0,0 +1,212 @@
+// The API for SHA-3 and SHAKE of FIPS 202 and the Keccak sponge they instantiate, which differ from
+// the Keccak-256 of Ethereum only in the rate and the domain separation of their padding.
+// Reference: https://nvlpubs.nist.gov/nistpubs/FIPS/NIST.FIPS.202.pdf
+package sha3
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/permutation/keccakf"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Sponge is the Keccak sponge over Keccak-f[1600] with a rate, the number of bytes absorbed and
+// squeezed per permutation, and a domain separation suffix, the bits appended to the message before
+// the pad10*1 padding, as the first padding byte.
+type Sponge struct {
+	Rate   int
+	Suffix byte
+}
+
+// The instances of the sponge of FIPS 202 and the original Keccak-256.
+var (
+	SHA3_256  = Sponge{Rate: 136, Suffix: 0x06}
+	SHA3_512  = Sponge{Rate: 72, Suffix: 0x06}
+	SHAKE128  = Sponge{Rate: 168, Suffix: 0x1f}
+	SHAKE256  = Sponge{Rate: 136, Suffix: 0x1f}
+	Keccak256 = Sponge{Rate: 136, Suffix: 0x01}
+)
+
+// Computes the SHA3-256 hash of the input bytes, where len(in) is a compile time constant.
+func Sum256(api builder.API, in []vars.Byte) [32]vars.Byte {
+	var out [32]vars.Byte
+	copy(out[:], SHA3_256.Hash(api, in, 32))
+	return out
+}
+
+// Computes the SHA3-512 hash of the input bytes, where len(in) is a compile time constant.
+func Sum512(api builder.API, in []vars.Byte) [64]vars.Byte {
+	var out [64]vars.Byte
+	copy(out[:], SHA3_512.Hash(api, in, 64))
+	return out
+}
+
+// Computes outLength bytes of the SHAKE128 output of the input bytes.
+func ShakeSum128(api builder.API, in []vars.Byte, outLength int) []vars.Byte {
+	return SHAKE128.Hash(api, in, outLength)
+}
+
+// Computes outLength bytes of the SHAKE256 output of the input bytes.
+func ShakeSum256(api builder.API, in []vars.Byte, outLength int) []vars.Byte {
+	return SHAKE256.Hash(api, in, outLength)
+}
+
+// Absorbs the input bytes and squeezes outLength bytes, where both lengths are compile time
+// constants, so that the padding costs no constraints. The bytes of in are assumed to be range
+// checked.
+func (s Sponge) Hash(api builder.API, in []vars.Byte, outLength int) []vars.Byte {
+	s.validate()
+	uapi := newUints(api)
+
+	// The padded message: <message> <suffix> <zeros> 0x80, where the suffix and 0x80 are or'ed
+	// together if the message ends one byte before a block.
+	nbBlocks := len(in)/s.Rate + 1
+	padding := make([]byte, nbBlocks*s.Rate-len(in))
+	padding[0] |= s.Suffix
+	padding[len(padding)-1] |= 0x80
+	padded := make([]uints.U8, nbBlocks*s.Rate)
+	for i := 0; i < len(padded); i++ {
+		if i < len(in) {
+			padded[i] = uints.U8{Val: in[i].Value.Value}
+		} else {
+			padded[i] = uints.NewU8(padding[i-len(in)])
+		}
+	}
+
+	var state [25]uints.U64
+	for i := 0; i < 25; i++ {
+		state[i] = uints.NewU64(0)
+	}
+	for b := 0; b < nbBlocks; b++ {
+		state = s.absorb(uapi, state, padded[b*s.Rate:(b+1)*s.Rate])
+	}
+
+	// Squeezes the rate of the state, permuting between blocks of the output.
+	out := make([]vars.Byte, 0, outLength)
+	for len(out) < outLength {
+		if len(out) > 0 {
+			state = keccakf.Permute(uapi, state)
+		}
+		for i := 0; i < s.Rate/8 && len(out) < outLength; i++ {
+			bytes := uapi.UnpackLSB(state[i])
+			for j := 0; j < 8 && len(out) < outLength; j++ {
+				out = append(out, vars.Byte{Value: vars.Variable{Value: bytes[j].Val}})
+			}
+		}
+	}
+	return out
+}
+
+// Absorbs the first length bytes of in and squeezes outLength bytes, where length is only known at
+// proving time and must be at most len(in), and outLength is at most the rate. Every block that
+// could be part of the message is absorbed and the output after the last block is selected. Bytes
+// of in past length are ignored, and those before are assumed to be range checked.
+func (s Sponge) HashVariable(api builder.API, in []vars.Byte, length vars.Variable, outLength int) []vars.Byte {
+	s.validate()
+	if outLength > s.Rate {
+		panic(fmt.Sprintf("output length %d exceeds the rate %d", outLength, s.Rate))
+	}
+	fapi := api.FrontendAPI()
+	uapi := newUints(api)
+
+	padded, isLast := s.Pad(api, in, length)
+	block := make([]uints.U8, s.Rate)
+	var state [25]uints.U64
+	for i := 0; i < 25; i++ {
+		state[i] = uints.NewU64(0)
+	}
+	out := make([]frontend.Variable, outLength)
+	for i := range out {
+		out[i] = frontend.Variable(0)
+	}
+	for b := range isLast {
+		for i := range block {
+			block[i] = uints.U8{Val: padded[b*s.Rate+i]}
+		}
+		state = s.absorb(uapi, state, block)
+		for i := 0; i < outLength; i += 8 {
+			bytes := uapi.UnpackLSB(state[i/8])
+			for j := 0; j < 8 && i+j < outLength; j++ {
+				out[i+j] = fapi.Add(out[i+j], fapi.Mul(isLast[b], bytes[j].Val))
+			}
+		}
+	}
+
+	result := make([]vars.Byte, outLength)
+	for i := range result {
+		result[i] = vars.Byte{Value: vars.Variable{Value: out[i]}}
+	}
+	return result
+}
+
+// Returns the padded message of the first length bytes of in, which must be at most len(in), and
+// whether each of its blocks is the last one, for a length only known at proving time. Permutations
+// other than the one on bytes, such as the packed one of the keccak256 package, absorb it as
+// HashVariable does.
+func (s Sponge) Pad(api builder.API, in []vars.Byte, length vars.Variable) ([]frontend.Variable, []frontend.Variable) {
+	s.validate()
+	fapi := api.FrontendAPI()
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(len(in)))
+
+	// The message needs at least 1 more byte for the padding.
+	nbBlocks := (len(in) + 1 + s.Rate - 1) / s.Rate
+
+	// Builds the padded message: <message> <suffix> <zeros> 0x80, where the last byte of the last
+	// block is or'ed with 0x80 and the last block is the block of the byte at index length.
+	inMessage := frontend.Variable(1)
+	isLast := make([]frontend.Variable, nbBlocks)
+	padded := make([]frontend.Variable, nbBlocks*s.Rate)
+	for i := 0; i < len(padded); i++ {
+		isEnd := fapi.IsZero(fapi.Sub(length.Value, i))
+		inMessage = fapi.Sub(inMessage, isEnd)
+		if i%s.Rate == 0 {
+			isLast[i/s.Rate] = frontend.Variable(0)
+		}
+		isLast[i/s.Rate] = fapi.Add(isLast[i/s.Rate], isEnd)
+		value := fapi.Mul(isEnd, s.Suffix)
+		if i < len(in) {
+			value = fapi.Add(value, fapi.Mul(inMessage, in[i].Value.Value))
+		}
+		padded[i] = value
+	}
+	nbLast := frontend.Variable(0)
+	for b := 0; b < nbBlocks; b++ {
+		nbLast = fapi.Add(nbLast, isLast[b])
+		last := b*s.Rate + s.Rate - 1
+		padded[last] = fapi.Add(padded[last], fapi.Mul(isLast[b], 0x80))
+	}
+	fapi.AssertIsEqual(nbLast, 1)
+	return padded, isLast
+}
+
+// Xors the block of rate bytes into the state and permutes it.
+func (s Sponge) absorb(uapi *uints.BinaryField[uints.U64], state [25]uints.U64, block []uints.U8) [25]uints.U64 {
+	for i := 0; i < s.Rate/8; i++ {
+		lane := uapi.PackLSB(block[i*8 : i*8+8]...)
+		state[i] = uapi.Xor(state[i], lane)
+	}
+	return keccakf.Permute(uapi, state)
+}
+
+// Panics if the rate or the suffix of the sponge are invalid.
+func (s Sponge) validate() {
+	if s.Rate <= 0 || s.Rate%8 != 0 || s.Rate >= 200 {
+		panic(fmt.Sprintf("invalid rate %d of a sponge", s.Rate))
+	}
+	if s.Suffix == 0 || s.Suffix&0x80 != 0 {
+		panic(fmt.Sprintf("invalid domain suffix %#x of a sponge", s.Suffix))
+	}
+}
+
+// Returns the operations on 64-bit lanes, whose bitwise operations are lookups in the tables of
+// gnark's uints, shared with the u32s of a builder.API created WithLookups.
+func newUints(api builder.API) *uints.BinaryField[uints.U64] {
+	uapi, err := uints.New[uints.U64](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	return uapi
+}
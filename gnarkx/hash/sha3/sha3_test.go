@@ -0,0 +1,166 @@
+package sha3
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	gosha3 "golang.org/x/crypto/sha3"
+)
+
+type TestSpongeCircuit struct {
+	Sponge Sponge `gnark:"-"`
+	In     []vars.Byte
+	Out    []vars.Byte
+}
+
+func (circuit *TestSpongeCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := circuit.Sponge.Hash(*succinctAPI, circuit.In, len(circuit.Out))
+	for i := range res {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSponge(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(sponge Sponge, in []byte, out []byte) {
+		circuit := TestSpongeCircuit{Sponge: sponge, In: vars.NewBytes(len(in)), Out: vars.NewBytes(len(out))}
+		witness := TestSpongeCircuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out)}
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+
+	message := make([]byte, 200)
+	for i := range message {
+		message[i] = byte(i*31 + 7)
+	}
+	// The lengths around the rates of the sponges, where the padding takes a block of its own.
+	for _, length := range []int{0, 71, 135, 136, 167} {
+		in := message[:length]
+		sum256 := gosha3.Sum256(in)
+		testCase(SHA3_256, in, sum256[:])
+		sum512 := gosha3.Sum512(in)
+		testCase(SHA3_512, in, sum512[:])
+		keccak := gosha3.NewLegacyKeccak256()
+		keccak.Write(in)
+		testCase(Keccak256, in, keccak.Sum(nil))
+
+		// The outputs longer than the rate are squeezed from more than one permutation.
+		shake128 := make([]byte, 200)
+		gosha3.ShakeSum128(shake128, in)
+		testCase(SHAKE128, in, shake128)
+		shake256 := make([]byte, 300)
+		gosha3.ShakeSum256(shake256, in)
+		testCase(SHAKE256, in, shake256)
+	}
+}
+
+type TestSpongeVariableCircuit struct {
+	Sponge Sponge `gnark:"-"`
+	In     []vars.Byte
+	Length vars.Variable
+	Out    []vars.Byte
+}
+
+func (circuit *TestSpongeVariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := circuit.Sponge.HashVariable(*succinctAPI, circuit.In, circuit.Length, len(circuit.Out))
+	for i := range res {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSpongeVariable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	message := make([]byte, 200)
+	for i := range message {
+		message[i] = byte(i*31 + 7)
+	}
+	circuit := TestSpongeVariableCircuit{Sponge: SHA3_256, In: vars.NewBytes(len(message)), Out: vars.NewBytes(32)}
+	for _, length := range []int{0, 135, 136, 200} {
+		sum256 := gosha3.Sum256(message[:length])
+		witness := TestSpongeVariableCircuit{
+			In:     vars.NewBytesFrom(message),
+			Length: vars.NewVariableFromInt(length),
+			Out:    vars.NewBytesFrom(sum256[:]),
+		}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+		// The output is of the first length bytes.
+		witness.Length = vars.NewVariableFromInt(length / 2)
+		if length > 0 {
+			assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+		}
+	}
+}
+
+type TestSumCircuit struct {
+	In       []vars.Byte
+	Out256   [32]vars.Byte
+	Out512   [64]vars.Byte
+	OutShake []vars.Byte
+}
+
+func (circuit *TestSumCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res256 := Sum256(*succinctAPI, circuit.In)
+	for i := range res256 {
+		succinctAPI.AssertIsEqualByte(res256[i], circuit.Out256[i])
+	}
+	res512 := Sum512(*succinctAPI, circuit.In)
+	for i := range res512 {
+		succinctAPI.AssertIsEqualByte(res512[i], circuit.Out512[i])
+	}
+	resShake := ShakeSum256(*succinctAPI, circuit.In, len(circuit.OutShake))
+	for i := range resShake {
+		succinctAPI.AssertIsEqualByte(resShake[i], circuit.OutShake[i])
+	}
+	return nil
+}
+
+func TestSum(t *testing.T) {
+	in := []byte("Succinct Labs")
+	circuit := TestSumCircuit{In: vars.NewBytes(len(in)), OutShake: vars.NewBytes(20)}
+	witness := TestSumCircuit{In: vars.NewBytesFrom(in)}
+	sum256 := gosha3.Sum256(in)
+	copy(witness.Out256[:], vars.NewBytesFrom(sum256[:]))
+	sum512 := gosha3.Sum512(in)
+	copy(witness.Out512[:], vars.NewBytesFrom(sum512[:]))
+	shake := make([]byte, 20)
+	gosha3.ShakeSum256(shake, in)
+	witness.OutShake = vars.NewBytesFrom(shake)
+	assert := test.NewAssert(t)
+	assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+	// The output is bound to the input.
+	witness.In[0].Set('s')
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+}
+
+func FuzzSha3(f *testing.F) {
+	target := fuzz.Target{
+		Name: "sha3",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			res := Sum256(api, in)
+			for i := 0; i < 32; i++ {
+				api.AssertIsEqualByte(res[i], out[i])
+			}
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			h := gosha3.Sum256(in)
+			return h[:], true
+		},
+		OutputLength: 32,
+		MaxLength:    256,
+	}
+	target.Fuzz(f, []byte(""), []byte("Succinct Labs"), make([]byte, 135), make([]byte, 136))
+}
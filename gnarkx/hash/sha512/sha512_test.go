@@ -1,12 +1,16 @@
 package sha512
 
 import (
+	gosha512 "crypto/sha512"
 	"encoding/hex"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
 type TestSha512Circuit struct {
@@ -56,6 +60,121 @@ func TestSha512Witness(t *testing.T) {
 	testCase(decode("35c323757c20640a294345c89c0bfcebe3d554fdb0c7b7a0bdb72222c531b1ecf7ec1c43f4de9d49556de87b86b26a98942cb078486fdb44de38b80864c3973153756363696e6374204c616273"), "4388243c4452274402673de881b2f942ff5730fd2c7d8ddb94c3e3d789fb3754380cba8faa40554d9506a0730a681e88ab348a04bc5c41d18926f140b59aed39")
 }
 
+type TestSha512BytesCircuit struct {
+	In  []vars.Byte
+	Out [64]vars.Byte
+}
+
+func (circuit *TestSha512BytesCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := Hash(*succinctAPI, circuit.In)
+	for i := 0; i < 64; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSha512BytesWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The lengths cover padding that fits in the last block and padding that needs an extra block.
+	for _, length := range []int{0, 13, 111, 112, 200} {
+		in := make([]byte, length)
+		for i := range in {
+			in[i] = byte(i * 7)
+		}
+		digest := gosha512.Sum512(in)
+
+		circuit := TestSha512BytesCircuit{In: vars.NewBytes(length)}
+		witness := TestSha512BytesCircuit{In: vars.NewBytesFrom(in)}
+		for i := range digest {
+			witness.Out[i].Set(digest[i])
+		}
+		err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+		assert.NoError(err)
+
+		witness.Out[63].Set(digest[63] ^ 1)
+		err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+		assert.Error(err)
+	}
+}
+
+type TestSha512VariableCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [64]vars.Byte
+}
+
+func (circuit *TestSha512VariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	for i := 0; i < 64; i++ {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestSha512VariableWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The lengths cover padding that fits in the last block of the message and padding that
+	// needs an extra block.
+	maxLength := 240
+	for _, length := range []int{0, 13, 111, 112, 128, 239, 240} {
+		in := make([]byte, maxLength)
+		for i := 0; i < length; i++ {
+			in[i] = byte(i * 7)
+		}
+		// Bytes past the length must not change the digest.
+		for i := length; i < maxLength; i++ {
+			in[i] = 0xff
+		}
+		digest := gosha512.Sum512(in[:length])
+
+		circuit := TestSha512VariableCircuit{In: vars.NewBytes(maxLength)}
+		witness := TestSha512VariableCircuit{
+			In:     vars.NewBytesFrom(in),
+			Length: vars.NewVariableFromInt(length),
+		}
+		for i := range digest {
+			witness.Out[i].Set(digest[i])
+		}
+		err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+		assert.NoError(err)
+	}
+
+	circuit := TestSha512VariableCircuit{In: vars.NewBytes(maxLength)}
+	witness := TestSha512VariableCircuit{
+		In:     vars.NewBytesFrom(make([]byte, maxLength)),
+		Length: vars.NewVariableFromInt(maxLength + 1),
+	}
+	digest := gosha512.Sum512(nil)
+	for i := range digest {
+		witness.Out[i].Set(digest[i])
+	}
+	err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.Error(err)
+}
+
+func FuzzSha512(f *testing.F) {
+	target := fuzz.Target{
+		Name: "sha512",
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			res := Hash(api, in)
+			for i := 0; i < 64; i++ {
+				api.AssertIsEqualByte(res[i], out[i])
+			}
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			h := gosha512.Sum512(in)
+			return h[:], true
+		},
+		OutputLength: 64,
+		MaxLength:    256,
+	}
+	target.Fuzz(f, []byte(""), []byte("Succinct Labs"), make([]byte, 111), make([]byte, 112))
+}
+
 func toBits(arr []byte) []frontend.Variable {
 	result := make([]frontend.Variable, len(arr)*8)
 	for i, v := range arr {
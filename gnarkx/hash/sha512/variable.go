@@ -0,0 +1,86 @@
+package sha512
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the SHA512-2 hash of the first length bytes of in, where length is only known at proving
+// time and must be at most len(in). As sha256.HashVariable, every block that could be part of the
+// message is compressed and the digest after the last block is selected.
+func HashVariable(api builder.API, in []vars.Byte, length vars.Variable) [64]vars.Byte {
+	fapi := api.FrontendAPI()
+
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(len(in)))
+
+	// The message needs at least 17 more bytes for the separator and the 128-bit length.
+	nbBlocks := (len(in) + 17 + 127) / 128
+	lastBlock := api.ToBinaryLE(api.Add(length, vars.NewVariableFromInt(16)), 32)[7:]
+	lastBlockIndex := frontend.Variable(0)
+	for i := 0; i < len(lastBlock); i++ {
+		lastBlockIndex = fapi.Add(lastBlockIndex, fapi.Mul(lastBlock[i].Value.Value, 1<<i))
+	}
+	isLast := make([]frontend.Variable, nbBlocks)
+	nbLast := frontend.Variable(0)
+	for i := 0; i < nbBlocks; i++ {
+		isLast[i] = fapi.IsZero(fapi.Sub(lastBlockIndex, i))
+		nbLast = fapi.Add(nbLast, isLast[i])
+	}
+	// The last block must be one of the blocks, i.e. length <= 128 * nbBlocks - 17.
+	fapi.AssertIsEqual(nbLast, 1)
+
+	// The length in bits is less than 2^64, so only the last 8 bytes of the 128-bit length are set.
+	lengthBits := api.ToBinaryBE(api.Mul(length, vars.NewVariableFromInt(8)), 64)
+	lengthBytes := make([]frontend.Variable, 8)
+	for i := 0; i < 8; i++ {
+		var bits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			bits[j] = lengthBits[i*8+7-j]
+		}
+		lengthBytes[i] = api.ToByteFromBits(bits).Value.Value
+	}
+
+	// Builds the big-endian bits of the padded message: <message> 0x80 <zeros> <length in bits>,
+	// where the length is placed at the end of the last block.
+	inMessage := frontend.Variable(1)
+	padded := make([]frontend.Variable, 0, nbBlocks*128*8)
+	for i := 0; i < nbBlocks*128; i++ {
+		isEnd := fapi.IsZero(fapi.Sub(length.Value, i))
+		inMessage = fapi.Sub(inMessage, isEnd)
+		value := fapi.Mul(isEnd, 0x80)
+		if i < len(in) {
+			value = fapi.Add(value, fapi.Mul(inMessage, in[i].Value.Value))
+		}
+		if i%128 >= 120 {
+			value = fapi.Add(value, fapi.Mul(isLast[i/128], lengthBytes[i%128-120]))
+		}
+		bits := fapi.ToBinary(value, 8)
+		for j := 7; j >= 0; j-- {
+			padded = append(padded, bits[j])
+		}
+	}
+
+	state := initialState()
+	digest := make([]frontend.Variable, 512)
+	for i := range digest {
+		digest[i] = frontend.Variable(0)
+	}
+	for b := 0; b < nbBlocks; b++ {
+		state = compress(fapi, state, padded[b*1024:(b+1)*1024])
+		flat := flatten8(state)
+		for i := 0; i < 512; i++ {
+			digest[i] = fapi.Add(digest[i], fapi.Mul(isLast[b], flat[i]))
+		}
+	}
+
+	var result [64]vars.Byte
+	for i := 0; i < 64; i++ {
+		var bits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			bits[7-j] = vars.Bool{Value: vars.Variable{Value: digest[i*8+j]}}
+		}
+		result[i] = api.ToByteFromBits(bits)
+	}
+	return result
+}
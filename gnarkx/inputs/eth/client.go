@@ -0,0 +1,198 @@
+//go:build !js
+
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// A Client fetches witnesses from an Ethereum JSON-RPC endpoint.
+type Client struct {
+	client  *ethclient.Client
+	gclient *gethclient.Client
+}
+
+// Creates a new client connected to the given RPC url.
+func NewClient(ctx context.Context, rpcURL string) (*Client, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial rpc: %w", err)
+	}
+	return &Client{client: client, gclient: gethclient.New(client.Client())}, nil
+}
+
+// Fetches the header at the given block number, or the latest header if number is nil.
+func (c *Client) Header(ctx context.Context, number *big.Int) (*Header, error) {
+	header, err := c.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header: %w", err)
+	}
+	result := NewHeader(MaxHeaderLength)
+	if err := result.Set(header); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Fetches the proof of an account against the state root and the proof of a storage slot against
+// the storage root of the account, at the given block number.
+func (c *Client) StorageProof(
+	ctx context.Context,
+	address common.Address,
+	slot common.Hash,
+	number *big.Int,
+) (*MPTProof, *MPTProof, error) {
+	accountProof, storageProofs, err := c.StorageProofs(ctx, address, []common.Hash{slot}, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return accountProof, storageProofs[0], nil
+}
+
+// Fetches the proof of an account against the state root and the proofs of storage slots against
+// the storage root of the account, at the given block number.
+func (c *Client) StorageProofs(
+	ctx context.Context,
+	address common.Address,
+	slots []common.Hash,
+	number *big.Int,
+) (*MPTProof, []*MPTProof, error) {
+	header, err := c.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get header: %w", err)
+	}
+	keys := make([]string, len(slots))
+	for i := 0; i < len(slots); i++ {
+		keys[i] = slots[i].Hex()
+	}
+	result, err := c.gclient.GetProof(ctx, address, keys, header.Number)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get proof: %w", err)
+	}
+	if len(result.StorageProof) != len(slots) {
+		return nil, nil, fmt.Errorf("expected %d storage proofs, got %d", len(slots), len(result.StorageProof))
+	}
+
+	accountNodes, err := decodeHexList(result.AccountProof)
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := rlp.EncodeToBytes([]interface{}{
+		result.Nonce,
+		result.Balance,
+		result.StorageHash,
+		result.CodeHash,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode account: %w", err)
+	}
+	accountProof := NewMPTProof(MaxDepth, MaxNodeLength, MaxAccountLength)
+	if err := accountProof.Set(header.Root, address.Bytes(), account, accountNodes); err != nil {
+		return nil, nil, fmt.Errorf("account proof: %w", err)
+	}
+
+	storageProofs := make([]*MPTProof, len(slots))
+	for i := 0; i < len(slots); i++ {
+		storageNodes, err := decodeHexList(result.StorageProof[i].Proof)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := rlp.EncodeToBytes(result.StorageProof[i].Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode storage value: %w", err)
+		}
+		storageProof := NewMPTProof(MaxDepth, MaxNodeLength, MaxStorageValueLength)
+		if err := storageProof.Set(result.StorageHash, slots[i].Bytes(), value, storageNodes); err != nil {
+			return nil, nil, fmt.Errorf("storage proof %d: %w", i, err)
+		}
+		storageProofs[i] = &storageProof
+	}
+	return &accountProof, storageProofs, nil
+}
+
+// Fetches the receipt of the given transaction.
+func (c *Client) Receipt(ctx context.Context, txHash common.Hash) (*Receipt, error) {
+	receipt, err := c.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+	result := NewReceipt(MaxReceiptLength)
+	if err := result.Set(receipt); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Fetches the proof of the receipt of the given transaction against the receipts root of its
+// block, with the index of the transaction in the block. The receipt trie is rebuilt from the
+// receipts of all transactions of the block.
+func (c *Client) ReceiptProof(ctx context.Context, txHash common.Hash) (*MPTProof, uint64, error) {
+	receipt, err := c.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get receipt: %w", err)
+	}
+	block, err := c.client.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get block: %w", err)
+	}
+	receipts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i, tx := range block.Transactions() {
+		r, err := c.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get receipt %d: %w", i, err)
+		}
+		key, err := rlp.EncodeToBytes(uint(i))
+		if err != nil {
+			return nil, 0, err
+		}
+		encoded, err := r.MarshalBinary()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to encode receipt %d: %w", i, err)
+		}
+		receipts.MustUpdate(key, encoded)
+	}
+	if receipts.Hash() != block.ReceiptHash() {
+		return nil, 0, fmt.Errorf("receipts do not match the receipts root of the block")
+	}
+
+	index := receipt.TransactionIndex
+	key, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		return nil, 0, err
+	}
+	var nodes ProofNodes
+	if err := receipts.Prove(key, 0, &nodes); err != nil {
+		return nil, 0, fmt.Errorf("failed to prove receipt: %w", err)
+	}
+	encoded, err := receipt.MarshalBinary()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode receipt: %w", err)
+	}
+	proof := NewMPTProof(MaxDepth, MaxReceiptNodeLength, MaxReceiptLength)
+	if err := proof.SetUnhashed(block.ReceiptHash(), key, encoded, nodes); err != nil {
+		return nil, 0, fmt.Errorf("receipt proof: %w", err)
+	}
+	return &proof, uint64(index), nil
+}
+
+func decodeHexList(list []string) ([][]byte, error) {
+	result := make([][]byte, len(list))
+	for i := 0; i < len(list); i++ {
+		decoded, err := hexutil.Decode(list[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode proof node: %w", err)
+		}
+		result[i] = decoded
+	}
+	return result, nil
+}
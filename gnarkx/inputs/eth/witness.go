@@ -0,0 +1,201 @@
+// Witness structs for Ethereum data, such as block headers, Merkle-Patricia trie proofs, and
+// receipts, along with a client that fetches them from a JSON-RPC endpoint. All structs have a
+// fixed shape given by maximum lengths, with variable length data right padded with zeros, so that
+// they can be embedded in circuits and assigned directly.
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Maximum lengths that fit all mainnet data seen in practice.
+const (
+	MaxHeaderLength  = 1024
+	MaxDepth         = 16
+	MaxNodeLength    = 532
+	MaxAccountLength = 110
+	MaxReceiptLength = 4096
+
+	// The maximum length of the RLP encoding of the value of a storage slot.
+	MaxStorageValueLength = 33
+
+	// The maximum length of a node of a receipt trie, whose leaves hold whole receipts.
+	MaxReceiptNodeLength = MaxReceiptLength + 16
+)
+
+// The RLP encoding of an Ethereum block header.
+type Header struct {
+	RLP          []vars.Byte
+	Length       vars.Variable
+	Hash         [32]vars.Byte
+	ParentHash   [32]vars.Byte
+	StateRoot    [32]vars.Byte
+	ReceiptsRoot [32]vars.Byte
+	Number       vars.U64
+	Timestamp    vars.U64
+}
+
+// Creates a new header with room for an RLP encoding of up to maxLength bytes.
+func NewHeader(maxLength int) Header {
+	return Header{
+		RLP:          vars.NewBytes(maxLength),
+		Length:       vars.NewVariable(),
+		Hash:         vars.NewBytes32(),
+		ParentHash:   vars.NewBytes32(),
+		StateRoot:    vars.NewBytes32(),
+		ReceiptsRoot: vars.NewBytes32(),
+		Number:       vars.NewU64(),
+		Timestamp:    vars.NewU64(),
+	}
+}
+
+// Assigns the header.
+func (h *Header) Set(header *types.Header) error {
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode header: %w", err)
+	}
+	if err := setPadded(h.RLP, &h.Length, encoded); err != nil {
+		return fmt.Errorf("header: %w", err)
+	}
+	vars.SetBytes32(&h.Hash, header.Hash())
+	vars.SetBytes32(&h.ParentHash, header.ParentHash)
+	vars.SetBytes32(&h.StateRoot, header.Root)
+	vars.SetBytes32(&h.ReceiptsRoot, header.ReceiptHash)
+	h.Number.Set(header.Number.Uint64())
+	h.Timestamp.Set(header.Time)
+	return nil
+}
+
+// A Merkle-Patricia trie proof of the value under a key, such as an account in the state trie or
+// a slot in a storage trie.
+type MPTProof struct {
+	Root        [32]vars.Byte
+	Key         [32]vars.Byte
+	Value       []vars.Byte
+	ValueLength vars.Variable
+	Nodes       [][]vars.Byte
+	NodeLengths []vars.Variable
+	Depth       vars.Variable
+}
+
+// Creates a new proof with room for up to maxDepth nodes of up to maxNodeLength bytes each and a
+// value of up to maxValueLength bytes.
+func NewMPTProof(maxDepth int, maxNodeLength int, maxValueLength int) MPTProof {
+	lengths := make([]vars.Variable, maxDepth)
+	for i := 0; i < maxDepth; i++ {
+		lengths[i] = vars.NewVariable()
+	}
+	return MPTProof{
+		Root:        vars.NewBytes32(),
+		Key:         vars.NewBytes32(),
+		Value:       vars.NewBytes(maxValueLength),
+		ValueLength: vars.NewVariable(),
+		Nodes:       vars.NewBytesArray(maxDepth, maxNodeLength),
+		NodeLengths: lengths,
+		Depth:       vars.NewVariable(),
+	}
+}
+
+// Assigns the proof. The key is the unhashed key, which is hashed with keccak256 as in the state
+// and storage tries.
+func (p *MPTProof) Set(root [32]byte, key []byte, value []byte, nodes [][]byte) error {
+	return p.SetUnhashed(root, crypto.Keccak256(key), value, nodes)
+}
+
+// Assigns the proof of a trie with unhashed keys, such as the receipt trie keyed by the RLP
+// encoding of the index. The key is right padded with zeros to 32 bytes.
+func (p *MPTProof) SetUnhashed(root [32]byte, key []byte, value []byte, nodes [][]byte) error {
+	if len(nodes) > len(p.Nodes) {
+		return fmt.Errorf("proof has %d nodes, max depth is %d", len(nodes), len(p.Nodes))
+	}
+	if len(key) > 32 {
+		return fmt.Errorf("key length %d exceeds 32 bytes", len(key))
+	}
+	vars.SetBytes32(&p.Root, root)
+	var paddedKey [32]byte
+	copy(paddedKey[:], key)
+	vars.SetBytes32(&p.Key, paddedKey)
+	if err := setPadded(p.Value, &p.ValueLength, value); err != nil {
+		return fmt.Errorf("value: %w", err)
+	}
+	for i := 0; i < len(p.Nodes); i++ {
+		var node []byte
+		if i < len(nodes) {
+			node = nodes[i]
+		}
+		if err := setPadded(p.Nodes[i], &p.NodeLengths[i], node); err != nil {
+			return fmt.Errorf("node %d: %w", i, err)
+		}
+	}
+	p.Depth.Set(big.NewInt(int64(len(nodes))))
+	return nil
+}
+
+// ProofNodes collects the nodes of a proof written by trie.Trie.Prove in order, from the root to
+// the leaf, so that they can be passed to MPTProof.Set.
+type ProofNodes [][]byte
+
+func (n *ProofNodes) Put(key []byte, value []byte) error {
+	*n = append(*n, common.CopyBytes(value))
+	return nil
+}
+
+func (n *ProofNodes) Delete(key []byte) error {
+	return nil
+}
+
+// The consensus encoding of a transaction receipt, as stored in the receipts trie.
+type Receipt struct {
+	Encoding  []vars.Byte
+	Length    vars.Variable
+	Index     vars.U64
+	BlockHash [32]vars.Byte
+}
+
+// Creates a new receipt with room for an encoding of up to maxLength bytes.
+func NewReceipt(maxLength int) Receipt {
+	return Receipt{
+		Encoding:  vars.NewBytes(maxLength),
+		Length:    vars.NewVariable(),
+		Index:     vars.NewU64(),
+		BlockHash: vars.NewBytes32(),
+	}
+}
+
+// Assigns the receipt.
+func (r *Receipt) Set(receipt *types.Receipt) error {
+	encoded, err := receipt.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode receipt: %w", err)
+	}
+	if err := setPadded(r.Encoding, &r.Length, encoded); err != nil {
+		return fmt.Errorf("receipt: %w", err)
+	}
+	r.Index.Set(uint64(receipt.TransactionIndex))
+	vars.SetBytes32(&r.BlockHash, receipt.BlockHash)
+	return nil
+}
+
+// Assigns data to a fixed length buffer, right padding it with zeros, and sets its length.
+func setPadded(buf []vars.Byte, length *vars.Variable, data []byte) error {
+	if len(data) > len(buf) {
+		return fmt.Errorf("length %d exceeds max length %d", len(data), len(buf))
+	}
+	for i := 0; i < len(buf); i++ {
+		if i < len(data) {
+			buf[i].Set(data[i])
+		} else {
+			buf[i].Set(0)
+		}
+	}
+	length.Set(big.NewInt(int64(len(data))))
+	return nil
+}
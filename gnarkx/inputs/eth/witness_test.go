@@ -0,0 +1,87 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func TestHeader(t *testing.T) {
+	header := &types.Header{
+		ParentHash:  common.HexToHash("0x01"),
+		Root:        common.HexToHash("0x02"),
+		ReceiptHash: common.HexToHash("0x03"),
+		Number:      big.NewInt(17000000),
+		Time:        1700000000,
+		Difficulty:  big.NewInt(0),
+	}
+	encoded, err := rlp.EncodeToBytes(header)
+	assert.NoError(t, err)
+
+	h := NewHeader(MaxHeaderLength)
+	assert.NoError(t, h.Set(header))
+	assert.Equal(t, encoded, vars.GetValuesUnsafe(h.RLP[:len(encoded)]))
+	assert.Equal(t, big.NewInt(int64(len(encoded))), h.Length.Value)
+	assert.Equal(t, header.Hash().Bytes(), vars.GetValuesUnsafe(h.Hash[:]))
+	assert.Equal(t, header.Root.Bytes(), vars.GetValuesUnsafe(h.StateRoot[:]))
+	assert.Equal(t, 1700000000, h.Timestamp.Value.Value)
+
+	small := NewHeader(16)
+	assert.Error(t, small.Set(header))
+}
+
+func TestMPTProof(t *testing.T) {
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := 0; i < 100; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		value, err := rlp.EncodeToBytes(big.NewInt(int64(i + 1)))
+		assert.NoError(t, err)
+		tr.MustUpdate(crypto.Keccak256(slot.Bytes()), value)
+	}
+	root := tr.Hash()
+
+	slot := common.BigToHash(big.NewInt(42))
+	var nodes ProofNodes
+	assert.NoError(t, tr.Prove(crypto.Keccak256(slot.Bytes()), 0, &nodes))
+	value, err := rlp.EncodeToBytes(big.NewInt(43))
+	assert.NoError(t, err)
+
+	proof := NewMPTProof(MaxDepth, MaxNodeLength, MaxStorageValueLength)
+	assert.NoError(t, proof.Set(root, slot.Bytes(), value, nodes))
+	assert.Equal(t, big.NewInt(int64(len(nodes))), proof.Depth.Value)
+	assert.Equal(t, crypto.Keccak256(slot.Bytes()), vars.GetValuesUnsafe(proof.Key[:]))
+	assert.Equal(t, root.Bytes(), crypto.Keccak256(vars.GetValuesUnsafe(proof.Nodes[0])[:len(nodes[0])]))
+	for i := len(nodes); i < MaxDepth; i++ {
+		assert.Equal(t, big.NewInt(0), proof.NodeLengths[i].Value)
+	}
+
+	shallow := NewMPTProof(1, MaxNodeLength, MaxStorageValueLength)
+	assert.Error(t, shallow.Set(root, slot.Bytes(), value, nodes))
+}
+
+func TestReceipt(t *testing.T) {
+	receipt := &types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{},
+		TransactionIndex:  3,
+		BlockHash:         common.HexToHash("0x04"),
+	}
+	encoded, err := receipt.MarshalBinary()
+	assert.NoError(t, err)
+
+	r := NewReceipt(MaxReceiptLength)
+	assert.NoError(t, r.Set(receipt))
+	assert.Equal(t, encoded, vars.GetValuesUnsafe(r.Encoding[:len(encoded)]))
+	assert.Equal(t, 3, r.Index.Value.Value)
+	assert.Equal(t, receipt.BlockHash.Bytes(), vars.GetValuesUnsafe(r.BlockHash[:]))
+}
@@ -0,0 +1,272 @@
+// A gadget for verifying JSON Web Tokens signed with RS256 or ES256 and extracting their claims,
+// for circuits attesting to credentials issued by web services.
+//
+// The signing input is verified as given and only the payload is decoded in the circuit. Claims
+// are located by witness positions: a claim is a top-level member "name":value of the payload
+// whose value is a string without escape sequences or a non-negative integer. Its key must be at
+// depth 1 of the payload, outside strings, which the circuit tracks through the objects and arrays
+// of the payload, so that members of nested objects are not taken for claims. Payloads with
+// whitespace between tokens are not supported.
+package jwt
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/encoding/base64"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/rsa"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The public key of the issuer of a token.
+type PublicKey struct {
+	Algorithm Algorithm
+
+	// The big-endian modulus of an RS256 key.
+	Modulus []vars.Byte
+
+	// The big-endian coordinates of an ES256 key.
+	X [32]vars.Byte
+	Y [32]vars.Byte
+}
+
+// Verifies the signature of the token under the key.
+func Verify(api builder.API, token Token, key PublicKey) {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(token.SigningInput); i++ {
+		rc.Check(token.SigningInput[i].Value.Value, 8)
+	}
+	for i := 0; i < len(token.Signature); i++ {
+		rc.Check(token.Signature[i].Value.Value, 8)
+	}
+
+	// The header and payload are separated by the only dot of the signing input, since dots are
+	// not in the base64url alphabet.
+	input := byteslice.NewTable(api, token.SigningInput, 1, 0)
+	fapi.AssertIsEqual(input.At(token.PayloadIndex, -1).Value.Value, '.')
+	api.AssertIsLessOrEqual(token.PayloadIndex, token.Length)
+
+	digest := sha256.HashVariable(api, token.SigningInput, vars.U64{Value: token.Length}, len(token.SigningInput))
+	switch key.Algorithm {
+	case RS256:
+		rsa.AssertValidPKCS1v15(api, key.Modulus, token.Signature, digest)
+	case ES256:
+		if len(token.Signature) != 64 {
+			panic(fmt.Sprintf("expected a 64 byte signature, got %d", len(token.Signature)))
+		}
+		var r, s [32]vars.Byte
+		copy(r[:], token.Signature[:32])
+		copy(s[:], token.Signature[32:])
+		compat.AssertValidP256ECDSA(api, digest, r, s, key.X, key.Y)
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %d", key.Algorithm))
+	}
+}
+
+// The decoded payload of a token, or its decoded header, which is read the same way.
+type Payload struct {
+	api   builder.API
+	data  []vars.Byte
+	table *byteslice.Table
+
+	// The number of decoded bytes.
+	length vars.Variable
+
+	// The levels of the bytes of the payload, computed by the first claim that is read.
+	levels *logderivlookup.Table
+}
+
+// Decodes the base64url payload of the token. The token must be checked with Verify.
+func DecodePayload(api builder.API, token Token) *Payload {
+	return decode(api, token, token.PayloadIndex, api.Sub(token.Length, token.PayloadIndex))
+}
+
+// Decodes the base64url header of the token, whose claims such as alg are read as those of the
+// payload. The token must be checked with Verify.
+func DecodeHeader(api builder.API, token Token) *Payload {
+	return decode(api, token, vars.ZERO, api.Sub(token.PayloadIndex, vars.ONE))
+}
+
+// Decodes the nbChars base64url characters of the signing input starting at index.
+func decode(api builder.API, token Token, index vars.Variable, nbChars vars.Variable) *Payload {
+	// At least one character of the other part and the dot are not decoded.
+	maxChars := len(token.SigningInput) - 2
+	input := byteslice.NewTable(api, token.SigningInput, 0, maxChars)
+	decoded := base64.RawURLEncoding.Decode(api, vars.VariableBytes{Data: input.Read(index, maxChars), Length: nbChars})
+	return &Payload{
+		api:    api,
+		data:   decoded.Data,
+		table:  byteslice.NewTable(api, decoded.Data, 1, len(decoded.Data)),
+		length: decoded.Length,
+	}
+}
+
+// Encodes the bytes in base64url without padding, as in the claims of tokens that commit to
+// binary values such as nonces.
+func EncodeBase64URL(api builder.API, in []vars.Byte) []vars.Byte {
+	return base64.RawURLEncoding.Encode(api, in)
+}
+
+// Returns the decoded payload, padded with zeros.
+func (p *Payload) Data() []vars.Byte {
+	return p.data
+}
+
+// Asserts that the claim key "name": is at the start of a top-level member and returns the index
+// of its value.
+func (p *Payload) assertKey(name string, claim Claim) vars.Variable {
+	fapi := p.api.FrontendAPI()
+	key := `"` + name + `":`
+	p.table.AssertLiteralAt(claim.Index, []byte(key))
+	before := p.table.At(claim.Index, -1).Value.Value
+	fapi.AssertIsEqual(fapi.Mul(fapi.Sub(before, '{'), fapi.Sub(before, ',')), 0)
+
+	// The key is in the top-level object, at depth 1 and not in a string.
+	fapi.AssertIsEqual(p.levelTable().Lookup(claim.Index.Value)[0], 2)
+	return p.api.Add(claim.Index, vars.NewVariableFromInt(len(key)))
+}
+
+// Returns the table of the levels 2 * depth + inString of the bytes of the payload, where depth is
+// the number of objects and arrays open before the byte and inString whether the byte is in a
+// string, after its opening quote. Brackets in strings and escaped quotes are skipped.
+func (p *Payload) levelTable() *logderivlookup.Table {
+	if p.levels != nil {
+		return p.levels
+	}
+	fapi := p.api.FrontendAPI()
+	nesting := byteTable(fapi, func(c byte) int {
+		switch c {
+		case '{', '[':
+			return 1
+		case '}', ']':
+			return -1
+		}
+		return 0
+	})
+	quotes := byteTable(fapi, func(c byte) int {
+		if c == '"' {
+			return 1
+		}
+		return 0
+	})
+	backslashes := byteTable(fapi, func(c byte) int {
+		if c == '\\' {
+			return 1
+		}
+		return 0
+	})
+
+	p.levels = logderivlookup.New(fapi)
+	depth, inString, escaped := frontend.Variable(0), frontend.Variable(0), frontend.Variable(0)
+	for i := range p.data {
+		p.levels.Insert(fapi.Add(fapi.Mul(depth, 2), inString))
+		c := p.data[i].Value.Value
+		outside := fapi.Sub(1, inString)
+		depth = fapi.Add(depth, fapi.Mul(outside, nesting.Lookup(c)[0]))
+
+		// An unescaped quote opens or closes a string, and a backslash in a string escapes the
+		// next byte unless it is escaped itself.
+		notEscaped := fapi.Sub(1, escaped)
+		toggle := fapi.Mul(notEscaped, quotes.Lookup(c)[0])
+		escaped = fapi.Mul(inString, notEscaped, backslashes.Lookup(c)[0])
+		inString = fapi.Sub(fapi.Add(inString, toggle), fapi.Mul(2, inString, toggle))
+	}
+	return p.levels
+}
+
+// Returns the table of f over the bytes.
+func byteTable(api frontend.API, f func(c byte) int) *logderivlookup.Table {
+	table := logderivlookup.New(api)
+	for i := 0; i < 256; i++ {
+		table.Insert(f(byte(i)))
+	}
+	return table
+}
+
+// Asserts that the bytes before end are within the decoded payload.
+func (p *Payload) assertWithin(end vars.Variable) {
+	p.api.AssertIsLessOrEqual(end, p.length)
+}
+
+// Returns the value of the string claim with the name, padded with zeros to maxLength bytes.
+func (p *Payload) String(name string, claim Claim, maxLength int) []vars.Byte {
+	fapi := p.api.FrontendAPI()
+	start := p.api.Add(p.assertKey(name, claim), vars.ONE)
+	fapi.AssertIsEqual(p.table.At(start, -1).Value.Value, '"')
+	p.api.AssertIsLessOrEqual(claim.Length, vars.NewVariableFromInt(maxLength))
+
+	value := make([]vars.Byte, maxLength)
+	inRange := frontend.Variable(1)
+	for k := 0; k <= maxLength; k++ {
+		c := p.table.At(start, k).Value.Value
+		isEnd := fapi.IsZero(fapi.Sub(claim.Length.Value, k))
+		inRange = fapi.Sub(inRange, isEnd)
+
+		// The string ends at the first quote and must not contain escape sequences.
+		isQuote := fapi.IsZero(fapi.Sub(c, '"'))
+		isEscape := fapi.IsZero(fapi.Sub(c, '\\'))
+		fapi.AssertIsEqual(fapi.Mul(inRange, fapi.Add(isQuote, isEscape)), 0)
+		fapi.AssertIsEqual(fapi.Mul(isEnd, fapi.Sub(1, isQuote)), 0)
+		if k < maxLength {
+			value[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, c)}}
+		}
+	}
+	p.assertWithin(p.api.Add(p.api.Add(start, claim.Length), vars.ONE))
+	return value
+}
+
+// Returns the value of the integer claim with the name, which has at most maxDigits digits.
+func (p *Payload) Integer(name string, claim Claim, maxDigits int) vars.Variable {
+	if maxDigits > 18 {
+		panic(fmt.Sprintf("max digits %d exceeds 18", maxDigits))
+	}
+	fapi := p.api.FrontendAPI()
+	start := p.assertKey(name, claim)
+	p.api.AssertIsLessOrEqual(claim.Length, vars.NewVariableFromInt(maxDigits))
+	p.api.AssertIsDifferent(claim.Length, vars.ZERO)
+
+	// Maps digits to their values and other characters to 16.
+	digits := logderivlookup.New(fapi)
+	for i := 0; i < 256; i++ {
+		if i >= '0' && i <= '9' {
+			digits.Insert(i - '0')
+		} else {
+			digits.Insert(16)
+		}
+	}
+
+	value := frontend.Variable(0)
+	inRange := frontend.Variable(1)
+	for k := 0; k <= maxDigits; k++ {
+		c := p.table.At(start, k).Value.Value
+		isEnd := fapi.IsZero(fapi.Sub(claim.Length.Value, k))
+		inRange = fapi.Sub(inRange, isEnd)
+
+		digit := p.api.ToBinaryLE(vars.Variable{Value: digits.Lookup(c)[0]}, 5)
+		isDigit := fapi.Sub(1, digit[4].Value.Value)
+		fapi.AssertIsEqual(fapi.Mul(inRange, fapi.Sub(1, isDigit)), 0)
+		if k < maxDigits {
+			d := fapi.Add(digit[0].Value.Value, fapi.Mul(digit[1].Value.Value, 2), fapi.Mul(digit[2].Value.Value, 4),
+				fapi.Mul(digit[3].Value.Value, 8))
+			value = fapi.Select(inRange, fapi.Add(fapi.Mul(value, 10), d), value)
+		}
+
+		// The number is followed by the end of the member.
+		fapi.AssertIsEqual(fapi.Mul(isEnd, fapi.Sub(c, ','), fapi.Sub(c, '}')), 0)
+	}
+	p.assertWithin(p.api.Add(p.api.Add(start, claim.Length), vars.ONE))
+	return vars.Variable{Value: value}
+}
+
+// Asserts that the token has not expired at the unix timestamp now, given the exp claim.
+func (p *Payload) AssertNotExpired(exp Claim, now vars.Variable) {
+	expiration := p.Integer("exp", exp, 12)
+	p.api.AssertIsLessOrEqual(p.api.Add(now, vars.ONE), expiration)
+}
@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"crypto"
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	gosha256 "crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const testPayload = `{"iss":"https://accounts.example.com","sub":"1234567890","exp":1700003600,"iat":1700000000}`
+
+type testCircuit struct {
+	Token   Token
+	Modulus []vars.Byte
+	X       [32]vars.Byte
+	Y       [32]vars.Byte
+	Sub     Claim
+	Exp     Claim
+	Now     vars.Variable
+	Subject []vars.Byte
+
+	algorithm Algorithm `gnark:"-"`
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	Verify(*api, c.Token, PublicKey{Algorithm: c.algorithm, Modulus: c.Modulus, X: c.X, Y: c.Y})
+	payload := DecodePayload(*api, c.Token)
+	payload.AssertNotExpired(c.Exp, c.Now)
+	subject := payload.String("sub", c.Sub, len(c.Subject))
+	for i := 0; i < len(subject); i++ {
+		api.AssertIsEqualByte(subject[i], c.Subject[i])
+	}
+	return nil
+}
+
+func signingInput(algorithm string, payload string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + algorithm + `","typ":"JWT"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+}
+
+func newTestCircuit(t *testing.T, token string, algorithm Algorithm, signatureLength int) (*testCircuit, *testCircuit) {
+	circuit := &testCircuit{
+		Token:     NewToken(256, signatureLength),
+		Modulus:   vars.NewBytes(256),
+		Subject:   vars.NewBytes(16),
+		algorithm: algorithm,
+	}
+	assignment := &testCircuit{
+		Token:     NewToken(256, signatureLength),
+		Sub:       NewClaim(),
+		Exp:       NewClaim(),
+		Now:       vars.NewVariableFromInt(1700000000),
+		Modulus:   vars.NewBytesFrom(make([]byte, 256)),
+		algorithm: algorithm,
+	}
+	vars.SetBytes32(&assignment.X, [32]byte{})
+	vars.SetBytes32(&assignment.Y, [32]byte{})
+	assert.NoError(t, assignment.Token.Set(token))
+	payload, err := ParsePayload(token)
+	assert.NoError(t, err)
+	assert.NoError(t, assignment.Sub.Set(payload, "sub"))
+	assert.NoError(t, assignment.Exp.Set(payload, "exp"))
+	subject := make([]byte, 16)
+	copy(subject, "1234567890")
+	assignment.Subject = vars.NewBytesFrom(subject)
+	return circuit, assignment
+}
+
+func TestRS256(t *testing.T) {
+	key, err := gorsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	input := signingInput("RS256", testPayload)
+	digest := gosha256.Sum256([]byte(input))
+	signature, err := gorsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	token := input + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	circuit, assignment := newTestCircuit(t, token, RS256, 256)
+	assignment.Modulus = vars.NewBytesFrom(key.N.FillBytes(make([]byte, 256)))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The token expires at the exp claim.
+	assignment.Now = vars.NewVariableFromInt(1700003600)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The sub claim cannot be read from the value of another claim.
+	assignment.Now = vars.NewVariableFromInt(1700000000)
+	assignment.Sub.Index = vars.NewVariableFromInt(7)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+func TestES256(t *testing.T) {
+	key, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	input := signingInput("ES256", testPayload)
+	digest := gosha256.Sum256([]byte(input))
+	r, s, err := goecdsa.Sign(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	token := input + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	circuit, assignment := newTestCircuit(t, token, ES256, 64)
+	vars.SetBytes32(&assignment.X, [32]byte(key.X.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.Y, [32]byte(key.Y.FillBytes(make([]byte, 32))))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// A token signed by another key is rejected.
+	otherKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	vars.SetBytes32(&assignment.X, [32]byte(otherKey.X.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.Y, [32]byte(otherKey.Y.FillBytes(make([]byte, 32))))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+// The circuit reads the sub claim of the payload of a token, without verifying it.
+type testClaimCircuit struct {
+	Token   Token
+	Sub     Claim
+	Subject []vars.Byte
+}
+
+func (c *testClaimCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	subject := DecodePayload(*api, c.Token).String("sub", c.Sub, len(c.Subject))
+	for i := 0; i < len(subject); i++ {
+		api.AssertIsEqualByte(subject[i], c.Subject[i])
+	}
+	return nil
+}
+
+func TestNestedClaims(t *testing.T) {
+	// Members of nested objects and arrays, and keys in strings, are not top-level claims.
+	payload := `{"act":{"sub":"attacker"},"aud":[{"sub":"attacker"}],"note":"x\",\"sub\":{","sub":"1234567890"}`
+	token := signingInput("RS256", payload) + "." + base64.RawURLEncoding.EncodeToString(make([]byte, 8))
+	circuit := &testClaimCircuit{Token: NewToken(256, 8), Subject: vars.NewBytes(10)}
+	assignment := &testClaimCircuit{Token: NewToken(256, 8), Sub: NewClaim(), Subject: vars.NewBytesFrom([]byte("1234567890"))}
+	assert.NoError(t, assignment.Token.Set(token))
+	assert.NoError(t, assignment.Sub.Set([]byte(payload), "sub"))
+	assert.Equal(t, strings.LastIndex(payload, `"sub"`), int(assignment.Sub.Index.Value.(int)))
+	err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	assignment.Subject = vars.NewBytesFrom([]byte("attacker\x00\x00"))
+	for _, index := range []int{strings.Index(payload, `"sub"`), strings.Index(payload, `[{"sub"`) + 2} {
+		assignment.Sub.Index = vars.NewVariableFromInt(index)
+		assignment.Sub.Length = vars.NewVariableFromInt(8)
+		err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+		assert.Error(t, err)
+	}
+}
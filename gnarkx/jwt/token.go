@@ -0,0 +1,146 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The signature algorithm of a token.
+type Algorithm int
+
+const (
+	// RSASSA-PKCS1-v1_5 with SHA-256 and public exponent 65537.
+	RS256 Algorithm = iota
+
+	// ECDSA P-256 with SHA-256.
+	ES256
+)
+
+// A Token is the witness of a JWT in compact serialization: the signing input
+// base64url(header) || '.' || base64url(payload), padded with zeros, and the decoded signature.
+type Token struct {
+	SigningInput []vars.Byte
+	Length       vars.Variable
+
+	// The index of the first character of the payload, just after the dot.
+	PayloadIndex vars.Variable
+
+	Signature []vars.Byte
+}
+
+// Creates a new token of up to maxLength bytes of signing input. For RS256 the signature has the
+// length of the modulus in bytes, and for ES256 it is the 64 byte concatenation r || s.
+func NewToken(maxLength int, signatureLength int) Token {
+	return Token{
+		SigningInput: vars.NewBytes(maxLength),
+		Length:       vars.NewVariable(),
+		PayloadIndex: vars.NewVariable(),
+		Signature:    vars.NewBytes(signatureLength),
+	}
+}
+
+// Sets the token from its compact serialization header.payload.signature.
+func (t *Token) Set(token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("expected 3 parts, got %d", len(parts))
+	}
+	signingInput := parts[0] + "." + parts[1]
+	if len(signingInput) > len(t.SigningInput) {
+		return fmt.Errorf("signing input length %d exceeds %d", len(signingInput), len(t.SigningInput))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if len(signature) != len(t.Signature) {
+		return fmt.Errorf("signature length %d, expected %d", len(signature), len(t.Signature))
+	}
+
+	data := make([]byte, len(t.SigningInput))
+	copy(data, signingInput)
+	vars.SetBytes(&t.SigningInput, data)
+	t.Length = vars.NewVariableFromInt(len(signingInput))
+	t.PayloadIndex = vars.NewVariableFromInt(len(parts[0]) + 1)
+	vars.SetBytes(&t.Signature, signature)
+	return nil
+}
+
+// Decodes the payload of a token in compact serialization.
+func ParsePayload(token string) ([]byte, error) {
+	return parsePart(token, 1)
+}
+
+// Decodes the header of a token in compact serialization.
+func ParseHeader(token string) ([]byte, error) {
+	return parsePart(token, 0)
+}
+
+func parsePart(token string, i int) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected 3 parts, got %d", len(parts))
+	}
+	return base64.RawURLEncoding.DecodeString(parts[i])
+}
+
+// A Claim is the witness of the position of a claim in the decoded payload: the index of the
+// quoted claim name and the length of the value.
+type Claim struct {
+	Index  vars.Variable
+	Length vars.Variable
+}
+
+// Creates a new claim.
+func NewClaim() Claim {
+	return Claim{Index: vars.NewVariable(), Length: vars.NewVariable()}
+}
+
+// Sets the claim to the top-level member with the name in the JSON payload, whose key is at depth
+// 1 outside strings. The value must be a string without escape sequences or a non-negative
+// integer, and the payload must not contain whitespace between tokens.
+func (c *Claim) Set(payload []byte, name string) error {
+	key := []byte(`"` + name + `":`)
+	depth, inString, escaped := 0, false, false
+	for i := 0; i+len(key) <= len(payload); i++ {
+		isTopLevel := depth == 1 && !inString
+		switch b := payload[i]; {
+		case escaped:
+			escaped = false
+		case inString && b == '\\':
+			escaped = true
+		case b == '"':
+			inString = !inString
+		case !inString && (b == '{' || b == '['):
+			depth++
+		case !inString && (b == '}' || b == ']'):
+			depth--
+		}
+		if i == 0 || !isTopLevel || !bytes.HasPrefix(payload[i:], key) || (payload[i-1] != '{' && payload[i-1] != ',') {
+			continue
+		}
+		value := payload[i+len(key):]
+		var length int
+		if len(value) > 0 && value[0] == '"' {
+			length = bytes.IndexAny(value[1:], `"\`)
+			if length < 0 || value[1+length] != '"' {
+				return fmt.Errorf("claim %s: unsupported string", name)
+			}
+		} else {
+			for length < len(value) && value[length] >= '0' && value[length] <= '9' {
+				length++
+			}
+			if length == 0 {
+				return fmt.Errorf("claim %s: unsupported value", name)
+			}
+		}
+		c.Index = vars.NewVariableFromInt(i)
+		c.Length = vars.NewVariableFromInt(length)
+		return nil
+	}
+	return fmt.Errorf("claim %s not found", name)
+}
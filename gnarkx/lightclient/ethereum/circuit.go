@@ -0,0 +1,188 @@
+package ethereum
+
+import (
+	"fmt"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/ssz"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/synccommittee"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Circuit proves that a beacon block header is signed by a supermajority of the committee of the
+// commitment.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The attested header.
+	Slot          vars.U64
+	ProposerIndex vars.U64
+	ParentRoot    [32]vars.Byte
+	StateRoot     [32]vars.Byte
+	BodyRoot      [32]vars.Byte
+
+	// The committee and its sync aggregate.
+	Pubkeys   [][bls.G1Length]vars.Byte
+	Bits      []vars.Bool
+	Signature [bls.G2Length]vars.Byte
+
+	config    *Config              `gnark:"-"`
+	header    *Header              `gnark:"-"`
+	pubkeys   [][bls.G1Length]byte `gnark:"-"`
+	aggregate *SyncAggregate       `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.CommitteeSize <= 0 || config.CommitteeSize&(config.CommitteeSize-1) != 0 {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	pubkeys := make([][bls.G1Length]vars.Byte, config.CommitteeSize)
+	bits := make([]vars.Bool, config.CommitteeSize)
+	for i := 0; i < config.CommitteeSize; i++ {
+		for j := 0; j < bls.G1Length; j++ {
+			pubkeys[i][j] = vars.NewByte()
+		}
+		bits[i] = vars.NewBool(false)
+	}
+	var signature [bls.G2Length]vars.Byte
+	for i := 0; i < bls.G2Length; i++ {
+		signature[i] = vars.NewByte()
+	}
+	return &Circuit{
+		InputBytes:    vars.NewBytes(32),
+		OutputBytes:   vars.NewBytes(8 + 32 + 8),
+		Slot:          vars.NewU64(),
+		ProposerIndex: vars.NewU64(),
+		ParentRoot:    vars.NewBytes32(),
+		StateRoot:     vars.NewBytes32(),
+		BodyRoot:      vars.NewBytes32(),
+		Pubkeys:       pubkeys,
+		Bits:          bits,
+		Signature:     signature,
+		config:        config,
+	}
+}
+
+// Sets the header, the compressed public keys of the committee and the sync aggregate of the
+// header that the next call to SetWitness assigns.
+func (c *Circuit) SetAggregate(header *Header, pubkeys [][bls.G1Length]byte, aggregate *SyncAggregate) error {
+	if len(pubkeys) != c.config.CommitteeSize {
+		return fmt.Errorf("committee of %d public keys, expected %d", len(pubkeys), c.config.CommitteeSize)
+	}
+	if len(aggregate.Bits) != c.config.CommitteeSize {
+		return fmt.Errorf("sync aggregate of %d bits, expected %d", len(aggregate.Bits), c.config.CommitteeSize)
+	}
+	var signature bls12381.G2Affine
+	if _, err := signature.SetBytes(aggregate.Signature[:]); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	c.header = header
+	c.pubkeys = pubkeys
+	c.aggregate = aggregate
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the header, committee and sync aggregate given to SetAggregate.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.header == nil {
+		panic("aggregate must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.Slot.Set(c.header.Slot)
+	c.ProposerIndex.Set(c.header.ProposerIndex)
+	vars.SetBytes32(&c.ParentRoot, c.header.ParentRoot)
+	vars.SetBytes32(&c.StateRoot, c.header.StateRoot)
+	vars.SetBytes32(&c.BodyRoot, c.header.BodyRoot)
+	for i := 0; i < c.config.CommitteeSize; i++ {
+		for j := 0; j < bls.G1Length; j++ {
+			c.Pubkeys[i][j].Set(c.pubkeys[i][j])
+		}
+		c.Bits[i] = vars.NewBool(c.aggregate.Bits[i])
+	}
+	for i := 0; i < bls.G2Length; i++ {
+		c.Signature[i].Set(c.aggregate.Signature[i])
+	}
+	vars.SetBytes(&c.OutputBytes, outputs(c.header, c.aggregate))
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	commitment := inputReader.ReadBytes32()
+	config := c.config
+
+	// The committee is the committee of the commitment.
+	rc := rangecheck.New(fapi)
+	for i := 0; i < config.CommitteeSize; i++ {
+		for j := 0; j < bls.G1Length; j++ {
+			rc.Check(c.Pubkeys[i][j].Value.Value, 8)
+		}
+	}
+	api.AssertIsEqual(synccommittee.CommitmentOf(*api, c.Pubkeys), api.ToVariableFromBytesBE(commitment[:]))
+
+	// The signing root of the header with the domain of the sync committee.
+	for _, root := range [][32]vars.Byte{c.ParentRoot, c.StateRoot, c.BodyRoot} {
+		for i := 0; i < 32; i++ {
+			rc.Check(root[i].Value.Value, 8)
+		}
+	}
+	sszAPI := ssz.NewAPI(api)
+	headerRoot := sszAPI.HashTreeRoot([][32]vars.Byte{
+		api.ToBytes32FromU64LE(c.Slot), api.ToBytes32FromU64LE(c.ProposerIndex),
+		c.ParentRoot, c.StateRoot, c.BodyRoot, vars.NewBytes32(), vars.NewBytes32(), vars.NewBytes32(),
+	}, 8)
+	domain := config.domain()
+	var domainBytes [32]vars.Byte
+	copy(domainBytes[:], vars.NewBytesFrom(domain[:]))
+	signingRoot := sha256.Hash(*api, append(headerRoot[:], domainBytes[:]...))
+
+	// The members whose bits are set sign the signing root, and they are a supermajority.
+	blsAPI := bls.NewAPI(api)
+	participation := vars.NewVariableFromInt(0)
+	pubkeys := make([]sw_bls12381.G1Affine, config.CommitteeSize)
+	for i := 0; i < config.CommitteeSize; i++ {
+		fapi.AssertIsBoolean(c.Bits[i].Value.Value)
+		participation = api.Add(participation, c.Bits[i].Value)
+		pubkeys[i] = *blsAPI.DecompressG1(c.Pubkeys[i])
+	}
+	api.AssertIsLessOrEqual(
+		vars.NewVariableFromInt(2*config.CommitteeSize),
+		api.Mul(participation, vars.NewVariableFromInt(3)),
+	)
+	for i := 0; i < bls.G2Length; i++ {
+		rc.Check(c.Signature[i].Value.Value, 8)
+	}
+	pubkey := blsAPI.AggregatePubkeys(pubkeys, c.Bits)
+	blsAPI.Verify(pubkey, signingRoot[:], blsAPI.DecompressG2(c.Signature))
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteU64(c.Slot)
+	outputWriter.WriteBytes32(headerRoot)
+	outputWriter.WriteU64(vars.U64{Value: participation})
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
@@ -0,0 +1,105 @@
+// A circuit template verifying that a beacon block header is signed by a supermajority of the sync
+// committee of the Beacon Chain, the step of the light clients that follow the sync committee.
+//
+// The input is the Poseidon commitment of the synccommittee package to the compressed public keys
+// of the committee. The output is the slot and the root of the attested header, followed by the
+// number of members that signed it. The members whose bits are set in the sync aggregate sign the
+// signing root of the header with the domain of the sync committee, and the header is accepted if
+// 3 * participation >= 2 * CommitteeSize, as the safety threshold of the light client protocol.
+//
+// The domain depends on the fork version of the epoch of the signature, which is part of the config,
+// so a circuit is compiled per fork.
+//
+// Reference: https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md
+package ethereum
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/synccommittee"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sszutils"
+)
+
+// The domain type of sync committee signatures.
+var domainSyncCommittee = [4]byte{0x07, 0x00, 0x00, 0x00}
+
+// Config describes the chain and the size of the committee a circuit accepts.
+type Config struct {
+	// The fork version of the epoch of the signature and the genesis validators root of the chain,
+	// which determine the signing domain of the sync committee.
+	ForkVersion           [4]byte
+	GenesisValidatorsRoot common.Hash
+
+	// The number of members of a committee, a power of 2 which is synccommittee.Size on mainnet.
+	CommitteeSize int
+}
+
+// A beacon block header.
+type Header struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    common.Hash
+	StateRoot     common.Hash
+	BodyRoot      common.Hash
+}
+
+// The sync aggregate of a header, where the bits of the signing members are set.
+type SyncAggregate struct {
+	Bits      []bool
+	Signature [bls.G2Length]byte
+}
+
+// Returns the SSZ root of the header.
+func (h *Header) HashTreeRoot() [32]byte {
+	return sszutils.HashTreeRoot([][32]byte{
+		sszutils.NewBytes32FromU64LE(h.Slot), sszutils.NewBytes32FromU64LE(h.ProposerIndex),
+		h.ParentRoot, h.StateRoot, h.BodyRoot, {}, {}, {},
+	})
+}
+
+// Returns the input of the circuit.
+func (c *Config) Input(commitment *big.Int) []byte {
+	return commitment.FillBytes(make([]byte, 32))
+}
+
+// Computes the Poseidon commitment to the committee.
+func (c *Config) Commitment(pubkeys [][bls.G1Length]byte) (*big.Int, error) {
+	if len(pubkeys) != c.CommitteeSize {
+		return nil, fmt.Errorf("committee of %d public keys, expected %d", len(pubkeys), c.CommitteeSize)
+	}
+	return synccommittee.ComputeCommitmentOf(pubkeys), nil
+}
+
+// Returns the signing domain of the sync committee.
+func (c *Config) domain() [32]byte {
+	var version [32]byte
+	copy(version[:], c.ForkVersion[:])
+	forkDataRoot := sszutils.HashTreeRoot([][32]byte{version, c.GenesisValidatorsRoot})
+	var domain [32]byte
+	copy(domain[:], domainSyncCommittee[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// Returns the signing root of the header, which the members of the committee sign.
+func (c *Config) SigningRoot(header *Header) [32]byte {
+	return sszutils.HashTreeRoot([][32]byte{header.HashTreeRoot(), c.domain()})
+}
+
+// Returns the outputs of the circuit for the header and its sync aggregate.
+func outputs(header *Header, aggregate *SyncAggregate) []byte {
+	participation := uint64(0)
+	for _, bit := range aggregate.Bits {
+		if bit {
+			participation++
+		}
+	}
+	root := header.HashTreeRoot()
+	output := binary.BigEndian.AppendUint64(nil, header.Slot)
+	output = append(output, root[:]...)
+	return binary.BigEndian.AppendUint64(output, participation)
+}
@@ -0,0 +1,96 @@
+package ethereum
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+)
+
+var (
+	config = &Config{
+		ForkVersion:           [4]byte{0x04, 0x00, 0x00, 0x00},
+		GenesisValidatorsRoot: common.HexToHash("0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"),
+		CommitteeSize:         4,
+	}
+	header = &Header{
+		Slot:          8000032,
+		ProposerIndex: 12345,
+		ParentRoot:    common.HexToHash("0x01"),
+		StateRoot:     common.HexToHash("0x02"),
+		BodyRoot:      common.HexToHash("0x03"),
+	}
+)
+
+// Returns a committee of n members with the secret keys of its members.
+func testCommittee(n int, seed int64) ([][bls.G1Length]byte, []*big.Int) {
+	_, _, g1, _ := bls12381.Generators()
+	pubkeys := make([][bls.G1Length]byte, n)
+	secrets := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		secrets[i] = big.NewInt(seed*1000 + int64(i)*7919 + 11)
+		var pubkey bls12381.G1Affine
+		pubkey.ScalarMultiplication(&g1, secrets[i])
+		pubkeys[i] = pubkey.Bytes()
+	}
+	return pubkeys, secrets
+}
+
+// Returns the sync aggregate of the header by the members whose bits are set.
+func testAggregate(t *testing.T, secrets []*big.Int, bits []bool) *SyncAggregate {
+	root := config.SigningRoot(header)
+	hash, err := bls12381.HashToG2(root[:], bls.DST)
+	assert.NoError(t, err)
+	var signature bls12381.G2Affine
+	for i := range secrets {
+		if bits[i] {
+			var partial bls12381.G2Affine
+			partial.ScalarMultiplication(&hash, secrets[i])
+			signature.Add(&signature, &partial)
+		}
+	}
+	return &SyncAggregate{Bits: bits, Signature: signature.Bytes()}
+}
+
+func isSolved(pubkeys [][bls.G1Length]byte, aggregate *SyncAggregate, input []byte) error {
+	circuit := NewCircuit(config)
+	if err := circuit.SetAggregate(header, pubkeys, aggregate); err != nil {
+		return err
+	}
+	return functiontest.IsSolved(circuit, input)
+}
+
+func TestCircuit(t *testing.T) {
+	pubkeys, secrets := testCommittee(4, 1)
+	commitment, err := config.Commitment(pubkeys)
+	assert.NoError(t, err)
+	input := config.Input(commitment)
+
+	// Three of four members are a supermajority.
+	aggregate := testAggregate(t, secrets, []bool{true, false, true, true})
+	assert.NoError(t, isSolved(pubkeys, aggregate, input))
+	result := outputs(header, aggregate)
+	root := header.HashTreeRoot()
+	assert.Equal(t, header.Slot, binary.BigEndian.Uint64(result[:8]))
+	assert.Equal(t, root[:], result[8:40])
+	assert.Equal(t, uint64(3), binary.BigEndian.Uint64(result[40:]))
+
+	// Two of four members are not a supermajority.
+	aggregate = testAggregate(t, secrets, []bool{true, false, false, true})
+	assert.Error(t, isSolved(pubkeys, aggregate, input))
+
+	// The bits must match the signature.
+	aggregate = testAggregate(t, secrets, []bool{true, false, true, true})
+	aggregate.Bits = []bool{true, true, true, true}
+	assert.Error(t, isSolved(pubkeys, aggregate, input))
+
+	// The committee must match the commitment.
+	other, otherSecrets := testCommittee(4, 2)
+	aggregate = testAggregate(t, otherSecrets, []bool{true, true, true, true})
+	assert.Error(t, isSolved(other, aggregate, input))
+}
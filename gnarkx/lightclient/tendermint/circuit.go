@@ -0,0 +1,220 @@
+package tendermint
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/ed25519"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Circuit proves that a block is committed by more than 2/3 of the voting power of the validator
+// set of the validators hash.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The vote of the commit.
+	Height       vars.U64
+	Round        vars.U64
+	BlockHash    [32]vars.Byte
+	PartSetTotal vars.U64
+	PartSetHash  [32]vars.Byte
+
+	// The validator set and the signatures of the commit, where the signatures that are not
+	// flagged as committed are ignored.
+	PublicKeys   []ed25519.PublicKey
+	VotingPowers []vars.U64
+	Committed    []vars.Bool
+	Seconds      []vars.U64
+	Nanos        []vars.U64
+	Signatures   []ed25519.Signature
+
+	config     *Config     `gnark:"-"`
+	validators []Validator `gnark:"-"`
+	commit     *Commit     `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.NbValidators <= 0 || len(config.ChainID) == 0 || len(config.ChainID) >= 128 {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	n := config.NbValidators
+	c := &Circuit{
+		InputBytes:   vars.NewBytes(32),
+		OutputBytes:  vars.NewBytes(8 + 32 + 8),
+		Height:       vars.NewU64(),
+		Round:        vars.NewU64(),
+		BlockHash:    vars.NewBytes32(),
+		PartSetTotal: vars.NewU64(),
+		PartSetHash:  vars.NewBytes32(),
+		PublicKeys:   make([]ed25519.PublicKey, n),
+		VotingPowers: make([]vars.U64, n),
+		Committed:    make([]vars.Bool, n),
+		Seconds:      make([]vars.U64, n),
+		Nanos:        make([]vars.U64, n),
+		Signatures:   make([]ed25519.Signature, n),
+		config:       config,
+	}
+	for i := 0; i < n; i++ {
+		c.PublicKeys[i] = ed25519.NewPublicKey()
+		c.VotingPowers[i] = vars.NewU64()
+		c.Committed[i] = vars.NewBool(false)
+		c.Seconds[i] = vars.NewU64()
+		c.Nanos[i] = vars.NewU64()
+		c.Signatures[i] = ed25519.NewSignature()
+	}
+	return c
+}
+
+// Sets the validator set and the commit of a block by it that the next call to SetWitness assigns.
+// The signatures of the commit that are flagged as committed must be valid.
+func (c *Circuit) SetCommit(validators []Validator, commit *Commit) error {
+	if len(validators) != c.config.NbValidators {
+		return fmt.Errorf("validator set of %d validators, expected %d", len(validators), c.config.NbValidators)
+	}
+	if len(commit.Signatures) != c.config.NbValidators {
+		return fmt.Errorf("commit of %d signatures, expected %d", len(commit.Signatures), c.config.NbValidators)
+	}
+	if commit.Height <= 0 || commit.Round < 0 {
+		return fmt.Errorf("invalid height %d or round %d", commit.Height, commit.Round)
+	}
+	for i, validator := range validators {
+		if len(validator.PublicKey) != 32 || validator.VotingPower <= 0 {
+			return fmt.Errorf("invalid validator %d", i)
+		}
+	}
+	if err := c.config.verifySignatures(validators, commit); err != nil {
+		return err
+	}
+	c.validators = validators
+	c.commit = commit
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the validator set and the commit given to SetCommit. The signatures that
+// are not committed are assigned as zeros.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.commit == nil {
+		panic("commit must be set before the witness")
+	}
+	commit := c.commit
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.Height.Set(uint64(commit.Height))
+	c.Round.Set(uint64(commit.Round))
+	vars.SetBytes32(&c.BlockHash, commit.BlockID.Hash)
+	c.PartSetTotal.Set(uint64(commit.BlockID.PartSetHeader.Total))
+	vars.SetBytes32(&c.PartSetHash, commit.BlockID.PartSetHeader.Hash)
+	for i, validator := range c.validators {
+		c.PublicKeys[i].Set(validator.PublicKey)
+		c.VotingPowers[i].Set(uint64(validator.VotingPower))
+		sig := commit.Signatures[i]
+		c.Committed[i] = vars.NewBool(sig.Committed)
+		if sig.Committed {
+			c.Seconds[i].Set(uint64(sig.Timestamp.Unix()))
+			c.Nanos[i].Set(uint64(sig.Timestamp.Nanosecond()))
+			c.Signatures[i].Set(sig.Signature)
+		} else {
+			c.Seconds[i].Set(uint64(0))
+			c.Nanos[i].Set(uint64(0))
+			c.Signatures[i].Set(make([]byte, 64))
+		}
+	}
+	vars.SetBytes(&c.OutputBytes, outputs(c.validators, commit))
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	validatorsHash := inputReader.ReadBytes32()
+	config := c.config
+
+	// The validator set is the set of the validators hash, where the voting powers are less than
+	// 2^63 as int64.
+	leaves := make([][32]vars.Byte, config.NbValidators)
+	for i := 0; i < config.NbValidators; i++ {
+		// The leaf prefix followed by the field of the public key, which holds the field of the
+		// Ed25519 key.
+		prefix := constantBytes([]byte{0x00, 0x0a, 0x22, 0x0a, 0x20})
+		prefix.Data = append(prefix.Data, c.PublicKeys[i][:]...)
+		prefix.Length = vars.NewVariableFromInt(len(prefix.Data))
+		leaf := byteslice.Concat(*api, prefix, varintField(*api, 2, c.VotingPowers[i].Value, 9))
+		leaves[i] = sha256.HashVariable(*api, leaf.Data, vars.U64{Value: leaf.Length}, len(leaf.Data))
+	}
+	api.AssertIsEqualBytes32(merkleRootOf(*api, leaves), validatorsHash)
+
+	// The canonical vote of the block up to the timestamp, which is the same for all validators,
+	// where the height is a positive int64 and the round is a non-negative int32.
+	api.AssertIsDifferent(c.Height.Value, vars.NewVariableFromInt(0))
+	api.ToBinaryLE(c.Height.Value, 63)
+	api.ToBinaryLE(c.Round.Value, 31)
+	partSetHeader := byteslice.Concat(*api, varintField(*api, 1, c.PartSetTotal.Value, 5), bytes32Field(2, c.PartSetHash))
+	blockID := byteslice.Concat(*api, bytes32Field(1, c.BlockHash), messageField(*api, 2, partSetHeader))
+	vote := byteslice.Concat(
+		*api,
+		constantBytes([]byte{0x08, precommitType}),
+		fixed64Field(*api, 2, c.Height),
+		fixed64Field(*api, 3, c.Round),
+		messageField(*api, 4, blockID),
+	)
+	chainID := constantBytes(appendBytesField(nil, 6, []byte(config.ChainID)))
+
+	// The validators flagged as committed sign the vote with their timestamps, and they have more
+	// than 2/3 of the voting power.
+	ed25519API := ed25519.NewAPI(api)
+	total := vars.NewVariableFromInt(0)
+	signed := vars.NewVariableFromInt(0)
+	for i := 0; i < config.NbValidators; i++ {
+		api.AssertIsBoolean(c.Committed[i].Value)
+		timestamp := byteslice.Concat(*api, varintField(*api, 1, c.Seconds[i].Value, 9), varintField(*api, 2, c.Nanos[i].Value, 5))
+		signBytes := delimited(*api, byteslice.Concat(*api, vote, messageField(*api, 5, timestamp), chainID))
+		isValid := ed25519API.IsValidVariable(c.PublicKeys[i], signBytes, c.Signatures[i])
+		api.AssertIsEqual(api.Mul(c.Committed[i].Value, api.Not(isValid).Value), vars.NewVariableFromInt(0))
+		total = api.Add(total, c.VotingPowers[i].Value)
+		signed = api.Add(signed, api.Mul(c.Committed[i].Value, c.VotingPowers[i].Value))
+	}
+	// The total voting power is at most MaxTotalVotingPower, 2^60 - 1.
+	api.ToBinaryLE(total, 60)
+	api.AssertIsLessOrEqual(
+		api.Add(api.Mul(total, vars.NewVariableFromInt(2)), vars.NewVariableFromInt(1)),
+		api.Mul(signed, vars.NewVariableFromInt(3)),
+	)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteU64(c.Height)
+	outputWriter.WriteBytes32(c.BlockHash)
+	outputWriter.WriteU64(vars.U64{Value: signed})
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Returns the RFC 6962 merkle root of the hashes of the leaves.
+func merkleRootOf(api builder.API, leaves [][32]vars.Byte) [32]vars.Byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	split := splitPoint(len(leaves))
+	left := merkleRootOf(api, leaves[:split])
+	right := merkleRootOf(api, leaves[split:])
+	in := append(vars.NewBytesFrom([]byte{1}), left[:]...)
+	return sha256.Hash(api, append(in, right[:]...))
+}
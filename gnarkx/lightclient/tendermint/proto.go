@@ -0,0 +1,90 @@
+package tendermint
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The protobuf encodings of the fields of the signed messages in the circuit, as variable bytes
+// whose bytes past their lengths are zero so that they can be concatenated.
+
+// Returns constant bytes.
+func constantBytes(in []byte) vars.VariableBytes {
+	return vars.VariableBytes{Data: vars.NewBytesFrom(in), Length: vars.NewVariableFromInt(len(in))}
+}
+
+// Returns the varint encoding of a value less than 2^(7 * maxLength), which is range checked.
+func varint(api builder.API, value vars.Variable, maxLength int) vars.VariableBytes {
+	bits := api.ToBinaryLE(value, 7*maxLength)
+	groups := make([]vars.Variable, maxLength)
+	isNonzero := make([]vars.Bool, maxLength)
+	for i := 0; i < maxLength; i++ {
+		groups[i] = vars.NewVariableFromInt(0)
+		for j := 0; j < 7; j++ {
+			groups[i] = api.Add(groups[i], api.Mul(bits[i*7+j].Value, vars.NewVariableFromInt(1<<j)))
+		}
+		isNonzero[i] = api.Not(api.IsZero(groups[i]))
+	}
+
+	// A group is followed by another if any group after it is nonzero, which sets its top bit, and
+	// the groups past the last one are zero.
+	result := vars.VariableBytes{Data: make([]vars.Byte, maxLength), Length: vars.NewVariableFromInt(1)}
+	hasMore := vars.NewBool(false)
+	for i := maxLength - 1; i >= 0; i-- {
+		result.Data[i] = vars.Byte{Value: api.Add(groups[i], api.Mul(hasMore.Value, vars.NewVariableFromInt(128)))}
+		if i > 0 {
+			// api.Or adds its inputs, which need not be exclusive here.
+			hasMore = vars.Bool{Value: vars.Variable{Value: api.FrontendAPI().Or(hasMore.Value.Value, isNonzero[i].Value.Value)}}
+			result.Length = api.Add(result.Length, hasMore.Value)
+		}
+	}
+	return result
+}
+
+// Returns the bytes scaled by a boolean, which are the bytes or zeros.
+func mask(api builder.API, in vars.VariableBytes, isPresent vars.Bool) vars.VariableBytes {
+	result := vars.VariableBytes{Data: make([]vars.Byte, len(in.Data)), Length: api.Mul(in.Length, isPresent.Value)}
+	for i := range in.Data {
+		result.Data[i] = vars.Byte{Value: api.Mul(in.Data[i].Value, isPresent.Value)}
+	}
+	return result
+}
+
+// Returns the key of a field, whose number is less than 16.
+func key(number int, wireType int) vars.VariableBytes {
+	return constantBytes([]byte{byte(number<<3 | wireType)})
+}
+
+// Returns a varint field of a value less than 2^(7 * maxLength), omitted if it is zero.
+func varintField(api builder.API, number int, value vars.Variable, maxLength int) vars.VariableBytes {
+	field := byteslice.Concat(api, key(number, 0), varint(api, value, maxLength))
+	return mask(api, field, api.Not(api.IsZero(value)))
+}
+
+// Returns a fixed64 field of a value, omitted if it is zero.
+func fixed64Field(api builder.API, number int, value vars.U64) vars.VariableBytes {
+	bytes := api.ToBytes32FromU64LE(value)
+	field := vars.VariableBytes{Data: append(key(number, 1).Data, bytes[:8]...), Length: vars.NewVariableFromInt(9)}
+	return mask(api, field, api.Not(api.IsZero(value.Value)))
+}
+
+// Returns a field of 32 bytes.
+func bytes32Field(number int, value [32]vars.Byte) vars.VariableBytes {
+	data := append(key(number, 2).Data, vars.NewBytesFrom([]byte{32})...)
+	return vars.VariableBytes{Data: append(data, value[:]...), Length: vars.NewVariableFromInt(34)}
+}
+
+// Returns an embedded message field, which is encoded even if the message is empty.
+func messageField(api builder.API, number int, value vars.VariableBytes) vars.VariableBytes {
+	return byteslice.Concat(api, key(number, 2), delimited(api, value))
+}
+
+// Returns the bytes prefixed by their length.
+func delimited(api builder.API, in vars.VariableBytes) vars.VariableBytes {
+	maxLength := 1
+	for 1<<(7*maxLength) <= len(in.Data) {
+		maxLength++
+	}
+	return byteslice.Concat(api, varint(api, in.Length, maxLength), in)
+}
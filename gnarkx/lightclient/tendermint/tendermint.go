@@ -0,0 +1,195 @@
+// A circuit template verifying that a CometBFT (Tendermint) block is committed by more than 2/3 of
+// the voting power of a validator set, the check of the light clients of CometBFT chains.
+//
+// The input is the validators hash of the set, as in the headers of the chain. The output is the
+// height and the hash of the block, followed by the voting power of the validators that signed it.
+// Each validator whose signature is flagged as a commit signs the canonical vote of the block, the
+// length-delimited protobuf encoding of CanonicalVote with its own timestamp, with Ed25519, and the
+// block is accepted if 3 * signed > 2 * total, as VerifyCommitLight. The header is identified by
+// its hash, against which its fields can be proven with merkle proofs.
+//
+// The chain id is part of the signed votes and of the config, so a circuit is compiled per chain.
+//
+// Reference: https://github.com/cometbft/cometbft/blob/main/spec/light-client/verification/README.md
+package tendermint
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// The type of precommit votes, which commits are made of.
+const precommitType = 2
+
+// Config describes the chain and the size of the validator set a circuit accepts.
+type Config struct {
+	// The chain id, which the votes sign.
+	ChainID string
+
+	// The number of validators of a set.
+	NbValidators int
+}
+
+// A validator of a set.
+type Validator struct {
+	PublicKey   ed25519.PublicKey
+	VotingPower int64
+}
+
+// The header of the parts of a block.
+type PartSetHeader struct {
+	Total uint32
+	Hash  [32]byte
+}
+
+// The id of a block, where Hash is the hash of its header.
+type BlockID struct {
+	Hash          [32]byte
+	PartSetHeader PartSetHeader
+}
+
+// The signature of a validator in a commit, which counts only if Committed is set.
+type CommitSig struct {
+	Committed bool
+	Timestamp time.Time
+	Signature []byte
+}
+
+// The commit of a block, with a signature for every validator of the set in its order.
+type Commit struct {
+	Height     int64
+	Round      int32
+	BlockID    BlockID
+	Signatures []CommitSig
+}
+
+// Returns the input of the circuit for the validators hash of a set.
+func (c *Config) Input(validatorsHash [32]byte) []byte {
+	return validatorsHash[:]
+}
+
+// Returns the validators hash of a set, the RFC 6962 merkle root of the protobuf encodings of
+// SimpleValidator.
+func ValidatorsHash(validators []Validator) [32]byte {
+	leaves := make([][]byte, len(validators))
+	for i, validator := range validators {
+		leaves[i] = validatorBytes(validator)
+	}
+	return merkleRoot(leaves)
+}
+
+func validatorBytes(validator Validator) []byte {
+	var pubkey []byte
+	pubkey = appendBytesField(pubkey, 1, validator.PublicKey)
+	var result []byte
+	result = appendBytesField(result, 1, pubkey)
+	return appendVarintField(result, 2, uint64(validator.VotingPower))
+}
+
+func merkleRoot(leaves [][]byte) [32]byte {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return sha256.Sum256(append([]byte{0}, leaves[0]...))
+	}
+	split := splitPoint(len(leaves))
+	left := merkleRoot(leaves[:split])
+	right := merkleRoot(leaves[split:])
+	return sha256.Sum256(append(append([]byte{1}, left[:]...), right[:]...))
+}
+
+// Returns the largest power of 2 less than n.
+func splitPoint(n int) int {
+	split := 1
+	for split*2 < n {
+		split *= 2
+	}
+	return split
+}
+
+// Returns the bytes that a validator signs for a precommit of the block at the timestamp, or for
+// a nil precommit if the block id is zero.
+func (c *Config) VoteSignBytes(height int64, round int32, blockID BlockID, timestamp time.Time) []byte {
+	var vote []byte
+	vote = appendVarintField(vote, 1, precommitType)
+	vote = appendFixed64Field(vote, 2, uint64(height))
+	vote = appendFixed64Field(vote, 3, uint64(round))
+	if blockID != (BlockID{}) {
+		var partSetHeader []byte
+		partSetHeader = appendVarintField(partSetHeader, 1, uint64(blockID.PartSetHeader.Total))
+		partSetHeader = appendBytesField(partSetHeader, 2, blockID.PartSetHeader.Hash[:])
+		var canonicalBlockID []byte
+		canonicalBlockID = appendBytesField(canonicalBlockID, 1, blockID.Hash[:])
+		canonicalBlockID = appendMessageField(canonicalBlockID, 2, partSetHeader)
+		vote = appendMessageField(vote, 4, canonicalBlockID)
+	}
+	var ts []byte
+	ts = appendVarintField(ts, 1, uint64(timestamp.Unix()))
+	ts = appendVarintField(ts, 2, uint64(timestamp.Nanosecond()))
+	vote = appendMessageField(vote, 5, ts)
+	vote = appendBytesField(vote, 6, []byte(c.ChainID))
+	return append(binary.AppendUvarint(nil, uint64(len(vote))), vote...)
+}
+
+// Returns the error of the first signature of a commit that is not valid for its validator.
+func (c *Config) verifySignatures(validators []Validator, commit *Commit) error {
+	for i, sig := range commit.Signatures {
+		if !sig.Committed {
+			continue
+		}
+		signBytes := c.VoteSignBytes(commit.Height, commit.Round, commit.BlockID, sig.Timestamp)
+		if !ed25519.Verify(validators[i].PublicKey, signBytes, sig.Signature) {
+			return fmt.Errorf("invalid signature of validator %d", i)
+		}
+	}
+	return nil
+}
+
+// Returns the outputs of the circuit for the commit of a block by the validators.
+func outputs(validators []Validator, commit *Commit) []byte {
+	signed := uint64(0)
+	for i, sig := range commit.Signatures {
+		if sig.Committed {
+			signed += uint64(validators[i].VotingPower)
+		}
+	}
+	output := binary.BigEndian.AppendUint64(nil, uint64(commit.Height))
+	output = append(output, commit.BlockID.Hash[:]...)
+	return binary.BigEndian.AppendUint64(output, signed)
+}
+
+// The protobuf fields of proto3, which are omitted when they have the default value.
+
+func appendVarintField(b []byte, number int, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(number)<<3)
+	return binary.AppendUvarint(b, value)
+}
+
+func appendFixed64Field(b []byte, number int, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(number)<<3|1)
+	return binary.LittleEndian.AppendUint64(b, value)
+}
+
+func appendBytesField(b []byte, number int, value []byte) []byte {
+	if len(value) == 0 {
+		return b
+	}
+	return appendMessageField(b, number, value)
+}
+
+// Appends an embedded message that is not nullable, which is encoded even if it is empty.
+func appendMessageField(b []byte, number int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(number)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
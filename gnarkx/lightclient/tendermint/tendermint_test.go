@@ -0,0 +1,104 @@
+package tendermint
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+)
+
+var (
+	// A chain id long enough for the votes to need two bytes for their length.
+	config  = &Config{ChainID: "succinct-testnet-100", NbValidators: 3}
+	blockID = BlockID{
+		Hash:          sha256.Sum256([]byte("header")),
+		PartSetHeader: PartSetHeader{Total: 1, Hash: sha256.Sum256([]byte("parts"))},
+	}
+)
+
+func TestVoteSignBytes(t *testing.T) {
+	// The test vectors of CometBFT for precommits without a block.
+	zero := time.Time{}
+	nilVote := []byte{
+		0x21, 0x08, 0x02,
+		0x11, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x19, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x2a, 0x0b, 0x08, 0x80, 0x92, 0xb8, 0xc3, 0x98, 0xfe, 0xff, 0xff, 0xff, 0x01,
+	}
+	assert.Equal(t, nilVote, (&Config{}).VoteSignBytes(1, 1, BlockID{}, zero))
+	withChainID := append([]byte{0x30}, nilVote[1:]...)
+	withChainID = append(withChainID, 0x32, 0x0d)
+	withChainID = append(withChainID, "test_chain_id"...)
+	assert.Equal(t, withChainID, (&Config{ChainID: "test_chain_id"}).VoteSignBytes(1, 1, BlockID{}, zero))
+}
+
+// Returns a validator set with the voting powers and the keys of its validators.
+func testValidators(powers []int64, seed byte) ([]Validator, []ed25519.PrivateKey) {
+	validators := make([]Validator, len(powers))
+	keys := make([]ed25519.PrivateKey, len(powers))
+	for i := range powers {
+		keySeed := make([]byte, ed25519.SeedSize)
+		keySeed[0] = seed
+		keySeed[1] = byte(i)
+		keys[i] = ed25519.NewKeyFromSeed(keySeed)
+		validators[i] = Validator{PublicKey: keys[i].Public().(ed25519.PublicKey), VotingPower: powers[i]}
+	}
+	return validators, keys
+}
+
+// Returns the commit of the block by the validators that are flagged.
+func testCommit(keys []ed25519.PrivateKey, committed []bool) *Commit {
+	commit := &Commit{Height: 1234567, Round: 1, BlockID: blockID, Signatures: make([]CommitSig, len(keys))}
+	for i := range keys {
+		if committed[i] {
+			timestamp := time.Unix(1700000000+int64(i), int64(i)*123456789)
+			signBytes := config.VoteSignBytes(commit.Height, commit.Round, commit.BlockID, timestamp)
+			commit.Signatures[i] = CommitSig{Committed: true, Timestamp: timestamp, Signature: ed25519.Sign(keys[i], signBytes)}
+		}
+	}
+	return commit
+}
+
+func isSolved(validators []Validator, commit *Commit, input []byte) error {
+	circuit := NewCircuit(config)
+	// The commit is set without SetCommit, which rejects invalid signatures.
+	circuit.validators = validators
+	circuit.commit = commit
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(input)
+	return test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+}
+
+func TestCircuit(t *testing.T) {
+	validators, keys := testValidators([]int64{10, 20, 30}, 1)
+	input := config.Input(ValidatorsHash(validators))
+
+	// 50 of 60 is more than 2/3 of the voting power.
+	commit := testCommit(keys, []bool{false, true, true})
+	assert.NoError(t, NewCircuit(config).SetCommit(validators, commit))
+	assert.NoError(t, isSolved(validators, commit, input))
+	result := outputs(validators, commit)
+	assert.Equal(t, uint64(commit.Height), binary.BigEndian.Uint64(result[:8]))
+	assert.Equal(t, blockID.Hash[:], result[8:40])
+	assert.Equal(t, uint64(50), binary.BigEndian.Uint64(result[40:]))
+
+	// 40 of 60 is not.
+	commit = testCommit(keys, []bool{true, false, true})
+	assert.Error(t, isSolved(validators, commit, input))
+
+	// The signatures must be of the block.
+	commit = testCommit(keys, []bool{false, true, true})
+	commit.BlockID.Hash[0] ^= 1
+	assert.Error(t, NewCircuit(config).SetCommit(validators, commit))
+	assert.Error(t, isSolved(validators, commit, input))
+
+	// The validator set must be the set of the validators hash.
+	other, otherKeys := testValidators([]int64{10, 20, 30}, 2)
+	assert.Error(t, isSolved(other, testCommit(otherKeys, []bool{true, true, true}), input))
+}
@@ -0,0 +1,281 @@
+package maci
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/cipher/elgamal"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/eddsa"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Config describes the number of messages of a batch, the depth of the state tree and the number
+// of options.
+type Config struct {
+	NbMessages int
+	StateDepth int
+	NbOptions  int
+}
+
+// Returns the input of the circuit: the public key of the coordinator, the root of the state tree,
+// the hash chain of the messages before the batch and the tally.
+func (c *Config) Input(coordinatorKey babyjubjub.PointValue, root *big.Int, chain *big.Int, tally []*big.Int) []byte {
+	input := append(coordinatorKey.X.FillBytes(make([]byte, 32)), coordinatorKey.Y.FillBytes(make([]byte, 32))...)
+	input = append(append(input, root.FillBytes(make([]byte, 32))...), chain.FillBytes(make([]byte, 32))...)
+	for _, v := range tally {
+		input = append(input, v.FillBytes(make([]byte, 32))...)
+	}
+	return input
+}
+
+// A leaf of the state tree in the circuit.
+type StateLeaf struct {
+	PublicKey babyjubjub.Point
+	Balance   vars.Variable
+	Nonce     vars.Variable
+	Votes     []vars.Variable
+}
+
+// Sets the leaf.
+func (l *StateLeaf) Set(value StateLeafValue) {
+	l.PublicKey.Set(value.PublicKey)
+	l.Balance.Set(value.Balance)
+	l.Nonce.Set(value.Nonce)
+	for i := range value.Votes {
+		l.Votes[i].Set(value.Votes[i])
+	}
+}
+
+// ProcessCircuit proves that the coordinator processed a batch of messages into the state tree and
+// the tally. The input is the public key of the coordinator, the root of the state tree, the hash
+// chain of the messages before the batch and the tally of each option, as bytes32. The output is
+// the new root, the hash chain after the batch, which the contract checks against the messages
+// published, and the new tally.
+type ProcessCircuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	CoordinatorSecret vars.Variable
+	Messages          []elgamal.ElementsCiphertext
+	Leaves            []StateLeaf
+	Proofs            [][]vars.Variable
+
+	config   *Config                           `gnark:"-"`
+	secret   *big.Int                          `gnark:"-"`
+	messages []elgamal.ElementsCiphertextValue `gnark:"-"`
+	leaves   []StateLeafValue                  `gnark:"-"`
+	proofs   [][]*big.Int                      `gnark:"-"`
+	outputs  []byte                            `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*ProcessCircuit)(nil)
+
+// Creates a new circuit for the config.
+func NewProcessCircuit(config *Config) *ProcessCircuit {
+	if config.NbMessages < 1 || config.StateDepth < 1 || config.NbOptions < 1 || 4+config.NbOptions > poseidon.MaxInputs {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	c := &ProcessCircuit{
+		InputBytes:        vars.NewBytes(32 * (4 + config.NbOptions)),
+		OutputBytes:       vars.NewBytes(32 * (2 + config.NbOptions)),
+		CoordinatorSecret: vars.NewVariable(),
+		Messages:          make([]elgamal.ElementsCiphertext, config.NbMessages),
+		Leaves:            make([]StateLeaf, config.NbMessages),
+		Proofs:            make([][]vars.Variable, config.NbMessages),
+		config:            config,
+	}
+	for i := 0; i < config.NbMessages; i++ {
+		c.Messages[i] = elgamal.NewElementsCiphertext(messageLength)
+		c.Leaves[i] = StateLeaf{
+			PublicKey: babyjubjub.NewPoint(),
+			Balance:   vars.NewVariable(),
+			Nonce:     vars.NewVariable(),
+			Votes:     newVariables(config.NbOptions),
+		}
+		c.Proofs[i] = newVariables(config.StateDepth)
+	}
+	return c
+}
+
+func newVariables(n int) []vars.Variable {
+	out := make([]vars.Variable, n)
+	for i := 0; i < n; i++ {
+		out[i] = vars.NewVariable()
+	}
+	return out
+}
+
+// Applies the batch of messages to the tree and the tally with the secret of the coordinator, and
+// sets the batch that the next call to SetWitness assigns. The tree and the tally are updated in
+// place, so that the next batch continues from them.
+func (c *ProcessCircuit) SetBatch(tree *StateTree, tally []*big.Int, secret *big.Int, chain *big.Int, messages []elgamal.ElementsCiphertextValue) error {
+	config := c.config
+	if len(messages) != config.NbMessages || len(tally) != config.NbOptions {
+		return fmt.Errorf("%d messages and %d options, expected %d and %d", len(messages), len(tally), config.NbMessages, config.NbOptions)
+	}
+	if tree.Depth() != config.StateDepth || len(tree.blank.Votes) != config.NbOptions {
+		return fmt.Errorf("tree of depth %d for %d options, expected %d and %d", tree.Depth(), len(tree.blank.Votes), config.StateDepth, config.NbOptions)
+	}
+	for i, message := range messages {
+		if len(message.Elements) != messageLength {
+			return fmt.Errorf("message %d has %d elements, expected %d", i, len(message.Elements), messageLength)
+		}
+	}
+	c.leaves = make([]StateLeafValue, len(messages))
+	c.proofs = make([][]*big.Int, len(messages))
+	for i, message := range messages {
+		index := 0
+		if command, _, ok := decrypt(secret, message); ok && command.StateIndex.Cmp(big.NewInt(1<<tree.Depth())) < 0 {
+			index = int(command.StateIndex.Int64())
+		}
+		c.leaves[i] = tree.Leaf(index)
+		c.proofs[i] = tree.Proof(index)
+		apply(tree, tally, secret, message)
+	}
+	c.outputs = append(tree.Root().FillBytes(make([]byte, 32)), MessageChain(chain, messages).FillBytes(make([]byte, 32))...)
+	for _, v := range tally {
+		c.outputs = append(c.outputs, v.FillBytes(make([]byte, 32))...)
+	}
+	c.secret = secret
+	c.messages = messages
+	return nil
+}
+
+func (c *ProcessCircuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *ProcessCircuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *ProcessCircuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the batch given to SetBatch.
+func (c *ProcessCircuit) SetWitness(inputBytes []byte) {
+	if c.messages == nil {
+		panic("batch must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.CoordinatorSecret.Set(c.secret)
+	for i := range c.messages {
+		c.Messages[i].Set(c.messages[i])
+		c.Leaves[i].Set(c.leaves[i])
+		for j := range c.proofs[i] {
+			c.Proofs[i][j].Set(c.proofs[i][j])
+		}
+	}
+	vars.SetBytes(&c.OutputBytes, c.outputs)
+}
+
+func (c *ProcessCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	config := c.config
+	rc := rangecheck.New(api.FrontendAPI())
+	for i := 0; i < len(c.InputBytes); i++ {
+		rc.Check(c.InputBytes[i].Value.Value, 8)
+	}
+	word := func(i int) vars.Variable {
+		return api.ToVariableFromBytesBE(c.InputBytes[32*i : 32*(i+1)])
+	}
+	coordinatorKey := babyjubjub.Point{X: word(0), Y: word(1)}
+	root := word(2)
+	chain := word(3)
+	tally := make([]vars.Variable, config.NbOptions)
+	for i := range tally {
+		tally[i] = word(4 + i)
+	}
+
+	curve := babyjubjub.NewAPI(api)
+	curve.AssertIsEqual(curve.ScalarMulBase(c.CoordinatorSecret), coordinatorKey)
+	elgamalAPI := elgamal.NewAPI(api)
+	eddsaAPI := eddsa.NewAPI(api)
+	base := babyjubjub.NewPointFrom(babyjubjub.Base8())
+	zero := vars.NewVariableFromInt(0)
+	one := vars.NewVariableFromInt(1)
+	isLess := func(a, b vars.Variable) vars.Bool {
+		return api.IsZero(api.Add(api.Cmp(a, b), one))
+	}
+
+	for i, message := range c.Messages {
+		hash := poseidon.Hash(*api, append([]vars.Variable{message.Ephemeral.X, message.Ephemeral.Y}, message.Elements...))
+		chain = poseidon.Hash(*api, []vars.Variable{chain, hash})
+
+		// An ephemeral key off the curve is replaced by Base8, and the message is invalid.
+		isOnCurve := curve.IsOnCurve(message.Ephemeral)
+		ciphertext := elgamal.ElementsCiphertext{Ephemeral: curve.Select(isOnCurve, message.Ephemeral, base), Elements: message.Elements}
+		e := elgamalAPI.DecryptElements(c.CoordinatorSecret, ciphertext)
+		stateIndex, newPublicKey, option, weight, nonce := e[0], babyjubjub.Point{X: e[1], Y: e[2]}, e[3], e[4], e[5]
+		signature := eddsa.Signature{R8: babyjubjub.Point{X: e[7], Y: e[8]}, S: e[9]}
+
+		// The leaf at the state index, or at index 0 if the index is out of the tree.
+		inRange := api.And(isOnCurve, isLess(stateIndex, vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), uint(config.StateDepth))}))
+		index := api.Select(inRange, stateIndex, zero)
+		leaf := c.Leaves[i]
+		api.AssertIsEqual(stateRoot(*api, leafHash(*api, leaf), index, c.Proofs[i]), root)
+
+		old := zero
+		isOption := vars.NewBoolFromInt(0)
+		for j, votes := range leaf.Votes {
+			isEqual := api.IsZero(api.Sub(option, vars.NewVariableFromInt(j)))
+			old = api.Add(old, api.Mul(isEqual.Value, votes))
+			isOption = api.Or(isOption, isEqual)
+		}
+		isWeight := isLess(weight, vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), WeightBits)})
+		credits := api.Add(leaf.Balance, api.Mul(old, old))
+		cost := api.Mul(weight, weight)
+		canPay := api.Not(isLess(credits, cost))
+		isNonce := api.IsZero(api.Sub(nonce, api.Add(leaf.Nonce, one)))
+		commandHash := poseidon.Hash(*api, e[:7])
+		isSigned := eddsaAPI.IsValid(leaf.PublicKey, commandHash, signature)
+		isValid := api.And(api.And(inRange, isOption), api.And(isWeight, canPay))
+		isValid = api.And(api.And(isValid, isNonce), api.And(isSigned, curve.IsOnCurve(newPublicKey)))
+
+		updated := StateLeaf{
+			PublicKey: curve.Select(isValid, newPublicKey, leaf.PublicKey),
+			Balance:   api.Select(isValid, api.Sub(credits, cost), leaf.Balance),
+			Nonce:     api.Select(isValid, nonce, leaf.Nonce),
+			Votes:     make([]vars.Variable, len(leaf.Votes)),
+		}
+		for j, votes := range leaf.Votes {
+			isEqual := api.And(isValid, api.IsZero(api.Sub(option, vars.NewVariableFromInt(j))))
+			updated.Votes[j] = api.Select(isEqual, weight, votes)
+			tally[j] = api.Select(isEqual, api.Add(api.Sub(tally[j], votes), weight), tally[j])
+		}
+		root = stateRoot(*api, leafHash(*api, updated), index, c.Proofs[i])
+	}
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(api.ToBytes32FromVariableBE(root))
+	outputWriter.WriteBytes32(api.ToBytes32FromVariableBE(chain))
+	for _, v := range tally {
+		outputWriter.WriteBytes32(api.ToBytes32FromVariableBE(v))
+	}
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+func leafHash(api builder.API, leaf StateLeaf) vars.Variable {
+	in := []vars.Variable{leaf.PublicKey.X, leaf.PublicKey.Y, leaf.Balance, leaf.Nonce}
+	return poseidon.Hash(api, append(in, leaf.Votes...))
+}
+
+// Returns the root of the node at the index with the siblings, where the index is range checked
+// to the depth.
+func stateRoot(api builder.API, node vars.Variable, index vars.Variable, siblings []vars.Variable) vars.Variable {
+	bits := api.ToBinaryLE(index, len(siblings))
+	for i, sibling := range siblings {
+		left := api.Select(bits[i], sibling, node)
+		right := api.Select(bits[i], node, sibling)
+		node = poseidon.Hash(api, []vars.Variable{left, right})
+	}
+	return node
+}
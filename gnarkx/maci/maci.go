@@ -0,0 +1,248 @@
+// A circuit template for the vote processing of MACI, minimal anti-collusion infrastructure: voters
+// publish commands encrypted for a coordinator, who proves that it processed every message of a
+// batch into the state tree and the tally without revealing the commands, so that a voter can change
+// its key in secret and a briber cannot tell whether a vote it paid for counts.
+// Reference: https://maci.pse.dev/docs/core-concepts/spec
+//
+// The state tree is a Merkle tree of fixed depth over the Poseidon hash of the poseidon package,
+// whose leaves are the hashes of the state leaves:
+//   - leaf = poseidon(publicKey.X, publicKey.Y, balance, nonce, votes...)
+//
+// where the public key is an EdDSA key on Baby Jubjub of the eddsa package, the balance is the
+// voice credits left, and the votes are the weights of the voter for each option. The empty leaves,
+// and the leaf at index 0, are the blank leaf, whose public key is the identity so that no message
+// is valid for it.
+//
+// A command of a voter sets the weight of its vote for an option and its public key, and is signed
+// with its current key. It is valid if its nonce follows the nonce of the leaf and the voter can pay
+// the quadratic cost of the change of weight: balance + old^2 - weight^2 >= 0. A message is a
+// command and its signature encrypted for the coordinator with the hashed ElGamal of the elgamal
+// package, and invalid messages, including those that fail to decrypt, are skipped, so that no
+// message can halt the processing.
+package maci
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/cipher/elgamal"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/eddsa"
+)
+
+// The number of bits of the weight of a vote, so that its quadratic cost cannot overflow.
+const WeightBits = 32
+
+// The number of elements of a message: a command and its signature.
+const messageLength = 10
+
+// A leaf of the state tree outside of circuits.
+type StateLeafValue struct {
+	PublicKey babyjubjub.PointValue
+	Balance   *big.Int
+	Nonce     *big.Int
+	Votes     []*big.Int
+}
+
+// Returns the blank leaf for the number of options.
+func BlankLeaf(nbOptions int) StateLeafValue {
+	votes := make([]*big.Int, nbOptions)
+	for i := range votes {
+		votes[i] = big.NewInt(0)
+	}
+	return StateLeafValue{PublicKey: babyjubjub.Identity(), Balance: big.NewInt(0), Nonce: big.NewInt(0), Votes: votes}
+}
+
+// Returns the hash of the leaf.
+func (l StateLeafValue) Hash() *big.Int {
+	in := []*big.Int{l.PublicKey.X, l.PublicKey.Y, l.Balance, l.Nonce}
+	return poseidon.HashValues(append(in, l.Votes...)...)
+}
+
+// The state tree of fixed depth, whose leaves are blank until voters sign up.
+type StateTree struct {
+	leaves map[int]StateLeafValue
+	levels []map[int]*big.Int
+	blank  StateLeafValue
+	zeros  []*big.Int
+	size   int
+}
+
+// Creates a state tree of the depth for the number of options, with the blank leaf at index 0.
+func NewStateTree(depth int, nbOptions int) *StateTree {
+	if nbOptions < 1 || 4+nbOptions > poseidon.MaxInputs {
+		panic(fmt.Sprintf("unsupported number of options %d", nbOptions))
+	}
+	blank := BlankLeaf(nbOptions)
+	t := &StateTree{
+		leaves: make(map[int]StateLeafValue),
+		levels: make([]map[int]*big.Int, depth+1),
+		blank:  blank,
+		zeros:  make([]*big.Int, depth+1),
+		size:   1,
+	}
+	t.zeros[0] = blank.Hash()
+	for i := 0; i <= depth; i++ {
+		t.levels[i] = make(map[int]*big.Int)
+		if i > 0 {
+			t.zeros[i] = poseidon.HashValues(t.zeros[i-1], t.zeros[i-1])
+		}
+	}
+	return t
+}
+
+// Returns the depth of the tree.
+func (t *StateTree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Returns the root of the tree.
+func (t *StateTree) Root() *big.Int {
+	return t.node(t.Depth(), 0)
+}
+
+// Signs up a voter with the public key and the balance, and returns the index of its leaf.
+func (t *StateTree) SignUp(publicKey babyjubjub.PointValue, balance *big.Int) (int, error) {
+	if t.size == 1<<t.Depth() {
+		return 0, fmt.Errorf("tree of depth %d is full", t.Depth())
+	}
+	if !publicKey.IsOnCurve() {
+		return 0, fmt.Errorf("public key is not on the curve")
+	}
+	leaf := BlankLeaf(len(t.blank.Votes))
+	leaf.PublicKey = publicKey
+	leaf.Balance = new(big.Int).Set(balance)
+	index := t.size
+	t.SetLeaf(index, leaf)
+	t.size++
+	return index, nil
+}
+
+// Returns the leaf at the index.
+func (t *StateTree) Leaf(index int) StateLeafValue {
+	if leaf, ok := t.leaves[index]; ok {
+		return leaf
+	}
+	return t.blank
+}
+
+// Sets the leaf at the index.
+func (t *StateTree) SetLeaf(index int, leaf StateLeafValue) {
+	t.leaves[index] = leaf
+	t.levels[0][index] = leaf.Hash()
+	for i := 1; i <= t.Depth(); i++ {
+		index /= 2
+		t.levels[i][index] = poseidon.HashValues(t.node(i-1, 2*index), t.node(i-1, 2*index+1))
+	}
+}
+
+// Returns the siblings of the leaf at the index, from the leaf to the root.
+func (t *StateTree) Proof(index int) []*big.Int {
+	siblings := make([]*big.Int, t.Depth())
+	for i := 0; i < len(siblings); i++ {
+		siblings[i] = t.node(i, index^1)
+		index /= 2
+	}
+	return siblings
+}
+
+func (t *StateTree) node(level int, index int) *big.Int {
+	if node, ok := t.levels[level][index]; ok {
+		return node
+	}
+	return t.zeros[level]
+}
+
+// A command of a voter outside of circuits.
+type CommandValue struct {
+	StateIndex   *big.Int
+	NewPublicKey babyjubjub.PointValue
+	Option       *big.Int
+	Weight       *big.Int
+	Nonce        *big.Int
+	Salt         *big.Int
+}
+
+// Returns the hash of the command that the voter signs.
+func (c *CommandValue) Hash() *big.Int {
+	return poseidon.HashValues(c.StateIndex, c.NewPublicKey.X, c.NewPublicKey.Y, c.Option, c.Weight, c.Nonce, c.Salt)
+}
+
+// Signs the command with the secret scalar of the current key of the voter and encrypts it for the
+// public key of the coordinator with the random scalar.
+func (c *CommandValue) Encrypt(secret *big.Int, coordinatorKey babyjubjub.PointValue, randomness *big.Int) elgamal.ElementsCiphertextValue {
+	signature := eddsa.Sign(secret, c.Hash())
+	elements := []*big.Int{
+		c.StateIndex, c.NewPublicKey.X, c.NewPublicKey.Y, c.Option, c.Weight, c.Nonce, c.Salt,
+		signature.R8.X, signature.R8.Y, signature.S,
+	}
+	return elgamal.EncryptElements(coordinatorKey, elements, randomness)
+}
+
+// Returns the hash of a message, which the contract chains as poseidon(chain, hash) in the order
+// of publication.
+func MessageHash(message elgamal.ElementsCiphertextValue) *big.Int {
+	in := []*big.Int{message.Ephemeral.X, message.Ephemeral.Y}
+	return poseidon.HashValues(append(in, message.Elements...)...)
+}
+
+// Returns the hash chain of the messages from the chain.
+func MessageChain(chain *big.Int, messages []elgamal.ElementsCiphertextValue) *big.Int {
+	for _, message := range messages {
+		chain = poseidon.HashValues(chain, MessageHash(message))
+	}
+	return chain
+}
+
+// Decrypts the message with the secret of the coordinator and returns its command and signature,
+// or false if the ephemeral key is not on the curve.
+func decrypt(secret *big.Int, message elgamal.ElementsCiphertextValue) (CommandValue, eddsa.SignatureValue, bool) {
+	if !message.Ephemeral.IsOnCurve() || len(message.Elements) != messageLength {
+		return CommandValue{}, eddsa.SignatureValue{}, false
+	}
+	e := elgamal.DecryptElements(secret, message)
+	command := CommandValue{
+		StateIndex:   e[0],
+		NewPublicKey: babyjubjub.PointValue{X: e[1], Y: e[2]},
+		Option:       e[3],
+		Weight:       e[4],
+		Nonce:        e[5],
+		Salt:         e[6],
+	}
+	return command, eddsa.SignatureValue{R8: babyjubjub.PointValue{X: e[7], Y: e[8]}, S: e[9]}, true
+}
+
+// Applies the message to the tree and the tally and returns the index of the leaf that the message
+// reads, which is 0 if its state index is out of the tree, and whether the message is valid.
+func apply(tree *StateTree, tally []*big.Int, secret *big.Int, message elgamal.ElementsCiphertextValue) (int, bool) {
+	command, signature, ok := decrypt(secret, message)
+	if !ok || command.StateIndex.Cmp(big.NewInt(1<<tree.Depth())) >= 0 {
+		return 0, false
+	}
+	index := int(command.StateIndex.Int64())
+	leaf := tree.Leaf(index)
+	if command.Option.Cmp(big.NewInt(int64(len(tally)))) >= 0 || command.Weight.BitLen() > WeightBits {
+		return index, false
+	}
+	if command.Nonce.Cmp(new(big.Int).Add(leaf.Nonce, big.NewInt(1))) != 0 || !command.NewPublicKey.IsOnCurve() {
+		return index, false
+	}
+	if !eddsa.Verify(leaf.PublicKey, command.Hash(), signature) {
+		return index, false
+	}
+	option := int(command.Option.Int64())
+	old := leaf.Votes[option]
+	balance := new(big.Int).Add(leaf.Balance, new(big.Int).Mul(old, old))
+	balance.Sub(balance, new(big.Int).Mul(command.Weight, command.Weight))
+	if balance.Sign() < 0 {
+		return index, false
+	}
+
+	votes := make([]*big.Int, len(leaf.Votes))
+	copy(votes, leaf.Votes)
+	votes[option] = command.Weight
+	tree.SetLeaf(index, StateLeafValue{PublicKey: command.NewPublicKey, Balance: balance, Nonce: command.Nonce, Votes: votes})
+	tally[option] = new(big.Int).Add(new(big.Int).Sub(tally[option], old), command.Weight)
+	return index, true
+}
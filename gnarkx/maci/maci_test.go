@@ -0,0 +1,82 @@
+package maci
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/cipher/elgamal"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	config            = &Config{NbMessages: 4, StateDepth: 3, NbOptions: 2}
+	coordinatorSecret = big.NewInt(1234567)
+	coordinatorKey    = babyjubjub.MulBase(coordinatorSecret)
+)
+
+func newCommand(index int, newKey *big.Int, option int64, weight int64, nonce int64) *CommandValue {
+	return &CommandValue{
+		StateIndex:   big.NewInt(int64(index)),
+		NewPublicKey: babyjubjub.MulBase(newKey),
+		Option:       big.NewInt(option),
+		Weight:       big.NewInt(weight),
+		Nonce:        big.NewInt(nonce),
+		Salt:         big.NewInt(99),
+	}
+}
+
+func TestProcess(t *testing.T) {
+	alice, bob, bobNew := big.NewInt(1111), big.NewInt(2222), big.NewInt(3333)
+	tree := NewStateTree(config.StateDepth, config.NbOptions)
+	aliceIndex, err := tree.SignUp(babyjubjub.MulBase(alice), big.NewInt(100))
+	assert.NoError(t, err)
+	bobIndex, err := tree.SignUp(babyjubjub.MulBase(bob), big.NewInt(100))
+	assert.NoError(t, err)
+	tally := []*big.Int{big.NewInt(0), big.NewInt(0)}
+	chain := big.NewInt(0)
+	root := tree.Root()
+
+	// Alice votes 10 for option 1 and Bob changes his key before he votes 3 for option 0. A vote
+	// signed with the old key of Bob, as a briber would demand, is skipped.
+	messages := []elgamal.ElementsCiphertextValue{
+		newCommand(aliceIndex, alice, 1, 10, 1).Encrypt(alice, coordinatorKey, big.NewInt(11)),
+		newCommand(bobIndex, bobNew, 0, 0, 1).Encrypt(bob, coordinatorKey, big.NewInt(12)),
+		newCommand(bobIndex, bob, 1, 5, 2).Encrypt(bob, coordinatorKey, big.NewInt(13)),
+		newCommand(bobIndex, bobNew, 0, 3, 2).Encrypt(bobNew, coordinatorKey, big.NewInt(14)),
+	}
+	circuit := NewProcessCircuit(config)
+	assert.NoError(t, circuit.SetBatch(tree, tally, coordinatorSecret, chain, messages))
+	assert.Equal(t, "[3 10]", fmt.Sprint(tally))
+	assert.Equal(t, "0", tree.Leaf(aliceIndex).Balance.String())
+	assert.Equal(t, "91", tree.Leaf(bobIndex).Balance.String())
+	assert.NoError(t, functiontest.IsSolved(circuit, config.Input(coordinatorKey, root, chain, []*big.Int{big.NewInt(0), big.NewInt(0)})))
+	output := vars.GetValuesUnsafe(*circuit.GetOutputBytes())
+	assert.Equal(t, tree.Root().FillBytes(make([]byte, 32)), output[:32])
+	assert.Equal(t, MessageChain(chain, messages).FillBytes(make([]byte, 32)), output[32:64])
+
+	// Alice cannot pay for a weight of 11, the nonce of Bob must follow, the state index must be in
+	// the tree, and a message with an ephemeral key off the curve is skipped.
+	chain = MessageChain(chain, messages)
+	root = tree.Root()
+	invalid := newCommand(bobIndex, bobNew, 1, 1, 3).Encrypt(bobNew, coordinatorKey, big.NewInt(18))
+	invalid.Ephemeral = babyjubjub.PointValue{X: big.NewInt(1), Y: big.NewInt(2)}
+	messages = []elgamal.ElementsCiphertextValue{
+		newCommand(aliceIndex, alice, 1, 11, 2).Encrypt(alice, coordinatorKey, big.NewInt(15)),
+		newCommand(bobIndex, bobNew, 1, 1, 4).Encrypt(bobNew, coordinatorKey, big.NewInt(16)),
+		newCommand(1<<config.StateDepth, alice, 1, 1, 2).Encrypt(alice, coordinatorKey, big.NewInt(17)),
+		invalid,
+	}
+	previous := []*big.Int{big.NewInt(3), big.NewInt(10)}
+	circuit = NewProcessCircuit(config)
+	assert.NoError(t, circuit.SetBatch(tree, tally, coordinatorSecret, chain, messages))
+	assert.Equal(t, fmt.Sprint(previous), fmt.Sprint(tally))
+	assert.Equal(t, root, tree.Root())
+	assert.NoError(t, functiontest.IsSolved(circuit, config.Input(coordinatorKey, root, chain, previous)))
+
+	// The coordinator must know the secret of its key.
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(babyjubjub.MulBase(big.NewInt(1)), root, chain, previous)))
+}
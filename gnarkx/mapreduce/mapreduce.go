@@ -0,0 +1,198 @@
+// Orchestration for MapReduce-style circuits. A "map" circuit (any succinct.Circuit) proves the
+// computation over a single chunk of the input, and layers of "reduce" circuits recursively verify
+// and aggregate the map proofs until a single proof remains. All layers share the input hash and
+// output hash public inputs of succinct.CircuitFunction, so the final proof can be verified by the
+// same on-chain verifier as a regular function.
+package mapreduce
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/succinctlabs/succinctx/gnarkx/recursion"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Result is a proof produced by either the map circuit or a reduce layer, along with the values
+// of its public inputs and its output bytes.
+type Result struct {
+	Proof      groth16.Proof
+	InputHash  *big.Int
+	OutputHash *big.Int
+	Output     []byte
+}
+
+// Proves the map circuit over a single chunk of the input.
+func ProveMap(f *succinct.CircuitFunction, build *succinct.CircuitBuild, chunk []byte) (*Result, error) {
+	proof, err := f.ProveGroth16(chunk, build)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Proof:      proof,
+		InputHash:  f.InputHash.Value.(*big.Int),
+		OutputHash: f.OutputHash.Value.(*big.Int),
+		Output:     vars.GetValuesUnsafe(*f.Circuit.GetOutputBytes()),
+	}, nil
+}
+
+// Computes the input hash of a reduce circuit from the input hashes of its children.
+func HashInputHashes(inputHashes []*big.Int) *big.Int {
+	var data []byte
+	for i := 0; i < len(inputHashes); i++ {
+		var buf [32]byte
+		inputHashes[i].FillBytes(buf[:])
+		data = append(data, buf[:]...)
+	}
+	return sha256utils.HashAndTruncate(data, 253)
+}
+
+// A Layer is a compiled reduce circuit that aggregates a fixed number of proofs from the layer
+// below it.
+type Layer struct {
+	nbChildren        int
+	childOutputLength int
+	childVK           groth16.VerifyingKey
+	reducer           Reducer
+
+	r1cs constraint.ConstraintSystem
+	pk   groth16.ProvingKey
+	vk   groth16.VerifyingKey
+}
+
+// Compiles and sets up a reduce layer over children with the given verifying key. Note that the
+// setup is not secure for production use and keys should instead come from a ceremony.
+func NewLayer(
+	childVK groth16.VerifyingKey,
+	nbChildren int,
+	childOutputLength int,
+	reducer Reducer,
+) (*Layer, error) {
+	circuit, err := NewReduceCircuit(childVK, nbChildren, childOutputLength, reducer)
+	if err != nil {
+		return nil, err
+	}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile reduce circuit: %w", err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup reduce circuit: %w", err)
+	}
+	return &Layer{
+		nbChildren:        nbChildren,
+		childOutputLength: childOutputLength,
+		childVK:           childVK,
+		reducer:           reducer,
+		r1cs:              ccs,
+		pk:                pk,
+		vk:                vk,
+	}, nil
+}
+
+// Returns the verifying key of the layer. This is the child verifying key of the next layer.
+func (l *Layer) VerifyingKey() groth16.VerifyingKey {
+	return l.vk
+}
+
+// Returns the number of children aggregated by each proof of the layer.
+func (l *Layer) NbChildren() int {
+	return l.nbChildren
+}
+
+// Proves the reduction of the given children.
+func (l *Layer) Prove(children []*Result) (*Result, error) {
+	if len(children) != l.nbChildren {
+		return nil, fmt.Errorf("expected %d children, got %d", l.nbChildren, len(children))
+	}
+
+	assignment, err := NewReduceCircuit(l.childVK, l.nbChildren, l.childOutputLength, l.reducer)
+	if err != nil {
+		return nil, err
+	}
+	inputHashes := make([]*big.Int, len(children))
+	outputs := make([][]byte, len(children))
+	for i, child := range children {
+		if len(child.Output) != l.childOutputLength {
+			return nil, fmt.Errorf("child %d has %d output bytes, expected %d", i, len(child.Output), l.childOutputLength)
+		}
+		proof, err := recursion.ValueOfProof(child.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert proof of child %d: %w", i, err)
+		}
+		assignment.ChildProofs[i] = proof
+		assignment.ChildInputHashes[i].Set(child.InputHash)
+		vars.SetBytes(&assignment.ChildOutputs[i], child.Output)
+		inputHashes[i] = child.InputHash
+		outputs[i] = child.Output
+	}
+
+	output := l.reducer.ReduceNative(outputs)
+	inputHash := HashInputHashes(inputHashes)
+	outputHash := sha256utils.HashAndTruncate(output, 253)
+	assignment.InputHash.Set(inputHash)
+	assignment.OutputHash.Set(outputHash)
+
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create witness: %w", err)
+	}
+	proof, err := groth16.Prove(l.r1cs, l.pk, witness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate proof: %w", err)
+	}
+	return &Result{
+		Proof:      proof,
+		InputHash:  inputHash,
+		OutputHash: outputHash,
+		Output:     output,
+	}, nil
+}
+
+// Runs the full pipeline: the map circuit is proven over every chunk and the resulting proofs
+// are aggregated by each layer in turn. The number of chunks must equal the product of the
+// number of children of every layer.
+func Run(
+	f *succinct.CircuitFunction,
+	build *succinct.CircuitBuild,
+	layers []*Layer,
+	chunks [][]byte,
+) (*Result, error) {
+	expected := 1
+	for _, layer := range layers {
+		expected *= layer.nbChildren
+	}
+	if len(chunks) != expected {
+		return nil, fmt.Errorf("expected %d chunks, got %d", expected, len(chunks))
+	}
+
+	results := make([]*Result, len(chunks))
+	for i, chunk := range chunks {
+		result, err := ProveMap(f, build, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prove chunk %d: %w", i, err)
+		}
+		results[i] = result
+	}
+
+	for depth, layer := range layers {
+		next := make([]*Result, len(results)/layer.nbChildren)
+		for i := 0; i < len(next); i++ {
+			result, err := layer.Prove(results[i*layer.nbChildren : (i+1)*layer.nbChildren])
+			if err != nil {
+				return nil, fmt.Errorf("failed to prove layer %d: %w", depth, err)
+			}
+			next[i] = result
+		}
+		results = next
+	}
+	return results[0], nil
+}
@@ -0,0 +1,103 @@
+package mapreduce
+
+import (
+	gosha256 "crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/recursion"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A minimal map circuit with the same public inputs as a succinct.CircuitFunction.
+type TestMapCircuit struct {
+	InputHash  vars.Variable `gnark:"inputHash,public"`
+	OutputHash vars.Variable `gnark:"outputHash,public"`
+	Output     [4]vars.Byte
+}
+
+func (c *TestMapCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(c.OutputHash, sha256.HashAndTruncate(*api, c.Output[:], 253))
+	return nil
+}
+
+// Reduces the outputs of children by hashing their concatenation.
+type hashReducer struct{}
+
+func (hashReducer) Reduce(api builder.API, childOutputs [][]vars.Byte) []vars.Byte {
+	var in []vars.Byte
+	for _, output := range childOutputs {
+		in = append(in, output...)
+	}
+	h := sha256.Hash(api, in)
+	return h[:4]
+}
+
+func (hashReducer) ReduceNative(childOutputs [][]byte) []byte {
+	var in []byte
+	for _, output := range childOutputs {
+		in = append(in, output...)
+	}
+	h := gosha256.Sum256(in)
+	return h[:4]
+}
+
+func TestReduceCircuit(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestMapCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+
+	prove := func(chunk []byte, output []byte) *Result {
+		assignment := &TestMapCircuit{}
+		inputHash := sha256utils.HashAndTruncate(chunk, 253)
+		outputHash := sha256utils.HashAndTruncate(output, 253)
+		assignment.InputHash.Set(inputHash)
+		assignment.OutputHash.Set(outputHash)
+		for i := 0; i < 4; i++ {
+			assignment.Output[i].Set(output[i])
+		}
+		witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+		proof, err := groth16.Prove(ccs, pk, witness)
+		assert.NoError(t, err)
+		return &Result{Proof: proof, InputHash: inputHash, OutputHash: outputHash, Output: output}
+	}
+	children := []*Result{
+		prove([]byte("chunk 0"), []byte{1, 2, 3, 4}),
+		prove([]byte("chunk 1"), []byte{5, 6, 7, 8}),
+	}
+
+	circuit, err := NewReduceCircuit(vk, 2, 4, hashReducer{})
+	assert.NoError(t, err)
+	assignment, err := NewReduceCircuit(vk, 2, 4, hashReducer{})
+	assert.NoError(t, err)
+	for i, child := range children {
+		proof, err := recursion.ValueOfProof(child.Proof)
+		assert.NoError(t, err)
+		assignment.ChildProofs[i] = proof
+		assignment.ChildInputHashes[i].Set(child.InputHash)
+		vars.SetBytes(&assignment.ChildOutputs[i], child.Output)
+	}
+	output := hashReducer{}.ReduceNative([][]byte{children[0].Output, children[1].Output})
+	assignment.InputHash.Set(HashInputHashes([]*big.Int{children[0].InputHash, children[1].InputHash}))
+	assignment.OutputHash.Set(sha256utils.HashAndTruncate(output, 253))
+
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// A child output that doesn't match the proven output hash must be rejected.
+	vars.SetBytes(&assignment.ChildOutputs[1], []byte{0, 0, 0, 0})
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package mapreduce
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/recursion"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A BN254 Groth16 proof as a circuit variable.
+type Proof = recursion.Proof
+
+// A BN254 Groth16 verifying key as a circuit variable.
+type VerifyingKey = recursion.VerifyingKey
+
+// A Reducer combines the outputs of several child proofs into a single output.
+type Reducer interface {
+	// Computes the reduced output in-circuit from the output bytes of each child.
+	Reduce(api builder.API, childOutputs [][]vars.Byte) []vars.Byte
+
+	// Computes the reduced output outside of the circuit. It must match Reduce.
+	ReduceNative(childOutputs [][]byte) []byte
+}
+
+// ReduceCircuit verifies a fixed number of child proofs and reduces their outputs. The circuit
+// exposes the same public inputs as a succinct.CircuitFunction, so its proofs can themselves be
+// aggregated by another ReduceCircuit:
+//   - inputHash = sha256(childInputHash_0 || ... || childInputHash_n) & ((1 << 253) - 1)
+//   - outputHash = sha256(reduce(childOutput_0, ..., childOutput_n)) & ((1 << 253) - 1)
+//
+// where each child input hash is encoded as a big-endian bytes32.
+type ReduceCircuit struct {
+	// The input hash is the commitment to the input hashes of all children.
+	InputHash vars.Variable `gnark:"inputHash,public"`
+
+	// The output hash is the hash of the reduced output.
+	OutputHash vars.Variable `gnark:"outputHash,public"`
+
+	// The proofs of each child.
+	ChildProofs []Proof
+
+	// The input hash of each child.
+	ChildInputHashes []vars.Variable
+
+	// The output bytes of each child, which are checked against each child's output hash.
+	ChildOutputs [][]vars.Byte
+
+	// The verifying key of the children. It is a constant of the circuit rather than part of the
+	// witness so that only proofs of the expected child circuit are accepted.
+	vk VerifyingKey `gnark:"-"`
+
+	reducer Reducer `gnark:"-"`
+}
+
+// Creates a new reduce circuit that aggregates nbChildren proofs with the given verifying key,
+// each having childOutputLength output bytes.
+func NewReduceCircuit(
+	childVK groth16.VerifyingKey,
+	nbChildren int,
+	childOutputLength int,
+	reducer Reducer,
+) (*ReduceCircuit, error) {
+	vk, err := recursion.ValueOfVerifyingKey(childVK)
+	if err != nil {
+		return nil, err
+	}
+	if recursion.NbPublicInputs(&vk) != 2 {
+		return nil, fmt.Errorf("child circuit must have exactly two public inputs, found %d", recursion.NbPublicInputs(&vk))
+	}
+	inputHashes := make([]vars.Variable, nbChildren)
+	for i := 0; i < nbChildren; i++ {
+		inputHashes[i] = vars.NewVariable()
+	}
+	return &ReduceCircuit{
+		InputHash:        vars.NewVariable(),
+		OutputHash:       vars.NewVariable(),
+		ChildProofs:      make([]Proof, nbChildren),
+		ChildInputHashes: inputHashes,
+		ChildOutputs:     vars.NewBytesArray(nbChildren, childOutputLength),
+		vk:               vk,
+		reducer:          reducer,
+	}, nil
+}
+
+// Define the circuit. Each child proof is verified against its input hash and the hash of its
+// output bytes, after which the input hashes are chained and the outputs are reduced.
+func (c *ReduceCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	recursionAPI := recursion.NewAPI(api)
+
+	var childInputHashBytes []vars.Byte
+	for i := 0; i < len(c.ChildProofs); i++ {
+		outputHash := sha256.HashAndTruncate(*api, c.ChildOutputs[i], 253)
+		recursionAPI.AssertProof(&c.vk, &c.ChildProofs[i], []vars.Variable{c.ChildInputHashes[i], outputHash})
+
+		inputHashBytes := toBytes32FromBitsLE(*api, api.ToBinaryLE(c.ChildInputHashes[i], 253))
+		childInputHashBytes = append(childInputHashBytes, inputHashBytes[:]...)
+	}
+
+	inputHash := sha256.HashAndTruncate(*api, childInputHashBytes, 253)
+	api.AssertIsEqual(c.InputHash, inputHash)
+
+	output := c.reducer.Reduce(*api, c.ChildOutputs)
+	outputHash := sha256.HashAndTruncate(*api, output, 253)
+	api.AssertIsEqual(c.OutputHash, outputHash)
+	return nil
+}
+
+// Packs little-endian bits into a big-endian bytes32, padding the most significant bits with
+// zeros.
+func toBytes32FromBitsLE(api builder.API, bits []vars.Bool) [32]vars.Byte {
+	var result [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			if i*8+j < len(bits) {
+				byteBits[j] = bits[i*8+j]
+			} else {
+				byteBits[j] = vars.FALSE
+			}
+		}
+		result[32-i-1] = api.ToByteFromBits(byteBits)
+	}
+	return result
+}
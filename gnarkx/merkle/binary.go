@@ -0,0 +1,147 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	sha256gadget "github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Hasher hashes the left and the right children of 32 bytes of a binary Merkle tree into their
+// parent.
+type Hasher interface {
+	// Computes the parent in the circuit.
+	Hash(api builder.API, left [32]vars.Byte, right [32]vars.Byte) [32]vars.Byte
+
+	// Computes the parent outside of the circuit. It must match Hash.
+	HashValues(left [32]byte, right [32]byte) [32]byte
+}
+
+// SHA256 hashes the children as sha256(left || right), as the trees of SSZ and of most contracts.
+type SHA256 struct{}
+
+var _ Hasher = SHA256{}
+
+func (SHA256) Hash(api builder.API, left [32]vars.Byte, right [32]vars.Byte) [32]vars.Byte {
+	return sha256gadget.Hash(api, append(left[:], right[:]...))
+}
+
+func (SHA256) HashValues(left [32]byte, right [32]byte) [32]byte {
+	return sha256.Sum256(append(left[:], right[:]...))
+}
+
+// Keccak256 hashes the children as keccak256(left || right), as the MerkleProof of OpenZeppelin
+// without the sorting of the children.
+type Keccak256 struct{}
+
+var _ Hasher = Keccak256{}
+
+func (Keccak256) Hash(api builder.API, left [32]vars.Byte, right [32]vars.Byte) [32]vars.Byte {
+	return keccak256.Hash(api, append(left[:], right[:]...))
+}
+
+func (Keccak256) HashValues(left [32]byte, right [32]byte) [32]byte {
+	var out [32]byte
+	copy(out[:], crypto.Keccak256(left[:], right[:]))
+	return out
+}
+
+// Poseidon hashes the children as poseidon(left[:16], left[16:], right[:16], right[16:]) with the
+// halves as big-endian integers, so that any 32 bytes are a node, and the parent is the hash as 32
+// big-endian bytes. It is much cheaper in circuits than the byte-oriented hashes, such as for trees
+// that are opened in recursive proofs.
+type Poseidon struct{}
+
+var _ Hasher = Poseidon{}
+
+func (Poseidon) Hash(api builder.API, left [32]vars.Byte, right [32]vars.Byte) [32]vars.Byte {
+	var in []vars.Variable
+	for _, node := range [][32]vars.Byte{left, right} {
+		u := api.ToU256FromBytes32(node)
+		in = append(in,
+			api.Add(api.Mul(u.Limbs[3], powerOf64), u.Limbs[2]),
+			api.Add(api.Mul(u.Limbs[1], powerOf64), u.Limbs[0]),
+		)
+	}
+	// The decomposition into 256 bits is unique since it checks that the bits are reduced.
+	bits := api.ToBinaryBE(poseidon.Hash(api, in), 256)
+	var out [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			byteBits[7-j] = bits[8*i+j]
+		}
+		out[i] = api.ToByteFromBits(byteBits)
+	}
+	return out
+}
+
+func (Poseidon) HashValues(left [32]byte, right [32]byte) [32]byte {
+	hash := poseidon.HashValues(
+		new(big.Int).SetBytes(left[:16]),
+		new(big.Int).SetBytes(left[16:]),
+		new(big.Int).SetBytes(right[:16]),
+		new(big.Int).SetBytes(right[16:]),
+	)
+	var out [32]byte
+	hash.FillBytes(out[:])
+	return out
+}
+
+// 2^64, to join the limbs of a u256.
+var powerOf64 = vars.NewVariableFromString(new(big.Int).Lsh(big.NewInt(1), 64).String())
+
+// Returns the root of the leaf at the index with the proof of its siblings from the leaf to the
+// root, where bit i of the index is set if the node is the right child of the level of the sibling
+// i.
+func RootValue(hasher Hasher, leaf [32]byte, proof [][32]byte, index int) [32]byte {
+	if index < 0 || index>>len(proof) != 0 {
+		panic(fmt.Sprintf("index %d of a tree of depth %d", index, len(proof)))
+	}
+	node := leaf
+	for i := range proof {
+		if index>>i&1 == 1 {
+			node = hasher.HashValues(proof[i], node)
+		} else {
+			node = hasher.HashValues(node, proof[i])
+		}
+	}
+	return node
+}
+
+// Returns the root of the binary Merkle tree of the hasher that includes the leaf at the index with
+// the proof of its siblings, as RootValue. The depth of the tree is len(proof), a compile time
+// constant, while the index is a variable, which is range checked to the depth.
+func (a *MerkleAPI) RestoreRoot(
+	hasher Hasher,
+	leaf [32]vars.Byte,
+	proof [][32]vars.Byte,
+	index vars.U64,
+) [32]vars.Byte {
+	api := a.api
+	bits := api.ToBinaryLE(index.Value, len(proof))
+	node := leaf
+	for i := range proof {
+		left := api.SelectBytes32(bits[i], proof[i], node)
+		right := api.SelectBytes32(bits[i], node, proof[i])
+		node = hasher.Hash(api, left, right)
+	}
+	return node
+}
+
+// Verifies that the leaf is at the index of the binary Merkle tree of the hasher with the root.
+func (a *MerkleAPI) VerifyProof(
+	hasher Hasher,
+	root [32]vars.Byte,
+	leaf [32]vars.Byte,
+	proof [][32]vars.Byte,
+	index vars.U64,
+) {
+	a.api.AssertIsEqualBytes32(root, a.RestoreRoot(hasher, leaf, proof, index))
+}
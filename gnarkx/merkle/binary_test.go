@@ -0,0 +1,86 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testBinaryCircuit struct {
+	Root   [32]vars.Byte
+	Leaf   [32]vars.Byte
+	Proof  [][32]vars.Byte
+	Index  vars.U64
+	hasher Hasher `gnark:"-"`
+}
+
+func (c *testBinaryCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).VerifyProof(c.hasher, c.Root, c.Leaf, c.Proof, c.Index)
+	return nil
+}
+
+func newBinaryAssignment(root [32]byte, leaf [32]byte, proof [][32]byte, index int) *testBinaryCircuit {
+	assignment := &testBinaryCircuit{Root: vars.NewBytes32(), Leaf: vars.NewBytes32(), Proof: vars.NewBytes32Array(len(proof))}
+	vars.SetBytes32(&assignment.Root, root)
+	vars.SetBytes32(&assignment.Leaf, leaf)
+	vars.SetBytes32Array(&assignment.Proof, proof)
+	assignment.Index.Set(uint64(index))
+	return assignment
+}
+
+func toBytes32(hex string) [32]byte {
+	var out [32]byte
+	copy(out[:], hexutil.MustDecode(hex))
+	return out
+}
+
+func TestRootValue(t *testing.T) {
+	// The proof of the gindex 105 of telepathy-contracts, i.e. the index 105 - 2^6 of a tree of
+	// depth 6.
+	root := toBytes32("0xe81a65c5c0f2a36e40b6872fcfdd62dbb67d47f3d49a6b978c0d4440341e723f")
+	leaf := toBytes32("0xd85d3181f1178b07e89691aa2bfcd4d88837f011fcda3326b4ce9a68ec6d9e44")
+	proof := [][32]byte{
+		toBytes32("0xe424020000000000000000000000000000000000000000000000000000000000"),
+		toBytes32("0x75410a8f37f9506fb3f972cce6ece955e381e51037e432ce4ca47479c9cd9158"),
+		toBytes32("0xe6af38835c0ac3c2b0d561dfaec168171d7d77c1c2e8e74ff9b1891cf43faf8d"),
+		toBytes32("0x3e4fb2d12bd835bc6ee23b5ec65a43f4493e32f5ef45d46bd2c38830b17672bb"),
+		toBytes32("0x880548f4df2d4003f7be2fbbde112eb46b8f756b5e33202e04863000e4383f3b"),
+		toBytes32("0x88475251bcec25245a44bddd92b2c36db6c9c48bc6d91b5d0da78af3229ff783"),
+	}
+	assert.Equal(t, root, RootValue(SHA256{}, leaf, proof, 105-64))
+	assert.Panics(t, func() { RootValue(SHA256{}, leaf, proof, 64) })
+}
+
+func TestVerifyProof(t *testing.T) {
+	var leaf [32]byte
+	proof := make([][32]byte, 3)
+	for i := 0; i < 32; i++ {
+		// The leaf is larger than the scalar field, which Poseidon splits into halves.
+		leaf[i] = byte(255 - i)
+		for j := range proof {
+			proof[j][i] = byte(i * (j + 2))
+		}
+	}
+	for _, hasher := range []Hasher{SHA256{}, Keccak256{}, Poseidon{}} {
+		root := RootValue(hasher, leaf, proof, 5)
+		circuit := &testBinaryCircuit{Root: vars.NewBytes32(), Leaf: vars.NewBytes32(), Proof: vars.NewBytes32Array(len(proof)), hasher: hasher}
+		assignment := newBinaryAssignment(root, leaf, proof, 5)
+		assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// The leaf must be at the index.
+		assignment = newBinaryAssignment(root, leaf, proof, 4)
+		assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// The index must be in the tree.
+		assignment = newBinaryAssignment(root, leaf, proof, 5)
+		assignment.Index.Set(5 + 8)
+		assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	}
+}
@@ -0,0 +1,268 @@
+package merkle
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A leaf of an indexed Merkle tree outside of circuits: a value and the index and the value of the
+// next greater value of the tree, or zero if the value is the greatest.
+type IndexedLeafValue struct {
+	Value     *big.Int
+	NextIndex int
+	NextValue *big.Int
+}
+
+// Returns the hash of the leaf.
+func (l IndexedLeafValue) Hash() *big.Int {
+	return poseidon.HashValues(l.Value, big.NewInt(int64(l.NextIndex)), l.NextValue)
+}
+
+// An indexed Merkle tree, the nullifier tree of Aztec: a Merkle tree of fixed depth whose leaves
+// form a linked list of the values in ascending order, so that the non-membership of a value is
+// proven by the inclusion of the leaf of the greatest smaller value, the low leaf, whose next value
+// is greater, rather than by a sparse tree of the depth of the field. The first leaf is (0, 0, 0),
+// the values are nonzero elements of the scalar field, the parent of two nodes is
+// poseidon(left, right), and the empty leaves are zero.
+// Reference: https://docs.aztec.network/aztec/concepts/advanced/storage/indexed_merkle_tree
+type IndexedTree struct {
+	leaves []IndexedLeafValue
+	levels []map[int]*big.Int
+	zeros  []*big.Int
+}
+
+// The low leaf of a value with its index and its siblings outside of circuits.
+type IndexedLowLeafValue struct {
+	Leaf     IndexedLeafValue
+	Index    int
+	Siblings []*big.Int
+}
+
+// The witness of the insertion of a value: the low leaf before the insertion and the siblings of
+// the empty leaf of the value after the update of the low leaf.
+type IndexedInsertionValue struct {
+	Low      IndexedLowLeafValue
+	Siblings []*big.Int
+}
+
+// Creates an indexed Merkle tree of the depth with the first leaf.
+func NewIndexedTree(depth int) *IndexedTree {
+	t := &IndexedTree{levels: make([]map[int]*big.Int, depth+1), zeros: make([]*big.Int, depth+1)}
+	t.zeros[0] = big.NewInt(0)
+	for i := 0; i <= depth; i++ {
+		t.levels[i] = make(map[int]*big.Int)
+		if i > 0 {
+			t.zeros[i] = poseidon.HashValues(t.zeros[i-1], t.zeros[i-1])
+		}
+	}
+	t.setLeaf(0, IndexedLeafValue{Value: big.NewInt(0), NextValue: big.NewInt(0)})
+	return t
+}
+
+// Returns the depth of the tree.
+func (t *IndexedTree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Returns the root of the tree.
+func (t *IndexedTree) Root() *big.Int {
+	return t.node(t.Depth(), 0)
+}
+
+// Returns the number of leaves of the tree, which is the index of the next leaf.
+func (t *IndexedTree) Size() int {
+	return len(t.leaves)
+}
+
+// Returns the low leaf of a value that is not in the tree.
+func (t *IndexedTree) LowLeaf(value *big.Int) (IndexedLowLeafValue, error) {
+	if value.Sign() <= 0 {
+		return IndexedLowLeafValue{}, fmt.Errorf("value %s is not a nonzero element", value)
+	}
+	low := 0
+	for i, leaf := range t.leaves {
+		if leaf.Value.Cmp(value) == 0 {
+			return IndexedLowLeafValue{}, fmt.Errorf("value %s is at index %d", value, i)
+		}
+		if leaf.Value.Cmp(value) < 0 && leaf.Value.Cmp(t.leaves[low].Value) > 0 {
+			low = i
+		}
+	}
+	return IndexedLowLeafValue{Leaf: t.leaves[low], Index: low, Siblings: t.siblings(low)}, nil
+}
+
+// Inserts a value that is not in the tree and returns the witness of the insertion.
+func (t *IndexedTree) Insert(value *big.Int) (IndexedInsertionValue, error) {
+	if t.Size() == 1<<t.Depth() {
+		return IndexedInsertionValue{}, fmt.Errorf("tree of depth %d is full", t.Depth())
+	}
+	low, err := t.LowLeaf(value)
+	if err != nil {
+		return IndexedInsertionValue{}, err
+	}
+	index := t.Size()
+	t.setLeaf(low.Index, IndexedLeafValue{Value: low.Leaf.Value, NextIndex: index, NextValue: new(big.Int).Set(value)})
+	siblings := t.siblings(index)
+	t.setLeaf(index, IndexedLeafValue{Value: new(big.Int).Set(value), NextIndex: low.Leaf.NextIndex, NextValue: low.Leaf.NextValue})
+	return IndexedInsertionValue{Low: low, Siblings: siblings}, nil
+}
+
+func (t *IndexedTree) node(level int, index int) *big.Int {
+	if node, ok := t.levels[level][index]; ok {
+		return node
+	}
+	return t.zeros[level]
+}
+
+func (t *IndexedTree) siblings(index int) []*big.Int {
+	siblings := make([]*big.Int, t.Depth())
+	for i := 0; i < len(siblings); i++ {
+		siblings[i] = t.node(i, index^1)
+		index /= 2
+	}
+	return siblings
+}
+
+func (t *IndexedTree) setLeaf(index int, leaf IndexedLeafValue) {
+	if index == len(t.leaves) {
+		t.leaves = append(t.leaves, leaf)
+	}
+	t.leaves[index] = leaf
+	t.levels[0][index] = leaf.Hash()
+	for i := 1; i <= t.Depth(); i++ {
+		index /= 2
+		t.levels[i][index] = poseidon.HashValues(t.node(i-1, 2*index), t.node(i-1, 2*index+1))
+	}
+}
+
+// A leaf of an indexed Merkle tree in the circuit.
+type IndexedLeaf struct {
+	Value     vars.Variable
+	NextIndex vars.Variable
+	NextValue vars.Variable
+}
+
+// Sets the leaf.
+func (l *IndexedLeaf) Set(value IndexedLeafValue) {
+	l.Value.Set(value.Value)
+	l.NextIndex = vars.NewVariableFromInt(value.NextIndex)
+	l.NextValue.Set(value.NextValue)
+}
+
+// The low leaf of a value with its index and its siblings in the circuit.
+type IndexedLowLeaf struct {
+	Leaf     IndexedLeaf
+	Index    vars.Variable
+	Siblings []vars.Variable
+}
+
+// Creates a new low leaf for trees of the depth.
+func NewIndexedLowLeaf(depth int) IndexedLowLeaf {
+	return IndexedLowLeaf{
+		Leaf:     IndexedLeaf{Value: vars.NewVariable(), NextIndex: vars.NewVariable(), NextValue: vars.NewVariable()},
+		Index:    vars.NewVariable(),
+		Siblings: newVariables(depth),
+	}
+}
+
+// Sets the low leaf.
+func (l *IndexedLowLeaf) Set(value IndexedLowLeafValue) error {
+	if len(value.Siblings) != len(l.Siblings) {
+		return fmt.Errorf("tree of depth %d, expected %d", len(value.Siblings), len(l.Siblings))
+	}
+	l.Leaf.Set(value.Leaf)
+	l.Index = vars.NewVariableFromInt(value.Index)
+	for i := range value.Siblings {
+		l.Siblings[i].Set(value.Siblings[i])
+	}
+	return nil
+}
+
+// The witness of the insertion of a value in the circuit.
+type IndexedInsertion struct {
+	Low      IndexedLowLeaf
+	Siblings []vars.Variable
+}
+
+// Creates a new insertion for trees of the depth.
+func NewIndexedInsertion(depth int) IndexedInsertion {
+	return IndexedInsertion{Low: NewIndexedLowLeaf(depth), Siblings: newVariables(depth)}
+}
+
+// Sets the insertion.
+func (s *IndexedInsertion) Set(value IndexedInsertionValue) error {
+	if len(value.Siblings) != len(s.Siblings) {
+		return fmt.Errorf("tree of depth %d, expected %d", len(value.Siblings), len(s.Siblings))
+	}
+	for i := range value.Siblings {
+		s.Siblings[i].Set(value.Siblings[i])
+	}
+	return s.Low.Set(value.Low)
+}
+
+func newVariables(n int) []vars.Variable {
+	out := make([]vars.Variable, n)
+	for i := 0; i < n; i++ {
+		out[i] = vars.NewVariable()
+	}
+	return out
+}
+
+// Verifies that the value is not in the indexed Merkle tree of the root, ie. that the low leaf is
+// in the tree, its value is less than the value and its next value is greater or zero.
+func (a *MerkleAPI) VerifyNonMembership(root vars.Variable, value vars.Variable, low IndexedLowLeaf) {
+	api := a.api
+	leaf := low.Leaf
+	api.AssertIsEqual(a.indexedRoot(a.indexedHash(leaf), low.Index, low.Siblings), root)
+	one := vars.NewVariableFromInt(1)
+	api.AssertIsEqual(api.Cmp(value, leaf.Value), one)
+	isLast := api.IsZero(leaf.NextValue)
+	api.AssertIsEqual(api.Select(isLast, vars.NewVariableFromInt(-1), api.Cmp(value, leaf.NextValue)), vars.NewVariableFromInt(-1))
+}
+
+// Inserts the value, which is not in the indexed Merkle tree of the root, at the index size, and
+// returns the new root.
+func (a *MerkleAPI) Insert(root vars.Variable, size vars.Variable, value vars.Variable, insertion IndexedInsertion) vars.Variable {
+	api := a.api
+	low := insertion.Low
+	a.VerifyNonMembership(root, value, low)
+
+	// The low leaf points to the value, and the leaf of the value takes its next value.
+	updated := IndexedLeaf{Value: low.Leaf.Value, NextIndex: size, NextValue: value}
+	root = a.indexedRoot(a.indexedHash(updated), low.Index, low.Siblings)
+	api.AssertIsEqual(a.indexedRoot(vars.NewVariableFromInt(0), size, insertion.Siblings), root)
+	leaf := IndexedLeaf{Value: value, NextIndex: low.Leaf.NextIndex, NextValue: low.Leaf.NextValue}
+	return a.indexedRoot(a.indexedHash(leaf), size, insertion.Siblings)
+}
+
+// Inserts the values in order at the indices from size, and returns the new root and size.
+func (a *MerkleAPI) BatchInsert(root vars.Variable, size vars.Variable, values []vars.Variable, insertions []IndexedInsertion) (vars.Variable, vars.Variable) {
+	if len(values) != len(insertions) {
+		panic("the number of values and of insertions must be equal")
+	}
+	for i := range values {
+		root = a.Insert(root, size, values[i], insertions[i])
+		size = a.api.Add(size, vars.NewVariableFromInt(1))
+	}
+	return root, size
+}
+
+func (a *MerkleAPI) indexedHash(leaf IndexedLeaf) vars.Variable {
+	return poseidon.Hash(a.api, []vars.Variable{leaf.Value, leaf.NextIndex, leaf.NextValue})
+}
+
+// Returns the root of the node at the index with the siblings, where the index is range checked
+// to the depth.
+func (a *MerkleAPI) indexedRoot(node vars.Variable, index vars.Variable, siblings []vars.Variable) vars.Variable {
+	api := a.api
+	bits := api.ToBinaryLE(index, len(siblings))
+	for i, sibling := range siblings {
+		left := api.Select(bits[i], sibling, node)
+		right := api.Select(bits[i], node, sibling)
+		node = poseidon.Hash(api, []vars.Variable{left, right})
+	}
+	return node
+}
@@ -0,0 +1,119 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testIndexedCircuit struct {
+	Root       vars.Variable
+	Size       vars.Variable
+	Values     []vars.Variable
+	Insertions []IndexedInsertion
+	NewRoot    vars.Variable
+	Absent     vars.Variable
+	Low        IndexedLowLeaf
+}
+
+func (c *testIndexedCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	merkleAPI := NewAPI(api)
+	root, size := merkleAPI.BatchInsert(c.Root, c.Size, c.Values, c.Insertions)
+	api.AssertIsEqual(root, c.NewRoot)
+	api.AssertIsEqual(size, api.Add(c.Size, vars.NewVariableFromInt(len(c.Values))))
+	merkleAPI.VerifyNonMembership(root, c.Absent, c.Low)
+	return nil
+}
+
+func newIndexedCircuit(depth int, n int) *testIndexedCircuit {
+	c := &testIndexedCircuit{Values: make([]vars.Variable, n), Insertions: make([]IndexedInsertion, n), Low: NewIndexedLowLeaf(depth)}
+	for i := 0; i < n; i++ {
+		c.Insertions[i] = NewIndexedInsertion(depth)
+	}
+	return c
+}
+
+func TestIndexedTree(t *testing.T) {
+	tree := NewIndexedTree(3)
+	for _, v := range []int64{30, 10, 20} {
+		_, err := tree.Insert(big.NewInt(v))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 4, tree.Size())
+	_, err := tree.Insert(big.NewInt(20))
+	assert.Error(t, err)
+	low, err := tree.LowLeaf(big.NewInt(25))
+	assert.NoError(t, err)
+	assert.Equal(t, IndexedLeafValue{Value: big.NewInt(20), NextIndex: 1, NextValue: big.NewInt(30)}, low.Leaf)
+	low, err = tree.LowLeaf(big.NewInt(99))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, low.Index)
+	assert.Equal(t, big.NewInt(0), low.Leaf.NextValue)
+
+	// The root is that of the hashes of the leaves padded with zeros.
+	leaves := []IndexedLeafValue{
+		{Value: big.NewInt(0), NextIndex: 2, NextValue: big.NewInt(10)},
+		{Value: big.NewInt(30), NextIndex: 0, NextValue: big.NewInt(0)},
+		{Value: big.NewInt(10), NextIndex: 3, NextValue: big.NewInt(20)},
+		{Value: big.NewInt(20), NextIndex: 1, NextValue: big.NewInt(30)},
+	}
+	nodes := make([]*big.Int, 8)
+	for i := range nodes {
+		nodes[i] = big.NewInt(0)
+		if i < len(leaves) {
+			nodes[i] = leaves[i].Hash()
+		}
+	}
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = poseidon.HashValues(nodes[2*i], nodes[2*i+1])
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	assert.Equal(t, nodes[0], tree.Root())
+}
+
+func TestBatchInsert(t *testing.T) {
+	tree := NewIndexedTree(3)
+	_, err := tree.Insert(big.NewInt(50))
+	assert.NoError(t, err)
+	root, size := tree.Root(), tree.Size()
+	values := []int64{20, 70, 30}
+	assignment := newIndexedCircuit(3, len(values))
+	assignment.Root.Set(root)
+	assignment.Size = vars.NewVariableFromInt(size)
+	for i, v := range values {
+		assignment.Values[i] = vars.NewVariableFromInt(int(v))
+		insertion, err := tree.Insert(big.NewInt(v))
+		assert.NoError(t, err)
+		assert.NoError(t, assignment.Insertions[i].Set(insertion))
+	}
+	assignment.NewRoot.Set(tree.Root())
+	assignment.Absent = vars.NewVariableFromInt(60)
+	low, err := tree.LowLeaf(big.NewInt(60))
+	assert.NoError(t, err)
+	assert.NoError(t, assignment.Low.Set(low))
+	circuit := newIndexedCircuit(3, len(values))
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A value of the tree has no low leaf.
+	assignment.Absent = vars.NewVariableFromInt(70)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The low leaf of a value must be the greatest smaller value.
+	assignment.Absent = vars.NewVariableFromInt(80)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	assignment.Absent = vars.NewVariableFromInt(60)
+
+	// A value cannot be inserted twice.
+	assignment.Values[2] = vars.NewVariableFromInt(20)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
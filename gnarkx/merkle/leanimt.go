@@ -0,0 +1,138 @@
+package merkle
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A lean incremental Merkle tree, the tree of the groups of Semaphore v4 and of the LeanIMT of
+// zk-kit: the parent of two nodes is poseidon(left, right), and a node without a right sibling is
+// its own parent, so the depth is the number of bits of the number of leaves minus one, and the
+// root only depends on the leaves rather than on a maximum depth.
+// Reference: https://github.com/privacy-scaling-explorations/zk-kit/tree/main/packages/lean-imt
+type LeanTree struct {
+	levels [][]*big.Int
+}
+
+// Builds the lean incremental Merkle tree over the leaves.
+func NewLeanTree(leaves []*big.Int) (*LeanTree, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("a lean tree must have at least one leaf")
+	}
+	level := append([]*big.Int{}, leaves...)
+	levels := [][]*big.Int{level}
+	for len(level) > 1 {
+		next := make([]*big.Int, (len(level)+1)/2)
+		for i := 0; i < len(next); i++ {
+			next[i] = level[2*i]
+			if 2*i+1 < len(level) {
+				next[i] = poseidon.HashValues(level[2*i], level[2*i+1])
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &LeanTree{levels: levels}, nil
+}
+
+// Returns the root of the tree.
+func (t *LeanTree) Root() *big.Int {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Returns the depth of the tree.
+func (t *LeanTree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Returns the proof of the leaf at the index: the siblings from the leaf to the root, skipping the
+// levels where the node has no sibling, and the index whose bit i is set if the node is the right
+// child of the level of the sibling i.
+func (t *LeanTree) Proof(index int) (int, []*big.Int, error) {
+	if index < 0 || index >= len(t.levels[0]) {
+		return 0, nil, fmt.Errorf("index %d of a tree of %d leaves", index, len(t.levels[0]))
+	}
+	var siblings []*big.Int
+	path := 0
+	for level := 0; level < t.Depth(); level++ {
+		sibling := index ^ 1
+		if sibling < len(t.levels[level]) {
+			path |= (index & 1) << len(siblings)
+			siblings = append(siblings, t.levels[level][sibling])
+		}
+		index /= 2
+	}
+	return path, siblings, nil
+}
+
+// Returns the root of the leaf with the siblings and the index of a proof of a lean tree.
+func LeanRootValue(leaf *big.Int, index int, siblings []*big.Int) *big.Int {
+	node := leaf
+	for i, sibling := range siblings {
+		if index>>i&1 == 1 {
+			node = poseidon.HashValues(sibling, node)
+		} else {
+			node = poseidon.HashValues(node, sibling)
+		}
+	}
+	return node
+}
+
+// A proof of inclusion of a leaf in a lean incremental Merkle tree in the circuit, whose first
+// Length siblings are used.
+type LeanProof struct {
+	Length   vars.Variable
+	Index    vars.Variable
+	Siblings []vars.Variable
+}
+
+// Creates a new proof for trees of at most the depth.
+func NewLeanProof(maxDepth int) LeanProof {
+	siblings := make([]vars.Variable, maxDepth)
+	for i := 0; i < maxDepth; i++ {
+		siblings[i] = vars.NewVariable()
+	}
+	return LeanProof{Length: vars.NewVariable(), Index: vars.NewVariable(), Siblings: siblings}
+}
+
+// Sets the proof of the leaf at the index of the tree.
+func (p *LeanProof) Set(tree *LeanTree, index int) error {
+	path, siblings, err := tree.Proof(index)
+	if err != nil {
+		return err
+	}
+	if len(siblings) > len(p.Siblings) {
+		return fmt.Errorf("proof of %d siblings, expected at most %d", len(siblings), len(p.Siblings))
+	}
+	p.Length = vars.NewVariableFromInt(len(siblings))
+	p.Index = vars.NewVariableFromInt(path)
+	for i := 0; i < len(p.Siblings); i++ {
+		p.Siblings[i] = vars.NewVariableFromInt(0)
+		if i < len(siblings) {
+			p.Siblings[i].Set(siblings[i])
+		}
+	}
+	return nil
+}
+
+// Returns the root of the lean incremental Merkle tree that includes the leaf with the proof, as
+// the BinaryMerkleRoot of Semaphore, where the index is range checked to the number of siblings.
+func (a *MerkleAPI) LeanRoot(leaf vars.Variable, proof LeanProof) vars.Variable {
+	api := a.api
+	maxDepth := len(proof.Siblings)
+	api.AssertIsLessOrEqual(proof.Length, vars.NewVariableFromInt(maxDepth))
+	bits := api.ToBinaryLE(proof.Index, maxDepth)
+	node := leaf
+	root := api.Mul(api.IsZero(proof.Length).Value, leaf)
+	for i, sibling := range proof.Siblings {
+		left := api.Select(bits[i], sibling, node)
+		right := api.Select(bits[i], node, sibling)
+		node = poseidon.Hash(api, []vars.Variable{left, right})
+		isLength := api.IsZero(api.Sub(proof.Length, vars.NewVariableFromInt(i+1)))
+		root = api.Add(root, api.Mul(isLength.Value, node))
+	}
+	return root
+}
@@ -0,0 +1,84 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testLeanCircuit struct {
+	Root  vars.Variable
+	Leaf  vars.Variable
+	Proof LeanProof
+}
+
+func (c *testLeanCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(NewAPI(api).LeanRoot(c.Leaf, c.Proof), c.Root)
+	return nil
+}
+
+func newLeaves(n int) []*big.Int {
+	leaves := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		leaves[i] = big.NewInt(int64(100 + i))
+	}
+	return leaves
+}
+
+func TestLeanTree(t *testing.T) {
+	tree, err := NewLeanTree(newLeaves(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tree.Depth())
+	assert.Equal(t, big.NewInt(100), tree.Root())
+
+	// The fifth leaf of five is the left child of the root.
+	leaves := newLeaves(5)
+	tree, err = NewLeanTree(leaves)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tree.Depth())
+	left := poseidon.HashValues(poseidon.HashValues(leaves[0], leaves[1]), poseidon.HashValues(leaves[2], leaves[3]))
+	assert.Equal(t, poseidon.HashValues(left, leaves[4]), tree.Root())
+	index, siblings, err := tree.Proof(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+	assert.Equal(t, []*big.Int{left}, siblings)
+	for i := range leaves {
+		index, siblings, err := tree.Proof(i)
+		assert.NoError(t, err)
+		assert.Equal(t, tree.Root(), LeanRootValue(leaves[i], index, siblings))
+	}
+	_, _, err = tree.Proof(5)
+	assert.Error(t, err)
+}
+
+func TestLeanRoot(t *testing.T) {
+	leaves := newLeaves(5)
+	tree, err := NewLeanTree(leaves)
+	assert.NoError(t, err)
+	for _, index := range []int{2, 4} {
+		circuit := &testLeanCircuit{Proof: NewLeanProof(4)}
+		assignment := &testLeanCircuit{Root: vars.Variable{Value: tree.Root()}, Leaf: vars.Variable{Value: leaves[index]}, Proof: NewLeanProof(4)}
+		assert.NoError(t, assignment.Proof.Set(tree, index))
+		assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// The leaf must be at the index.
+		assignment.Leaf = vars.Variable{Value: leaves[1]}
+		assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	}
+
+	// The proof of a single leaf has no siblings.
+	tree, err = NewLeanTree(leaves[:1])
+	assert.NoError(t, err)
+	circuit := &testLeanCircuit{Proof: NewLeanProof(2)}
+	assignment := &testLeanCircuit{Root: vars.Variable{Value: leaves[0]}, Leaf: vars.Variable{Value: leaves[0]}, Proof: NewLeanProof(2)}
+	assert.NoError(t, assignment.Proof.Set(tree, 0))
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
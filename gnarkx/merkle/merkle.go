@@ -0,0 +1,19 @@
+// The API for Merkle trees over the Poseidon hash of the poseidon package, whose nodes are
+// elements of the scalar field of BN254, for the commitments of apps that are cheaper to open in
+// circuits than trees over byte-oriented hashes, and for binary Merkle trees of 32 byte nodes with
+// a pluggable hasher, such as the trees of contracts and of light clients.
+package merkle
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+// MerkleAPI is a wrapper around succinct.API that provides methods for Merkle trees.
+type MerkleAPI struct {
+	api builder.API
+}
+
+// Creates a new MerkleAPI.
+func NewAPI(api *builder.API) *MerkleAPI {
+	return &MerkleAPI{api: *api}
+}
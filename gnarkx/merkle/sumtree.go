@@ -0,0 +1,195 @@
+package merkle
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A node of a Merkle sum tree outside of circuits.
+type SumNodeValue struct {
+	Hash *big.Int
+	Sum  *big.Int
+}
+
+// A binary Merkle sum tree, the structure of proofs of liabilities: every node carries the hash of
+// its children and the sum of the amounts of the leaves below it, and every sum is an unsigned
+// integer of nbBits bits, so that no sum of an inclusion proof can be negative modulo the field.
+//
+// The leaf of an account is (poseidon(id, amount), amount), where id is an element of the scalar
+// field identifying the account, such as a hash of the user ID and a nonce, and the parent of two
+// nodes is (poseidon(leftHash, leftSum, rightHash, rightSum), leftSum + rightSum). The leaves are
+// right padded with (0, 0) to a power of 2.
+type SumTree struct {
+	levels [][]SumNodeValue
+}
+
+// Returns the leaf of an account.
+func SumLeafValue(id *big.Int, amount *big.Int) SumNodeValue {
+	return SumNodeValue{Hash: poseidon.HashValues(id, amount), Sum: new(big.Int).Set(amount)}
+}
+
+// Returns the parent of two nodes.
+func SumParentValue(left SumNodeValue, right SumNodeValue) SumNodeValue {
+	return SumNodeValue{
+		Hash: poseidon.HashValues(left.Hash, left.Sum, right.Hash, right.Sum),
+		Sum:  new(big.Int).Add(left.Sum, right.Sum),
+	}
+}
+
+// Builds the Merkle sum tree of depth levels over the leaves, whose sums have at most nbBits bits.
+func NewSumTree(depth int, nbBits int, leaves []SumNodeValue) (*SumTree, error) {
+	checkBits(nbBits)
+	if len(leaves) > 1<<depth {
+		return nil, fmt.Errorf("%d leaves, expected at most %d", len(leaves), 1<<depth)
+	}
+	level := make([]SumNodeValue, 1<<depth)
+	for i := 0; i < len(level); i++ {
+		level[i] = SumNodeValue{Hash: new(big.Int), Sum: new(big.Int)}
+		if i < len(leaves) {
+			if leaves[i].Sum.Sign() < 0 || leaves[i].Sum.BitLen() > nbBits {
+				return nil, fmt.Errorf("leaf %d has sum %s of more than %d bits", i, leaves[i].Sum, nbBits)
+			}
+			level[i] = leaves[i]
+		}
+	}
+	levels := [][]SumNodeValue{level}
+	for len(level) > 1 {
+		next := make([]SumNodeValue, len(level)/2)
+		for i := 0; i < len(next); i++ {
+			next[i] = SumParentValue(level[2*i], level[2*i+1])
+			if next[i].Sum.BitLen() > nbBits {
+				return nil, fmt.Errorf("sum %s of more than %d bits", next[i].Sum, nbBits)
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &SumTree{levels: levels}, nil
+}
+
+// Returns the root of the tree.
+func (t *SumTree) Root() SumNodeValue {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Returns the node at the index of the level, where the leaves are the level 0, such as the
+// children of the root at the level depth - 1.
+func (t *SumTree) Node(level int, index int) SumNodeValue {
+	return t.levels[level][index]
+}
+
+// Returns the siblings of the leaf at the index, from the leaf to the root.
+func (t *SumTree) Proof(index int) []SumNodeValue {
+	siblings := make([]SumNodeValue, len(t.levels)-1)
+	for i := 0; i < len(siblings); i++ {
+		siblings[i] = t.levels[i][index^1]
+		index /= 2
+	}
+	return siblings
+}
+
+// A node of a Merkle sum tree in the circuit.
+type SumNode struct {
+	Hash vars.Variable
+	Sum  vars.Variable
+}
+
+// Creates a new node.
+func NewSumNode() SumNode {
+	return SumNode{Hash: vars.NewVariable(), Sum: vars.NewVariable()}
+}
+
+// Sets the node.
+func (n *SumNode) Set(value SumNodeValue) {
+	n.Hash.Set(value.Hash)
+	n.Sum.Set(value.Sum)
+}
+
+// A proof of inclusion of a leaf at an index in a Merkle sum tree in the circuit.
+type SumProof struct {
+	Index    vars.Variable
+	Siblings []SumNode
+}
+
+// Creates a new proof for trees of the depth.
+func NewSumProof(depth int) SumProof {
+	siblings := make([]SumNode, depth)
+	for i := 0; i < depth; i++ {
+		siblings[i] = NewSumNode()
+	}
+	return SumProof{Index: vars.NewVariable(), Siblings: siblings}
+}
+
+// Sets the proof of the leaf at the index of the tree.
+func (p *SumProof) Set(tree *SumTree, index int) error {
+	siblings := tree.Proof(index)
+	if len(siblings) != len(p.Siblings) {
+		return fmt.Errorf("tree of depth %d, expected %d", len(siblings), len(p.Siblings))
+	}
+	p.Index = vars.NewVariableFromInt(index)
+	for i := range siblings {
+		p.Siblings[i].Set(siblings[i])
+	}
+	return nil
+}
+
+// Returns the leaf of an account, where the amount is range checked to nbBits bits.
+func (a *MerkleAPI) SumLeaf(id vars.Variable, amount vars.Variable, nbBits int) SumNode {
+	checkBits(nbBits)
+	a.api.ToBinaryLE(amount, nbBits)
+	return SumNode{Hash: poseidon.Hash(a.api, []vars.Variable{id, amount}), Sum: amount}
+}
+
+// Returns the parent of two nodes, whose sums are assumed to be range checked, where the sum is
+// range checked to nbBits bits.
+func (a *MerkleAPI) SumParent(left SumNode, right SumNode, nbBits int) SumNode {
+	api := a.api
+	sum := api.Add(left.Sum, right.Sum)
+	api.ToBinaryLE(sum, nbBits)
+	return SumNode{Hash: poseidon.Hash(api, []vars.Variable{left.Hash, left.Sum, right.Hash, right.Sum}), Sum: sum}
+}
+
+// Verifies that the leaf is at the index of the proof in the Merkle sum tree of the root, where
+// the sums of the siblings and of the nodes of the path are range checked to nbBits bits. The sum
+// of the leaf is assumed to be range checked, as by SumLeaf.
+func (a *MerkleAPI) VerifySumProof(root SumNode, leaf SumNode, proof SumProof, nbBits int) {
+	checkBits(nbBits)
+	api := a.api
+	bits := api.ToBinaryLE(proof.Index, len(proof.Siblings))
+	node := leaf
+	for i, sibling := range proof.Siblings {
+		api.ToBinaryLE(sibling.Sum, nbBits)
+		left := SumNode{Hash: api.Select(bits[i], sibling.Hash, node.Hash), Sum: api.Select(bits[i], sibling.Sum, node.Sum)}
+		right := SumNode{Hash: api.Select(bits[i], node.Hash, sibling.Hash), Sum: api.Select(bits[i], node.Sum, sibling.Sum)}
+		node = a.SumParent(left, right, nbBits)
+	}
+	api.AssertIsEqual(node.Hash, root.Hash)
+	api.AssertIsEqual(node.Sum, root.Sum)
+}
+
+// Returns the root of the Merkle sum tree over the leaves, whose number is a power of 2, where
+// sums are range checked to nbBits bits. The sums of the leaves are assumed to be range checked.
+func (a *MerkleAPI) SumRoot(leaves []SumNode, nbBits int) SumNode {
+	checkBits(nbBits)
+	if len(leaves) == 0 || len(leaves)&(len(leaves)-1) != 0 {
+		panic("the number of leaves must be a power of 2")
+	}
+	nodes := append([]SumNode{}, leaves...)
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = a.SumParent(nodes[2*i], nodes[2*i+1], nbBits)
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
+
+// Checks that sums of nbBits bits can be added without overflowing the scalar field.
+func checkBits(nbBits int) {
+	if nbBits < 1 || nbBits > 128 {
+		panic(fmt.Sprintf("sums of %d bits, expected between 1 and 128", nbBits))
+	}
+}
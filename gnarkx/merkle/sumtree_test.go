@@ -0,0 +1,67 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testSumCircuit struct {
+	Root   SumNode
+	ID     vars.Variable
+	Amount vars.Variable
+	Proof  SumProof
+}
+
+func (c *testSumCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	merkleAPI := NewAPI(api)
+	leaf := merkleAPI.SumLeaf(c.ID, c.Amount, 64)
+	merkleAPI.VerifySumProof(c.Root, leaf, c.Proof, 64)
+	return nil
+}
+
+func TestVerifySumProof(t *testing.T) {
+	amounts := []int64{5000, 0, 123456789, 42, 1e18}
+	leaves := make([]SumNodeValue, len(amounts))
+	for i := range amounts {
+		leaves[i] = SumLeafValue(big.NewInt(int64(1000+i)), big.NewInt(amounts[i]))
+	}
+	tree, err := NewSumTree(3, 64, leaves)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1e18+123456789+5042), tree.Root().Sum)
+
+	circuit := &testSumCircuit{Proof: NewSumProof(3)}
+	assignment := &testSumCircuit{Proof: NewSumProof(3)}
+	assignment.Root.Set(tree.Root())
+	assignment.ID = vars.NewVariableFromInt(1003)
+	assignment.Amount = vars.NewVariableFromInt(42)
+	assert.NoError(t, assignment.Proof.Set(tree, 3))
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The amount must be of the leaf.
+	assignment.Amount = vars.NewVariableFromInt(43)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A sibling with a negative sum hides liabilities even though the root is consistent.
+	modulus := ecc.BN254.ScalarField()
+	leaf := SumLeafValue(big.NewInt(1003), big.NewInt(42))
+	negative := SumNodeValue{Hash: big.NewInt(1), Sum: new(big.Int).Sub(modulus, big.NewInt(40))}
+	root := SumParentValue(negative, leaf)
+	root.Sum.Mod(root.Sum, modulus)
+	assert.Equal(t, big.NewInt(2), root.Sum)
+	circuit = &testSumCircuit{Proof: NewSumProof(1)}
+	assignment = &testSumCircuit{Proof: NewSumProof(1)}
+	assignment.Root.Set(root)
+	assignment.ID = vars.NewVariableFromInt(1003)
+	assignment.Amount = vars.NewVariableFromInt(42)
+	assignment.Proof.Index = vars.NewVariableFromInt(1)
+	assignment.Proof.Siblings[0].Set(negative)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,283 @@
+// A circuit template proving possession of a valid OpenID Connect id_token from a pinned issuer,
+// for onboarding flows like sign-in with Google that must not reveal the identity of the user.
+//
+// The circuit takes a 32 byte commitment and a uint64 unix timestamp as its input. It verifies the
+// signature of the token, checks that its iss and aud claims match the config, that it has not
+// expired at the timestamp, and that its nonce claim is the unpadded base64url encoding of the
+// commitment, so that the proof is bound to what the user committed to when signing in. It
+// outputs the SHA-256 digest of the sub claim and nothing else about the token.
+//
+// The signing key of the issuer is either pinned as a constant, or taken from a certificate that
+// is verified up to a pinned root with the x509 gadget. In the latter case the input is followed by
+// the timestamp encoded as YYYYMMDDHHMMSS, see x509.EncodeTime, for the validity of the chain.
+package oidc
+
+import (
+	"crypto"
+	gosha256 "crypto/sha256"
+	gox509 "crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/jwt"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	"github.com/succinctlabs/succinctx/gnarkx/x509"
+)
+
+// The length of the base64url encoding of a 32 byte commitment.
+const nonceLength = 43
+
+// Config describes the issuer and the tokens a circuit accepts.
+type Config struct {
+	// The expected iss and aud claims.
+	Issuer   string
+	Audience string
+
+	// The signing key of the issuer, an *rsa.PublicKey for RS256 or a P-256 *ecdsa.PublicKey for
+	// ES256, pinned as a constant of the circuit. It is ignored if Root is set.
+	Key crypto.PublicKey
+
+	// The root certificate authenticating the signing key, and the subject key types of the chain
+	// from the certificate of the signing key to the last intermediate.
+	Root                 *gox509.Certificate
+	Chain                []x509.KeyType
+	MaxCertificateLength int
+
+	// The maximum lengths of the signing input of the token and of the sub claim.
+	MaxTokenLength int
+	MaxSubLength   int
+}
+
+// Circuit verifies an id_token and outputs the digest of its subject.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Token jwt.Token
+	Iss   jwt.Claim
+	Aud   jwt.Claim
+	Sub   jwt.Claim
+	Nonce jwt.Claim
+	Exp   jwt.Claim
+
+	// The certificates of the chain authenticating the signing key, if the config has a root.
+	Certificates []x509.Certificate
+
+	config       *Config               `gnark:"-"`
+	token        string                `gnark:"-"`
+	certificates []*gox509.Certificate `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Returns the algorithm and signature length of a key type.
+func algorithmOf(key x509.KeyType) (jwt.Algorithm, int) {
+	if key.Algorithm == x509.RSA {
+		return jwt.RS256, key.Bits / 8
+	}
+	return jwt.ES256, 64
+}
+
+// Returns the type of the signing key of the config.
+func (c *Config) keyType() x509.KeyType {
+	if c.Root != nil {
+		if len(c.Chain) == 0 {
+			panic("the chain of the signing key must not be empty")
+		}
+		return c.Chain[0]
+	}
+	key, err := x509.ConstantPublicKey(c.Key)
+	if err != nil {
+		panic(err)
+	}
+	return key.Type
+}
+
+// Creates a new circuit for tokens described by the config.
+func NewCircuit(config *Config) *Circuit {
+	_, signatureLength := algorithmOf(config.keyType())
+	inputLength := 32 + 8
+	var certificates []x509.Certificate
+	if config.Root != nil {
+		inputLength += 8
+		for i := 0; i < len(config.Chain); i++ {
+			signer := rootKeyType(config.Root)
+			if i+1 < len(config.Chain) {
+				signer = config.Chain[i+1]
+			}
+			certificates = append(certificates, x509.NewCertificate(config.MaxCertificateLength, signer))
+		}
+	}
+	return &Circuit{
+		InputBytes:   vars.NewBytes(inputLength),
+		OutputBytes:  vars.NewBytes(32),
+		Token:        jwt.NewToken(config.MaxTokenLength, signatureLength),
+		Iss:          jwt.NewClaim(),
+		Aud:          jwt.NewClaim(),
+		Sub:          jwt.NewClaim(),
+		Nonce:        jwt.NewClaim(),
+		Exp:          jwt.NewClaim(),
+		Certificates: certificates,
+		config:       config,
+	}
+}
+
+func rootKeyType(root *gox509.Certificate) x509.KeyType {
+	r, err := x509.NewRoot(root)
+	if err != nil {
+		panic(err)
+	}
+	return r.Key.Type
+}
+
+// Sets the token that the next call to SetWitness assigns, with the chain of certificates from
+// the certificate of the signing key if the config has a root. The token is checked to have the
+// claims read by the circuit, but its signature is only checked by the circuit.
+func (c *Circuit) SetToken(token string, certificates []*gox509.Certificate) error {
+	if len(certificates) != len(c.Certificates) {
+		return fmt.Errorf("expected %d certificates, got %d", len(c.Certificates), len(certificates))
+	}
+	t := jwt.NewToken(len(c.Token.SigningInput), len(c.Token.Signature))
+	if err := t.Set(token); err != nil {
+		return err
+	}
+	payload, err := jwt.ParsePayload(token)
+	if err != nil {
+		return err
+	}
+	for _, name := range claimNames {
+		claim := jwt.NewClaim()
+		if err := claim.Set(payload, name); err != nil {
+			return err
+		}
+	}
+	sub, err := subject(payload)
+	if err != nil {
+		return err
+	}
+	if len(sub) > c.config.MaxSubLength {
+		return fmt.Errorf("sub length %d exceeds %d", len(sub), c.config.MaxSubLength)
+	}
+	c.token = token
+	c.certificates = certificates
+	return nil
+}
+
+// The claims read by the circuit.
+var claimNames = []string{"iss", "aud", "sub", "nonce", "exp"}
+
+// Returns the value of the sub claim of a decoded payload.
+func subject(payload []byte) ([]byte, error) {
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return []byte(claims.Sub), nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the token given to SetToken.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.token == "" {
+		panic("token must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	if err := c.Token.Set(c.token); err != nil {
+		panic(err)
+	}
+	payload, err := jwt.ParsePayload(c.token)
+	if err != nil {
+		panic(err)
+	}
+	claims := []*jwt.Claim{&c.Iss, &c.Aud, &c.Sub, &c.Nonce, &c.Exp}
+	for i, name := range claimNames {
+		if err := claims[i].Set(payload, name); err != nil {
+			panic(err)
+		}
+	}
+	for i := 0; i < len(c.certificates); i++ {
+		if err := c.Certificates[i].Set(c.certificates[i]); err != nil {
+			panic(err)
+		}
+	}
+	sub, err := subject(payload)
+	if err != nil {
+		panic(err)
+	}
+	digest := gosha256.Sum256(sub)
+	vars.SetBytes(&c.OutputBytes, digest[:])
+}
+
+// Returns the pinned signing key, or the key of the verified chain of certificates.
+func (c *Circuit) signingKey(api builder.API, inputReader *builder.InputReader) jwt.PublicKey {
+	keyType := c.config.keyType()
+	algorithm, _ := algorithmOf(keyType)
+	var key x509.PublicKey
+	if c.config.Root != nil {
+		root, err := x509.NewRoot(c.config.Root)
+		if err != nil {
+			panic(err)
+		}
+		now := inputReader.ReadUint64()
+		leaf := x509.VerifyChain(api, c.Certificates, c.config.Chain, root, now.Value)
+		key = leaf.PublicKey
+	} else {
+		var err error
+		if key, err = x509.ConstantPublicKey(c.config.Key); err != nil {
+			panic(err)
+		}
+	}
+	return jwt.PublicKey{Algorithm: algorithm, Modulus: key.Modulus, X: key.X, Y: key.Y}
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	commitment := inputReader.ReadBytes32()
+	now := inputReader.ReadUint64()
+
+	jwt.Verify(*api, c.Token, c.signingKey(*api, inputReader))
+	payload := jwt.DecodePayload(*api, c.Token)
+	payload.AssertNotExpired(c.Exp, now.Value)
+
+	// The string claims with a fixed value.
+	assertString := func(name string, claim jwt.Claim, expected []vars.Byte) {
+		api.AssertIsEqual(claim.Length, vars.NewVariableFromInt(len(expected)))
+		value := payload.String(name, claim, len(expected))
+		for i := 0; i < len(expected); i++ {
+			api.AssertIsEqualByte(value[i], expected[i])
+		}
+	}
+	assertString("iss", c.Iss, vars.NewBytesFrom([]byte(c.config.Issuer)))
+	assertString("aud", c.Aud, vars.NewBytesFrom([]byte(c.config.Audience)))
+	nonce := jwt.EncodeBase64URL(*api, commitment[:])
+	if len(nonce) != nonceLength {
+		panic(fmt.Sprintf("nonce length %d, expected %d", len(nonce), nonceLength))
+	}
+	assertString("nonce", c.Nonce, nonce)
+
+	sub := payload.String("sub", c.Sub, c.config.MaxSubLength)
+	digest := sha256.HashVariable(*api, sub, vars.U64{Value: c.Sub.Length}, len(sub))
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(digest)
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
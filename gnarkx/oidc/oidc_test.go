@@ -0,0 +1,143 @@
+package oidc
+
+import (
+	"crypto"
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	gosha256 "crypto/sha256"
+	gox509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/x509"
+)
+
+const (
+	issuer   = "https://accounts.example.com"
+	audience = "1234.apps.example.com"
+	sub      = "110169484474386276334"
+)
+
+func newToken(t *testing.T, key crypto.Signer, commitment [32]byte) string {
+	algorithm := "RS256"
+	if _, ok := key.(*goecdsa.PrivateKey); ok {
+		algorithm = "ES256"
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + algorithm + `","typ":"JWT"}`))
+	payload := fmt.Sprintf(`{"iss":"%s","aud":"%s","sub":"%s","nonce":"%s","exp":1700003600,"iat":1700000000}`,
+		issuer, audience, sub, base64.RawURLEncoding.EncodeToString(commitment[:]))
+	input := header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	digest := gosha256.Sum256([]byte(input))
+	var signature []byte
+	switch k := key.(type) {
+	case *gorsa.PrivateKey:
+		var err error
+		signature, err = gorsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case *goecdsa.PrivateKey:
+		r, s, err := goecdsa.Sign(rand.Reader, k, digest[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func assertSolved(t *testing.T, circuit *Circuit, input []byte) {
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(input)
+	digest := gosha256.Sum256([]byte(sub))
+	for i := 0; i < 32; i++ {
+		assert.Equal(t, digest[i], circuit.OutputBytes[i].GetValueUnsafe())
+	}
+	err := test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+}
+
+func assertNotSolved(t *testing.T, circuit *Circuit, input []byte) {
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(input)
+	err := test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+func TestPinnedKey(t *testing.T) {
+	key, err := gorsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	commitment := gosha256.Sum256([]byte("ephemeral key"))
+	config := &Config{Issuer: issuer, Audience: audience, Key: key.Public(), MaxTokenLength: 384, MaxSubLength: 32}
+
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetToken(newToken(t, key, commitment), nil))
+	input := binary.BigEndian.AppendUint64(commitment[:], 1700000000)
+	assertSolved(t, circuit, input)
+
+	// The nonce must match the commitment of the input.
+	otherCommitment := gosha256.Sum256([]byte("other key"))
+	assertNotSolved(t, circuit, binary.BigEndian.AppendUint64(otherCommitment[:], 1700000000))
+
+	// The token must not be expired.
+	assertNotSolved(t, circuit, binary.BigEndian.AppendUint64(commitment[:], 1700003600))
+
+	// The token must be issued for the audience.
+	config.Audience = "5678.apps.example.com"
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetToken(newToken(t, key, commitment), nil))
+	assertNotSolved(t, circuit, input)
+}
+
+func TestCertifiedKey(t *testing.T) {
+	rootKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	signingKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &gox509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Example Root"},
+		NotBefore:             time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := gox509.CreateCertificate(rand.Reader, template, template, rootKey.Public(), rootKey)
+	assert.NoError(t, err)
+	root, err := gox509.ParseCertificate(der)
+	assert.NoError(t, err)
+	template.Subject = pkix.Name{CommonName: "Example Signing Key"}
+	template.IsCA = false
+	der, err = gox509.CreateCertificate(rand.Reader, template, root, signingKey.Public(), rootKey)
+	assert.NoError(t, err)
+	cert, err := gox509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	config := &Config{
+		Issuer:               issuer,
+		Audience:             audience,
+		Root:                 root,
+		Chain:                []x509.KeyType{{Algorithm: x509.ECDSAP256}},
+		MaxCertificateLength: 384,
+		MaxTokenLength:       384,
+		MaxSubLength:         32,
+	}
+	commitment := gosha256.Sum256([]byte("ephemeral key"))
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetToken(newToken(t, signingKey, commitment), []*gox509.Certificate{cert}))
+	input := binary.BigEndian.AppendUint64(commitment[:], 1700000000)
+	now := x509.EncodeTime(time.Unix(1700000000, 0))
+	assertSolved(t, circuit, binary.BigEndian.AppendUint64(input, now))
+
+	// The certificate of the signing key must be valid.
+	assertNotSolved(t, circuit, binary.BigEndian.AppendUint64(input, 20300101000001))
+}
@@ -0,0 +1,149 @@
+// Profiling of the constraints of circuits by named regions, so that the gadgets worth optimizing
+// can be found without bisecting circuits by hand. A circuit marks its regions with StartRegion and
+// EndRegion and is compiled with Compile, which reports the constraints of each region. Outside of
+// Compile, regions are ignored, so circuits may keep them.
+package profile
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+// ProfileAPI is a wrapper around succinct.API that provides methods for profiling regions.
+type ProfileAPI struct {
+	api builder.API
+}
+
+// Creates a new ProfileAPI.
+func NewAPI(api *builder.API) *ProfileAPI {
+	return &ProfileAPI{api: *api}
+}
+
+// A Region is a named part of a circuit and the number of constraints it adds.
+type Region struct {
+	Name string
+
+	// The number of regions the region is nested in.
+	Depth int
+
+	// The constraints added between the start and the end of the region, including those of its
+	// nested regions. Constraints deferred to the end of the compilation, such as the checks of
+	// lookup tables, are not part of any region.
+	NbConstraints int
+}
+
+// A Report is the number of constraints of a circuit and of its regions, in the order they start.
+type Report struct {
+	NbConstraints int
+	Regions       []Region
+}
+
+// The regions of a circuit being compiled by Compile.
+type session struct {
+	cs      constraint.ConstraintSystem
+	regions []Region
+
+	// The indices of the regions that are started but not ended, and their constraints at start.
+	open   []int
+	starts []int
+}
+
+// The sessions of the compilations in progress, by the builders they compile with.
+var (
+	sessionsMutex sync.Mutex
+	sessions      = map[frontend.API]*session{}
+)
+
+// Returns the session of the compilation of the API, or nil if it is not compiled by Compile.
+func (a *ProfileAPI) session() *session {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	return sessions[a.api.FrontendAPI()]
+}
+
+// Starts a region, which ends at the matching EndRegion. Regions may be nested.
+func (a *ProfileAPI) StartRegion(name string) {
+	s := a.session()
+	if s == nil {
+		return
+	}
+	s.open = append(s.open, len(s.regions))
+	s.starts = append(s.starts, s.cs.GetNbConstraints())
+	s.regions = append(s.regions, Region{Name: name, Depth: len(s.open) - 1})
+}
+
+// Ends the innermost region that is started.
+func (a *ProfileAPI) EndRegion() {
+	s := a.session()
+	if s == nil {
+		return
+	}
+	if len(s.open) == 0 {
+		panic("ending a region that is not started")
+	}
+	last := len(s.open) - 1
+	s.regions[s.open[last]].NbConstraints = s.cs.GetNbConstraints() - s.starts[last]
+	s.open, s.starts = s.open[:last], s.starts[:last]
+}
+
+// Compiles the circuit to an R1CS over BN254 and reports the constraints of its regions.
+func Compile(circuit frontend.Circuit) (*Report, error) {
+	var key frontend.API
+	newBuilder := func(field *big.Int, config frontend.CompileConfig) (frontend.Builder, error) {
+		b, err := r1cs.NewBuilder(field, config)
+		if err != nil {
+			return nil, err
+		}
+		// The constraint system of the builder is the one it builds, so it counts the constraints
+		// added so far.
+		cs, err := b.Compile()
+		if err != nil {
+			return nil, err
+		}
+		key = b
+		sessionsMutex.Lock()
+		sessions[key] = &session{cs: cs}
+		sessionsMutex.Unlock()
+		return b, nil
+	}
+	defer func() {
+		sessionsMutex.Lock()
+		delete(sessions, key)
+		sessionsMutex.Unlock()
+	}()
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), newBuilder, circuit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile circuit: %w", err)
+	}
+	sessionsMutex.Lock()
+	s := sessions[key]
+	sessionsMutex.Unlock()
+	if len(s.open) > 0 {
+		return nil, fmt.Errorf("region %q is not ended", s.regions[s.open[len(s.open)-1]].Name)
+	}
+	return &Report{NbConstraints: ccs.GetNbConstraints(), Regions: s.regions}, nil
+}
+
+// Returns the constraints of the circuit and of each region with its share of the circuit, one
+// region per line, indented by depth.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%12d  %6.2f%%  total\n", r.NbConstraints, 100.0)
+	for _, region := range r.Regions {
+		share := 0.0
+		if r.NbConstraints > 0 {
+			share = 100 * float64(region.NbConstraints) / float64(r.NbConstraints)
+		}
+		fmt.Fprintf(&sb, "%12d  %6.2f%%  %s%s\n", region.NbConstraints, share, strings.Repeat("  ", region.Depth+1), region.Name)
+	}
+	return sb.String()
+}
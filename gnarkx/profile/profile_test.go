@@ -0,0 +1,76 @@
+package profile
+
+import (
+	gosha256 "crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	In      [64]vars.Byte
+	Hash    [32]vars.Byte
+	Unended bool `gnark:"-"`
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	profile := NewAPI(api)
+	profile.StartRegion("hash")
+	profile.StartRegion("first")
+	hash := sha256.Hash(*api, c.In[:32])
+	profile.EndRegion()
+	profile.StartRegion("second")
+	hash = sha256.Hash(*api, append(hash[:], c.In[32:]...))
+	profile.EndRegion()
+	profile.EndRegion()
+	profile.StartRegion("check")
+	api.AssertIsEqualBytes32(hash, c.Hash)
+	if !c.Unended {
+		profile.EndRegion()
+	}
+	return nil
+}
+
+func TestCompile(t *testing.T) {
+	report, err := Compile(&testCircuit{})
+	assert.NoError(t, err)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testCircuit{})
+	assert.NoError(t, err)
+	assert.Equal(t, ccs.GetNbConstraints(), report.NbConstraints)
+
+	assert.Len(t, report.Regions, 4)
+	hash, first, second, check := report.Regions[0], report.Regions[1], report.Regions[2], report.Regions[3]
+	assert.Equal(t, []string{"hash", "first", "second", "check"}, []string{hash.Name, first.Name, second.Name, check.Name})
+	assert.Equal(t, []int{0, 1, 1, 0}, []int{hash.Depth, first.Depth, second.Depth, check.Depth})
+	assert.Equal(t, hash.NbConstraints, first.NbConstraints+second.NbConstraints)
+	// The second hash compresses two chunks and the first hash one.
+	assert.Greater(t, second.NbConstraints, first.NbConstraints)
+	assert.Positive(t, check.NbConstraints)
+	assert.LessOrEqual(t, hash.NbConstraints+check.NbConstraints, report.NbConstraints)
+	assert.Contains(t, report.String(), "    first\n")
+
+	_, err = Compile(&testCircuit{Unended: true})
+	assert.Error(t, err)
+}
+
+func TestOutsideCompile(t *testing.T) {
+	// Regions are ignored when the circuit is not compiled by Compile.
+	var assignment testCircuit
+	var in [64]byte
+	for i := range in {
+		in[i] = byte(i)
+		assignment.In[i] = vars.NewByte()
+		assignment.In[i].Set(in[i])
+	}
+	first := gosha256.Sum256(in[:32])
+	vars.SetBytes32(&assignment.Hash, gosha256.Sum256(append(first[:], in[32:]...)))
+	assert.NoError(t, test.IsSolved(&testCircuit{Unended: true}, &assignment, ecc.BN254.ScalarField()))
+}
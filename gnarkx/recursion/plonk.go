@@ -0,0 +1,314 @@
+package recursion
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/plonk"
+	plonkbn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of claimed values of the batched opening of a PLONK proof: the quotient, the
+// linearized polynomial, l, r, o, s1 and s2, all at zeta.
+const nbClaimedValues = 7
+
+// A BN254 PLONK proof as a circuit variable. The values are elements of the scalar field of BN254,
+// which is the native field of the outer circuit.
+type PlonkProof struct {
+	// The commitments to l, r and o, to the permutation polynomial z and to the three parts of the
+	// quotient polynomial.
+	LRO [3]sw_bn254.G1Affine
+	Z   sw_bn254.G1Affine
+	H   [3]sw_bn254.G1Affine
+
+	// The batched opening at zeta.
+	BatchedH      sw_bn254.G1Affine
+	ClaimedValues [nbClaimedValues]vars.Variable
+
+	// The opening of z at zeta times the generator of the domain.
+	ZShiftedH     sw_bn254.G1Affine
+	ZShiftedValue vars.Variable
+}
+
+// A BN254 PLONK verifying key, whose values are constants of the outer circuit.
+type PlonkVerifyingKey struct {
+	vk *plonkbn254.VerifyingKey
+}
+
+// Returns the verifying key of a BN254 PLONK circuit as constants for the outer circuit. Circuits
+// with BSB22 commitments, as made by api.Commit or the lookups and range checks of gnark, are not
+// supported.
+func ValueOfPlonkVerifyingKey(vk plonk.VerifyingKey) (PlonkVerifyingKey, error) {
+	tvk, ok := vk.(*plonkbn254.VerifyingKey)
+	if !ok {
+		return PlonkVerifyingKey{}, fmt.Errorf("expected a BN254 verifying key, got %T", vk)
+	}
+	if len(tvk.Qcp) != 0 {
+		return PlonkVerifyingKey{}, fmt.Errorf("verifying keys with %d commitments are not supported", len(tvk.Qcp))
+	}
+	return PlonkVerifyingKey{vk: tvk}, nil
+}
+
+// Returns the number of public inputs of the circuit of a PLONK verifying key.
+func (vk *PlonkVerifyingKey) NbPublicInputs() int {
+	return int(vk.vk.NbPublicVariables)
+}
+
+// Returns a BN254 PLONK proof as the witness of the outer circuit.
+func ValueOfPlonkProof(proof plonk.Proof) (PlonkProof, error) {
+	tproof, ok := proof.(*plonkbn254.Proof)
+	if !ok {
+		return PlonkProof{}, fmt.Errorf("expected a BN254 proof, got %T", proof)
+	}
+	if len(tproof.Bsb22Commitments) != 0 || len(tproof.BatchedProof.ClaimedValues) != nbClaimedValues {
+		return PlonkProof{}, fmt.Errorf("proofs with commitments are not supported")
+	}
+	var result PlonkProof
+	for i := 0; i < 3; i++ {
+		result.LRO[i] = sw_bn254.NewG1Affine(tproof.LRO[i])
+		result.H[i] = sw_bn254.NewG1Affine(tproof.H[i])
+	}
+	result.Z = sw_bn254.NewG1Affine(tproof.Z)
+	result.BatchedH = sw_bn254.NewG1Affine(tproof.BatchedProof.H)
+	for i := 0; i < nbClaimedValues; i++ {
+		result.ClaimedValues[i].Set(toBigInt(&tproof.BatchedProof.ClaimedValues[i]))
+	}
+	result.ZShiftedH = sw_bn254.NewG1Affine(tproof.ZShiftedOpening.H)
+	result.ZShiftedValue.Set(toBigInt(&tproof.ZShiftedOpening.ClaimedValue))
+	return result, nil
+}
+
+func toBigInt(e *fr.Element) *big.Int {
+	return e.BigInt(new(big.Int))
+}
+
+// Asserts that a PLONK proof is valid for the verifying key and the public inputs, in the order of
+// the inner circuit. This mirrors the verifier of gnark, including its Fiat-Shamir transcript over
+// SHA-256, except that the two openings are checked with a pairing each since the circuit has no
+// randomness to batch them with. The outer circuit must be over BN254, so that the scalars of the
+// proof are native.
+func (a *RecursionAPI) AssertPlonkProof(vk *PlonkVerifyingKey, proof *PlonkProof, public []vars.Variable) {
+	fapi := a.api.FrontendAPI()
+	if fapi.Compiler().Field().Cmp(ecc.BN254.ScalarField()) != 0 {
+		panic("PLONK proofs can only be verified in circuits over BN254")
+	}
+	if len(public) != vk.NbPublicInputs() {
+		panic(fmt.Sprintf("expected %d public inputs, got %d", vk.NbPublicInputs(), len(public)))
+	}
+	key := vk.vk
+	for _, p := range []*sw_bn254.G1Affine{&proof.LRO[0], &proof.LRO[1], &proof.LRO[2], &proof.Z, &proof.H[0], &proof.H[1], &proof.H[2], &proof.BatchedH, &proof.ZShiftedH} {
+		a.curve.AssertIsOnCurve(p)
+	}
+
+	// The challenges gamma, beta, alpha and zeta, each bound to the previous one.
+	var binding []vars.Byte
+	for _, p := range []bn254.G1Affine{key.S[0], key.S[1], key.S[2], key.Ql, key.Qr, key.Qm, key.Qo, key.Qk} {
+		binding = append(binding, constantPointBytes(p)...)
+	}
+	for i := range public {
+		binding = append(binding, a.scalarBytes(public[i].Value)...)
+	}
+	for i := range proof.LRO {
+		binding = append(binding, a.pointBytes(&proof.LRO[i])...)
+	}
+	gammaBytes := a.challenge("gamma", nil, binding)
+	betaBytes := a.challenge("beta", gammaBytes, nil)
+	alphaBytes := a.challenge("alpha", betaBytes, a.pointBytes(&proof.Z))
+	binding = nil
+	for i := range proof.H {
+		binding = append(binding, a.pointBytes(&proof.H[i])...)
+	}
+	zetaBytes := a.challenge("zeta", alphaBytes, binding)
+	gamma, beta, alpha, zeta := a.toScalarValue(gammaBytes), a.toScalarValue(betaBytes), a.toScalarValue(alphaBytes), a.toScalarValue(zetaBytes)
+
+	// The public inputs at zeta, with L_{i+1} = w * L_i * (zeta - w^i) / (zeta - w^{i+1}) from
+	// L_0 = (zeta^n - 1) / (n * (zeta - 1)).
+	generator := toBigInt(&key.Generator)
+	zetaPowerM := pow(fapi, zeta, key.Size)
+	zzeta := fapi.Sub(zetaPowerM, 1)
+	lagrangeOne := fapi.Mul(fapi.Div(zzeta, fapi.Sub(zeta, 1)), toBigInt(&key.SizeInv))
+	lagrange := lagrangeOne
+	pi := frontend.Variable(0)
+	wPowI := big.NewInt(1)
+	for i := range public {
+		pi = fapi.Add(pi, fapi.Mul(lagrange, public[i].Value))
+		if i+1 != len(public) {
+			den := fapi.Sub(zeta, new(big.Int).Set(wPowI))
+			wPowI.Mul(wPowI, generator).Mod(wPowI, fr.Modulus())
+			lagrange = fapi.Div(fapi.Mul(lagrange, generator, den), fapi.Sub(zeta, new(big.Int).Set(wPowI)))
+		}
+	}
+
+	// The quotient at zeta must be as claimed.
+	claimedQuotient, linearizedPolynomialZeta := proof.ClaimedValues[0].Value, proof.ClaimedValues[1].Value
+	l, r, o := proof.ClaimedValues[2].Value, proof.ClaimedValues[3].Value, proof.ClaimedValues[4].Value
+	s1, s2 := proof.ClaimedValues[5].Value, proof.ClaimedValues[6].Value
+	zu := proof.ZShiftedValue.Value
+	v := fapi.Add(fapi.Mul(beta, s1), l, gamma)
+	w := fapi.Add(fapi.Mul(beta, s2), r, gamma)
+	permutation := fapi.Mul(v, w, fapi.Add(o, gamma), alpha, zu)
+	alphaSquareLagrange := fapi.Mul(lagrangeOne, alpha, alpha)
+	quotient := fapi.Sub(fapi.Add(linearizedPolynomialZeta, pi, permutation), alphaSquareLagrange)
+	fapi.AssertIsEqual(fapi.Mul(claimedQuotient, zzeta), quotient)
+
+	// The commitment to the quotient, h1 + zeta^(n+2) * h2 + zeta^(2(n+2)) * h3.
+	zetaMPlusTwo := a.ToScalar(vars.Variable{Value: pow(fapi, zeta, key.Size+2)})
+	foldedH := a.curve.AddUnified(a.curve.ScalarMul(&proof.H[2], zetaMPlusTwo), &proof.H[1])
+	foldedH = a.curve.AddUnified(a.curve.ScalarMul(foldedH, zetaMPlusTwo), &proof.H[0])
+
+	// The commitment to the linearized polynomial.
+	coset := toBigInt(&key.CosetShift)
+	cosetSquare := new(big.Int).Mul(coset, coset)
+	betaZeta := fapi.Mul(beta, zeta)
+	sPermutation := fapi.Mul(zu, beta, v, w, alpha)
+	zPermutation := fapi.Mul(
+		fapi.Add(betaZeta, l, gamma),
+		fapi.Add(fapi.Mul(betaZeta, coset), r, gamma),
+		fapi.Add(fapi.Mul(betaZeta, cosetSquare), o, gamma),
+	)
+	zPermutation = fapi.Add(fapi.Neg(fapi.Mul(zPermutation, alpha)), alphaSquareLagrange)
+	points := []bn254.G1Affine{key.Ql, key.Qr, key.Qm, key.Qo, key.S[2]}
+	scalars := []frontend.Variable{l, r, fapi.Mul(l, r), o, sPermutation}
+	linearizedPolynomialDigest := a.constantPoint(key.Qk)
+	for i := range points {
+		term := a.curve.ScalarMul(a.constantPoint(points[i]), a.ToScalar(vars.Variable{Value: scalars[i]}))
+		linearizedPolynomialDigest = a.curve.AddUnified(linearizedPolynomialDigest, term)
+	}
+	term := a.curve.ScalarMul(&proof.Z, a.ToScalar(vars.Variable{Value: zPermutation}))
+	linearizedPolynomialDigest = a.curve.AddUnified(linearizedPolynomialDigest, term)
+
+	// The batched opening at zeta is folded with powers of a challenge bound to the digests and the
+	// claimed values, as kzg.FoldProof.
+	digests := []*sw_bn254.G1Affine{foldedH, linearizedPolynomialDigest, &proof.LRO[0], &proof.LRO[1], &proof.LRO[2], a.constantPoint(key.S[0]), a.constantPoint(key.S[1])}
+	binding = a.scalarBytes(zeta)
+	binding = append(binding, a.pointBytes(foldedH)...)
+	binding = append(binding, a.pointBytes(linearizedPolynomialDigest)...)
+	for i := range proof.LRO {
+		binding = append(binding, a.pointBytes(&proof.LRO[i])...)
+	}
+	binding = append(binding, constantPointBytes(key.S[0])...)
+	binding = append(binding, constantPointBytes(key.S[1])...)
+	for i := range proof.ClaimedValues {
+		binding = append(binding, a.scalarBytes(proof.ClaimedValues[i].Value)...)
+	}
+	binding = append(binding, a.scalarBytes(zu)...)
+	foldingGamma := a.toScalarValue(a.challenge("gamma", nil, binding))
+	foldedDigest := digests[0]
+	foldedValue := proof.ClaimedValues[0].Value
+	gammaI := frontend.Variable(1)
+	for i := 1; i < len(digests); i++ {
+		gammaI = fapi.Mul(gammaI, foldingGamma)
+		foldedDigest = a.curve.AddUnified(foldedDigest, a.curve.ScalarMul(digests[i], a.ToScalar(vars.Variable{Value: gammaI})))
+		foldedValue = fapi.Add(foldedValue, fapi.Mul(gammaI, proof.ClaimedValues[i].Value))
+	}
+
+	a.assertOpening(key, foldedDigest, zeta, foldedValue, &proof.BatchedH)
+	a.assertOpening(key, &proof.Z, fapi.Mul(zeta, generator), zu, &proof.ZShiftedH)
+}
+
+// Asserts that the KZG opening of the digest at the point has the value, with the commitment to
+// the quotient: e([f(τ) - f(a) + a * q(τ)]G₁, G₂) * e([-q(τ)]G₁, [τ]G₂) == 1.
+func (a *RecursionAPI) assertOpening(key *plonkbn254.VerifyingKey, digest *sw_bn254.G1Affine, point frontend.Variable, value frontend.Variable, quotient *sw_bn254.G1Affine) {
+	valueG1 := a.curve.ScalarMul(a.constantPoint(key.Kzg.G1), a.ToScalar(vars.Variable{Value: value}))
+	total := a.curve.AddUnified(digest, a.curve.Neg(valueG1))
+	total = a.curve.AddUnified(total, a.curve.ScalarMul(quotient, a.ToScalar(vars.Variable{Value: point})))
+	g2 := sw_bn254.NewG2Affine(key.Kzg.G2[0])
+	tauG2 := sw_bn254.NewG2Affine(key.Kzg.G2[1])
+	if err := a.pairing.PairingCheck([]*sw_bn254.G1Affine{total, a.curve.Neg(quotient)}, []*sw_bn254.G2Affine{&g2, &tauG2}); err != nil {
+		panic(fmt.Sprintf("pairing check: %s", err))
+	}
+}
+
+// Computes the challenge of the transcript of gnark, the SHA-256 hash of its name, the previous
+// challenge if any and the values bound to it.
+func (a *RecursionAPI) challenge(name string, previous []vars.Byte, binding []vars.Byte) []vars.Byte {
+	in := vars.NewBytesFrom([]byte(name))
+	in = append(in, previous...)
+	in = append(in, binding...)
+	out := sha256.Hash(a.api, in)
+	return out[:]
+}
+
+// Returns the big-endian bytes as a scalar, reduced modulo the native field as fr.Element.SetBytes.
+func (a *RecursionAPI) toScalarValue(bytes []vars.Byte) frontend.Variable {
+	fapi := a.api.FrontendAPI()
+	result := frontend.Variable(0)
+	for i := range bytes {
+		result = fapi.Add(fapi.Mul(result, 256), bytes[i].Value.Value)
+	}
+	return result
+}
+
+// Returns the 32 big-endian bytes of a scalar, as fr.Element.Marshal.
+func (a *RecursionAPI) scalarBytes(i1 frontend.Variable) []vars.Byte {
+	bits := a.api.ToBinaryLE(vars.Variable{Value: i1}, a.api.FrontendAPI().Compiler().FieldBitLen())
+	return toBytesBE(a.api.FrontendAPI(), toFrontendVariables(bits), 32)
+}
+
+// Returns the 64 bytes of the coordinates of a point, as bn254.G1Affine.Marshal for the points
+// other than the point at infinity.
+func (a *RecursionAPI) pointBytes(p *sw_bn254.G1Affine) []vars.Byte {
+	var result []vars.Byte
+	for _, x := range []*emulated.Element[emulated.BN254Fp]{&p.X, &p.Y} {
+		reduced := a.fp.Reduce(x)
+		a.fp.AssertIsInRange(reduced)
+		result = append(result, toBytesBE(a.api.FrontendAPI(), a.fp.ToBits(reduced), 32)...)
+	}
+	return result
+}
+
+// Returns the bytes of a constant point, as bn254.G1Affine.Marshal.
+func constantPointBytes(p bn254.G1Affine) []vars.Byte {
+	return vars.NewBytesFrom(p.Marshal())
+}
+
+// Returns a constant point of the circuit.
+func (a *RecursionAPI) constantPoint(p bn254.G1Affine) *sw_bn254.G1Affine {
+	result := sw_bn254.NewG1Affine(p)
+	return &result
+}
+
+// Packs the first 8 * n little-endian bits into n big-endian bytes.
+func toBytesBE(api frontend.API, bits []frontend.Variable, n int) []vars.Byte {
+	result := make([]vars.Byte, n)
+	for i := 0; i < n; i++ {
+		value := frontend.Variable(0)
+		for j := 7; j >= 0; j-- {
+			bit := frontend.Variable(0)
+			if 8*i+j < len(bits) {
+				bit = bits[8*i+j]
+			}
+			value = api.Add(api.Mul(value, 2), bit)
+		}
+		result[n-1-i] = vars.Byte{Value: vars.Variable{Value: value}}
+	}
+	return result
+}
+
+func toFrontendVariables(bits []vars.Bool) []frontend.Variable {
+	result := make([]frontend.Variable, len(bits))
+	for i := range bits {
+		result[i] = bits[i].Value.Value
+	}
+	return result
+}
+
+// Computes i1^e by square and multiply.
+func pow(api frontend.API, i1 frontend.Variable, e uint64) frontend.Variable {
+	result := frontend.Variable(1)
+	for i := 63; i >= 0; i-- {
+		result = api.Mul(result, result)
+		if e>>uint(i)&1 == 1 {
+			result = api.Mul(result, i1)
+		}
+	}
+	return result
+}
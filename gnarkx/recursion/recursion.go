@@ -0,0 +1,119 @@
+// The API for verifying BN254 Groth16 and PLONK proofs inside circuits with emulated pairings, so
+// that proofs of other circuits, such as the map proofs of the mapreduce package, can be
+// aggregated.
+//
+// The verifying key of the inner circuit is usually a constant of the outer circuit, held in a
+// field tagged with `gnark:"-"`, so that only proofs of the expected circuit are accepted, while
+// the proofs are part of the witness.
+package recursion
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A BN254 Groth16 proof as a circuit variable.
+type Proof = stdgroth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+
+// A BN254 Groth16 verifying key as a circuit variable.
+type VerifyingKey = stdgroth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]
+
+// Returns the verifying key of a BN254 circuit as constants for the outer circuit.
+func ValueOfVerifyingKey(vk groth16.VerifyingKey) (VerifyingKey, error) {
+	result, err := stdgroth16.ValueOfVerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](vk)
+	if err != nil {
+		return VerifyingKey{}, fmt.Errorf("failed to convert verifying key: %w", err)
+	}
+	return result, nil
+}
+
+// Returns a BN254 Groth16 proof as the witness of the outer circuit.
+func ValueOfProof(proof groth16.Proof) (Proof, error) {
+	result, err := stdgroth16.ValueOfProof[sw_bn254.G1Affine, sw_bn254.G2Affine](proof)
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to convert proof: %w", err)
+	}
+	return result, nil
+}
+
+// Returns the number of public inputs of the circuit of a verifying key.
+func NbPublicInputs(vk *VerifyingKey) int {
+	return len(vk.G1.K) - 1
+}
+
+// RecursionAPI is a wrapper around succinct.API that provides methods for verifying proofs.
+type RecursionAPI struct {
+	api     builder.API
+	curve   *sw_emulated.Curve[emulated.BN254Fp, emulated.BN254Fr]
+	pairing *sw_bn254.Pairing
+	fp      *emulated.Field[emulated.BN254Fp]
+	fr      *emulated.Field[emulated.BN254Fr]
+}
+
+// Creates a new RecursionAPI.
+func NewAPI(api *builder.API) *RecursionAPI {
+	fapi := api.FrontendAPI()
+	curve, err := sw_emulated.New[emulated.BN254Fp, emulated.BN254Fr](fapi, sw_emulated.GetBN254Params())
+	if err != nil {
+		panic(err)
+	}
+	pairing, err := sw_bn254.NewPairing(fapi)
+	if err != nil {
+		panic(err)
+	}
+	fp, err := emulated.NewField[emulated.BN254Fp](fapi)
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[emulated.BN254Fr](fapi)
+	if err != nil {
+		panic(err)
+	}
+	return &RecursionAPI{api: *api, curve: curve, pairing: pairing, fp: fp, fr: fr}
+}
+
+// Returns a native variable as an emulated scalar, which requires the native field to be at most
+// the scalar field of BN254, as when the outer circuit is itself over BN254.
+func (a *RecursionAPI) ToScalar(i1 vars.Variable) *sw_bn254.Scalar {
+	// The decomposition into as many bits as the field checks that they are the canonical ones.
+	bits := a.api.ToBinaryLE(i1, a.api.FrontendAPI().Compiler().FieldBitLen())
+	return a.fr.FromBits(toFrontendVariables(bits)...)
+}
+
+// Asserts that a Groth16 proof is valid for the verifying key and the public inputs, in the order
+// of the inner circuit.
+func (a *RecursionAPI) AssertProof(vk *VerifyingKey, proof *Proof, public []vars.Variable) {
+	scalars := make([]*sw_bn254.Scalar, len(public))
+	for i := range public {
+		scalars[i] = a.ToScalar(public[i])
+	}
+	a.AssertProofWithScalars(vk, proof, scalars)
+}
+
+// Asserts that a Groth16 proof is valid for the verifying key and the public inputs as emulated
+// scalars. This mirrors stdgroth16.Verifier.AssertProof, whose multi-scalar multiplication in
+// gnark v0.9.1 drops every public input but the first.
+func (a *RecursionAPI) AssertProofWithScalars(vk *VerifyingKey, proof *Proof, public []*sw_bn254.Scalar) {
+	if len(public) != NbPublicInputs(vk) {
+		panic(fmt.Sprintf("expected %d public inputs, got %d", NbPublicInputs(vk), len(public)))
+	}
+	kSum := &vk.G1.K[0]
+	for i := 0; i < len(public); i++ {
+		kSum = a.curve.AddUnified(kSum, a.curve.ScalarMul(&vk.G1.K[i+1], public[i]))
+	}
+	res, err := a.pairing.Pair(
+		[]*sw_bn254.G1Affine{kSum, &proof.Krs, &proof.Ar},
+		[]*sw_bn254.G2Affine{&vk.G2.GammaNeg, &vk.G2.DeltaNeg, &proof.Bs},
+	)
+	if err != nil {
+		panic(fmt.Sprintf("pairing: %s", err))
+	}
+	a.pairing.AssertIsEqual(res, &vk.E)
+}
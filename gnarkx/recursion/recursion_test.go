@@ -0,0 +1,105 @@
+package recursion
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An inner circuit with several public inputs, proving that X * Y = Z for a secret W = X + Y.
+type TestInnerCircuit struct {
+	X vars.Variable `gnark:",public"`
+	Y vars.Variable `gnark:",public"`
+	Z vars.Variable `gnark:",public"`
+	W vars.Variable
+}
+
+func (c *TestInnerCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(api.Mul(c.X, c.Y), c.Z)
+	api.AssertIsEqual(api.Add(c.X, c.Y), c.W)
+	return nil
+}
+
+type TestOuterCircuit struct {
+	Proof  Proof
+	Public [3]vars.Variable
+	vk     VerifyingKey `gnark:"-"`
+}
+
+func (c *TestOuterCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).AssertProof(&c.vk, &c.Proof, c.Public[:])
+	return nil
+}
+
+func TestAssertProof(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestInnerCircuit{})
+	assert.NoError(t, err)
+	pk, innerVK, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&TestInnerCircuit{X: vars.NewVariableFromInt(3), Y: vars.NewVariableFromInt(5), Z: vars.NewVariableFromInt(15), W: vars.NewVariableFromInt(8)}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	innerProof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+
+	vk, err := ValueOfVerifyingKey(innerVK)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, NbPublicInputs(&vk))
+	proof, err := ValueOfProof(innerProof)
+	assert.NoError(t, err)
+	circuit := &TestOuterCircuit{vk: vk}
+	assignment := &TestOuterCircuit{Proof: proof, Public: [3]vars.Variable{vars.NewVariableFromInt(3), vars.NewVariableFromInt(5), vars.NewVariableFromInt(15)}}
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// Every public input is bound by the proof, not only the first.
+	assignment.Public[2] = vars.NewVariableFromInt(16)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+type TestPlonkOuterCircuit struct {
+	Proof  PlonkProof
+	Public [3]vars.Variable
+	vk     PlonkVerifyingKey `gnark:"-"`
+}
+
+func (c *TestPlonkOuterCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).AssertPlonkProof(&c.vk, &c.Proof, c.Public[:])
+	return nil
+}
+
+func TestAssertPlonkProof(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &TestInnerCircuit{})
+	assert.NoError(t, err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(t, err)
+	pk, innerVK, err := plonk.Setup(ccs, srs)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&TestInnerCircuit{X: vars.NewVariableFromInt(3), Y: vars.NewVariableFromInt(5), Z: vars.NewVariableFromInt(15), W: vars.NewVariableFromInt(8)}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	innerProof, err := plonk.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+
+	vk, err := ValueOfPlonkVerifyingKey(innerVK)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, vk.NbPublicInputs())
+	proof, err := ValueOfPlonkProof(innerProof)
+	assert.NoError(t, err)
+	circuit := &TestPlonkOuterCircuit{vk: vk}
+	assignment := &TestPlonkOuterCircuit{Proof: proof, Public: [3]vars.Variable{vars.NewVariableFromInt(3), vars.NewVariableFromInt(5), vars.NewVariableFromInt(15)}}
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The public inputs are bound by the proof.
+	assignment.Public[2] = vars.NewVariableFromInt(16)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,258 @@
+package reserves
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The signature of the message hash by an address and the proof of its account.
+type Ownership struct {
+	Address      [20]vars.Byte
+	R            [32]vars.Byte
+	S            [32]vars.Byte
+	V            vars.Variable
+	AccountProof eth.MPTProof
+}
+
+// Circuit proves the reserves of the addresses of an exchange and the total of its liabilities.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The header of the block, and the addresses of which the first NbReserves are counted, where
+	// the others repeat the first one.
+	Header     eth.Header
+	NbReserves vars.Variable
+	Reserves   []Ownership
+
+	// The children of the root of the sum tree of the liabilities.
+	Liabilities [2]merkle.SumNode
+
+	config      *Config                `gnark:"-"`
+	header      *eth.Header            `gnark:"-"`
+	reserves    []Reserve              `gnark:"-"`
+	liabilities [2]merkle.SumNodeValue `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.MaxReserves < 1 {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	c := &Circuit{
+		InputBytes:  vars.NewBytes(32 + 32 + 32),
+		OutputBytes: vars.NewBytes(OutputLength),
+		Header:      eth.NewHeader(config.MaxHeaderLength),
+		NbReserves:  vars.NewVariable(),
+		Reserves:    make([]Ownership, config.MaxReserves),
+		Liabilities: [2]merkle.SumNode{merkle.NewSumNode(), merkle.NewSumNode()},
+		config:      config,
+	}
+	for i := 0; i < len(c.Reserves); i++ {
+		c.Reserves[i].V = vars.NewVariable()
+		c.Reserves[i].AccountProof = eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength)
+	}
+	return c
+}
+
+// Sets the header of the block, the reserves, with proofs against the state root of the header
+// as returned by eth.Client.StorageProofs, and the children of the root of the sum tree of the
+// liabilities, such as Node(depth - 1, 0) and Node(depth - 1, 1) of the tree, that the next call
+// to SetWitness assigns.
+func (c *Circuit) SetReserves(header *eth.Header, reserves []Reserve, liabilities [2]merkle.SumNodeValue) error {
+	if len(header.RLP) != len(c.Header.RLP) {
+		return fmt.Errorf("header max length %d, expected %d", len(header.RLP), len(c.Header.RLP))
+	}
+	if err := c.config.checkReserves(reserves); err != nil {
+		return err
+	}
+	for i := 0; i < len(liabilities); i++ {
+		if liabilities[i].Sum.Sign() < 0 || liabilities[i].Sum.BitLen() > amountBits {
+			return fmt.Errorf("liabilities of %d bits, expected at most %d", liabilities[i].Sum.BitLen(), amountBits)
+		}
+	}
+	c.header = header
+	c.reserves = reserves
+	c.liabilities = liabilities
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the header, reserves and liabilities given to SetReserves.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.header == nil {
+		panic("reserves must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	c.Header = *c.header
+	c.NbReserves = vars.NewVariableFromInt(len(c.reserves))
+	for i := 0; i < len(c.Reserves); i++ {
+		reserve := c.reserves[0]
+		if i < len(c.reserves) {
+			reserve = c.reserves[i]
+		}
+		for j := 0; j < 20; j++ {
+			c.Reserves[i].Address[j].Set(reserve.Address[j])
+		}
+		vars.SetBytes32(&c.Reserves[i].R, [32]byte(reserve.Signature[:32]))
+		vars.SetBytes32(&c.Reserves[i].S, [32]byte(reserve.Signature[32:64]))
+		c.Reserves[i].V = vars.NewVariableFromInt(int(reserve.Signature[64]))
+		c.Reserves[i].AccountProof = *reserve.Proof
+	}
+	for i := 0; i < len(c.Liabilities); i++ {
+		c.Liabilities[i].Set(c.liabilities[i])
+	}
+	vars.SetBytes(&c.OutputBytes, outputs(inputBytes, c.reserves, c.liabilities))
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	blockHash := inputReader.ReadBytes32()
+	messageHash := inputReader.ReadBytes32()
+	liabilitiesRoot := inputReader.ReadBytes32()
+	one := vars.NewVariableFromInt(1)
+	zero := vars.NewVariableFromInt(0)
+
+	header.NewAPI(api).Verify(c.Header)
+	st := storage.NewAPI(api)
+	api.AssertIsEqualBytes32(c.Header.Hash, blockHash)
+
+	// The total of the liabilities is the sum of the root of their tree.
+	merkleAPI := merkle.NewAPI(api)
+	for i := 0; i < len(c.Liabilities); i++ {
+		api.ToBinaryLE(c.Liabilities[i].Sum, amountBits)
+	}
+	root := merkleAPI.SumParent(c.Liabilities[0], c.Liabilities[1], amountBits)
+	api.AssertIsEqual(root.Hash, api.ToVariableFromBytesBE(liabilitiesRoot[:]))
+
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(c.Reserves); i++ {
+		witnessBytes := append(append(c.Reserves[i].Address[:], c.Reserves[i].R[:]...), c.Reserves[i].S[:]...)
+		for j := 0; j < len(witnessBytes); j++ {
+			rc.Check(witnessBytes[j].Value.Value, 8)
+		}
+	}
+	api.AssertIsDifferent(c.NbReserves, zero)
+	api.AssertIsLessOrEqual(c.NbReserves, vars.NewVariableFromInt(len(c.Reserves)))
+
+	prefix := vars.NewBytesFrom([]byte(personalSignMessage))
+	digest := keccak256.Hash(*api, append(prefix, messageHash[:]...))
+	var dummyHash, dummyR, dummyS [32]vars.Byte
+	copy(dummyHash[:], vars.NewBytesFrom(dummyDigest))
+	copy(dummyR[:], vars.NewBytesFrom(dummySignature[:32]))
+	copy(dummyS[:], vars.NewBytesFrom(dummySignature[32:64]))
+	dummyV := vars.NewVariableFromInt(int(dummySignature[64]))
+
+	// Every counted address signs the message hash and is greater than the previous one.
+	active := one
+	reserves := zero
+	first := api.ToVariableFromBytesBE(c.Reserves[0].Address[:])
+	last := first
+	for i, reserve := range c.Reserves {
+		address := api.ToVariableFromBytesBE(reserve.Address[:])
+		if i > 0 {
+			active = api.Sub(active, api.IsZero(api.Sub(c.NbReserves, vars.NewVariableFromInt(i))).Value)
+			next := api.Add(last, one)
+			api.AssertIsLessOrEqual(next, api.Select(vars.Bool{Value: active}, address, next))
+			last = api.Select(vars.Bool{Value: active}, address, last)
+		}
+		isActive := vars.Bool{Value: active}
+		x, y := compat.ECRecover(
+			*api,
+			api.SelectBytes32(isActive, digest, dummyHash),
+			api.Select(isActive, reserve.V, dummyV),
+			api.SelectBytes32(isActive, reserve.R, dummyR),
+			api.SelectBytes32(isActive, reserve.S, dummyS),
+		)
+		key := keccak256.Hash(*api, append(x[:], y[:]...))
+		api.AssertIsEqual(api.Mul(active, api.Sub(api.ToVariableFromBytesBE(key[12:]), address)), zero)
+
+		account := st.VerifyAccount(c.Header.StateRoot, reserve.Address, reserve.AccountProof)
+		for j := 0; j < 32-amountLength; j++ {
+			api.AssertIsEqual(account.Balance[j].Value, zero)
+		}
+		reserves = api.Add(reserves, api.Mul(active, api.ToVariableFromBytesBE(account.Balance[32-amountLength:])))
+	}
+
+	outputBytes := append(append(append([]vars.Byte{}, blockHash[:]...), messageHash[:]...), liabilitiesRoot[:]...)
+	outputBytes = append(outputBytes, amounts(*api, root.Sum, reserves, first, last)...)
+	for i := 0; i < OutputLength; i++ {
+		api.AssertIsEqualByte(outputBytes[i], c.OutputBytes[i])
+	}
+	return nil
+}
+
+// Returns the bytes of the liabilities, the reserves, the surplus and the addresses of an output,
+// where the amounts are range checked.
+func amounts(api builder.API, liabilities vars.Variable, reserves vars.Variable, first vars.Variable, last vars.Variable) []vars.Byte {
+	out := append(toBytes(api, liabilities, amountLength), toBytes(api, reserves, amountLength)...)
+
+	// The surplus is negative if the liabilities are greater than the reserves, and its low bytes
+	// are then reserves - liabilities + 2^128.
+	isNegative := api.IsZero(api.Sub(api.Cmp(liabilities, reserves), vars.NewVariableFromInt(1)))
+	modulus := vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), amountBits)}
+	surplus := api.Add(api.Sub(reserves, liabilities), api.Mul(isNegative.Value, modulus))
+	for i := 0; i < 32-amountLength; i++ {
+		out = append(out, vars.Byte{Value: api.Mul(isNegative.Value, vars.NewVariableFromInt(0xff))})
+	}
+	out = append(out, toBytes(api, surplus, amountLength)...)
+	return append(append(out, toBytes(api, first, 20)...), toBytes(api, last, 20)...)
+}
+
+// Returns the big-endian bytes of a variable of at most n bytes, which is range checked.
+func toBytes(api builder.API, v vars.Variable, n int) []vars.Byte {
+	bits := api.ToBinaryLE(v, 8*n)
+	out := make([]vars.Byte, n)
+	for i := 0; i < n; i++ {
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[8*(n-1-i):8*(n-i)])
+		out[i] = api.ToByteFromBits(byteBits)
+	}
+	return out
+}
+
+// A valid signature of a constant hash, recovered in place of the addresses that are not counted.
+var (
+	dummyDigest    = crypto.Keccak256([]byte("reserves"))
+	dummySignature = func() []byte {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{1}, 32))
+		if err != nil {
+			panic(err)
+		}
+		signature, err := crypto.Sign(dummyDigest, key)
+		if err != nil {
+			panic(err)
+		}
+		signature[64] += 27
+		return signature
+	}()
+)
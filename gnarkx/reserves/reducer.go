@@ -0,0 +1,67 @@
+package reserves
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/mapreduce"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Reducer aggregates the outputs of circuits for the same block, message and liabilities over
+// consecutive ranges of addresses, in ascending order, into the output of all of their addresses.
+type Reducer struct{}
+
+var _ mapreduce.Reducer = Reducer{}
+
+func (Reducer) Reduce(api builder.API, childOutputs [][]vars.Byte) []vars.Byte {
+	one := vars.NewVariableFromInt(1)
+	first := childOutputs[0]
+	reserves := api.ToVariableFromBytesBE(first[reservesOffset:surplusOffset])
+	for i := 1; i < len(childOutputs); i++ {
+		child := childOutputs[i]
+		for j := 0; j < reservesOffset; j++ {
+			api.AssertIsEqualByte(child[j], first[j])
+		}
+		last := api.ToVariableFromBytesBE(childOutputs[i-1][lastAddressOffset:OutputLength])
+		api.AssertIsLessOrEqual(api.Add(last, one), api.ToVariableFromBytesBE(child[firstAddressOffset:lastAddressOffset]))
+		reserves = api.Add(reserves, api.ToVariableFromBytesBE(child[reservesOffset:surplusOffset]))
+	}
+	output := append([]vars.Byte{}, first[:liabilitiesOffset]...)
+	return append(output, amounts(
+		api,
+		api.ToVariableFromBytesBE(first[liabilitiesOffset:reservesOffset]),
+		reserves,
+		api.ToVariableFromBytesBE(first[firstAddressOffset:lastAddressOffset]),
+		api.ToVariableFromBytesBE(childOutputs[len(childOutputs)-1][lastAddressOffset:OutputLength]),
+	)...)
+}
+
+func (Reducer) ReduceNative(childOutputs [][]byte) []byte {
+	first := childOutputs[0]
+	reserves := new(big.Int)
+	for i, child := range childOutputs {
+		if i > 0 {
+			if !bytes.Equal(child[:reservesOffset], first[:reservesOffset]) {
+				panic(fmt.Sprintf("child %d is of another block, message or liabilities", i))
+			}
+			if bytes.Compare(childOutputs[i-1][lastAddressOffset:OutputLength], child[firstAddressOffset:lastAddressOffset]) >= 0 {
+				panic(fmt.Sprintf("child %d is not in ascending order of address", i))
+			}
+		}
+		reserves.Add(reserves, new(big.Int).SetBytes(child[reservesOffset:surplusOffset]))
+	}
+	if reserves.BitLen() > amountBits {
+		panic(fmt.Sprintf("reserves of %d bits, expected at most %d", reserves.BitLen(), amountBits))
+	}
+	return appendAmounts(
+		append([]byte{}, first[:liabilitiesOffset]...),
+		new(big.Int).SetBytes(first[liabilitiesOffset:reservesOffset]),
+		reserves,
+		common.BytesToAddress(first[firstAddressOffset:lastAddressOffset]),
+		common.BytesToAddress(childOutputs[len(childOutputs)-1][lastAddressOffset:OutputLength]),
+	)
+}
@@ -0,0 +1,113 @@
+// A circuit template for proofs of reserves of an exchange: the circuit proves that the exchange
+// controls addresses holding a total balance of ether at a block, and compares it to the total of
+// its liabilities committed in a Merkle sum tree of the merkle package.
+//
+// The input is the block hash, the hash of a message that identifies the proof, such as the hash
+// of "<exchange> proof of reserves at <date>", and the hash of the root of the sum tree of the
+// liabilities. Every address signs the message hash with personal_sign, which proves that the
+// exchange controls its key, and its balance is proven against the state root of the block. The
+// addresses are in strictly ascending order so that no balance is counted twice.
+//
+// The output is the block hash, the message hash and the liabilities root followed by the total
+// liabilities and reserves as uint128, the surplus reserves - liabilities as an int256, and the
+// first and last addresses. A circuit proves at most MaxReserves addresses, and Reducer aggregates
+// the proofs of circuits over consecutive ranges of addresses with the mapreduce package, so that
+// exchanges with many addresses can be proven in chunks.
+package reserves
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+)
+
+// The length of the output and the offsets of its fields.
+const (
+	liabilitiesOffset   = 96
+	reservesOffset      = liabilitiesOffset + 16
+	surplusOffset       = reservesOffset + 16
+	firstAddressOffset  = surplusOffset + 32
+	lastAddressOffset   = firstAddressOffset + 20
+	OutputLength        = lastAddressOffset + 20
+	amountLength        = 16
+	amountBits          = 8 * amountLength
+	signatureLength     = 65
+	personalSignMessage = "\x19Ethereum Signed Message:\n32"
+)
+
+// Config describes the number of addresses and the proofs a circuit accepts.
+type Config struct {
+	MaxReserves     int
+	MaxHeaderLength int
+	MaxDepth        int
+	MaxNodeLength   int
+}
+
+// An address of the exchange, with its personal_sign signature r || s || v of the message hash,
+// its balance, and the proof of its account.
+type Reserve struct {
+	Address   common.Address
+	Signature []byte
+	Balance   *big.Int
+	Proof     *eth.MPTProof
+}
+
+// Returns the input of the circuit.
+func Input(blockHash common.Hash, messageHash common.Hash, liabilitiesRoot *big.Int) []byte {
+	return append(append(blockHash.Bytes(), messageHash.Bytes()...), liabilitiesRoot.FillBytes(make([]byte, 32))...)
+}
+
+// Returns the digest that the addresses sign for the message hash.
+func OwnershipDigest(messageHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte(personalSignMessage), messageHash[:])
+}
+
+// Checks that the reserves fit the config and are in strictly ascending order of address.
+func (c *Config) checkReserves(reserves []Reserve) error {
+	if len(reserves) == 0 || len(reserves) > c.MaxReserves {
+		return fmt.Errorf("%d reserves, expected between 1 and %d", len(reserves), c.MaxReserves)
+	}
+	for i, reserve := range reserves {
+		if i > 0 && bytes.Compare(reserves[i-1].Address[:], reserve.Address[:]) >= 0 {
+			return fmt.Errorf("reserve %d is not in ascending order of address", i)
+		}
+		if len(reserve.Signature) != signatureLength || reserve.Balance.Sign() < 0 || reserve.Balance.BitLen() > amountBits {
+			return fmt.Errorf("reserve %d has an invalid signature or balance", i)
+		}
+	}
+	return nil
+}
+
+// Returns the output of a circuit for the input, the reserves, and the children of the root of the
+// sum tree of the liabilities.
+func outputs(inputBytes []byte, reserves []Reserve, liabilities [2]merkle.SumNodeValue) []byte {
+	total := new(big.Int).Add(liabilities[0].Sum, liabilities[1].Sum)
+	balance := new(big.Int)
+	for _, reserve := range reserves {
+		balance.Add(balance, reserve.Balance)
+	}
+	return appendAmounts(
+		append([]byte{}, inputBytes[:liabilitiesOffset]...),
+		total,
+		balance,
+		reserves[0].Address,
+		reserves[len(reserves)-1].Address,
+	)
+}
+
+// Appends the liabilities, the reserves, the surplus and the addresses of an output.
+func appendAmounts(output []byte, liabilities *big.Int, reserves *big.Int, first common.Address, last common.Address) []byte {
+	output = append(output, liabilities.FillBytes(make([]byte, amountLength))...)
+	output = append(output, reserves.FillBytes(make([]byte, amountLength))...)
+	surplus := new(big.Int).Sub(reserves, liabilities)
+	if surplus.Sign() < 0 {
+		surplus.Add(surplus, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	output = append(output, surplus.FillBytes(make([]byte, 32))...)
+	return append(append(output, first.Bytes()...), last.Bytes()...)
+}
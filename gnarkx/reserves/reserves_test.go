@@ -0,0 +1,187 @@
+package reserves
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	config = &Config{
+		MaxReserves:     4,
+		MaxHeaderLength: eth.MaxHeaderLength,
+		MaxDepth:        4,
+		MaxNodeLength:   eth.MaxNodeLength,
+	}
+	messageHash = crypto.Keccak256Hash([]byte("exchange proof of reserves"))
+)
+
+// Returns the reserves of three keys sorted by address with the header of their state, where some
+// other accounts are not reserves.
+func newReserves(t *testing.T) (*eth.Header, []Reserve) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{byte(i + 2)}, 32))
+		assert.NoError(t, err)
+		keys[i] = key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(keys[i].PublicKey), crypto.PubkeyToAddress(keys[j].PublicKey)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+
+	accounts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	reserves := make([]Reserve, len(keys))
+	encoded := make([][]byte, len(keys))
+	for i, key := range keys {
+		balance := new(big.Int).Mul(big.NewInt(int64(i+1)), big.NewInt(1e18))
+		account, err := rlp.EncodeToBytes([]interface{}{uint64(i), balance, types.EmptyRootHash, types.EmptyCodeHash})
+		assert.NoError(t, err)
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		accounts.MustUpdate(crypto.Keccak256(address.Bytes()), account)
+		signature, err := crypto.Sign(OwnershipDigest(messageHash).Bytes(), key)
+		assert.NoError(t, err)
+		signature[64] += 27
+		reserves[i] = Reserve{Address: address, Signature: signature, Balance: balance}
+		encoded[i] = account
+	}
+	for i := 0; i < 10; i++ {
+		account, err := rlp.EncodeToBytes([]interface{}{uint64(0), big.NewInt(int64(i)), types.EmptyRootHash, types.EmptyCodeHash})
+		assert.NoError(t, err)
+		accounts.MustUpdate(crypto.Keccak256(common.BigToAddress(big.NewInt(int64(i))).Bytes()), account)
+	}
+	for i := range reserves {
+		reserves[i].Proof = functiontest.Prove(t, accounts, reserves[i].Address.Bytes(), encoded[i], config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength)
+	}
+
+	header := eth.NewHeader(config.MaxHeaderLength)
+	assert.NoError(t, header.Set(&types.Header{
+		Root:       accounts.Hash(),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(18000000),
+		GasLimit:   30000000,
+		Time:       1750000000,
+		BaseFee:    big.NewInt(20e9),
+	}))
+	return &header, reserves
+}
+
+// Returns the sum tree of the liabilities of accounts with the amounts.
+func newLiabilities(t *testing.T, amounts ...int64) *merkle.SumTree {
+	leaves := make([]merkle.SumNodeValue, len(amounts))
+	for i := range amounts {
+		leaves[i] = merkle.SumLeafValue(big.NewInt(int64(1000+i)), new(big.Int).Mul(big.NewInt(amounts[i]), big.NewInt(1e17)))
+	}
+	tree, err := merkle.NewSumTree(2, amountBits, leaves)
+	assert.NoError(t, err)
+	return tree
+}
+
+func TestReserves(t *testing.T) {
+	header, reserves := newReserves(t)
+	blockHash := common.BytesToHash(vars.GetValuesUnsafe(header.Hash[:]))
+	tree := newLiabilities(t, 15, 20, 5, 10)
+	liabilities := [2]merkle.SumNodeValue{tree.Node(1, 0), tree.Node(1, 1)}
+	input := Input(blockHash, messageHash, tree.Root().Hash)
+
+	// The reserves of 6 ether exceed the liabilities of 5 ether.
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetReserves(header, reserves, liabilities))
+	assert.NoError(t, functiontest.IsSolved(circuit, input))
+	output := vars.GetValuesUnsafe(*circuit.GetOutputBytes())
+	assert.Equal(t, input, output[:liabilitiesOffset])
+	assert.Equal(t, big.NewInt(5e18), new(big.Int).SetBytes(output[liabilitiesOffset:reservesOffset]))
+	assert.Equal(t, big.NewInt(6e18), new(big.Int).SetBytes(output[reservesOffset:surplusOffset]))
+	assert.Equal(t, big.NewInt(1e18), new(big.Int).SetBytes(output[surplusOffset:firstAddressOffset]))
+	assert.Equal(t, reserves[0].Address.Bytes(), output[firstAddressOffset:lastAddressOffset])
+	assert.Equal(t, reserves[2].Address.Bytes(), output[lastAddressOffset:])
+
+	// The surplus of the first two addresses is negative.
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetReserves(header, reserves[:2], liabilities))
+	assert.NoError(t, functiontest.IsSolved(circuit, input))
+	output = vars.GetValuesUnsafe(*circuit.GetOutputBytes())
+	surplus := new(big.Int).Sub(new(big.Int).SetBytes(output[surplusOffset:firstAddressOffset]), new(big.Int).Lsh(big.NewInt(1), 256))
+	assert.Equal(t, big.NewInt(-2e18), surplus)
+
+	// The addresses must be in ascending order.
+	assert.Error(t, circuit.SetReserves(header, []Reserve{reserves[1], reserves[0]}, liabilities))
+	circuit.reserves = []Reserve{reserves[1], reserves[0]}
+	assert.Error(t, functiontest.IsSolved(circuit, input))
+
+	// Every address must sign the message hash.
+	unsigned := append([]Reserve{}, reserves...)
+	unsigned[1].Signature = reserves[0].Signature
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetReserves(header, unsigned, liabilities))
+	assert.Error(t, functiontest.IsSolved(circuit, input))
+
+	// The balance must be of the account.
+	inflated := append([]Reserve{}, reserves...)
+	inflated[2].Balance = big.NewInt(4e18)
+	inflated[2].Proof = reserves[1].Proof
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetReserves(header, inflated, liabilities))
+	assert.Error(t, functiontest.IsSolved(circuit, input))
+
+	// The liabilities must be of the root.
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetReserves(header, reserves, [2]merkle.SumNodeValue{liabilities[0], tree.Node(0, 3)}))
+	assert.Error(t, functiontest.IsSolved(circuit, input))
+}
+
+type testReduceCircuit struct {
+	Children [2][]vars.Byte
+	Output   []vars.Byte
+}
+
+func (c *testReduceCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	output := Reducer{}.Reduce(*api, c.Children[:])
+	for i := range output {
+		api.AssertIsEqualByte(output[i], c.Output[i])
+	}
+	return nil
+}
+
+func TestReducer(t *testing.T) {
+	header, reserves := newReserves(t)
+	blockHash := common.BytesToHash(vars.GetValuesUnsafe(header.Hash[:]))
+	tree := newLiabilities(t, 15, 20, 5, 10)
+	liabilities := [2]merkle.SumNodeValue{tree.Node(1, 0), tree.Node(1, 1)}
+	input := Input(blockHash, messageHash, tree.Root().Hash)
+	children := [][]byte{outputs(input, reserves[:1], liabilities), outputs(input, reserves[1:], liabilities)}
+	output := Reducer{}.ReduceNative(children)
+	assert.Equal(t, outputs(input, reserves, liabilities), output)
+
+	circuit := &testReduceCircuit{Children: [2][]vars.Byte{vars.NewBytes(OutputLength), vars.NewBytes(OutputLength)}, Output: vars.NewBytes(OutputLength)}
+	assignment := &testReduceCircuit{Children: [2][]vars.Byte{vars.NewBytes(OutputLength), vars.NewBytes(OutputLength)}, Output: vars.NewBytes(OutputLength)}
+	vars.SetBytes(&assignment.Children[0], children[0])
+	vars.SetBytes(&assignment.Children[1], children[1])
+	vars.SetBytes(&assignment.Output, output)
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The ranges of addresses must not overlap.
+	assert.Panics(t, func() { Reducer{}.ReduceNative([][]byte{children[1], children[0]}) })
+	vars.SetBytes(&assignment.Children[0], children[1])
+	vars.SetBytes(&assignment.Children[1], children[0])
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
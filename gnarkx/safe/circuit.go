@@ -0,0 +1,409 @@
+package safe
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A signature r || s || v of the transaction hash.
+type Signature struct {
+	R [32]vars.Byte
+	S [32]vars.Byte
+	V vars.Variable
+}
+
+// The proofs of the approved hashes against the block of the input: the header, the proof of the
+// Safe account and the proofs of the slots of Config.Slots.
+type StateProofs struct {
+	Header        eth.Header
+	AccountProof  eth.MPTProof
+	StorageProofs []eth.MPTProof
+}
+
+// Circuit proves that a transaction of a Safe is approved by its owners.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	To             [20]vars.Byte
+	Value          [32]vars.Byte
+	Data           []vars.Byte
+	DataLength     vars.Variable
+	Operation      vars.Variable
+	SafeTxGas      [32]vars.Byte
+	BaseGas        [32]vars.Byte
+	GasPrice       [32]vars.Byte
+	GasToken       [20]vars.Byte
+	RefundReceiver [20]vars.Byte
+	Nonce          [32]vars.Byte
+
+	// The preimage of the hash of the owners right padded with zeros, and the number of owners.
+	Owners   []vars.Byte
+	NbOwners vars.Variable
+
+	// The signatures up to MaxThreshold, where those after the threshold are unused.
+	Signatures []Signature
+
+	// The proofs of the approved hashes, of which there is one if approved hashes are enabled and
+	// none otherwise.
+	Proofs []StateProofs
+
+	config        *Config          `gnark:"-"`
+	tx            *Transaction     `gnark:"-"`
+	threshold     uint64           `gnark:"-"`
+	owners        []common.Address `gnark:"-"`
+	signatures    []byte           `gnark:"-"`
+	header        *eth.Header      `gnark:"-"`
+	accountProof  *eth.MPTProof    `gnark:"-"`
+	storageProofs []*eth.MPTProof  `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.MaxThreshold < 1 || config.MaxThreshold > config.MaxOwners || config.MaxOwners > 255 {
+		panic(fmt.Sprintf("unsupported threshold %d of %d owners", config.MaxThreshold, config.MaxOwners))
+	}
+	inputLength := 20 + 32
+	if config.ApprovedHashes {
+		inputLength += 32
+	}
+	c := &Circuit{
+		InputBytes:  vars.NewBytes(inputLength),
+		OutputBytes: vars.NewBytes(32),
+		Data:        vars.NewBytes(config.MaxDataLength),
+		DataLength:  vars.NewVariable(),
+		Operation:   vars.NewVariable(),
+		Owners:      vars.NewBytes(32 * (1 + config.MaxOwners)),
+		NbOwners:    vars.NewVariable(),
+		Signatures:  make([]Signature, config.MaxThreshold),
+		config:      config,
+	}
+	for i := 0; i < len(c.Signatures); i++ {
+		c.Signatures[i].V = vars.NewVariable()
+	}
+	if config.ApprovedHashes {
+		proofs := StateProofs{
+			Header:        eth.NewHeader(config.MaxHeaderLength),
+			AccountProof:  eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength),
+			StorageProofs: make([]eth.MPTProof, config.MaxThreshold),
+		}
+		for i := 0; i < len(proofs.StorageProofs); i++ {
+			proofs.StorageProofs[i] = eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxStorageValueLength)
+		}
+		c.Proofs = []StateProofs{proofs}
+	}
+	return c
+}
+
+// Sets the transaction, the threshold and owners of the Safe, and the signatures of the
+// transaction hash concatenated as for execTransaction, that the next call to SetWitness assigns.
+func (c *Circuit) SetTransaction(
+	safe common.Address,
+	tx *Transaction,
+	threshold uint64,
+	owners []common.Address,
+	signatures []byte,
+) error {
+	if len(tx.Data) > c.config.MaxDataLength {
+		return fmt.Errorf("data length %d, expected at most %d", len(tx.Data), c.config.MaxDataLength)
+	}
+	if tx.Operation > 1 {
+		return fmt.Errorf("invalid operation %d", tx.Operation)
+	}
+	if err := c.config.checkSignatures(c.config.TransactionHash(safe, tx), threshold, owners, signatures); err != nil {
+		return err
+	}
+	c.tx = tx
+	c.threshold = threshold
+	c.owners = owners
+	c.signatures = signatures
+	return nil
+}
+
+// Sets the proofs of the approved hashes that the next call to SetWitness assigns: the header of
+// the block, and the proofs of the Safe account and of the slots of Config.Slots, as returned by
+// eth.Client.StorageProofs.
+func (c *Circuit) SetProofs(header *eth.Header, accountProof *eth.MPTProof, storageProofs []*eth.MPTProof) error {
+	if !c.config.ApprovedHashes {
+		return fmt.Errorf("approved hashes are not enabled")
+	}
+	shape := c.Proofs[0]
+	if len(header.RLP) != len(shape.Header.RLP) {
+		return fmt.Errorf("header max length %d, expected %d", len(header.RLP), len(shape.Header.RLP))
+	}
+	if len(storageProofs) != len(shape.StorageProofs) {
+		return fmt.Errorf("expected %d storage proofs, got %d", len(shape.StorageProofs), len(storageProofs))
+	}
+	proofs := append([]*eth.MPTProof{accountProof}, storageProofs...)
+	expected := append([]eth.MPTProof{shape.AccountProof}, shape.StorageProofs...)
+	for i := 0; i < len(proofs); i++ {
+		if len(proofs[i].Nodes) != len(expected[i].Nodes) || len(proofs[i].Nodes[0]) != len(expected[i].Nodes[0]) ||
+			len(proofs[i].Value) != len(expected[i].Value) {
+			return fmt.Errorf("proof %d does not have the shape of the config", i)
+		}
+	}
+	c.header = header
+	c.accountProof = accountProof
+	c.storageProofs = storageProofs
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the transaction given to SetTransaction and the proofs given to SetProofs.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	tx := c.tx
+	if tx == nil || (c.config.ApprovedHashes && c.header == nil) {
+		panic("transaction and proofs must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	setAddress(&c.To, tx.To)
+	vars.SetBytes32(&c.Value, [32]byte(math.U256Bytes(new(big.Int).Set(tx.Value))))
+	data := make([]byte, len(c.Data))
+	copy(data, tx.Data)
+	vars.SetBytes(&c.Data, data)
+	c.DataLength = vars.NewVariableFromInt(len(tx.Data))
+	c.Operation = vars.NewVariableFromInt(int(tx.Operation))
+	vars.SetBytes32(&c.SafeTxGas, [32]byte(math.U256Bytes(new(big.Int).Set(tx.SafeTxGas))))
+	vars.SetBytes32(&c.BaseGas, [32]byte(math.U256Bytes(new(big.Int).Set(tx.BaseGas))))
+	vars.SetBytes32(&c.GasPrice, [32]byte(math.U256Bytes(new(big.Int).Set(tx.GasPrice))))
+	setAddress(&c.GasToken, tx.GasToken)
+	setAddress(&c.RefundReceiver, tx.RefundReceiver)
+	vars.SetBytes32(&c.Nonce, [32]byte(math.U256Bytes(new(big.Int).Set(tx.Nonce))))
+
+	owners := make([]byte, len(c.Owners))
+	copy(owners, ownersPreimage(c.threshold, c.owners))
+	vars.SetBytes(&c.Owners, owners)
+	c.NbOwners = vars.NewVariableFromInt(len(c.owners))
+	for i := 0; i < len(c.Signatures); i++ {
+		signature := make([]byte, SignatureLength)
+		if i < int(c.threshold) {
+			copy(signature, c.signatures[i*SignatureLength:])
+		}
+		vars.SetBytes32(&c.Signatures[i].R, [32]byte(signature[:32]))
+		vars.SetBytes32(&c.Signatures[i].S, [32]byte(signature[32:64]))
+		c.Signatures[i].V = vars.NewVariableFromInt(int(signature[64]))
+	}
+	if c.config.ApprovedHashes {
+		c.Proofs[0].Header = *c.header
+		c.Proofs[0].AccountProof = *c.accountProof
+		for i := 0; i < len(c.storageProofs); i++ {
+			c.Proofs[0].StorageProofs[i] = *c.storageProofs[i]
+		}
+	}
+
+	hash := c.config.TransactionHash(common.BytesToAddress(inputBytes[:20]), tx)
+	vars.SetBytes(&c.OutputBytes, hash[:])
+}
+
+func setAddress(b *[20]vars.Byte, address common.Address) {
+	for i := 0; i < 20; i++ {
+		b[i].Set(address[i])
+	}
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	fapi := api.FrontendAPI()
+	config := c.config
+	rc := rangecheck.New(fapi)
+	witnessBytes := append(append(append(c.To[:], c.Value[:]...), c.Data...), c.SafeTxGas[:]...)
+	witnessBytes = append(append(append(witnessBytes, c.BaseGas[:]...), c.GasPrice[:]...), c.GasToken[:]...)
+	witnessBytes = append(append(append(witnessBytes, c.RefundReceiver[:]...), c.Nonce[:]...), c.Owners...)
+	for i := 0; i < len(c.Signatures); i++ {
+		witnessBytes = append(append(witnessBytes, c.Signatures[i].R[:]...), c.Signatures[i].S[:]...)
+	}
+	for i := 0; i < len(witnessBytes); i++ {
+		rc.Check(witnessBytes[i].Value.Value, 8)
+	}
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	safe := inputReader.ReadAddress()
+	ownersHash := inputReader.ReadBytes32()
+
+	// The threshold and the owners, where the threshold is at most the number of owners.
+	for i := 0; i < 31; i++ {
+		fapi.AssertIsEqual(c.Owners[i].Value.Value, 0)
+	}
+	threshold := c.Owners[31].Value
+	api.AssertIsDifferent(threshold, vars.NewVariableFromInt(0))
+	api.AssertIsLessOrEqual(threshold, vars.NewVariableFromInt(config.MaxThreshold))
+	api.AssertIsLessOrEqual(threshold, c.NbOwners)
+	api.AssertIsLessOrEqual(c.NbOwners, vars.NewVariableFromInt(config.MaxOwners))
+	owners := make([]vars.Variable, config.MaxOwners)
+	for i := 0; i < config.MaxOwners; i++ {
+		word := c.Owners[32*(i+1) : 32*(i+2)]
+		for j := 0; j < 12; j++ {
+			fapi.AssertIsEqual(word[j].Value.Value, 0)
+		}
+		owners[i] = api.ToVariableFromBytesBE(word[12:])
+	}
+	ownersLength := api.Mul(api.Add(c.NbOwners, vars.NewVariableFromInt(1)), vars.NewVariableFromInt(32))
+	digest := keccak256.HashVariable(*api, c.Owners, ownersLength)
+	api.AssertIsEqualBytes32(digest, ownersHash)
+
+	hash := c.transactionHash(*api, safe)
+	c.verifySignatures(*api, safe, hash, threshold, owners)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(hash)
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Returns the EIP-712 hash of the transaction of the Safe.
+func (c *Circuit) transactionHash(api builder.API, safe [20]vars.Byte) [32]vars.Byte {
+	fapi := api.FrontendAPI()
+	fapi.AssertIsBoolean(c.Operation.Value)
+	api.AssertIsLessOrEqual(c.DataLength, vars.NewVariableFromInt(len(c.Data)))
+	dataHash := keccak256.HashVariable(api, c.Data, c.DataLength)
+	operation := append(vars.NewBytesFrom(make([]byte, 31)), vars.Byte{Value: c.Operation})
+
+	encoded := vars.NewBytesFrom(crypto.Keccak256([]byte(transactionType)))
+	to := storage.AddressWord(c.To)
+	encoded = append(append(encoded, to[:]...), c.Value[:]...)
+	encoded = append(append(encoded, dataHash[:]...), operation...)
+	encoded = append(append(encoded, c.SafeTxGas[:]...), c.BaseGas[:]...)
+	gasToken := storage.AddressWord(c.GasToken)
+	encoded = append(append(encoded, c.GasPrice[:]...), gasToken[:]...)
+	refundReceiver := storage.AddressWord(c.RefundReceiver)
+	encoded = append(append(encoded, refundReceiver[:]...), c.Nonce[:]...)
+	structHash := keccak256.Hash(api, encoded)
+
+	domain := vars.NewBytesFrom(crypto.Keccak256([]byte(domainType)))
+	domain = append(domain, vars.NewBytesFrom(math.U256Bytes(new(big.Int).Set(c.config.ChainID)))...)
+	verifyingContract := storage.AddressWord(safe)
+	domainSeparator := keccak256.Hash(api, append(domain, verifyingContract[:]...))
+	message := append(append(vars.NewBytesFrom([]byte{0x19, 0x01}), domainSeparator[:]...), structHash[:]...)
+	return keccak256.Hash(api, message)
+}
+
+// Verifies the first threshold signatures of the transaction hash, which are by owners in
+// ascending order of address.
+func (c *Circuit) verifySignatures(
+	api builder.API,
+	safe [20]vars.Byte,
+	hash [32]vars.Byte,
+	threshold vars.Variable,
+	owners []vars.Variable,
+) {
+	config := c.config
+	one := vars.NewVariableFromInt(1)
+	var st *storage.StorageAPI
+	var account storage.Account
+	if config.ApprovedHashes {
+		proofs := c.Proofs[0]
+		blockHash := builder.NewInputReader(api, c.InputBytes[20+32:]).ReadBytes32()
+		header.NewAPI(&api).Verify(proofs.Header)
+		st = storage.NewAPI(&api)
+		api.AssertIsEqualBytes32(proofs.Header.Hash, blockHash)
+		account = st.VerifyAccount(proofs.Header.StateRoot, safe, proofs.AccountProof)
+	}
+
+	ethSignPrefix := vars.NewBytesFrom([]byte("\x19Ethereum Signed Message:\n32"))
+	ethSignHash := keccak256.Hash(api, append(ethSignPrefix, hash[:]...))
+	var dummyHash, dummyR, dummyS [32]vars.Byte
+	copy(dummyHash[:], vars.NewBytesFrom(dummyDigest))
+	copy(dummyR[:], vars.NewBytesFrom(dummySignature[:32]))
+	copy(dummyS[:], vars.NewBytesFrom(dummySignature[32:64]))
+	dummyV := vars.NewVariableFromInt(int(dummySignature[64]))
+
+	used := one
+	last := vars.NewVariableFromInt(0)
+	for i, signature := range c.Signatures {
+		used = api.Sub(used, api.IsZero(api.Sub(threshold, vars.NewVariableFromInt(i))).Value)
+		v := signature.V
+		isApproved := api.IsZero(api.Sub(v, one))
+		isEthSign := api.Add(api.IsZero(api.Sub(v, vars.NewVariableFromInt(31))).Value, api.IsZero(api.Sub(v, vars.NewVariableFromInt(32))).Value)
+		kind := api.Mul(api.Mul(api.Sub(v, one), api.Sub(v, vars.NewVariableFromInt(27))), api.Sub(v, vars.NewVariableFromInt(28)))
+		kind = api.Mul(api.Mul(kind, api.Sub(v, vars.NewVariableFromInt(31))), api.Sub(v, vars.NewVariableFromInt(32)))
+		api.AssertIsEqual(api.Mul(used, kind), vars.NewVariableFromInt(0))
+		if !config.ApprovedHashes {
+			api.AssertIsEqual(api.Mul(used, isApproved.Value), vars.NewVariableFromInt(0))
+		}
+
+		// Unused signatures and approved hashes recover a constant signature.
+		isECDSA := vars.Bool{Value: api.Mul(used, api.Sub(one, isApproved.Value))}
+		digest := api.SelectBytes32(vars.Bool{Value: isEthSign}, ethSignHash, hash)
+		digest = api.SelectBytes32(isECDSA, digest, dummyHash)
+		recoveryID := api.Select(isECDSA, api.Sub(v, api.Mul(isEthSign, vars.NewVariableFromInt(4))), dummyV)
+		r := api.SelectBytes32(isECDSA, signature.R, dummyR)
+		s := api.SelectBytes32(isECDSA, signature.S, dummyS)
+		x, y := compat.ECRecover(api, digest, recoveryID, r, s)
+		key := keccak256.Hash(api, append(x[:], y[:]...))
+		signer := api.Select(isApproved, api.ToVariableFromBytesBE(signature.R[12:]), api.ToVariableFromBytesBE(key[12:]))
+
+		// The signers are distinct owners in ascending order.
+		next := api.Add(last, one)
+		api.AssertIsLessOrEqual(next, api.Select(vars.Bool{Value: used}, signer, next))
+		last = api.Select(vars.Bool{Value: used}, signer, last)
+		isOwner := vars.NewVariableFromInt(0)
+		active := one
+		for j := 0; j < len(owners); j++ {
+			if j > 0 {
+				active = api.Sub(active, api.IsZero(api.Sub(c.NbOwners, vars.NewVariableFromInt(j))).Value)
+			}
+			isOwner = api.Add(isOwner, api.Mul(active, api.IsZero(api.Sub(owners[j], signer)).Value))
+		}
+		api.AssertIsEqual(api.Mul(used, api.Sub(one, isOwner)), vars.NewVariableFromInt(0))
+
+		// Approved hashes are proven against the storage of the Safe, and other signatures prove
+		// the threshold slot instead.
+		if config.ApprovedHashes {
+			var owner [20]vars.Byte
+			copy(owner[:], signature.R[12:])
+			approvals := st.MappingSlot(storage.ConstantSlot(big.NewInt(approvedHashesSlot)), storage.AddressWord(owner))
+			isApprovedUsed := vars.Bool{Value: api.Mul(used, isApproved.Value)}
+			slot := api.SelectBytes32(isApprovedUsed, st.MappingSlot(approvals, hash), storage.ConstantSlot(big.NewInt(thresholdSlot)))
+			value := st.VerifyStorage(account.StorageRoot, slot, c.Proofs[0].StorageProofs[i])
+			sum := vars.NewVariableFromInt(0)
+			for j := 0; j < 32; j++ {
+				sum = api.Add(sum, value[j].Value)
+			}
+			api.AssertIsEqual(api.Mul(isApprovedUsed.Value, api.IsZero(sum).Value), vars.NewVariableFromInt(0))
+		}
+	}
+}
+
+// A valid signature of a constant hash, recovered in place of unused signatures and approved
+// hashes.
+var (
+	dummyDigest    = crypto.Keccak256([]byte("safe"))
+	dummySignature = func() []byte {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{1}, 32))
+		if err != nil {
+			panic(err)
+		}
+		signature, err := crypto.Sign(dummyDigest, key)
+		if err != nil {
+			panic(err)
+		}
+		signature[64] += 27
+		return signature
+	}()
+)
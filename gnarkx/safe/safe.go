@@ -0,0 +1,197 @@
+// A circuit template verifying that a Safe (formerly Gnosis Safe) multisig transaction is approved
+// by its owners, for proving off-chain multisig approvals to other chains or apps.
+//
+// The input is the address of the Safe and the hash of its owners and threshold, followed by a
+// block hash if approved hashes are enabled, and the output is the EIP-712 hash of the transaction.
+// The hash of the owners is keccak256(abi.encodePacked(getThreshold(), getOwners())). As in
+// checkNSignatures, the first threshold signatures must be by distinct owners in ascending order
+// of address, and each is either an ECDSA signature of the transaction hash, an eth_sign signature
+// with v + 4, or, for v = 1, an approval of the hash by the owner in the r word, stored in the
+// approvedHashes mapping of the Safe at the block. Contract signatures with v = 0 are not
+// supported, and ECDSA signatures must have s in the lower half. Reference:
+// https://github.com/safe-global/safe-smart-account/blob/main/contracts/Safe.sol
+package safe
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The EIP-712 types of Safe transactions since version 1.3.0.
+const (
+	domainType      = "EIP712Domain(uint256 chainId,address verifyingContract)"
+	transactionType = "SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas," +
+		"uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"
+)
+
+// The storage slots of the threshold and of the approvedHashes mapping of the Safe, and the length
+// of a signature r || s || v.
+const (
+	thresholdSlot      = 4
+	approvedHashesSlot = 8
+	SignatureLength    = 65
+)
+
+// Config describes the Safes and transactions a circuit accepts.
+type Config struct {
+	// The chain of the Safes.
+	ChainID *big.Int
+
+	// The maximum number of owners and threshold, and the maximum length of the data of a
+	// transaction.
+	MaxOwners     int
+	MaxThreshold  int
+	MaxDataLength int
+
+	// Whether signatures may be hashes approved on-chain, which are proven against a block, and the
+	// shape of the proofs.
+	ApprovedHashes  bool
+	MaxHeaderLength int
+	MaxDepth        int
+	MaxNodeLength   int
+}
+
+// A Safe transaction, where the operation is 0 for a call and 1 for a delegate call.
+type Transaction struct {
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      uint8
+	SafeTxGas      *big.Int
+	BaseGas        *big.Int
+	GasPrice       *big.Int
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Nonce          *big.Int
+}
+
+// Returns the input of the circuit, where the block hash is only used for approved hashes.
+func (c *Config) Input(safe common.Address, ownersHash common.Hash, blockHash common.Hash) []byte {
+	input := append(safe.Bytes(), ownersHash.Bytes()...)
+	if c.ApprovedHashes {
+		input = append(input, blockHash.Bytes()...)
+	}
+	return input
+}
+
+// Returns the hash of the threshold and the owners, in the order of getOwners.
+func OwnersHash(threshold uint64, owners []common.Address) common.Hash {
+	return crypto.Keccak256Hash(ownersPreimage(threshold, owners))
+}
+
+func ownersPreimage(threshold uint64, owners []common.Address) []byte {
+	preimage := common.LeftPadBytes(new(big.Int).SetUint64(threshold).Bytes(), 32)
+	for _, owner := range owners {
+		preimage = append(preimage, common.LeftPadBytes(owner.Bytes(), 32)...)
+	}
+	return preimage
+}
+
+// Returns the EIP-712 hash of a transaction of the Safe, which its owners sign.
+func (c *Config) TransactionHash(safe common.Address, tx *Transaction) common.Hash {
+	domain := crypto.Keccak256Hash(
+		crypto.Keccak256([]byte(domainType)),
+		math.U256Bytes(new(big.Int).Set(c.ChainID)),
+		common.LeftPadBytes(safe.Bytes(), 32),
+	)
+	structHash := crypto.Keccak256Hash(
+		crypto.Keccak256([]byte(transactionType)),
+		common.LeftPadBytes(tx.To.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(tx.Value)),
+		crypto.Keccak256(tx.Data),
+		common.LeftPadBytes([]byte{tx.Operation}, 32),
+		math.U256Bytes(new(big.Int).Set(tx.SafeTxGas)),
+		math.U256Bytes(new(big.Int).Set(tx.BaseGas)),
+		math.U256Bytes(new(big.Int).Set(tx.GasPrice)),
+		common.LeftPadBytes(tx.GasToken.Bytes(), 32),
+		common.LeftPadBytes(tx.RefundReceiver.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(tx.Nonce)),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], structHash[:])
+}
+
+// Returns the slot of approvedHashes[owner][hash].
+func ApprovedHashSlot(owner common.Address, hash common.Hash) common.Hash {
+	slot := common.BigToHash(big.NewInt(approvedHashesSlot))
+	inner := crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), slot[:])
+	return crypto.Keccak256Hash(hash[:], inner[:])
+}
+
+// Returns the storage slots whose proofs the circuit reads for the first threshold signatures of
+// the transaction hash, one per signature up to MaxThreshold: the slot of the approval for approved
+// hashes, and the slot of the threshold, which is never empty, for other and unused signatures.
+func (c *Config) Slots(hash common.Hash, threshold uint64, signatures []byte) []common.Hash {
+	slots := make([]common.Hash, c.MaxThreshold)
+	for i := 0; i < len(slots); i++ {
+		slots[i] = common.BigToHash(big.NewInt(thresholdSlot))
+		if i < int(threshold) && (i+1)*SignatureLength <= len(signatures) && signatures[i*SignatureLength+64] == 1 {
+			owner := common.BytesToAddress(signatures[i*SignatureLength+12 : i*SignatureLength+32])
+			slots[i] = ApprovedHashSlot(owner, hash)
+		}
+	}
+	return slots
+}
+
+// Returns the signer of a signature of the transaction hash, which is the owner in the r word of
+// an approved hash.
+func signer(hash common.Hash, signature []byte) (common.Address, error) {
+	v := signature[64]
+	digest := hash[:]
+	switch {
+	case v == 1:
+		return common.BytesToAddress(signature[12:32]), nil
+	case v == 31 || v == 32:
+		digest = crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), hash[:])
+		v -= 4
+	case v != 27 && v != 28:
+		return common.Address{}, fmt.Errorf("unsupported signature type %d", v)
+	}
+	sig := append(append([]byte{}, signature[:64]...), v-27)
+	key, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
+
+// Checks the signatures of the transaction hash as checkNSignatures does, except for approvals,
+// which are only checked by the circuit.
+func (c *Config) checkSignatures(hash common.Hash, threshold uint64, owners []common.Address, signatures []byte) error {
+	if threshold == 0 || threshold > uint64(len(owners)) || threshold > uint64(c.MaxThreshold) {
+		return fmt.Errorf("threshold %d of %d owners", threshold, len(owners))
+	}
+	if len(owners) > c.MaxOwners {
+		return fmt.Errorf("%d owners, expected at most %d", len(owners), c.MaxOwners)
+	}
+	if len(signatures) < int(threshold)*SignatureLength {
+		return fmt.Errorf("%d bytes of signatures for a threshold of %d", len(signatures), threshold)
+	}
+	var last common.Address
+	for i := 0; i < int(threshold); i++ {
+		signature := signatures[i*SignatureLength : (i+1)*SignatureLength]
+		if signature[64] == 1 && !c.ApprovedHashes {
+			return fmt.Errorf("signature %d is an approved hash", i)
+		}
+		owner, err := signer(hash, signature)
+		if err != nil {
+			return fmt.Errorf("signature %d: %w", i, err)
+		}
+		if bytes.Compare(owner[:], last[:]) <= 0 {
+			return fmt.Errorf("signature %d: signers are not in ascending order", i)
+		}
+		isOwner := false
+		for _, o := range owners {
+			isOwner = isOwner || o == owner
+		}
+		if !isOwner {
+			return fmt.Errorf("signature %d: %s is not an owner", i, owner.Hex())
+		}
+		last = owner
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package safe
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	safeAddress = common.HexToAddress("0x4242424242424242424242424242424242424242")
+	transaction = &Transaction{
+		To:        common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Value:     big.NewInt(1e18),
+		Data:      []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01, 0x02},
+		SafeTxGas: big.NewInt(0),
+		BaseGas:   big.NewInt(0),
+		GasPrice:  big.NewInt(0),
+		Nonce:     big.NewInt(7),
+	}
+)
+
+func newConfig(approvedHashes bool) *Config {
+	return &Config{
+		ChainID:         big.NewInt(1),
+		MaxOwners:       3,
+		MaxThreshold:    2,
+		MaxDataLength:   64,
+		ApprovedHashes:  approvedHashes,
+		MaxHeaderLength: eth.MaxHeaderLength,
+		MaxDepth:        4,
+		MaxNodeLength:   eth.MaxNodeLength,
+	}
+}
+
+// Returns the keys of three owners sorted by address.
+func newOwners(t *testing.T) ([]*ecdsa.PrivateKey, []common.Address) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{byte(i + 2)}, 32))
+		assert.NoError(t, err)
+		keys[i] = key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(keys[i].PublicKey), crypto.PubkeyToAddress(keys[j].PublicKey)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	owners := make([]common.Address, len(keys))
+	for i := 0; i < len(keys); i++ {
+		owners[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	return keys, owners
+}
+
+// Returns the signature of the hash by the key, as an eth_sign signature if ethSign is set.
+func sign(t *testing.T, hash common.Hash, key *ecdsa.PrivateKey, ethSign bool) []byte {
+	digest := hash[:]
+	v := byte(27)
+	if ethSign {
+		digest = crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), hash[:])
+		v = 31
+	}
+	signature, err := crypto.Sign(digest, key)
+	assert.NoError(t, err)
+	signature[64] += v
+	return signature
+}
+
+func TestSignatures(t *testing.T) {
+	config := newConfig(false)
+	keys, owners := newOwners(t)
+	hash := config.TransactionHash(safeAddress, transaction)
+	input := config.Input(safeAddress, OwnersHash(2, owners), common.Hash{})
+
+	// The first and third owners sign, one of them with eth_sign.
+	signatures := append(sign(t, hash, keys[0], false), sign(t, hash, keys[2], true)...)
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetTransaction(safeAddress, transaction, 2, owners, signatures))
+	assert.NoError(t, functiontest.IsSolved(circuit, input))
+	outputs := *circuit.GetOutputBytes()
+	for i := 0; i < 32; i++ {
+		assert.Equal(t, hash[i], outputs[i].GetValueUnsafe())
+	}
+
+	// A threshold of one only reads the first signature.
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetTransaction(safeAddress, transaction, 1, owners, signatures[:SignatureLength]))
+	assert.NoError(t, functiontest.IsSolved(circuit, config.Input(safeAddress, OwnersHash(1, owners), common.Hash{})))
+
+	// The signers must be in ascending order.
+	reversed := append(sign(t, hash, keys[2], false), sign(t, hash, keys[0], false)...)
+	assert.Error(t, circuit.SetTransaction(safeAddress, transaction, 2, owners, reversed))
+	circuit.signatures = reversed
+	circuit.threshold = 2
+	assert.Error(t, functiontest.IsSolved(circuit, input))
+
+	// The signers must be owners.
+	other, err := crypto.ToECDSA(common.LeftPadBytes([]byte{0x99}, 32))
+	assert.NoError(t, err)
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetTransaction(safeAddress, transaction, 2, owners, signatures))
+	circuit.owners = []common.Address{owners[0], owners[1], crypto.PubkeyToAddress(other.PublicKey)}
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(safeAddress, OwnersHash(2, circuit.owners), common.Hash{})))
+}
+
+func TestApprovedHashes(t *testing.T) {
+	config := newConfig(true)
+	keys, owners := newOwners(t)
+	hash := config.TransactionHash(safeAddress, transaction)
+
+	// The first owner approved the hash on-chain and the second one signs.
+	approval := append(append(common.LeftPadBytes(owners[0].Bytes(), 32), make([]byte, 32)...), 1)
+	signatures := append(approval, sign(t, hash, keys[1], false)...)
+	newCircuit := func(approved bool) (*Circuit, []byte) {
+		storage := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+		words := map[common.Hash]*big.Int{common.BigToHash(big.NewInt(thresholdSlot)): big.NewInt(2)}
+		if approved {
+			words[ApprovedHashSlot(owners[0], hash)] = big.NewInt(1)
+		}
+		for i := 0; i < 10; i++ {
+			words[common.BigToHash(big.NewInt(int64(100+i)))] = big.NewInt(int64(i + 1))
+		}
+		values := make(map[common.Hash][]byte)
+		for slot, word := range words {
+			value, err := rlp.EncodeToBytes(word)
+			assert.NoError(t, err)
+			storage.MustUpdate(crypto.Keccak256(slot[:]), value)
+			values[slot] = value
+		}
+		accounts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+		account, err := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(0), storage.Hash(), types.EmptyCodeHash})
+		assert.NoError(t, err)
+		accounts.MustUpdate(crypto.Keccak256(safeAddress.Bytes()), account)
+		for i := 0; i < 10; i++ {
+			encoded, err := rlp.EncodeToBytes([]interface{}{uint64(0), big.NewInt(int64(i)), types.EmptyRootHash, types.EmptyCodeHash})
+			assert.NoError(t, err)
+			accounts.MustUpdate(crypto.Keccak256(common.BigToAddress(big.NewInt(int64(i))).Bytes()), encoded)
+		}
+		header := eth.NewHeader(config.MaxHeaderLength)
+		assert.NoError(t, header.Set(&types.Header{
+			Root:       accounts.Hash(),
+			Difficulty: big.NewInt(0),
+			Number:     big.NewInt(18000000),
+			GasLimit:   30000000,
+			Time:       1750000000,
+			BaseFee:    big.NewInt(20e9),
+		}))
+
+		// Without the approval, only the proof of another slot can be given.
+		var storageProofs []*eth.MPTProof
+		for _, slot := range config.Slots(hash, 2, signatures) {
+			value, ok := values[slot]
+			if !ok {
+				slot = common.BigToHash(big.NewInt(thresholdSlot))
+				value = values[slot]
+			}
+			storageProofs = append(storageProofs, functiontest.Prove(t, storage, slot[:], value, 4, eth.MaxNodeLength, eth.MaxStorageValueLength))
+		}
+		circuit := NewCircuit(config)
+		assert.NoError(t, circuit.SetTransaction(safeAddress, transaction, 2, owners, signatures))
+		assert.NoError(t, circuit.SetProofs(&header, functiontest.Prove(t, accounts, safeAddress.Bytes(), account, 4, eth.MaxNodeLength, eth.MaxAccountLength), storageProofs))
+		return circuit, config.Input(safeAddress, OwnersHash(2, owners), common.BytesToHash(vars.GetValuesUnsafe(header.Hash[:])))
+	}
+
+	circuit, input := newCircuit(true)
+	assert.NoError(t, functiontest.IsSolved(circuit, input))
+
+	circuit, input = newCircuit(false)
+	assert.Error(t, functiontest.IsSolved(circuit, input))
+}
@@ -0,0 +1,295 @@
+// Bindings of the inputs and the outputs of circuits to Go structs whose fields are tagged with
+// their Solidity types, so that the reading of the input bytes, the writing of the output bytes,
+// the witness and the Solidity code of the caller all follow from a single definition:
+//
+//	type Input struct {
+//		Number vars.U64        `succinct:"uint64"`
+//		Hashes [][32]vars.Byte `succinct:"bytes32[]"`
+//	}
+//
+// The input is encoded as abi.encodePacked of the fields in order, as read by builder.InputReader,
+// and the output as abi.encode of the fields in order, as written by builder.NewABIOutputWriter,
+// so that the callback can abi.decode it. The supported tags and the types of their fields are:
+//   - uint64: vars.U64
+//   - address: [20]vars.Byte
+//   - bytes32: [32]vars.Byte
+//   - bytes32[]: [][32]vars.Byte
+//   - bytes: []vars.Byte
+//
+// The lengths of the dynamic fields are constants of the circuit, which are the lengths of the
+// slices of the struct when the circuit is created. Fields without the tag are ignored.
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The name of the struct tag of the fields.
+const tagName = "succinct"
+
+var (
+	u64Type     = reflect.TypeOf(vars.U64{})
+	addressType = reflect.TypeOf([20]vars.Byte{})
+	bytes32Type = reflect.TypeOf([32]vars.Byte{})
+	arrayType   = reflect.TypeOf([][32]vars.Byte{})
+	bytesType   = reflect.TypeOf([]vars.Byte{})
+)
+
+// The Go types of the fields of each tag.
+var tagTypes = map[string]reflect.Type{
+	"uint64":    u64Type,
+	"address":   addressType,
+	"bytes32":   bytes32Type,
+	"bytes32[]": arrayType,
+	"bytes":     bytesType,
+}
+
+// A tagged field of a struct.
+type field struct {
+	name  string
+	tag   string
+	value reflect.Value
+}
+
+// Returns the tagged fields of a pointer to a struct, in order.
+func fields(v interface{}) []field {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("%T is not a pointer to a struct", v))
+	}
+	value = value.Elem()
+	var out []field
+	for i := 0; i < value.NumField(); i++ {
+		f := value.Type().Field(i)
+		tag, ok := f.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		typ, ok := tagTypes[tag]
+		if !ok {
+			panic(fmt.Sprintf("field %s has unsupported type %s", f.Name, tag))
+		}
+		if f.Type != typ {
+			panic(fmt.Sprintf("field %s of type %s must be a %s", f.Name, tag, typ))
+		}
+		out = append(out, field{name: f.Name, tag: tag, value: value.Field(i)})
+	}
+	return out
+}
+
+// Returns the length of the packed encoding of the field, or of its encoding in the tail of the
+// ABI encoding for dynamic fields.
+func (f *field) length() int {
+	switch f.tag {
+	case "uint64":
+		return 8
+	case "address":
+		return 20
+	case "bytes32":
+		return 32
+	case "bytes32[]":
+		return 32 * f.value.Len()
+	}
+	return f.value.Len()
+}
+
+// Returns whether the field is dynamic in the ABI encoding.
+func (f *field) isDynamic() bool {
+	return f.tag == "bytes32[]" || f.tag == "bytes"
+}
+
+// Returns the length of the packed input of the struct.
+func InputLength(v interface{}) int {
+	length := 0
+	for _, f := range fields(v) {
+		length += f.length()
+	}
+	return length
+}
+
+// Returns the length of the ABI encoded output of the struct.
+func OutputLength(v interface{}) int {
+	length := 0
+	for _, f := range fields(v) {
+		length += 32
+		if f.isDynamic() {
+			length += 32 + (f.length()+31)/32*32
+		}
+	}
+	return length
+}
+
+// Reads the fields of the struct from the input bytes in the circuit.
+func Read(api builder.API, inputBytes []vars.Byte, v interface{}) {
+	if len(inputBytes) != InputLength(v) {
+		panic(fmt.Sprintf("%d input bytes, expected %d", len(inputBytes), InputLength(v)))
+	}
+	reader := builder.NewInputReader(api, inputBytes)
+	for _, f := range fields(v) {
+		switch f.tag {
+		case "uint64":
+			f.value.Set(reflect.ValueOf(reader.ReadUint64()))
+		case "address":
+			f.value.Set(reflect.ValueOf(reader.ReadAddress()))
+		case "bytes32":
+			f.value.Set(reflect.ValueOf(reader.ReadBytes32()))
+		case "bytes32[]":
+			for i := 0; i < f.value.Len(); i++ {
+				f.value.Index(i).Set(reflect.ValueOf(reader.ReadBytes32()))
+			}
+		case "bytes":
+			f.value.Set(reflect.ValueOf(reader.ReadBytes(f.value.Len())))
+		}
+	}
+}
+
+// Writes the fields of the struct to the output bytes in the circuit.
+func Write(api builder.API, v interface{}, outputBytes []vars.Byte) {
+	writer := builder.NewABIOutputWriter(api)
+	for _, f := range fields(v) {
+		switch x := f.value.Interface().(type) {
+		case vars.U64:
+			writer.WriteU64(x)
+		case [20]vars.Byte:
+			writer.WriteAddress(x)
+		case [32]vars.Byte:
+			writer.WriteBytes32(x)
+		case [][32]vars.Byte:
+			writer.WriteBytes32Array(x)
+		case []vars.Byte:
+			writer.WriteBytes(x)
+		}
+	}
+	writer.Close(outputBytes)
+}
+
+// Sets the fields of the struct to the values of the packed input bytes, for the witness.
+func SetInput(v interface{}, inputBytes []byte) error {
+	if len(inputBytes) != InputLength(v) {
+		return fmt.Errorf("%d input bytes, expected %d", len(inputBytes), InputLength(v))
+	}
+	for _, f := range fields(v) {
+		data := inputBytes[:f.length()]
+		inputBytes = inputBytes[f.length():]
+		if f.tag == "uint64" {
+			f.value.Set(reflect.ValueOf(vars.U64{Value: vars.Variable{Value: new(big.Int).SetBytes(data)}}))
+			continue
+		}
+		setBytes(f.value, data)
+	}
+	return nil
+}
+
+// Sets the bytes of an array or a slice of bytes or of bytes32.
+func setBytes(value reflect.Value, data []byte) {
+	if value.Type() == arrayType {
+		for i := 0; i < value.Len(); i++ {
+			setBytes(value.Index(i), data[32*i:32*(i+1)])
+		}
+		return
+	}
+	for i := 0; i < value.Len(); i++ {
+		value.Index(i).Set(reflect.ValueOf(vars.Byte{Value: vars.NewVariableFromInt(int(data[i]))}))
+	}
+}
+
+// Returns the ABI encoded output of the values of the fields of the struct, for the witness.
+func OutputBytes(v interface{}) []byte {
+	var head, tail []byte
+	fs := fields(v)
+	for _, f := range fs {
+		data := getBytes(f.value)
+		if f.tag == "uint64" {
+			data = new(big.Int).SetUint64(getUint64(f.value.Interface().(vars.U64))).FillBytes(make([]byte, 8))
+		}
+		if !f.isDynamic() {
+			head = append(head, make([]byte, 32-len(data))...)
+			head = append(head, data...)
+			continue
+		}
+		head = append(head, word(32*len(fs)+len(tail))...)
+		tail = append(tail, word(f.value.Len())...)
+		tail = append(tail, data...)
+		tail = append(tail, make([]byte, (32-len(data)%32)%32)...)
+	}
+	return append(head, tail...)
+}
+
+// Returns the bytes of an array or a slice of bytes or of bytes32.
+func getBytes(value reflect.Value) []byte {
+	var out []byte
+	if value.Kind() != reflect.Array && value.Kind() != reflect.Slice {
+		return nil
+	}
+	for i := 0; i < value.Len(); i++ {
+		switch x := value.Index(i).Interface().(type) {
+		case vars.Byte:
+			out = append(out, x.GetValueUnsafe())
+		case [32]vars.Byte:
+			out = append(out, getBytes(reflect.ValueOf(x))...)
+		}
+	}
+	return out
+}
+
+func getUint64(u vars.U64) uint64 {
+	switch x := u.Value.Value.(type) {
+	case int:
+		return uint64(x)
+	case uint64:
+		return x
+	case *big.Int:
+		return x.Uint64()
+	}
+	panic(fmt.Sprintf("value of type %T is not a uint64", u.Value.Value))
+}
+
+func word(n int) []byte {
+	return big.NewInt(int64(n)).FillBytes(make([]byte, 32))
+}
+
+// Returns a Solidity library for the caller of a circuit, with structs of the input and the output
+// and functions that encode the input and decode the output.
+func Solidity(name string, input interface{}, output interface{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "library %sSchema {\n", name)
+	writeStruct(&b, name+"Input", fields(input))
+	writeStruct(&b, name+"Output", fields(output))
+
+	var args []string
+	for _, f := range fields(input) {
+		args = append(args, "input."+solidityName(f.name))
+	}
+	fmt.Fprintf(&b, "    function encodeInput(%sInput memory input) internal pure returns (bytes memory) {\n", name)
+	fmt.Fprintf(&b, "        return abi.encodePacked(%s);\n", strings.Join(args, ", "))
+	b.WriteString("    }\n\n")
+
+	var results, types []string
+	for _, f := range fields(output) {
+		results = append(results, "output."+solidityName(f.name))
+		types = append(types, f.tag)
+	}
+	fmt.Fprintf(&b, "    function decodeOutput(bytes memory data) internal pure returns (%sOutput memory output) {\n", name)
+	fmt.Fprintf(&b, "        (%s) = abi.decode(data, (%s));\n", strings.Join(results, ", "), strings.Join(types, ", "))
+	b.WriteString("    }\n}\n")
+	return b.String()
+}
+
+func writeStruct(b *strings.Builder, name string, fs []field) {
+	fmt.Fprintf(b, "    struct %s {\n", name)
+	for _, f := range fs {
+		fmt.Fprintf(b, "        %s %s;\n", f.tag, solidityName(f.name))
+	}
+	b.WriteString("    }\n\n")
+}
+
+// Returns the name of a field in Solidity, in lower camel case.
+func solidityName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
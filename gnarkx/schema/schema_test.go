@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testInput struct {
+	Number vars.U64        `succinct:"uint64"`
+	Owner  [20]vars.Byte   `succinct:"address"`
+	Hashes [][32]vars.Byte `succinct:"bytes32[]"`
+}
+
+type testOutput struct {
+	Number vars.U64      `succinct:"uint64"`
+	Data   []vars.Byte   `succinct:"bytes"`
+	Last   [32]vars.Byte `succinct:"bytes32"`
+	Owner  [20]vars.Byte `succinct:"address"`
+}
+
+// The circuit outputs the number, the first bytes of the owner, the last hash and the owner.
+type testCircuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+	input       testInput  `gnark:"-"`
+	output      testOutput `gnark:"-"`
+}
+
+func newTestCircuit() *testCircuit {
+	input := testInput{Hashes: vars.NewBytes32Array(3)}
+	output := testOutput{Data: vars.NewBytes(5)}
+	return &testCircuit{
+		InputBytes:  vars.NewBytes(InputLength(&input)),
+		OutputBytes: vars.NewBytes(OutputLength(&output)),
+		input:       input,
+		output:      output,
+	}
+}
+
+func (c *testCircuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *testCircuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *testCircuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+func (c *testCircuit) SetWitness(inputBytes []byte) {
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	if err := SetInput(&c.input, inputBytes); err != nil {
+		panic(err)
+	}
+	c.compute()
+	vars.SetBytes(&c.OutputBytes, OutputBytes(&c.output))
+}
+
+func (c *testCircuit) compute() {
+	c.output.Number = c.input.Number
+	copy(c.output.Data, c.input.Owner[:5])
+	c.output.Last = c.input.Hashes[len(c.input.Hashes)-1]
+	c.output.Owner = c.input.Owner
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	Read(*api, c.InputBytes, &c.input)
+	c.compute()
+	Write(*api, &c.output, c.OutputBytes)
+	return nil
+}
+
+func TestSchema(t *testing.T) {
+	owner := common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa")
+	hashes := [][32]byte{common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")}
+	input := append(binary.BigEndian.AppendUint64(nil, 18000000), owner.Bytes()...)
+	for _, hash := range hashes {
+		input = append(input, hash[:]...)
+	}
+
+	circuit := newTestCircuit()
+	assert.Equal(t, len(input), len(circuit.InputBytes))
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(input)
+	assert.NoError(t, test.IsSolved(&function, &function, ecc.BN254.ScalarField()))
+
+	// The output decodes as abi.decode(output, (uint64, bytes, bytes32, address)).
+	var arguments abi.Arguments
+	for _, name := range []string{"uint64", "bytes", "bytes32", "address"} {
+		typ, err := abi.NewType(name, "", nil)
+		assert.NoError(t, err)
+		arguments = append(arguments, abi.Argument{Type: typ})
+	}
+	values, err := arguments.Unpack(vars.GetValuesUnsafe(circuit.OutputBytes))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{uint64(18000000), owner.Bytes()[:5], hashes[2], owner}, values)
+
+	assert.Error(t, SetInput(&circuit.input, input[1:]))
+	assert.Panics(t, func() {
+		InputLength(&struct {
+			Number vars.U64 `succinct:"uint256"`
+		}{})
+	})
+	assert.Panics(t, func() {
+		InputLength(&struct {
+			Number [32]vars.Byte `succinct:"uint64"`
+		}{})
+	})
+}
+
+func TestSolidity(t *testing.T) {
+	circuit := newTestCircuit()
+	expected := `library TestSchema {
+    struct TestInput {
+        uint64 number;
+        address owner;
+        bytes32[] hashes;
+    }
+
+    struct TestOutput {
+        uint64 number;
+        bytes data;
+        bytes32 last;
+        address owner;
+    }
+
+    function encodeInput(TestInput memory input) internal pure returns (bytes memory) {
+        return abi.encodePacked(input.number, input.owner, input.hashes);
+    }
+
+    function decodeOutput(bytes memory data) internal pure returns (TestOutput memory output) {
+        (output.number, output.data, output.last, output.owner) = abi.decode(data, (uint64, bytes, bytes32, address));
+    }
+}
+`
+	assert.Equal(t, expected, Solidity("Test", &circuit.input, &circuit.output))
+}
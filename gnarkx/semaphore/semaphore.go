@@ -0,0 +1,112 @@
+// The API for the group membership of Semaphore v4: a member proves that the commitment of its
+// identity is in a group, a lean incremental Merkle tree of the merkle package, and emits the
+// nullifier of a scope, so that circuits can reuse the groups of Semaphore and its nullifiers.
+// Reference: https://github.com/semaphore-protocol/semaphore/blob/main/packages/circuits/src/semaphore.circom
+//
+// The identity of a member is the secret scalar of its EdDSA key on Baby Jubjub, the public key is
+// the multiple of Base8 by the secret, and:
+//   - commitment = poseidon(publicKey.X, publicKey.Y)
+//   - nullifier = poseidon(scope, secret)
+//
+// where the message and the scope of a proof are field elements, usually the Hash of a bytes32 as
+// in the contracts of Semaphore.
+package semaphore
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Returns the public key of the secret of an identity.
+func PublicKey(secret *big.Int) babyjubjub.PointValue {
+	return babyjubjub.MulBase(secret)
+}
+
+// Returns the commitment of the secret of an identity.
+func IdentityCommitment(secret *big.Int) *big.Int {
+	publicKey := PublicKey(secret)
+	return poseidon.HashValues(publicKey.X, publicKey.Y)
+}
+
+// Returns the nullifier of the secret of an identity for the scope.
+func Nullifier(scope *big.Int, secret *big.Int) *big.Int {
+	return poseidon.HashValues(scope, secret)
+}
+
+// Returns the hash of a message or of a scope as in Semaphore, keccak256(value) >> 8, which is an
+// element of the scalar field.
+func Hash(value [32]byte) *big.Int {
+	digest := crypto.Keccak256(value[:])
+	return new(big.Int).SetBytes(digest[:31])
+}
+
+// The identity of a member of a group and the proof of its commitment in the group.
+type Member struct {
+	Secret      vars.Variable
+	MerkleProof merkle.LeanProof
+}
+
+// Creates a new member of groups of at most the depth.
+func NewMember(maxDepth int) Member {
+	return Member{Secret: vars.NewVariable(), MerkleProof: merkle.NewLeanProof(maxDepth)}
+}
+
+// Sets the member of the secret, whose commitment is the leaf at the index of the group.
+func (m *Member) Set(secret *big.Int, group *merkle.LeanTree, index int) error {
+	if secret.Sign() < 0 || secret.Cmp(babyjubjub.Order) >= 0 {
+		return fmt.Errorf("secret is not a scalar of Baby Jubjub")
+	}
+	m.Secret.Set(secret)
+	return m.MerkleProof.Set(group, index)
+}
+
+// SemaphoreAPI is a wrapper around succinct.API that provides methods for Semaphore.
+type SemaphoreAPI struct {
+	api builder.API
+}
+
+// Creates a new SemaphoreAPI.
+func NewAPI(api *builder.API) *SemaphoreAPI {
+	return &SemaphoreAPI{api: *api}
+}
+
+// Returns the commitment of the secret of an identity, which is checked to be less than the order
+// of Base8 so that a public key has a single secret.
+func (a *SemaphoreAPI) IdentityCommitment(secret vars.Variable) vars.Variable {
+	api := a.api
+	api.AssertIsLessOrEqual(secret, vars.Variable{Value: new(big.Int).Sub(babyjubjub.Order, big.NewInt(1))})
+	publicKey := babyjubjub.NewAPI(&api).ScalarMulBase(secret)
+	return poseidon.Hash(api, []vars.Variable{publicKey.X, publicKey.Y})
+}
+
+// Returns the nullifier of the secret of an identity for the scope.
+func (a *SemaphoreAPI) Nullifier(scope vars.Variable, secret vars.Variable) vars.Variable {
+	return poseidon.Hash(a.api, []vars.Variable{scope, secret})
+}
+
+// Returns the hash of a message or of a scope as in Semaphore.
+func (a *SemaphoreAPI) Hash(value [32]vars.Byte) vars.Variable {
+	api := a.api
+	digest := keccak256.Hash(api, value[:])
+	hash := vars.NewVariableFromInt(0)
+	for i := 0; i < 31; i++ {
+		hash = api.Add(api.Mul(hash, vars.NewVariableFromInt(256)), digest[i].Value)
+	}
+	return hash
+}
+
+// Verifies the member and returns the root of its group and its nullifier for the scope, as the
+// outputs of the circuit of Semaphore, which the caller checks or exposes with the message.
+func (a *SemaphoreAPI) VerifyMember(member Member, scope vars.Variable) (vars.Variable, vars.Variable) {
+	commitment := a.IdentityCommitment(member.Secret)
+	root := merkle.NewAPI(&a.api).LeanRoot(commitment, member.MerkleProof)
+	return root, a.Nullifier(scope, member.Secret)
+}
@@ -0,0 +1,78 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Member    Member
+	Scope     vars.Variable
+	Message   [32]vars.Byte
+	Root      vars.Variable
+	Nullifier vars.Variable
+	Hash      vars.Variable
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	semaphoreAPI := NewAPI(api)
+	root, nullifier := semaphoreAPI.VerifyMember(c.Member, c.Scope)
+	api.AssertIsEqual(root, c.Root)
+	api.AssertIsEqual(nullifier, c.Nullifier)
+	api.AssertIsEqual(semaphoreAPI.Hash(c.Message), c.Hash)
+	return nil
+}
+
+// Returns the secrets of the members of a group and its tree.
+func newGroup(t *testing.T) ([]*big.Int, *merkle.LeanTree) {
+	secrets := make([]*big.Int, 3)
+	commitments := make([]*big.Int, len(secrets))
+	for i := range secrets {
+		secrets[i] = new(big.Int).Mod(new(big.Int).SetBytes(crypto.Keccak256([]byte{byte(i)})), babyjubjub.Order)
+		commitments[i] = IdentityCommitment(secrets[i])
+	}
+	group, err := merkle.NewLeanTree(commitments)
+	assert.NoError(t, err)
+	return secrets, group
+}
+
+func TestVerifyMember(t *testing.T) {
+	secrets, group := newGroup(t)
+	scope := Hash(common.BigToHash(big.NewInt(42)))
+	message := common.BigToHash(big.NewInt(7))
+	newAssignment := func(secret *big.Int, index int) *testCircuit {
+		assignment := &testCircuit{Member: NewMember(4), Scope: vars.Variable{Value: scope}}
+		assert.NoError(t, assignment.Member.Set(secrets[index], group, index))
+		assignment.Member.Secret.Set(secret)
+		vars.SetBytes32(&assignment.Message, message)
+		assignment.Root.Set(group.Root())
+		assignment.Nullifier.Set(Nullifier(scope, secret))
+		assignment.Hash.Set(Hash(message))
+		return assignment
+	}
+	circuit := &testCircuit{Member: NewMember(4)}
+	assert.NoError(t, test.IsSolved(circuit, newAssignment(secrets[2], 2), ecc.BN254.ScalarField()))
+	assert.NotEqual(t, Nullifier(scope, secrets[1]), Nullifier(scope, secrets[2]))
+
+	// The member must be of the commitment at the index.
+	assert.Error(t, test.IsSolved(circuit, newAssignment(secrets[1], 2), ecc.BN254.ScalarField()))
+
+	// A secret greater than the order has the commitment of another secret but not its nullifier.
+	aliased := new(big.Int).Add(secrets[2], babyjubjub.Order)
+	assert.Equal(t, IdentityCommitment(secrets[2]), IdentityCommitment(aliased))
+	assert.Error(t, test.IsSolved(circuit, newAssignment(aliased, 2), ecc.BN254.ScalarField()))
+	member := NewMember(4)
+	assert.Error(t, member.Set(aliased, group, 2))
+}
@@ -0,0 +1,260 @@
+package shielded
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/eddsa"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Config describes the number of notes of a transfer, the depth of the tree and the value logic.
+type Config struct {
+	NbSpent   int
+	NbCreated int
+	MaxDepth  int
+	Logic     ValueLogic
+}
+
+// A note to spend with the public spending key and the nullifying key of its owner, its index in
+// the tree, which is ignored for notes of zero value, and the signature of the transfer.
+type Spend struct {
+	SpendingKey   babyjubjub.PointValue
+	NullifyingKey *big.Int
+	Note          NoteValue
+	Index         int
+	Signature     eddsa.SignatureValue
+}
+
+// Returns the number of elements of the signing message.
+func (c *Config) messageLength() int {
+	return 1 + c.NbSpent + c.NbCreated + (c.Logic.PublicLength()+chunkLength-1)/chunkLength
+}
+
+// Returns the input of the circuit.
+func (c *Config) Input(root *big.Int, public []byte) []byte {
+	return append(root.FillBytes(make([]byte, 32)), public...)
+}
+
+// Circuit proves a transfer of notes of a tree.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Spent   []SpentNote
+	Proofs  []merkle.LeanProof
+	Created []CreatedNote
+
+	config  *Config          `gnark:"-"`
+	tree    *merkle.LeanTree `gnark:"-"`
+	spent   []Spend          `gnark:"-"`
+	created []NoteValue      `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.NbSpent < 1 || config.NbCreated < 0 || config.messageLength() > poseidon.MaxInputs {
+		panic(fmt.Sprintf("unsupported config %+v", *config))
+	}
+	c := &Circuit{
+		InputBytes:  vars.NewBytes(32 + config.Logic.PublicLength()),
+		OutputBytes: vars.NewBytes(32 * (1 + config.NbSpent + config.NbCreated)),
+		Spent:       make([]SpentNote, config.NbSpent),
+		Proofs:      make([]merkle.LeanProof, config.NbSpent),
+		Created:     make([]CreatedNote, config.NbCreated),
+		config:      config,
+	}
+	for i := 0; i < config.NbSpent; i++ {
+		c.Spent[i] = SpentNote{
+			SpendingKey:   babyjubjub.NewPoint(),
+			NullifyingKey: vars.NewVariable(),
+			Value:         vars.NewVariable(),
+			Blinding:      vars.NewVariable(),
+			Signature:     eddsa.NewSignature(),
+		}
+		c.Proofs[i] = merkle.NewLeanProof(config.MaxDepth)
+	}
+	for i := 0; i < config.NbCreated; i++ {
+		c.Created[i] = CreatedNote{Owner: vars.NewVariable(), Value: vars.NewVariable(), Blinding: vars.NewVariable()}
+	}
+	return c
+}
+
+// Returns the nullifiers of the spent notes and the commitments of the created notes.
+func (c *Config) notes(spent []Spend, created []NoteValue) ([]*big.Int, []*big.Int) {
+	nullifiers := make([]*big.Int, len(spent))
+	for i := range spent {
+		nullifiers[i] = spent[i].Note.Nullifier(spent[i].NullifyingKey)
+	}
+	commitments := make([]*big.Int, len(created))
+	for i := range created {
+		commitments[i] = created[i].Commitment()
+	}
+	return nullifiers, commitments
+}
+
+// Returns the message that the owners of the spent notes sign for the transfer.
+func (c *Config) Message(root *big.Int, spent []Spend, created []NoteValue, public []byte) *big.Int {
+	nullifiers, commitments := c.notes(spent, created)
+	return SigningMessage(root, nullifiers, commitments, public)
+}
+
+// Sets the transfer of the spent notes of the tree to the created notes with the public data of
+// the value logic, that the next call to SetWitness assigns.
+func (c *Circuit) SetTransfer(tree *merkle.LeanTree, spent []Spend, created []NoteValue, public []byte) error {
+	config := c.config
+	if len(spent) != config.NbSpent || len(created) != config.NbCreated || len(public) != config.Logic.PublicLength() {
+		return fmt.Errorf("%d spent and %d created notes with %d bytes of public data, expected %d, %d and %d", len(spent), len(created), len(public), config.NbSpent, config.NbCreated, config.Logic.PublicLength())
+	}
+	message := config.Message(tree.Root(), spent, created, public)
+	nullifiers, _ := config.notes(spent, created)
+	spentValues := make([]uint64, len(spent))
+	for i, spend := range spent {
+		for j := 0; j < i; j++ {
+			if nullifiers[i].Cmp(nullifiers[j]) == 0 {
+				return fmt.Errorf("notes %d and %d have the same nullifier", j, i)
+			}
+		}
+		if spend.Note.Owner.Cmp(Owner(spend.SpendingKey, spend.NullifyingKey)) != 0 {
+			return fmt.Errorf("note %d is not of the keys", i)
+		}
+		if !eddsa.Verify(spend.SpendingKey, message, spend.Signature) {
+			return fmt.Errorf("invalid signature of note %d", i)
+		}
+		if spend.Note.Value != 0 {
+			index, siblings, err := tree.Proof(spend.Index)
+			if err != nil {
+				return err
+			}
+			if len(siblings) > config.MaxDepth || merkle.LeanRootValue(spend.Note.Commitment(), index, siblings).Cmp(tree.Root()) != 0 {
+				return fmt.Errorf("note %d is not at index %d of the tree", i, spend.Index)
+			}
+		}
+		spentValues[i] = spend.Note.Value
+	}
+	createdValues := make([]uint64, len(created))
+	for i := range created {
+		createdValues[i] = created[i].Value
+	}
+	if err := config.Logic.Check(spentValues, createdValues, public); err != nil {
+		return err
+	}
+	c.tree = tree
+	c.spent = spent
+	c.created = created
+	return nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the transfer given to SetTransfer.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.tree == nil {
+		panic("transfer must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	for i, spend := range c.spent {
+		c.Spent[i].SpendingKey.Set(spend.SpendingKey)
+		c.Spent[i].NullifyingKey.Set(spend.NullifyingKey)
+		c.Spent[i].Value.Set(new(big.Int).SetUint64(spend.Note.Value))
+		c.Spent[i].Blinding.Set(spend.Note.Blinding)
+		c.Spent[i].Signature.Set(spend.Signature)
+		index := spend.Index
+		if spend.Note.Value == 0 {
+			index = 0
+		}
+		if err := c.Proofs[i].Set(c.tree, index); err != nil {
+			panic(err)
+		}
+	}
+	for i, note := range c.created {
+		c.Created[i].Owner.Set(note.Owner)
+		c.Created[i].Value.Set(new(big.Int).SetUint64(note.Value))
+		c.Created[i].Blinding.Set(note.Blinding)
+	}
+	nullifiers, commitments := c.config.notes(c.spent, c.created)
+	output := append([]byte{}, inputBytes[:32]...)
+	for _, v := range append(nullifiers, commitments...) {
+		output = append(output, v.FillBytes(make([]byte, 32))...)
+	}
+	vars.SetBytes(&c.OutputBytes, output)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	rc := rangecheck.New(api.FrontendAPI())
+	for i := 0; i < len(c.InputBytes); i++ {
+		rc.Check(c.InputBytes[i].Value.Value, 8)
+	}
+	root := api.ToVariableFromBytesBE(c.InputBytes[:32])
+	public := c.InputBytes[32:]
+	zero := vars.NewVariableFromInt(0)
+
+	// The notes of zero value need no proof of membership.
+	merkleAPI := merkle.NewAPI(api)
+	nullifiers := make([]vars.Variable, len(c.Spent))
+	spentValues := make([]vars.Variable, len(c.Spent))
+	for i, note := range c.Spent {
+		api.ToBinaryLE(note.Value, ValueBits)
+		owner := poseidon.Hash(*api, []vars.Variable{note.SpendingKey.X, note.SpendingKey.Y, note.NullifyingKey})
+		commitment := poseidon.Hash(*api, []vars.Variable{owner, note.Value, note.Blinding})
+		isZero := api.IsZero(note.Value)
+		noteRoot := merkleAPI.LeanRoot(commitment, c.Proofs[i])
+		api.AssertIsEqual(api.Select(isZero, zero, api.Sub(noteRoot, root)), zero)
+		nullifiers[i] = poseidon.Hash(*api, []vars.Variable{commitment, note.NullifyingKey})
+		for j := 0; j < i; j++ {
+			api.AssertIsDifferent(nullifiers[i], nullifiers[j])
+		}
+		spentValues[i] = note.Value
+	}
+	commitments := make([]vars.Variable, len(c.Created))
+	createdValues := make([]vars.Variable, len(c.Created))
+	for i, note := range c.Created {
+		api.ToBinaryLE(note.Value, ValueBits)
+		commitments[i] = poseidon.Hash(*api, []vars.Variable{note.Owner, note.Value, note.Blinding})
+		createdValues[i] = note.Value
+	}
+	c.config.Logic.Constrain(*api, spentValues, createdValues, public)
+
+	// The owner of every spent note signs the transfer.
+	message := append(append([]vars.Variable{root}, nullifiers...), commitments...)
+	for i := 0; i < len(public); i += chunkLength {
+		end := i + chunkLength
+		if end > len(public) {
+			end = len(public)
+		}
+		message = append(message, api.ToVariableFromBytesBE(public[i:end]))
+	}
+	hash := poseidon.Hash(*api, message)
+	eddsaAPI := eddsa.NewAPI(api)
+	for _, note := range c.Spent {
+		eddsaAPI.Verify(note.SpendingKey, hash, note.Signature)
+	}
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(api.ToBytes32FromVariableBE(root))
+	for _, v := range append(nullifiers, commitments...) {
+		outputWriter.WriteBytes32(api.ToBytes32FromVariableBE(v))
+	}
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
@@ -0,0 +1,150 @@
+// A circuit template for shielded transfers of notes, the skeleton of private payments: a transfer
+// spends notes committed in a lean incremental Merkle tree of the merkle package and creates new
+// ones, and it emits the nullifiers of the spent notes so that a contract rejects double spends.
+//
+// The owner of a note has a spending key, an EdDSA key on Baby Jubjub of the eddsa package, and a
+// nullifying key nk, an element of the scalar field, and:
+//   - owner = poseidon(spendingKey.X, spendingKey.Y, nk)
+//   - commitment = poseidon(owner, value, blinding)
+//   - nullifier = poseidon(commitment, nk)
+//
+// so that only the owner can nullify a note, and the nullifier does not reveal the commitment. The
+// owner of every spent note signs the transfer, and the notes of zero value need no proof of
+// membership, so that transfers of fewer notes pad their inputs with them.
+//
+// The input is the root of the tree followed by public data of the ValueLogic of the circuit, which
+// constrains the values of the notes, such as the Transfer logic of deposits and withdrawals. The
+// output is the root followed by the nullifiers of the spent notes and the commitments of the
+// created notes.
+package shielded
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/eddsa"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bits of the value of a note, so that the sums of values cannot overflow.
+const ValueBits = 64
+
+// A ValueLogic constrains the values of the notes of a transfer given the public data of the input.
+type ValueLogic interface {
+	// The length of the public data of the input.
+	PublicLength() int
+
+	// Constrains the values of the spent and created notes in-circuit, which are range checked to
+	// ValueBits bits, given the public data, whose bytes are range checked.
+	Constrain(api builder.API, spent []vars.Variable, created []vars.Variable, public []vars.Byte)
+
+	// Returns whether the values satisfy the logic outside of the circuit. It must match Constrain.
+	Check(spent []uint64, created []uint64, public []byte) error
+}
+
+// Transfer is the value logic of a pool of a single asset, whose public data is the deposit and the
+// withdrawal of the transfer as big-endian uint64: the sum of the spent notes plus the deposit is
+// the sum of the created notes plus the withdrawal.
+type Transfer struct{}
+
+var _ ValueLogic = Transfer{}
+
+func (Transfer) PublicLength() int {
+	return 16
+}
+
+func (Transfer) Constrain(api builder.API, spent []vars.Variable, created []vars.Variable, public []vars.Byte) {
+	in := api.ToVariableFromBytesBE(public[:8])
+	out := api.ToVariableFromBytesBE(public[8:16])
+	for _, value := range spent {
+		in = api.Add(in, value)
+	}
+	for _, value := range created {
+		out = api.Add(out, value)
+	}
+	api.AssertIsEqual(in, out)
+}
+
+func (Transfer) Check(spent []uint64, created []uint64, public []byte) error {
+	in := new(big.Int).SetBytes(public[:8])
+	out := new(big.Int).SetBytes(public[8:16])
+	for _, value := range spent {
+		in.Add(in, new(big.Int).SetUint64(value))
+	}
+	for _, value := range created {
+		out.Add(out, new(big.Int).SetUint64(value))
+	}
+	if in.Cmp(out) != 0 {
+		return fmt.Errorf("the transfer spends %s and creates %s", in, out)
+	}
+	return nil
+}
+
+// The keys of the owner of notes outside of circuits.
+type Keys struct {
+	SpendingKey   *big.Int
+	NullifyingKey *big.Int
+}
+
+// Returns the owner of the keys.
+func (k *Keys) Owner() *big.Int {
+	return Owner(eddsa.PublicKey(k.SpendingKey), k.NullifyingKey)
+}
+
+// Returns the owner of the public spending key and the nullifying key.
+func Owner(spendingKey babyjubjub.PointValue, nullifyingKey *big.Int) *big.Int {
+	return poseidon.HashValues(spendingKey.X, spendingKey.Y, nullifyingKey)
+}
+
+// A note outside of circuits.
+type NoteValue struct {
+	Owner    *big.Int
+	Value    uint64
+	Blinding *big.Int
+}
+
+// Returns the commitment of the note.
+func (n *NoteValue) Commitment() *big.Int {
+	return poseidon.HashValues(n.Owner, new(big.Int).SetUint64(n.Value), n.Blinding)
+}
+
+// Returns the nullifier of the note for the nullifying key of its owner.
+func (n *NoteValue) Nullifier(nullifyingKey *big.Int) *big.Int {
+	return poseidon.HashValues(n.Commitment(), nullifyingKey)
+}
+
+// Returns the message that the owners of the spent notes sign: the Poseidon of the root, the
+// nullifiers, the commitments and the public data in chunks of 31 bytes.
+func SigningMessage(root *big.Int, nullifiers []*big.Int, commitments []*big.Int, public []byte) *big.Int {
+	in := append(append([]*big.Int{root}, nullifiers...), commitments...)
+	for i := 0; i < len(public); i += chunkLength {
+		end := i + chunkLength
+		if end > len(public) {
+			end = len(public)
+		}
+		in = append(in, new(big.Int).SetBytes(public[i:end]))
+	}
+	return poseidon.HashValues(in...)
+}
+
+// The length of the chunks of the public data in the signing message.
+const chunkLength = 31
+
+// A spent note and the proofs of its ownership in the circuit.
+type SpentNote struct {
+	SpendingKey   babyjubjub.Point
+	NullifyingKey vars.Variable
+	Value         vars.Variable
+	Blinding      vars.Variable
+	Signature     eddsa.Signature
+}
+
+// A created note in the circuit.
+type CreatedNote struct {
+	Owner    vars.Variable
+	Value    vars.Variable
+	Blinding vars.Variable
+}
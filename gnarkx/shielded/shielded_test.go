@@ -0,0 +1,103 @@
+package shielded
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/eddsa"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var config = &Config{NbSpent: 2, NbCreated: 2, MaxDepth: 4, Logic: Transfer{}}
+
+// Returns the public data of a deposit and a withdrawal.
+func publicData(deposit uint64, withdrawal uint64) []byte {
+	return append(new(big.Int).SetUint64(deposit).FillBytes(make([]byte, 8)), new(big.Int).SetUint64(withdrawal).FillBytes(make([]byte, 8))...)
+}
+
+// Returns the spends of the notes by the keys, signed for the transfer.
+func sign(tree *merkle.LeanTree, keys []*Keys, spent []Spend, created []NoteValue, public []byte) []Spend {
+	message := config.Message(tree.Root(), spent, created, public)
+	for i := range spent {
+		spent[i].Signature = eddsa.Sign(keys[i].SpendingKey, message)
+	}
+	return spent
+}
+
+func TestTransfer(t *testing.T) {
+	alice := &Keys{SpendingKey: big.NewInt(1111), NullifyingKey: big.NewInt(2222)}
+	bob := &Keys{SpendingKey: big.NewInt(3333), NullifyingKey: big.NewInt(4444)}
+	notes := []NoteValue{
+		{Owner: bob.Owner(), Value: 5, Blinding: big.NewInt(1)},
+		{Owner: alice.Owner(), Value: 100, Blinding: big.NewInt(2)},
+		{Owner: alice.Owner(), Value: 20, Blinding: big.NewInt(3)},
+	}
+	leaves := make([]*big.Int, len(notes))
+	for i := range notes {
+		leaves[i] = notes[i].Commitment()
+	}
+	tree, err := merkle.NewLeanTree(leaves)
+	assert.NoError(t, err)
+	newSpend := func(keys *Keys, note NoteValue, index int) Spend {
+		return Spend{SpendingKey: eddsa.PublicKey(keys.SpendingKey), NullifyingKey: keys.NullifyingKey, Note: note, Index: index}
+	}
+
+	// Alice pays 70 to Bob, keeps 45 and withdraws 5.
+	created := []NoteValue{
+		{Owner: bob.Owner(), Value: 70, Blinding: big.NewInt(4)},
+		{Owner: alice.Owner(), Value: 45, Blinding: big.NewInt(5)},
+	}
+	public := publicData(0, 5)
+	spent := sign(tree, []*Keys{alice, alice}, []Spend{newSpend(alice, notes[1], 1), newSpend(alice, notes[2], 2)}, created, public)
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetTransfer(tree, spent, created, public))
+	input := config.Input(tree.Root(), public)
+	assert.NoError(t, functiontest.IsSolved(circuit, input))
+	output := vars.GetValuesUnsafe(*circuit.GetOutputBytes())
+	assert.Equal(t, notes[1].Nullifier(alice.NullifyingKey).FillBytes(make([]byte, 32)), output[32:64])
+	assert.Equal(t, created[1].Commitment().FillBytes(make([]byte, 32)), output[128:160])
+
+	// Bob deposits 10 and spends his note with a note of zero value that is not in the tree.
+	zero := NoteValue{Owner: bob.Owner(), Value: 0, Blinding: big.NewInt(6)}
+	created = []NoteValue{
+		{Owner: bob.Owner(), Value: 15, Blinding: big.NewInt(7)},
+		{Owner: alice.Owner(), Value: 0, Blinding: big.NewInt(8)},
+	}
+	public = publicData(10, 0)
+	spent = sign(tree, []*Keys{bob, bob}, []Spend{newSpend(bob, notes[0], 0), newSpend(bob, zero, 0)}, created, public)
+	circuit = NewCircuit(config)
+	assert.NoError(t, circuit.SetTransfer(tree, spent, created, public))
+	assert.NoError(t, functiontest.IsSolved(circuit, config.Input(tree.Root(), public)))
+
+	// The values must be conserved.
+	created[0].Value = 16
+	assert.Error(t, circuit.SetTransfer(tree, spent, created, public))
+	circuit.created = created
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(tree.Root(), public)))
+
+	// The owner of a spent note must sign the transfer.
+	created[0].Value = 15
+	spent = sign(tree, []*Keys{bob, alice}, []Spend{newSpend(bob, notes[0], 0), newSpend(bob, zero, 0)}, created, public)
+	assert.Error(t, circuit.SetTransfer(tree, spent, created, public))
+	circuit.spent = spent
+	circuit.created = created
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(tree.Root(), public)))
+
+	// A note of non-zero value must be in the tree.
+	forged := NoteValue{Owner: bob.Owner(), Value: 5, Blinding: big.NewInt(9)}
+	spent = sign(tree, []*Keys{bob, bob}, []Spend{newSpend(bob, forged, 0), newSpend(bob, zero, 0)}, created, public)
+	assert.Error(t, circuit.SetTransfer(tree, spent, created, public))
+	circuit.spent = spent
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(tree.Root(), public)))
+
+	// A note cannot be spent twice in a transfer.
+	double := []NoteValue{{Owner: alice.Owner(), Value: 200, Blinding: big.NewInt(10)}, created[1]}
+	spent = sign(tree, []*Keys{alice, alice}, []Spend{newSpend(alice, notes[1], 1), newSpend(alice, notes[1], 1)}, double, publicData(0, 0))
+	assert.Error(t, circuit.SetTransfer(tree, spent, double, publicData(0, 0)))
+	circuit.spent = spent
+	circuit.created = double
+	assert.Error(t, functiontest.IsSolved(circuit, config.Input(tree.Root(), publicData(0, 0))))
+}
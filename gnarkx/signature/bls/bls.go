@@ -0,0 +1,273 @@
+// Verification of BLS signatures over BLS12-381 with public keys in G1 and signatures in G2, as
+// used by the Ethereum consensus layer and the fast finality votes of BNB Smart Chain. Messages
+// are hashed to G2 with the suite BLS12381G2_XMD:SHA-256_SSWU_RO_ of RFC 9380, and points are
+// decoded from the compressed serialization of ZCash. Field arithmetic is emulated.
+package bls
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	fpbls "github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The domain separation tag of the proof of possession scheme, used for signatures of the
+// Ethereum consensus layer and BNB Smart Chain votes.
+var DST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+const (
+	// The lengths of compressed points of G1 and G2.
+	G1Length = 48
+	G2Length = 96
+
+	// The emulated limbs of an element of Fp.
+	nbLimbs  = 6
+	limbBits = 64
+)
+
+// (p - 1) / 2 and (p + 1) / 2, the bounds of the elements that are not and are lexicographically
+// largest among themselves and their negations.
+var (
+	halfModulus     = emulated.ValueOf[emulated.BLS12381Fp](new(big.Int).Rsh(modulus, 1))
+	halfModulusNext = emulated.ValueOf[emulated.BLS12381Fp](new(big.Int).Add(new(big.Int).Rsh(modulus, 1), big.NewInt(1)))
+)
+
+// BLSAPI is a wrapper around succinct.API that provides methods for verifying BLS signatures.
+type BLSAPI struct {
+	api     builder.API
+	fp      *emulated.Field[emulated.BLS12381Fp]
+	g1      *sw_emulated.Curve[emulated.BLS12381Fp, emulated.BLS12381Fr]
+	g2      *g2
+	pairing *sw_bls12381.Pairing
+}
+
+// Creates a new BLSAPI.
+func NewAPI(api *builder.API) *BLSAPI {
+	fapi := api.FrontendAPI()
+	fp, err := emulated.NewField[emulated.BLS12381Fp](fapi)
+	if err != nil {
+		panic(err)
+	}
+	g1, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](fapi, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pairing, err := sw_bls12381.NewPairing(fapi)
+	if err != nil {
+		panic(err)
+	}
+	return &BLSAPI{api: *api, fp: fp, g1: g1, g2: newG2(fapi), pairing: pairing}
+}
+
+// Verifies the signature of the message, of a compile time length, under the public key.
+func (a *BLSAPI) Verify(pubkey *sw_bls12381.G1Affine, msg []vars.Byte, signature *sw_bls12381.G2Affine) {
+	a.pairing.AssertIsOnG2(signature)
+	_, _, g1, _ := bls12381.Generators()
+	g1.Neg(&g1)
+	generator := sw_bls12381.NewG1Affine(g1)
+	hash := a.HashToG2(msg, DST)
+	err := a.pairing.PairingCheck([]*sw_bls12381.G1Affine{pubkey, &generator}, []*sw_bls12381.G2Affine{hash, signature})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Verifies the signature of the message, of a compile time length, under the public key if
+// enabled is set, and nothing otherwise. A disabled check verifies a constant signature of the
+// zero message under the generator instead, so the signature must still decode to a point of G2.
+func (a *BLSAPI) VerifyIf(enabled frontend.Variable, pubkey *sw_bls12381.G1Affine, msg []vars.Byte, signature *sw_bls12381.G2Affine) {
+	_, _, g1, _ := bls12381.Generators()
+	generator := sw_bls12381.NewG1Affine(g1)
+	hash, err := bls12381.HashToG2(make([]byte, len(msg)), DST)
+	if err != nil {
+		panic(err)
+	}
+	constant := sw_bls12381.NewG2Affine(hash)
+
+	zero := vars.Byte{Value: vars.NewVariableFromInt(0)}
+	selected := make([]vars.Byte, len(msg))
+	for i := 0; i < len(msg); i++ {
+		selected[i] = a.api.SelectByte(vars.Bool{Value: vars.Variable{Value: enabled}}, msg[i], zero)
+	}
+	a.Verify(
+		a.g1.Select(enabled, pubkey, &generator),
+		selected,
+		&sw_bls12381.G2Affine{
+			X: *a.g2.Select(enabled, &signature.X, &constant.X),
+			Y: *a.g2.Select(enabled, &signature.Y, &constant.Y),
+		},
+	)
+}
+
+// Returns the sum of the public keys whose bit is set, the aggregate public key of a signature
+// by these keys, which is (0, 0) if no bit is set.
+func (a *BLSAPI) AggregatePubkeys(pubkeys []sw_bls12381.G1Affine, bits []vars.Bool) *sw_bls12381.G1Affine {
+	if len(pubkeys) != len(bits) {
+		panic(fmt.Sprintf("%d public keys and %d bits", len(pubkeys), len(bits)))
+	}
+	zero := a.fp.Zero()
+	infinity := &sw_bls12381.G1Affine{X: *zero, Y: *zero}
+	result := infinity
+	for i := 0; i < len(pubkeys); i++ {
+		result = a.g1.AddUnified(result, a.g1.Select(bits[i].Value.Value, &pubkeys[i], infinity))
+	}
+	return result
+}
+
+// Decodes a compressed point of G1, which must not be the point at infinity. Only membership in
+// the curve is checked, which suffices for public keys that come with proofs of possession. The
+// bytes are assumed to be range checked.
+func (a *BLSAPI) DecompressG1(in [G1Length]vars.Byte) *sw_bls12381.G1Affine {
+	x, sign := a.decodeCoordinate(in[:], true)
+	rhs := a.fp.Add(a.fp.Mul(a.fp.Mul(x, x), x), a.fp.NewElement(4))
+	out := a.hint(g1SqrtHint, nbLimbs, append(a.limbs(x), sign)...)
+	y := a.fp.NewElement(out)
+	a.fp.AssertIsEqual(a.fp.Mul(y, y), rhs)
+	a.assertSign(y, sign)
+	point := &sw_bls12381.G1Affine{X: *x, Y: *y}
+	a.g1.AssertIsOnCurve(point)
+	return point
+}
+
+// Decodes a compressed point of G2, which must not be the point at infinity, and checks that it
+// is in the subgroup. The bytes are assumed to be range checked.
+func (a *BLSAPI) DecompressG2(in [G2Length]vars.Byte) *sw_bls12381.G2Affine {
+	x1, sign := a.decodeCoordinate(in[:G1Length], true)
+	x0, _ := a.decodeCoordinate(in[G1Length:], false)
+	x := &e2{A0: *x0, A1: *x1}
+	b := e2Constant("4", "4")
+	rhs := a.g2.Add(a.g2.Mul(a.g2.Square(x), x), &b)
+	out := a.hint(g2SqrtHint, 2*nbLimbs, append(a.limbs(x0, x1), sign)...)
+	y := &e2{A0: *a.fp.NewElement(out[:nbLimbs]), A1: *a.fp.NewElement(out[nbLimbs:])}
+	a.g2.AssertIsEqual(a.g2.Square(y), rhs)
+
+	// The sign of the second coordinate decides unless it is zero.
+	a.fp.AssertIsInRange(&y.A0)
+	a.fp.AssertIsInRange(&y.A1)
+	a.assertSign(a.fp.Select(a.fp.IsZero(&y.A1), &y.A0, &y.A1), sign)
+
+	point := &sw_bls12381.G2Affine{X: *x, Y: *y}
+	a.pairing.AssertIsOnG2(point)
+	return point
+}
+
+// Decodes a big-endian coordinate of 48 bytes, which is less than p. If flags is set, the top 3
+// bits of the first byte are the compression flags, which must be compressed and not infinity,
+// and the sign flag is returned.
+func (a *BLSAPI) decodeCoordinate(in []vars.Byte, flags bool) (*fpElement, frontend.Variable) {
+	fapi := a.api.FrontendAPI()
+	bits := make([]frontend.Variable, 0, nbLimbs*limbBits)
+	for i := len(in) - 1; i >= 0; i-- {
+		for _, bit := range a.api.ToBitsFromByte(in[i]) {
+			bits = append(bits, bit.Value.Value)
+		}
+	}
+	var sign frontend.Variable = 0
+	if flags {
+		fapi.AssertIsEqual(bits[len(bits)-1], 1)
+		fapi.AssertIsEqual(bits[len(bits)-2], 0)
+		sign = bits[len(bits)-3]
+		for i := len(bits) - 3; i < len(bits); i++ {
+			bits[i] = 0
+		}
+	}
+	x := a.fp.FromBits(bits...)
+	a.fp.AssertIsInRange(x)
+	return x, sign
+}
+
+// Asserts that an element less than p is lexicographically largest if sign is set and not
+// otherwise.
+func (a *BLSAPI) assertSign(y *fpElement, sign frontend.Variable) {
+	a.fp.AssertIsInRange(y)
+	lower := a.fp.Select(sign, &halfModulusNext, y)
+	upper := a.fp.Select(sign, y, &halfModulus)
+	a.fp.AssertIsLessOrEqual(lower, upper)
+}
+
+// Returns the limbs of the elements, reduced so that each has nbLimbs limbs.
+func (a *BLSAPI) limbs(in ...*fpElement) []frontend.Variable {
+	var result []frontend.Variable
+	for _, e := range in {
+		r := a.fp.Reduce(e)
+		if len(r.Limbs) != nbLimbs {
+			panic(fmt.Sprintf("element has %d limbs", len(r.Limbs)))
+		}
+		result = append(result, r.Limbs...)
+	}
+	return result
+}
+
+func (a *BLSAPI) hint(h *builder.Hint, nbOutputs int, in ...frontend.Variable) []frontend.Variable {
+	out, err := a.api.FrontendAPI().Compiler().NewHintForId(h.ID(), nbOutputs, in...)
+	if err != nil {
+		panic(fmt.Sprintf("hint %s: %v", h.Name(), err))
+	}
+	return out
+}
+
+// Returns the integers of groups of nbLimbs limbs.
+func fromLimbs(limbs []*big.Int) []*big.Int {
+	result := make([]*big.Int, len(limbs)/nbLimbs)
+	for i := range result {
+		result[i] = new(big.Int)
+		for j := nbLimbs - 1; j >= 0; j-- {
+			result[i].Lsh(result[i], limbBits)
+			result[i].Add(result[i], limbs[i*nbLimbs+j])
+		}
+	}
+	return result
+}
+
+// Writes the limbs of the integers, which must be less than p, to out.
+func toLimbs(out []*big.Int, in ...*big.Int) {
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), limbBits), big.NewInt(1))
+	for i, x := range in {
+		for j := 0; j < nbLimbs; j++ {
+			out[i*nbLimbs+j].And(new(big.Int).Rsh(x, uint(j*limbBits)), mask)
+		}
+	}
+}
+
+// Returns the square root of x^3 + 4 that is lexicographically largest if the sign is set.
+var g1SqrtHint = builder.NewHint("bls.g1sqrt", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	var x, y, rhs, four fpbls.Element
+	x.SetBigInt(fromLimbs(inputs[:nbLimbs])[0])
+	four.SetUint64(4)
+	rhs.Square(&x).Mul(&rhs, &x).Add(&rhs, &four)
+	if y.Sqrt(&rhs) == nil {
+		return fmt.Errorf("x is not on the curve")
+	}
+	if y.LexicographicallyLargest() != (inputs[nbLimbs].Sign() != 0) {
+		y.Neg(&y)
+	}
+	toLimbs(outputs, y.BigInt(new(big.Int)))
+	return nil
+})
+
+// Returns the square root of x^3 + 4(1+u) that is lexicographically largest if the sign is set.
+var g2SqrtHint = builder.NewHint("bls.g2sqrt", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	values := fromLimbs(inputs[:2*nbLimbs])
+	var x, y, rhs, b bls12381.E2
+	x.A0.SetBigInt(values[0])
+	x.A1.SetBigInt(values[1])
+	b.A0.SetUint64(4)
+	b.A1.SetUint64(4)
+	rhs.Square(&x).Mul(&rhs, &x).Add(&rhs, &b)
+	if rhs.Legendre() == -1 {
+		return fmt.Errorf("x is not on the twist")
+	}
+	y.Sqrt(&rhs)
+	if y.LexicographicallyLargest() != (inputs[2*nbLimbs].Sign() != 0) {
+		y.Neg(&y)
+	}
+	toLimbs(outputs, y.A0.BigInt(new(big.Int)), y.A1.BigInt(new(big.Int)))
+	return nil
+})
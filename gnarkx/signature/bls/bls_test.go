@@ -0,0 +1,101 @@
+package bls
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testHashCircuit struct {
+	Msg  [32]vars.Byte
+	Hash sw_bls12381.G2Affine
+}
+
+func (c *testHashCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	bls := NewAPI(api)
+	hash := bls.HashToG2(c.Msg[:], DST)
+	bls.g2.AssertIsEqual(&hash.X, &c.Hash.X)
+	bls.g2.AssertIsEqual(&hash.Y, &c.Hash.Y)
+	return nil
+}
+
+func TestHashToG2(t *testing.T) {
+	var msg [32]byte
+	for i := range msg {
+		msg[i] = byte(i * 13)
+	}
+	hash, err := bls12381.HashToG2(msg[:], DST)
+	assert.NoError(t, err)
+	assignment := &testHashCircuit{Hash: sw_bls12381.NewG2Affine(hash)}
+	vars.SetBytes32(&assignment.Msg, msg)
+	err = test.IsSolved(&testHashCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	msg[31] ^= 1
+	vars.SetBytes32(&assignment.Msg, msg)
+	err = test.IsSolved(&testHashCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+type testVerifyCircuit struct {
+	Pubkeys   [3][G1Length]vars.Byte
+	Bits      [3]vars.Bool
+	Msg       [32]vars.Byte
+	Signature [G2Length]vars.Byte
+}
+
+func (c *testVerifyCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	bls := NewAPI(api)
+	pubkeys := make([]sw_bls12381.G1Affine, len(c.Pubkeys))
+	for i := range c.Pubkeys {
+		pubkeys[i] = *bls.DecompressG1(c.Pubkeys[i])
+	}
+	pubkey := bls.AggregatePubkeys(pubkeys, c.Bits[:])
+	bls.Verify(pubkey, c.Msg[:], bls.DecompressG2(c.Signature))
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	var msg [32]byte
+	msg[0] = 0x42
+	hash, err := bls12381.HashToG2(msg[:], DST)
+	assert.NoError(t, err)
+	_, _, g1, _ := bls12381.Generators()
+
+	// The first and last of three keys sign.
+	assignment := &testVerifyCircuit{}
+	var signature bls12381.G2Affine
+	for i := 0; i < 3; i++ {
+		secret := big.NewInt(int64(1000003*i + 7))
+		var pubkey bls12381.G1Affine
+		pubkey.ScalarMultiplication(&g1, secret)
+		pubkeyBytes := pubkey.Bytes()
+		copy(assignment.Pubkeys[i][:], vars.NewBytesFrom(pubkeyBytes[:]))
+		assignment.Bits[i] = vars.NewBool(i != 1)
+		if i != 1 {
+			var partial bls12381.G2Affine
+			partial.ScalarMultiplication(&hash, secret)
+			signature.Add(&signature, &partial)
+		}
+	}
+	vars.SetBytes32(&assignment.Msg, msg)
+	signatureBytes := signature.Bytes()
+	copy(assignment.Signature[:], vars.NewBytesFrom(signatureBytes[:]))
+	err = test.IsSolved(&testVerifyCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The signature is not by the second key.
+	assignment.Bits[1] = vars.NewBool(true)
+	err = test.IsSolved(&testVerifyCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
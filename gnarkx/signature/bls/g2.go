@@ -0,0 +1,109 @@
+package bls
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+type (
+	fpElement = emulated.Element[emulated.BLS12381Fp]
+	e2        = fields_bls12381.E2
+)
+
+// The modulus of the base field.
+var modulus = emulated.BLS12381Fp{}.Modulus()
+
+// Returns the constant element of Fp of a decimal integer, which may be negative.
+func fpConstant(s string) fpElement {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid constant " + s)
+	}
+	return emulated.ValueOf[emulated.BLS12381Fp](v.Mod(v, modulus))
+}
+
+// Returns the constant element a0 + a1*u of Fp2.
+func e2Constant(a0, a1 string) e2 {
+	return e2{A0: fpConstant(a0), A1: fpConstant(a1)}
+}
+
+// The constants of the endomorphism psi, the untwist-Frobenius-twist map of the twist.
+var (
+	psiX = fpConstant("4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939437")
+	psiY = e2Constant(
+		"2973677408986561043442465346520108879172042883009249989176415018091420807192182638567116318576472649347015917690530",
+		"1028732146235106349975324479215795277384839936929757896155643118032610843298655225875571310552543014690878354869257",
+	)
+)
+
+// The absolute value of the BLS parameter x = -0xd201000000010000 of the curve.
+const seed uint64 = 0xd201000000010000
+
+// Arithmetic of affine points of the twist E2: y^2 = x^3 + 4(1+u) over Fp2. Additions and
+// doublings use incomplete formulas, which hold for the points of large order of hashing.
+type g2 struct {
+	*fields_bls12381.Ext2
+}
+
+func newG2(api frontend.API) *g2 {
+	return &g2{Ext2: fields_bls12381.NewExt2(api)}
+}
+
+func (g *g2) add(p, q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	λ := g.DivUnchecked(g.Sub(&q.Y, &p.Y), g.Sub(&q.X, &p.X))
+	x := g.Sub(g.Square(λ), g.Add(&p.X, &q.X))
+	y := g.Sub(g.Mul(λ, g.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+func (g *g2) double(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	λ := g.DivUnchecked(g.MulByConstElement(g.Square(&p.X), big.NewInt(3)), g.Double(&p.Y))
+	x := g.Sub(g.Square(λ), g.Double(&p.X))
+	y := g.Sub(g.Mul(λ, g.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+func (g *g2) neg(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return &sw_bls12381.G2Affine{X: p.X, Y: *g.Neg(&p.Y)}
+}
+
+func (g *g2) sub(p, q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return g.add(p, g.neg(q))
+}
+
+// Returns psi(p) = (u * psiX * conj(x), psiY * conj(y)).
+func (g *g2) psi(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	x := g.MulByElement(&p.X, &psiX)
+	y := g.Mul(g.Conjugate(&p.Y), &psiY)
+	return &sw_bls12381.G2Affine{X: e2{A0: x.A1, A1: x.A0}, Y: *y}
+}
+
+// Returns [x]p for the negative BLS parameter x.
+func (g *g2) mulBySeed(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	z := p
+	for i := 62; i >= 0; i-- {
+		z = g.double(z)
+		if (seed>>i)&1 == 1 {
+			z = g.add(z, p)
+		}
+	}
+	return g.neg(z)
+}
+
+// Maps a point of E2 to G2 by the multiplication by h_eff of RFC 9380, computed with the
+// endomorphism psi as in Appendix G.3.
+func (g *g2) clearCofactor(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	t1 := g.mulBySeed(p)
+	t2 := g.psi(p)
+	t3 := g.psi(g.psi(g.double(p)))
+	t3 = g.sub(t3, t2)
+	t2 = g.add(t1, t2)
+	t2 = g.mulBySeed(t2)
+	t3 = g.add(t3, t2)
+	t3 = g.sub(t3, t1)
+	return g.sub(t3, p)
+}
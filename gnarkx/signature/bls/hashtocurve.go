@@ -0,0 +1,248 @@
+package bls
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The constants of the suite BLS12381G2_XMD:SHA-256_SSWU_RO_ of RFC 9380: the curve E2' isogenous
+// to E2 on which the simplified SWU map is defined, and the coefficients of the 3-isogeny from E2'
+// to E2 of Appendix E.3, from the constant term up.
+var (
+	sswuA = e2Constant("0", "240")
+	sswuB = e2Constant("1012", "1012")
+	sswuZ = e2Constant("-2", "-1")
+
+	isogenyXNumerator = []e2{
+		e2Constant(
+			"889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542",
+			"889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542",
+		),
+		e2Constant(
+			"0",
+			"2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706522",
+		),
+		e2Constant(
+			"2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706526",
+			"1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853261",
+		),
+		e2Constant(
+			"3557697382419259905260257622876359250272784728834673675850718343221361467102966990615722337003569479144794908942033",
+			"0",
+		),
+	}
+	isogenyXDenominator = []e2{
+		e2Constant("0", "-72"),
+		e2Constant("12", "-12"),
+		e2Constant("1", "0"),
+	}
+	isogenyYNumerator = []e2{
+		e2Constant(
+			"3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558",
+			"3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558",
+		),
+		e2Constant(
+			"0",
+			"889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235518",
+		),
+		e2Constant(
+			"2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706524",
+			"1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853263",
+		),
+		e2Constant(
+			"2816510427748580758331037284777117739799287910327449993381818688383577828123182200904113516794492504322962636245776",
+			"0",
+		),
+	}
+	isogenyYDenominator = []e2{
+		e2Constant("-432", "-432"),
+		e2Constant("0", "-216"),
+		e2Constant("18", "-18"),
+		e2Constant("1", "0"),
+	}
+)
+
+// Computes expand_message_xmd of RFC 9380 with SHA-256, returning n bytes of uniform output for
+// a message of a compile time length and a constant domain separation tag.
+func expandMessageXMD(api builder.API, msg []vars.Byte, dst []byte, n int) []vars.Byte {
+	ell := (n + 31) / 32
+	if ell > 255 || len(dst) > 255 || n > 65535 {
+		panic("invalid expand_message_xmd parameters")
+	}
+	dstPrime := vars.NewBytesFrom(append(append([]byte{}, dst...), byte(len(dst))))
+
+	in := vars.NewBytesFrom(make([]byte, 64))
+	in = append(in, msg...)
+	in = append(in, vars.NewBytesFrom([]byte{byte(n >> 8), byte(n), 0})...)
+	b0 := sha256.Hash(api, append(in, dstPrime...))
+
+	var result []vars.Byte
+	b := sha256.Hash(api, append(append(b0[:], vars.NewBytesFrom([]byte{1})...), dstPrime...))
+	result = append(result, b[:]...)
+	for i := 2; i <= ell; i++ {
+		var x [32]vars.Byte
+		for j := 0; j < 32; j++ {
+			x[j] = xorByte(api, b0[j], b[j])
+		}
+		b = sha256.Hash(api, append(append(x[:], vars.NewBytesFrom([]byte{byte(i)})...), dstPrime...))
+		result = append(result, b[:]...)
+	}
+	return result[:n]
+}
+
+func xorByte(api builder.API, a vars.Byte, b vars.Byte) vars.Byte {
+	aBits := api.ToBitsFromByte(a)
+	bBits := api.ToBitsFromByte(b)
+	var bits [8]vars.Bool
+	for i := 0; i < 8; i++ {
+		bits[i] = api.Xor(aBits[i], bBits[i])
+	}
+	return api.ToByteFromBits(bits)
+}
+
+// Returns the element of Fp of 64 big-endian bytes reduced modulo p, as in hash_to_field.
+func (a *BLSAPI) fpFromBytes(in []vars.Byte) *fpElement {
+	if len(in) != 64 {
+		panic(fmt.Sprintf("expected 64 bytes, got %d", len(in)))
+	}
+	fromBytes := func(in []vars.Byte) *fpElement {
+		bits := make([]frontend.Variable, 0, 8*len(in))
+		for i := len(in) - 1; i >= 0; i-- {
+			for _, bit := range a.api.ToBitsFromByte(in[i]) {
+				bits = append(bits, bit.Value.Value)
+			}
+		}
+		for len(bits) < nbLimbs*limbBits {
+			bits = append(bits, 0)
+		}
+		return a.fp.FromBits(bits...)
+	}
+	shift := fpConstant(new(big.Int).Lsh(big.NewInt(1), 256).String())
+	return a.fp.Add(a.fp.Mul(fromBytes(in[:32]), &shift), fromBytes(in[32:]))
+}
+
+// Returns sgn0 of an element of Fp2 as defined by RFC 9380.
+func (a *BLSAPI) sgn0(x *e2) frontend.Variable {
+	fapi := a.api.FrontendAPI()
+	parity := func(x *fpElement) frontend.Variable {
+		r := a.fp.Reduce(x)
+		a.fp.AssertIsInRange(r)
+		return a.fp.ToBits(r)[0]
+	}
+	sign0 := parity(&x.A0)
+	zero0 := a.fp.IsZero(&x.A0)
+	sign1 := parity(&x.A1)
+	return fapi.Or(sign0, fapi.And(zero0, sign1))
+}
+
+// Evaluates the polynomial with the coefficients from the constant term up at x.
+func (a *BLSAPI) evalPolynomial(coefficients []e2, x *e2) *e2 {
+	result := &coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = a.g2.Add(a.g2.Mul(result, x), &coefficients[i])
+	}
+	return result
+}
+
+// Returns x^3 + A'x + B', the right hand side of the equation of E2'.
+func (a *BLSAPI) sswuCurve(x *e2) *e2 {
+	return a.g2.Add(a.g2.Mul(a.g2.Add(a.g2.Square(x), &sswuA), x), &sswuB)
+}
+
+// Maps an element of Fp2 to a point of E2' with the simplified SWU map of RFC 9380, Section
+// 6.6.2. The square root is a hint: exactly one of g(x1) and g(x2) is a square since Z is not,
+// so the choice of the hint is forced. The circuit is unsatisfiable for the negligible fraction of
+// inputs where Z^2 u^4 + Z u^2 is zero.
+func (a *BLSAPI) mapToCurve(u *e2) *sw_bls12381.G2Affine {
+	g := a.g2
+	zu2 := g.Mul(&sswuZ, g.Square(u))
+	den := g.Add(g.Square(zu2), zu2)
+
+	// x1 = (-B / A) * (1 + 1 / den)
+	minusBOverA := g.DivUnchecked(g.Neg(&sswuB), &sswuA)
+	x1 := g.DivUnchecked(g.Mul(minusBOverA, g.Add(den, g.One())), den)
+	gx1 := a.sswuCurve(x1)
+	x2 := g.Mul(zu2, x1)
+	gx2 := a.sswuCurve(x2)
+
+	in := append(a.limbs(&gx1.A0, &gx1.A1, &gx2.A0, &gx2.A1), a.limbs(&u.A0, &u.A1)...)
+	out := a.hint(sqrtHint, 1+2*nbLimbs, in...)
+	isSquare := out[0]
+	a.api.FrontendAPI().AssertIsBoolean(isSquare)
+	y := &e2{A0: *a.fp.NewElement(out[1 : 1+nbLimbs]), A1: *a.fp.NewElement(out[1+nbLimbs:])}
+
+	x := g.Select(isSquare, x1, x2)
+	g.AssertIsEqual(g.Square(y), g.Select(isSquare, gx1, gx2))
+	a.api.FrontendAPI().AssertIsEqual(a.sgn0(y), a.sgn0(u))
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+// Maps a point of E2' to E2 with the 3-isogeny.
+func (a *BLSAPI) isogeny(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	g := a.g2
+	x := g.DivUnchecked(a.evalPolynomial(isogenyXNumerator, &p.X), a.evalPolynomial(isogenyXDenominator, &p.X))
+	y := g.DivUnchecked(a.evalPolynomial(isogenyYNumerator, &p.X), a.evalPolynomial(isogenyYDenominator, &p.X))
+	return &sw_bls12381.G2Affine{X: *x, Y: *g.Mul(y, &p.Y)}
+}
+
+// Hashes a message of a compile time length to G2 with hash_to_curve of the suite
+// BLS12381G2_XMD:SHA-256_SSWU_RO_ of RFC 9380 and the domain separation tag.
+func (a *BLSAPI) HashToG2(msg []vars.Byte, dst []byte) *sw_bls12381.G2Affine {
+	uniform := expandMessageXMD(a.api, msg, dst, 256)
+	var u [2]e2
+	for i := 0; i < 2; i++ {
+		u[i] = e2{
+			A0: *a.fpFromBytes(uniform[128*i : 128*i+64]),
+			A1: *a.fpFromBytes(uniform[128*i+64 : 128*i+128]),
+		}
+	}
+	q0 := a.isogeny(a.mapToCurve(&u[0]))
+	q1 := a.isogeny(a.mapToCurve(&u[1]))
+	return a.g2.clearCofactor(a.g2.add(q0, q1))
+}
+
+// Returns the square root of g(x1) with the sign of u if it is a square, or of g(x2) otherwise.
+var sqrtHint = builder.NewHint("bls.sqrt", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	values := fromLimbs(inputs)
+	gx1 := bls12381.E2{}
+	gx1.A0.SetBigInt(values[0])
+	gx1.A1.SetBigInt(values[1])
+	gx2 := bls12381.E2{}
+	gx2.A0.SetBigInt(values[2])
+	gx2.A1.SetBigInt(values[3])
+	gx, isSquare := &gx1, 1
+	if gx1.Legendre() != 1 {
+		gx, isSquare = &gx2, 0
+	}
+	if gx.Legendre() == -1 {
+		return fmt.Errorf("neither g(x1) nor g(x2) is a square")
+	}
+	var y bls12381.E2
+	y.Sqrt(gx)
+	u := bls12381.E2{}
+	u.A0.SetBigInt(values[4])
+	u.A1.SetBigInt(values[5])
+	if sgn0(&y) != sgn0(&u) {
+		y.Neg(&y)
+	}
+	outputs[0].SetInt64(int64(isSquare))
+	toLimbs(outputs[1:], y.A0.BigInt(new(big.Int)), y.A1.BigInt(new(big.Int)))
+	return nil
+})
+
+// Returns sgn0 of an element of Fp2 as defined by RFC 9380.
+func sgn0(x *bls12381.E2) uint64 {
+	a0 := x.A0.BigInt(new(big.Int))
+	a1 := x.A1.BigInt(new(big.Int))
+	if a0.Sign() != 0 {
+		return uint64(a0.Bit(0))
+	}
+	return uint64(a1.Bit(0))
+}
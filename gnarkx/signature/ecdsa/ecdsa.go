@@ -0,0 +1,98 @@
+// Verification of secp256k1 ECDSA signatures, as used by Ethereum transactions and signed
+// messages, and recovery of their signers. The field arithmetic of secp256k1 is emulated.
+//
+// A signature is (r, s) with the recovery id v of the EVM, 27 or 28, and signs a 32 byte hash,
+// e.g. the keccak256 of an EIP-191 or EIP-712 message. The address of a public key (x, y) is the
+// last 20 bytes of keccak256(x || y).
+package ecdsa
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A secp256k1 public key as big-endian coordinates in the circuit.
+type PublicKey struct {
+	X [32]vars.Byte
+	Y [32]vars.Byte
+}
+
+// Creates a new public key.
+func NewPublicKey() PublicKey {
+	return PublicKey{X: vars.NewBytes32(), Y: vars.NewBytes32()}
+}
+
+// Sets the public key.
+func (k *PublicKey) Set(publicKey *ecdsa.PublicKey) {
+	vars.SetBytes32(&k.X, [32]byte(common.LeftPadBytes(publicKey.X.Bytes(), 32)))
+	vars.SetBytes32(&k.Y, [32]byte(common.LeftPadBytes(publicKey.Y.Bytes(), 32)))
+}
+
+// A signature in the circuit, with big-endian r and s.
+type Signature struct {
+	R [32]vars.Byte
+	S [32]vars.Byte
+	V vars.Variable
+}
+
+// Creates a new signature.
+func NewSignature() Signature {
+	return Signature{R: vars.NewBytes32(), S: vars.NewBytes32(), V: vars.NewVariable()}
+}
+
+// Sets the signature from its 65 byte encoding r || s || v, where v is 27 or 28, or 0 or 1 as
+// returned by crypto.Sign.
+func (s *Signature) Set(signature []byte) {
+	if len(signature) != 65 {
+		panic(fmt.Sprintf("signature of %d bytes, expected 65", len(signature)))
+	}
+	vars.SetBytes32(&s.R, [32]byte(signature[:32]))
+	vars.SetBytes32(&s.S, [32]byte(signature[32:64]))
+	v := int(signature[64])
+	if v < 27 {
+		v += 27
+	}
+	s.V = vars.NewVariableFromInt(v)
+}
+
+// ECDSAAPI is a wrapper around succinct.API that provides methods for secp256k1 ECDSA signatures.
+type ECDSAAPI struct {
+	api builder.API
+}
+
+// Creates a new ECDSAAPI.
+func NewAPI(api *builder.API) *ECDSAAPI {
+	return &ECDSAAPI{api: *api}
+}
+
+// Asserts that the signature of the hash is valid for the public key. The recovery id of the
+// signature is not used.
+func (a *ECDSAAPI) Verify(publicKey PublicKey, hash [32]vars.Byte, signature Signature) {
+	compat.AssertValidECDSA(a.api, hash, signature.R, signature.S, publicKey.X, publicKey.Y)
+}
+
+// Returns the public key that signed the hash, as ecrecover. s must be in the lower half of the
+// scalar field, as for Ethereum transactions.
+func (a *ECDSAAPI) Recover(hash [32]vars.Byte, signature Signature) PublicKey {
+	x, y := compat.ECRecover(a.api, hash, signature.V, signature.R, signature.S)
+	return PublicKey{X: x, Y: y}
+}
+
+// Returns the address of the public key.
+func (a *ECDSAAPI) Address(publicKey PublicKey) [20]vars.Byte {
+	hash := keccak256.Hash(a.api, append(publicKey.X[:], publicKey.Y[:]...))
+	var address [20]vars.Byte
+	copy(address[:], hash[12:])
+	return address
+}
+
+// Returns the address of the signer of the hash.
+func (a *ECDSAAPI) RecoverAddress(hash [32]vars.Byte, signature Signature) [20]vars.Byte {
+	return a.Address(a.Recover(hash, signature))
+}
@@ -0,0 +1,54 @@
+package ecdsa
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	PublicKey PublicKey
+	Hash      [32]vars.Byte
+	Signature Signature
+	Address   [20]vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	ecdsaAPI := NewAPI(api)
+	ecdsaAPI.Verify(c.PublicKey, c.Hash, c.Signature)
+	address := ecdsaAPI.RecoverAddress(c.Hash, c.Signature)
+	for i := range address {
+		api.AssertIsEqualByte(address[i], c.Address[i])
+	}
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	assert.NoError(t, err)
+	hash := crypto.Keccak256Hash([]byte("hello"))
+	signature, err := crypto.Sign(hash.Bytes(), key)
+	assert.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	circuit := &testCircuit{}
+	assignment := &testCircuit{}
+	assignment.PublicKey.Set(&key.PublicKey)
+	vars.SetBytes32(&assignment.Hash, hash)
+	assignment.Signature.Set(signature)
+	for i := range address {
+		assignment.Address[i].Set(address[i])
+	}
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The signature must be of the hash.
+	vars.SetBytes32(&assignment.Hash, crypto.Keccak256Hash([]byte("world")))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,264 @@
+// Verification of Ed25519 signatures, as used by the validators of CometBFT chains. The field
+// arithmetic of edwards25519 is emulated.
+//
+// A signature (R, S) of a message M is valid for the public key A if
+// [8][S]B = [8]R + [8][k]A, where k = sha512(R || A || M) mod L, which is the cofactored
+// verification of ZIP-215 that CometBFT uses. As in ZIP-215, the encodings of A and R need not
+// be canonical but S must be less than L. Every signature valid for crypto/ed25519 is valid here.
+package ed25519
+
+import (
+	goed25519 "crypto/ed25519"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha512"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	// The modulus 2^255 - 19 of the base field.
+	modulus = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+	// The order 2^252 + 27742317777372353535851937790883648493 of the base point.
+	order, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+	// The coefficient d = -121665 / 121666 of the curve -x^2 + y^2 = 1 + d * x^2 * y^2.
+	d = new(big.Int).Mod(new(big.Int).Mul(big.NewInt(-121665), new(big.Int).ModInverse(big.NewInt(121666), modulus)), modulus)
+
+	// The base point B, whose y is 4 / 5 and x is even.
+	baseX, _ = new(big.Int).SetString("15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+	baseY, _ = new(big.Int).SetString("46316835694926478169428394003475163141307993866256225615783033603165251855960", 10)
+)
+
+// The number of bits of the scalars less than the order.
+const scalarBits = 253
+
+type baseField struct{}
+
+func (baseField) NbLimbs() uint     { return 4 }
+func (baseField) BitsPerLimb() uint { return 64 }
+func (baseField) IsPrime() bool     { return true }
+func (baseField) Modulus() *big.Int { return modulus }
+
+type scalarField struct{}
+
+func (scalarField) NbLimbs() uint     { return 4 }
+func (scalarField) BitsPerLimb() uint { return 64 }
+func (scalarField) IsPrime() bool     { return true }
+func (scalarField) Modulus() *big.Int { return order }
+
+// A public key in the circuit, in its 32 byte encoding.
+type PublicKey [goed25519.PublicKeySize]vars.Byte
+
+// Creates a new public key.
+func NewPublicKey() PublicKey {
+	return PublicKey(vars.NewBytes32())
+}
+
+// Sets the public key.
+func (k *PublicKey) Set(publicKey goed25519.PublicKey) {
+	if len(publicKey) != goed25519.PublicKeySize {
+		panic(fmt.Sprintf("public key of %d bytes, expected %d", len(publicKey), goed25519.PublicKeySize))
+	}
+	for i := range k {
+		k[i].Set(publicKey[i])
+	}
+}
+
+// A signature in the circuit, the encoding of R followed by S in little-endian.
+type Signature struct {
+	R [32]vars.Byte
+	S [32]vars.Byte
+}
+
+// Creates a new signature.
+func NewSignature() Signature {
+	return Signature{R: vars.NewBytes32(), S: vars.NewBytes32()}
+}
+
+// Sets the signature from its 64 byte encoding.
+func (s *Signature) Set(signature []byte) {
+	if len(signature) != goed25519.SignatureSize {
+		panic(fmt.Sprintf("signature of %d bytes, expected %d", len(signature), goed25519.SignatureSize))
+	}
+	for i := 0; i < 32; i++ {
+		s.R[i].Set(signature[i])
+		s.S[i].Set(signature[32+i])
+	}
+}
+
+// A point in extended coordinates (X : Y : Z : T), where x = X / Z, y = Y / Z and x * y = T / Z.
+type point struct {
+	X, Y, Z, T *emulated.Element[baseField]
+}
+
+// Ed25519API is a wrapper around succinct.API that provides methods for Ed25519 signatures.
+type Ed25519API struct {
+	api builder.API
+	fp  *emulated.Field[baseField]
+	fr  *emulated.Field[scalarField]
+}
+
+// Creates a new Ed25519API.
+func NewAPI(api *builder.API) *Ed25519API {
+	fp, err := emulated.NewField[baseField](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[scalarField](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	return &Ed25519API{api: *api, fp: fp, fr: fr}
+}
+
+// Asserts that the signature of the message is valid for the public key.
+func (a *Ed25519API) Verify(publicKey PublicKey, message []vars.Byte, signature Signature) {
+	p := a.residue(publicKey, sha512.Hash(a.api, prefix(publicKey, signature, message)), signature)
+	a.fp.AssertIsEqual(p.X, a.fp.Zero())
+	a.fp.AssertIsEqual(p.Y, p.Z)
+}
+
+// Returns whether the signature of the message is valid for the public key without failing the
+// circuit, e.g. for the signatures of the validators that are absent from a commit. The public key
+// and R must still be encodings of points, which the zero encoding is.
+func (a *Ed25519API) IsValid(publicKey PublicKey, message []vars.Byte, signature Signature) vars.Bool {
+	return a.IsValidVariable(publicKey, vars.VariableBytes{Data: message, Length: vars.NewVariableFromInt(len(message))}, signature)
+}
+
+// Returns whether the signature of the first message.Length bytes of message is valid for the
+// public key, as IsValid.
+func (a *Ed25519API) IsValidVariable(publicKey PublicKey, message vars.VariableBytes, signature Signature) vars.Bool {
+	in := prefix(publicKey, signature, message.Data)
+	length := a.api.Add(message.Length, vars.NewVariableFromInt(64))
+	p := a.residue(publicKey, sha512.HashVariable(a.api, in, length), signature)
+	return a.api.And(a.isZero(p.X), a.isZero(a.fp.Sub(p.Y, p.Z)))
+}
+
+// Returns R || A || M, the input of the hash.
+func prefix(publicKey PublicKey, signature Signature, message []vars.Byte) []vars.Byte {
+	in := make([]vars.Byte, 0, 64+len(message))
+	in = append(in, signature.R[:]...)
+	in = append(in, publicKey[:]...)
+	return append(in, message...)
+}
+
+// Returns [8]([S]B - [k]A - R) for the hash of R || A || M, which is the identity for valid
+// signatures.
+func (a *Ed25519API) residue(publicKey PublicKey, hash [64]vars.Byte, signature Signature) point {
+	fr := a.fr
+	pk := a.decompress(publicKey)
+	r := a.decompress(signature.R)
+
+	// S must be canonical, so its bits past the order are zero.
+	sBits := a.bitsLE(signature.S[:])
+	fr.AssertIsInRange(fr.FromBits(sBits...))
+
+	// k is the little-endian hash modulo the order.
+	hBits := a.bitsLE(hash[:])
+	k := fr.Add(fr.FromBits(hBits[:256]...), fr.Mul(fr.FromBits(hBits[256:]...), fr.NewElement(new(big.Int).Lsh(big.NewInt(1), 256))))
+	k = fr.Reduce(k)
+	fr.AssertIsInRange(k)
+	kBits := fr.ToBits(k)
+
+	// [S]B - [k]A with a joint double-and-add over the bits of S and k.
+	identity := a.constant(big.NewInt(0), big.NewInt(1))
+	base := a.constant(baseX, baseY)
+	negPk := a.neg(pk)
+	table := [4]point{identity, base, negPk, a.add(base, negPk)}
+	p := a.lookup(sBits[scalarBits-1], kBits[scalarBits-1], table)
+	for i := scalarBits - 2; i >= 0; i-- {
+		p = a.add(p, p)
+		p = a.add(p, a.lookup(sBits[i], kBits[i], table))
+	}
+
+	p = a.add(p, a.neg(r))
+	for i := 0; i < 3; i++ {
+		p = a.add(p, p)
+	}
+	return p
+}
+
+// Returns the point of an encoding: the little-endian y with the sign of x in its top bit.
+func (a *Ed25519API) decompress(encoding [32]vars.Byte) point {
+	fp := a.fp
+	bits := a.bitsLE(encoding[:])
+	y := fp.FromBits(bits[:255]...)
+	sign := bits[255]
+
+	// x^2 = (y^2 - 1) / (d * y^2 + 1), where the denominator is never zero as -1 / d is not a
+	// square.
+	y2 := fp.Mul(y, y)
+	x2 := fp.Div(fp.Sub(y2, fp.One()), fp.Add(fp.Mul(y2, fp.NewElement(d)), fp.One()))
+	x := fp.Reduce(fp.Sqrt(x2))
+	fp.AssertIsInRange(x)
+	isOdd := fp.ToBits(x)[0]
+	x = fp.Select(a.api.FrontendAPI().Xor(isOdd, sign), fp.Neg(x), x)
+	return point{X: x, Y: y, Z: fp.One(), T: fp.Mul(x, y)}
+}
+
+// Returns the sum of two points with the complete formulas add-2008-hwcd-3, which also double.
+func (a *Ed25519API) add(p, q point) point {
+	fp := a.fp
+	pa := fp.Mul(fp.Sub(p.Y, p.X), fp.Sub(q.Y, q.X))
+	pb := fp.Mul(fp.Add(p.Y, p.X), fp.Add(q.Y, q.X))
+	pc := fp.Mul(fp.Mul(p.T, q.T), fp.NewElement(new(big.Int).Lsh(d, 1)))
+	pd := fp.MulConst(fp.Mul(p.Z, q.Z), big.NewInt(2))
+	e := fp.Sub(pb, pa)
+	f := fp.Sub(pd, pc)
+	g := fp.Add(pd, pc)
+	h := fp.Add(pb, pa)
+	return point{X: fp.Mul(e, f), Y: fp.Mul(g, h), Z: fp.Mul(f, g), T: fp.Mul(e, h)}
+}
+
+func (a *Ed25519API) neg(p point) point {
+	return point{X: a.fp.Neg(p.X), Y: p.Y, Z: p.Z, T: a.fp.Neg(p.T)}
+}
+
+func (a *Ed25519API) constant(x *big.Int, y *big.Int) point {
+	t := new(big.Int).Mod(new(big.Int).Mul(x, y), modulus)
+	return point{X: a.fp.NewElement(x), Y: a.fp.NewElement(y), Z: a.fp.One(), T: a.fp.NewElement(t)}
+}
+
+// Returns table[b0 + 2 * b1]. fp.Lookup2 is not used as in gnark v0.9.1 it drops the limbs past
+// those of its first input, which for the identity is the constant zero without limbs.
+func (a *Ed25519API) lookup(b0, b1 frontend.Variable, table [4]point) point {
+	fp := a.fp
+	lookup2 := func(e0, e1, e2, e3 *emulated.Element[baseField]) *emulated.Element[baseField] {
+		return fp.Select(b1, fp.Select(b0, e3, e2), fp.Select(b0, e1, e0))
+	}
+	return point{
+		X: lookup2(table[0].X, table[1].X, table[2].X, table[3].X),
+		Y: lookup2(table[0].Y, table[1].Y, table[2].Y, table[3].Y),
+		Z: lookup2(table[0].Z, table[1].Z, table[2].Z, table[3].Z),
+		T: lookup2(table[0].T, table[1].T, table[2].T, table[3].T),
+	}
+}
+
+// Returns whether the element is zero modulo the modulus.
+func (a *Ed25519API) isZero(e *emulated.Element[baseField]) vars.Bool {
+	fapi := a.api.FrontendAPI()
+	e = a.fp.Reduce(e)
+	a.fp.AssertIsInRange(e)
+	result := frontend.Variable(1)
+	for i := range e.Limbs {
+		result = fapi.Mul(result, fapi.IsZero(e.Limbs[i]))
+	}
+	return vars.Bool{Value: vars.Variable{Value: result}}
+}
+
+// Returns the little-endian bits of little-endian bytes, which range checks them.
+func (a *Ed25519API) bitsLE(in []vars.Byte) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, len(in)*8)
+	for i := range in {
+		byteBits := a.api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			bits = append(bits, byteBits[j].Value.Value)
+		}
+	}
+	return bits
+}
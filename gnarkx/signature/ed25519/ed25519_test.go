@@ -0,0 +1,80 @@
+package ed25519
+
+import (
+	goed25519 "crypto/ed25519"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	PublicKey PublicKey
+	Message   [13]vars.Byte
+	Signature Signature
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).Verify(c.PublicKey, c.Message[:], c.Signature)
+	return nil
+}
+
+type testVariableCircuit struct {
+	PublicKey PublicKey
+	Message   vars.VariableBytes
+	Signature Signature
+	IsValid   vars.Bool
+}
+
+func (c *testVariableCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqualBool(NewAPI(api).IsValidVariable(c.PublicKey, c.Message, c.Signature), c.IsValid)
+	return nil
+}
+
+func testKey() goed25519.PrivateKey {
+	seed := make([]byte, goed25519.SeedSize)
+	copy(seed, "Succinct Labs")
+	return goed25519.NewKeyFromSeed(seed)
+}
+
+func TestVerify(t *testing.T) {
+	key := testKey()
+	message := []byte("Succinct Labs")
+	signature := goed25519.Sign(key, message)
+
+	circuit := &testCircuit{}
+	assignment := &testCircuit{}
+	assignment.PublicKey.Set(key.Public().(goed25519.PublicKey))
+	copy(assignment.Message[:], vars.NewBytesFrom(message))
+	assignment.Signature.Set(signature)
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The signature must be of the message.
+	assignment.Message[0].Set(byte('s'))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+func TestIsValidVariable(t *testing.T) {
+	key := testKey()
+	message := []byte("Succinct Labs")
+	signature := goed25519.Sign(key, message)
+
+	circuit := &testVariableCircuit{Message: vars.NewVariableBytes(32)}
+	assignment := &testVariableCircuit{Message: vars.NewVariableBytes(32)}
+	assignment.PublicKey.Set(key.Public().(goed25519.PublicKey))
+	vars.SetVariableBytes(&assignment.Message, message)
+	assignment.Signature.Set(signature)
+	assignment.IsValid = vars.NewBool(true)
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The zero signature is invalid without failing the circuit.
+	assignment.Signature.Set(make([]byte, goed25519.SignatureSize))
+	assignment.IsValid = vars.NewBool(false)
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,116 @@
+// Verification of EdDSA signatures over Baby Jubjub with the Poseidon hash, as the
+// EdDSAPoseidonVerifier of circomlib and the keys of Semaphore and of the babyjubjub package.
+//
+// The public key of a secret scalar s is A = s * Base8, and a signature of a message M, an element
+// of the scalar field, is (R8, S) where S * Base8 = R8 + 8 * poseidon(R8.X, R8.Y, A.X, A.Y, M) * A
+// and S is less than the order of Base8.
+package eddsa
+
+import (
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A signature outside of circuits.
+type SignatureValue struct {
+	R8 babyjubjub.PointValue
+	S  *big.Int
+}
+
+// Returns the public key of the secret scalar.
+func PublicKey(secret *big.Int) babyjubjub.PointValue {
+	return babyjubjub.MulBase(secret)
+}
+
+// Signs the message with the secret scalar. The nonce is derived from the secret and the message
+// rather than from the private key as in circomlib, which signatures verify the same way.
+func Sign(secret *big.Int, message *big.Int) SignatureValue {
+	r := new(big.Int).Mod(poseidon.HashValues(secret, message), babyjubjub.Order)
+	r8 := babyjubjub.MulBase(r)
+	h := challenge(r8, PublicKey(secret), message)
+	s := new(big.Int).Mul(h, big.NewInt(8))
+	s.Mul(s, secret)
+	s.Add(s, r)
+	return SignatureValue{R8: r8, S: s.Mod(s, babyjubjub.Order)}
+}
+
+// Returns whether the signature of the message is valid for the public key.
+func Verify(publicKey babyjubjub.PointValue, message *big.Int, signature SignatureValue) bool {
+	if !publicKey.IsOnCurve() || !signature.R8.IsOnCurve() || publicKey.X.Sign() == 0 {
+		return false
+	}
+	if signature.S.Sign() < 0 || signature.S.Cmp(babyjubjub.Order) >= 0 {
+		return false
+	}
+	h := challenge(signature.R8, publicKey, message)
+	right := signature.R8.Add(publicKey.Mul(big.NewInt(8)).Mul(h))
+	return babyjubjub.MulBase(signature.S).Equal(right)
+}
+
+func challenge(r8 babyjubjub.PointValue, publicKey babyjubjub.PointValue, message *big.Int) *big.Int {
+	return poseidon.HashValues(r8.X, r8.Y, publicKey.X, publicKey.Y, message)
+}
+
+// A signature in the circuit.
+type Signature struct {
+	R8 babyjubjub.Point
+	S  vars.Variable
+}
+
+// Creates a new signature.
+func NewSignature() Signature {
+	return Signature{R8: babyjubjub.NewPoint(), S: vars.NewVariable()}
+}
+
+// Sets the signature.
+func (s *Signature) Set(value SignatureValue) {
+	s.R8.Set(value.R8)
+	s.S.Set(value.S)
+}
+
+// EdDSAAPI is a wrapper around succinct.API that provides methods for EdDSA signatures.
+type EdDSAAPI struct {
+	api builder.API
+}
+
+// Creates a new EdDSAAPI.
+func NewAPI(api *builder.API) *EdDSAAPI {
+	return &EdDSAAPI{api: *api}
+}
+
+// Verifies the signature of the message for the public key.
+func (a *EdDSAAPI) Verify(publicKey babyjubjub.Point, message vars.Variable, signature Signature) {
+	api := a.api
+	curve := babyjubjub.NewAPI(&api)
+	curve.AssertIsOnCurve(publicKey)
+	curve.AssertIsOnCurve(signature.R8)
+	api.AssertIsDifferent(publicKey.X, vars.NewVariableFromInt(0))
+	api.AssertIsLessOrEqual(signature.S, vars.Variable{Value: new(big.Int).Sub(babyjubjub.Order, big.NewInt(1))})
+
+	h := poseidon.Hash(api, []vars.Variable{signature.R8.X, signature.R8.Y, publicKey.X, publicKey.Y, message})
+	right := curve.Add(signature.R8, curve.ScalarMul(curve.MulCofactor(publicKey), h))
+	curve.AssertIsEqual(curve.ScalarMulBase(signature.S), right)
+}
+
+// Returns whether the signature of the message is valid for the public key without failing the
+// circuit, for signatures of untrusted data such as decrypted messages. The public key is assumed
+// to be on the curve, and a point R8 off the curve is replaced by Base8 so that the additions are
+// defined.
+func (a *EdDSAAPI) IsValid(publicKey babyjubjub.Point, message vars.Variable, signature Signature) vars.Bool {
+	api := a.api
+	curve := babyjubjub.NewAPI(&api)
+	isOnCurve := curve.IsOnCurve(signature.R8)
+	r8 := curve.Select(isOnCurve, signature.R8, babyjubjub.NewPointFrom(babyjubjub.Base8()))
+	order := vars.Variable{Value: babyjubjub.Order}
+	isReduced := api.IsZero(api.Add(api.Cmp(signature.S, order), vars.NewVariableFromInt(1)))
+
+	h := poseidon.Hash(api, []vars.Variable{r8.X, r8.Y, publicKey.X, publicKey.Y, message})
+	right := curve.Add(r8, curve.ScalarMul(curve.MulCofactor(publicKey), h))
+	isEqual := curve.IsEqual(curve.ScalarMulBase(signature.S), right)
+	isNonzero := api.Not(api.IsZero(publicKey.X))
+	return api.And(api.And(isOnCurve, isReduced), api.And(isEqual, isNonzero))
+}
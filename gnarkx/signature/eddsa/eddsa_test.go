@@ -0,0 +1,88 @@
+package eddsa
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/babyjubjub"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	PublicKey babyjubjub.Point
+	Message   vars.Variable
+	Signature Signature
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).Verify(c.PublicKey, c.Message, c.Signature)
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	secret := big.NewInt(123456789)
+	message := big.NewInt(42)
+	signature := Sign(secret, message)
+	assert.True(t, Verify(PublicKey(secret), message, signature))
+	assert.False(t, Verify(PublicKey(secret), big.NewInt(43), signature))
+
+	circuit := &testCircuit{}
+	assignment := &testCircuit{Message: vars.NewVariableFromInt(42)}
+	assignment.PublicKey.Set(PublicKey(secret))
+	assignment.Signature.Set(signature)
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The signature must be of the message.
+	assignment.Message = vars.NewVariableFromInt(43)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// S must be reduced, although S + order satisfies the equation.
+	malleable := SignatureValue{R8: signature.R8, S: new(big.Int).Add(signature.S, babyjubjub.Order)}
+	assert.False(t, Verify(PublicKey(secret), message, malleable))
+	assignment.Message = vars.NewVariableFromInt(42)
+	assignment.Signature.Set(malleable)
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+type testIsValidCircuit struct {
+	PublicKey babyjubjub.Point
+	Message   vars.Variable
+	Signature Signature
+	Expected  vars.Variable
+}
+
+func (c *testIsValidCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(NewAPI(api).IsValid(c.PublicKey, c.Message, c.Signature).Value, c.Expected)
+	return nil
+}
+
+func TestIsValid(t *testing.T) {
+	secret := big.NewInt(123456789)
+	signature := Sign(secret, big.NewInt(42))
+	offCurve := SignatureValue{R8: babyjubjub.PointValue{X: big.NewInt(1), Y: big.NewInt(2)}, S: signature.S}
+	malleable := SignatureValue{R8: signature.R8, S: new(big.Int).Add(signature.S, babyjubjub.Order)}
+	tests := []struct {
+		message   int
+		signature SignatureValue
+		expected  int
+	}{
+		{42, signature, 1},
+		{43, signature, 0},
+		{42, offCurve, 0},
+		{42, malleable, 0},
+	}
+	for _, tt := range tests {
+		circuit := &testIsValidCircuit{}
+		assignment := &testIsValidCircuit{Message: vars.NewVariableFromInt(tt.message), Expected: vars.NewVariableFromInt(tt.expected)}
+		assignment.PublicKey.Set(PublicKey(secret))
+		assignment.Signature.Set(tt.signature)
+		assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	}
+}
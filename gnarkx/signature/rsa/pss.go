@@ -0,0 +1,90 @@
+package rsa
+
+import (
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Asserts that signature is a valid RSASSA-PSS signature with public exponent 65537 of the SHA-256
+// digest under the modulus, with MGF1 over SHA-256 and a salt of saltLength bytes, as of RFC 8017.
+// All values are big-endian bytes and the signature must have the length of the modulus, which
+// must be a multiple of 8 bytes and whose most significant bit must be set, as for 2048-bit and
+// 4096-bit keys. The bytes of the modulus and signature are assumed to be range checked.
+func AssertValidPSS(api builder.API, modulus []vars.Byte, signature []vars.Byte, digest [32]vars.Byte, saltLength int) {
+	if len(signature) != len(modulus) {
+		panic(fmt.Sprintf("signature length %d does not match modulus length %d", len(signature), len(modulus)))
+	}
+	// The encoded message is maskedDB || H || 0xbc, where DB = zeros || 0x01 || salt.
+	emLength := len(modulus)
+	dbLength := emLength - len(digest) - 1
+	if saltLength < 0 || dbLength < saltLength+1 || emLength > maxModulusLength {
+		panic(fmt.Sprintf("unsupported modulus length %d for a salt of %d bytes", emLength, saltLength))
+	}
+
+	fapi := api.FrontendAPI()
+	api.AssertIsEqual(api.ToBitsFromByte(modulus[0])[7].Value, vars.ONE)
+	n := bigIntFromBytes(api, modulus)
+	x := powMod65537(api, bigIntFromBytes(api, signature), n)
+
+	// The little-endian bits of the encoded message, whose most significant bit is zero, as the
+	// message has 8 * emLength - 1 bits. It is then less than n, so x is canonical.
+	var bits []vars.Bool
+	for i := 0; i < len(x); i++ {
+		bits = append(bits, api.ToBinaryLE(vars.Variable{Value: x[i]}, limbBits)...)
+	}
+	api.AssertIsEqual(bits[8*emLength-1].Value, vars.ZERO)
+	byteBits := func(i int) [8]vars.Bool {
+		var result [8]vars.Bool
+		copy(result[:], bits[8*(emLength-1-i):8*(emLength-i)])
+		return result
+	}
+	assertByte := func(b [8]vars.Bool, value byte, nbBits int) {
+		for j := 0; j < nbBits; j++ {
+			fapi.AssertIsEqual(b[j].Value.Value, int(value>>j&1))
+		}
+	}
+	assertByte(byteBits(emLength-1), 0xbc, 8)
+
+	var h []vars.Byte
+	for i := dbLength; i < emLength-1; i++ {
+		h = append(h, api.ToByteFromBits(byteBits(i)))
+	}
+
+	// DB = maskedDB xor MGF1(H), whose most significant bit is cleared.
+	var mask []vars.Byte
+	for counter := 0; len(mask) < dbLength; counter++ {
+		in := append(append([]vars.Byte{}, h...), vars.NewBytesFrom([]byte{0, 0, byte(counter >> 8), byte(counter)})...)
+		block := sha256.Hash(api, in)
+		mask = append(mask, block[:]...)
+	}
+	salt := make([]vars.Byte, saltLength)
+	for i := 0; i < dbLength; i++ {
+		masked, maskBits := byteBits(i), api.ToBitsFromByte(mask[i])
+		var db [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			db[j] = api.Xor(masked[j], maskBits[j])
+		}
+		switch {
+		case i == 0 && i < dbLength-saltLength-1:
+			assertByte(db, 0, 7)
+		case i < dbLength-saltLength-1:
+			assertByte(db, 0, 8)
+		case i == 0:
+			assertByte(db, 1, 7)
+		case i == dbLength-saltLength-1:
+			assertByte(db, 1, 8)
+		default:
+			salt[i-(dbLength-saltLength)] = api.ToByteFromBits(db)
+		}
+	}
+
+	// H = SHA-256(zeros(8) || digest || salt).
+	in := append(vars.NewBytesFrom(make([]byte, 8)), digest[:]...)
+	expected := sha256.Hash(api, append(in, salt...))
+	for i := 0; i < len(h); i++ {
+		api.AssertIsEqualByte(h[i], expected[i])
+	}
+}
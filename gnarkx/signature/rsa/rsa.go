@@ -0,0 +1,200 @@
+// Verification of RSASSA-PKCS1-v1_5 and RSASSA-PSS signatures with public exponent 65537 and keys
+// of up to 4096 bits, using big integers of 64-bit limbs and a hinted quotient for every modular
+// multiplication.
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/limbutils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	// The number of bits per limb of a big integer.
+	limbBits = 64
+
+	// The offset added to carries so that they are non-negative, and the number of bits they are
+	// range checked to. Carries are bounded by 2^71 in absolute value for moduli of up to 4096
+	// bits.
+	carryOffsetBits = 72
+	carryBits       = 74
+
+	// The largest supported modulus in bytes.
+	maxModulusLength = 512
+)
+
+// The DER encoded DigestInfo prefix of a SHA-256 digest in an EMSA-PKCS1-v1_5 encoding.
+var sha256DigestInfo = []byte{
+	0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20,
+}
+
+// A big integer as little-endian limbs of limbBits bits.
+type bigInt []frontend.Variable
+
+// Packs big-endian bytes into limbs. The bytes are assumed to be range checked.
+func bigIntFromBytes(api builder.API, in []vars.Byte) bigInt {
+	if len(in)%(limbBits/8) != 0 {
+		panic(fmt.Sprintf("length %d is not a multiple of %d bytes", len(in), limbBits/8))
+	}
+	result := make(bigInt, len(in)/(limbBits/8))
+	for i := 0; i < len(result); i++ {
+		limb := frontend.Variable(0)
+		for j := 0; j < limbBits/8; j++ {
+			b := in[len(in)-1-(i*limbBits/8+j)]
+			limb = api.FrontendAPI().Add(limb, api.FrontendAPI().Mul(b.Value.Value, new(big.Int).Lsh(big.NewInt(1), uint(8*j))))
+		}
+		result[i] = limb
+	}
+	return result
+}
+
+// Computes a * b mod n. The result is congruent to a * b modulo n and has as many limbs as n, but
+// is not necessarily the canonical representative.
+func mulMod(api frontend.API, rc frontend.Rangechecker, a, b, n bigInt) bigInt {
+	nbLimbs := len(n)
+	in := make([]frontend.Variable, 0, 3*nbLimbs)
+	in = append(in, a...)
+	in = append(in, b...)
+	in = append(in, n...)
+	out, err := api.Compiler().NewHintForId(mulModHint.ID(), (nbLimbs+1)+nbLimbs+2*nbLimbs, in...)
+	if err != nil {
+		panic(err)
+	}
+	q, r, carries := out[:nbLimbs+1], out[nbLimbs+1:2*nbLimbs+1], out[2*nbLimbs+1:]
+
+	for i := 0; i < len(q); i++ {
+		rc.Check(q[i], limbBits)
+	}
+	for i := 0; i < len(r); i++ {
+		rc.Check(r[i], limbBits)
+	}
+	for i := 0; i < len(carries); i++ {
+		rc.Check(carries[i], carryBits)
+	}
+
+	// Check a * b = q * n + r over the integers, one limb position at a time:
+	//   ab_k - qn_k - r_k + carry_{k-1} = carry_k * 2^limbBits
+	offset := new(big.Int).Lsh(big.NewInt(1), carryOffsetBits)
+	base := new(big.Int).Lsh(big.NewInt(1), limbBits)
+	prevCarry := frontend.Variable(0)
+	for k := 0; k < 2*nbLimbs; k++ {
+		acc := prevCarry
+		for i := 0; i <= k; i++ {
+			j := k - i
+			if i < nbLimbs && j < nbLimbs {
+				acc = api.Add(acc, api.Mul(a[i], b[j]))
+			}
+			if i < nbLimbs+1 && j < nbLimbs {
+				acc = api.Sub(acc, api.Mul(q[i], n[j]))
+			}
+		}
+		if k < nbLimbs {
+			acc = api.Sub(acc, r[k])
+		}
+		carry := api.Sub(carries[k], offset)
+		api.AssertIsEqual(acc, api.Mul(carry, base))
+		prevCarry = carry
+	}
+	api.AssertIsEqual(prevCarry, 0)
+
+	result := make(bigInt, nbLimbs)
+	copy(result, r)
+	return result
+}
+
+// Computes the quotient, remainder, and carries of a * b = q * n + r. The inputs are the limbs of
+// a, b, and n.
+var mulModHint = builder.NewHint("rsa.mulmod", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	nbLimbs := len(inputs) / 3
+	if len(inputs) != 3*nbLimbs || len(outputs) != 4*nbLimbs+1 {
+		return fmt.Errorf("mulmod: invalid number of inputs or outputs")
+	}
+	a, b, n := inputs[:nbLimbs], inputs[nbLimbs:2*nbLimbs], inputs[2*nbLimbs:]
+	aInt, bInt, nInt := limbutils.FromLimbs(a, limbBits), limbutils.FromLimbs(b, limbBits), limbutils.FromLimbs(n, limbBits)
+	if nInt.Sign() == 0 {
+		return fmt.Errorf("mulmod: modulus is zero")
+	}
+	qInt, rInt := new(big.Int).QuoRem(new(big.Int).Mul(aInt, bInt), nInt, new(big.Int))
+	q, r := limbutils.ToLimbs(qInt, nbLimbs+1, limbBits), limbutils.ToLimbs(rInt, nbLimbs, limbBits)
+	for i := 0; i < len(q); i++ {
+		outputs[i].Set(q[i])
+	}
+	for i := 0; i < len(r); i++ {
+		outputs[nbLimbs+1+i].Set(r[i])
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(1), carryOffsetBits)
+	carry := new(big.Int)
+	for k := 0; k < 2*nbLimbs; k++ {
+		acc := new(big.Int).Set(carry)
+		for i := 0; i <= k; i++ {
+			j := k - i
+			if i < nbLimbs && j < nbLimbs {
+				acc.Add(acc, new(big.Int).Mul(a[i], b[j]))
+			}
+			if i < nbLimbs+1 && j < nbLimbs {
+				acc.Sub(acc, new(big.Int).Mul(q[i], n[j]))
+			}
+		}
+		if k < nbLimbs {
+			acc.Sub(acc, r[k])
+		}
+		// The accumulator is always divisible by 2^limbBits, so the shift is exact.
+		carry = acc.Rsh(acc, limbBits)
+		outputs[2*nbLimbs+1+k].Add(carry, offset)
+	}
+	return nil
+})
+
+// Computes s^65537 mod n, as s^(2^16) * s, which is congruent to the power modulo n and has as
+// many limbs as n, but is not necessarily the canonical representative.
+func powMod65537(api builder.API, s, n bigInt) bigInt {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	x := s
+	for i := 0; i < 16; i++ {
+		x = mulMod(fapi, rc, x, x, n)
+	}
+	return mulMod(fapi, rc, x, s, n)
+}
+
+// Asserts that signature is a valid RSASSA-PKCS1-v1_5 signature with public exponent 65537 of the
+// SHA-256 digest under the modulus. All values are big-endian bytes and the signature must have
+// the length of the modulus, which must be a multiple of 8 bytes. The bytes of the modulus and
+// signature are assumed to be range checked.
+func AssertValidPKCS1v15(api builder.API, modulus []vars.Byte, signature []vars.Byte, digest [32]vars.Byte) {
+	if len(signature) != len(modulus) {
+		panic(fmt.Sprintf("signature length %d does not match modulus length %d", len(signature), len(modulus)))
+	}
+	// At least 8 bytes of 0xff padding are required.
+	if len(modulus) < 3+8+len(sha256DigestInfo)+len(digest) || len(modulus) > maxModulusLength {
+		panic(fmt.Sprintf("unsupported modulus length %d", len(modulus)))
+	}
+
+	fapi := api.FrontendAPI()
+	n := bigIntFromBytes(api, modulus)
+	x := powMod65537(api, bigIntFromBytes(api, signature), n)
+
+	// The result must equal 0x00 0x01 0xff..0xff 0x00 || DigestInfo || digest.
+	em := make([]vars.Byte, len(modulus))
+	padLength := len(em) - 3 - len(sha256DigestInfo) - len(digest)
+	em[0] = vars.ZERO_BYTE
+	em[1] = vars.Byte{Value: vars.ONE}
+	for i := 0; i < padLength; i++ {
+		em[2+i] = vars.Byte{Value: vars.NewVariableFromInt(0xff)}
+	}
+	em[2+padLength] = vars.ZERO_BYTE
+	for i := 0; i < len(sha256DigestInfo); i++ {
+		em[3+padLength+i] = vars.Byte{Value: vars.NewVariableFromInt(int(sha256DigestInfo[i]))}
+	}
+	copy(em[3+padLength+len(sha256DigestInfo):], digest[:])
+	expected := bigIntFromBytes(api, em)
+	for i := 0; i < len(n); i++ {
+		fapi.AssertIsEqual(x[i], expected[i])
+	}
+}
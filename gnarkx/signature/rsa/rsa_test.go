@@ -0,0 +1,86 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Modulus   []vars.Byte
+	Signature []vars.Byte
+	Digest    [32]vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	AssertValidPKCS1v15(*api, c.Modulus, c.Signature, c.Digest)
+	return nil
+}
+
+func TestPKCS1v15(t *testing.T) {
+	for _, bits := range []int{1024, 2048} {
+		key, err := gorsa.GenerateKey(rand.Reader, bits)
+		assert.NoError(t, err)
+		digest := sha256.Sum256([]byte("succinct"))
+		signature, err := gorsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+
+		circuit := testCircuit{Modulus: vars.NewBytes(bits / 8), Signature: vars.NewBytes(bits / 8)}
+		assignment := testCircuit{
+			Modulus:   vars.NewBytesFrom(key.N.FillBytes(make([]byte, bits/8))),
+			Signature: vars.NewBytesFrom(signature),
+		}
+		vars.SetBytes32(&assignment.Digest, digest)
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+
+		vars.SetBytes32(&assignment.Digest, sha256.Sum256([]byte("succinctx")))
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.Error(t, err)
+	}
+}
+
+type testPSSCircuit struct {
+	Modulus   []vars.Byte
+	Signature []vars.Byte
+	Digest    [32]vars.Byte
+}
+
+func (c *testPSSCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	AssertValidPSS(*api, c.Modulus, c.Signature, c.Digest, sha256.Size)
+	return nil
+}
+
+func TestPSS(t *testing.T) {
+	for _, bits := range []int{2048, 4096} {
+		key, err := gorsa.GenerateKey(rand.Reader, bits)
+		assert.NoError(t, err)
+		digest := sha256.Sum256([]byte("succinct"))
+		signature, err := gorsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &gorsa.PSSOptions{SaltLength: gorsa.PSSSaltLengthEqualsHash})
+		assert.NoError(t, err)
+
+		circuit := testPSSCircuit{Modulus: vars.NewBytes(bits / 8), Signature: vars.NewBytes(bits / 8)}
+		assignment := testPSSCircuit{
+			Modulus:   vars.NewBytesFrom(key.N.FillBytes(make([]byte, bits/8))),
+			Signature: vars.NewBytesFrom(signature),
+		}
+		vars.SetBytes32(&assignment.Digest, digest)
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+
+		vars.SetBytes32(&assignment.Digest, sha256.Sum256([]byte("succinctx")))
+		err = test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField())
+		assert.Error(t, err)
+	}
+}
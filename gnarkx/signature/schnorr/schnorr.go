@@ -0,0 +1,107 @@
+// Verification of BIP-340 Schnorr signatures over secp256k1, as used by the key path spends of
+// Taproot outputs. The field arithmetic of secp256k1 is emulated.
+//
+// A public key is the x coordinate of the point P whose y is even. A signature (r, s) of a 32 byte
+// message m is valid if R = [s]G - [e]P is not the identity, has an even y and has x = r, where
+// e = int(hash_BIP0340/challenge(r || x(P) || m)) mod n, with r less than the modulus and s less
+// than the order n.
+//
+// Reference: https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+package schnorr
+
+import (
+	gosha256 "crypto/sha256"
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/curves/secp256k1"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The tag of the hash of the challenge.
+const challengeTag = "BIP0340/challenge"
+
+// A public key in the circuit, the big-endian x coordinate of its point.
+type PublicKey [32]vars.Byte
+
+// Creates a new public key.
+func NewPublicKey() PublicKey {
+	return PublicKey(vars.NewBytes32())
+}
+
+// Sets the public key from its 32 byte encoding.
+func (k *PublicKey) Set(publicKey []byte) {
+	if len(publicKey) != 32 {
+		panic(fmt.Sprintf("public key of %d bytes, expected 32", len(publicKey)))
+	}
+	vars.SetBytes32((*[32]vars.Byte)(k), [32]byte(publicKey))
+}
+
+// A signature in the circuit, the big-endian x coordinate of R followed by the big-endian s.
+type Signature struct {
+	R [32]vars.Byte
+	S [32]vars.Byte
+}
+
+// Creates a new signature.
+func NewSignature() Signature {
+	return Signature{R: vars.NewBytes32(), S: vars.NewBytes32()}
+}
+
+// Sets the signature from its 64 byte encoding.
+func (s *Signature) Set(signature []byte) {
+	if len(signature) != 64 {
+		panic(fmt.Sprintf("signature of %d bytes, expected 64", len(signature)))
+	}
+	vars.SetBytes32(&s.R, [32]byte(signature[:32]))
+	vars.SetBytes32(&s.S, [32]byte(signature[32:]))
+}
+
+// SchnorrAPI is a wrapper around succinct.API that provides methods for BIP-340 Schnorr signatures.
+type SchnorrAPI struct {
+	api   builder.API
+	curve *secp256k1.Secp256k1API
+}
+
+// Creates a new SchnorrAPI.
+func NewAPI(api *builder.API) *SchnorrAPI {
+	return &SchnorrAPI{api: *api, curve: secp256k1.NewAPI(api)}
+}
+
+// Asserts that the signature of the message is valid for the public key.
+func (a *SchnorrAPI) Verify(publicKey PublicKey, message [32]vars.Byte, signature Signature) {
+	curve := a.curve
+
+	// P = lift_x(x), whose y is even, which fails unless x is that of a point less than the modulus.
+	var encoding [33]vars.Byte
+	encoding[0] = vars.NewBytesFrom([]byte{0x02})[0]
+	copy(encoding[1:], publicKey[:])
+	p := curve.DecompressBytes(encoding)
+
+	s := curve.ScalarFromCanonicalBytes(signature.S)
+	e := curve.ScalarFromBytes(a.Challenge(signature.R, publicKey, message))
+
+	// R = [s]G - [e]P, whose canonical x must be r, which is thus less than the modulus.
+	r := curve.JointScalarMulBase(curve.Neg(p), e, s)
+	a.api.AssertIsEqualBool(curve.IsIdentity(r), vars.NewBool(false))
+	x, y := curve.PointToBytes(r)
+	a.api.AssertIsEqualBytes32(x, signature.R)
+	a.api.AssertIsEqualBool(a.api.ToBitsFromByte(y[31])[0], vars.NewBool(false))
+}
+
+// Returns the hash of the challenge, hash_BIP0340/challenge(r || x(P) || m).
+func (a *SchnorrAPI) Challenge(r [32]vars.Byte, publicKey PublicKey, message [32]vars.Byte) [32]vars.Byte {
+	return TaggedHash(a.api, challengeTag, r[:], publicKey[:], message[:])
+}
+
+// Returns the tagged hash of BIP-340, sha256(sha256(tag) || sha256(tag) || x), of the
+// concatenation of the inputs, where the tag is a compile time constant.
+func TaggedHash(api builder.API, tag string, in ...[]vars.Byte) [32]vars.Byte {
+	tagHash := gosha256.Sum256([]byte(tag))
+	data := vars.NewBytesFrom(append(tagHash[:], tagHash[:]...))
+	for i := range in {
+		data = append(data, in[i]...)
+	}
+	return sha256.Hash(api, data)
+}
@@ -0,0 +1,87 @@
+package schnorr
+
+import (
+	gosha256 "crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gosecp256k1 "github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/curves/secp256k1"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	PublicKey PublicKey
+	Message   [32]vars.Byte
+	Signature Signature
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	NewAPI(api).Verify(c.PublicKey, c.Message, c.Signature)
+	return nil
+}
+
+func taggedHashValue(tag string, in ...[]byte) []byte {
+	tagHash := gosha256.Sum256([]byte(tag))
+	h := gosha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for i := range in {
+		h.Write(in[i])
+	}
+	return h.Sum(nil)
+}
+
+// Signs the message with the secret key and the nonce as BIP-340, returning the public key and the
+// signature.
+func sign(secretKey, nonce *big.Int, message []byte) ([]byte, []byte) {
+	_, g := gosecp256k1.Generators()
+	var p, r gosecp256k1.G1Affine
+	p.ScalarMultiplication(&g, secretKey)
+	d := new(big.Int).Set(secretKey)
+	if p.Y.BigInt(new(big.Int)).Bit(0) == 1 {
+		d.Sub(secp256k1.Order, d)
+	}
+	r.ScalarMultiplication(&g, nonce)
+	k := new(big.Int).Set(nonce)
+	if r.Y.BigInt(new(big.Int)).Bit(0) == 1 {
+		k.Sub(secp256k1.Order, k)
+	}
+	px, rx := p.X.Bytes(), r.X.Bytes()
+	e := new(big.Int).SetBytes(taggedHashValue(challengeTag, rx[:], px[:], message))
+	s := new(big.Int).Mod(new(big.Int).Add(k, new(big.Int).Mul(e, d)), secp256k1.Order)
+	signature := append(rx[:], make([]byte, 32)...)
+	s.FillBytes(signature[32:])
+	return px[:], signature
+}
+
+func isSolved(publicKey, message, signature []byte) error {
+	assignment := &testCircuit{}
+	assignment.PublicKey.Set(publicKey)
+	vars.SetBytes32(&assignment.Message, [32]byte(message))
+	assignment.Signature.Set(signature)
+	return test.IsSolved(&testCircuit{}, assignment, ecc.BN254.ScalarField())
+}
+
+func TestVerify(t *testing.T) {
+	// The first test vector of BIP-340.
+	publicKey, _ := hex.DecodeString("F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9")
+	signature, _ := hex.DecodeString("E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0")
+	assert.NoError(t, isSolved(publicKey, make([]byte, 32), signature))
+
+	// A signature of the signer of the test.
+	message := gosha256.Sum256([]byte("Succinct Labs"))
+	publicKey, signature = sign(big.NewInt(12345), big.NewInt(67890), message[:])
+	assert.NoError(t, isSolved(publicKey, message[:], signature))
+
+	// The signature must be of the message.
+	message[0] ^= 1
+	assert.Error(t, isSolved(publicKey, message[:], signature))
+}
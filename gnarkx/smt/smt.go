@@ -0,0 +1,210 @@
+// The API for sparse Merkle trees of 32 byte keys and values over the hashers of the merkle
+// package, for the state of rollups that circuits read, insert into and update.
+//
+// A tree of depth d has 2^d leaves, where the leaf of a key is at the index of its first d bits,
+// from the root to the leaf. An empty leaf is zero and the leaf of a key with a value is
+// hasher(key, value), so that the values can be zero. Keys whose first d bits are equal cannot both
+// be in the tree, which is negligible for keys that are hashes in a tree of depth 256. The
+// non-inclusion of a key is proven by the emptiness of its leaf, and the siblings of a leaf are
+// the same before and after it is set, so that one proof serves both the old and the new root.
+package smt
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum depth of a tree, the number of bits of a key.
+const MaxDepth = 256
+
+// Returns the leaf of a key with a value.
+func LeafValue(hasher merkle.Hasher, key [32]byte, value [32]byte) [32]byte {
+	return hasher.HashValues(key, value)
+}
+
+type entry struct {
+	key   [32]byte
+	value [32]byte
+}
+
+// A sparse Merkle tree outside of circuits.
+type Tree struct {
+	hasher  merkle.Hasher
+	entries map[string]entry
+	levels  []map[string][32]byte
+	zeros   [][32]byte
+}
+
+// Creates an empty sparse Merkle tree of the depth.
+func NewTree(hasher merkle.Hasher, depth int) *Tree {
+	if depth <= 0 || depth > MaxDepth {
+		panic(fmt.Sprintf("depth %d is not in [1, %d]", depth, MaxDepth))
+	}
+	t := &Tree{hasher: hasher, entries: make(map[string]entry), levels: make([]map[string][32]byte, depth+1), zeros: make([][32]byte, depth+1)}
+	for i := 0; i <= depth; i++ {
+		t.levels[i] = make(map[string][32]byte)
+		if i > 0 {
+			t.zeros[i] = hasher.HashValues(t.zeros[i-1], t.zeros[i-1])
+		}
+	}
+	return t
+}
+
+// Returns the depth of the tree.
+func (t *Tree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Returns the root of the tree.
+func (t *Tree) Root() [32]byte {
+	return t.node(t.Depth(), new(big.Int))
+}
+
+// Returns the value of the key and whether it is in the tree.
+func (t *Tree) Get(key [32]byte) ([32]byte, bool) {
+	e, ok := t.entries[t.index(key).String()]
+	if !ok || e.key != key {
+		return [32]byte{}, false
+	}
+	return e.value, true
+}
+
+// Returns the siblings of the leaf of the key, from the leaf to the root.
+func (t *Tree) Proof(key [32]byte) [][32]byte {
+	index := t.index(key)
+	siblings := make([][32]byte, t.Depth())
+	for i := range siblings {
+		siblings[i] = t.node(i, new(big.Int).Xor(index, big.NewInt(1)))
+		index.Rsh(index, 1)
+	}
+	return siblings
+}
+
+// Inserts the key, which is not in the tree, with the value and returns the proof of its leaf.
+func (t *Tree) Insert(key [32]byte, value [32]byte) ([][32]byte, error) {
+	if e, ok := t.entries[t.index(key).String()]; ok {
+		return nil, fmt.Errorf("the leaf of key %x holds key %x", key, e.key)
+	}
+	return t.set(key, value), nil
+}
+
+// Updates the value of the key, which is in the tree, and returns the proof of its leaf.
+func (t *Tree) Update(key [32]byte, value [32]byte) ([][32]byte, error) {
+	if _, ok := t.Get(key); !ok {
+		return nil, fmt.Errorf("key %x is not in the tree", key)
+	}
+	return t.set(key, value), nil
+}
+
+// Returns the index of the leaf of the key, its first depth bits.
+func (t *Tree) index(key [32]byte) *big.Int {
+	return new(big.Int).Rsh(new(big.Int).SetBytes(key[:]), uint(MaxDepth-t.Depth()))
+}
+
+func (t *Tree) node(level int, index *big.Int) [32]byte {
+	if node, ok := t.levels[level][index.String()]; ok {
+		return node
+	}
+	return t.zeros[level]
+}
+
+func (t *Tree) set(key [32]byte, value [32]byte) [][32]byte {
+	proof := t.Proof(key)
+	index := t.index(key)
+	t.entries[index.String()] = entry{key: key, value: value}
+	node := LeafValue(t.hasher, key, value)
+	t.levels[0][index.String()] = node
+	for i := 0; i < t.Depth(); i++ {
+		if index.Bit(0) == 1 {
+			node = t.hasher.HashValues(proof[i], node)
+		} else {
+			node = t.hasher.HashValues(node, proof[i])
+		}
+		index.Rsh(index, 1)
+		t.levels[i+1][index.String()] = node
+	}
+	return proof
+}
+
+// The siblings of a leaf from the leaf to the root in the circuit.
+type Proof struct {
+	Siblings [][32]vars.Byte
+}
+
+// Creates a new proof for trees of the depth.
+func NewProof(depth int) Proof {
+	return Proof{Siblings: vars.NewBytes32Array(depth)}
+}
+
+// Sets the proof.
+func (p *Proof) Set(siblings [][32]byte) error {
+	if len(siblings) != len(p.Siblings) {
+		return fmt.Errorf("tree of depth %d, expected %d", len(siblings), len(p.Siblings))
+	}
+	vars.SetBytes32Array(&p.Siblings, siblings)
+	return nil
+}
+
+// SMTAPI is a wrapper around succinct.API that provides methods for sparse Merkle trees.
+type SMTAPI struct {
+	api builder.API
+}
+
+// Creates a new SMTAPI.
+func NewAPI(api *builder.API) *SMTAPI {
+	return &SMTAPI{api: *api}
+}
+
+// Returns the root of the tree whose leaf of the key is the leaf with the proof, where the depth of
+// the tree is len(proof.Siblings), a compile time constant.
+func (a *SMTAPI) RestoreRoot(hasher merkle.Hasher, key [32]vars.Byte, leaf [32]vars.Byte, proof Proof) [32]vars.Byte {
+	api := a.api
+	depth := len(proof.Siblings)
+	if depth <= 0 || depth > MaxDepth {
+		panic(fmt.Sprintf("depth %d is not in [1, %d]", depth, MaxDepth))
+	}
+	node := leaf
+	for i := range proof.Siblings {
+		// The bit of the level of the sibling i, counting from the root.
+		j := depth - 1 - i
+		bit := api.ToBitsFromByte(key[j/8])[7-j%8]
+		left := api.SelectBytes32(bit, proof.Siblings[i], node)
+		right := api.SelectBytes32(bit, node, proof.Siblings[i])
+		node = hasher.Hash(api, left, right)
+	}
+	return node
+}
+
+// Verifies that the key is in the tree of the root with the value.
+func (a *SMTAPI) VerifyInclusion(hasher merkle.Hasher, root [32]vars.Byte, key [32]vars.Byte, value [32]vars.Byte, proof Proof) {
+	a.api.AssertIsEqualBytes32(root, a.RestoreRoot(hasher, key, hasher.Hash(a.api, key, value), proof))
+}
+
+// Verifies that the key is not in the tree of the root, i.e. that its leaf is empty.
+func (a *SMTAPI) VerifyNonInclusion(hasher merkle.Hasher, root [32]vars.Byte, key [32]vars.Byte, proof Proof) {
+	a.api.AssertIsEqualBytes32(root, a.RestoreRoot(hasher, key, vars.NewBytes32(), proof))
+}
+
+// Inserts the key, which is not in the tree of the root, with the value and returns the new root.
+func (a *SMTAPI) Insert(hasher merkle.Hasher, root [32]vars.Byte, key [32]vars.Byte, value [32]vars.Byte, proof Proof) [32]vars.Byte {
+	a.VerifyNonInclusion(hasher, root, key, proof)
+	return a.RestoreRoot(hasher, key, hasher.Hash(a.api, key, value), proof)
+}
+
+// Updates the value of the key, which is in the tree of the root with the old value, and returns
+// the new root.
+func (a *SMTAPI) Update(
+	hasher merkle.Hasher,
+	root [32]vars.Byte,
+	key [32]vars.Byte,
+	oldValue [32]vars.Byte,
+	newValue [32]vars.Byte,
+	proof Proof,
+) [32]vars.Byte {
+	a.VerifyInclusion(hasher, root, key, oldValue, proof)
+	return a.RestoreRoot(hasher, key, hasher.Hash(a.api, key, newValue), proof)
+}
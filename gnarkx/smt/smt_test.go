@@ -0,0 +1,121 @@
+package smt
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Root        [32]vars.Byte
+	Key         [32]vars.Byte
+	Value       [32]vars.Byte
+	InsertProof Proof
+	NewValue    [32]vars.Byte
+	UpdateProof Proof
+	NewRoot     [32]vars.Byte
+	Absent      [32]vars.Byte
+	AbsentProof Proof
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	smtAPI := NewAPI(api)
+	hasher := merkle.Poseidon{}
+	root := smtAPI.Insert(hasher, c.Root, c.Key, c.Value, c.InsertProof)
+	root = smtAPI.Update(hasher, root, c.Key, c.Value, c.NewValue, c.UpdateProof)
+	api.AssertIsEqualBytes32(root, c.NewRoot)
+	smtAPI.VerifyInclusion(hasher, root, c.Key, c.NewValue, c.UpdateProof)
+	smtAPI.VerifyNonInclusion(hasher, root, c.Absent, c.AbsentProof)
+	return nil
+}
+
+func newTestCircuit(depth int) *testCircuit {
+	return &testCircuit{InsertProof: NewProof(depth), UpdateProof: NewProof(depth), AbsentProof: NewProof(depth)}
+}
+
+func key(b ...byte) [32]byte {
+	var result [32]byte
+	copy(result[:], b)
+	return result
+}
+
+func TestTree(t *testing.T) {
+	hasher := merkle.SHA256{}
+	tree := NewTree(hasher, 3)
+	_, err := tree.Insert(key(0b01000000), key(1))
+	assert.NoError(t, err)
+	_, err = tree.Insert(key(0b11100000), key(2))
+	assert.NoError(t, err)
+
+	// The root is the root of the 8 leaves, where the keys are at the indexes of their first 3 bits.
+	leaves := make([][32]byte, 8)
+	leaves[2] = LeafValue(hasher, key(0b01000000), key(1))
+	leaves[7] = LeafValue(hasher, key(0b11100000), key(2))
+	for len(leaves) > 1 {
+		for i := 0; i < len(leaves)/2; i++ {
+			leaves[i] = hasher.HashValues(leaves[2*i], leaves[2*i+1])
+		}
+		leaves = leaves[:len(leaves)/2]
+	}
+	assert.Equal(t, leaves[0], tree.Root())
+	assert.Equal(t, tree.Root(), merkle.RootValue(hasher, leaves[0], nil, 0))
+	assert.Equal(t, tree.Root(), merkle.RootValue(hasher, LeafValue(hasher, key(0b01000000), key(1)), tree.Proof(key(0b01000000)), 2))
+
+	// A key whose leaf holds another key can be neither inserted nor updated.
+	_, err = tree.Insert(key(0b01011111), key(3))
+	assert.Error(t, err)
+	_, err = tree.Update(key(0b01011111), key(3))
+	assert.Error(t, err)
+	value, ok := tree.Get(key(0b01000000))
+	assert.True(t, ok)
+	assert.Equal(t, key(1), value)
+	_, ok = tree.Get(key(0b01011111))
+	assert.False(t, ok)
+}
+
+func TestSMT(t *testing.T) {
+	depth := 32
+	tree := NewTree(merkle.Poseidon{}, depth)
+	_, err := tree.Insert(key(1, 2, 3), key(4))
+	assert.NoError(t, err)
+
+	circuit := newTestCircuit(depth)
+	assignment := newTestCircuit(depth)
+	vars.SetBytes32(&assignment.Root, tree.Root())
+	vars.SetBytes32(&assignment.Key, key(0xff, 0, 0, 1))
+	vars.SetBytes32(&assignment.Value, key(5))
+	proof, err := tree.Insert(key(0xff, 0, 0, 1), key(5))
+	assert.NoError(t, err)
+	assert.NoError(t, assignment.InsertProof.Set(proof))
+	vars.SetBytes32(&assignment.NewValue, key(6))
+	proof, err = tree.Update(key(0xff, 0, 0, 1), key(6))
+	assert.NoError(t, err)
+	assert.NoError(t, assignment.UpdateProof.Set(proof))
+	vars.SetBytes32(&assignment.NewRoot, tree.Root())
+	vars.SetBytes32(&assignment.Absent, key(1, 2, 4))
+	assert.NoError(t, assignment.AbsentProof.Set(tree.Proof(key(1, 2, 4))))
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A key in the tree is not absent.
+	vars.SetBytes32(&assignment.Absent, key(1, 2, 3))
+	assert.NoError(t, assignment.AbsentProof.Set(tree.Proof(key(1, 2, 3))))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	vars.SetBytes32(&assignment.Absent, key(1, 2, 4))
+	assert.NoError(t, assignment.AbsentProof.Set(tree.Proof(key(1, 2, 4))))
+
+	// The new root must be of the new value.
+	vars.SetBytes32(&assignment.NewValue, key(7))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A key in the tree cannot be inserted.
+	vars.SetBytes32(&assignment.NewValue, key(6))
+	vars.SetBytes32(&assignment.Root, tree.Root())
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
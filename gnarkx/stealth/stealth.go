@@ -0,0 +1,172 @@
+// The API for the stealth addresses of ERC-5564 with the scheme 1 of secp256k1: a recipient with a
+// stealth meta-address of a spending key and a viewing key proves that a stealth address derives
+// from the ephemeral public key of an announcement without disclosing its viewing key.
+// Reference: https://eips.ethereum.org/EIPS/eip-5564
+//
+// The sender derives the shared secret S = ephemeralKey * viewingPublicKey, which the recipient
+// derives as viewingKey * ephemeralPublicKey, and:
+//   - hashedSecret = keccak256(compressed(S))
+//   - viewTag = hashedSecret[0]
+//   - stealthPublicKey = spendingPublicKey + hashedSecret * G
+//
+// where compressed is the 33 bytes of SEC1, as the getSharedSecret of the reference implementation,
+// and the stealth address is the address of the stealth public key, whose private key is
+// spendingKey + hashedSecret.
+package stealth
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The scheme identifier of secp256k1 in the announcements of ERC-5564.
+const SchemeID = 1
+
+// The prefix of the stealth meta-addresses of Ethereum.
+const metaAddressPrefix = "st:eth:0x"
+
+// A stealth meta-address outside of circuits.
+type MetaAddress struct {
+	SpendingKey *ecdsa.PublicKey
+	ViewingKey  *ecdsa.PublicKey
+}
+
+// Parses a stealth meta-address st:eth:0x<spendingKey><viewingKey> of compressed public keys.
+func ParseMetaAddress(s string) (*MetaAddress, error) {
+	if !strings.HasPrefix(s, metaAddressPrefix) {
+		return nil, fmt.Errorf("stealth meta-address without prefix %s", metaAddressPrefix)
+	}
+	encoded, err := hex.DecodeString(s[len(metaAddressPrefix):])
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) != 66 {
+		return nil, fmt.Errorf("stealth meta-address of %d bytes, expected 66", len(encoded))
+	}
+	spendingKey, err := crypto.DecompressPubkey(encoded[:33])
+	if err != nil {
+		return nil, err
+	}
+	viewingKey, err := crypto.DecompressPubkey(encoded[33:])
+	if err != nil {
+		return nil, err
+	}
+	return &MetaAddress{SpendingKey: spendingKey, ViewingKey: viewingKey}, nil
+}
+
+// Returns the encoding of the stealth meta-address.
+func (m *MetaAddress) String() string {
+	return metaAddressPrefix + hex.EncodeToString(append(crypto.CompressPubkey(m.SpendingKey), crypto.CompressPubkey(m.ViewingKey)...))
+}
+
+// Returns the stealth address and the view tag of the meta-address for the ephemeral key of the
+// sender, whose public key is the ephemeral public key of the announcement.
+func (m *MetaAddress) Generate(ephemeralKey *ecdsa.PrivateKey) (common.Address, byte) {
+	return derive(m.SpendingKey, sharedSecret(m.ViewingKey, ephemeralKey.D))
+}
+
+// Returns the stealth address and the view tag of the announcement of the ephemeral public key
+// for the viewing key and the spending public key of the recipient.
+func Check(viewingKey *ecdsa.PrivateKey, spendingKey *ecdsa.PublicKey, ephemeralKey *ecdsa.PublicKey) (common.Address, byte) {
+	return derive(spendingKey, sharedSecret(ephemeralKey, viewingKey.D))
+}
+
+// Returns the private key of the stealth address of the announcement of the ephemeral public key.
+func PrivateKey(spendingKey *ecdsa.PrivateKey, viewingKey *ecdsa.PrivateKey, ephemeralKey *ecdsa.PublicKey) (*ecdsa.PrivateKey, error) {
+	hashedSecret := new(big.Int).SetBytes(sharedSecret(ephemeralKey, viewingKey.D))
+	d := new(big.Int).Add(spendingKey.D, hashedSecret)
+	d.Mod(d, crypto.S256().Params().N)
+	return crypto.ToECDSA(common.LeftPadBytes(d.Bytes(), 32))
+}
+
+// Returns the hash of the compressed shared secret of the public key and the scalar.
+func sharedSecret(publicKey *ecdsa.PublicKey, d *big.Int) []byte {
+	x, y := crypto.S256().ScalarMult(publicKey.X, publicKey.Y, common.LeftPadBytes(d.Bytes(), 32))
+	return crypto.Keccak256(crypto.CompressPubkey(&ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y}))
+}
+
+func derive(spendingKey *ecdsa.PublicKey, hashedSecret []byte) (common.Address, byte) {
+	curve := crypto.S256()
+	x, y := curve.ScalarBaseMult(hashedSecret)
+	x, y = curve.Add(spendingKey.X, spendingKey.Y, x, y)
+	return crypto.PubkeyToAddress(ecdsa.PublicKey{Curve: curve, X: x, Y: y}), hashedSecret[0]
+}
+
+// A secp256k1 public key as big-endian coordinates in the circuit.
+type PublicKey struct {
+	X [32]vars.Byte
+	Y [32]vars.Byte
+}
+
+// Sets the public key.
+func (k *PublicKey) Set(publicKey *ecdsa.PublicKey) {
+	vars.SetBytes32(&k.X, [32]byte(common.LeftPadBytes(publicKey.X.Bytes(), 32)))
+	vars.SetBytes32(&k.Y, [32]byte(common.LeftPadBytes(publicKey.Y.Bytes(), 32)))
+}
+
+type curve = sw_emulated.Curve[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+
+type point = sw_emulated.AffinePoint[emulated.Secp256k1Fp]
+
+// StealthAPI is a wrapper around succinct.API that provides methods for stealth addresses.
+type StealthAPI struct {
+	api   builder.API
+	curve *curve
+}
+
+// Creates a new StealthAPI.
+func NewAPI(api *builder.API) *StealthAPI {
+	c, err := sw_emulated.New[emulated.Secp256k1Fp, emulated.Secp256k1Fr](api.FrontendAPI(), sw_emulated.GetSecp256k1Params())
+	if err != nil {
+		panic(err)
+	}
+	return &StealthAPI{api: *api, curve: c}
+}
+
+// Returns the stealth address and the view tag of the announcement of the ephemeral public key for
+// the meta-address of the spending and viewing public keys, where the viewing key is checked to be
+// the private key of the viewing public key. The public keys are checked to be on the curve, and
+// the bytes are assumed to be range checked.
+func (a *StealthAPI) Derive(viewingKey [32]vars.Byte, spendingKey PublicKey, viewingPublicKey PublicKey, ephemeralKey PublicKey) ([20]vars.Byte, vars.Byte) {
+	api := a.api
+	scalar := compat.ToElement[emulated.Secp256k1Fr](api, viewingKey)
+	a.curve.AssertIsEqual(a.curve.ScalarMulBase(scalar), a.toPoint(viewingPublicKey))
+
+	// The shared secret is hashed in its compressed form, whose prefix is 2 plus the parity of y.
+	secret := a.curve.ScalarMul(a.toPoint(ephemeralKey), scalar)
+	x := compat.FromElement[emulated.Secp256k1Fp](api, &secret.X)
+	y := compat.FromElement[emulated.Secp256k1Fp](api, &secret.Y)
+	parity := api.ToBitsFromByte(y[31])[0]
+	prefix := vars.Byte{Value: api.Add(vars.NewVariableFromInt(2), parity.Value)}
+	hashedSecret := keccak256.Hash(api, append([]vars.Byte{prefix}, x[:]...))
+
+	offset := a.curve.ScalarMulBase(compat.ToElement[emulated.Secp256k1Fr](api, hashedSecret))
+	stealthKey := a.curve.AddUnified(a.toPoint(spendingKey), offset)
+	stealthX := compat.FromElement[emulated.Secp256k1Fp](api, &stealthKey.X)
+	stealthY := compat.FromElement[emulated.Secp256k1Fp](api, &stealthKey.Y)
+	hash := keccak256.Hash(api, append(stealthX[:], stealthY[:]...))
+	var address [20]vars.Byte
+	copy(address[:], hash[12:])
+	return address, hashedSecret[0]
+}
+
+func (a *StealthAPI) toPoint(publicKey PublicKey) *point {
+	p := &point{
+		X: *compat.ToElement[emulated.Secp256k1Fp](a.api, publicKey.X),
+		Y: *compat.ToElement[emulated.Secp256k1Fp](a.api, publicKey.Y),
+	}
+	a.curve.AssertIsOnCurve(p)
+	return p
+}
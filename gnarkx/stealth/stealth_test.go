@@ -0,0 +1,71 @@
+package stealth
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	ViewingKey       [32]vars.Byte
+	SpendingKey      PublicKey
+	ViewingPublicKey PublicKey
+	EphemeralKey     PublicKey
+	Address          [20]vars.Byte
+	ViewTag          vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	address, viewTag := NewAPI(api).Derive(c.ViewingKey, c.SpendingKey, c.ViewingPublicKey, c.EphemeralKey)
+	for i := 0; i < 20; i++ {
+		api.AssertIsEqualByte(address[i], c.Address[i])
+	}
+	api.AssertIsEqualByte(viewTag, c.ViewTag)
+	return nil
+}
+
+func newKey(t *testing.T, seed byte) *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte{seed}))
+	assert.NoError(t, err)
+	return key
+}
+
+func TestDerive(t *testing.T) {
+	spendingKey, viewingKey, ephemeralKey := newKey(t, 1), newKey(t, 2), newKey(t, 3)
+	meta := &MetaAddress{SpendingKey: &spendingKey.PublicKey, ViewingKey: &viewingKey.PublicKey}
+	parsed, err := ParseMetaAddress(meta.String())
+	assert.NoError(t, err)
+	assert.Equal(t, meta.String(), parsed.String())
+	address, viewTag := meta.Generate(ephemeralKey)
+	checked, checkedTag := Check(viewingKey, &spendingKey.PublicKey, &ephemeralKey.PublicKey)
+	assert.Equal(t, address, checked)
+	assert.Equal(t, viewTag, checkedTag)
+	stealthKey, err := PrivateKey(spendingKey, viewingKey, &ephemeralKey.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, address, crypto.PubkeyToAddress(stealthKey.PublicKey))
+
+	circuit := &testCircuit{}
+	assignment := &testCircuit{}
+	vars.SetBytes32(&assignment.ViewingKey, [32]byte(common.LeftPadBytes(viewingKey.D.Bytes(), 32)))
+	assignment.SpendingKey.Set(&spendingKey.PublicKey)
+	assignment.ViewingPublicKey.Set(&viewingKey.PublicKey)
+	assignment.EphemeralKey.Set(&ephemeralKey.PublicKey)
+	for i := 0; i < 20; i++ {
+		assignment.Address[i].Set(address[i])
+	}
+	assignment.ViewTag.Set(viewTag)
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The viewing key must be of the meta-address.
+	vars.SetBytes32(&assignment.ViewingKey, [32]byte(common.LeftPadBytes(newKey(t, 4).D.Bytes(), 32)))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
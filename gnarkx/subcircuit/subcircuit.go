@@ -0,0 +1,162 @@
+// Define-once subcircuits for gadgets that are invoked many times on a small number of distinct
+// inputs, such as the hashes of the empty subtrees of a sparse Merkle tree. Instead of inlining the
+// gadget at every call site, the gadget is constrained once per entry of a table of distinct
+// inputs, and every call looks its inputs and outputs up in that table.
+//
+// The lookups use gnark's log-derivative argument, which commits to the table and the queries and
+// checks them with a challenge derived from that commitment. Each call then costs a few
+// constraints per input and output instead of the constraints of the whole gadget.
+package subcircuit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Gadget is a function from a fixed number of variables to a fixed number of variables.
+type Gadget struct {
+	NbInputs  int
+	NbOutputs int
+
+	// Defines the constraints of the gadget. It must return NbOutputs variables.
+	Define func(api builder.API, in []vars.Variable) []vars.Variable
+}
+
+// A Table holds the distinct inputs of a gadget as private witness values. The prover assigns it
+// with the distinct inputs of all calls, so it should be a field of the circuit.
+type Table struct {
+	Inputs [][]vars.Variable
+}
+
+// Creates a new table with room for the given number of distinct inputs.
+func NewTable(gadget *Gadget, capacity int) Table {
+	inputs := make([][]vars.Variable, capacity)
+	for i := 0; i < capacity; i++ {
+		inputs[i] = make([]vars.Variable, gadget.NbInputs)
+		for j := 0; j < gadget.NbInputs; j++ {
+			inputs[i][j] = vars.NewVariable()
+		}
+	}
+	return Table{Inputs: inputs}
+}
+
+// Assigns the table from the inputs of all calls. Duplicates are removed and unused entries are
+// filled with the first input, so the order and multiplicity of the calls don't matter.
+func (t *Table) Assign(calls [][]*big.Int) error {
+	var distinct [][]*big.Int
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		key := fmt.Sprint(call)
+		if !seen[key] {
+			seen[key] = true
+			distinct = append(distinct, call)
+		}
+	}
+	if len(distinct) > len(t.Inputs) {
+		return fmt.Errorf("found %d distinct inputs, table capacity is %d", len(distinct), len(t.Inputs))
+	}
+	for i := 0; i < len(t.Inputs); i++ {
+		for j := 0; j < len(t.Inputs[i]); j++ {
+			switch {
+			case i < len(distinct):
+				t.Inputs[i][j].Set(distinct[i][j])
+			case len(distinct) > 0:
+				t.Inputs[i][j].Set(distinct[0][j])
+			default:
+				t.Inputs[i][j].Set(big.NewInt(0))
+			}
+		}
+	}
+	return nil
+}
+
+// An Instance is a gadget constrained over every entry of a table, which can be called from
+// anywhere in the circuit.
+type Instance struct {
+	api     builder.API
+	gadget  *Gadget
+	inputs  [][]vars.Variable
+	columns []*logderivlookup.Table
+}
+
+// Instantiates the gadget over every entry of the table. This is the only place where the
+// constraints of the gadget are added to the circuit.
+func Instantiate(api builder.API, gadget *Gadget, table *Table) *Instance {
+	if len(table.Inputs) == 0 {
+		panic("table must have at least one entry")
+	}
+	columns := make([]*logderivlookup.Table, gadget.NbInputs+gadget.NbOutputs)
+	for i := 0; i < len(columns); i++ {
+		columns[i] = logderivlookup.New(api.FrontendAPI())
+	}
+	for _, in := range table.Inputs {
+		if len(in) != gadget.NbInputs {
+			panic(fmt.Sprintf("expected %d inputs, got %d", gadget.NbInputs, len(in)))
+		}
+		out := gadget.Define(api, in)
+		if len(out) != gadget.NbOutputs {
+			panic(fmt.Sprintf("expected %d outputs, got %d", gadget.NbOutputs, len(out)))
+		}
+		row := append(append([]vars.Variable{}, in...), out...)
+		for i := 0; i < len(row); i++ {
+			columns[i].Insert(row[i].Value)
+		}
+	}
+	return &Instance{api: api, gadget: gadget, inputs: table.Inputs, columns: columns}
+}
+
+// Computes the gadget on the given inputs by looking them up in the table. The proof can only be
+// generated if the inputs are an entry of the table.
+func (s *Instance) Call(in ...vars.Variable) []vars.Variable {
+	if len(in) != s.gadget.NbInputs {
+		panic(fmt.Sprintf("expected %d inputs, got %d", s.gadget.NbInputs, len(in)))
+	}
+
+	hintInputs := append([]vars.Variable{vars.NewVariableFromInt(len(in))}, in...)
+	for _, entry := range s.inputs {
+		hintInputs = append(hintInputs, entry...)
+	}
+	index := s.api.HintVariables(indexHint, 1, hintInputs...)[0]
+
+	for i := 0; i < len(in); i++ {
+		value := s.columns[i].Lookup(index.Value)[0]
+		s.api.AssertIsEqual(in[i], vars.Variable{Value: value})
+	}
+	out := make([]vars.Variable, s.gadget.NbOutputs)
+	for i := 0; i < len(out); i++ {
+		out[i] = vars.Variable{Value: s.columns[len(in)+i].Lookup(index.Value)[0]}
+	}
+	return out
+}
+
+// Finds the index of the first table entry matching the call inputs. The inputs are the number
+// of call inputs n, the n call inputs, and the flattened table entries.
+var indexHint = builder.NewHint("subcircuit.index", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) == 0 || len(outputs) != 1 {
+		return fmt.Errorf("invalid number of inputs or outputs")
+	}
+	n := int(inputs[0].Int64())
+	if n == 0 {
+		outputs[0].SetInt64(0)
+		return nil
+	}
+	call, entries := inputs[1:1+n], inputs[1+n:]
+	for i := 0; i*n < len(entries); i++ {
+		match := true
+		for j := 0; j < n; j++ {
+			if call[j].Cmp(entries[i*n+j]) != 0 {
+				match = false
+				break
+			}
+		}
+		if match {
+			outputs[0].SetInt64(int64(i))
+			return nil
+		}
+	}
+	return fmt.Errorf("call inputs are not in the table")
+})
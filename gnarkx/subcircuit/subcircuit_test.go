@@ -0,0 +1,119 @@
+package subcircuit
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Hashes the big-endian bytes of a 64-bit value.
+var hashGadget = &Gadget{
+	NbInputs:  1,
+	NbOutputs: 1,
+	Define: func(api builder.API, in []vars.Variable) []vars.Variable {
+		bits := api.ToBinaryBE(in[0], 64)
+		bytes := make([]vars.Byte, 8)
+		for i := 0; i < 8; i++ {
+			var byteBits [8]vars.Bool
+			for j := 0; j < 8; j++ {
+				byteBits[j] = bits[i*8+7-j]
+			}
+			bytes[i] = api.ToByteFromBits(byteBits)
+		}
+		return []vars.Variable{sha256.HashAndTruncate(api, bytes, 253)}
+	},
+}
+
+func hashNative(x uint64) *big.Int {
+	return sha256utils.HashAndTruncate(new(big.Int).SetUint64(x).FillBytes(make([]byte, 8)), 253)
+}
+
+type TestCircuit struct {
+	Table   Table
+	Inputs  []vars.Variable
+	Outputs []vars.Variable
+
+	inline bool `gnark:"-"`
+}
+
+func newTestCircuit(nbCalls int, capacity int, inline bool) *TestCircuit {
+	c := &TestCircuit{
+		Table:   NewTable(hashGadget, capacity),
+		Inputs:  make([]vars.Variable, nbCalls),
+		Outputs: make([]vars.Variable, nbCalls),
+		inline:  inline,
+	}
+	for i := 0; i < nbCalls; i++ {
+		c.Inputs[i] = vars.NewVariable()
+		c.Outputs[i] = vars.NewVariable()
+	}
+	return c
+}
+
+func (c *TestCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	if c.inline {
+		for i := range c.Inputs {
+			api.AssertIsEqual(c.Outputs[i], hashGadget.Define(*api, []vars.Variable{c.Inputs[i]})[0])
+		}
+		return nil
+	}
+	instance := Instantiate(*api, hashGadget, &c.Table)
+	for i := range c.Inputs {
+		api.AssertIsEqual(c.Outputs[i], instance.Call(c.Inputs[i])[0])
+	}
+	return nil
+}
+
+func TestSubcircuit(t *testing.T) {
+	inputs := []uint64{7, 42, 7, 7, 42, 7}
+	assign := func(c *TestCircuit, tableInputs []uint64) error {
+		var calls [][]*big.Int
+		for _, x := range tableInputs {
+			calls = append(calls, []*big.Int{new(big.Int).SetUint64(x)})
+		}
+		for i, x := range inputs {
+			c.Inputs[i].Set(new(big.Int).SetUint64(x))
+			c.Outputs[i].Set(hashNative(x))
+		}
+		return c.Table.Assign(calls)
+	}
+
+	circuit := newTestCircuit(len(inputs), 3, false)
+	assignment := newTestCircuit(len(inputs), 3, false)
+	assert.NoError(t, assign(assignment, inputs))
+	err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	test.NewAssert(t).ProverSucceeded(circuit, assignment, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254), test.NoFuzzing())
+
+	// A wrong output must be rejected.
+	assignment.Outputs[2].Set(hashNative(42))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// Calls with inputs missing from the table can't be proven.
+	assignment = newTestCircuit(len(inputs), 3, false)
+	assert.NoError(t, assign(assignment, []uint64{7}))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	assert.Error(t, assign(newTestCircuit(len(inputs), 1, false), inputs))
+}
+
+func TestSubcircuitConstraints(t *testing.T) {
+	memoized, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, newTestCircuit(16, 2, false))
+	assert.NoError(t, err)
+	inline, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, newTestCircuit(16, 2, true))
+	assert.NoError(t, err)
+	assert.Less(t, memoized.GetNbConstraints()*4, inline.GetNbConstraints())
+}
@@ -0,0 +1,126 @@
+package succinct
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+)
+
+// An Accelerator generates Groth16 proofs outside of the default CPU prover, for example on a GPU
+// (icicle) or by delegating to a remote proving service. The Groth16 prover in gnark v0.9.1 does
+// not expose its MSM and FFT steps individually, so accelerators take over the whole prove call,
+// which is dominated by those two steps.
+type Accelerator interface {
+	// Returns a short human readable name of the accelerator.
+	Name() string
+
+	// Returns whether the accelerator can currently be used, e.g. whether a device is present.
+	Available() bool
+
+	// Generates a proof for the given witness.
+	Prove(r1cs constraint.ConstraintSystem, pk groth16.ProvingKey, witness witness.Witness) (groth16.Proof, error)
+}
+
+// The default accelerator, which runs the gnark prover on the CPU.
+type CPU struct{}
+
+func (CPU) Name() string {
+	return "cpu"
+}
+
+func (CPU) Available() bool {
+	return true
+}
+
+func (CPU) Prove(r1cs constraint.ConstraintSystem, pk groth16.ProvingKey, witness witness.Witness) (groth16.Proof, error) {
+	return groth16.Prove(r1cs, pk, witness)
+}
+
+type proveConfig struct {
+	accelerator Accelerator
+	fallback    bool
+	onFallback  func(error)
+}
+
+// A ProveOption configures how circuit functions generate proofs.
+type ProveOption func(*proveConfig)
+
+// Delegates proving to the given accelerator. If the accelerator is unavailable, fails, or
+// returns an invalid proof, proving falls back to the CPU unless WithoutFallback is also given.
+func WithAccelerator(a Accelerator) ProveOption {
+	return func(cfg *proveConfig) {
+		cfg.accelerator = a
+	}
+}
+
+// Disables the CPU fallback, so that accelerator failures are returned as errors.
+func WithoutFallback() ProveOption {
+	return func(cfg *proveConfig) {
+		cfg.fallback = false
+	}
+}
+
+// Calls f with the reason whenever proving falls back to the CPU, e.g. to log it.
+func OnFallback(f func(error)) ProveOption {
+	return func(cfg *proveConfig) {
+		cfg.onFallback = f
+	}
+}
+
+// Generates a proof with the configured accelerator, falling back to the CPU if enabled. Proofs
+// from accelerators are verified before being returned, since a faulty device would otherwise
+// only be noticed on-chain.
+func prove(
+	r1cs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	vk groth16.VerifyingKey,
+	w witness.Witness,
+	opts ...ProveOption,
+) (groth16.Proof, error) {
+	cfg := proveConfig{accelerator: CPU{}, fallback: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if _, ok := cfg.accelerator.(CPU); ok {
+		return CPU{}.Prove(r1cs, pk, w)
+	}
+
+	err := fmt.Errorf("accelerator %s is not available", cfg.accelerator.Name())
+	if cfg.accelerator.Available() {
+		var proof groth16.Proof
+		proof, err = proveAndVerify(cfg.accelerator, r1cs, pk, vk, w)
+		if err == nil {
+			return proof, nil
+		}
+	}
+	if !cfg.fallback {
+		return nil, err
+	}
+	if cfg.onFallback != nil {
+		cfg.onFallback(err)
+	}
+	return CPU{}.Prove(r1cs, pk, w)
+}
+
+func proveAndVerify(
+	a Accelerator,
+	r1cs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	vk groth16.VerifyingKey,
+	w witness.Witness,
+) (groth16.Proof, error) {
+	proof, err := a.Prove(r1cs, pk, w)
+	if err != nil {
+		return nil, fmt.Errorf("accelerator %s failed: %w", a.Name(), err)
+	}
+	public, err := w.Public()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public witness: %w", err)
+	}
+	if err := groth16.Verify(proof, vk, public); err != nil {
+		return nil, fmt.Errorf("accelerator %s returned an invalid proof: %w", a.Name(), err)
+	}
+	return proof, nil
+}
@@ -0,0 +1,90 @@
+package succinct
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type squareCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.X, api.Mul(c.Y, c.Y))
+	return nil
+}
+
+type testAccelerator struct {
+	available bool
+	err       error
+	proof     groth16.Proof
+	calls     int
+}
+
+func (a *testAccelerator) Name() string {
+	return "test"
+}
+
+func (a *testAccelerator) Available() bool {
+	return a.available
+}
+
+func (a *testAccelerator) Prove(constraint.ConstraintSystem, groth16.ProvingKey, witness.Witness) (groth16.Proof, error) {
+	a.calls++
+	return a.proof, a.err
+}
+
+func TestAcceleratorFallback(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	w, err := frontend.NewWitness(&squareCircuit{X: 9, Y: 3}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	other, err := frontend.NewWitness(&squareCircuit{X: 16, Y: 4}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	public, err := w.Public()
+	assert.NoError(t, err)
+
+	// A working accelerator is used directly.
+	validProof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(t, err)
+	a := &testAccelerator{available: true, proof: validProof}
+	proof, err := prove(ccs, pk, vk, w, WithAccelerator(a))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, a.calls)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+
+	// Unavailable and failing accelerators fall back to the CPU.
+	a = &testAccelerator{available: false}
+	proof, err = prove(ccs, pk, vk, w, WithAccelerator(a))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, a.calls)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+
+	a = &testAccelerator{available: true, err: errors.New("device lost")}
+	var reason error
+	proof, err = prove(ccs, pk, vk, w, WithAccelerator(a), OnFallback(func(err error) { reason = err }))
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+	assert.ErrorContains(t, reason, "device lost")
+
+	// Without fallback, failures and invalid proofs are returned as errors.
+	_, err = prove(ccs, pk, vk, w, WithAccelerator(a), WithoutFallback())
+	assert.Error(t, err)
+
+	wrongProof, err := groth16.Prove(ccs, pk, other)
+	assert.NoError(t, err)
+	a = &testAccelerator{available: true, proof: wrongProof}
+	_, err = prove(ccs, pk, vk, w, WithAccelerator(a), WithoutFallback())
+	assert.Error(t, err)
+}
@@ -10,12 +10,24 @@ import (
 )
 
 type CircuitBuild struct {
-	pk   groth16.ProvingKey
-	vk   groth16.VerifyingKey
-	r1cs constraint.ConstraintSystem
+	curve ecc.ID
+	pk    groth16.ProvingKey
+	vk    groth16.VerifyingKey
+	r1cs  constraint.ConstraintSystem
 }
 
-// Export exports the R1CS, proving key, and verifying key to files.
+// Returns the curve of the circuit.
+func (build *CircuitBuild) Curve() ecc.ID {
+	return build.curve
+}
+
+// Returns the verifying key of the circuit.
+func (build *CircuitBuild) VerifyingKey() groth16.VerifyingKey {
+	return build.vk
+}
+
+// Export exports the R1CS, proving key, and verifying key to files, and the Solidity verifier
+// for BN254 circuits.
 func (build *CircuitBuild) Export() {
 	// Make build directory.
 	err := os.MkdirAll("build", 0755)
@@ -67,6 +79,11 @@ func (build *CircuitBuild) Export() {
 		return
 	}
 
+	// Only BN254 proofs can be verified on-chain.
+	if build.curve != ecc.BN254 {
+		return
+	}
+
 	// Write verifier smart contract into a file.
 	verifierFile, err := os.Create("build/FunctionVerifier.sol")
 	if err != nil {
@@ -84,9 +101,16 @@ func (build *CircuitBuild) Export() {
 
 }
 
-// ImportCircuitBuild imports the R1CS, proving key, and verifying key from files.
+// ImportCircuitBuild imports the R1CS, proving key, and verifying key of a BN254 circuit from
+// files.
 func ImportCircuitBuild() (*CircuitBuild, error) {
-	r1cs := groth16.NewCS(ecc.BN254)
+	return ImportCircuitBuildForCurve(ecc.BN254)
+}
+
+// ImportCircuitBuildForCurve imports the R1CS, proving key, and verifying key of a circuit over
+// the curve from files.
+func ImportCircuitBuildForCurve(curve ecc.ID) (*CircuitBuild, error) {
+	r1cs := groth16.NewCS(curve)
 
 	// Read the proving key file.
 	pkFile, err := os.Open("build/pkey.bin")
@@ -96,7 +120,7 @@ func ImportCircuitBuild() (*CircuitBuild, error) {
 	defer pkFile.Close()
 
 	// Deserialize the proving key.
-	pk := groth16.NewProvingKey(ecc.BN254)
+	pk := groth16.NewProvingKey(curve)
 	_, err = pk.ReadFrom(pkFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data: %w", err)
@@ -109,7 +133,7 @@ func ImportCircuitBuild() (*CircuitBuild, error) {
 	defer vkFile.Close()
 
 	// Deserialize the verifying key.
-	vk := groth16.NewVerifyingKey(ecc.BN254)
+	vk := groth16.NewVerifyingKey(curve)
 	_, err = vk.ReadFrom(vkFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data: %w", err)
@@ -129,8 +153,9 @@ func ImportCircuitBuild() (*CircuitBuild, error) {
 	}
 
 	return &CircuitBuild{
-		pk:   pk,
-		vk:   vk,
-		r1cs: r1cs,
+		curve: curve,
+		pk:    pk,
+		vk:    vk,
+		r1cs:  r1cs,
 	}, nil
 }
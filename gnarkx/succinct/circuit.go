@@ -1,18 +1,15 @@
 package succinct
 
 import (
-	"bytes"
 	"fmt"
-	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
-	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
 	"github.com/succinctlabs/succinctx/gnarkx/types"
-	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
@@ -26,21 +23,38 @@ type CircuitFunction struct {
 
 	// The circuit definies the computation of the function.
 	Circuit Circuit
+
+	// The commitment of the input and the output bytes.
+	Commitment Commitment `gnark:"-"`
+
+	// The curve whose scalar field the circuit is compiled over.
+	Curve ecc.ID `gnark:"-"`
 }
 
-// Creates a new circuit function based on a circuit that implements the Circuit interface.
+// Creates a new circuit function based on a circuit that implements the Circuit interface. The
+// input and output hashes are the commitment of the circuit if it implements CommitmentCircuit,
+// and the SHA256Commitment otherwise, and the circuit is compiled over the curve of the circuit if
+// it implements CurveCircuit, and over BN254 otherwise.
 func NewCircuitFunction(c Circuit) CircuitFunction {
 	function := CircuitFunction{}
 	function.InputHash = vars.NewVariable()
 	function.OutputHash = vars.NewVariable()
 	function.Circuit = c
+	function.Commitment = SHA256Commitment{}
+	if cc, ok := c.(CommitmentCircuit); ok {
+		function.Commitment = cc.Commitment()
+	}
+	function.Curve = ecc.BN254
+	if cc, ok := c.(CurveCircuit); ok {
+		function.Curve = cc.Curve()
+	}
 	return function
 }
 
 // Generate and set witnesses for the circuit function. In particular, this function will set the
 // input hash and output hash variables (which will be public values). Recall that all functions
 // have the form f(inputs, witness) = outputs. Both inputsHash and outputsHash are h(inputs) and
-// h(outputs) respectively, where h is a hash function.
+// h(outputs) respectively, where h is the commitment of the function.
 func (f *CircuitFunction) SetWitness(inputBytes []byte) {
 	// Set the input bytes.
 	vars.SetBytes(f.Circuit.GetInputBytes(), inputBytes)
@@ -48,14 +62,15 @@ func (f *CircuitFunction) SetWitness(inputBytes []byte) {
 	// Assign the circuit.
 	f.Circuit.SetWitness(inputBytes)
 
-	// Set inputHash = sha256(inputBytes) && ((1 << 253) - 1).
-	inputHash := sha256utils.HashAndTruncate(inputBytes, 253)
+	// Set inputHash = h(inputBytes), by default sha256(inputBytes) && ((1 << 253) - 1) over BN254.
+	field := f.curve().ScalarField()
+	inputHash := f.commitment().CommitValue(field, inputBytes)
 	f.InputHash.Set(inputHash)
 
-	// Set outputHash = sha256(outputBytes) && ((1 << 253) - 1).
+	// Set outputHash = h(outputBytes), by default sha256(outputBytes) && ((1 << 253) - 1) over BN254.
 	outputBytes := f.Circuit.GetOutputBytes()
 	outputBytesValues := vars.GetValuesUnsafe(*outputBytes)
-	outputHash := sha256utils.HashAndTruncate(outputBytesValues, 253)
+	outputHash := f.commitment().CommitValue(field, outputBytesValues)
 	f.OutputHash.Set(outputHash)
 }
 
@@ -68,16 +83,32 @@ func (f *CircuitFunction) Define(baseApi frontend.API) error {
 
 	// Automatically handle the input and output hashes and assert that they must be consistent.
 	api := builder.NewAPI(baseApi)
-	inputHash := sha256.HashAndTruncate(*api, *f.Circuit.GetInputBytes(), 253)
-	outputHash := sha256.HashAndTruncate(*api, *f.Circuit.GetOutputBytes(), 253)
+	inputHash := f.commitment().Commit(*api, *f.Circuit.GetInputBytes())
+	outputHash := f.commitment().Commit(*api, *f.Circuit.GetOutputBytes())
 	api.AssertIsEqual(f.InputHash, inputHash)
 	api.AssertIsEqual(f.OutputHash, outputHash)
 	return nil
 }
 
+// Returns the commitment of the function, which is the SHA256Commitment if it is not set.
+func (f *CircuitFunction) commitment() Commitment {
+	if f.Commitment == nil {
+		return SHA256Commitment{}
+	}
+	return f.Commitment
+}
+
+// Returns the curve of the function, which is BN254 if it is not set.
+func (f *CircuitFunction) curve() ecc.ID {
+	if f.Curve == ecc.UNKNOWN {
+		return ecc.BN254
+	}
+	return f.Curve
+}
+
 // Build the circuit and serialize the r1cs, proving key, and verifying key to files.
 func (circuit *CircuitFunction) Build() (*CircuitBuild, error) {
-	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	r1cs, err := frontend.Compile(circuit.curve().ScalarField(), r1cs.NewBuilder, circuit)
 	if err != nil {
 		return nil, err
 	}
@@ -88,43 +119,59 @@ func (circuit *CircuitFunction) Build() (*CircuitBuild, error) {
 	}
 
 	return &CircuitBuild{
-		pk:   pk,
-		vk:   vk,
-		r1cs: r1cs,
+		curve: circuit.curve(),
+		pk:    pk,
+		vk:    vk,
+		r1cs:  r1cs,
 	}, nil
 }
 
-// Generates a proof for f(inputs, witness) = outputs based on a circuit.
-func (f *CircuitFunction) Prove(inputBytes []byte, build *CircuitBuild) (*types.Groth16Proof, error) {
+// Generates the full witness of the circuit function for the given input bytes. Witness
+// generation doesn't need the circuit build, so it can run separately from proving, e.g. in a
+// browser.
+func (f *CircuitFunction) Witness(inputBytes []byte) (witness.Witness, error) {
 	// Fill in the witness values.
 	f.SetWitness(inputBytes)
 
 	// Calculate the actual witness.
-	witness, err := frontend.NewWitness(f, ecc.BN254.ScalarField())
+	witness, err := frontend.NewWitness(f, f.curve().ScalarField())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create witness: %w", err)
 	}
+	return witness, nil
+}
+
+// Generates a raw gnark proof for f(inputs, witness) = outputs based on a circuit. This is useful
+// when the proof will be verified recursively instead of on-chain.
+func (f *CircuitFunction) ProveGroth16(inputBytes []byte, build *CircuitBuild, opts ...ProveOption) (groth16.Proof, error) {
+	witness, err := f.Witness(inputBytes)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate the proof.
-	proof, err := groth16.Prove(build.r1cs, build.pk, witness)
+	proof, err := prove(build.r1cs, build.pk, build.vk, witness, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate proof: %w", err)
 	}
+	return proof, nil
+}
 
-	const fpSize = 4 * 8
-	var buf bytes.Buffer
-	proof.WriteRawTo(&buf)
-	proofBytes := buf.Bytes()
-	output := &types.Groth16Proof{}
-	output.A[0] = new(big.Int).SetBytes(proofBytes[fpSize*0 : fpSize*1])
-	output.A[1] = new(big.Int).SetBytes(proofBytes[fpSize*1 : fpSize*2])
-	output.B[0][0] = new(big.Int).SetBytes(proofBytes[fpSize*2 : fpSize*3])
-	output.B[0][1] = new(big.Int).SetBytes(proofBytes[fpSize*3 : fpSize*4])
-	output.B[1][0] = new(big.Int).SetBytes(proofBytes[fpSize*4 : fpSize*5])
-	output.B[1][1] = new(big.Int).SetBytes(proofBytes[fpSize*5 : fpSize*6])
-	output.C[0] = new(big.Int).SetBytes(proofBytes[fpSize*6 : fpSize*7])
-	output.C[1] = new(big.Int).SetBytes(proofBytes[fpSize*7 : fpSize*8])
+// Generates a proof for f(inputs, witness) = outputs based on a circuit. The circuit must be over
+// BN254, which is the only curve of the on-chain verifier.
+func (f *CircuitFunction) Prove(inputBytes []byte, build *CircuitBuild, opts ...ProveOption) (*types.Groth16Proof, error) {
+	if build.curve != ecc.BN254 {
+		return nil, fmt.Errorf("proofs over %s can't be verified on-chain, use ProveGroth16", build.curve)
+	}
+	proof, err := f.ProveGroth16(inputBytes, build, opts...)
+	if err != nil {
+		return nil, err
+	}
 
+	output, err := types.NewGroth16Proof(proof)
+	if err != nil {
+		return nil, err
+	}
 	output.Input = inputBytes
 	output.Output = vars.GetValuesUnsafe(*f.Circuit.GetOutputBytes())
 
@@ -0,0 +1,131 @@
+package succinct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Commitment hashes the input or the output bytes of a circuit function into the single public
+// input that replaces them, so that the cost of verifying a proof does not depend on their length.
+type Commitment interface {
+	// Computes the commitment of the bytes in the circuit.
+	Commit(api builder.API, bytes []vars.Byte) vars.Variable
+
+	// Computes the commitment of the bytes outside of the circuit, for a circuit over the scalar
+	// field with the modulus. It must match Commit.
+	CommitValue(field *big.Int, bytes []byte) *big.Int
+}
+
+// CommitmentCircuit is a circuit that chooses the commitment of its circuit function rather than
+// the default SHA256Commitment.
+type CommitmentCircuit interface {
+	Circuit
+	Commitment() Commitment
+}
+
+// SHA256Commitment is sha256(bytes) truncated to one bit less than the scalar field, so that it is
+// always less than the modulus, or the whole digest over fields of more than 256 bits. It is
+// sha256(bytes) & ((1 << 253) - 1) over BN254, which the gateway contracts recompute on-chain.
+type SHA256Commitment struct{}
+
+var _ Commitment = SHA256Commitment{}
+
+func (SHA256Commitment) Commit(api builder.API, bytes []vars.Byte) vars.Variable {
+	return sha256.HashAndTruncate(api, bytes, sha256CommitmentBits(api.FrontendAPI().Compiler().FieldBitLen()))
+}
+
+func (SHA256Commitment) CommitValue(field *big.Int, bytes []byte) *big.Int {
+	return sha256utils.HashAndTruncate(bytes, sha256CommitmentBits(field.BitLen()))
+}
+
+// Returns the number of bits of a SHA256Commitment over a scalar field of fieldBitLen bits.
+func sha256CommitmentBits(fieldBitLen int) int {
+	if fieldBitLen > 256 {
+		return 256
+	}
+	return fieldBitLen - 1
+}
+
+// PoseidonCommitment is the Poseidon hash of the poseidon package of the length of the bytes and
+// of their big-endian chunks of 31 bytes, the most whole bytes that are less than the modulus,
+// absorbed as h = poseidon(length, chunks[0:15]) and then h = poseidon(h, chunks[15i:15(i+1)]),
+// which is much cheaper in circuits than SHA-256 for callers that compute Poseidon, such as
+// circuits that verify the proof recursively. Like the poseidon package, it is only defined over
+// the scalar field of BN254 and panics over other fields.
+type PoseidonCommitment struct{}
+
+var _ Commitment = PoseidonCommitment{}
+
+// The length of the chunks of the bytes of a PoseidonCommitment.
+const poseidonChunkLength = 31
+
+// Panics if the field is not the scalar field of BN254.
+func assertPoseidonField(field *big.Int) {
+	if field.Cmp(ecc.BN254.ScalarField()) != 0 {
+		panic(fmt.Sprintf("poseidon commitments are only defined over the scalar field of BN254, not %s", field))
+	}
+}
+
+func (PoseidonCommitment) Commit(api builder.API, bytes []vars.Byte) vars.Variable {
+	assertPoseidonField(api.FrontendAPI().Compiler().Field())
+	rc := rangecheck.New(api.FrontendAPI())
+	for i := 0; i < len(bytes); i++ {
+		rc.Check(bytes[i].Value.Value, 8)
+	}
+	in := []vars.Variable{vars.NewVariableFromInt(len(bytes))}
+	for i := 0; i < len(bytes); i += poseidonChunkLength {
+		end := i + poseidonChunkLength
+		if end > len(bytes) {
+			end = len(bytes)
+		}
+		chunk := vars.NewVariableFromInt(0)
+		for j := i; j < end; j++ {
+			chunk = api.Add(api.Mul(chunk, vars.NewVariableFromInt(256)), bytes[j].Value)
+		}
+		in = append(in, chunk)
+	}
+	return absorb(in, func(in []vars.Variable) vars.Variable {
+		return poseidon.Hash(api, in)
+	})
+}
+
+func (PoseidonCommitment) CommitValue(field *big.Int, bytes []byte) *big.Int {
+	assertPoseidonField(field)
+	in := []*big.Int{big.NewInt(int64(len(bytes)))}
+	for i := 0; i < len(bytes); i += poseidonChunkLength {
+		end := i + poseidonChunkLength
+		if end > len(bytes) {
+			end = len(bytes)
+		}
+		in = append(in, new(big.Int).SetBytes(bytes[i:end]))
+	}
+	return absorb(in, func(in []*big.Int) *big.Int {
+		return poseidon.HashValues(in...)
+	})
+}
+
+// Absorbs the elements into the hash by groups of at most poseidon.MaxInputs elements, where each
+// group after the first starts with the hash of the previous ones.
+func absorb[T any](in []T, hash func([]T) T) T {
+	end := len(in)
+	if end > poseidon.MaxInputs {
+		end = poseidon.MaxInputs
+	}
+	h := hash(in[:end])
+	for i := end; i < len(in); i += poseidon.MaxInputs - 1 {
+		end = i + poseidon.MaxInputs - 1
+		if end > len(in) {
+			end = len(in)
+		}
+		h = hash(append([]T{h}, in[i:end]...))
+	}
+	return h
+}
@@ -0,0 +1,104 @@
+package succinct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCommitmentCircuit struct {
+	Bytes      []vars.Byte
+	Commitment vars.Variable
+}
+
+func (c *testCommitmentCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(PoseidonCommitment{}.Commit(*api, c.Bytes), c.Commitment)
+	return nil
+}
+
+func TestPoseidonCommitment(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	// The lengths cover a partial chunk, the first group of 15 chunks and a second group.
+	for _, length := range []int{0, 1, 31, 465, 466, 1000} {
+		data := make([]byte, length)
+		for i := range data {
+			data[i] = byte(i * 13)
+		}
+		circuit := &testCommitmentCircuit{Bytes: vars.NewBytes(length)}
+		assignment := &testCommitmentCircuit{Bytes: vars.NewBytesFrom(data)}
+		assignment.Commitment.Set(PoseidonCommitment{}.CommitValue(field, data))
+		assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	}
+
+	// The commitment binds the length of the bytes.
+	assert.NotEqual(t, PoseidonCommitment{}.CommitValue(field, []byte{0, 1}), PoseidonCommitment{}.CommitValue(field, []byte{1}))
+
+	// The commitment is only defined over BN254.
+	assert.Panics(t, func() { PoseidonCommitment{}.CommitValue(ecc.BLS12_381.ScalarField(), []byte{1}) })
+	circuit := &testCommitmentCircuit{Bytes: vars.NewBytes(1)}
+	assignment := &testCommitmentCircuit{Bytes: vars.NewBytesFrom([]byte{1}), Commitment: vars.NewVariableFromInt(0)}
+	assert.ErrorContains(t, test.IsSolved(circuit, assignment, ecc.BLS12_381.ScalarField()), "only defined over the scalar field of BN254")
+}
+
+type testPoseidonCircuit struct {
+	TestCircuit
+}
+
+func (c *testPoseidonCircuit) Commitment() Commitment {
+	return PoseidonCommitment{}
+}
+
+func TestCircuitFunctionCommitment(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	input := []byte{0, 0, 0, 0, 0, 0, 1, 0xa4, 0, 0, 0, 0, 0, 0, 0, 0x45}
+	for _, circuit := range []Circuit{NewTestCircuit(), &testPoseidonCircuit{*NewTestCircuit()}} {
+		function := NewCircuitFunction(circuit)
+		function.SetWitness(input)
+		assert.NoError(t, test.IsSolved(&function, &function, ecc.BN254.ScalarField()))
+		assert.Equal(t, function.Commitment.CommitValue(field, input), function.InputHash.Value)
+	}
+	function := NewCircuitFunction(&testPoseidonCircuit{*NewTestCircuit()})
+	assert.Equal(t, PoseidonCommitment{}, function.Commitment)
+}
+
+type testOutputCommitCircuit struct {
+	Number     vars.U64
+	Hash       [32]vars.Byte
+	OutputHash vars.Variable `gnark:",public"`
+}
+
+func (c *testOutputCommitCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	w := builder.NewOutputWriter(*api)
+	w.WriteU64(c.Number)
+	w.WriteBytes32(c.Hash)
+	w.Commit(c.OutputHash, SHA256Commitment{}.Commit)
+	return nil
+}
+
+func TestOutputWriterCommit(t *testing.T) {
+	hash := [32]byte{0xaa, 0xbb}
+	output := append([]byte{0, 0, 0, 0, 0, 0, 1, 0xa4}, hash[:]...)
+	// The hash is truncated to 253 bits over BN254 and to 252 bits over BLS12-377.
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		field := curve.ScalarField()
+		assignment := &testOutputCommitCircuit{}
+		assignment.Number.Set(0x1a4)
+		vars.SetBytes32(&assignment.Hash, hash)
+		assignment.OutputHash.Set(SHA256Commitment{}.CommitValue(field, output))
+		assert.Less(t, assignment.OutputHash.Value.(*big.Int).BitLen(), field.BitLen())
+		assert.NoError(t, test.IsSolved(&testOutputCommitCircuit{}, assignment, field))
+
+		assignment.Number.Set(0x1a5)
+		assert.Error(t, test.IsSolved(&testOutputCommitCircuit{}, assignment, field))
+	}
+	assert.Equal(t, sha256utils.HashAndTruncate(output, 253), SHA256Commitment{}.CommitValue(ecc.BN254.ScalarField(), output))
+}
@@ -0,0 +1,46 @@
+package succinct
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCurveCircuit struct {
+	TestCircuit
+	curve ecc.ID `gnark:"-"`
+}
+
+func (c *testCurveCircuit) Curve() ecc.ID {
+	return c.curve
+}
+
+func TestCurves(t *testing.T) {
+	input, err := hex.DecodeString("00000000000001a40000000000000045")
+	assert.NoError(t, err)
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761} {
+		function := NewCircuitFunction(&testCurveCircuit{*NewTestCircuit(), curve})
+		assert.Equal(t, curve, function.Curve)
+		function.SetWitness(input)
+		assert.NoError(t, test.IsSolved(&function, &function, curve.ScalarField()))
+	}
+
+	// Circuits over other curves are proven with gnark but can't be verified on-chain.
+	function := NewCircuitFunction(&testCurveCircuit{*NewTestCircuit(), ecc.BLS12_377})
+	build, err := function.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, ecc.BLS12_377, build.Curve())
+	proof, err := function.ProveGroth16(input, build)
+	assert.NoError(t, err)
+	witness, err := function.Witness(input)
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, build.VerifyingKey(), publicWitness))
+	_, err = function.Prove(input, build)
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package succinct
 
 import (
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
@@ -15,3 +16,11 @@ type Circuit interface {
 	Assign(inputBytes []byte) error
 	Define(BaseApi frontend.API) error
 }
+
+// CurveCircuit is a circuit that is compiled over the scalar field of another curve than BN254,
+// e.g. to be verified recursively in a circuit over a curve of a 2-chain. Only BN254 proofs can be
+// verified on-chain.
+type CurveCircuit interface {
+	Circuit
+	Curve() ecc.ID
+}
@@ -0,0 +1,32 @@
+// Helpers for testing circuit functions: solving a function for its input, and proving keys of
+// Ethereum tries for the witnesses of the functions that verify accounts and storage.
+package functiontest
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+)
+
+// Checks that the function of the circuit, whose witness has been set, is solved for the input.
+func IsSolved(circuit succinct.Circuit, input []byte) error {
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(input)
+	return test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+}
+
+// Returns the proof of the key of the secure trie, whose path is keccak256(key), with the value.
+func Prove(t *testing.T, tr *trie.Trie, key []byte, value []byte, maxDepth int, maxNodeLength int, maxValueLength int) *eth.MPTProof {
+	t.Helper()
+	var nodes eth.ProofNodes
+	assert.NoError(t, tr.Prove(crypto.Keccak256(key), 0, &nodes))
+	proof := eth.NewMPTProof(maxDepth, maxNodeLength, maxValueLength)
+	assert.NoError(t, proof.Set(tr.Hash(), key, value, nodes))
+	return &proof
+}
@@ -0,0 +1,131 @@
+// Helpers for testing hash gadgets against their reference implementations in the Go standard
+// library or golang.org/x/crypto, so that a gadget needs no circuit of its own to be tested:
+//
+//	test.AssertHashMatches(t, func(api builder.API, in []vars.Byte) []vars.Byte {
+//		h := sha256.Hash(api, in)
+//		return h[:]
+//	}, func(in []byte) []byte {
+//		h := gosha256.Sum256(in)
+//		return h[:]
+//	}, []byte(""), []byte("Succinct Labs"))
+//
+// Every input is checked as by fuzz.Target: the circuit for its length must compile, the witness
+// with the reference output must solve it and the witness with a corrupted output must not.
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fuzz"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A hash gadget, where len(in) is a compile time constant.
+type HashFn func(api builder.API, in []vars.Byte) []vars.Byte
+
+// The reference implementation of a hash gadget.
+type GoHashFn func(in []byte) []byte
+
+// The seed of the random inputs, fixed so that failures can be reproduced.
+const seed = 1
+
+// Returns the fuzz target of a hash gadget, whose output length is that of the reference.
+func HashTarget(name string, circuitHashFn HashFn, goHashFn GoHashFn) *fuzz.Target {
+	outputLength := len(goHashFn(nil))
+	return &fuzz.Target{
+		Name: name,
+		Gadget: func(api builder.API, in []vars.Byte, out []vars.Byte) {
+			res := circuitHashFn(api, in)
+			if len(res) != len(out) {
+				panic(fmt.Sprintf("%s: gadget returned %d bytes, expected %d", name, len(res), len(out)))
+			}
+			for i := range res {
+				api.AssertIsEqualByte(res[i], out[i])
+			}
+		},
+		Reference: func(in []byte) ([]byte, bool) {
+			return goHashFn(in), true
+		},
+		OutputLength: outputLength,
+	}
+}
+
+type compileCircuit struct {
+	In     []vars.Byte
+	Out    []vars.Byte
+	target *fuzz.Target `gnark:"-"`
+}
+
+func (c *compileCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	c.target.Gadget(*api, c.In, c.Out)
+	return nil
+}
+
+// Compiles the circuit of the target for inputs of length n.
+func compile(target *fuzz.Target, n int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %v", target.Name, r)
+		}
+	}()
+	circuit := &compileCircuit{In: vars.NewBytes(n), Out: vars.NewBytes(target.OutputLength), target: target}
+	if _, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit); err != nil {
+		return fmt.Errorf("%s: failed to compile for %d input bytes: %w", target.Name, n, err)
+	}
+	return nil
+}
+
+// Checks the hash gadget against its reference implementation for the inputs, returning the
+// first mismatch.
+func CheckHashMatches(circuitHashFn HashFn, goHashFn GoHashFn, inputs ...[]byte) error {
+	target := HashTarget("hash", circuitHashFn, goHashFn)
+	compiled := map[int]bool{}
+	for _, in := range inputs {
+		if !compiled[len(in)] {
+			if err := compile(target, len(in)); err != nil {
+				return err
+			}
+			compiled[len(in)] = true
+		}
+		if err := target.Check(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Asserts that the hash gadget matches its reference implementation for the inputs.
+func AssertHashMatches(t *testing.T, circuitHashFn HashFn, goHashFn GoHashFn, inputs ...[]byte) {
+	t.Helper()
+	if err := CheckHashMatches(circuitHashFn, goHashFn, inputs...); err != nil {
+		t.Error(err)
+	}
+}
+
+// Returns nbInputs random inputs of random lengths of at most maxLength bytes, which always
+// include the empty input and one of maxLength bytes.
+func RandomInputs(nbInputs int, maxLength int) [][]byte {
+	rng := rand.New(rand.NewSource(seed))
+	inputs := [][]byte{{}, make([]byte, maxLength)}
+	rng.Read(inputs[1])
+	for len(inputs) < nbInputs {
+		in := make([]byte, rng.Intn(maxLength+1))
+		rng.Read(in)
+		inputs = append(inputs, in)
+	}
+	return inputs
+}
+
+// Asserts that the hash gadget matches its reference implementation for nbInputs random inputs of
+// at most maxLength bytes.
+func AssertHashMatchesRandom(t *testing.T, circuitHashFn HashFn, goHashFn GoHashFn, nbInputs int, maxLength int) {
+	t.Helper()
+	AssertHashMatches(t, circuitHashFn, goHashFn, RandomInputs(nbInputs, maxLength)...)
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	gosha256 "crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func sha256Fn(api builder.API, in []vars.Byte) []vars.Byte {
+	h := sha256.Hash(api, in)
+	return h[:]
+}
+
+func goSha256Fn(in []byte) []byte {
+	h := gosha256.Sum256(in)
+	return h[:]
+}
+
+func TestAssertHashMatches(t *testing.T) {
+	AssertHashMatches(t, sha256Fn, goSha256Fn, []byte(""), []byte("Succinct Labs"), make([]byte, 64))
+	AssertHashMatchesRandom(t, sha256Fn, goSha256Fn, 4, 100)
+}
+
+func TestCheckHashMatches(t *testing.T) {
+	// A gadget that ignores the last byte of its input.
+	truncated := func(api builder.API, in []vars.Byte) []vars.Byte {
+		if len(in) == 0 {
+			return sha256Fn(api, in)
+		}
+		return sha256Fn(api, in[:len(in)-1])
+	}
+	assert.NoError(t, CheckHashMatches(truncated, goSha256Fn, []byte("")))
+	assert.Error(t, CheckHashMatches(truncated, goSha256Fn, []byte(""), []byte("Succinct Labs")))
+
+	// The output of the gadget must have the length of the reference.
+	short := func(api builder.API, in []vars.Byte) []vars.Byte {
+		return sha256Fn(api, in)[:31]
+	}
+	assert.Error(t, CheckHashMatches(short, goSha256Fn, []byte("")))
+}
+
+func TestRandomInputs(t *testing.T) {
+	inputs := RandomInputs(5, 10)
+	assert.Len(t, inputs, 5)
+	assert.Empty(t, inputs[0])
+	assert.Len(t, inputs[1], 10)
+	for _, in := range inputs {
+		assert.LessOrEqual(t, len(in), 10)
+	}
+	assert.Equal(t, inputs, RandomInputs(5, 10))
+}
@@ -0,0 +1,84 @@
+package tls
+
+import (
+	"crypto/hmac"
+	gosha256 "crypto/sha256"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The block size of SHA-256 in bytes.
+const blockSize = 64
+
+// Computes HMAC-SHA256 of the message under a key of at most 64 bytes. The lengths of the key and
+// message are fixed when the circuit is compiled, and the bytes of the key are assumed to be range
+// checked.
+func HMAC(api builder.API, key []vars.Byte, message []vars.Byte) [32]vars.Byte {
+	if len(key) > blockSize {
+		panic("keys longer than the block size are not supported")
+	}
+	inner := make([]vars.Byte, blockSize, blockSize+len(message))
+	outer := make([]vars.Byte, blockSize, blockSize+32)
+	for i := 0; i < blockSize; i++ {
+		bits := [8]vars.Bool{vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE, vars.FALSE}
+		if i < len(key) {
+			bits = api.ToBitsFromByte(key[i])
+		}
+		inner[i] = xorConstant(api, bits, 0x36)
+		outer[i] = xorConstant(api, bits, 0x5c)
+	}
+	innerHash := compat.Sha256(api, append(inner, message...))
+	return compat.Sha256(api, append(outer, innerHash[:]...))
+}
+
+func xorConstant(api builder.API, bits [8]vars.Bool, c byte) vars.Byte {
+	for j := 0; j < 8; j++ {
+		if c>>j&1 == 1 {
+			bits[j] = api.Not(bits[j])
+		}
+	}
+	return api.ToByteFromBits(bits)
+}
+
+// Computes HKDF-Extract with SHA-256.
+func HKDFExtract(api builder.API, salt []vars.Byte, secret []vars.Byte) [32]vars.Byte {
+	return HMAC(api, salt, secret)
+}
+
+// Returns the HkdfLabel of RFC 8446, section 7.1.
+func hkdfLabel(label string, context []byte, length int) []byte {
+	info := []byte{byte(length >> 8), byte(length), byte(len("tls13 ") + len(label))}
+	info = append(info, "tls13 "+label...)
+	info = append(info, byte(len(context)))
+	return append(info, context...)
+}
+
+// Computes HKDF-Expand-Label of TLS 1.3 with SHA-256, for lengths of at most 32 bytes.
+func HKDFExpandLabel(api builder.API, secret [32]vars.Byte, label string, context []vars.Byte, length int) []vars.Byte {
+	if length > 32 {
+		panic("lengths longer than the hash are not supported")
+	}
+	prefix := hkdfLabel(label, make([]byte, len(context)), length)
+	info := vars.NewBytesFrom(prefix[:len(prefix)-len(context)])
+	info = append(info, context...)
+	info = append(info, vars.Byte{Value: vars.NewVariableFromInt(1)})
+	out := HMAC(api, secret[:], info)
+	return out[:length]
+}
+
+// Computes HKDF-Expand-Label natively, for the constants of the key schedule.
+func expandLabel(secret []byte, label string, context []byte, length int) []byte {
+	mac := hmac.New(gosha256.New, secret)
+	mac.Write(hkdfLabel(label, context, length))
+	mac.Write([]byte{1})
+	return mac.Sum(nil)[:length]
+}
+
+// Computes HKDF-Extract natively.
+func extract(salt []byte, secret []byte) []byte {
+	mac := hmac.New(gosha256.New, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,316 @@
+// Gadgets verifying a TLS 1.3 handshake and decrypting application data records, so that a
+// circuit can attest to the contents of a TLS session with a server (zkTLS).
+//
+// The handshake transcript, from the ClientHello to the server Finished, and the (EC)DHE shared
+// secret are part of the witness. The circuit derives the key schedule of RFC 8446 from the shared
+// secret, checks the server CertificateVerify signature under the server key and the server
+// Finished message, and derives the application traffic keys. Records encrypted with these keys
+// can then be decrypted, and ranges of their plaintext selectively disclosed.
+//
+// Only the TLS_AES_128_GCM_SHA256 cipher suite and ecdsa_secp256r1_sha256 CertificateVerify
+// signatures are supported. The server key must be authenticated by the caller, for instance with
+// the x509 gadget, and handshakes with a HelloRetryRequest or without a server certificate are not
+// supported.
+package tls
+
+import (
+	gosha256 "crypto/sha256"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/cipher/aes"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The types of the handshake messages of the transcript.
+const (
+	typeClientHello         = 0x01
+	typeServerHello         = 0x02
+	typeEncryptedExtensions = 0x08
+	typeCertificate         = 0x0b
+	typeCertificateRequest  = 0x0d
+	typeCertificateVerify   = 0x0f
+	typeFinished            = 0x14
+)
+
+// The SignatureScheme ecdsa_secp256r1_sha256.
+const ecdsaSecp256r1SHA256 = 0x0403
+
+// The content type of application data records.
+const contentTypeApplicationData = 0x17
+
+// A Handshake is the witness of a TLS 1.3 handshake: the handshake messages from the ClientHello to
+// the server Finished, padded with zeros, and the (EC)DHE shared secret.
+type Handshake struct {
+	Transcript   []vars.Byte
+	Length       vars.Variable
+	SharedSecret [32]vars.Byte
+}
+
+// Creates a new handshake of up to maxLength bytes of transcript.
+func NewHandshake(maxLength int) Handshake {
+	var sharedSecret [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		sharedSecret[i] = vars.NewByte()
+	}
+	return Handshake{Transcript: vars.NewBytes(maxLength), Length: vars.NewVariable(), SharedSecret: sharedSecret}
+}
+
+// Sets the handshake from the concatenated handshake messages and the 32 byte shared secret.
+func (h *Handshake) Set(transcript []byte, sharedSecret []byte) error {
+	if len(transcript) > len(h.Transcript) {
+		return fmt.Errorf("transcript length %d exceeds %d", len(transcript), len(h.Transcript))
+	}
+	if len(sharedSecret) != 32 {
+		return fmt.Errorf("shared secret length %d, expected 32", len(sharedSecret))
+	}
+	data := make([]byte, len(h.Transcript))
+	copy(data, transcript)
+	vars.SetBytes(&h.Transcript, data)
+	h.Length = vars.NewVariableFromInt(len(transcript))
+	vars.SetBytes32(&h.SharedSecret, [32]byte(sharedSecret))
+	return nil
+}
+
+// The key and IV of a direction of the connection.
+type TrafficKeys struct {
+	Key [16]vars.Byte
+	IV  [12]vars.Byte
+}
+
+// The application traffic keys of a verified handshake.
+type Session struct {
+	Client TrafficKeys
+	Server TrafficKeys
+}
+
+// The P-256 public key of the server, as big-endian coordinates.
+type PublicKey struct {
+	X [32]vars.Byte
+	Y [32]vars.Byte
+}
+
+// A handshake message of the transcript.
+type message struct {
+	start vars.Variable
+	end   vars.Variable
+}
+
+// Reads the header of the message at offset, which must have the type if active is 1.
+func readMessage(api builder.API, t *byteslice.Table, offset vars.Variable, msgType int, active frontend.Variable) message {
+	fapi := api.FrontendAPI()
+	fapi.AssertIsEqual(fapi.Mul(active, fapi.Sub(t.At(offset, 0).Value.Value, msgType)), 0)
+	length := fapi.Add(
+		fapi.Mul(t.At(offset, 1).Value.Value, 1<<16),
+		fapi.Mul(t.At(offset, 2).Value.Value, 1<<8),
+		t.At(offset, 3).Value.Value,
+	)
+	return message{start: offset, end: vars.Variable{Value: fapi.Add(offset.Value, 4, length)}}
+}
+
+// Reads a DER INTEGER of at most 33 bytes at offset as 32 big-endian bytes, and returns the offset
+// of the next element.
+func readInteger(api builder.API, t *byteslice.Table, offset vars.Variable) ([32]vars.Byte, vars.Variable) {
+	fapi := api.FrontendAPI()
+	fapi.AssertIsEqual(t.At(offset, 0).Value.Value, 0x02)
+	length := vars.Variable{Value: t.At(offset, 1).Value.Value}
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(33))
+	api.AssertIsDifferent(length, vars.ZERO)
+	start := api.Add(offset, vars.NewVariableFromInt(2))
+	end := api.Add(start, length)
+
+	// A 33 byte integer has a leading zero.
+	isLong := fapi.IsZero(fapi.Sub(length.Value, 33))
+	fapi.AssertIsEqual(fapi.Mul(isLong, t.At(start, 0).Value.Value), 0)
+
+	// Byte k of the result is the last 32 - k byte of the integer, for k >= 32 - length.
+	var result [32]vars.Byte
+	isValue := isLong
+	for k := 0; k < 32; k++ {
+		isValue = fapi.Add(isValue, fapi.IsZero(fapi.Sub(length.Value, 32-k)))
+		result[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(isValue, t.At(end, k-32).Value.Value)}}
+	}
+	return result, end
+}
+
+// The key schedule secrets derived without the transcript.
+var (
+	emptyHash    = gosha256.Sum256(nil)
+	earlySecret  = extract(make([]byte, 32), make([]byte, 32))
+	derivedEarly = expandLabel(earlySecret, "derived", emptyHash[:], 32)
+)
+
+// The context of the server CertificateVerify signature, which precedes the transcript hash.
+var certificateVerifyContext = append(
+	[]byte("                                                                "),
+	append([]byte("TLS 1.3, server CertificateVerify"), 0x00)...,
+)
+
+// Verifies the handshake with a server holding the key, and returns the application traffic keys.
+func VerifyHandshake(api builder.API, handshake Handshake, server PublicKey) *Session {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(handshake.Transcript); i++ {
+		rc.Check(handshake.Transcript[i].Value.Value, 8)
+	}
+	for i := 0; i < 32; i++ {
+		rc.Check(handshake.SharedSecret[i].Value.Value, 8)
+	}
+	t := byteslice.NewTable(api, handshake.Transcript, 32, 64)
+
+	// ClientHello, ServerHello, EncryptedExtensions, an optional CertificateRequest, Certificate,
+	// CertificateVerify, and Finished.
+	clientHello := readMessage(api, t, vars.ZERO, typeClientHello, 1)
+	serverHello := readMessage(api, t, clientHello.end, typeServerHello, 1)
+	extensions := readMessage(api, t, serverHello.end, typeEncryptedExtensions, 1)
+	hasRequest := fapi.IsZero(fapi.Sub(t.At(extensions.end, 0).Value.Value, typeCertificateRequest))
+	request := readMessage(api, t, extensions.end, typeCertificateRequest, hasRequest)
+	certificateOffset := fapi.Select(hasRequest, request.end.Value, extensions.end.Value)
+	certificate := readMessage(api, t, vars.Variable{Value: certificateOffset}, typeCertificate, 1)
+	certificateVerify := readMessage(api, t, certificate.end, typeCertificateVerify, 1)
+	finished := readMessage(api, t, certificateVerify.end, typeFinished, 1)
+	api.AssertIsEqual(api.Sub(finished.end, finished.start), vars.NewVariableFromInt(4+32))
+	api.AssertIsEqual(finished.end, handshake.Length)
+
+	helloHash := sha256.HashVariable(api, handshake.Transcript, vars.U64{Value: serverHello.end}, len(handshake.Transcript))
+	certificateHash := sha256.HashVariable(api, handshake.Transcript, vars.U64{Value: certificateVerify.start}, len(handshake.Transcript))
+	certificateVerifyHash := sha256.HashVariable(api, handshake.Transcript, vars.U64{Value: finished.start}, len(handshake.Transcript))
+	finishedHash := sha256.HashVariable(api, handshake.Transcript, vars.U64{Value: finished.end}, len(handshake.Transcript))
+
+	// The CertificateVerify message is the signature scheme and a DER encoded ECDSA signature.
+	fapi.AssertIsEqual(t.At(certificateVerify.start, 4).Value.Value, ecdsaSecp256r1SHA256>>8)
+	fapi.AssertIsEqual(t.At(certificateVerify.start, 5).Value.Value, ecdsaSecp256r1SHA256&0xff)
+	signatureLength := fapi.Add(fapi.Mul(t.At(certificateVerify.start, 6).Value.Value, 256), t.At(certificateVerify.start, 7).Value.Value)
+	signature := api.Add(certificateVerify.start, vars.NewVariableFromInt(8))
+	api.AssertIsEqual(api.Add(signature, vars.Variable{Value: signatureLength}), certificateVerify.end)
+	fapi.AssertIsEqual(t.At(signature, 0).Value.Value, 0x30)
+	fapi.AssertIsEqual(t.At(signature, 1).Value.Value, fapi.Sub(signatureLength, 2))
+	r, rEnd := readInteger(api, t, api.Add(signature, vars.NewVariableFromInt(2)))
+	s, sEnd := readInteger(api, t, rEnd)
+	api.AssertIsEqual(sEnd, certificateVerify.end)
+	signed := append(vars.NewBytesFrom(certificateVerifyContext), certificateHash[:]...)
+	compat.AssertValidP256ECDSA(api, compat.Sha256(api, signed), r, s, server.X, server.Y)
+
+	// The key schedule of RFC 8446, section 7.1.
+	handshakeSecret := HKDFExtract(api, vars.NewBytesFrom(derivedEarly), handshake.SharedSecret[:])
+	serverHandshakeSecret := HKDFExpandLabel(api, handshakeSecret, "s hs traffic", helloHash[:], 32)
+	finishedKey := HKDFExpandLabel(api, [32]vars.Byte(serverHandshakeSecret), "finished", nil, 32)
+	verifyData := HMAC(api, finishedKey, certificateVerifyHash[:])
+	t.AssertEqualAt(api.Add(finished.start, vars.NewVariableFromInt(4)), verifyData[:])
+
+	derived := HKDFExpandLabel(api, handshakeSecret, "derived", vars.NewBytesFrom(emptyHash[:]), 32)
+	masterSecret := HKDFExtract(api, derived, vars.NewBytesFrom(make([]byte, 32)))
+	clientSecret := HKDFExpandLabel(api, masterSecret, "c ap traffic", finishedHash[:], 32)
+	serverSecret := HKDFExpandLabel(api, masterSecret, "s ap traffic", finishedHash[:], 32)
+	return &Session{
+		Client: trafficKeys(api, [32]vars.Byte(clientSecret)),
+		Server: trafficKeys(api, [32]vars.Byte(serverSecret)),
+	}
+}
+
+func trafficKeys(api builder.API, secret [32]vars.Byte) TrafficKeys {
+	return TrafficKeys{
+		Key: [16]vars.Byte(HKDFExpandLabel(api, secret, "key", nil, 16)),
+		IV:  [12]vars.Byte(HKDFExpandLabel(api, secret, "iv", nil, 12)),
+	}
+}
+
+// A Record is the witness of an encrypted application data record: its encrypted TLSInnerPlaintext
+// and tag, the sequence number of the record in its direction, and the length of its content.
+type Record struct {
+	Ciphertext []vars.Byte
+	Tag        [aes.TagSize]vars.Byte
+	Sequence   vars.Variable
+	Length     vars.Variable
+}
+
+// Creates a new record whose TLSInnerPlaintext has the given length, which is public in TLS.
+func NewRecord(length int) Record {
+	var tag [aes.TagSize]vars.Byte
+	for i := 0; i < aes.TagSize; i++ {
+		tag[i] = vars.NewByte()
+	}
+	return Record{Ciphertext: vars.NewBytes(length), Tag: tag, Sequence: vars.NewVariable(), Length: vars.NewVariable()}
+}
+
+// Sets the record from its encrypted payload, without the record header, its sequence number, and
+// the length of its decrypted content.
+func (r *Record) Set(payload []byte, sequence uint64, length int) error {
+	if len(payload) != len(r.Ciphertext)+aes.TagSize {
+		return fmt.Errorf("payload length %d, expected %d", len(payload), len(r.Ciphertext)+aes.TagSize)
+	}
+	vars.SetBytes(&r.Ciphertext, payload[:len(r.Ciphertext)])
+	for i := 0; i < aes.TagSize; i++ {
+		r.Tag[i].Set(payload[len(r.Ciphertext)+i])
+	}
+	r.Sequence = vars.NewVariableFromInt(int(sequence))
+	r.Length = vars.NewVariableFromInt(length)
+	return nil
+}
+
+// Decrypts an application data record under the traffic keys and returns its content, padded
+// with zeros.
+func DecryptRecord(api builder.API, keys TrafficKeys, record Record) []vars.Byte {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(record.Ciphertext); i++ {
+		rc.Check(record.Ciphertext[i].Value.Value, 8)
+	}
+	for i := 0; i < aes.TagSize; i++ {
+		rc.Check(record.Tag[i].Value.Value, 8)
+	}
+
+	// The nonce is the IV xored with the 64-bit sequence number.
+	sequence := api.ToBinaryLE(record.Sequence, 64)
+	var nonce [12]vars.Byte
+	for i := 0; i < 12; i++ {
+		nonce[i] = keys.IV[i]
+		if i >= 4 {
+			bits := api.ToBitsFromByte(keys.IV[i])
+			for j := 0; j < 8; j++ {
+				bits[j] = api.Xor(bits[j], sequence[8*(11-i)+j])
+			}
+			nonce[i] = api.ToByteFromBits(bits)
+		}
+	}
+
+	// The additional data is the record header.
+	length := len(record.Ciphertext) + aes.TagSize
+	header := vars.NewBytesFrom([]byte{contentTypeApplicationData, 0x03, 0x03, byte(length >> 8), byte(length)})
+	plaintext := aes.NewCipher(api, keys.Key).OpenGCM(nonce, record.Ciphertext, record.Tag, header)
+
+	// The TLSInnerPlaintext is the content, its type, and zeros.
+	api.AssertIsLessOrEqual(api.Add(record.Length, vars.ONE), vars.NewVariableFromInt(len(plaintext)))
+	content := make([]vars.Byte, len(plaintext))
+	inContent := frontend.Variable(1)
+	for k := 0; k < len(plaintext); k++ {
+		isType := fapi.IsZero(fapi.Sub(record.Length.Value, k))
+		inContent = fapi.Sub(inContent, isType)
+		value := plaintext[k].Value.Value
+		fapi.AssertIsEqual(fapi.Mul(isType, fapi.Sub(value, contentTypeApplicationData)), 0)
+		fapi.AssertIsEqual(fapi.Mul(fapi.Sub(1, fapi.Add(inContent, isType)), value), 0)
+		content[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inContent, value)}}
+	}
+	return content
+}
+
+// Returns the bytes of data in the range [start, start + length), with the other bytes replaced by
+// zeros, for disclosing part of a plaintext.
+func Reveal(api builder.API, data []vars.Byte, start vars.Variable, length vars.Variable) []vars.Byte {
+	fapi := api.FrontendAPI()
+	end := api.Add(start, length)
+	api.AssertIsLessOrEqual(end, vars.NewVariableFromInt(len(data)))
+	result := make([]vars.Byte, len(data))
+	inRange := frontend.Variable(0)
+	for k := 0; k < len(data); k++ {
+		inRange = fapi.Add(inRange, fapi.IsZero(fapi.Sub(start.Value, k)))
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(end.Value, k)))
+		result[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, data[k].Value.Value)}}
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package tls
+
+import (
+	goaes "crypto/aes"
+	"crypto/cipher"
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	gosha256 "crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Handshake Handshake
+	Record    Record
+	Server    PublicKey
+	Start     vars.Variable
+	Length    vars.Variable
+	Revealed  []vars.Byte
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	session := VerifyHandshake(*api, c.Handshake, c.Server)
+	content := DecryptRecord(*api, session.Server, c.Record)
+	revealed := Reveal(*api, content, c.Start, c.Length)
+	for i := 0; i < len(revealed); i++ {
+		api.AssertIsEqualByte(revealed[i], c.Revealed[i])
+	}
+	return nil
+}
+
+func handshakeMessage(msgType byte, body []byte) []byte {
+	header := []byte{msgType, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}
+	return append(header, body...)
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	data := make([]byte, n)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestKeyScheduleConstants(t *testing.T) {
+	// The early secret and derived secret of RFC 8448, section 3.
+	assert.Equal(t, "33ad0a1c607ec03b09e6cd9893680ce210adf300aa1f2660e1b22e10f170f92a", hex.EncodeToString(earlySecret))
+	assert.Equal(t, "6f2615a108c702c5678f54fc9dbab69716c076189c48250cebeac3576c3611ba", hex.EncodeToString(derivedEarly))
+}
+
+func TestTLS(t *testing.T) {
+	serverKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	sharedSecret := randomBytes(t, 32)
+
+	transcript := handshakeMessage(typeClientHello, randomBytes(t, 100))
+	transcript = append(transcript, handshakeMessage(typeServerHello, randomBytes(t, 80))...)
+	helloHash := gosha256.Sum256(transcript)
+	transcript = append(transcript, handshakeMessage(typeEncryptedExtensions, []byte{0, 0})...)
+	transcript = append(transcript, handshakeMessage(typeCertificate, randomBytes(t, 120))...)
+
+	certificateHash := gosha256.Sum256(transcript)
+	digest := gosha256.Sum256(append(append([]byte{}, certificateVerifyContext...), certificateHash[:]...))
+	r, s, err := goecdsa.Sign(rand.Reader, serverKey, digest[:])
+	assert.NoError(t, err)
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.NoError(t, err)
+	certificateVerify := binary.BigEndian.AppendUint16(nil, ecdsaSecp256r1SHA256)
+	certificateVerify = binary.BigEndian.AppendUint16(certificateVerify, uint16(len(signature)))
+	transcript = append(transcript, handshakeMessage(typeCertificateVerify, append(certificateVerify, signature...))...)
+
+	handshakeSecret := extract(derivedEarly, sharedSecret)
+	serverHandshakeSecret := expandLabel(handshakeSecret, "s hs traffic", helloHash[:], 32)
+	certificateVerifyHash := gosha256.Sum256(transcript)
+	mac := hmac.New(gosha256.New, expandLabel(serverHandshakeSecret, "finished", nil, 32))
+	mac.Write(certificateVerifyHash[:])
+	verifyData := mac.Sum(nil)
+	transcript = append(transcript, handshakeMessage(typeFinished, verifyData)...)
+
+	finishedHash := gosha256.Sum256(transcript)
+	masterSecret := extract(expandLabel(handshakeSecret, "derived", emptyHash[:], 32), make([]byte, 32))
+	serverSecret := expandLabel(masterSecret, "s ap traffic", finishedHash[:], 32)
+	key := expandLabel(serverSecret, "key", nil, 16)
+	iv := expandLabel(serverSecret, "iv", nil, 12)
+
+	// The second record of the server, with three bytes of padding.
+	content := []byte("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n{\"balance\":1000}")
+	inner := append(append([]byte{}, content...), contentTypeApplicationData, 0, 0, 0)
+	nonce := append([]byte{}, iv...)
+	nonce[11] ^= 1
+	block, err := goaes.NewCipher(key)
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	header := []byte{contentTypeApplicationData, 0x03, 0x03, 0, byte(len(inner) + 16)}
+	payload := gcm.Seal(nil, nonce, inner, header)
+
+	start := len(content) - len(`{"balance":1000}`)
+	revealed := make([]byte, len(inner))
+	copy(revealed[start:], `{"balance":1000}`)
+
+	circuit := &testCircuit{
+		Handshake: NewHandshake(512),
+		Record:    NewRecord(len(inner)),
+		Revealed:  vars.NewBytes(len(inner)),
+	}
+	newAssignment := func() *testCircuit {
+		assignment := &testCircuit{
+			Handshake: NewHandshake(512),
+			Record:    NewRecord(len(inner)),
+			Start:     vars.NewVariableFromInt(start),
+			Length:    vars.NewVariableFromInt(len(`{"balance":1000}`)),
+			Revealed:  vars.NewBytesFrom(revealed),
+		}
+		assert.NoError(t, assignment.Handshake.Set(transcript, sharedSecret))
+		assert.NoError(t, assignment.Record.Set(payload, 1, len(content)))
+		vars.SetBytes32(&assignment.Server.X, [32]byte(serverKey.X.FillBytes(make([]byte, 32))))
+		vars.SetBytes32(&assignment.Server.Y, [32]byte(serverKey.Y.FillBytes(make([]byte, 32))))
+		return assignment
+	}
+
+	assignment := newAssignment()
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The record must be decrypted with its sequence number.
+	assignment.Record.Sequence = vars.NewVariableFromInt(0)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The shared secret must match the Finished message of the server.
+	assignment = newAssignment()
+	assignment.Handshake.SharedSecret[0].Set(sharedSecret[0] ^ 1)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The transcript must be signed by the server key.
+	otherKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	assignment = newAssignment()
+	vars.SetBytes32(&assignment.Server.X, [32]byte(otherKey.X.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.Server.Y, [32]byte(otherKey.Y.FillBytes(make([]byte, 32))))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+// A Fiat–Shamir transcript, which derives the challenges of an interactive protocol from the
+// messages of the prover, so that the verifier of the protocol can run in a circuit.
+//
+// The state of a transcript is a single field element, which starts as the label of the protocol.
+// The absorbed elements are buffered until the next challenge, which is the hash of the state and
+// of the buffered elements and becomes the new state. Consecutive challenges without absorbed
+// elements therefore differ, and every challenge depends on the label and on all of the elements
+// absorbed before it. Transcript runs in the circuit and TranscriptValue outside of it, and both
+// derive the same challenges from the same elements.
+package transcript
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	sha256gadget "github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum length of a label, so that it fits in a field element.
+const MaxLabelLength = 31
+
+// A Hasher compresses the state of a transcript and the absorbed elements into the next state.
+type Hasher interface {
+	// Computes the next state in the circuit.
+	Hash(api builder.API, state vars.Variable, in []vars.Variable) vars.Variable
+
+	// Computes the next state outside of the circuit. It must match Hash.
+	HashValues(state *big.Int, in []*big.Int) *big.Int
+}
+
+// Poseidon hashes the state and the elements as h = poseidon(state, in[0:15]) and then
+// h = poseidon(h, in[15i:15(i+1)]), which is the cheapest hasher in circuits.
+type Poseidon struct{}
+
+var _ Hasher = Poseidon{}
+
+func (Poseidon) Hash(api builder.API, state vars.Variable, in []vars.Variable) vars.Variable {
+	for i := 0; i == 0 || i < len(in); i += poseidon.MaxInputs - 1 {
+		end := i + poseidon.MaxInputs - 1
+		if end > len(in) {
+			end = len(in)
+		}
+		state = poseidon.Hash(api, append([]vars.Variable{state}, in[i:end]...))
+	}
+	return state
+}
+
+func (Poseidon) HashValues(state *big.Int, in []*big.Int) *big.Int {
+	for i := 0; i == 0 || i < len(in); i += poseidon.MaxInputs - 1 {
+		end := i + poseidon.MaxInputs - 1
+		if end > len(in) {
+			end = len(in)
+		}
+		state = poseidon.HashValues(append([]*big.Int{state}, in[i:end]...)...)
+	}
+	return state
+}
+
+// SHA256 hashes the state and the elements as sha256(state || in[0] || ... ) & ((1 << 253) - 1),
+// where each element is encoded as 32 big-endian bytes, for protocols whose transcript is also
+// computed on-chain.
+type SHA256 struct{}
+
+var _ Hasher = SHA256{}
+
+func (SHA256) Hash(api builder.API, state vars.Variable, in []vars.Variable) vars.Variable {
+	var data []vars.Byte
+	for _, v := range append([]vars.Variable{state}, in...) {
+		// The decomposition into 256 bits is unique since it checks that the bits are reduced.
+		bits := api.ToBinaryBE(v, 256)
+		for i := 0; i < 32; i++ {
+			var byteBits [8]vars.Bool
+			for j := 0; j < 8; j++ {
+				byteBits[7-j] = bits[8*i+j]
+			}
+			data = append(data, api.ToByteFromBits(byteBits))
+		}
+	}
+	return sha256gadget.HashAndTruncate(api, data, 253)
+}
+
+func (SHA256) HashValues(state *big.Int, in []*big.Int) *big.Int {
+	var data []byte
+	for _, v := range append([]*big.Int{state}, in...) {
+		data = append(data, v.FillBytes(make([]byte, sha256.Size))...)
+	}
+	return sha256utils.HashAndTruncate(data, 253)
+}
+
+// Returns the initial state of a transcript with the label.
+func labelValue(label string) *big.Int {
+	if len(label) > MaxLabelLength {
+		panic(fmt.Sprintf("label %q is longer than %d bytes", label, MaxLabelLength))
+	}
+	return new(big.Int).SetBytes([]byte(label))
+}
+
+// A transcript in the circuit.
+type Transcript struct {
+	api     builder.API
+	hasher  Hasher
+	state   vars.Variable
+	pending []vars.Variable
+}
+
+// Creates a transcript of the protocol with the label, which must be at most MaxLabelLength
+// bytes.
+func New(api builder.API, hasher Hasher, label string) *Transcript {
+	return &Transcript{
+		api:    api,
+		hasher: hasher,
+		state:  vars.NewVariableFromString(labelValue(label).String()),
+	}
+}
+
+// Absorbs the elements into the transcript.
+func (t *Transcript) Absorb(in ...vars.Variable) {
+	t.pending = append(t.pending, in...)
+}
+
+// Returns the next challenge, which depends on the label and on all of the absorbed elements.
+func (t *Transcript) SqueezeChallenge() vars.Variable {
+	t.state = t.hasher.Hash(t.api, t.state, t.pending)
+	t.pending = nil
+	return t.state
+}
+
+// Returns the next n challenges.
+func (t *Transcript) SqueezeChallenges(n int) []vars.Variable {
+	challenges := make([]vars.Variable, n)
+	for i := 0; i < n; i++ {
+		challenges[i] = t.SqueezeChallenge()
+	}
+	return challenges
+}
+
+// A transcript outside of the circuit, for the prover and the witness.
+type TranscriptValue struct {
+	hasher  Hasher
+	state   *big.Int
+	pending []*big.Int
+}
+
+// Creates a transcript of the protocol with the label outside of the circuit.
+func NewValue(hasher Hasher, label string) *TranscriptValue {
+	return &TranscriptValue{hasher: hasher, state: labelValue(label)}
+}
+
+// Absorbs the elements, reduced modulo the scalar field, into the transcript.
+func (t *TranscriptValue) Absorb(in ...*big.Int) {
+	for _, v := range in {
+		t.pending = append(t.pending, new(big.Int).Mod(v, ecc.BN254.ScalarField()))
+	}
+}
+
+// Returns the next challenge, which matches Transcript.SqueezeChallenge.
+func (t *TranscriptValue) SqueezeChallenge() *big.Int {
+	t.state = t.hasher.HashValues(t.state, t.pending)
+	t.pending = nil
+	return new(big.Int).Set(t.state)
+}
+
+// Returns the next n challenges.
+func (t *TranscriptValue) SqueezeChallenges(n int) []*big.Int {
+	challenges := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		challenges[i] = t.SqueezeChallenge()
+	}
+	return challenges
+}
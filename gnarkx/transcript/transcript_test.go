@@ -0,0 +1,75 @@
+package transcript
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The circuit absorbs the first elements, squeezes two challenges, absorbs the rest and squeezes
+// the last challenge.
+type testCircuit struct {
+	First      []vars.Variable
+	Rest       []vars.Variable
+	Challenges [3]vars.Variable
+	hasher     Hasher `gnark:"-"`
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	t := New(*api, c.hasher, "test")
+	t.Absorb(c.First...)
+	challenges := t.SqueezeChallenges(2)
+	t.Absorb(c.Rest...)
+	challenges = append(challenges, t.SqueezeChallenge())
+	for i := range challenges {
+		api.AssertIsEqual(challenges[i], c.Challenges[i])
+	}
+	return nil
+}
+
+func TestTranscript(t *testing.T) {
+	for _, hasher := range []Hasher{Poseidon{}, SHA256{}} {
+		// The lengths cover a single hash and several hashes of Poseidon.
+		for _, n := range []int{0, 3, 20} {
+			first := make([]*big.Int, n)
+			for i := range first {
+				first[i] = big.NewInt(int64(i * 7))
+			}
+			rest := []*big.Int{new(big.Int).Sub(ecc.BN254.ScalarField(), big.NewInt(1))}
+
+			transcript := NewValue(hasher, "test")
+			transcript.Absorb(first...)
+			challenges := transcript.SqueezeChallenges(2)
+			transcript.Absorb(rest...)
+			challenges = append(challenges, transcript.SqueezeChallenge())
+			assert.NotEqual(t, challenges[0].String(), challenges[1].String())
+
+			circuit := testCircuit{First: make([]vars.Variable, n), Rest: make([]vars.Variable, 1), hasher: hasher}
+			witness := testCircuit{First: make([]vars.Variable, n), Rest: make([]vars.Variable, 1)}
+			for i := range first {
+				witness.First[i].Set(first[i])
+			}
+			witness.Rest[0].Set(rest[0])
+			for i := range challenges {
+				witness.Challenges[i].Set(challenges[i])
+			}
+			assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+			// The challenges depend on the absorbed elements.
+			witness.Rest[0].Set(big.NewInt(1))
+			assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+		}
+
+		// The challenges depend on the label.
+		a, b := NewValue(hasher, "a"), NewValue(hasher, "b")
+		assert.NotEqual(t, a.SqueezeChallenge().String(), b.SqueezeChallenge().String())
+	}
+	assert.Panics(t, func() { NewValue(Poseidon{}, "a label that is longer than the field") })
+}
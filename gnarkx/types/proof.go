@@ -1,11 +1,13 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
 
+	"github.com/consensys/gnark/backend/groth16"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
@@ -17,6 +19,30 @@ type Groth16Proof struct {
 	Output hexutil.Bytes  `json:"output,omitempty"`
 }
 
+// Converts a BN254 gnark proof into the format expected by the Solidity verifier.
+func NewGroth16Proof(proof groth16.Proof) (*Groth16Proof, error) {
+	const fpSize = 4 * 8
+	var buf bytes.Buffer
+	_, err := proof.WriteRawTo(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write proof: %w", err)
+	}
+	proofBytes := buf.Bytes()
+	if len(proofBytes) < fpSize*8 {
+		return nil, fmt.Errorf("unexpected proof length %d", len(proofBytes))
+	}
+	output := &Groth16Proof{}
+	output.A[0] = new(big.Int).SetBytes(proofBytes[fpSize*0 : fpSize*1])
+	output.A[1] = new(big.Int).SetBytes(proofBytes[fpSize*1 : fpSize*2])
+	output.B[0][0] = new(big.Int).SetBytes(proofBytes[fpSize*2 : fpSize*3])
+	output.B[0][1] = new(big.Int).SetBytes(proofBytes[fpSize*3 : fpSize*4])
+	output.B[1][0] = new(big.Int).SetBytes(proofBytes[fpSize*4 : fpSize*5])
+	output.B[1][1] = new(big.Int).SetBytes(proofBytes[fpSize*5 : fpSize*6])
+	output.C[0] = new(big.Int).SetBytes(proofBytes[fpSize*6 : fpSize*7])
+	output.C[1] = new(big.Int).SetBytes(proofBytes[fpSize*7 : fpSize*8])
+	return output, nil
+}
+
 // Export saves the proof to a file.
 func (g *Groth16Proof) Export(file string) error {
 	// Write the proof to a JSON-compatible format.
@@ -0,0 +1,127 @@
+package uniswap
+
+import (
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fixedpoint"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The bounds of ticks, for which 1.0001^tick is within the range of prices of Uniswap V3.
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+// The Q128.128 values of sqrt(1.0001^-(2^i)) of TickMath.getSqrtRatioAtTick.
+var tickRatios = func() []*big.Int {
+	hex := []string{
+		"fffcb933bd6fad37aa2d162d1a594001",
+		"fff97272373d413259a46990580e213a",
+		"fff2e50f5f656932ef12357cf3c7fdcc",
+		"ffe5caca7e10e4e61c3624eaa0941cd0",
+		"ffcb9843d60f6159c9db58835c926644",
+		"ff973b41fa98c081472e6896dfb254c0",
+		"ff2ea16466c96a3843ec78b326b52861",
+		"fe5dee046a99a2a811c461f1969c3053",
+		"fcbe86c7900a88aedcffc83b479aa3a4",
+		"f987a7253ac413176f2b074cf7815e54",
+		"f3392b0822b70005940c7a398e4b70f3",
+		"e7159475a2c29b7443b29c7fa6e889d9",
+		"d097f3bdfd2022b8845ad8f792aa5825",
+		"a9f746462d870fdf8a65dc1f90e061e5",
+		"70d869a156d2a1b890bb3df62baf32f7",
+		"31be135f97d08fd981231505542fcfa6",
+		"9aa508b5b7a84e1c677de54f3e99bc9",
+		"5d6af8dedb81196699c329225ee604",
+		"2216e584f5fa1ea926041bedfe98",
+		"48a170391f7dc42444e8fa2",
+	}
+	ratios := make([]*big.Int, len(hex))
+	for i := 0; i < len(hex); i++ {
+		ratios[i], _ = new(big.Int).SetString(hex[i], 16)
+	}
+	return ratios
+}()
+
+var (
+	q32      = new(big.Int).Lsh(big.NewInt(1), 32)
+	q128     = new(big.Int).Lsh(big.NewInt(1), 128)
+	maxUint  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	int24Min = new(big.Int).Lsh(big.NewInt(1), 23)
+)
+
+// Returns sqrt(1.0001^tick) as a Q64.96, exactly as TickMath.getSqrtRatioAtTick.
+func SqrtRatioAtTick(tick int64) *big.Int {
+	absTick := tick
+	if tick < 0 {
+		absTick = -tick
+	}
+	if absTick > MaxTick {
+		panic("tick out of range")
+	}
+	ratio := new(big.Int).Set(q128)
+	for i := 0; i < len(tickRatios); i++ {
+		if absTick&(1<<i) != 0 {
+			ratio.Mul(ratio, tickRatios[i])
+			ratio.Rsh(ratio, 128)
+		}
+	}
+	if tick > 0 {
+		ratio.Div(maxUint, ratio)
+	}
+	sqrtPrice, remainder := new(big.Int).QuoRem(ratio, q32, new(big.Int))
+	if remainder.Sign() != 0 {
+		sqrtPrice.Add(sqrtPrice, big.NewInt(1))
+	}
+	return sqrtPrice
+}
+
+// Returns sqrt(1.0001^tick) as a Q64.96 as TickMath.getSqrtRatioAtTick, asserting that the tick
+// is between MinTick and MaxTick.
+func sqrtRatioAtTick(api builder.API, tick vars.Variable) fixedpoint.Uint256 {
+	isNegative, absBits := decodeTick(api, tick)
+
+	ratio := fixedpoint.Constant(q128)
+	for i := 0; i < len(tickRatios); i++ {
+		next := fixedpoint.MulDiv(api, ratio, fixedpoint.Constant(tickRatios[i]), fixedpoint.Constant(q128))
+		ratio = fixedpoint.Select(api, absBits[i], next, ratio)
+	}
+	inverse := fixedpoint.MulDiv(api, fixedpoint.Constant(maxUint), fixedpoint.Constant(big.NewInt(1)), ratio)
+	isZero := api.IsZero(tick)
+	isPositive := api.And(api.Not(isNegative), api.Not(isZero))
+	ratio = fixedpoint.Select(api, isPositive, inverse, ratio)
+	return fixedpoint.MulDivRoundingUp(api, ratio, fixedpoint.Constant(big.NewInt(1)), fixedpoint.Constant(q32))
+}
+
+// Returns whether the tick is negative and the bits of its absolute value, asserting that the
+// tick is between MinTick and MaxTick.
+func decodeTick(api builder.API, tick vars.Variable) (vars.Bool, []vars.Bool) {
+	bits := api.ToBinaryLE(api.Add(tick, vars.Variable{Value: int24Min}), 24)
+	isNegative := api.Not(bits[23])
+	abs := api.Select(isNegative, api.Neg(tick), tick)
+	absBits := api.ToBinaryLE(abs, len(tickRatios))
+	api.AssertIsLessOrEqual(abs, vars.NewVariableFromInt(MaxTick))
+	return isNegative, absBits
+}
+
+// Returns the ABI word of an int24 tick, which is assumed to be between MinTick and MaxTick.
+func tickWord(api builder.API, tick vars.Variable) [32]vars.Byte {
+	bits := api.ToBinaryLE(api.Add(tick, vars.Variable{Value: int24Min}), 24)
+	// The two's complement of the tick is its offset by 2^23 with the top bit flipped, and the
+	// top bit is the sign, which is extended to the whole word.
+	isNegative := api.Not(bits[23])
+	bits[23] = isNegative
+	var word [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		if i < 29 {
+			word[i] = vars.Byte{Value: api.Mul(isNegative.Value, vars.NewVariableFromInt(0xff))}
+			continue
+		}
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[(31-i)*8:(32-i)*8])
+		word[i] = api.ToByteFromBits(byteBits)
+	}
+	return word
+}
@@ -0,0 +1,72 @@
+package uniswap
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/fixedpoint"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func TestSqrtRatioAtTick(t *testing.T) {
+	assert.Equal(t, "4295128739", SqrtRatioAtTick(MinTick).String())
+	assert.Equal(t, "1461446703485210103287273052203988822378723970342", SqrtRatioAtTick(MaxTick).String())
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(1), 96), SqrtRatioAtTick(0))
+
+	// Every ratio of the table is sqrt(1.0001^-(2^i)).
+	for i := 0; i < len(tickRatios); i++ {
+		for _, tick := range []int64{-(1 << i), 1 << i} {
+			expected := math.Sqrt(math.Pow(1.0001, float64(tick)))
+			actual, _ := new(big.Float).Quo(new(big.Float).SetInt(SqrtRatioAtTick(tick)), new(big.Float).SetInt(q32)).Float64()
+			actual /= math.Pow(2, 64)
+			assert.InEpsilon(t, expected, actual, 1e-9, "tick %d", tick)
+		}
+	}
+}
+
+type testTickCircuit struct {
+	Tick      vars.Variable
+	Word      [32]vars.Byte
+	SqrtPrice [32]vars.Byte
+}
+
+func (c *testTickCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	word := tickWord(*api, c.Tick)
+	sqrtPrice := fixedpoint.ToBytes(*api, sqrtRatioAtTick(*api, c.Tick))
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(word[i], c.Word[i])
+		api.AssertIsEqualByte(sqrtPrice[i], c.SqrtPrice[i])
+	}
+	return nil
+}
+
+func newTickAssignment(tick int64) *testTickCircuit {
+	assignment := &testTickCircuit{Tick: vars.Variable{Value: new(big.Int).Mod(big.NewInt(tick), ecc.BN254.ScalarField())}}
+	word := big.NewInt(tick)
+	if tick < 0 {
+		word.Add(word, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	vars.SetBytes32(&assignment.Word, [32]byte(word.FillBytes(make([]byte, 32))))
+	vars.SetBytes32(&assignment.SqrtPrice, [32]byte(SqrtRatioAtTick(tick).FillBytes(make([]byte, 32))))
+	return assignment
+}
+
+func TestSqrtRatioAtTickCircuit(t *testing.T) {
+	for _, tick := range []int64{MinTick, -224, -1, 0, 1, 69082, MaxTick} {
+		err := test.IsSolved(&testTickCircuit{}, newTickAssignment(tick), ecc.BN254.ScalarField())
+		assert.NoError(t, err, "tick %d", tick)
+	}
+
+	// The tick must be in range.
+	assignment := newTickAssignment(MaxTick)
+	assignment.Tick = vars.NewVariableFromInt(MaxTick + 1)
+	err := test.IsSolved(&testTickCircuit{}, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
@@ -0,0 +1,315 @@
+// A circuit template proving the time-weighted average price of a Uniswap V3 pool between two
+// blocks from the oracle of the pool, as OracleLibrary.consult computes it on-chain.
+//
+// For each block, the circuit verifies the header, the proof of the pool account and the proofs
+// of slot0 and of the latest observation, and extrapolates the tick cumulative of the observation
+// to the timestamp of the block with the current tick. The arithmetic mean tick over the window
+// is rounded towards negative infinity and converted to a price with TickMath.getSqrtRatioAtTick.
+//
+// The input is the pool address followed by the hashes of the start and end blocks, and the
+// output is the mean tick as an int256 followed by its square root price as a uint256 Q64.96.
+package uniswap
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	ethheader "github.com/succinctlabs/succinctx/gnarkx/ethereum/header"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/storage"
+	"github.com/succinctlabs/succinctx/gnarkx/fixedpoint"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The storage slots of slot0 and of the observations array of UniswapV3Pool.
+const (
+	Slot0Slot        = 0
+	ObservationsSlot = 8
+)
+
+// Config describes the shape of the proofs of a circuit, where eth.Client returns proofs of
+// eth.MaxHeaderLength, eth.MaxDepth and eth.MaxNodeLength.
+type Config struct {
+	MaxHeaderLength int
+	MaxDepth        int
+	MaxNodeLength   int
+}
+
+// The proofs of the oracle of a pool at a block: the header, the proof of the pool account and
+// the proofs of slot0 and of the observation at its observation index, ObservationSlot.
+type Block struct {
+	Header           *eth.Header
+	AccountProof     *eth.MPTProof
+	Slot0Proof       *eth.MPTProof
+	ObservationProof *eth.MPTProof
+}
+
+// Circuit proves the time-weighted average price of a pool between two blocks.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	// The proofs of the start and end blocks.
+	Headers           [2]eth.Header
+	AccountProofs     [2]eth.MPTProof
+	Slot0Proofs       [2]eth.MPTProof
+	ObservationProofs [2]eth.MPTProof
+
+	blocks [2]*Block `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for the config.
+func NewCircuit(config *Config) *Circuit {
+	c := &Circuit{
+		InputBytes:  vars.NewBytes(20 + 32 + 32),
+		OutputBytes: vars.NewBytes(64),
+	}
+	for i := 0; i < 2; i++ {
+		c.Headers[i] = eth.NewHeader(config.MaxHeaderLength)
+		c.AccountProofs[i] = eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxAccountLength)
+		c.Slot0Proofs[i] = eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxStorageValueLength)
+		c.ObservationProofs[i] = eth.NewMPTProof(config.MaxDepth, config.MaxNodeLength, eth.MaxStorageValueLength)
+	}
+	return c
+}
+
+// Returns the input of the circuit for the pool between the blocks.
+func Input(pool common.Address, startBlockHash common.Hash, endBlockHash common.Hash) []byte {
+	return append(append(pool.Bytes(), startBlockHash.Bytes()...), endBlockHash.Bytes()...)
+}
+
+// Returns the storage slot of the observation at the index.
+func ObservationSlot(index uint16) common.Hash {
+	return common.BigToHash(big.NewInt(ObservationsSlot + int64(index)))
+}
+
+// Returns the observation index of the value of slot0, whose observation must be proven.
+func ObservationIndex(slot0 common.Hash) uint16 {
+	return uint16(slot0[7])<<8 | uint16(slot0[8])
+}
+
+// Sets the proofs of the start and end blocks that the next call to SetWitness assigns.
+func (c *Circuit) SetBlocks(start *Block, end *Block) error {
+	blocks := [2]*Block{start, end}
+	for i := 0; i < 2; i++ {
+		if len(blocks[i].Header.RLP) != len(c.Headers[i].RLP) {
+			return fmt.Errorf("header max length %d, expected %d", len(blocks[i].Header.RLP), len(c.Headers[i].RLP))
+		}
+		proofs := []*eth.MPTProof{blocks[i].AccountProof, blocks[i].Slot0Proof, blocks[i].ObservationProof}
+		expected := []eth.MPTProof{c.AccountProofs[i], c.Slot0Proofs[i], c.ObservationProofs[i]}
+		for j := 0; j < len(proofs); j++ {
+			if len(proofs[j].Nodes) != len(expected[j].Nodes) || len(proofs[j].Nodes[0]) != len(expected[j].Nodes[0]) ||
+				len(proofs[j].Value) != len(expected[j].Value) {
+				return fmt.Errorf("proof %d of block %d does not have the shape of the config", j, i)
+			}
+		}
+	}
+	if _, err := meanTick(blocks); err != nil {
+		return err
+	}
+	c.blocks = blocks
+	return nil
+}
+
+// Returns the value of the storage slot of a proof.
+func storageValue(proof *eth.MPTProof) (common.Hash, error) {
+	length := int(proof.ValueLength.Value.(*big.Int).Int64())
+	var value big.Int
+	if err := rlp.DecodeBytes(vars.GetValuesUnsafe(proof.Value[:length]), &value); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid value of storage proof: %w", err)
+	}
+	if value.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("value of storage proof exceeds 256 bits")
+	}
+	return common.BigToHash(&value), nil
+}
+
+// Returns the signed big-endian integer of bytes.
+func signed(in []byte) int64 {
+	value := new(big.Int).SetBytes(in)
+	if in[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(8*len(in))))
+	}
+	return value.Int64()
+}
+
+// Returns the tick cumulative of the pool at the block and the timestamp of the block.
+func tickCumulative(block *Block) (int64, int64, error) {
+	slot0, err := storageValue(block.Slot0Proof)
+	if err != nil {
+		return 0, 0, err
+	}
+	observation, err := storageValue(block.ObservationProof)
+	if err != nil {
+		return 0, 0, err
+	}
+	length := int(block.Header.Length.Value.(*big.Int).Int64())
+	var header types.Header
+	if err := rlp.DecodeBytes(vars.GetValuesUnsafe(block.Header.RLP[:length]), &header); err != nil {
+		return 0, 0, fmt.Errorf("invalid header: %w", err)
+	}
+	timestamp := int64(header.Time)
+	elapsed := timestamp - new(big.Int).SetBytes(observation[28:32]).Int64()
+	if elapsed < 0 {
+		return 0, 0, fmt.Errorf("observation is after the block")
+	}
+	return signed(observation[21:28]) + signed(slot0[9:12])*elapsed, timestamp, nil
+}
+
+// Returns the arithmetic mean tick between the blocks, rounded towards negative infinity.
+func meanTick(blocks [2]*Block) (int64, error) {
+	start, startTime, err := tickCumulative(blocks[0])
+	if err != nil {
+		return 0, err
+	}
+	end, endTime, err := tickCumulative(blocks[1])
+	if err != nil {
+		return 0, err
+	}
+	if endTime <= startTime {
+		return 0, fmt.Errorf("end block is not after the start block")
+	}
+	// Euclidean division by a positive divisor rounds towards negative infinity.
+	tick := new(big.Int).Div(big.NewInt(end-start), big.NewInt(endTime-startTime)).Int64()
+	if tick < MinTick || tick > MaxTick {
+		return 0, fmt.Errorf("mean tick %d out of range", tick)
+	}
+	return tick, nil
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the blocks given to SetBlocks.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.blocks[0] == nil {
+		panic("blocks must be set before the witness")
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	for i := 0; i < 2; i++ {
+		c.Headers[i] = *c.blocks[i].Header
+		c.AccountProofs[i] = *c.blocks[i].AccountProof
+		c.Slot0Proofs[i] = *c.blocks[i].Slot0Proof
+		c.ObservationProofs[i] = *c.blocks[i].ObservationProof
+	}
+	tick, err := meanTick(c.blocks)
+	if err != nil {
+		panic(err)
+	}
+	word := new(big.Int).SetInt64(tick)
+	if tick < 0 {
+		word.Add(word, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	outputs := append(word.FillBytes(make([]byte, 32)), SqrtRatioAtTick(tick).FillBytes(make([]byte, 32))...)
+	vars.SetBytes(&c.OutputBytes, outputs)
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	inputReader := builder.NewInputReader(*api, c.InputBytes)
+	pool := inputReader.ReadAddress()
+	var cumulatives, timestamps [2]vars.Variable
+	for i := 0; i < 2; i++ {
+		blockHash := inputReader.ReadBytes32()
+		cumulatives[i], timestamps[i] = c.verifyBlock(*api, pool, blockHash, i)
+	}
+
+	// The mean tick is the floor of the change in tick cumulative by the elapsed time, which is
+	// positive and fits in 32 bits like the timestamps of observations.
+	elapsed := api.Sub(timestamps[1], timestamps[0])
+	api.ToBinaryLE(api.Sub(elapsed, vars.NewVariableFromInt(1)), 32)
+	delta := api.Sub(cumulatives[1], cumulatives[0])
+	out := api.HintVariables(floorDivHint, 2, delta, elapsed)
+	tick, remainder := out[0], out[1]
+	api.AssertIsEqual(api.Add(api.Mul(tick, elapsed), remainder), delta)
+	api.ToBinaryLE(remainder, 32)
+	api.ToBinaryLE(api.Sub(api.Sub(elapsed, remainder), vars.NewVariableFromInt(1)), 32)
+
+	sqrtPrice := sqrtRatioAtTick(*api, tick)
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(tickWord(*api, tick))
+	outputWriter.WriteBytes32(fixedpoint.ToBytes(*api, sqrtPrice))
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Verifies the proofs of the i-th block against its hash and returns the tick cumulative of the
+// pool at the block and the timestamp of the block.
+func (c *Circuit) verifyBlock(
+	api builder.API,
+	pool [20]vars.Byte,
+	blockHash [32]vars.Byte,
+	i int,
+) (vars.Variable, vars.Variable) {
+	st := storage.NewAPI(&api)
+	header := c.Headers[i]
+	ethheader.NewAPI(&api).Verify(header)
+	api.AssertIsEqualBytes32(header.Hash, blockHash)
+	account := st.VerifyAccount(header.StateRoot, pool, c.AccountProofs[i])
+	slot0 := st.VerifyStorage(account.StorageRoot, storage.ConstantSlot(big.NewInt(Slot0Slot)), c.Slot0Proofs[i])
+
+	// The slot of observations[observationIndex], which is less than 2^24.
+	index := api.ToVariableFromBytesBE(slot0[7:9])
+	bits := api.ToBinaryLE(api.Add(index, vars.NewVariableFromInt(ObservationsSlot)), 24)
+	var slot [32]vars.Byte
+	for j := 0; j < 32; j++ {
+		slot[j] = vars.Byte{Value: vars.NewVariableFromInt(0)}
+		if j >= 29 {
+			var byteBits [8]vars.Bool
+			copy(byteBits[:], bits[(31-j)*8:(32-j)*8])
+			slot[j] = api.ToByteFromBits(byteBits)
+		}
+	}
+	observation := st.VerifyStorage(account.StorageRoot, slot, c.ObservationProofs[i])
+	api.AssertIsEqual(observation[0].Value, vars.NewVariableFromInt(1))
+
+	// The tick cumulative of the observation extrapolated with the current tick.
+	elapsed := api.Sub(header.Timestamp.Value, api.ToVariableFromBytesBE(observation[28:32]))
+	api.ToBinaryLE(elapsed, 32)
+	tick := fromSignedBytes(api, slot0[9:12])
+	cumulative := api.Add(fromSignedBytes(api, observation[21:28]), api.Mul(tick, elapsed))
+	return cumulative, header.Timestamp.Value
+}
+
+// Returns the signed big-endian integer of bytes in two's complement.
+func fromSignedBytes(api builder.API, in []vars.Byte) vars.Variable {
+	sign := api.ToBitsFromByte(in[0])[7]
+	modulus := vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), uint(8*len(in)))}
+	return api.Sub(api.ToVariableFromBytesBE(in), api.Mul(sign.Value, modulus))
+}
+
+// Computes the floor division of a signed dividend by a positive divisor, returning the signed
+// quotient and the remainder.
+var floorDivHint = builder.NewHint("uniswap.floordiv", func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 2 || len(outputs) != 2 {
+		return fmt.Errorf("floordiv: invalid number of inputs or outputs")
+	}
+	dividend := new(big.Int).Set(inputs[0])
+	if dividend.Cmp(new(big.Int).Rsh(field, 1)) > 0 {
+		dividend.Sub(dividend, field)
+	}
+	if inputs[1].Sign() == 0 {
+		return fmt.Errorf("floordiv: division by zero")
+	}
+	q, r := new(big.Int).DivMod(dividend, inputs[1], new(big.Int))
+	outputs[0].Mod(q, field)
+	outputs[1].Set(r)
+	return nil
+})
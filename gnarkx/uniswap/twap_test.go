@@ -0,0 +1,105 @@
+package uniswap
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/inputs/eth"
+	"github.com/succinctlabs/succinctx/gnarkx/test/functiontest"
+)
+
+var pool = common.HexToAddress("0x88e6a0c2ddd26feeb64f039a2c41296fcb3f5640")
+
+// Returns the two's complement of a signed integer in n bytes.
+func twosComplement(v int64, n int) []byte {
+	value := big.NewInt(v)
+	if v < 0 {
+		value.Add(value, new(big.Int).Lsh(big.NewInt(1), uint(8*n)))
+	}
+	return value.FillBytes(make([]byte, n))
+}
+
+// Returns the proofs of a block at the timestamp where the pool has the tick and its latest
+// observation is at the index with the timestamp and tick cumulative.
+func newBlock(t *testing.T, timestamp uint64, tick int64, index uint16, observed uint32, cumulative int64) (*Block, common.Hash) {
+	var slot0, observation common.Hash
+	slot0[31] = 1 // sqrtPriceX96
+	copy(slot0[9:12], twosComplement(tick, 3))
+	slot0[7], slot0[8] = byte(index>>8), byte(index)
+	slot0[1] = 1 // unlocked
+	observation[0] = 1
+	copy(observation[21:28], twosComplement(cumulative, 7))
+	observation[28], observation[29], observation[30], observation[31] =
+		byte(observed>>24), byte(observed>>16), byte(observed>>8), byte(observed)
+
+	storage := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	values := make(map[common.Hash][]byte)
+	for slot, value := range map[common.Hash]common.Hash{
+		common.BigToHash(big.NewInt(Slot0Slot)): slot0,
+		ObservationSlot(index):                  observation,
+		ObservationSlot(index - 1):              common.HexToHash("0x01"),
+		common.HexToHash("0x04"):                common.HexToHash("0x1234"),
+	} {
+		encoded, err := rlp.EncodeToBytes(value.Big())
+		assert.NoError(t, err)
+		storage.MustUpdate(crypto.Keccak256(slot[:]), encoded)
+		values[slot] = encoded
+	}
+
+	accounts := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	account, err := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(0), storage.Hash(), types.EmptyCodeHash})
+	assert.NoError(t, err)
+	accounts.MustUpdate(crypto.Keccak256(pool.Bytes()), account)
+	for i := 0; i < 10; i++ {
+		other, err := rlp.EncodeToBytes([]interface{}{uint64(0), big.NewInt(int64(i)), types.EmptyRootHash, types.EmptyCodeHash})
+		assert.NoError(t, err)
+		accounts.MustUpdate(crypto.Keccak256(common.BigToAddress(big.NewInt(int64(i))).Bytes()), other)
+	}
+
+	block := &types.Header{
+		Root:       accounts.Hash(),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(int64(timestamp / 12)),
+		GasLimit:   30000000,
+		Time:       timestamp,
+		BaseFee:    big.NewInt(20e9),
+	}
+	header := eth.NewHeader(eth.MaxHeaderLength)
+	assert.NoError(t, header.Set(block))
+	slot0Slot := common.BigToHash(big.NewInt(Slot0Slot))
+	observationSlot := ObservationSlot(ObservationIndex(slot0))
+	return &Block{
+		Header:           &header,
+		AccountProof:     functiontest.Prove(t, accounts, pool.Bytes(), account, 4, eth.MaxNodeLength, eth.MaxAccountLength),
+		Slot0Proof:       functiontest.Prove(t, storage, slot0Slot[:], values[slot0Slot], 4, eth.MaxNodeLength, eth.MaxStorageValueLength),
+		ObservationProof: functiontest.Prove(t, storage, observationSlot[:], values[observationSlot], 4, eth.MaxNodeLength, eth.MaxStorageValueLength),
+	}, block.Hash()
+}
+
+func TestTWAP(t *testing.T) {
+	config := &Config{MaxHeaderLength: eth.MaxHeaderLength, MaxDepth: 4, MaxNodeLength: eth.MaxNodeLength}
+	start, startHash := newBlock(t, 1700000000, -200, 5, 1699999900, -1000000)
+	end, endHash := newBlock(t, 1700003600, -210, 7, 1700003000, -1700000)
+
+	// The tick cumulatives are -1020000 and -1826000, so the mean tick is floor(-806000 / 3600).
+	circuit := NewCircuit(config)
+	assert.NoError(t, circuit.SetBlocks(start, end))
+	assert.NoError(t, functiontest.IsSolved(circuit, Input(pool, startHash, endHash)))
+	expected := append(twosComplement(-224, 32), SqrtRatioAtTick(-224).FillBytes(make([]byte, 32))...)
+	for i := 0; i < len(expected); i++ {
+		assert.Equal(t, expected[i], circuit.OutputBytes[i].GetValueUnsafe())
+	}
+
+	// The blocks must be the blocks of the input.
+	assert.Error(t, functiontest.IsSolved(circuit, Input(pool, endHash, startHash)))
+
+	// The end block must be after the start block.
+	assert.Error(t, circuit.SetBlocks(end, start))
+}
@@ -59,3 +59,9 @@ func TruncateBytes32(data [32]byte, nbBits int) [32]byte {
 	out := make([]byte, 32)
 	return [32]byte(result.FillBytes(out))
 }
+
+// Packs a digest into two elements as builder.API.PackDigest: the big-endian integers of its
+// first and last 16 bytes.
+func PackDigest(digest [32]byte) [2]*big.Int {
+	return [2]*big.Int{new(big.Int).SetBytes(digest[:16]), new(big.Int).SetBytes(digest[16:])}
+}
@@ -0,0 +1,28 @@
+// Helpers for the little-endian limbs of big integers, as the hints of the circuits that emulate
+// arithmetic over large integers compute them.
+package limbutils
+
+import "math/big"
+
+// Returns the integer of the little-endian limbs of limbBits bits.
+func FromLimbs(limbs []*big.Int, limbBits int) *big.Int {
+	result := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		result.Lsh(result, uint(limbBits))
+		result.Add(result, limbs[i])
+	}
+	return result
+}
+
+// Returns the n little-endian limbs of limbBits bits of the integer, which must be non-negative.
+// The bits above the limbs are dropped.
+func ToLimbs(x *big.Int, n int, limbBits int) []*big.Int {
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(limbBits)), big.NewInt(1))
+	limbs := make([]*big.Int, n)
+	tmp := new(big.Int).Set(x)
+	for i := 0; i < n; i++ {
+		limbs[i] = new(big.Int).And(tmp, mask)
+		tmp.Rsh(tmp, uint(limbBits))
+	}
+	return limbs
+}
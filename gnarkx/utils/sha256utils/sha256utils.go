@@ -2,7 +2,11 @@ package sha256utils
 
 import (
 	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
 	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/utils/byteutils"
 )
 
 // Computes sha256(data) & ((1 << nbBits) - 1)).
@@ -21,3 +25,28 @@ func HashAndTruncate(data []byte, nbBits int) *big.Int {
 	result := new(big.Int).And(value, mask)
 	return result
 }
+
+// Computes sha256(data) packed into two elements, the high and the low 128 bits.
+func HashToFieldElements(data []byte) [2]*big.Int {
+	return byteutils.PackDigest(sha256.Sum256(data))
+}
+
+// Computes the midstate of sha256 after the prefix, whose length must be a multiple of 64, the
+// state of the hasher after compressing its chunks.
+func Midstate(prefix []byte) [8]uint32 {
+	if len(prefix)%sha256.BlockSize != 0 {
+		panic("the length of the prefix of a midstate must be a multiple of 64")
+	}
+	hasher := sha256.New()
+	hasher.Write(prefix)
+	// The marshaled hasher is a magic of 4 bytes followed by the big-endian words of the state.
+	state, err := hasher.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	var midstate [8]uint32
+	for i := 0; i < 8; i++ {
+		midstate[i] = binary.BigEndian.Uint32(state[4+4*i:])
+	}
+	return midstate
+}
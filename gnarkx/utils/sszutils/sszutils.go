@@ -85,3 +85,70 @@ func NewBytes32FromBytesRightPad(data []byte) [32]byte {
 	copy(res[:], data)
 	return res
 }
+
+// Returns the root of a subtree of the given depth whose leaves are all zero.
+func ZeroHash(depth int) [32]byte {
+	var hash [32]byte
+	for i := 0; i < depth; i++ {
+		hash = Hash(append(hash[:], hash[:]...))
+	}
+	return hash
+}
+
+// Returns the number of layers of a tree with limit leaves, rounded up to a power of 2.
+func Depth(limit int) int {
+	depth := 0
+	for 1<<depth < limit {
+		depth++
+	}
+	return depth
+}
+
+// Computes the root of the chunks as a tree of limit leaves, rounded up to a power of 2, where
+// the leaves past the chunks are zero.
+func Merkleize(chunks [][32]byte, limit int) [32]byte {
+	if len(chunks) > limit {
+		panic("more chunks than the limit")
+	}
+	layer := append([][32]byte{}, chunks...)
+	for depth := 0; depth < Depth(limit); depth++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, ZeroHash(depth))
+		}
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = Hash(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	if len(layer) == 0 {
+		return ZeroHash(Depth(limit))
+	}
+	return layer[0]
+}
+
+// Mixes the length of a list into the root of its chunks.
+func MixInLength(root [32]byte, length uint64) [32]byte {
+	lengthBytes := NewBytes32FromU64LE(length)
+	return Hash(append(root[:], lengthBytes[:]...))
+}
+
+// Packs bytes into chunks, right padding the last chunk with zeros.
+func PackBytes(data []byte) [][32]byte {
+	chunks := make([][32]byte, (len(data)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], data[i*32:])
+	}
+	return chunks
+}
+
+// Packs u64s into chunks of four little-endian values.
+func PackU64s(values []uint64) [][32]byte {
+	chunks := make([][32]byte, (len(values)+3)/4)
+	for i, v := range values {
+		for j := 0; j < 8; j++ {
+			chunks[i/4][(i%4)*8+j] = byte(v >> (8 * j))
+		}
+	}
+	return chunks
+}
@@ -87,24 +87,29 @@ func SetBytesArray(b *[][]Byte, i1 [][]byte) {
 	}
 }
 
-func NewBytes32() [32]Byte {
-	var result [32]Byte
+// A bytes32 as a variable in a circuit, such as a digest or a word of the EVM. It is an alias of
+// [32]Byte, so the arrays returned by the hash gadgets are bytes32 as is.
+type Bytes32 = [32]Byte
+
+// Creates a new bytes32 as a variable in a circuit.
+func NewBytes32() Bytes32 {
+	var result Bytes32
 	for i := 0; i < 32; i++ {
 		result[i] = ZERO_BYTE
 	}
 	return result
 }
 
-// Creates a new bytes32 as a variable in a circuit.
-func SetBytes32(b *[32]Byte, i1 [32]byte) {
+// Sets a bytes32 from a value.
+func SetBytes32(b *Bytes32, i1 [32]byte) {
 	for i := 0; i < 32; i++ {
 		b[i].Set(i1[i])
 	}
 }
 
 // ReverseBytes32 returns a bytes32 with the bytes reversed.
-func ReverseBytes32(b [32]Byte) [32]Byte {
-	var result [32]Byte
+func ReverseBytes32(b Bytes32) Bytes32 {
+	var result Bytes32
 	for i := 0; i < 32; i++ {
 		result[i] = b[32-i-1]
 	}
@@ -155,3 +160,27 @@ func SetBytes32Array(b *[][32]Byte, i1 [][32]byte) {
 		SetBytes32(&(*b)[i], i1[i])
 	}
 }
+
+// Bytes whose length is only known at proving time: the first Length bytes of Data, where
+// len(Data), the maximum length, is a compile time constant. The bytes of Data past Length are
+// zero for the results of the functions of the byteslice package.
+type VariableBytes struct {
+	Data   []Byte
+	Length Variable
+}
+
+// Creates new variable bytes of up to maxLength bytes as a variable in a circuit.
+func NewVariableBytes(maxLength int) VariableBytes {
+	return VariableBytes{Data: NewBytes(maxLength), Length: ZERO}
+}
+
+// Sets the variable bytes to i1, padded with zeros to the maximum length.
+func SetVariableBytes(b *VariableBytes, i1 []byte) {
+	if len(i1) > len(b.Data) {
+		panic("length of data is greater than the maximum length")
+	}
+	padded := make([]byte, len(b.Data))
+	copy(padded, i1)
+	SetBytes(&b.Data, padded)
+	b.Length = NewVariableFromInt(len(i1))
+}
@@ -0,0 +1,27 @@
+package vars
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// A variable in a circuit representing a u256, as little-endian limbs of 64 bits.
+type U256 struct {
+	Limbs [4]Variable
+}
+
+// Creates a new u256 as a variable in a circuit.
+func NewU256() U256 {
+	return U256{Limbs: [4]Variable{ZERO, ZERO, ZERO, ZERO}}
+}
+
+// Sets the u256 to i1, which must fit in 256 bits.
+func (u *U256) Set(i1 *big.Int) {
+	if i1.Sign() < 0 || i1.BitLen() > 256 {
+		panic(fmt.Sprintf("%s does not fit in 256 bits", i1))
+	}
+	for i := 0; i < 4; i++ {
+		limb := new(big.Int).Rsh(i1, uint(64*i))
+		u.Limbs[i].Set(limb.And(limb, new(big.Int).SetUint64(^uint64(0))))
+	}
+}
@@ -0,0 +1,15 @@
+package vars
+
+// A variable in a circuit representing a u32.
+type U32 struct {
+	Value Variable
+}
+
+// Creates a new u32 as a variable in a circuit.
+func NewU32() U32 {
+	return U32{Value: ZERO}
+}
+
+func (u *U32) Set(i1 uint32) {
+	u.Value = NewVariableFromInt(int(i1))
+}
@@ -1,5 +1,7 @@
 package vars
 
+import "math"
+
 // A variable in a circuit representing a u64.
 type U64 struct {
 	Value Variable
@@ -10,6 +12,11 @@ func NewU64() U64 {
 	return U64{Value: ZERO}
 }
 
+// Sets the u64 to i1. Values of 2^63 and above are kept as a uint64 so that they do not wrap.
 func (u *U64) Set(i1 uint64) {
+	if i1 > math.MaxInt64 {
+		u.Value = Variable{Value: i1}
+		return
+	}
 	u.Value = NewVariableFromInt(int(i1))
 }
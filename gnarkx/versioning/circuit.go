@@ -0,0 +1,34 @@
+package versioning
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// A Versioned circuit appends a constant version number to the public inputs of a circuit. A
+// verifier that checks the version public input rejects proofs from older deployments even if the
+// rest of the public inputs match. Note that the extra public input changes the verifying key, so
+// the wrapped circuit needs a verifier that accepts one more public input.
+type Versioned struct {
+	// The wrapped circuit.
+	Circuit frontend.Circuit
+
+	// The version, which is constrained to equal the version the circuit was created with.
+	Version frontend.Variable `gnark:"version,public"`
+
+	version uint32 `gnark:"-"`
+}
+
+// Creates a new versioned circuit. The same version must be used for compilation and assignment.
+func NewVersioned(circuit frontend.Circuit, version uint32) *Versioned {
+	return &Versioned{
+		Circuit: circuit,
+		Version: version,
+		version: version,
+	}
+}
+
+// Define the circuit, constraining the version public input and the wrapped circuit.
+func (c *Versioned) Define(api frontend.API) error {
+	api.AssertIsEqual(c.Version, c.version)
+	return c.Circuit.Define(api)
+}
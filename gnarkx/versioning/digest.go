@@ -0,0 +1,61 @@
+// Tooling for managing upgrades of deployed circuits. Verifying keys are identified by a canonical
+// digest, circuits can commit to a version in their public inputs, and artifacts can be checked
+// against an expected digest at runtime before they are used for proving.
+package versioning
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Computes the canonical digest of a verifying key, which is the sha256 of its uncompressed
+// serialization prefixed with the curve identifier.
+func Digest(vk groth16.VerifyingKey) ([32]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(vk.CurveID().String())
+	if _, err := vk.WriteRawTo(&buf); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to serialize verifying key: %w", err)
+	}
+	return sha256.Sum256(buf.Bytes()), nil
+}
+
+// Returns an error if the digest of the verifying key does not match the expected digest.
+func VerifyDigest(vk groth16.VerifyingKey, expected [32]byte) error {
+	digest, err := Digest(vk)
+	if err != nil {
+		return err
+	}
+	if digest != expected {
+		return fmt.Errorf(
+			"verifying key digest mismatch: expected %s, got %s",
+			hexutil.Encode(expected[:]),
+			hexutil.Encode(digest[:]),
+		)
+	}
+	return nil
+}
+
+// Reads a verifying key over the given curve from a file, such as build/vkey.bin, and checks that
+// it matches the expected digest.
+func VerifyArtifact(curve ecc.ID, file string, expected [32]byte) (groth16.VerifyingKey, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	vk := groth16.NewVerifyingKey(curve)
+	if _, err := vk.ReadFrom(f); err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	if err := VerifyDigest(vk, expected); err != nil {
+		return nil, err
+	}
+	return vk, nil
+}
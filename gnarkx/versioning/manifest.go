@@ -0,0 +1,97 @@
+package versioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// A Release is a deployed version of a circuit identified by its verifying key digest.
+type Release struct {
+	Version uint32        `json:"version"`
+	Digest  hexutil.Bytes `json:"digest"`
+}
+
+// A Manifest is the ordered history of the releases of a circuit.
+type Manifest struct {
+	Releases []Release `json:"releases"`
+}
+
+// Loads a manifest that was previously exported to a file.
+func LoadManifest(file string) (*Manifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Export saves the manifest to a file.
+func (m *Manifest) Export(file string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Adds a new release of the circuit with the given verifying key. The version must be greater
+// than the latest release and the verifying key must not have been released before.
+func (m *Manifest) Add(version uint32, vk groth16.VerifyingKey) (*Release, error) {
+	digest, err := Digest(vk)
+	if err != nil {
+		return nil, err
+	}
+	if latest := m.Latest(); latest != nil && version <= latest.Version {
+		return nil, fmt.Errorf("version %d is not greater than latest version %d", version, latest.Version)
+	}
+	if release := m.Find(digest); release != nil {
+		return nil, fmt.Errorf("verifying key was already released as version %d", release.Version)
+	}
+	m.Releases = append(m.Releases, Release{Version: version, Digest: digest[:]})
+	return &m.Releases[len(m.Releases)-1], nil
+}
+
+// Returns the latest release, or nil if there are none.
+func (m *Manifest) Latest() *Release {
+	if len(m.Releases) == 0 {
+		return nil
+	}
+	return &m.Releases[len(m.Releases)-1]
+}
+
+// Returns the release with the given digest, or nil if there is none.
+func (m *Manifest) Find(digest [32]byte) *Release {
+	for i := range m.Releases {
+		if bytes.Equal(m.Releases[i].Digest, digest[:]) {
+			return &m.Releases[i]
+		}
+	}
+	return nil
+}
+
+// Returns an error if the verifying key is not the latest release, such as when a stale build is
+// about to be used for proving after an upgrade.
+func (m *Manifest) VerifyLatest(vk groth16.VerifyingKey) error {
+	latest := m.Latest()
+	if latest == nil {
+		return fmt.Errorf("manifest has no releases")
+	}
+	var expected [32]byte
+	if len(latest.Digest) != len(expected) {
+		return fmt.Errorf("invalid digest length %d", len(latest.Digest))
+	}
+	copy(expected[:], latest.Digest)
+	return VerifyDigest(vk, expected)
+}
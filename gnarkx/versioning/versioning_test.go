@@ -0,0 +1,85 @@
+package versioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *testCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.X, api.Mul(c.Y, c.Y))
+	return nil
+}
+
+func setup(t *testing.T, version uint32) groth16.VerifyingKey {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, NewVersioned(&testCircuit{}, version))
+	assert.NoError(t, err)
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	return vk
+}
+
+func TestDigest(t *testing.T) {
+	vk := setup(t, 1)
+	digest, err := Digest(vk)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyDigest(vk, digest))
+
+	file := filepath.Join(t.TempDir(), "vkey.bin")
+	f, err := os.Create(file)
+	assert.NoError(t, err)
+	_, err = vk.WriteTo(f)
+	assert.NoError(t, err)
+	f.Close()
+	_, err = VerifyArtifact(ecc.BN254, file, digest)
+	assert.NoError(t, err)
+
+	other := setup(t, 1)
+	assert.Error(t, VerifyDigest(other, digest))
+	_, err = VerifyArtifact(ecc.BN254, file, [32]byte{})
+	assert.Error(t, err)
+}
+
+func TestManifest(t *testing.T) {
+	v1 := setup(t, 1)
+	v2 := setup(t, 2)
+
+	m := &Manifest{}
+	_, err := m.Add(1, v1)
+	assert.NoError(t, err)
+	_, err = m.Add(1, v2)
+	assert.Error(t, err)
+	_, err = m.Add(2, v1)
+	assert.Error(t, err)
+	_, err = m.Add(2, v2)
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "manifest.json")
+	assert.NoError(t, m.Export(file))
+	loaded, err := LoadManifest(file)
+	assert.NoError(t, err)
+	assert.Equal(t, m, loaded)
+	assert.NoError(t, loaded.VerifyLatest(v2))
+	assert.Error(t, loaded.VerifyLatest(v1))
+}
+
+func TestVersioned(t *testing.T) {
+	circuit := NewVersioned(&testCircuit{}, 2)
+	err := test.IsSolved(circuit, NewVersioned(&testCircuit{X: 9, Y: 3}, 2), ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	err = test.IsSolved(circuit, NewVersioned(&testCircuit{X: 9, Y: 3}, 1), ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
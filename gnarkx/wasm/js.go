@@ -0,0 +1,26 @@
+//go:build js && wasm
+
+package wasm
+
+import (
+	"syscall/js"
+
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+)
+
+// Registers a global JavaScript function with the given name that generates witnesses for the
+// circuit, and blocks forever so that the function stays callable. The function takes a JSON
+// encoded Request string and returns a JSON encoded Response string, or throws an Error.
+func Serve(name string, c succinct.Circuit) {
+	js.Global().Set(name, js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			panic(js.Global().Get("Error").New("expected a single JSON string argument"))
+		}
+		response, err := GenerateWitness(c, []byte(args[0].String()))
+		if err != nil {
+			panic(js.Global().Get("Error").New(err.Error()))
+		}
+		return string(response)
+	}))
+	select {}
+}
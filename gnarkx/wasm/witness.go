@@ -0,0 +1,58 @@
+// Witness generation for circuit functions that compiles to WebAssembly. Browsers can generate
+// the witness for an input locally and send the serialized witness to a proving service, which
+// deserializes it with witness.New(ecc.BN254.ScalarField()) and UnmarshalBinary.
+//
+// Build with GOOS=js GOARCH=wasm and call Serve from the main function of the program.
+package wasm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The JSON request for witness generation.
+type Request struct {
+	Input hexutil.Bytes `json:"input"`
+}
+
+// The JSON response of witness generation.
+type Response struct {
+	Witness    hexutil.Bytes `json:"witness"`
+	InputHash  *hexutil.Big  `json:"inputHash"`
+	OutputHash *hexutil.Big  `json:"outputHash"`
+	Output     hexutil.Bytes `json:"output"`
+}
+
+// Generates the serialized witness of a circuit for a JSON encoded Request and returns the JSON
+// encoded Response.
+func GenerateWitness(c succinct.Circuit, request []byte) ([]byte, error) {
+	var req Request
+	if err := json.Unmarshal(request, &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+	if len(*c.GetInputBytes()) != len(req.Input) {
+		return nil, fmt.Errorf("expected %d input bytes, got %d", len(*c.GetInputBytes()), len(req.Input))
+	}
+
+	f := succinct.NewCircuitFunction(c)
+	w, err := f.Witness(req.Input)
+	if err != nil {
+		return nil, err
+	}
+	data, err := w.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize witness: %w", err)
+	}
+
+	return json.Marshal(Response{
+		Witness:    data,
+		InputHash:  (*hexutil.Big)(f.InputHash.Value.(*big.Int)),
+		OutputHash: (*hexutil.Big)(f.OutputHash.Value.(*big.Int)),
+		Output:     vars.GetValuesUnsafe(*f.Circuit.GetOutputBytes()),
+	})
+}
@@ -0,0 +1,67 @@
+package wasm
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestCircuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+}
+
+func (c *TestCircuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *TestCircuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *TestCircuit) Assign(in []byte) error {
+	return nil
+}
+
+func (c *TestCircuit) SetWitness(inputBytes []byte) {
+	vars.SetBytes(&c.InputBytes, inputBytes)
+	vars.SetBytes(&c.OutputBytes, []byte{inputBytes[1], inputBytes[0]})
+}
+
+func (c *TestCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqualByte(c.OutputBytes[0], c.InputBytes[1])
+	api.AssertIsEqualByte(c.OutputBytes[1], c.InputBytes[0])
+	return nil
+}
+
+func TestGenerateWitness(t *testing.T) {
+	c := &TestCircuit{InputBytes: vars.NewBytes(2), OutputBytes: vars.NewBytes(2)}
+	data, err := GenerateWitness(c, []byte(`{"input":"0x0102"}`))
+	assert.NoError(t, err)
+
+	var response Response
+	assert.NoError(t, json.Unmarshal(data, &response))
+	assert.Equal(t, []byte{2, 1}, []byte(response.Output))
+	assert.Equal(t, sha256utils.HashAndTruncate([]byte{1, 2}, 253), response.InputHash.ToInt())
+	assert.Equal(t, sha256utils.HashAndTruncate([]byte{2, 1}, 253), response.OutputHash.ToInt())
+
+	w, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	assert.NoError(t, w.UnmarshalBinary(response.Witness))
+	public, err := w.Public()
+	assert.NoError(t, err)
+	values := public.Vector().(fr.Vector)
+	assert.Equal(t, 2, len(values))
+	assert.Equal(t, response.InputHash.ToInt(), values[0].BigInt(new(big.Int)))
+	assert.Equal(t, response.OutputHash.ToInt(), values[1].BigInt(new(big.Int)))
+}
@@ -0,0 +1,213 @@
+// Assignment of the witnesses of circuits from Go structs of native values, so that the witness of
+// a circuit with many byte fields is not built by hand:
+//
+//	type Header struct {
+//		Number uint64
+//		Hash   [32]byte
+//		Extra  []byte
+//	}
+//	var assignment Circuit // Number vars.U64, Hash [32]vars.Byte, Extra []vars.Byte
+//	err := witness.Assign(&assignment, header)
+//
+// The fields of the Go struct are assigned to the fields of the circuit of the same name, which
+// must exist, and the fields of the circuit without a field in the Go struct are left as they are.
+// The supported fields of the circuit and the native values they take are:
+//   - vars.Byte: byte
+//   - vars.Bool: bool
+//   - vars.U32: uint32
+//   - vars.U64: uint64
+//   - vars.U256: *big.Int
+//   - vars.Variable: *big.Int or any integer
+//   - vars.VariableBytes: []byte or [n]byte of at most the maximum length, which must be set
+//   - arrays and slices of the above: arrays or slices of their values of the same length
+//   - structs of the above: structs of their values, assigned by name
+//
+// A nil slice of the circuit is created with the length of the value, and a slice that is already
+// created, as for the compiled circuit, must have the length of the value.
+package witness
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	byteType          = reflect.TypeOf(vars.Byte{})
+	boolType          = reflect.TypeOf(vars.Bool{})
+	u32Type           = reflect.TypeOf(vars.U32{})
+	u64Type           = reflect.TypeOf(vars.U64{})
+	u256Type          = reflect.TypeOf(vars.U256{})
+	variableType      = reflect.TypeOf(vars.Variable{})
+	variableBytesType = reflect.TypeOf(vars.VariableBytes{})
+	bigIntType        = reflect.TypeOf(&big.Int{})
+)
+
+// Assigns the fields of the value, a struct or a pointer to a struct of native values, to the
+// fields of the same name of the circuit, a pointer to a struct.
+func Assign(circuit interface{}, value interface{}) error {
+	dst := reflect.ValueOf(circuit)
+	if dst.Kind() != reflect.Pointer || dst.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%T is not a pointer to a struct", circuit)
+	}
+	src := reflect.ValueOf(value)
+	if src.Kind() == reflect.Pointer {
+		src = src.Elem()
+	}
+	if src.Kind() != reflect.Struct {
+		return fmt.Errorf("%T is not a struct", value)
+	}
+	return assign(dst.Elem(), src, "")
+}
+
+// Assigns the native value to the value of the circuit at the path.
+func assign(dst reflect.Value, src reflect.Value, path string) error {
+	if src.Kind() == reflect.Interface || (src.Kind() == reflect.Pointer && src.Type() != bigIntType) {
+		if src.IsNil() {
+			return fmt.Errorf("%s: nil value", name(path))
+		}
+		src = src.Elem()
+	}
+	mismatch := func() error {
+		return fmt.Errorf("%s: cannot assign %s to %s", name(path), src.Type(), dst.Type())
+	}
+
+	switch dst.Type() {
+	case byteType:
+		if src.Kind() != reflect.Uint8 {
+			return mismatch()
+		}
+		dst.Set(reflect.ValueOf(vars.Byte{Value: vars.NewVariableFromInt(int(src.Uint()))}))
+		return nil
+	case boolType:
+		if src.Kind() != reflect.Bool {
+			return mismatch()
+		}
+		dst.Set(reflect.ValueOf(vars.NewBool(src.Bool())))
+		return nil
+	case u32Type:
+		if src.Kind() != reflect.Uint32 {
+			return mismatch()
+		}
+		var u vars.U32
+		u.Set(uint32(src.Uint()))
+		dst.Set(reflect.ValueOf(u))
+		return nil
+	case u64Type:
+		if src.Kind() != reflect.Uint64 {
+			return mismatch()
+		}
+		var u vars.U64
+		u.Set(src.Uint())
+		dst.Set(reflect.ValueOf(u))
+		return nil
+	case u256Type:
+		if src.Type() != bigIntType || src.IsNil() {
+			return mismatch()
+		}
+		i := src.Interface().(*big.Int)
+		if i.Sign() < 0 || i.BitLen() > 256 {
+			return fmt.Errorf("%s: %s does not fit in 256 bits", name(path), i)
+		}
+		u := vars.NewU256()
+		u.Set(i)
+		dst.Set(reflect.ValueOf(u))
+		return nil
+	case variableType:
+		i, ok := toBigInt(src)
+		if !ok {
+			return mismatch()
+		}
+		dst.Set(reflect.ValueOf(vars.Variable{Value: i}))
+		return nil
+	case variableBytesType:
+		data, ok := toBytes(src)
+		if !ok {
+			return mismatch()
+		}
+		b := dst.Interface().(vars.VariableBytes)
+		if len(data) > len(b.Data) {
+			return fmt.Errorf("%s: %d bytes, at most %d expected, as the maximum length must be set", name(path), len(data), len(b.Data))
+		}
+		vars.SetVariableBytes(&b, data)
+		dst.Set(reflect.ValueOf(b))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Array, reflect.Slice:
+		if src.Kind() != reflect.Array && src.Kind() != reflect.Slice {
+			return mismatch()
+		}
+		if dst.Kind() == reflect.Slice && dst.IsNil() {
+			dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+		}
+		if dst.Len() != src.Len() {
+			return fmt.Errorf("%s: %d elements, %d expected", name(path), src.Len(), dst.Len())
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := assign(dst.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if src.Kind() != reflect.Struct {
+			return mismatch()
+		}
+		for i := 0; i < src.NumField(); i++ {
+			f := src.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fieldPath := f.Name
+			if path != "" {
+				fieldPath = path + "." + f.Name
+			}
+			field := dst.FieldByName(f.Name)
+			if !field.IsValid() || !field.CanSet() {
+				return fmt.Errorf("%s: no such field in %s", fieldPath, dst.Type())
+			}
+			if err := assign(field, src.Field(i), fieldPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: unsupported type %s", name(path), dst.Type())
+}
+
+// Returns the integer of a *big.Int or of an integer.
+func toBigInt(v reflect.Value) (*big.Int, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint()), true
+	}
+	if v.Type() == bigIntType && !v.IsNil() {
+		return new(big.Int).Set(v.Interface().(*big.Int)), true
+	}
+	return nil, false
+}
+
+// Returns the bytes of a []byte or of a [n]byte.
+func toBytes(v reflect.Value) ([]byte, bool) {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	data := make([]byte, v.Len())
+	for i := range data {
+		data[i] = byte(v.Index(i).Uint())
+	}
+	return data, true
+}
+
+func name(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
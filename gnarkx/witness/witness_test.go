@@ -0,0 +1,104 @@
+package witness
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testHeader struct {
+	Number vars.U64
+	Hash   [32]vars.Byte
+}
+
+type testCircuit struct {
+	Flag    vars.Bool
+	Small   vars.Byte
+	Index   vars.U32
+	Value   vars.U256
+	Field   vars.Variable
+	Header  testHeader
+	Parents [][32]vars.Byte
+	Extra   vars.VariableBytes
+}
+
+func newTestCircuit() *testCircuit {
+	return &testCircuit{Parents: vars.NewBytes32Array(2), Extra: vars.NewVariableBytes(8)}
+}
+
+// The circuit checks the values of the assignment of TestAssign.
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(c.Flag.Value, vars.ONE)
+	api.AssertIsEqual(c.Small.Value, vars.NewVariableFromInt(0x2a))
+	api.AssertIsEqual(c.Index.Value, vars.NewVariableFromInt(7))
+	api.AssertIsEqual(c.Value.Limbs[1], vars.ONE)
+	api.AssertIsEqual(c.Field, vars.NewVariableFromInt(5))
+	api.AssertIsEqual(c.Header.Number.Value, vars.NewVariableFromInt(12345))
+	api.AssertIsEqual(c.Header.Hash[31].Value, vars.NewVariableFromInt(0xaa))
+	api.AssertIsEqual(c.Parents[1][31].Value, vars.NewVariableFromInt(0xcc))
+	api.AssertIsEqual(c.Extra.Length, vars.THREE)
+	api.AssertIsEqual(c.Extra.Data[2].Value, vars.NewVariableFromInt('c'))
+	return nil
+}
+
+type header struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+type value struct {
+	Flag    bool
+	Small   byte
+	Index   uint32
+	Value   *big.Int
+	Field   int
+	Header  *header
+	Parents [][32]byte
+	Extra   []byte
+}
+
+func testValue() value {
+	return value{
+		Flag:    true,
+		Small:   0x2a,
+		Index:   7,
+		Value:   new(big.Int).Lsh(big.NewInt(1), 64),
+		Field:   5,
+		Header:  &header{Number: 12345, Hash: common.HexToHash("0xaa")},
+		Parents: [][32]byte{common.HexToHash("0xbb"), common.HexToHash("0xcc")},
+		Extra:   []byte("abc"),
+	}
+}
+
+func TestAssign(t *testing.T) {
+	assignment := newTestCircuit()
+	assert.NoError(t, Assign(assignment, testValue()))
+	assert.NoError(t, test.IsSolved(newTestCircuit(), assignment, ecc.BN254.ScalarField()))
+
+	// A nil slice is created with the length of the value.
+	assignment = &testCircuit{Extra: vars.NewVariableBytes(8)}
+	assert.NoError(t, Assign(assignment, testValue()))
+	assert.Len(t, assignment.Parents, 2)
+}
+
+func TestAssignErrors(t *testing.T) {
+	v := testValue()
+	v.Parents = v.Parents[:1]
+	assert.EqualError(t, Assign(newTestCircuit(), v), "Parents: 1 elements, 2 expected")
+
+	v = testValue()
+	v.Extra = make([]byte, 9)
+	assert.EqualError(t, Assign(newTestCircuit(), v), "Extra: 9 bytes, at most 8 expected, as the maximum length must be set")
+
+	assert.EqualError(t, Assign(newTestCircuit(), struct{ Small uint64 }{}), "Small: cannot assign uint64 to vars.Byte")
+	assert.EqualError(t, Assign(newTestCircuit(), struct{ Missing byte }{}), "Missing: no such field in witness.testCircuit")
+	assert.EqualError(t, Assign(newTestCircuit(), struct{ Header struct{ Number int } }{}), "Header.Number: cannot assign int to vars.U64")
+}
@@ -0,0 +1,99 @@
+package wrapper
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A BLS12-381 Groth16 proof as a circuit variable.
+type Proof = stdgroth16.Proof[sw_bls12381.G1Affine, sw_bls12381.G2Affine]
+
+// A BLS12-381 Groth16 verifying key as a circuit variable.
+type VerifyingKey = stdgroth16.VerifyingKey[sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl]
+
+// WrapCircuit is a BN254 circuit that verifies a BLS12-381 Groth16 proof of a circuit function.
+// The inner input hash and output hash (which are both less than 2^253 and therefore fit in the
+// BN254 scalar field) are re-exposed as the public inputs of the wrapper, so the wrapped proof is
+// verified on-chain exactly like a proof of the inner circuit function.
+type WrapCircuit struct {
+	// The input hash of the inner circuit function.
+	InputHash vars.Variable `gnark:"inputHash,public"`
+
+	// The output hash of the inner circuit function.
+	OutputHash vars.Variable `gnark:"outputHash,public"`
+
+	// The proof of the inner circuit function.
+	Proof Proof
+
+	// The verifying key of the inner circuit function. It is a constant of the circuit so that
+	// only proofs of the expected inner circuit are accepted.
+	vk VerifyingKey `gnark:"-"`
+}
+
+// Creates a new wrapper circuit for proofs with the given BLS12-381 verifying key.
+func NewWrapCircuit(innerVK groth16.VerifyingKey) (*WrapCircuit, error) {
+	vk, err := stdgroth16.ValueOfVerifyingKey[sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl](innerVK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert verifying key: %w", err)
+	}
+	if len(vk.G1.K) != 3 {
+		return nil, fmt.Errorf("inner circuit must have exactly two public inputs, found %d", len(vk.G1.K)-1)
+	}
+	return &WrapCircuit{
+		InputHash:  vars.NewVariable(),
+		OutputHash: vars.NewVariable(),
+		vk:         vk,
+	}, nil
+}
+
+// Define the circuit. The public inputs are range checked to 253 bits, converted to emulated
+// BLS12-381 scalars and used to verify the inner proof.
+func (c *WrapCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](baseAPI, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	pairing, err := sw_bls12381.NewPairing(baseAPI)
+	if err != nil {
+		return err
+	}
+	fr, err := emulated.NewField[emulated.BLS12381Fr](baseAPI)
+	if err != nil {
+		return err
+	}
+
+	var public []*sw_bls12381.Scalar
+	for _, v := range []vars.Variable{c.InputHash, c.OutputHash} {
+		bits := api.ToBinaryLE(v, 253)
+		fbits := make([]frontend.Variable, len(bits))
+		for i := 0; i < len(bits); i++ {
+			fbits[i] = bits[i].Value.Value
+		}
+		public = append(public, fr.FromBits(fbits...))
+	}
+
+	// This mirrors stdgroth16.Verifier.AssertProof, whose multi-scalar multiplication in gnark
+	// v0.9.1 drops every public input but the first.
+	kSum := &c.vk.G1.K[0]
+	for i := 0; i < len(public); i++ {
+		kSum = curve.AddUnified(kSum, curve.ScalarMul(&c.vk.G1.K[i+1], public[i]))
+	}
+	res, err := pairing.Pair(
+		[]*sw_bls12381.G1Affine{kSum, &c.Proof.Krs, &c.Proof.Ar},
+		[]*sw_bls12381.G2Affine{&c.vk.G2.GammaNeg, &c.vk.G2.DeltaNeg, &c.Proof.Bs},
+	)
+	if err != nil {
+		return fmt.Errorf("pairing: %w", err)
+	}
+	pairing.AssertIsEqual(res, &c.vk.E)
+	return nil
+}
@@ -0,0 +1,120 @@
+// A two-stage proving pipeline where a circuit function is proven with Groth16 over BLS12-381 and
+// the resulting proof is wrapped into a BN254 Groth16 proof that can be verified on Ethereum with
+// the regular FunctionVerifier contract. Proving over BLS12-381 is useful for circuits that rely
+// on its scalar field or that are produced by BLS12-381 based tooling.
+//
+// Wrapping BW6-761 proofs is not supported, since gnark does not provide an emulated BW6-761
+// pairing to verify them inside a BN254 circuit.
+package wrapper
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Pipeline holds the compiled inner and outer stages of a wrapped circuit function.
+type Pipeline struct {
+	function *succinct.CircuitFunction
+	inner    *Stage
+	outer    *Stage
+}
+
+// Compiles the circuit function over BLS12-381 and the wrapper circuit over BN254.
+func NewPipeline(f *succinct.CircuitFunction) (*Pipeline, error) {
+	inner, err := CompileStage(ecc.BLS12_381, f)
+	if err != nil {
+		return nil, fmt.Errorf("inner stage: %w", err)
+	}
+	circuit, err := NewWrapCircuit(inner.VerifyingKey())
+	if err != nil {
+		return nil, err
+	}
+	outer, err := CompileStage(ecc.BN254, circuit)
+	if err != nil {
+		return nil, fmt.Errorf("outer stage: %w", err)
+	}
+	return &Pipeline{function: f, inner: inner, outer: outer}, nil
+}
+
+// Imports a pipeline for the circuit function that was previously exported to a directory.
+func ImportPipeline(f *succinct.CircuitFunction, dir string) (*Pipeline, error) {
+	inner, err := ImportStage(ecc.BLS12_381, filepath.Join(dir, "inner"))
+	if err != nil {
+		return nil, fmt.Errorf("inner stage: %w", err)
+	}
+	outer, err := ImportStage(ecc.BN254, filepath.Join(dir, "outer"))
+	if err != nil {
+		return nil, fmt.Errorf("outer stage: %w", err)
+	}
+	return &Pipeline{function: f, inner: inner, outer: outer}, nil
+}
+
+// Returns the inner BLS12-381 stage.
+func (p *Pipeline) Inner() *Stage {
+	return p.inner
+}
+
+// Returns the outer BN254 stage.
+func (p *Pipeline) Outer() *Stage {
+	return p.outer
+}
+
+// Export writes both stages to subdirectories of dir along with the Solidity verifier for the
+// outer stage.
+func (p *Pipeline) Export(dir string) error {
+	if err := p.inner.Export(filepath.Join(dir, "inner")); err != nil {
+		return err
+	}
+	if err := p.outer.Export(filepath.Join(dir, "outer")); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(dir, "FunctionVerifier.sol"))
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+	svk := &succinct.SuccinctVerifyingKey{VerifyingKey: p.outer.VerifyingKey()}
+	return svk.ExportIFunctionVerifierSolidity(f)
+}
+
+// Generates a wrapped proof for f(inputs, witness) = outputs. The inner proof is generated over
+// BLS12-381 and then verified inside the outer BN254 circuit.
+func (p *Pipeline) Prove(inputBytes []byte) (*types.Groth16Proof, error) {
+	p.function.SetWitness(inputBytes)
+	innerProof, _, err := p.inner.Prove(p.function)
+	if err != nil {
+		return nil, fmt.Errorf("inner stage: %w", err)
+	}
+
+	assignment, err := NewWrapCircuit(p.inner.VerifyingKey())
+	if err != nil {
+		return nil, err
+	}
+	assignment.Proof, err = stdgroth16.ValueOfProof[sw_bls12381.G1Affine, sw_bls12381.G2Affine](innerProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert inner proof: %w", err)
+	}
+	assignment.InputHash.Set(p.function.InputHash.Value.(*big.Int))
+	assignment.OutputHash.Set(p.function.OutputHash.Value.(*big.Int))
+	outerProof, _, err := p.outer.Prove(assignment)
+	if err != nil {
+		return nil, fmt.Errorf("outer stage: %w", err)
+	}
+
+	output, err := types.NewGroth16Proof(outerProof)
+	if err != nil {
+		return nil, err
+	}
+	output.Input = inputBytes
+	output.Output = vars.GetValuesUnsafe(*p.function.Circuit.GetOutputBytes())
+	return output, nil
+}
@@ -0,0 +1,122 @@
+package wrapper
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// A Stage is a circuit compiled over a specific curve together with its Groth16 keys.
+type Stage struct {
+	curve ecc.ID
+	r1cs  constraint.ConstraintSystem
+	pk    groth16.ProvingKey
+	vk    groth16.VerifyingKey
+}
+
+// Compiles a circuit over the scalar field of the given curve and runs the Groth16 setup. Note that
+// the setup is not secure for production use and keys should instead come from a ceremony.
+func CompileStage(curve ecc.ID, circuit frontend.Circuit) (*Stage, error) {
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile circuit: %w", err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup circuit: %w", err)
+	}
+	return &Stage{curve: curve, r1cs: ccs, pk: pk, vk: vk}, nil
+}
+
+// Returns the curve of the stage.
+func (s *Stage) Curve() ecc.ID {
+	return s.curve
+}
+
+// Returns the verifying key of the stage.
+func (s *Stage) VerifyingKey() groth16.VerifyingKey {
+	return s.vk
+}
+
+// Generates a proof for the given assignment. The full witness is returned alongside the proof so
+// that callers can extract the public inputs.
+func (s *Stage) Prove(assignment frontend.Circuit) (groth16.Proof, witness.Witness, error) {
+	w, err := frontend.NewWitness(assignment, s.curve.ScalarField())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create witness: %w", err)
+	}
+	proof, err := groth16.Prove(s.r1cs, s.pk, w)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate proof: %w", err)
+	}
+	return proof, w, nil
+}
+
+// Export writes the R1CS, proving key, and verifying key of the stage to a directory.
+func (s *Stage) Export(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	files := []struct {
+		name string
+		data interface {
+			WriteTo(w io.Writer) (int64, error)
+		}
+	}{
+		{"r1cs.bin", s.r1cs},
+		{"pkey.bin", s.pk},
+		{"vkey.bin", s.vk},
+	}
+	for _, file := range files {
+		f, err := os.Create(filepath.Join(dir, file.name))
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		_, err = file.data.WriteTo(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write data: %w", err)
+		}
+	}
+	return nil
+}
+
+// ImportStage reads a stage over the given curve that was previously exported to a directory.
+func ImportStage(curve ecc.ID, dir string) (*Stage, error) {
+	s := &Stage{
+		curve: curve,
+		r1cs:  groth16.NewCS(curve),
+		pk:    groth16.NewProvingKey(curve),
+		vk:    groth16.NewVerifyingKey(curve),
+	}
+	files := []struct {
+		name string
+		data interface {
+			ReadFrom(r io.Reader) (int64, error)
+		}
+	}{
+		{"r1cs.bin", s.r1cs},
+		{"pkey.bin", s.pk},
+		{"vkey.bin", s.vk},
+	}
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(dir, file.name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		_, err = file.data.ReadFrom(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data: %w", err)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,57 @@
+package wrapper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A minimal inner circuit with the same public inputs as a succinct.CircuitFunction.
+type TestInnerCircuit struct {
+	InputHash  vars.Variable `gnark:"inputHash,public"`
+	OutputHash vars.Variable `gnark:"outputHash,public"`
+	Secret     vars.Variable
+}
+
+func (c *TestInnerCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	api.AssertIsEqual(c.OutputHash, api.Mul(c.InputHash, c.Secret))
+	return nil
+}
+
+func TestWrapCircuit(t *testing.T) {
+	inner, err := CompileStage(ecc.BLS12_381, &TestInnerCircuit{})
+	assert.NoError(t, err)
+
+	innerAssignment := &TestInnerCircuit{}
+	innerAssignment.InputHash.Set(big.NewInt(3))
+	innerAssignment.OutputHash.Set(big.NewInt(21))
+	innerAssignment.Secret.Set(big.NewInt(7))
+	innerProof, _, err := inner.Prove(innerAssignment)
+	assert.NoError(t, err)
+
+	circuit, err := NewWrapCircuit(inner.VerifyingKey())
+	assert.NoError(t, err)
+	assignment, err := NewWrapCircuit(inner.VerifyingKey())
+	assert.NoError(t, err)
+	assignment.Proof, err = stdgroth16.ValueOfProof[sw_bls12381.G1Affine, sw_bls12381.G2Affine](innerProof)
+	assert.NoError(t, err)
+	assignment.InputHash.Set(big.NewInt(3))
+	assignment.OutputHash.Set(big.NewInt(21))
+
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// A public input that doesn't match the inner proof must be rejected.
+	assignment.OutputHash.Set(big.NewInt(22))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
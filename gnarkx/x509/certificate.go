@@ -0,0 +1,231 @@
+package x509
+
+import (
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	gorsa "crypto/rsa"
+	gox509 "crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The limits of the certificates read by the circuit.
+const (
+	// The maximum length in bytes of the DER encoding of an issuer or subject name.
+	MaxNameLength = 256
+
+	// The maximum number of extensions of a certificate.
+	MaxExtensions = 16
+)
+
+// The algorithm of a public key.
+type Algorithm int
+
+const (
+	// RSA with public exponent 65537, signing with RSASSA-PKCS1-v1_5 and SHA-256.
+	RSA Algorithm = iota
+
+	// ECDSA P-256, signing with SHA-256.
+	ECDSAP256
+)
+
+// The type of a public key, which is fixed when the circuit is compiled.
+type KeyType struct {
+	Algorithm Algorithm
+
+	// The size of the modulus of an RSA key, a multiple of 64.
+	Bits int
+}
+
+// Returns the length of a signature under a key of the type.
+func (k KeyType) signatureLength() int {
+	if k.Algorithm == RSA {
+		return k.Bits / 8
+	}
+	return 64
+}
+
+// Returns the DER encoding of the AlgorithmIdentifier of signatures under a key of the type.
+func (k KeyType) signatureAlgorithm() []byte {
+	if k.Algorithm == RSA {
+		// sha256WithRSAEncryption with NULL parameters.
+		return []byte{0x30, 0x0d, 0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x01, 0x01, 0x0b, 0x05, 0x00}
+	}
+	// ecdsa-with-SHA256 without parameters.
+	return []byte{0x30, 0x0a, 0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x04, 0x03, 0x02}
+}
+
+func derHeader(tag byte, length int) []byte {
+	switch {
+	case length < 0x80:
+		return []byte{tag, byte(length)}
+	case length < 0x100:
+		return []byte{tag, 0x81, byte(length)}
+	default:
+		return []byte{tag, 0x82, byte(length >> 8), byte(length)}
+	}
+}
+
+// Returns the DER encoding of a SubjectPublicKeyInfo of the type with a zero key, and the offset of
+// the key in it. The key is the modulus of an RSA key, or the coordinates X || Y of a P-256 key.
+func (k KeyType) subjectPublicKeyInfo() ([]byte, int) {
+	if k.Algorithm == RSA {
+		// The modulus has its top bit set and is encoded as a positive INTEGER with a leading zero.
+		modulus := append(derHeader(tagInteger, k.Bits/8+1), 0x00)
+		exponent := []byte{tagInteger, 0x03, 0x01, 0x00, 0x01}
+		key := append(derHeader(tagSequence, len(modulus)+k.Bits/8+len(exponent)), modulus...)
+		bitString := append(derHeader(tagBitString, 1+len(key)+k.Bits/8+len(exponent)), 0x00)
+		// rsaEncryption with NULL parameters.
+		algorithm := []byte{0x30, 0x0d, 0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x01, 0x01, 0x01, 0x05, 0x00}
+
+		prefix := append(algorithm, bitString...)
+		prefix = append(prefix, key...)
+		prefix = append(derHeader(tagSequence, len(prefix)+k.Bits/8+len(exponent)), prefix...)
+		spki := append(prefix, make([]byte, k.Bits/8)...)
+		return append(spki, exponent...), len(prefix)
+	}
+	// id-ecPublicKey with the prime256v1 curve, and an uncompressed point.
+	prefix := []byte{
+		0x30, 0x59, 0x30, 0x13, 0x06, 0x07, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x02, 0x01, 0x06, 0x08, 0x2a,
+		0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07, 0x03, 0x42, 0x00, 0x04,
+	}
+	return append(prefix, make([]byte, 64)...), len(prefix)
+}
+
+// Returns the type of a public key of a parsed certificate.
+func keyTypeOf(key interface{}) (KeyType, error) {
+	switch k := key.(type) {
+	case *gorsa.PublicKey:
+		if k.E != 65537 || k.N.BitLen()%64 != 0 {
+			return KeyType{}, fmt.Errorf("unsupported RSA key with exponent %d and %d bits", k.E, k.N.BitLen())
+		}
+		return KeyType{Algorithm: RSA, Bits: k.N.BitLen()}, nil
+	case *goecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return KeyType{}, fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+		}
+		return KeyType{Algorithm: ECDSAP256}, nil
+	default:
+		return KeyType{}, fmt.Errorf("unsupported public key %T", key)
+	}
+}
+
+// A Certificate is the witness of a certificate: the DER encoding of its TBSCertificate, padded
+// with zeros, and its signature.
+type Certificate struct {
+	TBS    []vars.Byte
+	Length vars.Variable
+
+	// The big-endian signature, which is r || s for ECDSA.
+	Signature []vars.Byte
+
+	// The index in the extensions of the basic constraints extension, which is checked for the
+	// certificates of intermediate authorities.
+	BasicConstraints vars.Variable
+
+	signer KeyType `gnark:"-"`
+}
+
+// Creates a new certificate of up to maxLength bytes of TBSCertificate, signed by a key of the
+// signer type.
+func NewCertificate(maxLength int, signer KeyType) Certificate {
+	return Certificate{
+		TBS:              vars.NewBytes(maxLength),
+		Length:           vars.NewVariable(),
+		Signature:        vars.NewBytes(signer.signatureLength()),
+		BasicConstraints: vars.NewVariable(),
+		signer:           signer,
+	}
+}
+
+var oidBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+
+// Sets the certificate from a parsed certificate.
+func (c *Certificate) Set(cert *gox509.Certificate) error {
+	expected := gox509.SHA256WithRSA
+	if c.signer.Algorithm == ECDSAP256 {
+		expected = gox509.ECDSAWithSHA256
+	}
+	if cert.SignatureAlgorithm != expected {
+		return fmt.Errorf("signature algorithm %s, expected %s", cert.SignatureAlgorithm, expected)
+	}
+	if len(cert.RawTBSCertificate) > len(c.TBS) {
+		return fmt.Errorf("certificate length %d exceeds %d", len(cert.RawTBSCertificate), len(c.TBS))
+	}
+	if len(cert.Extensions) > MaxExtensions {
+		return fmt.Errorf("%d extensions exceed %d", len(cert.Extensions), MaxExtensions)
+	}
+
+	signature := cert.Signature
+	if c.signer.Algorithm == ECDSAP256 {
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(cert.Signature, &sig); err != nil {
+			return fmt.Errorf("invalid ECDSA signature: %w", err)
+		}
+		signature = append(sig.R.FillBytes(make([]byte, 32)), sig.S.FillBytes(make([]byte, 32))...)
+	}
+	if len(signature) != len(c.Signature) {
+		return fmt.Errorf("signature length %d, expected %d", len(signature), len(c.Signature))
+	}
+
+	index := 0
+	for i, extension := range cert.Extensions {
+		if extension.Id.Equal(oidBasicConstraints) {
+			index = i
+		}
+	}
+
+	tbs := make([]byte, len(c.TBS))
+	copy(tbs, cert.RawTBSCertificate)
+	vars.SetBytes(&c.TBS, tbs)
+	c.Length = vars.NewVariableFromInt(len(cert.RawTBSCertificate))
+	vars.SetBytes(&c.Signature, signature)
+	c.BasicConstraints = vars.NewVariableFromInt(index)
+	return nil
+}
+
+// A pinned root authority, whose key and subject are constants of the circuit.
+type Root struct {
+	Key PublicKey
+
+	// The DER encoding of the subject name.
+	Subject []byte
+}
+
+// Creates the root authority of a parsed certificate. Its validity period is not checked.
+func NewRoot(cert *gox509.Certificate) (Root, error) {
+	key, err := ConstantPublicKey(cert.PublicKey)
+	if err != nil {
+		return Root{}, err
+	}
+	return Root{Key: key, Subject: cert.RawSubject}, nil
+}
+
+// Returns a public key as constants of the circuit, from an *rsa.PublicKey or a P-256
+// *ecdsa.PublicKey.
+func ConstantPublicKey(publicKey interface{}) (PublicKey, error) {
+	keyType, err := keyTypeOf(publicKey)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	key := PublicKey{Type: keyType}
+	switch k := publicKey.(type) {
+	case *gorsa.PublicKey:
+		key.Modulus = vars.NewBytesFrom(k.N.FillBytes(make([]byte, keyType.Bits/8)))
+	case *goecdsa.PublicKey:
+		copy(key.X[:], vars.NewBytesFrom(k.X.FillBytes(make([]byte, 32))))
+		copy(key.Y[:], vars.NewBytesFrom(k.Y.FillBytes(make([]byte, 32))))
+	}
+	return key, nil
+}
+
+// Encodes a time as the integer YYYYMMDDHHMMSS in UTC, the format of the times of the circuit.
+func EncodeTime(t time.Time) uint64 {
+	t = t.UTC()
+	date := uint64(t.Year())*10000 + uint64(t.Month())*100 + uint64(t.Day())
+	return date*1000000 + uint64(t.Hour())*10000 + uint64(t.Minute())*100 + uint64(t.Second())
+}
@@ -0,0 +1,122 @@
+package x509
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// DER tags of the elements read by the circuit.
+const (
+	tagBoolean         = 0x01
+	tagInteger         = 0x02
+	tagBitString       = 0x03
+	tagOctetString     = 0x04
+	tagOID             = 0x06
+	tagUTCTime         = 0x17
+	tagGeneralizedTime = 0x18
+	tagSequence        = 0x30
+	tagVersion         = 0xa0
+	tagExtensions      = 0xa3
+)
+
+// A DER element of a table, with the offsets of its header, its contents, and the next element.
+type element struct {
+	start   vars.Variable
+	content vars.Variable
+	end     vars.Variable
+}
+
+// Reads the header of the DER element at offset, which must have the tag if active is 1. Lengths of
+// up to 0xffff bytes are supported.
+func readElement(api builder.API, t *byteslice.Table, offset vars.Variable, tag int, active frontend.Variable) element {
+	fapi := api.FrontendAPI()
+	b0 := t.At(offset, 0).Value.Value
+	b1 := t.At(offset, 1).Value
+	b2 := t.At(offset, 2).Value.Value
+	b3 := t.At(offset, 3).Value.Value
+	fapi.AssertIsEqual(fapi.Mul(active, fapi.Sub(b0, tag)), 0)
+
+	// The length is either b1 < 0x80, or b2 for b1 = 0x81, or b2 b3 for b1 = 0x82.
+	isShort := fapi.Sub(1, api.ToBinaryLE(b1, 8)[7].Value.Value)
+	is81 := fapi.IsZero(fapi.Sub(b1.Value, 0x81))
+	is82 := fapi.IsZero(fapi.Sub(b1.Value, 0x82))
+	fapi.AssertIsEqual(fapi.Mul(active, fapi.Sub(fapi.Add(isShort, is81, is82), 1)), 0)
+	length := fapi.Add(fapi.Mul(isShort, b1.Value), fapi.Mul(is81, b2), fapi.Mul(is82, fapi.Add(fapi.Mul(b2, 256), b3)))
+	header := fapi.Add(2, is81, fapi.Mul(is82, 2))
+
+	content := fapi.Add(offset.Value, header)
+	return element{
+		start:   offset,
+		content: vars.Variable{Value: content},
+		end:     vars.Variable{Value: fapi.Add(content, length)},
+	}
+}
+
+// Reads a UTCTime or GeneralizedTime at offset and returns it as the integer YYYYMMDDHHMMSS. Times
+// must be in UTC and without fractional seconds, as required by RFC 5280.
+func readTime(api builder.API, t *byteslice.Table, offset vars.Variable) (vars.Variable, element) {
+	fapi := api.FrontendAPI()
+	tag := t.At(offset, 0).Value.Value
+	isUTC := fapi.IsZero(fapi.Sub(tag, tagUTCTime))
+	isGeneralized := fapi.IsZero(fapi.Sub(tag, tagGeneralizedTime))
+	fapi.AssertIsEqual(fapi.Add(isUTC, isGeneralized), 1)
+
+	// YYMMDDHHMMSSZ or YYYYMMDDHHMMSSZ.
+	fapi.AssertIsEqual(t.At(offset, 1).Value.Value, fapi.Select(isUTC, 13, 15))
+	content := api.Add(offset, vars.NewVariableFromInt(2))
+	end := fapi.Add(content.Value, fapi.Select(isUTC, 13, 15))
+
+	// Maps digits to their values and other characters to 16.
+	digits := logderivlookup.New(fapi)
+	for i := 0; i < 256; i++ {
+		if i >= '0' && i <= '9' {
+			digits.Insert(i - '0')
+		} else {
+			digits.Insert(16)
+		}
+	}
+	d := make([]frontend.Variable, 14)
+	for k := 0; k < 14; k++ {
+		bits := api.ToBinaryLE(vars.Variable{Value: digits.Lookup(t.At(content, k).Value.Value)[0]}, 5)
+		// The last two digits are only part of a GeneralizedTime.
+		if k < 12 {
+			fapi.AssertIsEqual(bits[4].Value.Value, 0)
+		} else {
+			fapi.AssertIsEqual(fapi.Mul(isGeneralized, bits[4].Value.Value), 0)
+		}
+		d[k] = fapi.Add(bits[0].Value.Value, fapi.Mul(bits[1].Value.Value, 2), fapi.Mul(bits[2].Value.Value, 4),
+			fapi.Mul(bits[3].Value.Value, 8))
+	}
+	fapi.AssertIsEqual(fapi.Mul(isUTC, fapi.Sub(t.At(content, 12).Value.Value, 'Z')), 0)
+	fapi.AssertIsEqual(fapi.Mul(isGeneralized, fapi.Sub(t.At(content, 14).Value.Value, 'Z')), 0)
+
+	// A two digit year YY is 19YY for YY >= 50 and 20YY otherwise.
+	years := logderivlookup.New(fapi)
+	for yy := 0; yy < 100; yy++ {
+		if yy >= 50 {
+			years.Insert(1900 + yy)
+		} else {
+			years.Insert(2000 + yy)
+		}
+	}
+	utcYear := years.Lookup(fapi.Add(fapi.Mul(d[0], 10), d[1]))[0]
+	utcRest := decimal(fapi, d[2:12])
+	generalizedYear := decimal(fapi, d[0:4])
+	generalizedRest := decimal(fapi, d[4:14])
+	value := fapi.Select(isUTC,
+		fapi.Add(fapi.Mul(utcYear, 10000000000), utcRest),
+		fapi.Add(fapi.Mul(generalizedYear, 10000000000), generalizedRest),
+	)
+	return vars.Variable{Value: value}, element{start: offset, content: content, end: vars.Variable{Value: end}}
+}
+
+func decimal(api frontend.API, digits []frontend.Variable) frontend.Variable {
+	result := frontend.Variable(0)
+	for i := 0; i < len(digits); i++ {
+		result = api.Add(api.Mul(result, 10), digits[i])
+	}
+	return result
+}
@@ -0,0 +1,272 @@
+// A gadget for verifying X.509 certificate chains up to a pinned root authority, for circuits that
+// validate TLS or hardware attestation certificates.
+//
+// The DER encoding of every TBSCertificate is parsed in the circuit, element by element, so that
+// the issuer, validity, subject, and public key are read from their fields rather than located by
+// the prover. Certificates must be version 3, without unique identifiers, and signed with
+// sha256WithRSAEncryption or ecdsa-with-SHA256. The algorithms and key sizes of the chain are
+// fixed when the circuit is compiled.
+//
+// Intermediate authorities must have the cA flag of the basic constraints extension set. Other
+// extensions, such as key usages, path length constraints, and name constraints, are not checked,
+// and neither is revocation.
+//
+// Times are represented as the integer YYYYMMDDHHMMSS in UTC, see EncodeTime.
+package x509
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/compat"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/rsa"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A public key.
+type PublicKey struct {
+	Type KeyType
+
+	// The big-endian modulus of an RSA key.
+	Modulus []vars.Byte
+
+	// The big-endian coordinates of a P-256 key.
+	X [32]vars.Byte
+	Y [32]vars.Byte
+}
+
+// The DER encoding of a name in a TBSCertificate.
+type Name struct {
+	api    builder.API
+	table  *byteslice.Table
+	offset vars.Variable
+	length vars.Variable
+}
+
+func newName(api builder.API, t *byteslice.Table, e element) Name {
+	length := api.Sub(e.end, e.start)
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(MaxNameLength))
+	return Name{api: api, table: t, offset: e.start, length: length}
+}
+
+// Returns the bytes of the name, padded with zeros to MaxNameLength bytes.
+func (n Name) Bytes() []vars.Byte {
+	fapi := n.api.FrontendAPI()
+	result := make([]vars.Byte, MaxNameLength)
+	inRange := frontend.Variable(1)
+	for k := 0; k < MaxNameLength; k++ {
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(n.length.Value, k)))
+		result[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, n.table.At(n.offset, k).Value.Value)}}
+	}
+	return result
+}
+
+// Asserts that the name equals another name.
+func (n Name) AssertIsEqual(other Name) {
+	n.api.AssertIsEqual(n.length, other.length)
+	a, b := n.Bytes(), other.Bytes()
+	for k := 0; k < MaxNameLength; k++ {
+		n.api.AssertIsEqualByte(a[k], b[k])
+	}
+}
+
+// Asserts that the name equals the DER encoding of a name.
+func (n Name) AssertIsEqualLiteral(der []byte) {
+	if len(der) > MaxNameLength {
+		panic(fmt.Sprintf("name length %d exceeds %d", len(der), MaxNameLength))
+	}
+	n.api.AssertIsEqual(n.length, vars.NewVariableFromInt(len(der)))
+	n.table.AssertLiteralAt(n.offset, der)
+}
+
+// The fields of a parsed TBSCertificate.
+type TBSCertificate struct {
+	Issuer  Name
+	Subject Name
+
+	// The validity period, inclusive.
+	NotBefore vars.Variable
+	NotAfter  vars.Variable
+
+	PublicKey PublicKey
+
+	api   builder.API
+	table *byteslice.Table
+
+	// The offsets of the extensions, and whether they are present.
+	extensions []vars.Variable
+	present    []frontend.Variable
+}
+
+// Parses the TBSCertificate of a certificate whose subject has a key of the given type.
+func Parse(api builder.API, cert Certificate, key KeyType) *TBSCertificate {
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(cert.TBS); i++ {
+		rc.Check(cert.TBS[i].Value.Value, 8)
+	}
+	api.AssertIsLessOrEqual(cert.Length, vars.NewVariableFromInt(len(cert.TBS)))
+
+	spki, keyOffset := key.subjectPublicKeyInfo()
+	padding := MaxNameLength
+	if len(spki) > padding {
+		padding = len(spki)
+	}
+	t := byteslice.NewTable(api, cert.TBS, 0, padding)
+
+	tbs := readElement(api, t, vars.ZERO, tagSequence, 1)
+	api.AssertIsEqual(tbs.end, cert.Length)
+
+	// The version is v3, encoded as [0] EXPLICIT INTEGER 2.
+	version := readElement(api, t, tbs.content, tagVersion, 1)
+	t.AssertLiteralAt(version.content, []byte{tagInteger, 0x01, 0x02})
+	serial := readElement(api, t, version.end, tagInteger, 1)
+
+	// The signature algorithm must match the signer.
+	algorithm := cert.signer.signatureAlgorithm()
+	t.AssertLiteralAt(serial.end, algorithm)
+	issuer := readElement(api, t, api.Add(serial.end, vars.NewVariableFromInt(len(algorithm))), tagSequence, 1)
+
+	validity := readElement(api, t, issuer.end, tagSequence, 1)
+	notBefore, notBeforeElement := readTime(api, t, validity.content)
+	notAfter, notAfterElement := readTime(api, t, notBeforeElement.end)
+	api.AssertIsEqual(notAfterElement.end, validity.end)
+	subject := readElement(api, t, validity.end, tagSequence, 1)
+
+	// The SubjectPublicKeyInfo has a fixed encoding given the key type.
+	spkiOffset := subject.end
+	t.AssertLiteralAt(spkiOffset, spki[:keyOffset])
+	keyLength := len(spki) - keyOffset
+	if key.Algorithm == RSA {
+		keyLength = key.Bits / 8
+		t.AssertLiteralAt(api.Add(spkiOffset, vars.NewVariableFromInt(keyOffset+keyLength)), spki[keyOffset+keyLength:])
+	}
+	keyBytes := t.Read(api.Add(spkiOffset, vars.NewVariableFromInt(keyOffset)), keyLength)
+	publicKey := PublicKey{Type: key}
+	if key.Algorithm == RSA {
+		publicKey.Modulus = keyBytes
+	} else {
+		copy(publicKey.X[:], keyBytes[:32])
+		copy(publicKey.Y[:], keyBytes[32:])
+	}
+	spkiEnd := api.Add(spkiOffset, vars.NewVariableFromInt(len(spki)))
+
+	// The optional extensions are [3] EXPLICIT SEQUENCE OF Extension and end the TBSCertificate.
+	hasExtensions := fapi.Sub(1, fapi.IsZero(fapi.Sub(tbs.end.Value, spkiEnd.Value)))
+	wrapper := readElement(api, t, spkiEnd, tagExtensions, hasExtensions)
+	list := readElement(api, t, wrapper.content, tagSequence, hasExtensions)
+	fapi.AssertIsEqual(fapi.Mul(hasExtensions, fapi.Sub(wrapper.end.Value, tbs.end.Value)), 0)
+	fapi.AssertIsEqual(fapi.Mul(hasExtensions, fapi.Sub(list.end.Value, tbs.end.Value)), 0)
+
+	offset := vars.Variable{Value: fapi.Select(hasExtensions, list.content.Value, tbs.end.Value)}
+	extensions := make([]vars.Variable, MaxExtensions)
+	present := make([]frontend.Variable, MaxExtensions)
+	for j := 0; j < MaxExtensions; j++ {
+		extensions[j] = offset
+		present[j] = fapi.Sub(1, fapi.IsZero(fapi.Sub(offset.Value, tbs.end.Value)))
+		extension := readElement(api, t, offset, tagSequence, present[j])
+		offset = vars.Variable{Value: fapi.Select(present[j], extension.end.Value, offset.Value)}
+	}
+	api.AssertIsEqual(offset, tbs.end)
+
+	return &TBSCertificate{
+		Issuer:     newName(api, t, issuer),
+		Subject:    newName(api, t, subject),
+		NotBefore:  notBefore,
+		NotAfter:   notAfter,
+		PublicKey:  publicKey,
+		api:        api,
+		table:      t,
+		extensions: extensions,
+		present:    present,
+	}
+}
+
+// Asserts that the certificate is valid at the time now.
+func (t *TBSCertificate) AssertValidAt(now vars.Variable) {
+	t.api.AssertIsLessOrEqual(t.NotBefore, now)
+	t.api.AssertIsLessOrEqual(now, t.NotAfter)
+}
+
+// Asserts that the extension at the index is a basic constraints extension with the cA flag set.
+func (t *TBSCertificate) AssertCA(index vars.Variable) {
+	fapi := t.api.FrontendAPI()
+	offset := frontend.Variable(0)
+	present := frontend.Variable(0)
+	for j := 0; j < MaxExtensions; j++ {
+		isIndex := fapi.IsZero(fapi.Sub(index.Value, j))
+		offset = fapi.Add(offset, fapi.Mul(isIndex, t.extensions[j].Value))
+		present = fapi.Add(present, fapi.Mul(isIndex, t.present[j]))
+	}
+	fapi.AssertIsEqual(present, 1)
+
+	// Extension ::= SEQUENCE { extnID, critical BOOLEAN DEFAULT FALSE, extnValue OCTET STRING },
+	// where the value is BasicConstraints ::= SEQUENCE { cA BOOLEAN DEFAULT FALSE, ... }.
+	extension := readElement(t.api, t.table, vars.Variable{Value: offset}, tagSequence, 1)
+	t.table.AssertLiteralAt(extension.content, []byte{tagOID, 0x03, 0x55, 0x1d, 0x13})
+	value := t.api.Add(extension.content, vars.NewVariableFromInt(5))
+	isCritical := fapi.IsZero(fapi.Sub(t.table.At(value, 0).Value.Value, tagBoolean))
+	critical := []byte{tagBoolean, 0x01, 0xff}
+	for k := 0; k < len(critical); k++ {
+		fapi.AssertIsEqual(fapi.Mul(isCritical, fapi.Sub(t.table.At(value, k).Value.Value, int(critical[k]))), 0)
+	}
+	value = t.api.Add(value, vars.Variable{Value: fapi.Mul(isCritical, len(critical))})
+
+	octets := readElement(t.api, t.table, value, tagOctetString, 1)
+	constraints := readElement(t.api, t.table, octets.content, tagSequence, 1)
+	t.table.AssertLiteralAt(constraints.content, []byte{tagBoolean, 0x01, 0xff})
+	t.api.AssertIsEqual(constraints.end, octets.end)
+	t.api.AssertIsEqual(octets.end, extension.end)
+}
+
+// Verifies the signature of the certificate under the key of its issuer.
+func Verify(api builder.API, cert Certificate, issuer PublicKey) {
+	if issuer.Type != cert.signer {
+		panic(fmt.Sprintf("issuer key type %v does not match signer %v", issuer.Type, cert.signer))
+	}
+	fapi := api.FrontendAPI()
+	rc := rangecheck.New(fapi)
+	for i := 0; i < len(cert.Signature); i++ {
+		rc.Check(cert.Signature[i].Value.Value, 8)
+	}
+
+	digest := sha256.HashVariable(api, cert.TBS, vars.U64{Value: cert.Length}, len(cert.TBS))
+	switch issuer.Type.Algorithm {
+	case RSA:
+		rsa.AssertValidPKCS1v15(api, issuer.Modulus, cert.Signature, digest)
+	case ECDSAP256:
+		var r, s [32]vars.Byte
+		copy(r[:], cert.Signature[:32])
+		copy(s[:], cert.Signature[32:])
+		compat.AssertValidP256ECDSA(api, digest, r, s, issuer.X, issuer.Y)
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %d", issuer.Type.Algorithm))
+	}
+}
+
+// Verifies a chain of certificates from the leaf to the last intermediate, which is issued by the
+// root, at the time now, and returns the parsed leaf. The keys are the subject key types of the
+// certificates of the chain.
+func VerifyChain(api builder.API, chain []Certificate, keys []KeyType, root Root, now vars.Variable) *TBSCertificate {
+	if len(chain) == 0 || len(keys) != len(chain) {
+		panic(fmt.Sprintf("expected a key type for each of the %d certificates, got %d", len(chain), len(keys)))
+	}
+	parsed := make([]*TBSCertificate, len(chain))
+	for i := 0; i < len(chain); i++ {
+		parsed[i] = Parse(api, chain[i], keys[i])
+		parsed[i].AssertValidAt(now)
+		if i > 0 {
+			parsed[i].AssertCA(chain[i].BasicConstraints)
+			parsed[i-1].Issuer.AssertIsEqual(parsed[i].Subject)
+			Verify(api, chain[i-1], parsed[i].PublicKey)
+		}
+	}
+	last := len(chain) - 1
+	parsed[last].Issuer.AssertIsEqualLiteral(root.Subject)
+	Verify(api, chain[last], root.Key)
+	return parsed[0]
+}
@@ -0,0 +1,134 @@
+package x509
+
+import (
+	"crypto"
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	gox509 "crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testCircuit struct {
+	Chain []Certificate
+	Now   vars.Variable
+	X     [32]vars.Byte
+
+	keys    []KeyType `gnark:"-"`
+	root    Root      `gnark:"-"`
+	subject []byte    `gnark:"-"`
+}
+
+func (c *testCircuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	leaf := VerifyChain(*api, c.Chain, c.keys, c.root, c.Now)
+	leaf.Subject.AssertIsEqualLiteral(c.subject)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(leaf.PublicKey.X[i], c.X[i])
+	}
+	return nil
+}
+
+func createCertificate(
+	t *testing.T,
+	name string,
+	isCA bool,
+	key crypto.PublicKey,
+	parent *gox509.Certificate,
+	signer crypto.Signer,
+) *gox509.Certificate {
+	template := &gox509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name, Organization: []string{"Example"}},
+		NotBefore:             time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              gox509.KeyUsageDigitalSignature | gox509.KeyUsageCertSign,
+		DNSNames:              []string{"example.com"},
+	}
+	if parent == nil {
+		parent = template
+	}
+	der, err := gox509.CreateCertificate(rand.Reader, template, parent, key, signer)
+	assert.NoError(t, err)
+	cert, err := gox509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert
+}
+
+func TestVerifyChain(t *testing.T) {
+	rootKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	intermediateKey, err := gorsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	leafKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	rootCert := createCertificate(t, "Root", true, rootKey.Public(), nil, rootKey)
+	intermediateCert := createCertificate(t, "Intermediate", true, intermediateKey.Public(), rootCert, rootKey)
+	leafCert := createCertificate(t, "example.com", false, leafKey.Public(), intermediateCert, intermediateKey)
+	root, err := NewRoot(rootCert)
+	assert.NoError(t, err)
+
+	keys := []KeyType{{Algorithm: ECDSAP256}, {Algorithm: RSA, Bits: 2048}}
+	newCircuit := func(chain ...*gox509.Certificate) *testCircuit {
+		c := &testCircuit{
+			Chain: []Certificate{
+				NewCertificate(640, KeyType{Algorithm: RSA, Bits: 2048}),
+				NewCertificate(640, KeyType{Algorithm: ECDSAP256}),
+			},
+			Now:     vars.NewVariableFromInt(int(EncodeTime(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)))),
+			keys:    keys,
+			root:    root,
+			subject: leafCert.RawSubject,
+		}
+		vars.SetBytes32(&c.X, [32]byte(leafKey.X.FillBytes(make([]byte, 32))))
+		for i := 0; i < len(chain); i++ {
+			assert.NoError(t, c.Chain[i].Set(chain[i]))
+		}
+		return c
+	}
+
+	circuit := newCircuit()
+	assignment := newCircuit(leafCert, intermediateCert)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// The leaf has expired.
+	assignment.Now = vars.NewVariableFromInt(int(EncodeTime(time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC))))
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The intermediate must be an authority.
+	nonCACert := createCertificate(t, "Intermediate", false, intermediateKey.Public(), rootCert, rootKey)
+	leafCert = createCertificate(t, "example.com", false, leafKey.Public(), nonCACert, intermediateKey)
+	assignment = newCircuit(leafCert, nonCACert)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// The intermediate must be issued by the pinned root.
+	otherKey, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	otherRootCert := createCertificate(t, "Root", true, otherKey.Public(), nil, otherKey)
+	otherCert := createCertificate(t, "Intermediate", true, intermediateKey.Public(), otherRootCert, otherKey)
+	leafCert = createCertificate(t, "example.com", false, leafKey.Public(), otherCert, intermediateKey)
+	assignment = newCircuit(leafCert, otherCert)
+	err = test.IsSolved(circuit, assignment, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+}
+
+func TestEncodeTime(t *testing.T) {
+	assert.Equal(t, uint64(20240601120005), EncodeTime(time.Date(2024, 6, 1, 12, 0, 5, 0, time.UTC)))
+}
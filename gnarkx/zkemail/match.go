@@ -0,0 +1,105 @@
+package zkemail
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Asserts that index is either zero or preceded by a CRLF, i.e. that it is the start of a line.
+// The table must have at least two bytes of padding before the data.
+func assertLineStart(api builder.API, t *byteslice.Table, index vars.Variable) {
+	fapi := api.FrontendAPI()
+	notStart := fapi.Sub(1, fapi.IsZero(index.Value))
+	fapi.AssertIsEqual(fapi.Mul(notStart, fapi.Sub(t.At(index, -2).Value.Value, '\r')), 0)
+	fapi.AssertIsEqual(fapi.Mul(notStart, fapi.Sub(t.At(index, -1).Value.Value, '\n')), 0)
+}
+
+// Asserts that none of the length bytes starting at index equal the forbidden byte. The length
+// must be at most maxLength.
+func assertExcludes(
+	api builder.API,
+	t *byteslice.Table,
+	index vars.Variable,
+	length vars.Variable,
+	maxLength int,
+	forbidden byte,
+) {
+	fapi := api.FrontendAPI()
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(maxLength))
+	inRange := frontend.Variable(1)
+	for k := 0; k < maxLength; k++ {
+		inRange = fapi.Sub(inRange, fapi.IsZero(fapi.Sub(length.Value, k)))
+		isForbidden := fapi.IsZero(fapi.Sub(t.At(index, k).Value.Value, int(forbidden)))
+		fapi.AssertIsEqual(fapi.Mul(inRange, isForbidden), 0)
+	}
+}
+
+// Reads a field matching the regular expression [class]{length} followed by one of the
+// terminators, starting at index. The length must be between 1 and maxLength. The field is
+// returned padded with zeros to maxLength bytes.
+func readField(
+	api builder.API,
+	t *byteslice.Table,
+	index vars.Variable,
+	length vars.Variable,
+	maxLength int,
+	class *charClass,
+	terminators string,
+) []vars.Byte {
+	fapi := api.FrontendAPI()
+	api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(maxLength))
+	api.AssertIsDifferent(length, vars.ZERO)
+
+	result := make([]vars.Byte, maxLength)
+	inRange := frontend.Variable(1)
+	for k := 0; k <= maxLength; k++ {
+		c := t.At(index, k).Value.Value
+		isEnd := fapi.IsZero(fapi.Sub(length.Value, k))
+		inRange = fapi.Sub(inRange, isEnd)
+
+		// Bytes of the field must be in the class.
+		fapi.AssertIsEqual(fapi.Mul(inRange, fapi.Sub(1, class.contains(c))), 0)
+
+		// The byte after the field must be a terminator.
+		isTerminator := frontend.Variable(1)
+		for i := 0; i < len(terminators); i++ {
+			isTerminator = fapi.Mul(isTerminator, fapi.Sub(c, int(terminators[i])))
+		}
+		fapi.AssertIsEqual(fapi.Mul(isEnd, isTerminator), 0)
+
+		if k < maxLength {
+			result[k] = vars.Byte{Value: vars.Variable{Value: fapi.Mul(inRange, c)}}
+		}
+	}
+	return result
+}
+
+// A charClass is a set of bytes, like a character class [...] of a regular expression.
+type charClass struct {
+	table *logderivlookup.Table
+}
+
+// Creates a new class containing the given bytes.
+func newCharClass(api builder.API, chars string) *charClass {
+	var members [256]bool
+	for i := 0; i < len(chars); i++ {
+		members[chars[i]] = true
+	}
+	table := logderivlookup.New(api.FrontendAPI())
+	for i := 0; i < 256; i++ {
+		if members[i] {
+			table.Insert(1)
+		} else {
+			table.Insert(0)
+		}
+	}
+	return &charClass{table: table}
+}
+
+// Returns 1 if the byte is in the class and 0 otherwise. The byte must be range checked.
+func (c *charClass) contains(b frontend.Variable) frontend.Variable {
+	return c.table.Lookup(b)[0]
+}
@@ -0,0 +1,325 @@
+// A circuit template for zkEmail: proving that an email was signed by a domain with DKIM and
+// extracting a field from its body, without revealing the rest of the email.
+//
+// The circuit takes the 2048-bit RSA public key of the signing domain as its input and outputs the
+// domain of the from address and the extracted field, each right-padded with zeros to 32 bytes.
+// The header is the DKIM signing input, i.e. the canonicalized signed headers followed by the
+// DKIM-Signature header with an empty b= tag, and the body is the canonicalized body. Only the
+// relaxed header canonicalization (lowercase header names) and rsa-sha256 are supported.
+package zkemail
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/byteslice"
+	"github.com/succinctlabs/succinctx/gnarkx/email"
+	"github.com/succinctlabs/succinctx/gnarkx/encoding/base64"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	// The length of a 2048-bit RSA modulus and signature in bytes.
+	keyLength = email.ModulusLength
+
+	// The maximum number of bytes between the start of the from header and the domain.
+	maxFromLength = 256
+
+	// The maximum length of the from domain.
+	maxDomainLength = 32
+
+	// The length of the base64 encoding of a SHA-256 digest.
+	bodyHashLength = 44
+)
+
+// The characters allowed in a domain.
+const domainClass = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789.-"
+
+// Config describes the emails a circuit accepts and the field it extracts from the body.
+type Config struct {
+	// The maximum lengths of the header and body.
+	MaxHeaderLength int
+	MaxBodyLength   int
+
+	// The field is a run of bytes in FieldClass following FieldPrefix in the body, which must be
+	// followed by a byte in FieldTerminators. In regular expression terms:
+	// FieldPrefix([FieldClass]{1,MaxFieldLength})[FieldTerminators].
+	FieldPrefix      string
+	FieldClass       string
+	FieldTerminators string
+	MaxFieldLength   int
+}
+
+// An email and the DKIM signature over it.
+type Email struct {
+	Header    []byte
+	Body      []byte
+	Signature []byte
+}
+
+// Circuit verifies the DKIM signature of an email, reads the domain of the from address, and
+// extracts a field from the body.
+type Circuit struct {
+	InputBytes  []vars.Byte
+	OutputBytes []vars.Byte
+
+	Header       []vars.Byte
+	HeaderLength vars.Variable
+	Body         []vars.Byte
+	BodyLength   vars.Variable
+	Signature    []vars.Byte
+
+	// The positions of the from header, the domain of the from address, the body hash in the
+	// DKIM-Signature header, and the field in the body.
+	FromIndex     vars.Variable
+	DomainIndex   vars.Variable
+	DomainLength  vars.Variable
+	BodyHashIndex vars.Variable
+	FieldIndex    vars.Variable
+	FieldLength   vars.Variable
+
+	config *Config `gnark:"-"`
+	email  *Email  `gnark:"-"`
+}
+
+var _ succinct.Circuit = (*Circuit)(nil)
+
+// Creates a new circuit for emails described by the config.
+func NewCircuit(config *Config) *Circuit {
+	if config.MaxFieldLength > 32 {
+		panic(fmt.Sprintf("max field length %d exceeds 32", config.MaxFieldLength))
+	}
+	if len(config.FieldPrefix) == 0 || len(config.FieldTerminators) == 0 {
+		panic("field prefix and terminators must not be empty")
+	}
+	return &Circuit{
+		InputBytes:    vars.NewBytes(keyLength),
+		OutputBytes:   vars.NewBytes(64),
+		Header:        vars.NewBytes(config.MaxHeaderLength),
+		HeaderLength:  vars.NewVariable(),
+		Body:          vars.NewBytes(config.MaxBodyLength),
+		BodyLength:    vars.NewVariable(),
+		Signature:     vars.NewBytes(keyLength),
+		FromIndex:     vars.NewVariable(),
+		DomainIndex:   vars.NewVariable(),
+		DomainLength:  vars.NewVariable(),
+		BodyHashIndex: vars.NewVariable(),
+		FieldIndex:    vars.NewVariable(),
+		FieldLength:   vars.NewVariable(),
+		config:        config,
+	}
+}
+
+// Sets the email that the next call to SetWitness assigns. The email is checked to match the
+// config, but the signature is only checked by the circuit.
+func (c *Circuit) SetEmail(email *Email) error {
+	if len(email.Header) > c.config.MaxHeaderLength {
+		return fmt.Errorf("header length %d exceeds %d", len(email.Header), c.config.MaxHeaderLength)
+	}
+	if len(email.Body) > c.config.MaxBodyLength {
+		return fmt.Errorf("body length %d exceeds %d", len(email.Body), c.config.MaxBodyLength)
+	}
+	if len(email.Signature) != keyLength {
+		return fmt.Errorf("signature length %d, expected %d", len(email.Signature), keyLength)
+	}
+	if _, err := c.locate(email); err != nil {
+		return err
+	}
+	c.email = email
+	return nil
+}
+
+// The positions of the matched parts of an email.
+type positions struct {
+	from, domain, domainLength, bodyHash, field, fieldLength int
+}
+
+// Finds the from domain, body hash, and field of the email.
+func (c *Circuit) locate(email *Email) (*positions, error) {
+	var p positions
+	header, body := email.Header, email.Body
+
+	switch {
+	case bytes.HasPrefix(header, []byte("from:")):
+		p.from = 0
+	case bytes.Contains(header, []byte("\r\nfrom:")):
+		p.from = bytes.Index(header, []byte("\r\nfrom:")) + 2
+	default:
+		return nil, fmt.Errorf("from header not found")
+	}
+	line := header[p.from:]
+	if end := bytes.IndexByte(line, '\r'); end >= 0 {
+		line = line[:end]
+	}
+	at := bytes.IndexByte(line, '@')
+	if at < 0 || at+1 > maxFromLength {
+		return nil, fmt.Errorf("from address not found")
+	}
+	p.domain = p.from + at + 1
+	p.domainLength = matchClass(header[p.domain:], domainClass)
+	if p.domainLength == 0 || p.domainLength > maxDomainLength {
+		return nil, fmt.Errorf("invalid from domain")
+	}
+	if end := p.domain + p.domainLength; end >= len(header) || (header[end] != '>' && header[end] != '\r') {
+		return nil, fmt.Errorf("from domain is not terminated")
+	}
+
+	bh := -1
+	for i := 1; i+3 <= len(header); i++ {
+		if bytes.HasPrefix(header[i:], []byte("bh=")) && bytes.IndexByte([]byte(" ;\t"), header[i-1]) >= 0 {
+			bh = i
+			break
+		}
+	}
+	if bh < 0 || bh+3+bodyHashLength > len(header) {
+		return nil, fmt.Errorf("body hash not found")
+	}
+	p.bodyHash = bh
+
+	p.field = bytes.Index(body, []byte(c.config.FieldPrefix))
+	if p.field < 0 {
+		return nil, fmt.Errorf("field prefix %q not found", c.config.FieldPrefix)
+	}
+	start := p.field + len(c.config.FieldPrefix)
+	p.fieldLength = matchClass(body[start:], c.config.FieldClass)
+	if p.fieldLength == 0 || p.fieldLength > c.config.MaxFieldLength {
+		return nil, fmt.Errorf("invalid field length %d", p.fieldLength)
+	}
+	if end := start + p.fieldLength; end >= len(body) || bytes.IndexByte([]byte(c.config.FieldTerminators), body[end]) < 0 {
+		return nil, fmt.Errorf("field is not terminated")
+	}
+	return &p, nil
+}
+
+// Returns the length of the longest prefix of data whose bytes are in class.
+func matchClass(data []byte, class string) int {
+	for i := 0; i < len(data); i++ {
+		if bytes.IndexByte([]byte(class), data[i]) < 0 {
+			return i
+		}
+	}
+	return len(data)
+}
+
+func (c *Circuit) GetInputBytes() *[]vars.Byte {
+	return &c.InputBytes
+}
+
+func (c *Circuit) GetOutputBytes() *[]vars.Byte {
+	return &c.OutputBytes
+}
+
+func (c *Circuit) Assign(inputBytes []byte) error {
+	return nil
+}
+
+// Sets the witness for the email given to SetEmail, signed under the modulus in inputBytes.
+func (c *Circuit) SetWitness(inputBytes []byte) {
+	if c.email == nil {
+		panic("email must be set before the witness")
+	}
+	p, err := c.locate(c.email)
+	if err != nil {
+		panic(err)
+	}
+	vars.SetBytes(&c.InputBytes, inputBytes)
+
+	header := make([]byte, c.config.MaxHeaderLength)
+	copy(header, c.email.Header)
+	vars.SetBytes(&c.Header, header)
+	c.HeaderLength = vars.NewVariableFromInt(len(c.email.Header))
+	body := make([]byte, c.config.MaxBodyLength)
+	copy(body, c.email.Body)
+	vars.SetBytes(&c.Body, body)
+	c.BodyLength = vars.NewVariableFromInt(len(c.email.Body))
+	vars.SetBytes(&c.Signature, c.email.Signature)
+
+	c.FromIndex = vars.NewVariableFromInt(p.from)
+	c.DomainIndex = vars.NewVariableFromInt(p.domain)
+	c.DomainLength = vars.NewVariableFromInt(p.domainLength)
+	c.BodyHashIndex = vars.NewVariableFromInt(p.bodyHash)
+	c.FieldIndex = vars.NewVariableFromInt(p.field)
+	c.FieldLength = vars.NewVariableFromInt(p.fieldLength)
+
+	start := p.field + len(c.config.FieldPrefix)
+	var domain, field [32]byte
+	copy(domain[:], c.email.Header[p.domain:p.domain+p.domainLength])
+	copy(field[:], c.email.Body[start:start+p.fieldLength])
+	vars.SetBytes(&c.OutputBytes, append(domain[:], field[:]...))
+}
+
+func (c *Circuit) Define(baseAPI frontend.API) error {
+	api := builder.NewAPI(baseAPI)
+	rc := rangecheck.New(baseAPI)
+	for i := 0; i < len(c.Body); i++ {
+		rc.Check(c.Body[i].Value.Value, 8)
+	}
+
+	// Verify the DKIM signature over the header.
+	email.Verify(*api, email.Message{Header: c.Header, Length: c.HeaderLength, Signature: c.Signature}, c.InputBytes)
+
+	// Every matched byte must be within the signed header, since the rest is unconstrained.
+	header := byteslice.NewTable(*api, c.Header, 2, maxFromLength+maxDomainLength+bodyHashLength)
+	offset := func(v vars.Variable, k int) vars.Variable {
+		return api.Add(v, vars.NewVariableFromInt(k))
+	}
+
+	// Read the domain of the address in the from header: from:...@<domain>[>\r].
+	assertLineStart(*api, header, c.FromIndex)
+	header.AssertLiteralAt(c.FromIndex, []byte("from:"))
+	api.AssertIsEqual(header.At(c.DomainIndex, -1).Value, vars.NewVariableFromInt('@'))
+	assertExcludes(*api, header, c.FromIndex, api.Sub(c.DomainIndex, c.FromIndex), maxFromLength, '\r')
+	domainChars := newCharClass(*api, domainClass)
+	domain := readField(*api, header, c.DomainIndex, c.DomainLength, maxDomainLength, domainChars, ">\r")
+	api.AssertIsLessOrEqual(offset(api.Add(c.DomainIndex, c.DomainLength), 1), c.HeaderLength)
+
+	// Check the body hash in the DKIM-Signature header: [ ;\t]bh=<base64 digest>.
+	fapi := api.FrontendAPI()
+	separator := header.At(c.BodyHashIndex, -1).Value.Value
+	isSeparator := fapi.Mul(fapi.Sub(separator, ' '), fapi.Sub(separator, ';'), fapi.Sub(separator, '\t'))
+	fapi.AssertIsEqual(isSeparator, 0)
+	header.AssertLiteralAt(c.BodyHashIndex, []byte("bh="))
+	api.AssertIsLessOrEqual(offset(c.BodyHashIndex, 3+bodyHashLength), c.HeaderLength)
+	bodyDigest := sha256.HashVariable(*api, c.Body, vars.U64{Value: c.BodyLength}, len(c.Body))
+	header.AssertEqualAt(offset(c.BodyHashIndex, 3), base64.StdEncoding.Encode(*api, bodyDigest[:]))
+
+	// Extract the field from the body.
+	prefix := c.config.FieldPrefix
+	body := byteslice.NewTable(*api, c.Body, 0, len(prefix)+c.config.MaxFieldLength+1)
+	body.AssertLiteralAt(c.FieldIndex, []byte(prefix))
+	fieldStart := offset(c.FieldIndex, len(prefix))
+	field := readField(
+		*api,
+		body,
+		fieldStart,
+		c.FieldLength,
+		c.config.MaxFieldLength,
+		newCharClass(*api, c.config.FieldClass),
+		c.config.FieldTerminators,
+	)
+	api.AssertIsLessOrEqual(offset(api.Add(fieldStart, c.FieldLength), 1), c.BodyLength)
+
+	outputWriter := builder.NewOutputWriter(*api)
+	outputWriter.WriteBytes32(padBytes32(domain))
+	outputWriter.WriteBytes32(padBytes32(field))
+	outputWriter.Close(c.OutputBytes)
+	return nil
+}
+
+// Right-pads the bytes with zeros to 32 bytes.
+func padBytes32(in []vars.Byte) [32]vars.Byte {
+	var result [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		if i < len(in) {
+			result[i] = in[i]
+		} else {
+			result[i] = vars.ZERO_BYTE
+		}
+	}
+	return result
+}
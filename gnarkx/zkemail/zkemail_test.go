@@ -0,0 +1,84 @@
+package zkemail
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/succinct"
+)
+
+var testConfig = &Config{
+	MaxHeaderLength:  384,
+	MaxBodyLength:    128,
+	FieldPrefix:      "code: ",
+	FieldClass:       "0123456789",
+	FieldTerminators: "\r",
+	MaxFieldLength:   8,
+}
+
+func signEmail(t *testing.T, key *rsa.PrivateKey, body string) *Email {
+	bodyHash := sha256.Sum256([]byte(body))
+	header := "from:Alice <alice@example.com>\r\n" +
+		"to:bob@example.org\r\n" +
+		"subject:verification\r\n" +
+		"dkim-signature:v=1; a=rsa-sha256; c=relaxed/relaxed; d=example.com; s=sel; h=from:to:subject; " +
+		"bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) + "; b="
+	digest := sha256.Sum256([]byte(header))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return &Email{Header: []byte(header), Body: []byte(body), Signature: signature}
+}
+
+func TestZKEmail(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	modulus := key.N.FillBytes(make([]byte, keyLength))
+	email := signEmail(t, key, "Your code: 123456\r\n")
+
+	circuit := NewCircuit(testConfig)
+	assert.NoError(t, circuit.SetEmail(email))
+	function := succinct.NewCircuitFunction(circuit)
+	function.SetWitness(modulus)
+
+	var expected [64]byte
+	copy(expected[:], "example.com")
+	copy(expected[32:], "123456")
+	assert.Equal(t, expected[:], circuitOutput(circuit))
+
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	// A signature under a different key must be rejected.
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	function.SetWitness(otherKey.N.FillBytes(make([]byte, keyLength)))
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	// A body that does not match the body hash must be rejected.
+	circuit = NewCircuit(testConfig)
+	tampered := *email
+	tampered.Body = []byte("Your code: 654321\r\n")
+	assert.NoError(t, circuit.SetEmail(&tampered))
+	function = succinct.NewCircuitFunction(circuit)
+	function.SetWitness(modulus)
+	err = test.IsSolved(&function, &function, ecc.BN254.ScalarField())
+	assert.Error(t, err)
+
+	assert.Error(t, NewCircuit(testConfig).SetEmail(&Email{Header: []byte("to:bob@example.org"), Signature: email.Signature}))
+}
+
+func circuitOutput(c *Circuit) []byte {
+	output := make([]byte, len(c.OutputBytes))
+	for i := range c.OutputBytes {
+		output[i] = c.OutputBytes[i].GetValueUnsafe()
+	}
+	return output
+}